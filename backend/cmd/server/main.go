@@ -4,6 +4,7 @@ import (
 	_ "api/docs" // Import generated docs
 	"api/pkg/api"
 	"api/pkg/database"
+	"api/pkg/services"
 	"log"
 	"net/http"
 	"os"
@@ -64,6 +65,44 @@ func main() {
 		log.Println("✅ Automatic daily backups enabled")
 	}
 
+	// Start audit log forwarding to SIEM (if configured)
+	if forwarder := services.InitAuditForwarder(db); forwarder != nil {
+		forwarder.Start(5 * time.Second)
+	}
+
+	// Start webhook delivery dispatcher
+	services.NewWebhookService(db).Start(15 * time.Second)
+
+	// Start reconciliation reminder escalation
+	services.NewReconciliationReminderService(db).Start(time.Hour)
+
+	// Start customer volume spike detection
+	services.NewVolumeAlertService(db).Start(24 * time.Hour)
+
+	// Start currency-pair margin floor alerts
+	services.NewMarginAlertService(db).Start(24 * time.Hour)
+
+	// Start follow-up of remittances left on compliance hold
+	services.NewHeldRemittanceService(db).Start(time.Hour)
+
+	// Start scheduled exchange rate refreshes for tenants with an interval
+	services.NewRateRefreshService(db).Start(5 * time.Minute)
+
+	// Start exchange rate snapshots for the dashboard rate trends
+	services.NewRateSnapshotService(db).Start(5 * time.Minute)
+
+	// Start SLA breach escalation for support tickets
+	services.NewTicketSLAService(db).Start(services.TicketSLAScanInterval())
+
+	// Start follow-up tickets for transactions left partially paid
+	services.NewPartialPaymentEscalationService(db).Start(time.Hour)
+
+	// Start emailing scheduled exports
+	services.NewScheduledExportService(db).Start(15 * time.Minute)
+
+	// Start KYC renewal reminders and expiry downgrades
+	services.NewComplianceExpiryService(db).Start(time.Hour)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// AddDailyCloseUniqueIndex makes a branch's day closable only once. The company-wide till has a NULL
+// branch_id, which a plain unique index would never consider equal, so the index is built over
+// COALESCE(branch_id, 0). It replaces the non-unique idx_daily_close_day.
+func AddDailyCloseUniqueIndex(db *gorm.DB) error {
+	if !db.Migrator().HasTable("daily_closes") {
+		log.Println("Daily closes table does not exist, skipping migration")
+		return nil
+	}
+
+	if err := db.Exec("DROP INDEX IF EXISTS idx_daily_close_day").Error; err != nil {
+		log.Printf("Note: Could not drop index (may not exist): %v", err)
+	}
+
+	sql := "CREATE UNIQUE INDEX IF NOT EXISTS uidx_daily_close_day ON daily_closes (tenant_id, COALESCE(branch_id, 0), date)"
+	if err := db.Exec(sql).Error; err != nil {
+		return err
+	}
+	log.Println("Created unique index: uidx_daily_close_day on daily_closes")
+	return nil
+}
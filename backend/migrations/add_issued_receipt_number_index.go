@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// AddIssuedReceiptNumberIndex keeps receipt numbers unique per branch rather than per tenant. Each branch
+// counts its own sequence, so two branches whose codes format alike (e.g. "dt" and "DT") issue the same
+// number; the branch is part of the index so neither is refused. Receipts without a branch have a NULL
+// branch_id, which a plain unique index would never consider equal, so the index is built over
+// COALESCE(branch_id, 0). It replaces idx_issued_receipt_number on (tenant_id, number).
+func AddIssuedReceiptNumberIndex(db *gorm.DB) error {
+	if !db.Migrator().HasTable("issued_receipts") {
+		log.Println("Issued receipts table does not exist, skipping migration")
+		return nil
+	}
+
+	if err := db.Exec("DROP INDEX IF EXISTS idx_issued_receipt_number").Error; err != nil {
+		log.Printf("Note: Could not drop index (may not exist): %v", err)
+	}
+
+	sql := "CREATE UNIQUE INDEX IF NOT EXISTS uidx_issued_receipt_number ON issued_receipts (tenant_id, COALESCE(branch_id, 0), number)"
+	if err := db.Exec(sql).Error; err != nil {
+		return err
+	}
+	log.Println("Created unique index: uidx_issued_receipt_number on issued_receipts")
+	return nil
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"api/pkg/models"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// LinkBranchUsers gives every branch with login credentials an explicit BranchUserID.
+// Branch logins used to be resolved to a shadow user found by the email "<username>@branch.local",
+// which collided when two tenants used the same branch username. Existing shadow users of the same
+// tenant and branch are adopted (and stripped of the branch's username and password so they can only
+// be reached through the branch login); branches without one get a fresh linked user.
+func LinkBranchUsers(db *gorm.DB) error {
+	log.Println("Linking branch logins to branch users...")
+
+	var branches []models.Branch
+	if err := db.Where("username IS NOT NULL AND password_hash IS NOT NULL AND branch_user_id IS NULL").
+		Find(&branches).Error; err != nil {
+		return err
+	}
+
+	for i := range branches {
+		branch := &branches[i]
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var legacy models.User
+			err := tx.Where("email = ? AND tenant_id = ? AND primary_branch_id = ?",
+				*branch.Username+"@branch.local", branch.TenantID, branch.ID).
+				First(&legacy).Error
+			if err == nil {
+				if err := tx.Model(&legacy).Updates(map[string]interface{}{
+					"username":      nil,
+					"password_hash": "",
+				}).Error; err != nil {
+					return err
+				}
+				return tx.Model(branch).Update("branch_user_id", legacy.ID).Error
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			branchUser, err := models.NewBranchUser(branch)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(branchUser).Error; err != nil {
+				return err
+			}
+			return tx.Model(branch).Update("branch_user_id", branchUser.ID).Error
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to link a user to branch %d: %v", branch.ID, err)
+			continue
+		}
+		log.Printf("Linked branch %d to its branch user", branch.ID)
+	}
+
+	return nil
+}
@@ -119,6 +119,38 @@ func (h *AdminHandler) ActivateTenantHandler(w http.ResponseWriter, r *http.Requ
 	w.WriteHeader(http.StatusOK)
 }
 
+// UpdateTenantTokenLifetimesHandler handler
+func (h *AdminHandler) UpdateTenantTokenLifetimesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseUint(vars["id"], 10, 64)
+
+	var req struct {
+		AccessTokenTTL  *int `json:"accessTokenTtl"`  // Minutes
+		RefreshTokenTTL *int `json:"refreshTokenTtl"` // Minutes
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.adminService.UpdateTenantTokenLifetimes(uint(id), req.AccessTokenTTL, req.RefreshTokenTTL); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// UnlockUserHandler handler
+func (h *AdminHandler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseUint(vars["id"], 10, 64)
+	if err := h.adminService.UnlockUser(uint(id)); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 // GetTenantCashBalancesHandler handler
 func (h *AdminHandler) GetTenantCashBalancesHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// APIKeyHandler handles tenant API key management requests
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	auditService  *services.AuditService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(db *gorm.DB) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: services.NewAPIKeyService(db),
+		auditService:  services.NewAuditService(db),
+	}
+}
+
+// keyManager returns the tenant and user when the caller is an owner or admin, writing an error otherwise
+func (h *APIKeyHandler) keyManager(w http.ResponseWriter, r *http.Request) (*uint, *models.User, bool) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can manage API keys", http.StatusForbidden)
+		return nil, nil, false
+	}
+	return tenantID, user, true
+}
+
+// GetAPIKeysHandler lists the tenant's API keys
+// @Summary List API keys
+// @Description Lists the tenant's API keys without their secrets (owners and admins)
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIKey
+// @Failure 403 {object} map[string]string
+// @Router /api-keys [get]
+func (h *APIKeyHandler) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := h.keyManager(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListKeys(*tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load API keys", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, keys)
+}
+
+// CreateAPIKeyHandler issues a new API key
+// @Summary Create API key
+// @Description Issues a scoped key for server-to-server calls with the X-API-Key header. The secret is returned only once. Scopes: reports:read, transactions:read.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.APIKeyRequest true "API key"
+// @Success 201 {object} services.CreatedAPIKey
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, user, ok := h.keyManager(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.apiKeyService.CreateKey(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionCreateAPIKey, "APIKey", strconv.FormatUint(uint64(created.ID), 10),
+		"Created API key "+created.Name+" ("+created.Prefix+")", nil, created.APIKey, r)
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// RevokeAPIKeyHandler revokes an API key
+// @Summary Revoke API key
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id}/revoke [post]
+func (h *APIKeyHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, user, ok := h.keyManager(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeKey(*tenantID, uint(keyID), user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionRevokeAPIKey, "APIKey", strconv.FormatUint(uint64(key.ID), 10),
+		"Revoked API key "+key.Name+" ("+key.Prefix+")", nil, nil, r)
+
+	respondJSON(w, http.StatusOK, key)
+}
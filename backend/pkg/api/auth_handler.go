@@ -4,6 +4,7 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 
@@ -158,6 +159,7 @@ func (ah *AuthHandler) ResendVerificationCodeHandler(w http.ResponseWriter, r *h
 // @Success 200 {object} map[string]interface{} "Login successful"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 401 {object} map[string]string "Unauthorized"
+// @Failure 423 {object} map[string]interface{} "Account temporarily locked"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /auth/login [post]
 func (ah *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
@@ -175,10 +177,37 @@ func (ah *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	loginResp, err := ah.AuthService.Login(req)
 	if err != nil {
 		log.Printf("Login error: %v", err)
-		respondWithError(w, http.StatusUnauthorized, err.Error())
+		respondWithLoginError(w, err)
+		return
+	}
+
+	if loginResp.TwoFactorRequired {
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"message":           "Two-factor authentication required",
+			"twoFactorRequired": true,
+			"challengeToken":    loginResp.ChallengeToken,
+		})
+		return
+	}
+
+	respondWithLogin(w, loginResp)
+}
+
+// respondWithLoginError reports a failed login step, telling a locked-out user when they can try again
+func respondWithLoginError(w http.ResponseWriter, err error) {
+	var locked *services.AccountLockedError
+	if errors.As(err, &locked) {
+		respondWithJSON(w, http.StatusLocked, map[string]interface{}{
+			"error":       err.Error(),
+			"lockedUntil": locked.Until,
+		})
 		return
 	}
+	respondWithError(w, http.StatusUnauthorized, err.Error())
+}
 
+// respondWithLogin writes the tokens, user and tenant of a completed login
+func respondWithLogin(w http.ResponseWriter, loginResp *services.LoginResponse) {
 	user := loginResp.User
 
 	// Get tenant info if exists
@@ -224,6 +253,102 @@ func (ah *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Verify2FAHandler completes a login that requires two-factor authentication
+// @Summary Verify two-factor code
+// @Description Exchange the login challenge token and a TOTP or recovery code for JWT tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Login successful"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 401 {object} map[string]string "Unauthorized"
+// @Router /auth/2fa/verify [post]
+func (ah *AuthHandler) Verify2FAHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ChallengeToken == "" || req.Code == "" {
+		respondWithError(w, http.StatusBadRequest, "Challenge token and code are required")
+		return
+	}
+
+	loginResp, err := ah.AuthService.Verify2FA(req.ChallengeToken, req.Code)
+	if err != nil {
+		log.Printf("2FA verification error: %v", err)
+		respondWithLoginError(w, err)
+		return
+	}
+
+	respondWithLogin(w, loginResp)
+}
+
+// EnableTwoFactorHandler starts 2FA enrollment for the current user
+// POST /api/auth/2fa/enable
+func (ah *AuthHandler) EnableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	setup, err := ah.AuthService.EnableTwoFactor(user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, setup)
+}
+
+// ActivateTwoFactorHandler confirms the first authenticator code and returns the recovery codes
+// POST /api/auth/2fa/activate
+func (ah *AuthHandler) ActivateTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := ah.AuthService.VerifyAndActivateTwoFactor(user.ID, req.Code)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// DisableTwoFactorHandler turns 2FA off after checking a current code
+// POST /api/auth/2fa/disable
+func (ah *AuthHandler) DisableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := ah.AuthService.DisableTwoFactor(user.ID, req.Code); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
 // GetMeHandler returns current user info (requires authentication)
 // @Summary Get current user
 // @Description Get authenticated user information
@@ -2,6 +2,7 @@ package api
 
 import (
 	"api/pkg/middleware"
+	"api/pkg/models"
 	"api/pkg/services"
 	"api/pkg/validation"
 	"encoding/json"
@@ -34,6 +35,7 @@ func NewAutoSettlementHandler(db *gorm.DB) *AutoSettlementHandler {
 // @Param id path int true "Incoming Remittance ID"
 // @Param strategy query string false "Settlement strategy: FIFO, LIFO, BEST_RATE" default(FIFO)
 // @Param limit query int false "Maximum number of suggestions"
+// @Param includeSkipped query bool false "Also return remittances excluded from suggestions (e.g. compliance holds)"
 // @Success 200 {array} services.SettlementSuggestion
 // @Router /remittances/incoming/{id}/suggestions [get]
 func (h *AutoSettlementHandler) GetSettlementSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
@@ -50,9 +52,10 @@ func (h *AutoSettlementHandler) GetSettlementSuggestionsHandler(w http.ResponseW
 		return
 	}
 
-	strategy := services.SettlementStrategy(r.URL.Query().Get("strategy"))
-	if strategy == "" {
-		strategy = services.StrategyFIFO
+	strategy, err := services.ParseSettlementStrategy(r.URL.Query().Get("strategy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	limit := 0
@@ -61,24 +64,29 @@ func (h *AutoSettlementHandler) GetSettlementSuggestionsHandler(w http.ResponseW
 		limit = limitVal
 	}
 
-	suggestions, err := h.autoSettlementService.GetSettlementSuggestions(*tenantID, uint(incomingID), strategy, limit)
+	result, err := h.autoSettlementService.GetSettlementCandidates(*tenantID, uint(incomingID), strategy, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(suggestions)
+	if r.URL.Query().Get("includeSkipped") == "true" {
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+	json.NewEncoder(w).Encode(result.Suggestions)
 }
 
 // AutoSettleHandler automatically settles an incoming remittance
 // @Summary Auto-settle incoming remittance
-// @Description Automatically settles an incoming remittance using the specified strategy
+// @Description Automatically settles an incoming remittance using the specified strategy, optionally capped at maxAmountIrr
 // @Tags Auto-Settlement
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body validation.AutoSettleRequest true "Auto-settle request"
+// @Param strategy query string false "Settlement strategy if not in the body: FIFO, LIFO, BEST_RATE" default(FIFO)
 // @Success 200 {object} services.AutoSettlementResult
 // @Router /remittances/auto-settle [post]
 func (h *AutoSettlementHandler) AutoSettleHandler(w http.ResponseWriter, r *http.Request) {
@@ -99,6 +107,15 @@ func (h *AutoSettlementHandler) AutoSettleHandler(w http.ResponseWriter, r *http
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if req.Strategy == "" {
+		req.Strategy = r.URL.Query().Get("strategy")
+	}
+	strategy, err := services.ParseSettlementStrategy(req.Strategy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Strategy = string(strategy)
 
 	// Validate
 	if errors := validation.ValidateStruct(req); errors != nil {
@@ -108,12 +125,13 @@ func (h *AutoSettlementHandler) AutoSettleHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	strategy := services.SettlementStrategy(req.Strategy)
-	if strategy == "" {
-		strategy = services.StrategyFIFO
+	var maxAmountIRR *models.Decimal
+	if req.MaxAmountIRR != nil {
+		maxAmount := models.NewDecimal(*req.MaxAmountIRR)
+		maxAmountIRR = &maxAmount
 	}
 
-	result, err := h.autoSettlementService.AutoSettle(*tenantID, req.IncomingRemittanceID, userID, strategy)
+	result, err := h.autoSettlementService.AutoSettleUpTo(*tenantID, req.IncomingRemittanceID, userID, strategy, maxAmountIRR)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -361,3 +361,180 @@ func (bh *BranchHandler) SetBranchCredentialsHandler(w http.ResponseWriter, r *h
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Branch credentials set successfully"})
 }
+
+// parseBranchFloatTargetIDs reads the branch ID and, if present, the float target ID from the URL
+func parseBranchFloatTargetIDs(r *http.Request) (branchID, targetID uint, err error) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if raw, ok := vars["targetId"]; ok {
+		tid, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		targetID = uint(tid)
+	}
+	return uint(id), targetID, nil
+}
+
+// canManageFloatTargets reports whether the user may change branch float targets
+func canManageFloatTargets(user *models.User) bool {
+	return user.Role == models.RoleTenantOwner || user.Role == models.RoleTenantAdmin
+}
+
+// GetFloatTargetsHandler lists a branch's cash float targets
+// @Summary Get branch float targets
+// @Description Get the cash float the branch aims to hold per currency
+// @Tags branches
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Success 200 {array} models.BranchFloatTarget
+// @Failure 404 {object} map[string]string
+// @Router /branches/{id}/float-targets [get]
+func (bh *BranchHandler) GetFloatTargetsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok || user.TenantID == nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	branchID, _, err := parseBranchFloatTargetIDs(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid branch ID")
+		return
+	}
+
+	targets, err := bh.BranchService.GetFloatTargets(branchID, *user.TenantID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, targets)
+}
+
+// CreateFloatTargetHandler sets a branch's cash float target for a currency
+// @Summary Create branch float target
+// @Description Set the cash float a branch aims to hold in a currency (requires tenant_owner or tenant_admin role)
+// @Tags branches
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param request body services.FloatTargetRequest true "Float target"
+// @Success 201 {object} models.BranchFloatTarget
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /branches/{id}/float-targets [post]
+func (bh *BranchHandler) CreateFloatTargetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok || user.TenantID == nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if !canManageFloatTargets(user) {
+		respondWithError(w, http.StatusForbidden, "Only organization owners and admins can set float targets")
+		return
+	}
+
+	branchID, _, err := parseBranchFloatTargetIDs(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid branch ID")
+		return
+	}
+
+	var req services.FloatTargetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	target, err := bh.BranchService.CreateFloatTarget(branchID, *user.TenantID, req, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, target)
+}
+
+// UpdateFloatTargetHandler changes a branch's cash float target
+// @Summary Update branch float target
+// @Description Change a float target's amount or band (requires tenant_owner or tenant_admin role)
+// @Tags branches
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Param targetId path int true "Float target ID"
+// @Param request body services.FloatTargetRequest true "Float target"
+// @Success 200 {object} models.BranchFloatTarget
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /branches/{id}/float-targets/{targetId} [put]
+func (bh *BranchHandler) UpdateFloatTargetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok || user.TenantID == nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if !canManageFloatTargets(user) {
+		respondWithError(w, http.StatusForbidden, "Only organization owners and admins can change float targets")
+		return
+	}
+
+	branchID, targetID, err := parseBranchFloatTargetIDs(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid branch or float target ID")
+		return
+	}
+
+	var req services.FloatTargetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	target, err := bh.BranchService.UpdateFloatTarget(branchID, *user.TenantID, targetID, req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, target)
+}
+
+// DeleteFloatTargetHandler removes a branch's cash float target
+// @Summary Delete branch float target
+// @Description Remove a float target (requires tenant_owner or tenant_admin role)
+// @Tags branches
+// @Param id path int true "Branch ID"
+// @Param targetId path int true "Float target ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /branches/{id}/float-targets/{targetId} [delete]
+func (bh *BranchHandler) DeleteFloatTargetHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value("user").(*models.User)
+	if !ok || user.TenantID == nil {
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if !canManageFloatTargets(user) {
+		respondWithError(w, http.StatusForbidden, "Only organization owners and admins can delete float targets")
+		return
+	}
+
+	branchID, targetID, err := parseBranchFloatTargetIDs(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid branch or float target ID")
+		return
+	}
+
+	if err := bh.BranchService.DeleteFloatTarget(branchID, *user.TenantID, targetID); err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Float target deleted successfully"})
+}
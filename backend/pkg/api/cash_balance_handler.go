@@ -5,6 +5,8 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -143,7 +145,121 @@ func (h *CashBalanceHandler) CreateAdjustmentHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, adjustment)
+	// Adjustments above the approval limit are held until a second approver decides them
+	status := http.StatusCreated
+	if adjustment.Status == models.CashAdjustmentStatusPending {
+		status = http.StatusAccepted
+	}
+	respondJSON(w, status, adjustment)
+}
+
+// GetPendingAdjustmentsHandler lists cash adjustments waiting for approval
+// GET /cash-balances/adjustments/pending?branch_id=1
+func (h *CashBalanceHandler) GetPendingAdjustmentsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var branchID *uint
+	if branchIDStr := r.URL.Query().Get("branch_id"); branchIDStr != "" {
+		if id, err := strconv.ParseUint(branchIDStr, 10, 64); err == nil {
+			branchIDUint := uint(id)
+			branchID = &branchIDUint
+		}
+	}
+
+	adjustments, err := h.CashBalanceService.GetPendingAdjustments(*tenantID, branchID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, adjustments)
+}
+
+// ApproveAdjustmentHandler applies a pending cash adjustment
+// POST /cash-balances/adjustments/:id/approve
+func (h *CashBalanceHandler) ApproveAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid adjustment ID", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.CashBalanceService.ApproveAdjustment(*tenantID, uint(id), user)
+	if err != nil {
+		http.Error(w, err.Error(), cashAdjustmentDecisionStatus(err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, adjustment)
+}
+
+// RejectAdjustmentHandler declines a pending cash adjustment; the note is optional
+// POST /cash-balances/adjustments/:id/reject
+func (h *CashBalanceHandler) RejectAdjustmentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid adjustment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	adjustment, err := h.CashBalanceService.RejectAdjustment(*tenantID, uint(id), user, req.Note)
+	if err != nil {
+		http.Error(w, err.Error(), cashAdjustmentDecisionStatus(err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, adjustment)
+}
+
+// cashAdjustmentDecisionStatus maps an approve or reject error to its HTTP status
+func cashAdjustmentDecisionStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCashAdjustmentApprovalForbidden), errors.Is(err, services.ErrCashAdjustmentSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
 }
 
 // GetAdjustmentHistoryHandler retrieves adjustment history
@@ -242,3 +358,232 @@ func (h *CashBalanceHandler) GetActiveCurrenciesHandler(w http.ResponseWriter, r
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{"currencies": currencies})
 }
+
+// GetDenominationsHandler retrieves the denomination breakdown for a currency balance
+// GET /cash-balances/:currency/denominations?branch_id=1
+func (h *CashBalanceHandler) GetDenominationsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	currency := mux.Vars(r)["currency"]
+	if currency == "" {
+		http.Error(w, "Currency is required", http.StatusBadRequest)
+		return
+	}
+
+	var branchID *uint
+	if branchIDStr := r.URL.Query().Get("branch_id"); branchIDStr != "" {
+		if id, err := strconv.ParseUint(branchIDStr, 10, 64); err == nil {
+			branchIDUint := uint(id)
+			branchID = &branchIDUint
+		}
+	}
+
+	breakdown, err := h.CashBalanceService.GetBalanceDenominations(*tenantID, branchID, currency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, breakdown)
+}
+
+// SetDenominationsHandler replaces the denomination breakdown for a currency balance
+// PUT /cash-balances/:currency/denominations
+func (h *CashBalanceHandler) SetDenominationsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	currency := mux.Vars(r)["currency"]
+	if currency == "" {
+		http.Error(w, "Currency is required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		BranchID      *uint                        `json:"branchId"`
+		Denominations []services.DenominationCount `json:"denominations"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	breakdown, err := h.CashBalanceService.SetBalanceDenominations(*tenantID, req.BranchID, currency, req.Denominations, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, breakdown)
+}
+
+// GetThresholdsHandler lists the tenant's minimum cash balance per currency
+// GET /cash-balances/thresholds
+func (h *CashBalanceHandler) GetThresholdsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	thresholds, err := h.CashBalanceService.GetThresholds(*tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, thresholds)
+}
+
+// SetThresholdHandler sets the minimum cash balance for a currency; the dashboard warns below it
+// PUT /cash-balances/thresholds/:currency
+func (h *CashBalanceHandler) SetThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	var req struct {
+		MinimumBalance float64 `json:"minimumBalance"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	threshold, err := h.CashBalanceService.SetThreshold(*tenantID, mux.Vars(r)["currency"], req.MinimumBalance, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, threshold)
+}
+
+// DeleteThresholdHandler removes a currency's minimum cash balance
+// DELETE /cash-balances/thresholds/:currency
+func (h *CashBalanceHandler) DeleteThresholdHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.CashBalanceService.DeleteThreshold(*tenantID, mux.Vars(r)["currency"]); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetApprovalLimitsHandler lists the tenant's cash adjustment approval limit per currency
+// GET /cash-balances/approval-limits
+func (h *CashBalanceHandler) GetApprovalLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	limits, err := h.CashBalanceService.GetApprovalLimits(*tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limits)
+}
+
+// SetApprovalLimitHandler sets the largest adjustment in a currency that applies without a second approver
+// PUT /cash-balances/approval-limits/:currency
+func (h *CashBalanceHandler) SetApprovalLimitHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	var req struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit, err := h.CashBalanceService.SetApprovalLimit(*tenantID, mux.Vars(r)["currency"], req.Amount, user)
+	if errors.Is(err, services.ErrApprovalLimitForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, limit)
+}
+
+// DeleteApprovalLimitHandler removes a currency's approval limit so its adjustments apply at once
+// DELETE /cash-balances/approval-limits/:currency
+func (h *CashBalanceHandler) DeleteApprovalLimitHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	err := h.CashBalanceService.DeleteApprovalLimit(*tenantID, mux.Vars(r)["currency"], user)
+	if errors.Is(err, services.ErrApprovalLimitForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, services.ErrApprovalLimitNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
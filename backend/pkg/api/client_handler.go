@@ -3,7 +3,9 @@ package api
 import (
 	"api/pkg/middleware"
 	"api/pkg/models"
+	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -108,6 +110,10 @@ func (h *Handler) GetClient(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Client not found", http.StatusNotFound)
 		return
 	}
+
+	if user, ok := middleware.GetUserFromContext(r); ok {
+		h.auditService.LogRead(client.TenantID, user.ID, services.AuditEntityClient, client.ID, r)
+	}
 	respondJSON(w, http.StatusOK, client)
 }
 
@@ -225,3 +231,49 @@ func (h *Handler) SearchClients(w http.ResponseWriter, r *http.Request) {
 	}
 	respondJSON(w, http.StatusOK, clients)
 }
+
+// ImportClients godoc
+// @Summary Import clients from CSV
+// @Description Validate a CSV of clients (name, phone, email, join date) and save the valid rows. With dryRun=true nothing is saved and the per-row outcome is only reported.
+// @Tags clients
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file with a header row"
+// @Param dryRun query bool false "Validate without saving"
+// @Success 200 {object} services.ClientImportResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /clients/import [post]
+func (h *Handler) ImportClients(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "File too large or invalid form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	dryRun := r.FormValue("dryRun") == "true"
+	result, err := h.clientService.ImportClientsCSV(*tenantID, file, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrClientImportHeader) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
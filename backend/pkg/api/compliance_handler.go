@@ -5,12 +5,15 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -20,18 +23,24 @@ import (
 // ComplianceHandler handles compliance-related API endpoints
 type ComplianceHandler struct {
 	complianceService    *services.ComplianceService
+	volumeAlertService   *services.VolumeAlertService
 	verificationProvider services.VerificationProvider
+	screeningProvider    services.ScreeningProvider
+	uploadValidator      *services.DocumentUploadValidator
 	db                   *gorm.DB
 	uploadDir            string
 }
 
 // NewComplianceHandler creates a new compliance handler
 func NewComplianceHandler(db *gorm.DB) *ComplianceHandler {
-	// Use Sumsub if configured, otherwise mock
+	// Use Sumsub if configured, otherwise mock. Name screening has no mock fallback: without a
+	// configured provider it is unavailable rather than reporting every customer clear.
 	var provider services.VerificationProvider
+	var screeningProvider services.ScreeningProvider
 	sumsub := services.NewSumsubProvider()
 	if sumsub.IsConfigured() {
 		provider = sumsub
+		screeningProvider = sumsub
 	} else {
 		provider = services.NewMockVerificationProvider()
 	}
@@ -46,7 +55,10 @@ func NewComplianceHandler(db *gorm.DB) *ComplianceHandler {
 
 	return &ComplianceHandler{
 		complianceService:    services.NewComplianceService(db),
+		volumeAlertService:   services.NewVolumeAlertService(db),
 		verificationProvider: provider,
+		screeningProvider:    screeningProvider,
+		uploadValidator:      services.NewDocumentUploadValidator(),
 		db:                   db,
 		uploadDir:            uploadDir,
 	}
@@ -62,7 +74,8 @@ func NewComplianceHandler(db *gorm.DB) *ComplianceHandler {
 // @Router /compliance/customer/{customerId} [get]
 func (h *ComplianceHandler) GetCustomerComplianceHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
-	if tenantID == nil {
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -74,7 +87,7 @@ func (h *ComplianceHandler) GetCustomerComplianceHandler(w http.ResponseWriter,
 		return
 	}
 
-	compliance, err := h.complianceService.GetOrCreateCompliance(*tenantID, uint(customerID))
+	compliance, err := h.complianceService.ViewCompliance(*tenantID, uint(customerID), user.ID, r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -180,7 +193,8 @@ func (h *ComplianceHandler) UploadDocumentHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Parse multipart form (max 10MB)
+	// Bound the whole request by the largest per-type limit plus room for the form fields
+	r.Body = http.MaxBytesReader(w, r.Body, h.uploadValidator.LargestMaxBytes()+(1<<20))
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
 		http.Error(w, "File too large or invalid form", http.StatusBadRequest)
 		return
@@ -199,35 +213,20 @@ func (h *ComplianceHandler) UploadDocumentHandler(w http.ResponseWriter, r *http
 	}
 	defer file.Close()
 
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg":      true,
-		"image/png":       true,
-		"image/gif":       true,
-		"application/pdf": true,
-	}
-	contentType := header.Header.Get("Content-Type")
-	if !allowedTypes[contentType] {
-		http.Error(w, "Invalid file type. Allowed: JPEG, PNG, GIF, PDF", http.StatusBadRequest)
-		return
-	}
-
-	// Generate unique filename
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("%d_%d_%s_%d%s", *tenantID, complianceID, docType, time.Now().UnixNano(), ext)
-	filePath := filepath.Join(h.uploadDir, filename)
-
-	// Create destination file
-	dst, err := os.Create(filePath)
+	// The stored name and type come from the sniffed content, never the client's filename or header
+	baseName := fmt.Sprintf("%d_%d_%s_%d", *tenantID, complianceID, filepath.Base(docType), time.Now().UnixNano())
+	filePath, contentType, size, err := h.uploadValidator.Save(docType, header.Header.Get("Content-Type"), file, filepath.Join(h.uploadDir, baseName))
 	if err != nil {
-		http.Error(w, "Failed to save file", http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
-
-	// Copy file
-	if _, err := io.Copy(dst, file); err != nil {
-		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, services.ErrDocumentTypeNotAllowed), errors.Is(err, services.ErrDocumentTypeMismatch):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, services.ErrDocumentTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, services.ErrDocumentRejectedByScan):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -238,7 +237,7 @@ func (h *ComplianceHandler) UploadDocumentHandler(w http.ResponseWriter, r *http
 		docType,
 		header.Filename,
 		filePath,
-		header.Size,
+		size,
 		contentType,
 	)
 	if err != nil {
@@ -248,6 +247,13 @@ func (h *ComplianceHandler) UploadDocumentHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Reviews of customers onboarded at a branch go to that branch's compliance officer
+	if user, ok := middleware.GetUserFromContext(r); ok && user.PrimaryBranchID != nil {
+		if err := h.complianceService.RouteToBranch(*tenantID, uint(complianceID), *user.PrimaryBranchID, false); err != nil {
+			log.Printf("⚠️  Failed to route compliance %d to branch %d: %v", complianceID, *user.PrimaryBranchID, err)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(doc)
 }
@@ -281,6 +287,69 @@ func (h *ComplianceHandler) GetDocumentsHandler(w http.ResponseWriter, r *http.R
 	json.NewEncoder(w).Encode(docs)
 }
 
+// DownloadDocumentHandler streams one of the tenant's compliance documents
+// @Summary Download compliance document
+// @Tags Compliance
+// @Produce octet-stream
+// @Param docId path int true "Document ID"
+// @Router /compliance/documents/{docId}/download [get]
+func (h *ComplianceHandler) DownloadDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	docID, err := strconv.ParseUint(mux.Vars(r)["docId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	// Another tenant's document gets the same 404 as a missing one
+	doc, err := h.complianceService.GetDocument(*tenantID, uint(docID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to load document", http.StatusInternalServerError)
+		return
+	}
+
+	file, err := os.Open(doc.FilePath)
+	if err != nil {
+		log.Printf("⚠️  Compliance document %d is missing from storage: %v", doc.ID, err)
+		http.Error(w, "Document not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Failed to read document", http.StatusInternalServerError)
+		return
+	}
+
+	// Name the download after the original file but with the extension of the stored, sniffed type
+	name := strings.TrimSuffix(filepath.Base(doc.FileName), filepath.Ext(doc.FileName))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = strings.ToLower(doc.DocumentType)
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name + filepath.Ext(doc.FilePath)})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	contentType := doc.MimeType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, "", info.ModTime(), file)
+}
+
 // ReviewDocumentHandler reviews an uploaded document
 // @Summary Review compliance document
 // @Tags Compliance
@@ -368,14 +437,18 @@ func (h *ComplianceHandler) SetTransactionLimitsHandler(w http.ResponseWriter, r
 	json.NewEncoder(w).Encode(map[string]string{"message": "Limits updated successfully"})
 }
 
-// GetPendingReviewsHandler retrieves compliance records pending review
+// GetPendingReviewsHandler retrieves compliance records pending review. A branch compliance officer only
+// sees the reviews routed to their branches.
 // @Summary Get pending compliance reviews
 // @Tags Compliance
 // @Produce json
+// @Param branchId query int false "Only reviews routed to this branch"
+// @Failure 403 {string} string "Not the compliance officer for this branch"
 // @Router /compliance/pending [get]
 func (h *ComplianceHandler) GetPendingReviewsHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
-	if tenantID == nil {
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -387,7 +460,28 @@ func (h *ComplianceHandler) GetPendingReviewsHandler(w http.ResponseWriter, r *h
 		}
 	}
 
-	records, err := h.complianceService.GetPendingReviews(*tenantID, limit)
+	var requested *uint
+	if branchIDStr := r.URL.Query().Get("branchId"); branchIDStr != "" {
+		branchID, err := strconv.ParseUint(branchIDStr, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+			return
+		}
+		id := uint(branchID)
+		requested = &id
+	}
+
+	branchIDs, err := h.complianceService.ReviewBranches(*tenantID, user, requested)
+	if err != nil {
+		if errors.Is(err, services.ErrNotBranchComplianceOfficer) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	records, err := h.complianceService.GetPendingReviews(*tenantID, branchIDs, limit)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -397,6 +491,68 @@ func (h *ComplianceHandler) GetPendingReviewsHandler(w http.ResponseWriter, r *h
 	json.NewEncoder(w).Encode(records)
 }
 
+// GetAlertsHandler lists compliance alerts such as customer volume spikes
+// @Summary Get compliance alerts
+// @Tags Compliance
+// @Produce json
+// @Param status query string false "OPEN or RESOLVED"
+// @Router /compliance/alerts [get]
+func (h *ComplianceHandler) GetAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	alerts, err := h.volumeAlertService.GetAlerts(*tenantID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// ResolveAlertHandler closes a compliance alert after review
+// @Summary Resolve compliance alert
+// @Tags Compliance
+// @Produce json
+// @Router /compliance/alerts/{alertId}/resolve [put]
+func (h *ComplianceHandler) ResolveAlertHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	alertID, err := strconv.ParseUint(mux.Vars(r)["alertId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid alert ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.volumeAlertService.ResolveAlert(*tenantID, uint(alertID), user.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Open alert not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Alert resolved"})
+}
+
 // GetExpiringComplianceHandler retrieves expiring compliance records
 // @Summary Get expiring compliance records
 // @Tags Compliance
@@ -519,6 +675,67 @@ func (h *ComplianceHandler) InitiateVerificationHandler(w http.ResponseWriter, r
 	})
 }
 
+// ScreenCustomerHandler screens the customer's name against sanctions and PEP lists
+// @Summary Screen customer against sanctions/PEP lists
+// @Description Records the screening result on the compliance record; a potential match moves it to IN_REVIEW
+// @Tags Compliance
+// @Accept json
+// @Produce json
+// @Param id path int true "Compliance ID"
+// @Success 200 {object} services.ScreeningResult
+// @Failure 403 {string} string "Only compliance officers, owners and admins can screen customers"
+// @Failure 503 {string} string "Screening provider not configured"
+// @Router /compliance/{id}/screen [post]
+func (h *ComplianceHandler) ScreenCustomerHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	reviewer, err := h.complianceService.IsComplianceReviewer(*tenantID, user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !reviewer {
+		http.Error(w, "Only compliance officers, owners and admins can screen customers", http.StatusForbidden)
+		return
+	}
+	if h.screeningProvider == nil {
+		http.Error(w, "Screening provider not configured", http.StatusServiceUnavailable)
+		return
+	}
+	complianceID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid compliance ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DateOfBirth string `json:"dateOfBirth"` // Optional, YYYY-MM-DD
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := h.complianceService.ScreenCustomer(*tenantID, uint(complianceID), req.DateOfBirth, h.screeningProvider, &user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Compliance record not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // GetVerificationStatusHandler retrieves external verification status
 // @Summary Get external verification status
 // @Tags Compliance
@@ -572,3 +789,196 @@ func (h *ComplianceHandler) GetVerificationStatusHandler(w http.ResponseWriter,
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(status)
 }
+
+// AssignComplianceOfficerHandler designates the user who reviews KYC for a branch (owner/admin only)
+// @Summary Assign branch compliance officer
+// @Tags Compliance
+// @Accept json
+// @Produce json
+// @Param branchId path int true "Branch ID"
+// @Success 200 {object} models.Branch
+// @Router /compliance/branches/{branchId}/officer [put]
+func (h *ComplianceHandler) AssignComplianceOfficerHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can assign compliance officers", http.StatusForbidden)
+		return
+	}
+
+	branchID, err := strconv.ParseUint(mux.Vars(r)["branchId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OfficerID *uint `json:"officerId"` // null clears the assignment
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	branch, err := h.complianceService.AssignComplianceOfficer(*tenantID, uint(branchID), req.OfficerID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(branch)
+}
+
+// RouteComplianceHandler routes a customer's compliance reviews to a branch's compliance officer
+// @Summary Route compliance record to a branch
+// @Tags Compliance
+// @Accept json
+// @Produce json
+// @Param id path int true "Compliance ID"
+// @Failure 403 {string} string "Only compliance officers, owners and admins can route compliance reviews"
+// @Failure 404 {string} string "Compliance record not found"
+// @Router /compliance/{id}/branch [put]
+func (h *ComplianceHandler) RouteComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	reviewer, err := h.complianceService.IsComplianceReviewer(*tenantID, user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !reviewer {
+		http.Error(w, "Only compliance officers, owners and admins can route compliance reviews", http.StatusForbidden)
+		return
+	}
+
+	complianceID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid compliance ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		BranchID uint `json:"branchId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.BranchID == 0 {
+		http.Error(w, "branchId is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.complianceService.RouteToBranch(*tenantID, uint(complianceID), req.BranchID, true); err != nil {
+		if errors.Is(err, services.ErrComplianceRecordNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Compliance review routed"})
+}
+
+// GetStructuringRulesHandler lists the tenant's structuring detection rules
+// @Summary List structuring rules
+// @Tags Compliance
+// @Produce json
+// @Success 200 {array} models.StructuringRule
+// @Router /compliance/structuring-rules [get]
+func (h *ComplianceHandler) GetStructuringRulesHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.complianceService.ListStructuringRules(*tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load structuring rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// SetStructuringRuleHandler creates or replaces the structuring rule for a currency (owner/admin only)
+// @Summary Set structuring rule
+// @Description Flags customers whose sub-threshold transactions in the currency reach the reporting threshold within the short (hours) or long (days) window. Currency "*" covers currencies without their own rule.
+// @Tags Compliance
+// @Accept json
+// @Produce json
+// @Param request body services.StructuringRuleRequest true "Structuring rule"
+// @Success 200 {object} models.StructuringRule
+// @Router /compliance/structuring-rules [put]
+func (h *ComplianceHandler) SetStructuringRuleHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can change structuring rules", http.StatusForbidden)
+		return
+	}
+
+	var req services.StructuringRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.complianceService.SetStructuringRule(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteStructuringRuleHandler removes a structuring rule (owner/admin only)
+// @Summary Delete structuring rule
+// @Tags Compliance
+// @Param ruleId path int true "Rule ID"
+// @Success 204
+// @Router /compliance/structuring-rules/{ruleId} [delete]
+func (h *ComplianceHandler) DeleteStructuringRuleHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can change structuring rules", http.StatusForbidden)
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["ruleId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.complianceService.DeleteStructuringRule(*tenantID, uint(ruleID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Structuring rule not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
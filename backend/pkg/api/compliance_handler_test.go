@@ -0,0 +1,159 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// TestDownloadDocument_ScopedToTenant verifies a tenant can download its own compliance document and
+// another tenant gets a 404 rather than a hint that the document exists
+func TestDownloadDocument_ScopedToTenant(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.CustomerCompliance{}, &models.ComplianceDocument{})
+
+	owner := &models.Tenant{Name: "Owner Exchange"}
+	db.Create(owner)
+	other := &models.Tenant{Name: "Other Exchange"}
+	db.Create(other)
+
+	uploadDir := t.TempDir()
+	t.Setenv("COMPLIANCE_UPLOAD_DIR", uploadDir)
+	storedPath := filepath.Join(uploadDir, "1_1_ID_FRONT_1.png")
+	content := []byte("\x89PNG\r\n\x1a\nfake image body")
+	if err := os.WriteFile(storedPath, content, 0644); err != nil {
+		t.Fatalf("Failed to write document: %v", err)
+	}
+	doc := &models.ComplianceDocument{
+		CustomerComplianceID: 1,
+		TenantID:             owner.ID,
+		DocumentType:         "ID_FRONT",
+		FileName:             "passport scan.jpg",
+		FilePath:             storedPath,
+		FileSize:             int64(len(content)),
+		MimeType:             "image/png",
+	}
+	db.Create(doc)
+
+	handler := NewComplianceHandler(db)
+	download := func(tenantID uint) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/compliance/documents/1/download", nil)
+		req = mux.SetURLVars(req, map[string]string{"docId": "1"})
+		req = req.WithContext(context.WithValue(req.Context(), "tenantId", &tenantID))
+		rr := httptest.NewRecorder()
+		handler.DownloadDocumentHandler(rr, req)
+		return rr
+	}
+
+	rr := download(owner.ID)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for the owning tenant, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Expected Content-Type image/png, got %q", got)
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="passport scan.png"` {
+		t.Errorf("Expected the original name with the stored extension, got %q", got)
+	}
+	if rr.Body.String() != string(content) {
+		t.Errorf("Expected the stored file body, got %q", rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String()+strings.Join(rr.Header().Values("Content-Disposition"), ""), uploadDir) {
+		t.Error("Expected the storage path not to be exposed")
+	}
+
+	if rr := download(other.ID); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another tenant, got %d", rr.Code)
+	}
+}
+
+// TestScreenCustomerHandler_RequiresReviewerAndProvider verifies tellers cannot screen customers and that
+// screening is unavailable rather than mocked when no provider is configured
+func TestScreenCustomerHandler_RequiresReviewerAndProvider(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.CustomerCompliance{})
+	t.Setenv("SUMSUB_APP_TOKEN", "")
+	t.Setenv("SUMSUB_SECRET_KEY", "")
+	t.Setenv("COMPLIANCE_UPLOAD_DIR", t.TempDir())
+
+	tenant := &models.Tenant{Name: "Screening Exchange"}
+	db.Create(tenant)
+	teller := &models.User{Email: "teller@example.com", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(teller)
+	admin := &models.User{Email: "admin@example.com", TenantID: &tenant.ID, Role: models.RoleTenantAdmin}
+	db.Create(admin)
+
+	handler := NewComplianceHandler(db)
+	screen := func(user *models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/compliance/1/screen", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		ctx := context.WithValue(req.Context(), "tenantId", &tenant.ID)
+		req = req.WithContext(context.WithValue(ctx, middleware.UserContextKey, user))
+		rr := httptest.NewRecorder()
+		handler.ScreenCustomerHandler(rr, req)
+		return rr
+	}
+
+	if rr := screen(teller); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a teller, got %d", rr.Code)
+	}
+	if rr := screen(admin); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a screening provider, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// TestRouteComplianceHandler_RequiresReviewerAndRecord verifies tellers cannot route compliance reviews and
+// that routing a record missing from the tenant returns 404
+func TestRouteComplianceHandler_RequiresReviewerAndRecord(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.CustomerCompliance{})
+	t.Setenv("COMPLIANCE_UPLOAD_DIR", t.TempDir())
+
+	tenant := &models.Tenant{Name: "Routing Exchange"}
+	db.Create(tenant)
+	other := &models.Tenant{Name: "Other Exchange"}
+	db.Create(other)
+	teller := &models.User{Email: "teller@example.com", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(teller)
+	admin := &models.User{Email: "admin@example.com", TenantID: &tenant.ID, Role: models.RoleTenantAdmin}
+	db.Create(admin)
+	branch := &models.Branch{TenantID: tenant.ID, Name: "Downtown", BranchCode: "DT"}
+	db.Create(branch)
+	record := &models.CustomerCompliance{TenantID: tenant.ID, CustomerID: 1, Status: models.ComplianceStatusPending}
+	db.Create(record)
+	foreign := &models.CustomerCompliance{TenantID: other.ID, CustomerID: 2, Status: models.ComplianceStatusPending}
+	db.Create(foreign)
+
+	handler := NewComplianceHandler(db)
+	route := func(user *models.User, complianceID uint) *httptest.ResponseRecorder {
+		body := strings.NewReader(fmt.Sprintf(`{"branchId": %d}`, branch.ID))
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/compliance/%d/branch", complianceID), body)
+		req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(complianceID)})
+		ctx := context.WithValue(req.Context(), "tenantId", &tenant.ID)
+		req = req.WithContext(context.WithValue(ctx, middleware.UserContextKey, user))
+		rr := httptest.NewRecorder()
+		handler.RouteComplianceHandler(rr, req)
+		return rr
+	}
+
+	if rr := route(teller, record.ID); rr.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a teller, got %d", rr.Code)
+	}
+	if rr := route(admin, foreign.ID); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another tenant's record, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := route(admin, record.ID); rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for an admin, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var routed models.CustomerCompliance
+	db.First(&routed, record.ID)
+	if routed.BranchID == nil || *routed.BranchID != branch.ID {
+		t.Errorf("Expected the record to be routed to branch %d, got %v", branch.ID, routed.BranchID)
+	}
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"api/pkg/middleware"
+	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -13,11 +15,15 @@ import (
 
 type CustomerHandler struct {
 	CustomerService *services.CustomerService
+	CampaignService *services.CustomerCampaignService
+	auditService    *services.AuditService
 }
 
 func NewCustomerHandler(db *gorm.DB) *CustomerHandler {
 	return &CustomerHandler{
 		CustomerService: services.NewCustomerService(db),
+		CampaignService: services.NewCustomerCampaignService(db),
+		auditService:    services.NewAuditService(db),
 	}
 }
 
@@ -77,6 +83,7 @@ func (h *CustomerHandler) FindOrCreateCustomerHandler(w http.ResponseWriter, r *
 		Phone    string  `json:"phone"`
 		FullName string  `json:"fullName"`
 		Email    *string `json:"email"`
+		IDNumber *string `json:"idNumber"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -85,8 +92,16 @@ func (h *CustomerHandler) FindOrCreateCustomerHandler(w http.ResponseWriter, r *
 	}
 
 	// Find or create customer
-	customer, err := h.CustomerService.FindOrCreateCustomer(req.Phone, req.FullName, req.Email)
+	customer, err := h.CustomerService.FindOrCreateCustomer(*tenantID, req.Phone, req.FullName, req.Email, req.IDNumber)
 	if err != nil {
+		var missing *services.MissingCustomerFieldError
+		if errors.As(err, &missing) {
+			respondJSON(w, http.StatusBadRequest, map[string]string{
+				"error":        err.Error(),
+				"missingField": missing.Field,
+			})
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -101,7 +116,7 @@ func (h *CustomerHandler) FindOrCreateCustomerHandler(w http.ResponseWriter, r *
 }
 
 // GetCustomersForTenantHandler retrieves all customers for the current tenant
-// GET /customers
+// GET /customers?tag=vip
 func (h *CustomerHandler) GetCustomersForTenantHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
 	if tenantID == nil {
@@ -109,7 +124,7 @@ func (h *CustomerHandler) GetCustomersForTenantHandler(w http.ResponseWriter, r
 		return
 	}
 
-	customers, err := h.CustomerService.GetCustomersForTenant(*tenantID)
+	customers, err := h.CustomerService.GetCustomersForTenant(*tenantID, r.URL.Query().Get("tag"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -147,6 +162,130 @@ func (h *CustomerHandler) UpdateCustomerHandler(w http.ResponseWriter, r *http.R
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Customer updated successfully"})
 }
 
+// SetCustomerTagsHandler replaces the tenant's tags on a customer
+// PUT /customers/:id/tags
+func (h *CustomerHandler) SetCustomerTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.CustomerService.SetCustomerTags(uint(id), *tenantID, req.Tags)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Customer not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, link)
+}
+
+// UpdateCustomerConsentHandler records a customer's messaging consent and opt-outs
+// PUT /customers/:id/consent
+func (h *CustomerHandler) UpdateCustomerConsentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	var req services.CustomerConsentUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	link, err := h.CustomerService.UpdateConsent(uint(id), *tenantID, req)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Customer not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, link)
+}
+
+// SendCustomerMessageHandler sends an email or SMS to every consenting customer carrying a tag
+// POST /customers/message
+func (h *CustomerHandler) SendCustomerMessageHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+	user := r.Context().Value("user").(*models.User)
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can message customers", http.StatusForbidden)
+		return
+	}
+
+	var req services.CustomerMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	campaign, err := h.CampaignService.SendToSegment(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionSendCustomerCampaign, "CustomerCampaign", strconv.FormatUint(uint64(campaign.ID), 10),
+		"Sent "+campaign.Channel+" campaign to tag "+campaign.Tag,
+		nil, map[string]interface{}{
+			"matched": campaign.MatchedCount,
+			"sent":    campaign.SentCount,
+			"skipped": campaign.SkippedCount,
+			"failed":  campaign.FailedCount,
+		}, r)
+
+	respondJSON(w, http.StatusOK, campaign)
+}
+
+// GetCustomerCampaignsHandler lists the tenant's customer campaigns
+// GET /customers/campaigns
+func (h *CustomerHandler) GetCustomerCampaignsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	campaigns, err := h.CampaignService.GetCampaigns(*tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, campaigns)
+}
+
 // ============ SUPER ADMIN ROUTES ============
 
 // SearchCustomersGlobalHandler searches customers across all tenants (SuperAdmin only)
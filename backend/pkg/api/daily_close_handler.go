@@ -0,0 +1,150 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DailyCloseHandler struct {
+	DailyCloseService *services.DailyCloseService
+}
+
+func NewDailyCloseHandler(db *gorm.DB) *DailyCloseHandler {
+	return &DailyCloseHandler{
+		DailyCloseService: services.NewDailyCloseService(db),
+	}
+}
+
+// dailyCloseRequest identifies the branch and business day to close or reopen
+type dailyCloseRequest struct {
+	BranchID *uint  `json:"branchId"`
+	Date     string `json:"date"` // YYYY-MM-DD
+	Reason   string `json:"reason"`
+}
+
+// CloseDayHandler snapshots every currency's balance and closes the day for the branch
+// POST /cash-balances/close
+func (h *DailyCloseHandler) CloseDayHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	var req dailyCloseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	dailyClose, err := h.DailyCloseService.CloseDay(*tenantID, req.BranchID, date, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), dailyCloseErrorStatus(err))
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, dailyClose)
+}
+
+// ReopenDayHandler reopens a closed day so postings into it are accepted again; a reason is required
+// POST /cash-balances/reopen
+func (h *DailyCloseHandler) ReopenDayHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	var req dailyCloseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	dailyClose, err := h.DailyCloseService.ReopenDay(*tenantID, req.BranchID, date, user, req.Reason)
+	if err != nil {
+		http.Error(w, err.Error(), dailyCloseErrorStatus(err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dailyClose)
+}
+
+// GetClosesHandler lists day closes with their balance snapshots
+// GET /cash-balances/closes?branch_id=1&startDate=2024-01-01&endDate=2024-01-31
+func (h *DailyCloseHandler) GetClosesHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var branchID *uint
+	if branchIDStr := r.URL.Query().Get("branch_id"); branchIDStr != "" {
+		if id, err := strconv.ParseUint(branchIDStr, 10, 64); err == nil {
+			branchIDUint := uint(id)
+			branchID = &branchIDUint
+		}
+	}
+
+	var startDate, endDate *time.Time
+	if parsed, err := time.Parse("2006-01-02", r.URL.Query().Get("startDate")); err == nil {
+		startDate = &parsed
+	}
+	if parsed, err := time.Parse("2006-01-02", r.URL.Query().Get("endDate")); err == nil {
+		endDate = &parsed
+	}
+
+	closes, err := h.DailyCloseService.GetCloses(*tenantID, branchID, startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, closes)
+}
+
+// dailyCloseErrorStatus maps a close or reopen error to its HTTP status
+func dailyCloseErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrDayReopenForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrDailyCloseBranchNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrDayAlreadyClosed), errors.Is(err, services.ErrDayNotClosed):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
@@ -30,6 +30,7 @@ func NewDashboardHandler(db *gorm.DB) *DashboardHandler {
 // @Produce json
 // @Security BearerAuth
 // @Param branchId query int false "Branch ID (optional)"
+// @Param forceRefresh query bool false "Bypass the cached dashboard"
 // @Success 200 {object} services.DashboardData
 // @Router /dashboard [get]
 func (h *DashboardHandler) GetDashboardHandler(w http.ResponseWriter, r *http.Request) {
@@ -48,7 +49,9 @@ func (h *DashboardHandler) GetDashboardHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	data, err := h.dashboardService.GetDashboardData(*tenantID, branchID)
+	forceRefresh, _ := strconv.ParseBool(r.URL.Query().Get("forceRefresh"))
+
+	data, err := h.dashboardService.GetCachedDashboardData(*tenantID, branchID, forceRefresh)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -93,3 +96,96 @@ func (h *DashboardHandler) GetDashboardSummaryHandler(w http.ResponseWriter, r *
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// GetDebtAgingBucketsHandler returns the tenant's debt aging buckets
+// @Summary Get debt aging buckets
+// @Description Returns the age bands the dashboard groups unsettled remittances into
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DebtAgingBucket
+// @Router /dashboard/debt-aging-buckets [get]
+func (h *DashboardHandler) GetDebtAgingBucketsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, h.dashboardService.GetDebtAgingBuckets(*tenantID))
+}
+
+// SetDebtAgingBucketsHandler replaces the tenant's debt aging buckets
+// @Summary Set debt aging buckets
+// @Description Replaces the age bands, youngest first; the last bucket must be open-ended. An empty list restores the defaults.
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DebtAgingBucket
+// @Router /dashboard/debt-aging-buckets [put]
+func (h *DashboardHandler) SetDebtAgingBucketsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Buckets []services.DebtAgingBucketInput `json:"buckets"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	buckets, err := h.dashboardService.SetDebtAgingBuckets(*tenantID, req.Buckets)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, buckets)
+}
+
+// GetCashFlowForecastHandler projects expected cash in and out over the coming days
+// @Summary Get cash flow forecast
+// @Description Projects daily expected inflows (incoming remittances, installments) and outflows (outgoing remittances) in the tenant's base currency
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param branchId query int false "Branch ID (optional)"
+// @Param days query int false "Forecast horizon in days (default 30, max 365)"
+// @Success 200 {object} services.CashFlowForecast
+// @Router /dashboard/cash-flow-forecast [get]
+func (h *DashboardHandler) GetCashFlowForecastHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var branchID *uint
+	if branchIDStr := r.URL.Query().Get("branchId"); branchIDStr != "" {
+		id, err := strconv.ParseUint(branchIDStr, 10, 64)
+		if err == nil {
+			bid := uint(id)
+			branchID = &bid
+		}
+	}
+
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		if parsed, err := strconv.Atoi(daysStr); err == nil {
+			days = parsed
+		}
+	}
+
+	forecast, err := h.dashboardService.GetCashFlowForecast(*tenantID, branchID, days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, forecast)
+}
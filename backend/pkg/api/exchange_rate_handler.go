@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type ExchangeRateHandler struct {
@@ -17,7 +18,8 @@ func NewExchangeRateHandler(service *services.ExchangeRateService) *ExchangeRate
 	return &ExchangeRateHandler{ExchangeRateService: service}
 }
 
-// GetAllRatesHandler retrieves all current exchange rates
+// GetAllRatesHandler retrieves all current exchange rates. Each rate's source shows whether it is a
+// manual override (MANUAL, with expiresAt for temporary ones) or the external rate (API)
 func (h *ExchangeRateHandler) GetAllRatesHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
 	if tenantID == nil {
@@ -66,7 +68,7 @@ func (h *ExchangeRateHandler) RefreshRatesHandler(w http.ResponseWriter, r *http
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Rates refreshed successfully"})
 }
 
-// SetManualRateHandler sets a custom exchange rate
+// SetManualRateHandler sets a custom exchange rate, optionally as an override that expires
 func (h *ExchangeRateHandler) SetManualRateHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
 	if tenantID == nil {
@@ -75,9 +77,10 @@ func (h *ExchangeRateHandler) SetManualRateHandler(w http.ResponseWriter, r *htt
 	}
 
 	var req struct {
-		BaseCurrency   string  `json:"baseCurrency"`
-		TargetCurrency string  `json:"targetCurrency"`
-		Rate           float64 `json:"rate"`
+		BaseCurrency   string     `json:"baseCurrency"`
+		TargetCurrency string     `json:"targetCurrency"`
+		Rate           float64    `json:"rate"`
+		ExpiresAt      *time.Time `json:"expiresAt"` // Optional: revert to the external rate after this time
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -89,8 +92,12 @@ func (h *ExchangeRateHandler) SetManualRateHandler(w http.ResponseWriter, r *htt
 		http.Error(w, "Invalid rate data", http.StatusBadRequest)
 		return
 	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		http.Error(w, "expiresAt must be in the future", http.StatusBadRequest)
+		return
+	}
 
-	err := h.ExchangeRateService.UpdateRate(*tenantID, req.BaseCurrency, req.TargetCurrency, req.Rate)
+	err := h.ExchangeRateService.UpdateRate(*tenantID, req.BaseCurrency, req.TargetCurrency, req.Rate, req.ExpiresAt)
 	if err != nil {
 		http.Error(w, "Failed to set rate", http.StatusInternalServerError)
 		return
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ExportProfileHandler handles saved export profile API requests
+type ExportProfileHandler struct {
+	exportProfileService   *services.ExportProfileService
+	scheduledExportService *services.ScheduledExportService
+}
+
+// NewExportProfileHandler creates a new ExportProfileHandler
+func NewExportProfileHandler(db *gorm.DB) *ExportProfileHandler {
+	return &ExportProfileHandler{
+		exportProfileService:   services.NewExportProfileService(db),
+		scheduledExportService: services.NewScheduledExportService(db),
+	}
+}
+
+// GetExportProfilesHandler lists the tenant's export profiles
+// @Summary List export profiles
+// @Tags Exports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ExportProfile
+// @Router /exports/profiles [get]
+func (h *ExportProfileHandler) GetExportProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	profiles, err := h.exportProfileService.GetProfiles(*tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load export profiles", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, profiles)
+}
+
+// CreateExportProfileHandler saves a new export profile
+// @Summary Create export profile
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.ExportProfileRequest true "Export profile"
+// @Success 201 {object} models.ExportProfile
+// @Router /exports/profiles [post]
+func (h *ExportProfileHandler) CreateExportProfileHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req services.ExportProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.exportProfileService.CreateProfile(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusCreated, profile)
+}
+
+// UpdateExportProfileHandler replaces an export profile's configuration
+// @Summary Update export profile
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profileId path int true "Export profile ID"
+// @Param request body services.ExportProfileRequest true "Export profile"
+// @Success 200 {object} models.ExportProfile
+// @Router /exports/profiles/{profileId} [put]
+func (h *ExportProfileHandler) UpdateExportProfileHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	profileID, err := strconv.ParseUint(mux.Vars(r)["profileId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export profile ID", http.StatusBadRequest)
+		return
+	}
+
+	var req services.ExportProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.exportProfileService.UpdateProfile(*tenantID, uint(profileID), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// DeleteExportProfileHandler removes an export profile
+// @Summary Delete export profile
+// @Tags Exports
+// @Security BearerAuth
+// @Param profileId path int true "Export profile ID"
+// @Success 204
+// @Router /exports/profiles/{profileId} [delete]
+func (h *ExportProfileHandler) DeleteExportProfileHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	profileID, err := strconv.ParseUint(mux.Vars(r)["profileId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export profile ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exportProfileService.DeleteProfile(*tenantID, uint(profileID)); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunExportProfileHandler streams the export a saved profile describes
+// @Summary Run export profile
+// @Description Exports with the profile's columns, filters and format (CSV, JSON or PDF)
+// @Tags Exports
+// @Produce text/csv,application/json,application/pdf
+// @Security BearerAuth
+// @Param profileId path int true "Export profile ID"
+// @Success 200 {file} file
+// @Router /exports/{profileId}/run [post]
+func (h *ExportProfileHandler) RunExportProfileHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	profileID, err := strconv.ParseUint(mux.Vars(r)["profileId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid export profile ID", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.exportProfileService.GetProfile(*tenantID, uint(profileID))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	contentType, extension := services.ExportFileType(profile.Format)
+	filename := fmt.Sprintf("%s_export_%d_%s.%s", profile.Entity, profile.ID, time.Now().Format("20060102_150405"), extension)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+	count, err := h.exportProfileService.RunProfile(profile, w)
+	if err != nil {
+		// Headers may already be sent, so the failure can only be logged
+		log.Printf("Export profile %d failed: %v", profile.ID, err)
+		return
+	}
+
+	log.Printf("User %s exported %d %s with profile %d (%s)", user.Email, count, profile.Entity, profile.ID, profile.Format)
+}
+
+// GetScheduledExportsHandler lists the tenant's scheduled exports
+// @Summary List scheduled exports
+// @Tags Exports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ScheduledExport
+// @Router /exports/schedules [get]
+func (h *ExportProfileHandler) GetScheduledExportsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	schedules, err := h.scheduledExportService.GetSchedules(*tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load scheduled exports", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, schedules)
+}
+
+// CreateScheduledExportHandler schedules an export profile to be emailed on a recurring cadence
+// @Summary Create scheduled export
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.ScheduledExportRequest true "Scheduled export"
+// @Success 201 {object} models.ScheduledExport
+// @Failure 403 {string} string "Only owners and admins can schedule exports"
+// @Router /exports/schedules [post]
+func (h *ExportProfileHandler) CreateScheduledExportHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can schedule exports", http.StatusForbidden)
+		return
+	}
+
+	var req services.ScheduledExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.scheduledExportService.CreateSchedule(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusCreated, schedule)
+}
+
+// DeleteScheduledExportHandler stops a scheduled export
+// @Summary Delete scheduled export
+// @Tags Exports
+// @Security BearerAuth
+// @Param scheduleId path int true "Scheduled export ID"
+// @Success 204
+// @Failure 403 {string} string "Only owners and admins can stop scheduled exports"
+// @Failure 404 {string} string "Scheduled export not found"
+// @Router /exports/schedules/{scheduleId} [delete]
+func (h *ExportProfileHandler) DeleteScheduledExportHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can stop scheduled exports", http.StatusForbidden)
+		return
+	}
+	scheduleID, err := strconv.ParseUint(mux.Vars(r)["scheduleId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid scheduled export ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.scheduledExportService.DeleteSchedule(*tenantID, uint(scheduleID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Scheduled export not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete scheduled export", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
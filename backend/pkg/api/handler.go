@@ -15,6 +15,8 @@ type Handler struct {
 	exchangeRateService *services.ExchangeRateService
 	transactionService  *services.TransactionService
 	navasanService      *services.NavasanService
+	settingsService     *services.TenantSettingsService
+	clientService       *services.ClientService
 }
 
 // NewHandler creates a new handler instance with database connection
@@ -26,6 +28,8 @@ func NewHandler(db *gorm.DB) *Handler {
 		exchangeRateService: exchangeRateService,
 		transactionService:  services.NewTransactionService(db, exchangeRateService),
 		navasanService:      services.NewNavasanService(),
+		settingsService:     services.NewTenantSettingsService(db),
+		clientService:       services.NewClientService(db),
 	}
 }
 
@@ -7,20 +7,23 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 type LedgerHandler struct {
-	ledgerService *services.LedgerService
-	db            *gorm.DB
+	ledgerService    *services.LedgerService
+	statementService *services.StatementService
+	db               *gorm.DB
 }
 
 func NewLedgerHandler(db *gorm.DB) *LedgerHandler {
 	return &LedgerHandler{
-		ledgerService: services.NewLedgerService(db),
-		db:            db,
+		ledgerService:    services.NewLedgerService(db),
+		statementService: services.NewStatementService(db),
+		db:               db,
 	}
 }
 
@@ -166,3 +169,81 @@ func (h *LedgerHandler) Exchange(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, entries)
 }
+
+// parseStatementRange reads the statement's from/to dates (YYYY-MM-DD); "to" is inclusive of that day
+func parseStatementRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// GetClientStatement returns a client's statement for one currency, reconciled against the last closed period
+func (h *LedgerHandler) GetClientStatement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	from, to, err := parseStatementRange(query.Get("from"), query.Get("to"))
+	if err != nil {
+		http.Error(w, "from and to must be dates in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.statementService.GenerateStatement(*tenantID, clientID, query.Get("currency"), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, statement)
+}
+
+// CloseStatementPeriod closes a client's statement period, recording its closing balance
+func (h *LedgerHandler) CloseStatementPeriod(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+	user := r.Context().Value("user").(*models.User)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Currency string `json:"currency"`
+		From     string `json:"from"`
+		To       string `json:"to"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseStatementRange(req.From, req.To)
+	if err != nil {
+		http.Error(w, "from and to must be dates in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	period, err := h.statementService.ClosePeriod(*tenantID, clientID, req.Currency, from, to, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, period)
+}
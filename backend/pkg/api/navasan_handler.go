@@ -2,7 +2,9 @@ package api
 
 import (
 	"api/pkg/services"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/shopspring/decimal"
@@ -20,9 +22,18 @@ func NewNavasanHandler() *NavasanHandler {
 	}
 }
 
+// addCacheStatus adds when the rates were fetched and whether they are a last-known-good fallback
+func addCacheStatus(payload map[string]interface{}, status services.NavasanCacheStatus) map[string]interface{} {
+	payload["fetched_at"] = status.FetchedAt.Format(time.RFC3339)
+	payload["stale"] = status.Stale
+	payload["age_seconds"] = int(status.Age.Seconds())
+	return payload
+}
+
 // GetNavasanRates returns all Navasan exchange rates (Tehran market)
 // @Summary Get Navasan exchange rates
-// @Description Get live exchange rates from Navasan (Tehran market rates in Toman)
+// @Description Get exchange rates from Navasan (Tehran market rates in Toman). If Navasan is unreachable the last
+// @Description fetched rates are returned with "stale": true and their age in "age_seconds".
 // @Tags Navasan
 // @Accept json
 // @Produce json
@@ -30,7 +41,7 @@ func NewNavasanHandler() *NavasanHandler {
 // @Failure 500 {object} map[string]interface{}
 // @Router /rates/navasan [get]
 func (h *NavasanHandler) GetNavasanRates(w http.ResponseWriter, r *http.Request) {
-	rates, err := h.navasanService.FormatRatesForDisplay()
+	rates, status, err := h.navasanService.FormatRatesForDisplay()
 	if err != nil {
 		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
 			"error":   "Failed to fetch Navasan rates",
@@ -50,7 +61,7 @@ func (h *NavasanHandler) GetNavasanRates(w http.ResponseWriter, r *http.Request)
 		payload["items"] = items
 	}
 
-	respondJSON(w, http.StatusOK, payload)
+	respondJSON(w, http.StatusOK, addCacheStatus(payload, status))
 }
 
 // RefreshNavasanRates forces a refresh of cached rates
@@ -97,7 +108,8 @@ func (h *NavasanHandler) RefreshNavasanRates(w http.ResponseWriter, r *http.Requ
 
 // GetNavasanRate returns the rate for a specific currency
 // @Summary Get Navasan rate for a currency
-// @Description Get live exchange rate for a specific currency from Navasan (Tehran market)
+// @Description Get the exchange rate for a specific currency from Navasan (Tehran market), served from cache; flagged
+// @Description "stale" when Navasan is unreachable and the last fetched rate is returned
 // @Tags Navasan
 // @Accept json
 // @Produce json
@@ -110,16 +122,23 @@ func (h *NavasanHandler) GetNavasanRate(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	currency := vars["currency"]
 
-	rate, err := h.navasanService.GetRate(currency)
+	rate, status, err := h.navasanService.GetRateWithStatus(currency)
 	if err != nil {
-		respondJSON(w, http.StatusNotFound, map[string]interface{}{
-			"error":   "Currency not found",
+		if errors.Is(err, services.ErrNavasanRateNotFound) {
+			respondJSON(w, http.StatusNotFound, map[string]interface{}{
+				"error":   "Currency not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":   "Failed to fetch Navasan rates",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	respondJSON(w, http.StatusOK, addCacheStatus(map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
 			"currency":       rate.Currency,
@@ -132,7 +151,7 @@ func (h *NavasanHandler) GetNavasanRate(w http.ResponseWriter, r *http.Request)
 		},
 		"source": "navasan.tech",
 		"note":   "Rate is in Iranian Toman (1 Toman = 10 Rial)",
-	})
+	}, status))
 }
 
 // GetUSDToIRR returns the USD to IRR conversion rate
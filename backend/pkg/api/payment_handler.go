@@ -5,8 +5,13 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
@@ -41,6 +46,7 @@ func (h *PaymentHandler) CreatePaymentHandler(w http.ResponseWriter, r *http.Req
 		Amount        float64                `json:"amount"`
 		Currency      string                 `json:"currency"`
 		ExchangeRate  float64                `json:"exchangeRate"`
+		RateSource    string                 `json:"rateSource"` // manual (default), navasan, stored
 		PaymentMethod string                 `json:"paymentMethod"`
 		Notes         *string                `json:"notes"`
 		ReceiptNumber *string                `json:"receiptNumber"`
@@ -62,7 +68,11 @@ func (h *PaymentHandler) CreatePaymentHandler(w http.ResponseWriter, r *http.Req
 		http.Error(w, "Currency is required", http.StatusBadRequest)
 		return
 	}
-	if req.ExchangeRate <= 0 {
+	req.RateSource = strings.ToLower(strings.TrimSpace(req.RateSource))
+	if req.RateSource == "" {
+		req.RateSource = models.PaymentRateSourceManual
+	}
+	if req.RateSource == models.PaymentRateSourceManual && req.ExchangeRate <= 0 {
 		http.Error(w, "Exchange rate must be positive", http.StatusBadRequest)
 		return
 	}
@@ -77,6 +87,7 @@ func (h *PaymentHandler) CreatePaymentHandler(w http.ResponseWriter, r *http.Req
 		Amount:        models.NewDecimal(req.Amount),
 		Currency:      req.Currency,
 		ExchangeRate:  models.NewDecimal(req.ExchangeRate),
+		RateSource:    req.RateSource,
 		PaymentMethod: req.PaymentMethod,
 		Notes:         req.Notes,
 		ReceiptNumber: req.ReceiptNumber,
@@ -104,6 +115,173 @@ func (h *PaymentHandler) CreatePaymentHandler(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// CreatePaymentsBatchHandler posts many payments at once, e.g. from a bank statement import
+// POST /api/transactions/payments/batch
+func (h *PaymentHandler) CreatePaymentsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user := r.Context().Value("user").(*models.User)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req []struct {
+		TransactionID string                 `json:"transactionId"`
+		Amount        float64                `json:"amount"`
+		Currency      string                 `json:"currency"`
+		ExchangeRate  float64                `json:"exchangeRate"`
+		RateSource    string                 `json:"rateSource"`
+		PaymentMethod string                 `json:"paymentMethod"`
+		Notes         *string                `json:"notes"`
+		ReceiptNumber *string                `json:"receiptNumber"`
+		BranchID      *uint                  `json:"branchId"`
+		Details       map[string]interface{} `json:"details"`
+		PaidAt        *time.Time             `json:"paidAt"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req) == 0 {
+		http.Error(w, "At least one payment is required", http.StatusBadRequest)
+		return
+	}
+
+	payments := make([]*models.Payment, 0, len(req))
+	for i, item := range req {
+		if item.TransactionID == "" || item.Amount <= 0 || item.Currency == "" {
+			http.Error(w, fmt.Sprintf("Payment %d: transactionId, a positive amount and currency are required", i), http.StatusBadRequest)
+			return
+		}
+
+		rateSource := strings.ToLower(strings.TrimSpace(item.RateSource))
+		if rateSource == "" {
+			rateSource = models.PaymentRateSourceManual
+		}
+		paymentMethod := item.PaymentMethod
+		if paymentMethod == "" {
+			paymentMethod = models.PaymentMethodCash
+		}
+
+		payment := &models.Payment{
+			TenantID:      *tenantID,
+			TransactionID: item.TransactionID,
+			BranchID:      item.BranchID,
+			Amount:        models.NewDecimal(item.Amount),
+			Currency:      item.Currency,
+			ExchangeRate:  models.NewDecimal(item.ExchangeRate),
+			RateSource:    rateSource,
+			PaymentMethod: paymentMethod,
+			Notes:         item.Notes,
+			ReceiptNumber: item.ReceiptNumber,
+			Details:       item.Details,
+		}
+		if item.PaidAt != nil {
+			payment.PaidAt = *item.PaidAt
+		}
+		payments = append(payments, payment)
+	}
+
+	results, err := h.paymentService.CreatePaymentsBatch(payments, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to process payment batch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":   fmt.Sprintf("%d of %d payments posted", succeeded, len(results)),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
+
+// CreatePaymentPlanHandler attaches an installment schedule to a partial-payment transaction
+// POST /api/transactions/{id}/payment-plan
+func (h *PaymentHandler) CreatePaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	transactionID := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+	user := r.Context().Value("user").(*models.User)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Installments []struct {
+			DueDate        string  `json:"dueDate"` // YYYY-MM-DD
+			ExpectedAmount float64 `json:"expectedAmount"`
+			Currency       string  `json:"currency"`
+		} `json:"installments"`
+		Notes string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	installments := make([]services.PaymentInstallmentInput, 0, len(req.Installments))
+	for i, item := range req.Installments {
+		dueDate, err := time.Parse("2006-01-02", item.DueDate)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Installment %d: invalid due date format", i+1), http.StatusBadRequest)
+			return
+		}
+		installments = append(installments, services.PaymentInstallmentInput{
+			DueDate:        dueDate,
+			ExpectedAmount: models.NewDecimal(item.ExpectedAmount),
+			Currency:       item.Currency,
+		})
+	}
+
+	plan, err := h.paymentService.CreatePaymentPlan(*tenantID, transactionID, installments, req.Notes, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "Payment plan created successfully",
+		"plan":    plan,
+	})
+}
+
+// GetPaymentPlanHandler returns a transaction's payment plan
+// GET /api/transactions/{id}/payment-plan
+func (h *PaymentHandler) GetPaymentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	transactionID := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.paymentService.GetPaymentPlan(*tenantID, transactionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Payment plan not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, plan)
+}
+
 // GetPaymentsHandler retrieves all payments for a transaction
 // GET /api/transactions/{id}/payments
 func (h *PaymentHandler) GetPaymentsHandler(w http.ResponseWriter, r *http.Request) {
@@ -304,6 +482,104 @@ func (h *PaymentHandler) CancelPaymentHandler(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// VoidTransactionHandler cancels a transaction and reverses every payment on it
+// POST /api/transactions/{id}/void
+func (h *PaymentHandler) VoidTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	transactionID := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+	user := r.Context().Value("user").(*models.User)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Reason == "" {
+		http.Error(w, "Void reason is required", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.paymentService.VoidTransaction(transactionID, *tenantID, user.ID, req.Reason)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	services.NewAuditService(h.db).LogAction(user.ID, tenantID, models.ActionVoidTransaction, "Transaction", transaction.ID,
+		"Transaction voided: "+req.Reason,
+		nil, map[string]interface{}{"status": transaction.Status, "reason": req.Reason, "totalPaid": transaction.TotalPaid},
+		r)
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":     "Transaction voided successfully",
+		"transaction": transaction,
+	})
+}
+
+// RefundPaymentHandler issues a partial refund against a payment
+// POST /api/payments/{id}/refund
+func (h *PaymentHandler) RefundPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	paymentIDStr := vars["id"]
+	tenantID := middleware.GetTenantID(r)
+	user := r.Context().Value("user").(*models.User)
+
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	paymentID, err := strconv.ParseUint(paymentIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid payment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Amount float64 `json:"amount"`
+		Reason string  `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Amount <= 0 {
+		http.Error(w, "Refund amount must be positive", http.StatusBadRequest)
+		return
+	}
+	if req.Reason == "" {
+		http.Error(w, "Refund reason is required", http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.RefundPayment(uint(paymentID), *tenantID, models.NewDecimal(req.Amount), req.Reason, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Payment refunded successfully",
+		"payment": payment,
+	})
+}
+
 // CompleteTransactionHandler marks a transaction as completed
 // POST /api/transactions/{id}/complete
 func (h *PaymentHandler) CompleteTransactionHandler(w http.ResponseWriter, r *http.Request) {
@@ -316,7 +592,26 @@ func (h *PaymentHandler) CompleteTransactionHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	if err := h.paymentService.CompleteTransaction(transactionID, *tenantID); err != nil {
+	user := r.Context().Value("user").(*models.User)
+
+	// The body is optional; without it the transaction must already be within tolerance
+	var req struct {
+		WriteOff bool   `json:"writeOff"`
+		Reason   string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	opts := services.CompleteTransactionOptions{WriteOff: req.WriteOff, Reason: req.Reason, UserID: user.ID}
+	if err := h.paymentService.CompleteTransaction(transactionID, *tenantID, opts); err != nil {
+		if errors.Is(err, services.ErrComplianceApprovalRequired) || errors.Is(err, services.ErrSupportingDocumentRequired) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -41,6 +41,7 @@ func (h *PickupHandler) CreatePickupTransactionHandler(w http.ResponseWriter, r
 		SenderPhone      string   `json:"senderPhone"`
 		RecipientName    string   `json:"recipientName"`
 		RecipientPhone   *string  `json:"recipientPhone"`  // Optional for bank transfers
+		RecipientEmail   *string  `json:"recipientEmail"`  // Optional, used for ready-for-pickup notices
 		RecipientIBAN    *string  `json:"recipientIban"`   // For bank transfers
 		TransactionType  string   `json:"transactionType"` // CASH_PICKUP, CASH_EXCHANGE, BANK_TRANSFER, CARD_SWAP_IRR
 		Amount           float64  `json:"amount"`
@@ -134,6 +135,7 @@ func (h *PickupHandler) CreatePickupTransactionHandler(w http.ResponseWriter, r
 		SenderPhone:      req.SenderPhone,
 		RecipientName:    req.RecipientName,
 		RecipientPhone:   req.RecipientPhone,
+		RecipientEmail:   req.RecipientEmail,
 		RecipientIBAN:    req.RecipientIBAN,
 		TransactionType:  req.TransactionType,
 		Amount:           req.Amount,
@@ -401,6 +403,42 @@ func (h *PickupHandler) MarkAsPickedUpHandler(w http.ResponseWriter, r *http.Req
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Pickup marked as picked up successfully"})
 }
 
+// MarkAsReadyHandler marks a pickup as ready for collection and notifies the recipient
+// POST /pickups/:id/ready
+func (h *PickupHandler) MarkAsReadyHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	userVal := r.Context().Value("user")
+	if userVal == nil {
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+	user := userVal.(*models.User)
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid pickup ID", http.StatusBadRequest)
+		return
+	}
+
+	pickup, err := h.PickupService.MarkAsReady(uint(id), *tenantID, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":           "Pickup marked as ready",
+		"recipientNotified": pickup.ReadyNotifiedAt != nil,
+		"pickup":            pickup,
+	})
+}
+
 // CancelPickupTransactionHandler cancels a pickup transaction
 // POST /pickups/:id/cancel
 func (h *PickupHandler) CancelPickupTransactionHandler(w http.ResponseWriter, r *http.Request) {
@@ -2,24 +2,38 @@ package api
 
 import (
 	"api/pkg/middleware"
+	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 // ProfitAnalysisHandler handles profit analysis API requests
 type ProfitAnalysisHandler struct {
-	profitService *services.ProfitAnalysisService
+	profitService      *services.ProfitAnalysisService
+	preferencesService *services.UserPreferencesService
+	marginAlertService *services.MarginAlertService
+	profitFloorService *services.ProfitFloorService
+	auditService       *services.AuditService
 }
 
 // NewProfitAnalysisHandler creates a new ProfitAnalysisHandler
 func NewProfitAnalysisHandler(db *gorm.DB) *ProfitAnalysisHandler {
 	return &ProfitAnalysisHandler{
-		profitService: services.NewProfitAnalysisService(db),
+		profitService:      services.NewProfitAnalysisService(db),
+		preferencesService: services.NewUserPreferencesService(db),
+		marginAlertService: services.NewMarginAlertService(db),
+		profitFloorService: services.NewProfitFloorService(db),
+		auditService:       services.NewAuditService(db),
 	}
 }
 
@@ -33,7 +47,11 @@ func NewProfitAnalysisHandler(db *gorm.DB) *ProfitAnalysisHandler {
 // @Param startDate query string false "Start date (YYYY-MM-DD)"
 // @Param endDate query string false "End date (YYYY-MM-DD)"
 // @Param branchId query int false "Branch ID"
+// @Param baseCurrency query string false "Reporting currency (defaults to the tenant's base currency)"
+// @Param baselineStart query string false "Custom baseline period start (YYYY-MM-DD), compared in vsBaseline"
+// @Param baselineEnd query string false "Custom baseline period end (YYYY-MM-DD)"
 // @Success 200 {object} services.ProfitAnalysisResult
+// @Failure 400 {string} string "Invalid baseline period"
 // @Router /analytics/profit [get]
 func (h *ProfitAnalysisHandler) GetProfitAnalysisHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
@@ -42,19 +60,84 @@ func (h *ProfitAnalysisHandler) GetProfitAnalysisHandler(w http.ResponseWriter,
 		return
 	}
 
-	startDate, endDate := parseDateRange(r)
+	startDate, endDate := parseDateRange(r, h.preferencesService)
 	branchID := parseBranchID(r)
 
-	result, err := h.profitService.GetProfitAnalysis(*tenantID, branchID, startDate, endDate)
+	baselineStart, baselineEnd, err := parseBaselineRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.profitService.GetProfitAnalysis(*tenantID, branchID, startDate, endDate, r.URL.Query().Get("baseCurrency"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if baselineStart != nil {
+		comparison := h.profitService.GetBaselineComparison(*tenantID, branchID, startDate, endDate, *baselineStart, *baselineEnd)
+		result.VsBaseline = &comparison
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
+// ExportProfitAnalysisHandler downloads the full profit analysis as an Excel workbook or CSV
+// @Summary Export profit analysis
+// @Description Exports the summary and each breakdown (period, branch, currency pair, customer segment) with totals
+// @Tags Analytics
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce text/csv
+// @Security BearerAuth
+// @Param format query string false "Export format (xlsx or csv)" default(xlsx)
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Param branchId query int false "Branch ID"
+// @Param baseCurrency query string false "Reporting currency (defaults to the tenant's base currency)"
+// @Success 200 {file} file
+// @Router /profit-analysis/export [get]
+func (h *ProfitAnalysisHandler) ExportProfitAnalysisHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = services.ProfitExportFormatXLSX
+	}
+	if format != services.ProfitExportFormatXLSX && format != services.ProfitExportFormatCSV {
+		http.Error(w, "Unsupported export format", http.StatusBadRequest)
+		return
+	}
+
+	startDate, endDate := parseDateRange(r, h.preferencesService)
+	branchID := parseBranchID(r)
+
+	result, err := h.profitService.GetProfitAnalysis(*tenantID, branchID, startDate, endDate, r.URL.Query().Get("baseCurrency"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("profit_analysis_%s.%s", time.Now().Format("20060102_150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+	if format == services.ProfitExportFormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+		err = services.WriteProfitAnalysisCSV(w, result)
+	} else {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = services.WriteProfitAnalysisXLSX(w, result)
+	}
+	if err != nil {
+		// Headers may already be sent, so the failure can only be logged
+		log.Printf("Profit analysis export failed for tenant %d: %v", *tenantID, err)
+	}
+}
+
 // GetDailyProfitHandler returns daily profit for the last N days
 // @Summary Get daily profit
 // @Description Returns daily profit breakdown
@@ -91,6 +174,49 @@ func (h *ProfitAnalysisHandler) GetDailyProfitHandler(w http.ResponseWriter, r *
 	json.NewEncoder(w).Encode(result)
 }
 
+// GetProfitMovingAverageHandler returns daily profit with a trailing moving average
+// @Summary Get profit moving average
+// @Description Returns daily profit for the last N days with its rolling average over the window
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param window query int false "Moving average window in days" default(7)
+// @Param days query int false "Number of days" default(30)
+// @Param branchId query int false "Branch ID"
+// @Success 200 {array} services.ProfitMovingAverage
+// @Router /analytics/profit/moving-average [get]
+func (h *ProfitAnalysisHandler) GetProfitMovingAverageHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	window := 7
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		if parsed, err := strconv.Atoi(windowStr); err == nil && parsed > 0 {
+			window = parsed
+		}
+	}
+	days := 30
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		if parsed, err := strconv.Atoi(daysStr); err == nil && parsed > 0 {
+			days = parsed
+		}
+	}
+	branchID := parseBranchID(r)
+
+	result, err := h.profitService.GetProfitMovingAverage(*tenantID, branchID, window, days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
 // GetMonthlyProfitHandler returns monthly profit summary
 // @Summary Get monthly profit
 // @Description Returns monthly profit summary
@@ -134,23 +260,47 @@ func (h *ProfitAnalysisHandler) GetProfitByBranchHandler(w http.ResponseWriter,
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	startDate, endDate := parseDateRange(r)
+	startDate, endDate := parseDateRange(r, h.preferencesService)
 	branchID := parseBranchID(r)
 
-	// We need total profit for percentage calculation, so we get the full analysis or just sum it up
-	// For efficiency, we could just get branch data, but percentage requires total.
-	// Let's just get branch data and sum it up for total.
-
-	// Note: The service method requires totalProfit to calculate percentages.
-	// We can pass 0 for now if we don't want to calculate it separately, or calculate it first.
-	// Let's calculate total profit first.
-	summary, err := h.profitService.GetProfitAnalysis(*tenantID, branchID, startDate, endDate)
+	// Branch shares are of the profit normalized to the base currency, which the full analysis computes
+	summary, err := h.profitService.GetProfitAnalysis(*tenantID, branchID, startDate, endDate, r.URL.Query().Get("baseCurrency"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	result := h.profitService.GetProfitByBranch(*tenantID, branchID, startDate, endDate, summary.TotalProfitCAD)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary.ByBranch)
+}
+
+// GetProfitByPaymentMethodHandler returns profit grouped by payment method
+func (h *ProfitAnalysisHandler) GetProfitByPaymentMethodHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	startDate, endDate := parseDateRange(r, h.preferencesService)
+	branchID := parseBranchID(r)
+
+	result := h.profitService.GetProfitByPaymentMethod(*tenantID, branchID, startDate, endDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetProfitByAgentHandler returns profit grouped by the user who created each transaction
+func (h *ProfitAnalysisHandler) GetProfitByAgentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	startDate, endDate := parseDateRange(r, h.preferencesService)
+	branchID := parseBranchID(r)
+
+	result := h.profitService.GetProfitByAgent(*tenantID, branchID, startDate, endDate)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -163,7 +313,7 @@ func (h *ProfitAnalysisHandler) GetProfitTrendHandler(w http.ResponseWriter, r *
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	startDate, endDate := parseDateRange(r)
+	startDate, endDate := parseDateRange(r, h.preferencesService)
 	branchID := parseBranchID(r)
 	// groupBy := r.URL.Query().Get("groupBy") // Currently service only supports daily via GetProfitByPeriod
 
@@ -180,7 +330,7 @@ func (h *ProfitAnalysisHandler) GetTopCustomersHandler(w http.ResponseWriter, r
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	startDate, endDate := parseDateRange(r)
+	startDate, endDate := parseDateRange(r, h.preferencesService)
 	branchID := parseBranchID(r)
 
 	limit := 10
@@ -200,17 +350,199 @@ func (h *ProfitAnalysisHandler) GetTopCustomersHandler(w http.ResponseWriter, r
 	json.NewEncoder(w).Encode(result)
 }
 
-func parseDateRange(r *http.Request) (time.Time, time.Time) {
+// GetMarginAlertsHandler lists alerts raised for currency pairs whose margin fell below the tenant's floor
+// @Summary Get currency-pair margin alerts
+// @Tags Analytics
+// @Produce json
+// @Param status query string false "OPEN or RESOLVED"
+// @Router /profit-analysis/margin-alerts [get]
+func (h *ProfitAnalysisHandler) GetMarginAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	alerts, err := h.marginAlertService.GetAlerts(*tenantID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+// ResolveMarginAlertHandler closes a margin alert after review
+// @Summary Resolve currency-pair margin alert
+// @Tags Analytics
+// @Produce json
+// @Router /profit-analysis/margin-alerts/{alertId}/resolve [put]
+func (h *ProfitAnalysisHandler) ResolveMarginAlertHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	alertID, err := strconv.ParseUint(mux.Vars(r)["alertId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid alert ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.marginAlertService.ResolveAlert(*tenantID, uint(alertID), user.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Open alert not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Alert resolved"})
+}
+
+// GetProfitFloorsHandler lists the tenant's per-currency-pair minimum margins
+// @Summary Get currency-pair profit floors
+// @Tags Analytics
+// @Produce json
+// @Success 200 {array} models.CurrencyProfitFloor
+// @Router /profit-analysis/profit-floors [get]
+func (h *ProfitAnalysisHandler) GetProfitFloorsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	floors, err := h.profitFloorService.ListFloors(*tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(floors)
+}
+
+// SetProfitFloorRequest sets the minimum margin for a currency pair
+type SetProfitFloorRequest struct {
+	BaseCurrency   string  `json:"baseCurrency"`
+	TargetCurrency string  `json:"targetCurrency"`
+	MinMarginPct   float64 `json:"minMarginPct"` // Net profit as a percentage of the send amount; 0 blocks only losses
+}
+
+// SetProfitFloorHandler creates or replaces the minimum margin for a currency pair (owner/admin only)
+// @Summary Set currency-pair profit floor
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Param request body SetProfitFloorRequest true "Profit floor"
+// @Success 200 {object} models.CurrencyProfitFloor
+// @Failure 403 {string} string "Only owners and admins can set profit floors"
+// @Router /profit-analysis/profit-floors [put]
+func (h *ProfitAnalysisHandler) SetProfitFloorHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can set profit floors", http.StatusForbidden)
+		return
+	}
+
+	var req SetProfitFloorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	floor, err := h.profitFloorService.SetFloor(*tenantID, req.BaseCurrency, req.TargetCurrency, models.NewDecimal(req.MinMarginPct), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionUpdateProfitFloor, "CurrencyProfitFloor",
+		strconv.FormatUint(uint64(floor.ID), 10),
+		fmt.Sprintf("Set %s/%s profit floor to %s%%", floor.BaseCurrency, floor.TargetCurrency, floor.MinMarginPct.String()),
+		nil, floor, r)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(floor)
+}
+
+// DeleteProfitFloorHandler removes a currency pair's minimum margin (owner/admin only)
+// @Summary Delete currency-pair profit floor
+// @Tags Analytics
+// @Produce json
+// @Router /profit-analysis/profit-floors/{floorId} [delete]
+func (h *ProfitAnalysisHandler) DeleteProfitFloorHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can remove profit floors", http.StatusForbidden)
+		return
+	}
+
+	floorID, err := strconv.ParseUint(mux.Vars(r)["floorId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid floor ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.profitFloorService.DeleteFloor(*tenantID, uint(floorID)); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Profit floor not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionUpdateProfitFloor, "CurrencyProfitFloor",
+		strconv.FormatUint(floorID, 10), "Removed profit floor", nil, nil, r)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Profit floor removed"})
+}
+
+// parseDateRange reads the startDate/endDate (YYYY-MM-DD) query params. When neither is given the
+// user's preferred default range applies, falling back to the last month.
+func parseDateRange(r *http.Request, preferences *services.UserPreferencesService) (time.Time, time.Time) {
 	startDate := time.Now().AddDate(0, -1, 0) // Default: last month
 	endDate := time.Now()
 
-	if startStr := r.URL.Query().Get("startDate"); startStr != "" {
+	startStr, endStr := r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate")
+	if startStr == "" && endStr == "" {
+		if start, end, ok := userDefaultDateRange(r, preferences); ok {
+			return start, end
+		}
+	}
+
+	if startStr != "" {
 		if parsed, err := time.Parse("2006-01-02", startStr); err == nil {
 			startDate = parsed
 		}
 	}
 
-	if endStr := r.URL.Query().Get("endDate"); endStr != "" {
+	if endStr != "" {
 		if parsed, err := time.Parse("2006-01-02", endStr); err == nil {
 			endDate = parsed.Add(24*time.Hour - time.Second) // End of day
 		}
@@ -218,6 +550,32 @@ func parseDateRange(r *http.Request) (time.Time, time.Time) {
 	return startDate, endDate
 }
 
+// parseBaselineRange reads the optional baselineStart/baselineEnd (YYYY-MM-DD) query params.
+// Both or neither must be given; nil is returned when no baseline is requested.
+func parseBaselineRange(r *http.Request) (*time.Time, *time.Time, error) {
+	startStr, endStr := r.URL.Query().Get("baselineStart"), r.URL.Query().Get("baselineEnd")
+	if startStr == "" && endStr == "" {
+		return nil, nil, nil
+	}
+	if startStr == "" || endStr == "" {
+		return nil, nil, errors.New("baselineStart and baselineEnd must be given together")
+	}
+
+	start, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		return nil, nil, errors.New("invalid baselineStart, expected YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		return nil, nil, errors.New("invalid baselineEnd, expected YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, nil, errors.New("baselineStart must not be after baselineEnd")
+	}
+	end = end.Add(24*time.Hour - time.Second) // End of day
+	return &start, &end, nil
+}
+
 func parseBranchID(r *http.Request) *uint {
 	if idStr := r.URL.Query().Get("branchId"); idStr != "" {
 		if id, err := strconv.ParseUint(idStr, 10, 64); err == nil {
@@ -2,10 +2,14 @@ package api
 
 import (
 	"api/pkg/middleware"
+	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
@@ -280,7 +284,8 @@ func (h *ReceiptHandler) GetVariablesHandler(w http.ResponseWriter, r *http.Requ
 // @Summary Render receipt with data
 // @Tags Receipts
 // @Accept json
-// @Produce html
+// @Produce html,application/pdf
+// @Param format query string false "pdf for a PDF instead of HTML"
 // @Router /receipts/render [post]
 func (h *ReceiptHandler) RenderReceiptHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
@@ -292,15 +297,15 @@ func (h *ReceiptHandler) RenderReceiptHandler(w http.ResponseWriter, r *http.Req
 	var req struct {
 		TemplateID   *uint                  `json:"templateId"`
 		TemplateType string                 `json:"templateType"`
+		BranchID     *uint                  `json:"branchId"` // Defaults to the user's primary branch
 		Data         map[string]interface{} `json:"data"`
+		Locale       string                 `json:"locale"` // Overrides the template's locale, en or fa
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-
-	var html string
-	var err error
+	req.Data = services.ParseReceiptDates(req.Data)
 
 	if req.TemplateID != nil {
 		template, err := h.receiptService.GetTemplate(*tenantID, *req.TemplateID)
@@ -308,23 +313,134 @@ func (h *ReceiptHandler) RenderReceiptHandler(w http.ResponseWriter, r *http.Req
 			http.Error(w, "Template not found", http.StatusNotFound)
 			return
 		}
-		html = h.receiptService.RenderWithTemplate(template, req.Data)
-	} else {
-		templateType := req.TemplateType
-		if templateType == "" {
-			templateType = "transaction"
+		h.writeRendered(w, r, template, req.Data, req.Locale)
+		return
+	}
+
+	templateType := req.TemplateType
+	if templateType == "" {
+		templateType = "transaction"
+	}
+	branchID := req.BranchID
+	if branchID == nil {
+		if user, ok := r.Context().Value("user").(*models.User); ok {
+			branchID = user.PrimaryBranchID
 		}
-		html, err = h.receiptService.RenderReceipt(*tenantID, templateType, req.Data)
+	}
+	template, err := h.receiptService.GetDefaultTemplate(*tenantID, branchID, templateType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeRendered(w, r, template, req.Data, req.Locale)
+}
+
+// writeReceipt renders data with the default template of the branch and writes the receipt, in the
+// locale given by ?locale= if any
+func (h *ReceiptHandler) writeReceipt(w http.ResponseWriter, r *http.Request, tenantID uint, branchID *uint, templateType string, data map[string]interface{}) {
+	template, err := h.receiptService.GetDefaultTemplate(tenantID, branchID, templateType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.writeRendered(w, r, template, data, r.URL.Query().Get("locale"))
+}
+
+// writeRendered renders data with the template, in locale instead of the template's own when one is
+// given, and writes the receipt as HTML, or as a PDF laid out on the template's page when the request
+// asks for ?format=pdf
+func (h *ReceiptHandler) writeRendered(w http.ResponseWriter, r *http.Request, template *models.ReceiptTemplate, data map[string]interface{}, locale string) {
+	if locale != "" {
+		normalized, err := services.NormalizeReceiptLocale(locale)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		localized := *template
+		localized.Locale = normalized
+		template = &localized
+	}
+	html := h.receiptService.RenderWithTemplate(template, data)
+
+	if strings.EqualFold(r.URL.Query().Get("format"), "pdf") {
+		pdf, err := h.receiptService.RenderReceiptPDF(template, html)
+		if errors.Is(err, services.ErrReceiptPDFLocaleUnsupported) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to render receipt PDF", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", services.ReceiptPDFContentType)
+		w.Header().Set("Content-Disposition", "inline; filename=\"receipt.pdf\"")
+		w.Write(pdf)
+		return
 	}
 
 	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(html))
 }
 
+// SetBranchDefaultTemplateHandler assigns a branch's default receipt template
+// @Summary Set a branch's default receipt template
+// @Description Assign templateId as the branch default for its type, or send templateId null with templateType to clear the override
+// @Tags Receipts
+// @Accept json
+// @Produce json
+// @Param id path int true "Branch ID"
+// @Router /branches/{id}/default-template [put]
+func (h *ReceiptHandler) SetBranchDefaultTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := r.Context().Value("user").(*models.User)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can assign branch templates", http.StatusForbidden)
+		return
+	}
+
+	branchID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid branch ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		TemplateID   *uint  `json:"templateId"`
+		TemplateType string `json:"templateType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.TemplateID == nil {
+		if req.TemplateType == "" {
+			http.Error(w, "templateType is required to clear a branch template", http.StatusBadRequest)
+			return
+		}
+		if err := h.receiptService.ClearBranchDefaultTemplate(*tenantID, uint(branchID), req.TemplateType); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Branch template cleared"})
+		return
+	}
+
+	assignment, err := h.receiptService.SetBranchDefaultTemplate(*tenantID, uint(branchID), *req.TemplateID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assignment)
+}
+
 // CreateDefaultTemplatesHandler creates default templates for a tenant
 // @Summary Create default templates
 // @Tags Receipts
@@ -355,7 +471,9 @@ func (h *ReceiptHandler) CreateDefaultTemplatesHandler(w http.ResponseWriter, r
 // GetOutgoingRemittanceReceiptHandler generates a receipt for an outgoing remittance
 // @Summary Get outgoing remittance receipt
 // @Tags Receipts
-// @Produce html
+// @Produce html,application/pdf
+// @Param format query string false "pdf for a PDF instead of HTML"
+// @Param locale query string false "en or fa, overriding the template's locale"
 // @Router /receipts/outgoing/{id} [get]
 func (h *ReceiptHandler) GetOutgoingRemittanceReceiptHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
@@ -379,6 +497,8 @@ func (h *ReceiptHandler) GetOutgoingRemittanceReceiptHandler(w http.ResponseWrit
 		Amount       float64 `json:"amount"`
 		Currency     string  `json:"currency"`
 		Status       string  `json:"status"`
+		BranchID     *uint   `json:"branchId"`
+		CreatedAt    time.Time
 	}
 	if err := h.db.Table("outgoing_remittances").Where("id = ? AND tenant_id = ?", remittanceID, *tenantID).First(&remittance).Error; err != nil {
 		http.Error(w, "Remittance not found", http.StatusNotFound)
@@ -388,6 +508,7 @@ func (h *ReceiptHandler) GetOutgoingRemittanceReceiptHandler(w http.ResponseWrit
 	// Build data map
 	data := map[string]interface{}{
 		"transaction.id":     remittance.ID,
+		"transaction.date":   remittance.CreatedAt,
 		"customer.name":      remittance.SenderName,
 		"customer.phone":     remittance.SenderPhone,
 		"beneficiary.name":   remittance.ReceiverName,
@@ -396,20 +517,40 @@ func (h *ReceiptHandler) GetOutgoingRemittanceReceiptHandler(w http.ResponseWrit
 		"transaction.status": remittance.Status,
 	}
 
-	html, err := h.receiptService.RenderReceipt(*tenantID, "remittance", data)
+	// Incoming remittances that settled it, listed with {{#each payments}}
+	var settlements []models.RemittanceSettlement
+	if err := h.db.Where("outgoing_remittance_id = ? AND tenant_id = ?", remittance.ID, *tenantID).
+		Order("created_at ASC").Find(&settlements).Error; err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	payments := make([]map[string]interface{}, 0, len(settlements))
+	for _, settlement := range settlements {
+		payments = append(payments, map[string]interface{}{
+			"amount":    settlement.SettledAmountIRR.String(),
+			"currency":  "IRR",
+			"date":      settlement.CreatedAt,
+			"reference": settlement.IncomingRemittanceID,
+		})
+	}
+	data["payments"] = payments
+
+	number, err := h.receiptService.IssueReceiptNumber(*tenantID, remittance.BranchID, models.ReceiptSourceOutgoingRemittance, strconv.FormatUint(uint64(remittance.ID), 10))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	data["receipt.number"] = number
 
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	h.writeReceipt(w, r, *tenantID, remittance.BranchID, "remittance", data)
 }
 
 // GetIncomingRemittanceReceiptHandler generates a receipt for an incoming remittance
 // @Summary Get incoming remittance receipt
 // @Tags Receipts
-// @Produce html
+// @Produce html,application/pdf
+// @Param format query string false "pdf for a PDF instead of HTML"
+// @Param locale query string false "en or fa, overriding the template's locale"
 // @Router /receipts/incoming/{id} [get]
 func (h *ReceiptHandler) GetIncomingRemittanceReceiptHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
@@ -431,6 +572,8 @@ func (h *ReceiptHandler) GetIncomingRemittanceReceiptHandler(w http.ResponseWrit
 		Amount       float64 `json:"amount"`
 		Currency     string  `json:"currency"`
 		Status       string  `json:"status"`
+		BranchID     *uint   `json:"branchId"`
+		CreatedAt    time.Time
 	}
 	if err := h.db.Table("incoming_remittances").Where("id = ? AND tenant_id = ?", remittanceID, *tenantID).First(&remittance).Error; err != nil {
 		http.Error(w, "Remittance not found", http.StatusNotFound)
@@ -439,6 +582,7 @@ func (h *ReceiptHandler) GetIncomingRemittanceReceiptHandler(w http.ResponseWrit
 
 	data := map[string]interface{}{
 		"transaction.id":     remittance.ID,
+		"transaction.date":   remittance.CreatedAt,
 		"beneficiary.name":   remittance.SenderName,
 		"customer.name":      remittance.ReceiverName,
 		"receive.amount":     remittance.Amount,
@@ -446,20 +590,22 @@ func (h *ReceiptHandler) GetIncomingRemittanceReceiptHandler(w http.ResponseWrit
 		"transaction.status": remittance.Status,
 	}
 
-	html, err := h.receiptService.RenderReceipt(*tenantID, "remittance", data)
+	number, err := h.receiptService.IssueReceiptNumber(*tenantID, remittance.BranchID, models.ReceiptSourceIncomingRemittance, strconv.FormatUint(uint64(remittance.ID), 10))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	data["receipt.number"] = number
 
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	h.writeReceipt(w, r, *tenantID, remittance.BranchID, "remittance", data)
 }
 
 // GetTransactionReceiptHandler generates a receipt for a transaction
 // @Summary Get transaction receipt
 // @Tags Receipts
-// @Produce html
+// @Produce html,application/pdf
+// @Param format query string false "pdf for a PDF instead of HTML"
+// @Param locale query string false "en or fa, overriding the template's locale"
 // @Router /receipts/transaction/{id} [get]
 func (h *ReceiptHandler) GetTransactionReceiptHandler(w http.ResponseWriter, r *http.Request) {
 	tenantID := middleware.GetTenantID(r)
@@ -467,15 +613,11 @@ func (h *ReceiptHandler) GetTransactionReceiptHandler(w http.ResponseWriter, r *
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	vars := mux.Vars(r)
-	transactionID, err := strconv.ParseUint(vars["id"], 10, 32)
-	if err != nil {
-		http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
-		return
-	}
+	// Transaction IDs are UUIDs
+	transactionID := mux.Vars(r)["id"]
 
 	var transaction struct {
-		ID              uint    `json:"id"`
+		ID              string  `json:"id"`
 		TransactionType string  `json:"transactionType"`
 		SendCurrency    string  `json:"sendCurrency"`
 		ReceiveCurrency string  `json:"receiveCurrency"`
@@ -483,14 +625,17 @@ func (h *ReceiptHandler) GetTransactionReceiptHandler(w http.ResponseWriter, r *
 		ReceiveAmount   float64 `json:"receiveAmount"`
 		ExchangeRate    float64 `json:"exchangeRate"`
 		Status          string  `json:"status"`
+		BranchID        *uint   `json:"branchId"`
+		TransactionDate time.Time
 	}
-	if err := h.db.Table("transactions").Where("id = ? AND tenant_id = ?", transactionID, *tenantID).First(&transaction).Error; err != nil {
+	if err := h.db.Table("transactions").Where("id = ? AND tenant_id = ? AND deleted_at IS NULL", transactionID, *tenantID).First(&transaction).Error; err != nil {
 		http.Error(w, "Transaction not found", http.StatusNotFound)
 		return
 	}
 
 	data := map[string]interface{}{
 		"transaction.id":     transaction.ID,
+		"transaction.date":   transaction.TransactionDate,
 		"transaction.type":   transaction.TransactionType,
 		"send.currency":      transaction.SendCurrency,
 		"receive.currency":   transaction.ReceiveCurrency,
@@ -500,12 +645,12 @@ func (h *ReceiptHandler) GetTransactionReceiptHandler(w http.ResponseWriter, r *
 		"transaction.status": transaction.Status,
 	}
 
-	html, err := h.receiptService.RenderReceipt(*tenantID, "transaction", data)
+	number, err := h.receiptService.IssueReceiptNumber(*tenantID, transaction.BranchID, models.ReceiptSourceTransaction, transaction.ID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	data["receipt.number"] = number
 
-	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(html))
+	h.writeReceipt(w, r, *tenantID, transaction.BranchID, "transaction", data)
 }
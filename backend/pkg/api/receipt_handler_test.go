@@ -0,0 +1,63 @@
+package api
+
+import (
+	"api/pkg/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// TestGetTransactionReceipt_UsesTransactionUUID verifies a transaction receipt is found by its UUID and keeps
+// the receipt number issued for it on reprint
+func TestGetTransactionReceipt_UsesTransactionUUID(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Client{}, &models.Transaction{}, &models.ReceiptTemplate{},
+		&models.Sequence{}, &models.IssuedReceipt{})
+
+	tenant := &models.Tenant{Name: "Receipt Exchange"}
+	db.Create(tenant)
+	client := &models.Client{ID: uuid.New().String(), TenantID: tenant.ID, Name: "Receipt Client", PhoneNumber: "555-0120"}
+	db.Create(client)
+	transaction := &models.Transaction{
+		ID:              uuid.New().String(),
+		TenantID:        tenant.ID,
+		ClientID:        client.ID,
+		PaymentMethod:   models.TransactionMethodCash,
+		SendCurrency:    "CAD",
+		SendAmount:      models.NewDecimal(500),
+		ReceiveCurrency: "USD",
+		ReceiveAmount:   models.NewDecimal(360),
+		RateApplied:     models.NewDecimal(0.72),
+		TransactionDate: time.Now(),
+	}
+	if err := db.Create(transaction).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	handler := NewReceiptHandler(db)
+	fetch := func() int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/receipts/transaction/"+transaction.ID, nil)
+		req = req.WithContext(context.WithValue(req.Context(), "tenantId", &tenant.ID))
+		req = mux.SetURLVars(req, map[string]string{"id": transaction.ID})
+		rr := httptest.NewRecorder()
+		handler.GetTransactionReceiptHandler(rr, req)
+		return rr.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := fetch(); code != http.StatusOK {
+			t.Fatalf("Expected 200 for the transaction's receipt, got %d", code)
+		}
+	}
+
+	var issued []models.IssuedReceipt
+	db.Where("tenant_id = ?", tenant.ID).Find(&issued)
+	if len(issued) != 1 || issued[0].SourceID != transaction.ID || issued[0].SourceType != models.ReceiptSourceTransaction {
+		t.Errorf("Expected one receipt number issued against the transaction's UUID, got %+v", issued)
+	}
+}
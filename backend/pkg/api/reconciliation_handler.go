@@ -5,6 +5,7 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -29,12 +30,13 @@ func (h *ReconciliationHandler) CreateReconciliationHandler(w http.ResponseWrite
 	user := r.Context().Value("user").(*models.User)
 
 	var req struct {
-		BranchID          uint               `json:"branchId"`
-		Date              string             `json:"date"`
-		OpeningBalance    float64            `json:"openingBalance"`
-		ClosingBalance    float64            `json:"closingBalance"`
-		CurrencyBreakdown map[string]float64 `json:"currencyBreakdown"`
-		Notes             string             `json:"notes"`
+		BranchID          uint                                    `json:"branchId"`
+		Date              string                                  `json:"date"`
+		OpeningBalance    float64                                 `json:"openingBalance"`
+		ClosingBalance    float64                                 `json:"closingBalance"`
+		CurrencyBreakdown map[string]float64                      `json:"currencyBreakdown"`
+		Denominations     map[string][]services.DenominationCount `json:"denominations"`
+		Notes             string                                  `json:"notes"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -65,7 +67,12 @@ func (h *ReconciliationHandler) CreateReconciliationHandler(w http.ResponseWrite
 		reconciliation.Notes = &req.Notes
 	}
 
-	if err := h.ReconciliationService.CreateReconciliation(reconciliation); err != nil {
+	if err := h.ReconciliationService.CreateReconciliation(reconciliation, req.Denominations); err != nil {
+		var mismatch *services.DenominationMismatchError
+		if errors.As(err, &mismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to create reconciliation: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -162,3 +169,95 @@ func (h *ReconciliationHandler) GetSystemStateHandler(w http.ResponseWriter, r *
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(systemState)
 }
+
+// AutoMatchHandler returns a branch's expected closing balance per currency for a day, so the
+// reconciliation form can be pre-filled and staff only enter what they counted
+func (h *ReconciliationHandler) AutoMatchHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	var branchID uint
+	if _, err := fmt.Sscanf(r.URL.Query().Get("branchId"), "%d", &branchID); err != nil {
+		http.Error(w, "Invalid Branch ID", http.StatusBadRequest)
+		return
+	}
+
+	date := time.Now()
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Invalid date format", http.StatusBadRequest)
+			return
+		}
+		date = parsed
+	}
+
+	match, err := h.ReconciliationService.AutoMatch(*tenantID, branchID, date, nil)
+	if err != nil {
+		http.Error(w, "Failed to match reconciliation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, match)
+}
+
+// CreateMatchedReconciliationHandler saves a reconciliation from the counted amounts per currency,
+// taking the expected figures from the auto-match and flagging variances beyond the tolerance
+func (h *ReconciliationHandler) CreateMatchedReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	user := r.Context().Value("user").(*models.User)
+
+	var req struct {
+		BranchID      uint                                    `json:"branchId"`
+		Date          string                                  `json:"date"`
+		Counted       map[string]float64                      `json:"counted"`
+		Denominations map[string][]services.DenominationCount `json:"denominations"`
+		Notes         string                                  `json:"notes"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		http.Error(w, "Invalid date format", http.StatusBadRequest)
+		return
+	}
+
+	reconciliation := &models.DailyReconciliation{
+		TenantID:        *tenantID,
+		BranchID:        req.BranchID,
+		Date:            date,
+		CreatedByUserID: user.ID,
+	}
+
+	if req.Notes != "" {
+		reconciliation.Notes = &req.Notes
+	}
+
+	match, err := h.ReconciliationService.CreateMatchedReconciliation(reconciliation, req.Counted, req.Denominations)
+	if err != nil {
+		var mismatch *services.DenominationMismatchError
+		if errors.As(err, &mismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to create reconciliation: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"reconciliation": reconciliation,
+		"match":          match,
+	})
+}
@@ -4,29 +4,36 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 // CreateOutgoingRemittanceRequest represents the request to create outgoing remittance
 type CreateOutgoingRemittanceRequest struct {
-	SenderName       string  `json:"senderName"`
-	SenderPhone      string  `json:"senderPhone"`
-	SenderEmail      *string `json:"senderEmail"`
-	RecipientName    string  `json:"recipientName"`
-	RecipientPhone   *string `json:"recipientPhone"`
-	RecipientIBAN    *string `json:"recipientIban"`
-	RecipientBank    *string `json:"recipientBank"`
-	RecipientAddress *string `json:"recipientAddress"`
-	AmountIRR        float64 `json:"amountIrr"`
-	BuyRateCAD       float64 `json:"buyRateCad"`
-	ReceivedCAD      float64 `json:"receivedCad"`
-	FeeCAD           float64 `json:"feeCAD"`
-	Notes            *string `json:"notes"`
-	InternalNotes    *string `json:"internalNotes"`
+	SenderName         string  `json:"senderName"`
+	SenderPhone        string  `json:"senderPhone"`
+	SenderEmail        *string `json:"senderEmail"`
+	RecipientName      string  `json:"recipientName"`
+	RecipientPhone     *string `json:"recipientPhone"`
+	RecipientIBAN      *string `json:"recipientIban"`
+	RecipientBank      *string `json:"recipientBank"`
+	RecipientAddress   *string `json:"recipientAddress"`
+	AmountIRR          float64 `json:"amountIrr"`
+	BuyRateCAD         float64 `json:"buyRateCad"`
+	ReceivedCAD        float64 `json:"receivedCad"`
+	FeeCAD             float64 `json:"feeCAD"`
+	SettlementCurrency string  `json:"settlementCurrency"` // Currency the customer paid in (defaults to CAD)
+	TransactionID      *string `json:"transactionId"`      // Transaction the customer paid through, if any
+	Notes              *string `json:"notes"`
+	InternalNotes      *string `json:"internalNotes"`
 }
 
 // CreateIncomingRemittanceRequest represents the request to create incoming remittance
@@ -54,6 +61,16 @@ type SettleRemittanceRequest struct {
 	Notes                *string `json:"notes"`
 }
 
+// SettleMultipleRequest represents the request to settle one incoming remittance against several outgoing ones
+type SettleMultipleRequest struct {
+	IncomingRemittanceID uint `json:"incomingRemittanceId"`
+	Allocations          []struct {
+		OutgoingRemittanceID uint    `json:"outgoingRemittanceId"`
+		AmountIRR            float64 `json:"amountIrr"`
+	} `json:"allocations"`
+	Notes *string `json:"notes"`
+}
+
 // MarkAsPaidRequest represents the request to mark incoming as paid
 type MarkAsPaidRequest struct {
 	PaymentMethod    string  `json:"paymentMethod"`
@@ -101,28 +118,40 @@ func (h *Handler) CreateOutgoingRemittance(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// The *CAD amounts are in the currency the customer paid in
+	settlementCurrency := strings.ToUpper(strings.TrimSpace(req.SettlementCurrency))
+	if settlementCurrency == "" {
+		settlementCurrency = "CAD"
+	}
+
 	remittance := &models.OutgoingRemittance{
-		TenantID:         *user.TenantID,
-		BranchID:         user.PrimaryBranchID,
-		SenderName:       req.SenderName,
-		SenderPhone:      req.SenderPhone,
-		SenderEmail:      req.SenderEmail,
-		RecipientName:    req.RecipientName,
-		RecipientPhone:   req.RecipientPhone,
-		RecipientIBAN:    req.RecipientIBAN,
-		RecipientBank:    req.RecipientBank,
-		RecipientAddress: req.RecipientAddress,
-		AmountIRR:        models.NewDecimal(req.AmountIRR),
-		BuyRateCAD:       models.NewDecimal(req.BuyRateCAD),
-		ReceivedCAD:      models.NewDecimal(req.ReceivedCAD),
-		FeeCAD:           models.NewDecimal(req.FeeCAD),
-		Notes:            req.Notes,
-		InternalNotes:    req.InternalNotes,
-		CreatedBy:        user.ID,
+		TenantID:           *user.TenantID,
+		BranchID:           user.PrimaryBranchID,
+		SenderName:         req.SenderName,
+		SenderPhone:        req.SenderPhone,
+		SenderEmail:        req.SenderEmail,
+		RecipientName:      req.RecipientName,
+		RecipientPhone:     req.RecipientPhone,
+		RecipientIBAN:      req.RecipientIBAN,
+		RecipientBank:      req.RecipientBank,
+		RecipientAddress:   req.RecipientAddress,
+		AmountIRR:          requestAmount(req.AmountIRR, "IRR"),
+		BuyRateCAD:         requestRate(req.BuyRateCAD),
+		ReceivedCAD:        requestAmount(req.ReceivedCAD, settlementCurrency),
+		FeeCAD:             requestAmount(req.FeeCAD, settlementCurrency),
+		SettlementCurrency: settlementCurrency,
+		TransactionID:      req.TransactionID,
+		Notes:              req.Notes,
+		InternalNotes:      req.InternalNotes,
+		CreatedBy:          user.ID,
 	}
 
 	remittanceService := services.NewRemittanceService(h.db)
 	if err := remittanceService.CreateOutgoingRemittance(remittance); err != nil {
+		if errors.Is(err, services.ErrSamePartyRemittance) || errors.Is(err, services.ErrRemittanceTransactionNotFound) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -177,9 +206,9 @@ func (h *Handler) CreateIncomingRemittance(w http.ResponseWriter, r *http.Reques
 		RecipientPhone:   req.RecipientPhone,
 		RecipientEmail:   req.RecipientEmail,
 		RecipientAddress: req.RecipientAddress,
-		AmountIRR:        models.NewDecimal(req.AmountIRR),
-		SellRateCAD:      models.NewDecimal(req.SellRateCAD),
-		FeeCAD:           models.NewDecimal(req.FeeCAD),
+		AmountIRR:        requestAmount(req.AmountIRR, "IRR"),
+		SellRateCAD:      requestRate(req.SellRateCAD),
+		FeeCAD:           requestAmount(req.FeeCAD, "CAD"),
 		Notes:            req.Notes,
 		InternalNotes:    req.InternalNotes,
 		CreatedBy:        user.ID,
@@ -187,6 +216,10 @@ func (h *Handler) CreateIncomingRemittance(w http.ResponseWriter, r *http.Reques
 
 	remittanceService := services.NewRemittanceService(h.db)
 	if err := remittanceService.CreateIncomingRemittance(remittance); err != nil {
+		if errors.Is(err, services.ErrSamePartyRemittance) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -224,10 +257,22 @@ func (h *Handler) SettleRemittance(w http.ResponseWriter, r *http.Request) {
 		*user.TenantID,
 		req.OutgoingRemittanceID,
 		req.IncomingRemittanceID,
-		models.NewDecimal(req.AmountIRR),
+		requestAmount(req.AmountIRR, "IRR"),
 		user.ID,
 	)
 
+	var held *services.SettlementPendingApprovalError
+	if errors.As(err, &held) {
+		if req.Notes != nil {
+			held.Pending.Notes = req.Notes
+			if err := h.db.Model(held.Pending).Update("notes", *req.Notes).Error; err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Failed to save settlement notes")
+				return
+			}
+		}
+		respondWithJSON(w, http.StatusAccepted, held.Pending)
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
@@ -241,6 +286,193 @@ func (h *Handler) SettleRemittance(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusCreated, settlement)
 }
 
+// @Summary List settlements awaiting approval
+// @Description Settlements at or above the tenant's approval limits that have not been approved or rejected yet
+// @Tags Remittances
+// @Produce json
+// @Success 200 {array} models.PendingSettlement
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/settlements/pending [get]
+func (h *Handler) GetPendingSettlements(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	pending, err := services.NewRemittanceService(h.db).GetPendingSettlements(*user.TenantID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pending)
+}
+
+// @Summary Approve a pending settlement
+// @Description Apply a settlement held for approval. The approver must be an owner or admin other than its creator.
+// @Tags Remittances
+// @Produce json
+// @Param id path int true "Pending settlement ID"
+// @Success 201 {object} models.RemittanceSettlement
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/settlements/{id}/approve [post]
+func (h *Handler) ApproveSettlement(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pending settlement ID")
+		return
+	}
+
+	settlement, err := services.NewRemittanceService(h.db).ApproveSettlement(*user.TenantID, uint(id), user)
+	if err != nil {
+		respondWithError(w, settlementDecisionStatus(err), err.Error())
+		return
+	}
+
+	h.auditService.LogAction(user.ID, user.TenantID, models.ActionApproveSettlement, "PendingSettlement", strconv.FormatUint(id, 10),
+		fmt.Sprintf("Approved settlement of %s IRR", settlement.SettledAmountIRR.String()),
+		nil, map[string]interface{}{"settlementId": settlement.ID, "profitCad": settlement.ProfitCAD}, r)
+
+	respondWithJSON(w, http.StatusCreated, settlement)
+}
+
+// @Summary Reject a pending settlement
+// @Description Decline a settlement held for approval; neither remittance changes
+// @Tags Remittances
+// @Accept json
+// @Produce json
+// @Param id path int true "Pending settlement ID"
+// @Success 200 {object} models.PendingSettlement
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/settlements/{id}/reject [post]
+func (h *Handler) RejectSettlement(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pending settlement ID")
+		return
+	}
+
+	// The note is optional
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	pending, err := services.NewRemittanceService(h.db).RejectSettlement(*user.TenantID, uint(id), user, req.Note)
+	if err != nil {
+		respondWithError(w, settlementDecisionStatus(err), err.Error())
+		return
+	}
+
+	h.auditService.LogAction(user.ID, user.TenantID, models.ActionRejectSettlement, "PendingSettlement", strconv.FormatUint(id, 10),
+		fmt.Sprintf("Rejected settlement of %s IRR", pending.AmountIRR.String()),
+		nil, map[string]interface{}{"note": req.Note}, r)
+
+	respondWithJSON(w, http.StatusOK, pending)
+}
+
+// settlementDecisionStatus maps an approve or reject error to its HTTP status
+func settlementDecisionStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSettlementApprovalForbidden), errors.Is(err, services.ErrSettlementSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// @Summary Settle an incoming remittance against several outgoing remittances
+// @Description Apply every allocation of one incoming remittance in a single transaction; all or none are saved
+// @Tags Remittances
+// @Accept json
+// @Produce json
+// @Param settlement body SettleMultipleRequest true "Incoming remittance and allocations"
+// @Success 201 {array} models.RemittanceSettlement
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/settle-multiple [post]
+func (h *Handler) SettleMultipleRemittances(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	var req SettleMultipleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	allocations := make([]services.SettlementAllocation, len(req.Allocations))
+	for i, allocation := range req.Allocations {
+		allocations[i] = services.SettlementAllocation{
+			OutgoingID: allocation.OutgoingRemittanceID,
+			AmountIRR:  requestAmount(allocation.AmountIRR, "IRR"),
+		}
+	}
+
+	remittanceService := services.NewRemittanceService(h.db)
+	settlements, err := remittanceService.SettleMultiple(*user.TenantID, req.IncomingRemittanceID, allocations, req.Notes, user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, settlements)
+}
+
+// @Summary Preview remittance settlement
+// @Description Compute the settlement and remaining balances a settle would produce, without saving anything
+// @Tags Remittances
+// @Accept json
+// @Produce json
+// @Param settlement body SettleRemittanceRequest true "Settlement Data"
+// @Success 200 {object} services.SettlementPreview
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/settle/preview [post]
+func (h *Handler) PreviewSettleRemittance(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	var req SettleRemittanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.AmountIRR <= 0 {
+		respondWithError(w, http.StatusBadRequest, "Settlement amount must be greater than 0")
+		return
+	}
+
+	remittanceService := services.NewRemittanceService(h.db)
+	preview, err := remittanceService.SettleRemittancePreview(
+		*user.TenantID,
+		req.OutgoingRemittanceID,
+		req.IncomingRemittanceID,
+		requestAmount(req.AmountIRR, "IRR"),
+	)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, preview)
+}
+
 // @Summary Get outgoing remittances
 // @Description Get list of outgoing remittances with optional filters
 // @Tags Remittances
@@ -458,6 +690,72 @@ func (h *Handler) CancelOutgoingRemittance(w http.ResponseWriter, r *http.Reques
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Remittance cancelled successfully"})
 }
 
+// @Summary Place compliance hold on outgoing remittance
+// @Description Hold an outgoing remittance so it is excluded from settlement suggestions and auto-settlement
+// @Tags Remittances
+// @Accept json
+// @Produce json
+// @Param id path int true "Remittance ID"
+// @Param hold body CancelRemittanceRequest true "Hold reason"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/outgoing/{id}/hold [post]
+func (h *Handler) HoldOutgoingRemittance(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid remittance ID")
+		return
+	}
+
+	var req CancelRemittanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	remittanceService := services.NewRemittanceService(h.db)
+	if err := remittanceService.PlaceComplianceHold(*user.TenantID, uint(id), user.ID, req.Reason); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Remittance placed on compliance hold"})
+}
+
+// @Summary Release compliance hold on outgoing remittance
+// @Description Clear a compliance hold so the remittance can be settled again
+// @Tags Remittances
+// @Produce json
+// @Param id path int true "Remittance ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /remittances/outgoing/{id}/release-hold [post]
+func (h *Handler) ReleaseOutgoingRemittanceHold(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid remittance ID")
+		return
+	}
+
+	remittanceService := services.NewRemittanceService(h.db)
+	if err := remittanceService.ReleaseComplianceHold(*user.TenantID, uint(id), user.ID); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Compliance hold released"})
+}
+
 // @Summary Cancel incoming remittance
 // @Description Cancel an incoming remittance
 // @Tags Remittances
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"api/pkg/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCreateOutgoingRemittance_StoresExactDecimals verifies float artifacts in request amounts are
+// rounded to the currency's precision before they are stored
+func TestCreateOutgoingRemittance_StoresExactDecimals(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{},
+		&models.TenantSettings{}, &models.OutgoingRemittance{}, &models.IncomingRemittance{})
+
+	tenant := &models.Tenant{Name: "Precision Exchange"}
+	db.Create(tenant)
+	user := &models.User{Email: "teller@precision.test", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(user)
+
+	// What a browser sends after summing 0.1 + 0.2 and similar float arithmetic
+	body := `{
+		"senderName": "Sara Tehrani",
+		"senderPhone": "+14165550401",
+		"recipientName": "Ali Tehrani",
+		"amountIrr": 150000000.00000003,
+		"buyRateCad": 81250.12999999999,
+		"receivedCad": 1846.1500000000001,
+		"feeCAD": 0.30000000000000004
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/remittances/outgoing", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	rr := httptest.NewRecorder()
+
+	NewHandler(db).CreateOutgoingRemittance(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var stored models.OutgoingRemittance
+	if err := db.Where("tenant_id = ?", tenant.ID).First(&stored).Error; err != nil {
+		t.Fatalf("Failed to load remittance: %v", err)
+	}
+	cases := []struct {
+		name string
+		got  models.Decimal
+		want string
+	}{
+		{"AmountIRR", stored.AmountIRR, "150000000"},
+		{"BuyRateCAD", stored.BuyRateCAD, "81250.13"},
+		{"ReceivedCAD", stored.ReceivedCAD, "1846.15"},
+		{"FeeCAD", stored.FeeCAD, "0.3"},
+	}
+	for _, c := range cases {
+		if c.got.String() != c.want {
+			t.Errorf("%s: expected %s, got %s", c.name, c.want, c.got.String())
+		}
+	}
+}
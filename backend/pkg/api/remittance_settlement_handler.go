@@ -65,7 +65,7 @@ func (h *RemittanceSettlementHandler) CreateSettlementHandler(w http.ResponseWri
 		*tenantID,
 		req.OutgoingRemittanceID,
 		req.IncomingRemittanceID,
-		models.NewDecimal(req.SettlementAmount),
+		requestAmount(req.SettlementAmount, "IRR"),
 		req.Notes,
 		user.ID,
 	)
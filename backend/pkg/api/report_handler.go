@@ -10,11 +10,15 @@ import (
 )
 
 type ReportHandler struct {
-	ReportService *services.ReportService
+	ReportService      *services.ReportService
+	preferencesService *services.UserPreferencesService
 }
 
 func NewReportHandler(service *services.ReportService) *ReportHandler {
-	return &ReportHandler{ReportService: service}
+	return &ReportHandler{
+		ReportService:      service,
+		preferencesService: services.NewUserPreferencesService(service.DB),
+	}
 }
 
 // GetDailyReportHandler generates a daily report
@@ -112,20 +116,95 @@ func (h *ReportHandler) GetCustomReportHandler(w http.ResponseWriter, r *http.Re
 	endDateStr := r.URL.Query().Get("endDate")
 	branchIDStr := r.URL.Query().Get("branchId")
 
-	if startDateStr == "" || endDateStr == "" {
-		http.Error(w, "Start and end dates required", http.StatusBadRequest)
-		return
+	var startDate, endDate time.Time
+	if preferredStart, preferredEnd, ok := userDefaultDateRange(r, h.preferencesService); ok && startDateStr == "" && endDateStr == "" {
+		startDate, endDate = preferredStart, preferredEnd
+	} else {
+		if startDateStr == "" || endDateStr == "" {
+			http.Error(w, "Start and end dates required", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			http.Error(w, "Invalid start date format", http.StatusBadRequest)
+			return
+		}
+
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			http.Error(w, "Invalid end date format", http.StatusBadRequest)
+			return
+		}
+
+		// Add one day to end date to include the entire day
+		endDate = endDate.Add(24 * time.Hour)
+	}
+
+	var branchID *uint
+	if branchIDStr != "" && branchIDStr != "all" {
+		id, err := strconv.ParseUint(branchIDStr, 10, 64)
+		if err == nil {
+			branchIDUint := uint(id)
+			branchID = &branchIDUint
+		}
 	}
 
-	startDate, err := time.Parse("2006-01-02", startDateStr)
+	report, err := h.ReportService.GenerateCustomReport(*tenantID, branchID, startDate, endDate)
 	if err != nil {
-		http.Error(w, "Invalid start date format", http.StatusBadRequest)
+		http.Error(w, "Failed to generate custom report", http.StatusInternalServerError)
 		return
 	}
 
-	endDate, err := time.Parse("2006-01-02", endDateStr)
-	if err != nil {
-		http.Error(w, "Invalid end date format", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetCancellationRateHandler reports how often transactions are voided/cancelled
+// GET /api/reports/cancellation-rate?start=YYYY-MM-DD&end=YYYY-MM-DD&branchId=
+func (h *ReportHandler) GetCancellationRateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start")
+	endDateStr := r.URL.Query().Get("end")
+	branchIDStr := r.URL.Query().Get("branchId")
+
+	// Default to the user's preferred range, or the last 30 days
+	var startDate, endDate time.Time
+	if preferredStart, preferredEnd, ok := userDefaultDateRange(r, h.preferencesService); ok && startDateStr == "" && endDateStr == "" {
+		startDate, endDate = preferredStart, preferredEnd
+	} else {
+		endDate = time.Now()
+		if endDateStr != "" {
+			parsed, err := time.Parse("2006-01-02", endDateStr)
+			if err != nil {
+				http.Error(w, "Invalid end date format", http.StatusBadRequest)
+				return
+			}
+			endDate = parsed
+		}
+		endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
+
+		startDate = endDate.AddDate(0, 0, -30)
+		if startDateStr != "" {
+			parsed, err := time.Parse("2006-01-02", startDateStr)
+			if err != nil {
+				http.Error(w, "Invalid start date format", http.StatusBadRequest)
+				return
+			}
+			startDate = parsed
+		}
+
+		// Add one day to end date to include the entire day
+		endDate = endDate.Add(24 * time.Hour)
+	}
+	if !startDate.Before(endDate) {
+		http.Error(w, "Start date must not be after end date", http.StatusBadRequest)
 		return
 	}
 
@@ -138,12 +217,9 @@ func (h *ReportHandler) GetCustomReportHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	// Add one day to end date to include the entire day
-	endDate = endDate.Add(24 * time.Hour)
-
-	report, err := h.ReportService.GenerateCustomReport(*tenantID, branchID, startDate, endDate)
+	report, err := h.ReportService.GetCancellationRate(*tenantID, branchID, startDate, endDate)
 	if err != nil {
-		http.Error(w, "Failed to generate custom report", http.StatusInternalServerError)
+		http.Error(w, "Failed to generate cancellation rate report", http.StatusInternalServerError)
 		return
 	}
 
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"api/pkg/models"
+	"api/pkg/utils"
+)
+
+// remittanceRatePlaces matches the scale of the remittance rate columns
+const remittanceRatePlaces = 6
+
+// requestAmount converts a float amount from a request body into a Decimal at the currency's precision,
+// so float artifacts such as 0.30000000000000004 never reach the ledger
+func requestAmount(amount float64, currency string) models.Decimal {
+	return models.NewDecimal(amount).Round(int32(utils.GetDecimalPlaces(currency)))
+}
+
+// requestRate converts a float exchange rate from a request body into a Decimal at the rate columns' scale
+func requestRate(rate float64) models.Decimal {
+	return models.NewDecimal(rate).Round(remittanceRatePlaces)
+}
@@ -39,6 +39,7 @@ func NewRouter(db *gorm.DB) http.Handler {
 	pickupHandler := NewPickupHandler(db)
 	customerHandler := NewCustomerHandler(db)
 	cashBalanceHandler := NewCashBalanceHandler(db)
+	dailyCloseHandler := NewDailyCloseHandler(db)
 	statisticsHandler := NewStatisticsHandler(statisticsService)
 	exchangeRateHandler := NewExchangeRateHandler(exchangeRateService)
 	reconciliationHandler := NewReconciliationHandler(reconciliationService)
@@ -59,6 +60,9 @@ func NewRouter(db *gorm.DB) http.Handler {
 	navasanHandler := NewNavasanHandler()
 	transferHandler := NewTransferHandler(transferService)
 	feeHandler := NewFeeHandler(db)
+	exportProfileHandler := NewExportProfileHandler(db)
+	userPreferencesHandler := NewUserPreferencesHandler(db)
+	trackingHandler := NewTrackingHandler(db)
 
 	// =============================================================================
 	// API VERSIONING STRATEGY
@@ -90,6 +94,7 @@ func NewRouter(db *gorm.DB) http.Handler {
 			authRouter.HandleFunc("/verify-email", authHandler.VerifyEmailHandler).Methods("POST")
 			authRouter.HandleFunc("/resend-code", authHandler.ResendVerificationCodeHandler).Methods("POST")
 			authRouter.HandleFunc("/login", authHandler.LoginHandler).Methods("POST")
+			authRouter.HandleFunc("/2fa/verify", authHandler.Verify2FAHandler).Methods("POST")
 			authRouter.HandleFunc("/forgot-password", authHandler.ForgotPasswordHandler).Methods("POST")
 			authRouter.HandleFunc("/reset-password", authHandler.ResetPasswordHandler).Methods("POST")
 			authRouter.HandleFunc("/refresh", authHandler.RefreshTokenHandler).Methods("POST")
@@ -111,24 +116,38 @@ func NewRouter(db *gorm.DB) http.Handler {
 			api.HandleFunc("/rates/fetch-external", handler.FetchExternalRatesHandler).Methods("GET")
 		}
 
+		// Remittance status page (public, IP rate limited so tracking codes cannot be enumerated)
+		for _, api := range []*mux.Router{v1, legacy} {
+			track := api.PathPrefix("/track").Subrouter()
+			track.Use(middleware.TrackingRateLimitMiddleware(db, 30, 1*time.Minute))
+			track.HandleFunc("/{code}", trackingHandler.TrackRemittanceHandler).Methods("GET")
+		}
+
 		// ============ PROTECTED ROUTES (Authentication Required) ============
 
 		// Create protected subrouters with rate limiting
 		protectedV1 := v1.PathPrefix("").Subrouter()
+		protectedV1.Use(middleware.APIKeyAuthMiddleware(db, middleware.DefaultAPIKeyRoutes()))
 		protectedV1.Use(middleware.AuthMiddleware(db))
-		protectedV1.Use(middleware.RateLimitMiddleware(db, 100, 1*time.Minute))
+		protectedV1.Use(middleware.PolicyRateLimitMiddleware(db, middleware.DefaultRateLimitPolicy()))
 		protectedV1.Use(middleware.TenantIsolationMiddleware)
 
 		protectedLegacy := legacy.PathPrefix("").Subrouter()
+		protectedLegacy.Use(middleware.APIKeyAuthMiddleware(db, middleware.DefaultAPIKeyRoutes()))
 		protectedLegacy.Use(middleware.AuthMiddleware(db))
-		protectedLegacy.Use(middleware.RateLimitMiddleware(db, 100, 1*time.Minute))
+		protectedLegacy.Use(middleware.PolicyRateLimitMiddleware(db, middleware.DefaultRateLimitPolicy()))
 		protectedLegacy.Use(middleware.TenantIsolationMiddleware)
 
 		for _, protected := range []*mux.Router{protectedV1, protectedLegacy} {
 			// Auth routes (protected)
 			protected.HandleFunc("/auth/me", authHandler.GetMeHandler).Methods("GET")
+			protected.HandleFunc("/me/preferences", userPreferencesHandler.GetMyPreferencesHandler).Methods("GET")
+			protected.HandleFunc("/me/preferences", userPreferencesHandler.UpdateMyPreferencesHandler).Methods("PUT")
 			protected.HandleFunc("/auth/change-password", authHandler.ChangePasswordHandler).Methods("POST")
 			protected.HandleFunc("/auth/logout", authHandler.LogoutHandler).Methods("POST")
+			protected.HandleFunc("/auth/2fa/enable", authHandler.EnableTwoFactorHandler).Methods("POST")
+			protected.HandleFunc("/auth/2fa/activate", authHandler.ActivateTwoFactorHandler).Methods("POST")
+			protected.HandleFunc("/auth/2fa/disable", authHandler.DisableTwoFactorHandler).Methods("POST")
 
 			// Migration routes (protected - tenant owner only)
 			protected.HandleFunc("/migrations/fix-owner-branch", migrationHandler.FixOwnerBranchHandler).Methods("POST")
@@ -141,36 +160,80 @@ func NewRouter(db *gorm.DB) http.Handler {
 			// Transaction routes (protected)
 			protected.HandleFunc("/transactions", handler.GetTransactions).Methods("GET")
 			protected.Handle("/transactions", middleware.WithIdempotency(db, 24*time.Hour, middleware.ValidateRequestMiddleware(models.Transaction{}, handler.CreateTransaction))).Methods("POST")
+			// Registered before /transactions/{id} so "search" is not taken for an ID
+			protected.HandleFunc("/transactions/search", handler.SearchTransactions).Methods("GET")
 			protected.HandleFunc("/transactions/{id}", handler.GetTransaction).Methods("GET")
 			protected.HandleFunc("/transactions/{id}", handler.UpdateTransaction).Methods("PUT")
 			protected.HandleFunc("/transactions/{id}/cancel", handler.CancelTransaction).Methods("POST")
+			protected.HandleFunc("/transactions/{id}/profit-breakdown", handler.GetTransactionProfitBreakdown).Methods("GET")
+			protected.HandleFunc("/transactions/{id}/rate-audit", handler.GetTransactionRateAudit).Methods("GET")
 			protected.HandleFunc("/transactions/{id}", handler.DeleteTransaction).Methods("DELETE")
-			protected.HandleFunc("/transactions/search", handler.SearchTransactions).Methods("GET")
+			protected.HandleFunc("/transactions/{id}/restore", handler.RestoreTransaction).Methods("POST")
 
 			// Payment routes (protected)
+			protected.Handle("/transactions/payments/batch", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(paymentHandler.CreatePaymentsBatchHandler))).Methods("POST")
 			protected.Handle("/transactions/{id}/payments", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(paymentHandler.CreatePaymentHandler))).Methods("POST")
 			protected.HandleFunc("/transactions/{id}/payments", paymentHandler.GetPaymentsHandler).Methods("GET")
 			protected.HandleFunc("/transactions/{id}/complete", paymentHandler.CompleteTransactionHandler).Methods("POST")
+			protected.HandleFunc("/transactions/{id}/void", paymentHandler.VoidTransactionHandler).Methods("POST")
+			protected.HandleFunc("/transactions/{id}/payment-plan", paymentHandler.CreatePaymentPlanHandler).Methods("POST")
+			protected.HandleFunc("/transactions/{id}/payment-plan", paymentHandler.GetPaymentPlanHandler).Methods("GET")
 			protected.HandleFunc("/payments/{id}", paymentHandler.GetPaymentHandler).Methods("GET")
 			protected.HandleFunc("/payments/{id}", paymentHandler.UpdatePaymentHandler).Methods("PUT")
 			protected.HandleFunc("/payments/{id}", paymentHandler.DeletePaymentHandler).Methods("DELETE")
 			protected.HandleFunc("/payments/{id}/cancel", paymentHandler.CancelPaymentHandler).Methods("POST")
+			protected.HandleFunc("/payments/{id}/refund", paymentHandler.RefundPaymentHandler).Methods("POST")
+
+			// Transaction attachment routes (protected)
+			attachmentHandler := NewTransactionAttachmentHandler(db)
+			protected.HandleFunc("/transactions/{id}/attachments", attachmentHandler.UploadAttachmentHandler).Methods("POST")
+			protected.HandleFunc("/transactions/{id}/attachments", attachmentHandler.GetAttachmentsHandler).Methods("GET")
+			protected.HandleFunc("/transactions/{id}/attachments/{attachmentId}", attachmentHandler.DeleteAttachmentHandler).Methods("DELETE")
+
+			// API key routes (protected, owners and admins)
+			apiKeyHandler := NewAPIKeyHandler(db)
+			protected.HandleFunc("/api-keys", apiKeyHandler.GetAPIKeysHandler).Methods("GET")
+			protected.HandleFunc("/api-keys", apiKeyHandler.CreateAPIKeyHandler).Methods("POST")
+			protected.HandleFunc("/api-keys/{id}/revoke", apiKeyHandler.RevokeAPIKeyHandler).Methods("POST")
+
+			// Webhook routes (protected, owners and admins)
+			webhookHandler := NewWebhookHandler(db)
+			protected.HandleFunc("/webhooks", webhookHandler.GetWebhookEndpointsHandler).Methods("GET")
+			protected.HandleFunc("/webhooks", webhookHandler.CreateWebhookEndpointHandler).Methods("POST")
+			protected.HandleFunc("/webhooks/deliveries", webhookHandler.GetWebhookDeliveriesHandler).Methods("GET")
+			protected.HandleFunc("/webhooks/{id}", webhookHandler.UpdateWebhookEndpointHandler).Methods("PUT")
+			protected.HandleFunc("/webhooks/{id}", webhookHandler.DeleteWebhookEndpointHandler).Methods("DELETE")
+
+			// Transaction template routes (protected)
+			templateHandler := NewTransactionTemplateHandler(db)
+			protected.HandleFunc("/transaction-templates", templateHandler.GetTransactionTemplatesHandler).Methods("GET")
+			protected.HandleFunc("/transaction-templates", templateHandler.CreateTransactionTemplateHandler).Methods("POST")
+			protected.HandleFunc("/transaction-templates/{templateId}", templateHandler.UpdateTransactionTemplateHandler).Methods("PUT")
+			protected.HandleFunc("/transaction-templates/{templateId}", templateHandler.DeleteTransactionTemplateHandler).Methods("DELETE")
+			protected.HandleFunc("/transaction-templates/{templateId}/transaction", templateHandler.NewTransactionFromTemplateHandler).Methods("POST")
 
 			// Remittance routes (protected)
 			protected.Handle("/remittances/outgoing", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(handler.CreateOutgoingRemittance))).Methods("POST")
 			protected.HandleFunc("/remittances/outgoing", handler.GetOutgoingRemittances).Methods("GET")
 			protected.HandleFunc("/remittances/outgoing/{id}", handler.GetOutgoingRemittanceDetails).Methods("GET")
 			protected.HandleFunc("/remittances/outgoing/{id}/cancel", handler.CancelOutgoingRemittance).Methods("POST")
+			protected.HandleFunc("/remittances/outgoing/{id}/hold", handler.HoldOutgoingRemittance).Methods("POST")
+			protected.HandleFunc("/remittances/outgoing/{id}/release-hold", handler.ReleaseOutgoingRemittanceHold).Methods("POST")
 			protected.Handle("/remittances/incoming", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(handler.CreateIncomingRemittance))).Methods("POST")
 			protected.HandleFunc("/remittances/incoming", handler.GetIncomingRemittances).Methods("GET")
 			protected.HandleFunc("/remittances/incoming/{id}", handler.GetIncomingRemittanceDetails).Methods("GET")
 			protected.HandleFunc("/remittances/incoming/{id}/mark-paid", handler.MarkIncomingAsPaid).Methods("POST")
 			protected.HandleFunc("/remittances/incoming/{id}/cancel", handler.CancelIncomingRemittance).Methods("POST")
 			protected.Handle("/remittances/settle", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(handler.SettleRemittance))).Methods("POST")
+			protected.HandleFunc("/remittances/settle/preview", handler.PreviewSettleRemittance).Methods("POST")
+			protected.Handle("/remittances/settle-multiple", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(handler.SettleMultipleRemittances))).Methods("POST")
 			protected.HandleFunc("/remittances/profit-summary", handler.GetRemittanceProfitSummary).Methods("GET")
 
 			// Settlement routes
 			protected.Handle("/remittances/settlements", middleware.WithIdempotency(db, 24*time.Hour, http.HandlerFunc(settlementHandler.CreateSettlementHandler))).Methods("POST")
+			protected.HandleFunc("/remittances/settlements/pending", handler.GetPendingSettlements).Methods("GET")
+			protected.HandleFunc("/remittances/settlements/{id}/approve", handler.ApproveSettlement).Methods("POST")
+			protected.HandleFunc("/remittances/settlements/{id}/reject", handler.RejectSettlement).Methods("POST")
 			protected.HandleFunc("/remittances/{id}/settlements", settlementHandler.GetSettlementHistoryHandler).Methods("GET")
 			protected.HandleFunc("/remittances/{id}/settlement-summary", settlementHandler.GetSettlementSummaryHandler).Methods("GET")
 			protected.HandleFunc("/remittances/unsettled", settlementHandler.GetUnsettledRemittancesHandler).Methods("GET")
@@ -188,6 +251,7 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/clients/{id}", handler.DeleteClient).Methods("DELETE")
 			protected.HandleFunc("/clients/{id}/transactions", handler.GetClientTransactions).Methods("GET")
 			protected.HandleFunc("/clients/search", handler.SearchClients).Methods("GET")
+			protected.HandleFunc("/clients/import", handler.ImportClients).Methods("POST")
 
 			// Audit logs (protected)
 			protected.HandleFunc("/audit-logs", auditHandler.GetAuditLogsHandler).Methods("GET")
@@ -195,6 +259,8 @@ func NewRouter(db *gorm.DB) http.Handler {
 			// Tenant routes (protected)
 			protected.HandleFunc("/tenant/info", handler.GetTenantInfo).Methods("GET")
 			protected.HandleFunc("/tenant/update-name", handler.UpdateTenantName).Methods("PUT")
+			protected.HandleFunc("/tenant/settings", handler.GetTenantSettings).Methods("GET")
+			protected.HandleFunc("/tenant/settings", handler.UpdateTenantSettings).Methods("PUT")
 
 			// User management routes (protected)
 			protected.HandleFunc("/users", userHandler.GetUsersHandler).Methods("GET")
@@ -212,6 +278,11 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/branches/{id}/users", userHandler.GetBranchUsersHandler).Methods("GET")
 			protected.HandleFunc("/branches/{id}/deactivate", branchHandler.DeactivateBranchHandler).Methods("POST")
 			protected.HandleFunc("/branches/{id}/assign-user", branchHandler.AssignUserToBranchHandler).Methods("POST")
+			protected.HandleFunc("/branches/{id}/default-template", receiptHandler.SetBranchDefaultTemplateHandler).Methods("PUT")
+			protected.HandleFunc("/branches/{id}/float-targets", branchHandler.GetFloatTargetsHandler).Methods("GET")
+			protected.HandleFunc("/branches/{id}/float-targets", branchHandler.CreateFloatTargetHandler).Methods("POST")
+			protected.HandleFunc("/branches/{id}/float-targets/{targetId}", branchHandler.UpdateFloatTargetHandler).Methods("PUT")
+			protected.HandleFunc("/branches/{id}/float-targets/{targetId}", branchHandler.DeleteFloatTargetHandler).Methods("DELETE")
 
 			// Pickup transaction routes (protected)
 			protected.HandleFunc("/pickups", pickupHandler.GetPickupTransactionsHandler).Methods("GET")
@@ -221,6 +292,7 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/pickups/search/{code}", pickupHandler.SearchPickupByCodeHandler).Methods("GET")
 			protected.HandleFunc("/pickups/{id}", pickupHandler.GetPickupTransactionHandler).Methods("GET")
 			protected.HandleFunc("/pickups/{id}/edit", pickupHandler.EditPickupTransactionHandler).Methods("PUT")
+			protected.HandleFunc("/pickups/{id}/ready", pickupHandler.MarkAsReadyHandler).Methods("POST")
 			protected.HandleFunc("/pickups/{id}/pickup", pickupHandler.MarkAsPickedUpHandler).Methods("POST")
 			protected.HandleFunc("/pickups/{id}/cancel", pickupHandler.CancelPickupTransactionHandler).Methods("POST")
 
@@ -234,9 +306,18 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/profit-analysis", profitAnalysisHandler.GetProfitAnalysisHandler).Methods("GET")
 			protected.HandleFunc("/profit-analysis/daily", profitAnalysisHandler.GetDailyProfitHandler).Methods("GET")
 			protected.HandleFunc("/profit-analysis/monthly", profitAnalysisHandler.GetMonthlyProfitHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/moving-average", profitAnalysisHandler.GetProfitMovingAverageHandler).Methods("GET")
 			protected.HandleFunc("/profit-analysis/by-branch", profitAnalysisHandler.GetProfitByBranchHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/by-method", profitAnalysisHandler.GetProfitByPaymentMethodHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/by-agent", profitAnalysisHandler.GetProfitByAgentHandler).Methods("GET")
 			protected.HandleFunc("/profit-analysis/trend", profitAnalysisHandler.GetProfitTrendHandler).Methods("GET")
 			protected.HandleFunc("/profit-analysis/by-customer", profitAnalysisHandler.GetTopCustomersHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/export", profitAnalysisHandler.ExportProfitAnalysisHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/margin-alerts", profitAnalysisHandler.GetMarginAlertsHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/margin-alerts/{alertId}/resolve", profitAnalysisHandler.ResolveMarginAlertHandler).Methods("PUT")
+			protected.HandleFunc("/profit-analysis/profit-floors", profitAnalysisHandler.GetProfitFloorsHandler).Methods("GET")
+			protected.HandleFunc("/profit-analysis/profit-floors", profitAnalysisHandler.SetProfitFloorHandler).Methods("PUT")
+			protected.HandleFunc("/profit-analysis/profit-floors/{floorId}", profitAnalysisHandler.DeleteProfitFloorHandler).Methods("DELETE")
 
 			// Transfer routes
 			protected.HandleFunc("/transfers", transferHandler.GetTransfersHandler).Methods("GET")
@@ -247,6 +328,9 @@ func NewRouter(db *gorm.DB) http.Handler {
 			// Dashboard routes
 			protected.HandleFunc("/dashboard", dashboardHandler.GetDashboardHandler).Methods("GET")
 			protected.HandleFunc("/dashboard/stats", dashboardHandler.GetDashboardSummaryHandler).Methods("GET")
+			protected.HandleFunc("/dashboard/cash-flow-forecast", dashboardHandler.GetCashFlowForecastHandler).Methods("GET")
+			protected.HandleFunc("/dashboard/debt-aging-buckets", dashboardHandler.GetDebtAgingBucketsHandler).Methods("GET")
+			protected.HandleFunc("/dashboard/debt-aging-buckets", dashboardHandler.SetDebtAgingBucketsHandler).Methods("PUT")
 
 			// Receipt generation routes
 			protected.HandleFunc("/receipts/outgoing/{id}", receiptHandler.GetOutgoingRemittanceReceiptHandler).Methods("GET")
@@ -259,13 +343,19 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/customers/search", customerHandler.SearchCustomersHandler).Methods("GET")
 			protected.HandleFunc("/customers/phone/{phone}", customerHandler.GetCustomerByPhoneHandler).Methods("GET")
 			protected.HandleFunc("/customers/find-or-create", customerHandler.FindOrCreateCustomerHandler).Methods("POST")
+			protected.HandleFunc("/customers/message", customerHandler.SendCustomerMessageHandler).Methods("POST")
+			protected.HandleFunc("/customers/campaigns", customerHandler.GetCustomerCampaignsHandler).Methods("GET")
 			protected.HandleFunc("/customers/{id}", customerHandler.UpdateCustomerHandler).Methods("PUT")
+			protected.HandleFunc("/customers/{id}/tags", customerHandler.SetCustomerTagsHandler).Methods("PUT")
+			protected.HandleFunc("/customers/{id}/consent", customerHandler.UpdateCustomerConsentHandler).Methods("PUT")
 
 			// Ledger routes (protected)
 			protected.HandleFunc("/clients/{id}/ledger/balance", ledgerHandler.GetClientBalances).Methods("GET")
 			protected.HandleFunc("/clients/{id}/ledger/entries", ledgerHandler.GetClientEntries).Methods("GET")
 			protected.HandleFunc("/clients/{id}/ledger/entry", ledgerHandler.AddEntry).Methods("POST")
 			protected.HandleFunc("/clients/{id}/ledger/exchange", ledgerHandler.Exchange).Methods("POST")
+			protected.HandleFunc("/clients/{id}/ledger/statement", ledgerHandler.GetClientStatement).Methods("GET")
+			protected.HandleFunc("/clients/{id}/ledger/statement/close", ledgerHandler.CloseStatementPeriod).Methods("POST")
 
 			// Cash balance routes (protected)
 			protected.HandleFunc("/cash-balances", cashBalanceHandler.GetAllBalancesHandler).Methods("GET")
@@ -273,7 +363,21 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/cash-balances/refresh-all", cashBalanceHandler.RefreshAllBalancesHandler).Methods("POST")
 			protected.HandleFunc("/cash-balances/adjust", cashBalanceHandler.CreateAdjustmentHandler).Methods("POST")
 			protected.HandleFunc("/cash-balances/adjustments", cashBalanceHandler.GetAdjustmentHistoryHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/thresholds", cashBalanceHandler.GetThresholdsHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/thresholds/{currency}", cashBalanceHandler.SetThresholdHandler).Methods("PUT")
+			protected.HandleFunc("/cash-balances/thresholds/{currency}", cashBalanceHandler.DeleteThresholdHandler).Methods("DELETE")
+			protected.HandleFunc("/cash-balances/adjustments/pending", cashBalanceHandler.GetPendingAdjustmentsHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/adjustments/{id}/approve", cashBalanceHandler.ApproveAdjustmentHandler).Methods("POST")
+			protected.HandleFunc("/cash-balances/adjustments/{id}/reject", cashBalanceHandler.RejectAdjustmentHandler).Methods("POST")
+			protected.HandleFunc("/cash-balances/approval-limits", cashBalanceHandler.GetApprovalLimitsHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/approval-limits/{currency}", cashBalanceHandler.SetApprovalLimitHandler).Methods("PUT")
+			protected.HandleFunc("/cash-balances/approval-limits/{currency}", cashBalanceHandler.DeleteApprovalLimitHandler).Methods("DELETE")
+			protected.HandleFunc("/cash-balances/close", dailyCloseHandler.CloseDayHandler).Methods("POST")
+			protected.HandleFunc("/cash-balances/reopen", dailyCloseHandler.ReopenDayHandler).Methods("POST")
+			protected.HandleFunc("/cash-balances/closes", dailyCloseHandler.GetClosesHandler).Methods("GET")
 			protected.HandleFunc("/cash-balances/{currency}", cashBalanceHandler.GetBalanceByCurrencyHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/{currency}/denominations", cashBalanceHandler.GetDenominationsHandler).Methods("GET")
+			protected.HandleFunc("/cash-balances/{currency}/denominations", cashBalanceHandler.SetDenominationsHandler).Methods("PUT")
 			protected.HandleFunc("/cash-balances/{id}/refresh", cashBalanceHandler.RefreshBalanceHandler).Methods("POST")
 
 			// Statistics and export routes (protected)
@@ -281,6 +385,14 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/export/csv", statisticsHandler.ExportCSVHandler).Methods("GET")
 			protected.HandleFunc("/export/json", statisticsHandler.ExportJSONHandler).Methods("GET")
 			protected.HandleFunc("/export/pdf", statisticsHandler.ExportPDFHandler).Methods("GET")
+			protected.HandleFunc("/exports/profiles", exportProfileHandler.GetExportProfilesHandler).Methods("GET")
+			protected.HandleFunc("/exports/profiles", exportProfileHandler.CreateExportProfileHandler).Methods("POST")
+			protected.HandleFunc("/exports/profiles/{profileId}", exportProfileHandler.UpdateExportProfileHandler).Methods("PUT")
+			protected.HandleFunc("/exports/profiles/{profileId}", exportProfileHandler.DeleteExportProfileHandler).Methods("DELETE")
+			protected.HandleFunc("/exports/{profileId}/run", exportProfileHandler.RunExportProfileHandler).Methods("POST")
+			protected.HandleFunc("/exports/schedules", exportProfileHandler.GetScheduledExportsHandler).Methods("GET")
+			protected.HandleFunc("/exports/schedules", exportProfileHandler.CreateScheduledExportHandler).Methods("POST")
+			protected.HandleFunc("/exports/schedules/{scheduleId}", exportProfileHandler.DeleteScheduledExportHandler).Methods("DELETE")
 
 			// Exchange Rate routes
 			protected.HandleFunc("/rates", exchangeRateHandler.GetAllRatesHandler).Methods("GET")
@@ -293,11 +405,14 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/reconciliation", reconciliationHandler.GetReconciliationHistoryHandler).Methods("GET")
 			protected.HandleFunc("/reconciliation/variance", reconciliationHandler.GetVarianceReportHandler).Methods("GET")
 			protected.HandleFunc("/reconciliation/system-state", reconciliationHandler.GetSystemStateHandler).Methods("GET")
+			protected.HandleFunc("/reconciliation/auto-match", reconciliationHandler.AutoMatchHandler).Methods("GET")
+			protected.HandleFunc("/reconciliation/auto-match", reconciliationHandler.CreateMatchedReconciliationHandler).Methods("POST")
 
 			// Report Dashboard routes
 			protected.HandleFunc("/reports/daily", reportHandler.GetDailyReportHandler).Methods("GET")
 			protected.HandleFunc("/reports/monthly", reportHandler.GetMonthlyReportHandler).Methods("GET")
 			protected.HandleFunc("/reports/custom", reportHandler.GetCustomReportHandler).Methods("GET")
+			protected.HandleFunc("/reports/cancellation-rate", reportHandler.GetCancellationRateHandler).Methods("GET")
 
 			// Search routes (protected)
 			protected.HandleFunc("/search/global", searchHandler.GlobalSearchHandler).Methods("GET")
@@ -325,6 +440,11 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/compliance/check", complianceHandler.CheckTransactionComplianceHandler).Methods("POST")
 			protected.HandleFunc("/compliance/pending", complianceHandler.GetPendingReviewsHandler).Methods("GET")
 			protected.HandleFunc("/compliance/expiring", complianceHandler.GetExpiringComplianceHandler).Methods("GET")
+			protected.HandleFunc("/compliance/alerts", complianceHandler.GetAlertsHandler).Methods("GET")
+			protected.HandleFunc("/compliance/alerts/{alertId}/resolve", complianceHandler.ResolveAlertHandler).Methods("PUT")
+			protected.HandleFunc("/compliance/structuring-rules", complianceHandler.GetStructuringRulesHandler).Methods("GET")
+			protected.HandleFunc("/compliance/structuring-rules", complianceHandler.SetStructuringRuleHandler).Methods("PUT")
+			protected.HandleFunc("/compliance/structuring-rules/{ruleId}", complianceHandler.DeleteStructuringRuleHandler).Methods("DELETE")
 			protected.HandleFunc("/compliance/{id}/status", complianceHandler.UpdateComplianceStatusHandler).Methods("PUT")
 			protected.HandleFunc("/compliance/{id}/limits", complianceHandler.SetTransactionLimitsHandler).Methods("PUT")
 			protected.HandleFunc("/compliance/{id}/documents", complianceHandler.GetDocumentsHandler).Methods("GET")
@@ -332,7 +452,11 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/compliance/{id}/audit", complianceHandler.GetAuditLogHandler).Methods("GET")
 			protected.HandleFunc("/compliance/{id}/verify", complianceHandler.InitiateVerificationHandler).Methods("POST")
 			protected.HandleFunc("/compliance/{id}/verify/status", complianceHandler.GetVerificationStatusHandler).Methods("GET")
+			protected.HandleFunc("/compliance/{id}/screen", complianceHandler.ScreenCustomerHandler).Methods("POST")
 			protected.HandleFunc("/compliance/documents/{docId}/review", complianceHandler.ReviewDocumentHandler).Methods("PUT")
+			protected.HandleFunc("/compliance/documents/{docId}/download", complianceHandler.DownloadDocumentHandler).Methods("GET")
+			protected.HandleFunc("/compliance/{id}/branch", complianceHandler.RouteComplianceHandler).Methods("PUT")
+			protected.HandleFunc("/compliance/branches/{branchId}/officer", complianceHandler.AssignComplianceOfficerHandler).Methods("PUT")
 
 			// Ticket management routes (protected)
 			ticketHandler := NewTicketHandler(db)
@@ -349,6 +473,8 @@ func NewRouter(db *gorm.DB) http.Handler {
 			protected.HandleFunc("/tickets/{id}/messages", ticketHandler.GetMessagesHandler).Methods("GET")
 			protected.HandleFunc("/tickets/{id}/messages", ticketHandler.AddMessageHandler).Methods("POST")
 			protected.HandleFunc("/tickets/{id}/resolve", ticketHandler.ResolveTicketHandler).Methods("POST")
+			protected.HandleFunc("/tickets/{id}/merge", ticketHandler.MergeTicketHandler).Methods("POST")
+			protected.HandleFunc("/tickets/{id}/satisfaction", ticketHandler.RecordSatisfactionHandler).Methods("POST")
 			protected.HandleFunc("/tickets/{id}/activity", ticketHandler.GetTicketActivityHandler).Methods("GET")
 
 			// Receipt template routes (protected) - uses receiptHandler defined at top
@@ -387,11 +513,13 @@ func NewRouter(db *gorm.DB) http.Handler {
 			admin.HandleFunc("/tenants/{id}", adminHandler.GetTenantByIDHandler).Methods("GET")
 			admin.HandleFunc("/tenants/{id}/suspend", adminHandler.SuspendTenantHandler).Methods("POST")
 			admin.HandleFunc("/tenants/{id}/activate", adminHandler.ActivateTenantHandler).Methods("POST")
+			admin.HandleFunc("/tenants/{id}/token-lifetimes", adminHandler.UpdateTenantTokenLifetimesHandler).Methods("PUT")
 			admin.HandleFunc("/tenants/{id}/cash-balances", adminHandler.GetTenantCashBalancesHandler).Methods("GET")
 			admin.HandleFunc("/tenants/{id}/customer-count", adminHandler.GetTenantCustomerCountHandler).Methods("GET")
 
 			// User management (SuperAdmin)
 			admin.HandleFunc("/users", adminHandler.GetAllUsersHandler).Methods("GET")
+			admin.HandleFunc("/users/{id}/unlock", adminHandler.UnlockUserHandler).Methods("POST")
 
 			// Transaction management (SuperAdmin)
 			admin.HandleFunc("/transactions", adminHandler.GetAllTransactionsHandler).Methods("GET")
@@ -2,7 +2,9 @@ package api
 
 import (
 	"api/pkg/models"
+	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -109,3 +111,74 @@ func (h *Handler) GetTenantInfo(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, response)
 }
+
+// GetTenantSettings godoc
+// @Summary Get tenant settings
+// @Description Get the configurable policies for the current tenant
+// @Tags tenant
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TenantSettings
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenant/settings [get]
+func (h *Handler) GetTenantSettings(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	if user.TenantID == nil {
+		http.Error(w, "User must belong to a tenant", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.settingsService.GetSettings(*user.TenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
+
+// UpdateTenantSettings godoc
+// @Summary Update tenant settings
+// @Description Update the configurable policies for the current tenant (tenant owner only)
+// @Tags tenant
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.UpdateTenantSettingsRequest true "Settings to change"
+// @Success 200 {object} models.TenantSettings
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tenant/settings [put]
+func (h *Handler) UpdateTenantSettings(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value("user").(*models.User)
+
+	if user.Role != "tenant_owner" {
+		http.Error(w, "Only tenant owners can update settings", http.StatusForbidden)
+		return
+	}
+	if user.TenantID == nil {
+		http.Error(w, "User must belong to a tenant", http.StatusBadRequest)
+		return
+	}
+
+	var req services.UpdateTenantSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.settingsService.UpdateSettings(*user.TenantID, req)
+	if errors.Is(err, services.ErrInvalidTenantSetting) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a fresh in-memory SQLite database and migrates the given models, stopping the test at once
+// if either step fails
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to open test database")
+	require.NoError(t, db.AutoMigrate(tables...), "failed to migrate test database")
+	return db
+}
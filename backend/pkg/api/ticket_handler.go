@@ -5,6 +5,7 @@ import (
 	"api/pkg/models"
 	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -294,6 +295,10 @@ func (h *TicketHandler) AddMessageHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	message, err := h.ticketService.AddMessage(*tenantID, uint(ticketID), userID, req.Content, req.IsInternal)
+	if errors.Is(err, services.ErrTicketMerged) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -369,6 +374,94 @@ func (h *TicketHandler) ResolveTicketHandler(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(map[string]string{"message": "Ticket resolved"})
 }
 
+// MergeTicketHandler merges a duplicate ticket into another
+// @Summary Merge duplicate ticket
+// @Tags Tickets
+// @Accept json
+// @Produce json
+// @Router /tickets/{id}/merge [post]
+func (h *TicketHandler) MergeTicketHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	ticketID, _ := strconv.ParseUint(vars["id"], 10, 32)
+
+	var req struct {
+		TargetTicketID uint `json:"targetTicketId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TargetTicketID == 0 {
+		http.Error(w, "targetTicketId is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.ticketService.MergeTickets(*tenantID, uint(ticketID), req.TargetTicketID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTicketMergeSelf), errors.Is(err, services.ErrTicketAlreadyMerged):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, "Ticket not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Ticket merged"})
+}
+
+// RecordSatisfactionHandler records the customer's satisfaction rating for a resolved ticket
+// @Summary Record ticket satisfaction rating
+// @Tags Tickets
+// @Accept json
+// @Produce json
+// @Router /tickets/{id}/satisfaction [post]
+func (h *TicketHandler) RecordSatisfactionHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	ticketID, _ := strconv.ParseUint(vars["id"], 10, 32)
+
+	var req struct {
+		Score   int    `json:"score"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.ticketService.RecordSatisfaction(*tenantID, uint(ticketID), req.Score, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidSatisfactionScore):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrTicketNotResolved), errors.Is(err, services.ErrTicketMerged):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, "Ticket not found", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Satisfaction rating recorded"})
+}
+
 // GetTicketStatsHandler returns ticket statistics
 // @Summary Get ticket statistics
 // @Tags Tickets
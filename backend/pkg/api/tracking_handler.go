@@ -0,0 +1,45 @@
+package api
+
+import (
+	"api/pkg/services"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// TrackingHandler serves the public remittance status page
+type TrackingHandler struct {
+	trackingService *services.RemittanceTrackingService
+}
+
+// NewTrackingHandler creates a new TrackingHandler
+func NewTrackingHandler(db *gorm.DB) *TrackingHandler {
+	return &TrackingHandler{
+		trackingService: services.NewRemittanceTrackingService(db),
+	}
+}
+
+// TrackRemittanceHandler returns the status timeline of a remittance by its tracking code
+// @Summary Track a remittance
+// @Description Public status timeline for a remittance; returns no names, accounts or amounts
+// @Tags Tracking
+// @Produce json
+// @Param code path string true "Tracking code"
+// @Success 200 {object} services.RemittanceTrackingStatus
+// @Failure 404 {object} map[string]interface{}
+// @Router /track/{code} [get]
+func (h *TrackingHandler) TrackRemittanceHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := h.trackingService.GetStatus(mux.Vars(r)["code"])
+	if err != nil {
+		if errors.Is(err, services.ErrTrackingCodeNotFound) {
+			respondJSON(w, http.StatusNotFound, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusInternalServerError, map[string]interface{}{"error": "Failed to load remittance status"})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// TransactionAttachmentHandler handles supporting documents attached to transactions
+type TransactionAttachmentHandler struct {
+	attachmentService *services.TransactionAttachmentService
+	uploadDir         string
+}
+
+// NewTransactionAttachmentHandler creates a new transaction attachment handler
+func NewTransactionAttachmentHandler(db *gorm.DB) *TransactionAttachmentHandler {
+	uploadDir := os.Getenv("TRANSACTION_UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "./uploads/transactions"
+	}
+
+	// Ensure upload directory exists
+	os.MkdirAll(uploadDir, 0755)
+
+	return &TransactionAttachmentHandler{
+		attachmentService: services.NewTransactionAttachmentService(db),
+		uploadDir:         uploadDir,
+	}
+}
+
+// UploadAttachmentHandler attaches a supporting document to a transaction
+// @Summary Upload transaction attachment
+// @Tags Transactions
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Router /transactions/{id}/attachments [post]
+func (h *TransactionAttachmentHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	transactionID := mux.Vars(r)["id"]
+
+	// Parse multipart form (max 10MB)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		http.Error(w, "File too large or invalid form", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Validate file type
+	allowedTypes := map[string]bool{
+		"image/jpeg":      true,
+		"image/png":       true,
+		"image/gif":       true,
+		"application/pdf": true,
+	}
+	contentType := header.Header.Get("Content-Type")
+	if !allowedTypes[contentType] {
+		http.Error(w, "Invalid file type. Allowed: JPEG, PNG, GIF, PDF", http.StatusBadRequest)
+		return
+	}
+
+	// Generate unique filename
+	ext := filepath.Ext(header.Filename)
+	filename := fmt.Sprintf("%d_%s_%d%s", *tenantID, filepath.Base(transactionID), time.Now().UnixNano(), ext)
+	filePath := filepath.Join(h.uploadDir, filename)
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		os.Remove(filePath)
+		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+
+	attachment := &models.TransactionAttachment{
+		FileName:    header.Filename,
+		FilePath:    filePath,
+		FileSize:    header.Size,
+		MimeType:    contentType,
+		Description: r.FormValue("description"),
+	}
+	if err := h.attachmentService.AddAttachment(*tenantID, transactionID, attachment, user.ID); err != nil {
+		// Clean up file on error
+		os.Remove(filePath)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attachment)
+}
+
+// GetAttachmentsHandler lists the supporting documents attached to a transaction
+// @Summary Get transaction attachments
+// @Tags Transactions
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Router /transactions/{id}/attachments [get]
+func (h *TransactionAttachmentHandler) GetAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(*tenantID, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// DeleteAttachmentHandler removes a supporting document from a transaction
+// @Summary Delete transaction attachment
+// @Tags Transactions
+// @Param id path string true "Transaction ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Router /transactions/{id}/attachments/{attachmentId} [delete]
+func (h *TransactionAttachmentHandler) DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	vars := mux.Vars(r)
+	attachmentID, err := strconv.ParseUint(vars["attachmentId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	attachment, err := h.attachmentService.DeleteAttachment(*tenantID, vars["id"], uint(attachmentID), user)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrRequiredDocumentProtected) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	os.Remove(attachment.FilePath)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,23 +3,29 @@ package api
 import (
 	"api/pkg/middleware"
 	"api/pkg/models"
+	"api/pkg/services"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"gorm.io/gorm"
 )
 
 // CreateTransaction godoc
 // @Summary Create a new transaction
-// @Description Create a new transaction (CASH_EXCHANGE or BANK_TRANSFER)
+// @Description Create a new transaction (CASH_EXCHANGE or BANK_TRANSFER). If rateApplied is omitted it is filled from the tenant's effective rate.
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param transaction body models.Transaction true "Transaction object"
 // @Success 201 {object} models.Transaction
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} map[string]string "Invalid request, the margin is below the pair's profit floor (an admin can resend with marginOverrideReason), or the rate is beyond the tenant's spread limit (an admin can resend with rateOverride and rateOverrideReason)"
+// @Failure 403 {object} map[string]string "Customer KYC has expired, or only an admin can override the profit floor or rate spread limit"
 // @Failure 500 {object} map[string]string
 // @Router /transactions [post]
 func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
@@ -49,9 +55,20 @@ func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	transaction.TenantID = *tenantID
+	transaction.CreatedBy = &user.ID
 
 	// Create transaction using service
 	if err := h.transactionService.CreateTransaction(r.Context(), &transaction); err != nil {
+		if errors.Is(err, services.ErrRateUnavailable) || errors.Is(err, services.ErrInvalidRate) || errors.Is(err, services.ErrBelowProfitFloor) ||
+			errors.Is(err, services.ErrRateSpreadExceeded) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrComplianceExpired) || errors.Is(err, services.ErrProfitFloorOverrideForbidden) ||
+			errors.Is(err, services.ErrRateSpreadOverrideForbidden) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -73,59 +90,81 @@ func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetTransactions godoc
-// @Summary Get all transactions
-// @Description Get a list of all transactions with client details (filtered by tenant). Supports date filtering via query params: ?startDate=2024-01-01&endDate=2024-12-31&branchId=1
+// @Summary Get transactions
+// @Description Get a page of transactions with client details (filtered by tenant), newest first. Supports date filtering via query params: ?startDate=2024-01-01&endDate=2024-12-31&branchId=1. Pass the returned nextCursor as ?cursor= to fetch the next page.
 // @Tags transactions
 // @Produce json
 // @Security BearerAuth
 // @Param startDate query string false "Start date (YYYY-MM-DD)"
 // @Param endDate query string false "End date (YYYY-MM-DD)"
 // @Param branchId query int false "Branch ID"
-// @Success 200 {array} models.Transaction
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Transactions to skip (ignored when cursor is set)"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} services.TransactionPage
+// @Failure 400 {object} map[string]string "Invalid branch, limit, offset or cursor"
 // @Failure 500 {object} map[string]string
 // @Router /transactions [get]
 func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
-	var transactions []models.Transaction
-
-	// Apply tenant isolation
-	db := middleware.ApplyTenantScope(h.db, r)
-
-	// Filter out cancelled transactions for regular users (not SuperAdmin)
-	db = db.Where("status != ?", models.StatusCancelled)
-
-	// Parse date filters from query params
-	startDate := r.URL.Query().Get("startDate")
-	endDate := r.URL.Query().Get("endDate")
-	branchID := r.URL.Query().Get("branchId")
+	query := r.URL.Query()
+	filter := services.TransactionListFilter{
+		// Nil for SuperAdmin, who sees every tenant
+		TenantID: middleware.GetTenantID(r),
+		Cursor:   query.Get("cursor"),
+	}
 
 	// Apply date filters if provided
-	if startDate != "" {
-		parsedStart, err := time.Parse("2006-01-02", startDate)
-		if err == nil {
-			db = db.Where("transaction_date >= ?", parsedStart)
+	if startDate := query.Get("startDate"); startDate != "" {
+		if parsedStart, err := time.Parse("2006-01-02", startDate); err == nil {
+			filter.StartDate = &parsedStart
 		}
 	}
-
-	if endDate != "" {
-		parsedEnd, err := time.Parse("2006-01-02", endDate)
-		if err == nil {
+	if endDate := query.Get("endDate"); endDate != "" {
+		if parsedEnd, err := time.Parse("2006-01-02", endDate); err == nil {
 			// Add one day to include the entire end date
 			parsedEnd = parsedEnd.Add(24 * time.Hour)
-			db = db.Where("transaction_date < ?", parsedEnd)
+			filter.EndDate = &parsedEnd
 		}
 	}
 
 	// Apply branch filter if provided
-	if branchID != "" && branchID != "all" {
-		db = db.Where("branch_id = ?", branchID)
+	if branchID := query.Get("branchId"); branchID != "" && branchID != "all" {
+		id, err := strconv.ParseUint(branchID, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid branchId", http.StatusBadRequest)
+			return
+		}
+		branch := uint(id)
+		filter.BranchID = &branch
 	}
 
-	result := db.Preload("Client").Preload("Branch").Order("transaction_date DESC, created_at DESC").Find(&transactions)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+	if offset := query.Get("offset"); offset != "" {
+		parsed, err := strconv.Atoi(offset)
+		if err != nil || parsed < 0 {
+			http.Error(w, "offset must be zero or a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.Offset = parsed
+	}
+
+	page, err := h.transactionService.ListTransactions(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	respondJSON(w, http.StatusOK, transactions)
+	respondJSON(w, http.StatusOK, page)
 }
 
 // GetTransaction godoc
@@ -163,6 +202,34 @@ func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, transaction)
 }
 
+// GetTransactionProfitBreakdown godoc
+// @Summary Get profit breakdown for a transaction
+// @Description Show how a transaction's profit was derived: spread, fee, cost, discount and net
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} services.TransactionProfitBreakdown
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /transactions/{id}/profit-breakdown [get]
+func (h *Handler) GetTransactionProfitBreakdown(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	breakdown, err := h.transactionService.GetProfitBreakdown(r.Context(), id, *tenantID)
+	if err != nil {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	respondJSON(w, http.StatusOK, breakdown)
+}
+
 // UpdateTransaction godoc
 // @Summary Update a transaction
 // @Description Update an existing transaction
@@ -174,6 +241,7 @@ func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 // @Param transaction body models.Transaction true "Transaction object"
 // @Success 200 {object} models.Transaction
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /transactions/{id} [put]
 func (h *Handler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
@@ -193,46 +261,6 @@ func (h *Handler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 	userVal := r.Context().Value("user")
 	user := userVal.(*models.User)
 
-	var branchName string
-	if user.PrimaryBranchID != nil {
-		var branch models.Branch
-		if err := h.db.WithContext(r.Context()).First(&branch, *user.PrimaryBranchID).Error; err == nil {
-			branchName = branch.Name
-		}
-	}
-
-	// Save current state to edit history before updating
-	editHistoryEntry := map[string]interface{}{
-		"editedAt":           existingTransaction.UpdatedAt,
-		"editedByBranchId":   user.PrimaryBranchID, // Track which branch made the edit
-		"editedByBranchName": branchName,           // Store name for easier display
-
-		"paymentMethod":      existingTransaction.PaymentMethod,
-		"sendCurrency":       existingTransaction.SendCurrency,
-		"sendAmount":         existingTransaction.SendAmount,
-		"receiveCurrency":    existingTransaction.ReceiveCurrency,
-		"receiveAmount":      existingTransaction.ReceiveAmount,
-		"rateApplied":        existingTransaction.RateApplied,
-		"feeCharged":         existingTransaction.FeeCharged,
-		"beneficiaryName":    existingTransaction.BeneficiaryName,
-		"beneficiaryDetails": existingTransaction.BeneficiaryDetails,
-		"userNotes":          existingTransaction.UserNotes,
-	}
-
-	// Parse existing edit history
-	var editHistory []map[string]interface{}
-	if existingTransaction.EditHistory != nil && *existingTransaction.EditHistory != "" {
-		if err := json.Unmarshal([]byte(*existingTransaction.EditHistory), &editHistory); err != nil {
-			// If parsing fails, start with empty history
-			editHistory = []map[string]interface{}{}
-		}
-	}
-
-	// Append new entry to history
-	editHistory = append(editHistory, editHistoryEntry)
-	historyJSON, _ := json.Marshal(editHistory)
-	historyStr := string(historyJSON)
-
 	// Decode the update request
 	var updatedTransaction models.Transaction
 	if err := json.NewDecoder(r.Body).Decode(&updatedTransaction); err != nil {
@@ -240,59 +268,20 @@ func (h *Handler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Mark as edited and update edit history
-	now := time.Now()
-
-	// Prepare update map to avoid tenant_id ambiguity with scoped queries
-	updates := map[string]interface{}{
-		"payment_method":        updatedTransaction.PaymentMethod,
-		"send_currency":         updatedTransaction.SendCurrency,
-		"send_amount":           updatedTransaction.SendAmount,
-		"receive_currency":      updatedTransaction.ReceiveCurrency,
-		"receive_amount":        updatedTransaction.ReceiveAmount,
-		"rate_applied":          updatedTransaction.RateApplied,
-		"fee_charged":           updatedTransaction.FeeCharged,
-		"beneficiary_name":      updatedTransaction.BeneficiaryName,
-		"beneficiary_details":   updatedTransaction.BeneficiaryDetails,
-		"user_notes":            updatedTransaction.UserNotes,
-		"allow_partial_payment": updatedTransaction.AllowPartialPayment, // Allow updating this flag
-		"is_edited":             true,
-		"last_edited_at":        now,
-		"edited_by_branch_id":   user.PrimaryBranchID, // Set the current branch as editor
-		"edit_history":          historyStr,
-		"updated_at":            now,
-	}
-
-	// If enabling partial payments for the first time, initialize tracking fields
-	if updatedTransaction.AllowPartialPayment && !existingTransaction.AllowPartialPayment {
-		updates["total_received"] = updatedTransaction.SendAmount // Assuming we receive the SendAmount
-		updates["received_currency"] = updatedTransaction.SendCurrency
-		updates["remaining_balance"] = updatedTransaction.SendAmount
-		updates["payment_status"] = models.PaymentStatusOpen
-	}
-
-	// Update the transaction - use base db to avoid scope ambiguity, add WHERE manually
-	result := h.db.WithContext(r.Context()).Model(&models.Transaction{}).
-		Where("id = ? AND tenant_id = ?", existingTransaction.ID, existingTransaction.TenantID).
-		Updates(updates)
-
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if result.RowsAffected == 0 {
-		http.Error(w, "Transaction not found or access denied", http.StatusNotFound)
-		return
-	}
-
-	// Reload the transaction to get the updated data with tenant scope
-	if err := db.First(&existingTransaction, "id = ?", existingTransaction.ID).Error; err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	transaction, err := h.transactionService.UpdateTransaction(r.Context(), &existingTransaction, &updatedTransaction, user)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCompletedTransactionLocked):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			http.Error(w, "Transaction not found or access denied", http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
-	respondJSON(w, http.StatusOK, existingTransaction)
+	respondJSON(w, http.StatusOK, transaction)
 }
 
 // CancelTransaction godoc
@@ -358,6 +347,7 @@ func (h *Handler) CancelTransaction(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to cancel transaction", http.StatusInternalServerError)
 		return
 	}
+	h.transactionService.PublishEvent(services.EventTransactionCancelled, &transaction)
 
 	// Log audit
 	h.auditService.LogAction(
@@ -377,29 +367,133 @@ func (h *Handler) CancelTransaction(w http.ResponseWriter, r *http.Request) {
 
 // DeleteTransaction godoc
 // @Summary Delete a transaction
-// @Description Delete a transaction by ID (permanent deletion, use cancel instead)
+// @Description Soft-delete a transaction by ID, reversing its ledger entries and cash balance effects. An owner or admin can restore it.
 // @Tags transactions
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Transaction ID"
 // @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "A day the transaction affects is closed"
 // @Failure 500 {object} map[string]string
 // @Router /transactions/{id} [delete]
 func (h *Handler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Apply tenant isolation
-	db := middleware.ApplyTenantScope(h.db, r)
+	user := r.Context().Value("user").(*models.User)
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
 
-	result := db.Delete(&models.Transaction{}, "id = ?", id)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
+	transaction, err := h.transactionService.DeleteTransaction(r.Context(), id, *tenantID, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrDayClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.transactionService.PublishEvent(services.EventTransactionDeleted, transaction)
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionDeleteTransaction, "Transaction", transaction.ID,
+		"Deleted transaction", nil, nil, r)
+
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Transaction deleted successfully"})
 }
 
+// RestoreTransaction godoc
+// @Summary Restore a deleted transaction
+// @Description Un-delete a transaction and re-apply the ledger entries and cash balance effects its deletion reversed (owner/admin only)
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} map[string]string "Transaction is not deleted"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "A day the transaction affects is closed"
+// @Router /transactions/{id}/restore [post]
+func (h *Handler) RestoreTransaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	user := r.Context().Value("user").(*models.User)
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can restore transactions", http.StatusForbidden)
+		return
+	}
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.transactionService.RestoreTransaction(r.Context(), id, *tenantID, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Transaction not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrTransactionNotDeleted) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, services.ErrDayClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.transactionService.PublishEvent(services.EventTransactionRestored, transaction)
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionRestoreTransaction, "Transaction", transaction.ID,
+		"Restored deleted transaction", nil, nil, r)
+
+	respondJSON(w, http.StatusOK, transaction)
+}
+
+// GetTransactionRateAudit godoc
+// @Summary Get a transaction's rate audit snapshot
+// @Description The market rate, any manual override, and the effective rate in force when the transaction was created
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transaction ID"
+// @Success 200 {object} models.TransactionRateAudit
+// @Failure 404 {object} map[string]string
+// @Router /transactions/{id}/rate-audit [get]
+func (h *Handler) GetTransactionRateAudit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return
+	}
+
+	audit, err := h.transactionService.GetRateAudit(r.Context(), id, *tenantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "No rate audit recorded for this transaction", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, audit)
+}
+
 // GetClientTransactions godoc
 // @Summary Get transactions for a client
 // @Description Get all transactions for a specific client
@@ -427,6 +521,19 @@ func (h *Handler) GetClientTransactions(w http.ResponseWriter, r *http.Request)
 	respondJSON(w, http.StatusOK, transactions)
 }
 
+// SearchTransactions godoc
+// @Summary Search transactions
+// @Description Search transactions by beneficiary, client name or phone, notes, currency or payment method, most relevant first (exact field match > prefix match > contains). Each result carries its score and where the query matched.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param fields query string false "Comma-separated fields to search: beneficiary_name, client_name, client_phone, user_notes, send_currency, receive_currency, payment_method (default all)"
+// @Param limit query int false "Maximum results (default 50, max 200)"
+// @Success 200 {array} services.TransactionSearchResult
+// @Failure 400 {object} map[string]string "Missing query or unknown field"
+// @Failure 500 {object} map[string]string
+// @Router /transactions/search [get]
 func (h *Handler) SearchTransactions(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -434,17 +541,21 @@ func (h *Handler) SearchTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var transactions []models.Transaction
-
-	// Apply tenant isolation
-	db := middleware.ApplyTenantScope(h.db, r)
+	var fields []string
+	if scoped := r.URL.Query().Get("fields"); scoped != "" {
+		fields = strings.Split(scoped, ",")
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 
-	// Updated search to include user_notes and beneficiary_name
-	result := db.Preload("Client").Where("send_currency LIKE ? OR receive_currency LIKE ? OR payment_method LIKE ? OR user_notes LIKE ? OR beneficiary_name LIKE ?",
-		"%"+query+"%", "%"+query+"%", "%"+query+"%", "%"+query+"%", "%"+query+"%").Find(&transactions)
-	if result.Error != nil {
-		http.Error(w, result.Error.Error(), http.StatusInternalServerError)
+	// Apply tenant isolation; nil for SuperAdmin
+	results, err := h.transactionService.SearchTransactions(r.Context(), middleware.GetTenantID(r), query, fields, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSearchField) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	respondJSON(w, http.StatusOK, transactions)
+	respondJSON(w, http.StatusOK, results)
 }
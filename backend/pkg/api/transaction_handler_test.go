@@ -0,0 +1,87 @@
+package api
+
+import (
+	"api/pkg/models"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// TestUpdateTransaction_RecalculatesProfit verifies editing the amounts, rate or fee refreshes the stored
+// profit and net profit against the standard rate captured on creation
+func TestUpdateTransaction_RecalculatesProfit(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{},
+		&models.TenantSettings{}, &models.ExchangeRate{}, &models.Transaction{})
+
+	tenant := &models.Tenant{Name: "Edit Exchange"}
+	db.Create(tenant)
+	user := &models.User{Email: "teller@edit.test", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(user)
+	client := &models.Client{ID: uuid.New().String(), TenantID: tenant.ID, Name: "Edit Client", PhoneNumber: "555-0110"}
+	db.Create(client)
+
+	// Spread = 1000 * (0.75 - 0.72) / 0.75 = 40; net = 40 + 15 - 2.5 - 5 = 47.5
+	transaction := &models.Transaction{
+		ID:                      uuid.New().String(),
+		TenantID:                tenant.ID,
+		ClientID:                client.ID,
+		PaymentMethod:           models.TransactionMethodCash,
+		SendCurrency:            "CAD",
+		SendAmount:              models.NewDecimal(1000),
+		ReceiveCurrency:         "USD",
+		ReceiveAmount:           models.NewDecimal(720),
+		RateApplied:             models.NewDecimal(0.72),
+		FeeCharged:              models.NewDecimal(15),
+		CostAmount:              models.NewDecimal(2.5),
+		DiscountAmount:          models.NewDecimal(5),
+		StandardRate:            models.NewDecimal(0.75),
+		Profit:                  models.NewDecimal(40),
+		NetProfit:               models.NewDecimal(47.5),
+		ProfitCalculationStatus: models.ProfitStatusCalculated,
+		TransactionDate:         time.Now(),
+	}
+	if err := db.Create(transaction).Error; err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	body := `{
+		"paymentMethod": "CASH",
+		"sendCurrency": "CAD",
+		"sendAmount": 2000,
+		"receiveCurrency": "USD",
+		"receiveAmount": 1400,
+		"rateApplied": 0.70,
+		"feeCharged": 20
+	}`
+	req := httptest.NewRequest(http.MethodPut, "/transactions/"+transaction.ID, strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	req = mux.SetURLVars(req, map[string]string{"id": transaction.ID})
+	rr := httptest.NewRecorder()
+
+	NewHandler(db).UpdateTransaction(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var stored models.Transaction
+	if err := db.First(&stored, "id = ?", transaction.ID).Error; err != nil {
+		t.Fatalf("Failed to load transaction: %v", err)
+	}
+	// Spread = 2000 * (0.75 - 0.70) / 0.75 = 133.3333; net = 133.3333 + 20 - 2.5 - 5 = 145.8333
+	if math.Abs(stored.Profit.Float64()-133.3333) > 0.0001 {
+		t.Errorf("Expected profit 133.3333, got %s", stored.Profit.String())
+	}
+	if math.Abs(stored.NetProfit.Float64()-145.8333) > 0.0001 {
+		t.Errorf("Expected net profit 145.8333, got %s", stored.NetProfit.String())
+	}
+	if stored.StandardRate.Float64() != 0.75 || stored.ProfitCalculationStatus != models.ProfitStatusCalculated {
+		t.Errorf("Expected the captured standard rate to be kept, got %s (%s)", stored.StandardRate.String(), stored.ProfitCalculationStatus)
+	}
+}
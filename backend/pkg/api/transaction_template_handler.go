@@ -0,0 +1,202 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// TransactionTemplateHandler handles transaction template API requests
+type TransactionTemplateHandler struct {
+	templateService *services.TransactionTemplateService
+}
+
+// NewTransactionTemplateHandler creates a new TransactionTemplateHandler
+func NewTransactionTemplateHandler(db *gorm.DB) *TransactionTemplateHandler {
+	return &TransactionTemplateHandler{
+		templateService: services.NewTransactionTemplateService(db),
+	}
+}
+
+// transactionTemplateID parses the template ID from the path, writing a 400 if it is invalid
+func transactionTemplateID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["templateId"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// templateErrorStatus maps a template lookup or validation error to its HTTP status
+func templateErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// GetTransactionTemplatesHandler lists the tenant's transaction templates
+// @Summary List transaction templates
+// @Description Lists templates by name; with branchId, only that branch's templates and those shared across branches
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param branchId query int false "Branch ID"
+// @Success 200 {array} models.TransactionTemplate
+// @Router /transaction-templates [get]
+func (h *TransactionTemplateHandler) GetTransactionTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var branchID *uint
+	if branch := r.URL.Query().Get("branchId"); branch != "" && branch != "all" {
+		id, err := strconv.ParseUint(branch, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid branchId", http.StatusBadRequest)
+			return
+		}
+		parsed := uint(id)
+		branchID = &parsed
+	}
+
+	templates, err := h.templateService.GetTemplates(*tenantID, branchID)
+	if err != nil {
+		http.Error(w, "Failed to load transaction templates", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, templates)
+}
+
+// CreateTransactionTemplateHandler saves a new transaction template
+// @Summary Create transaction template
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.TransactionTemplateRequest true "Transaction template"
+// @Success 201 {object} models.TransactionTemplate
+// @Failure 400 {object} map[string]string
+// @Router /transaction-templates [post]
+func (h *TransactionTemplateHandler) CreateTransactionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req services.TransactionTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateService.CreateTemplate(*tenantID, req, user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusCreated, template)
+}
+
+// UpdateTransactionTemplateHandler replaces a transaction template's details
+// @Summary Update transaction template
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path int true "Template ID"
+// @Param request body services.TransactionTemplateRequest true "Transaction template"
+// @Success 200 {object} models.TransactionTemplate
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /transaction-templates/{templateId} [put]
+func (h *TransactionTemplateHandler) UpdateTransactionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := transactionTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.TransactionTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(*tenantID, id, req)
+	if err != nil {
+		http.Error(w, err.Error(), templateErrorStatus(err))
+		return
+	}
+	respondJSON(w, http.StatusOK, template)
+}
+
+// DeleteTransactionTemplateHandler removes a transaction template
+// @Summary Delete transaction template
+// @Tags transactions
+// @Security BearerAuth
+// @Param templateId path int true "Template ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /transaction-templates/{templateId} [delete]
+func (h *TransactionTemplateHandler) DeleteTransactionTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := transactionTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(*tenantID, id); err != nil {
+		http.Error(w, "Transaction template not found", templateErrorStatus(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NewTransactionFromTemplateHandler prefills a new transaction from a template
+// @Summary Create transaction from template
+// @Description Returns an unsaved transaction with the template's client, branch, currencies, fee, beneficiary and notes. Enter the amounts and rate, then create it with POST /transactions.
+// @Tags transactions
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path int true "Template ID"
+// @Success 200 {object} models.Transaction
+// @Failure 404 {object} map[string]string
+// @Router /transaction-templates/{templateId}/transaction [post]
+func (h *TransactionTemplateHandler) NewTransactionFromTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := middleware.GetTenantID(r)
+	if tenantID == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := transactionTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.templateService.NewTransaction(*tenantID, id)
+	if err != nil {
+		http.Error(w, "Transaction template not found", templateErrorStatus(err))
+		return
+	}
+	respondJSON(w, http.StatusOK, transaction)
+}
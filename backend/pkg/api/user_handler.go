@@ -291,12 +291,13 @@ func (h *UserHandler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
 
 // UpdateUserRequest represents request to update a user
 type UpdateUserRequest struct {
-	Username        *string `json:"username"`
-	Email           *string `json:"email"`
-	Password        *string `json:"password"`
-	PrimaryBranchID *uint   `json:"primaryBranchId"`
-	Role            *string `json:"role"`
-	Status          *string `json:"status"`
+	Username         *string `json:"username"`
+	Email            *string `json:"email"`
+	Password         *string `json:"password"`
+	PrimaryBranchID  *uint   `json:"primaryBranchId"`
+	Role             *string `json:"role"`
+	Status           *string `json:"status"`
+	TicketCategories *string `json:"ticketCategories"` // Comma-separated ticket categories auto-assigned to the user, or ALL
 }
 
 // UpdateUserHandler updates a user
@@ -384,6 +385,15 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 		updates["status"] = *req.Status
 	}
 
+	if req.TicketCategories != nil {
+		categories, err := models.NormalizeTicketCategories(*req.TicketCategories)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		updates["ticket_categories"] = categories
+	}
+
 	// Apply updates
 	if len(updates) > 0 {
 		if err := h.DB.Model(&targetUser).Updates(updates).Error; err != nil {
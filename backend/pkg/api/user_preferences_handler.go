@@ -0,0 +1,85 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/services"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserPreferencesHandler handles the current user's preference API requests
+type UserPreferencesHandler struct {
+	preferencesService *services.UserPreferencesService
+}
+
+// NewUserPreferencesHandler creates a new UserPreferencesHandler
+func NewUserPreferencesHandler(db *gorm.DB) *UserPreferencesHandler {
+	return &UserPreferencesHandler{
+		preferencesService: services.NewUserPreferencesService(db),
+	}
+}
+
+// GetMyPreferencesHandler returns the current user's preferences
+// @Summary Get my preferences
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.UserPreferences
+// @Router /me/preferences [get]
+func (h *UserPreferencesHandler) GetMyPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	preferences, err := h.preferencesService.GetPreferences(user.ID)
+	if err != nil {
+		http.Error(w, "Failed to load preferences", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, preferences)
+}
+
+// UpdateMyPreferencesHandler changes the current user's preferences
+// @Summary Update my preferences
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.UpdateUserPreferencesRequest true "Preferences to change"
+// @Success 200 {object} models.UserPreferences
+// @Failure 400 {object} map[string]string
+// @Router /me/preferences [put]
+func (h *UserPreferencesHandler) UpdateMyPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req services.UpdateUserPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := h.preferencesService.UpdatePreferences(user.ID, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, http.StatusOK, preferences)
+}
+
+// userDefaultDateRange returns the requesting user's preferred date range for requests that give none
+func userDefaultDateRange(r *http.Request, preferences *services.UserPreferencesService) (time.Time, time.Time, bool) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok || preferences == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return preferences.DefaultDateRange(user.ID, time.Now())
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"api/pkg/middleware"
+	"api/pkg/models"
+	"api/pkg/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler handles webhook endpoint management and the delivery log
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+	auditService   *services.AuditService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(db),
+		auditService:   services.NewAuditService(db),
+	}
+}
+
+// webhookManager returns the tenant and user when the caller is an owner or admin, writing an error otherwise
+func (h *WebhookHandler) webhookManager(w http.ResponseWriter, r *http.Request) (*uint, *models.User, bool) {
+	tenantID := middleware.GetTenantID(r)
+	user, ok := middleware.GetUserFromContext(r)
+	if tenantID == nil || !ok {
+		http.Error(w, "Tenant ID required", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	if user.Role != models.RoleTenantOwner && user.Role != models.RoleTenantAdmin {
+		http.Error(w, "Only owners and admins can manage webhooks", http.StatusForbidden)
+		return nil, nil, false
+	}
+	return tenantID, user, true
+}
+
+// GetWebhookEndpointsHandler lists the tenant's webhook endpoints
+// @Summary List webhook endpoints
+// @Description Lists the tenant's webhook endpoints without their signing secrets (owners and admins)
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.WebhookEndpoint
+// @Failure 403 {object} map[string]string
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhookEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := h.webhookManager(w, r)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.webhookService.GetEndpoints(*tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load webhook endpoints", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, endpoints)
+}
+
+// CreateWebhookEndpointHandler registers a webhook endpoint
+// @Summary Create webhook endpoint
+// @Description Registers a URL that receives event payloads signed with HMAC-SHA256 in the X-Webhook-Signature header. The signing secret is returned only once. Events: settlement.created, transaction.large, ticket.sla_breached.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.WebhookEndpointRequest true "Webhook endpoint"
+// @Success 201 {object} services.CreatedWebhookEndpoint
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, user, ok := h.webhookManager(w, r)
+	if !ok {
+		return
+	}
+
+	var req services.WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.webhookService.CreateEndpoint(*tenantID, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionCreateWebhook, "WebhookEndpoint", strconv.FormatUint(uint64(created.ID), 10),
+		"Created webhook endpoint "+created.URL, nil, created.WebhookEndpoint, r)
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// UpdateWebhookEndpointHandler changes a webhook endpoint
+// @Summary Update webhook endpoint
+// @Description Changes the URL, subscribed events or active flag of an endpoint; its signing secret is kept
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Param request body services.WebhookEndpointRequest true "Webhook endpoint"
+// @Success 200 {object} models.WebhookEndpoint
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, user, ok := h.webhookManager(w, r)
+	if !ok {
+		return
+	}
+	endpointID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook endpoint ID", http.StatusBadRequest)
+		return
+	}
+
+	var req services.WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	old, err := h.webhookService.GetEndpoint(*tenantID, uint(endpointID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Webhook endpoint not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(*tenantID, uint(endpointID), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionUpdateWebhook, "WebhookEndpoint", strconv.FormatUint(uint64(endpoint.ID), 10),
+		"Updated webhook endpoint "+endpoint.URL, old, endpoint, r)
+
+	respondJSON(w, http.StatusOK, endpoint)
+}
+
+// DeleteWebhookEndpointHandler removes a webhook endpoint
+// @Summary Delete webhook endpoint
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, user, ok := h.webhookManager(w, r)
+	if !ok {
+		return
+	}
+	endpointID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook endpoint ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(*tenantID, uint(endpointID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Webhook endpoint not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.auditService.LogAction(user.ID, tenantID, models.ActionDeleteWebhook, "WebhookEndpoint", strconv.FormatUint(endpointID, 10),
+		"Deleted webhook endpoint", nil, nil, r)
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Webhook endpoint deleted"})
+}
+
+// GetWebhookDeliveriesHandler returns the delivery log
+// @Summary Webhook delivery log
+// @Description Recent deliveries, newest first, with every attempt's response code and error
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param endpointId query int false "Only deliveries to this endpoint"
+// @Param status query string false "PENDING, DELIVERED or FAILED"
+// @Param limit query int false "Maximum deliveries (default 50, max 200)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Router /webhooks/deliveries [get]
+func (h *WebhookHandler) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, _, ok := h.webhookManager(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var endpointID *uint
+	if value := query.Get("endpointId"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid endpointId", http.StatusBadRequest)
+			return
+		}
+		parsed := uint(id)
+		endpointID = &parsed
+	}
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(*tenantID, endpointID, query.Get("status"), limit)
+	if err != nil {
+		http.Error(w, "Failed to load webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, deliveries)
+}
@@ -5,6 +5,8 @@ import (
 	"api/pkg/services"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -26,17 +28,47 @@ type WebSocketHandler struct {
 	Hub *services.Hub
 }
 
+// forwardEventsOnce ensures domain events are bridged to the hub only once per process
+var forwardEventsOnce sync.Once
+
 // NewWebSocketHandler creates a new WebSocket handler
 func NewWebSocketHandler(db *gorm.DB) *WebSocketHandler {
-	return &WebSocketHandler{
+	wsh := &WebSocketHandler{
 		DB:  db,
 		Hub: services.GetHub(),
 	}
+	forwardEventsOnce.Do(func() {
+		services.GetEventBus().Subscribe(wsh.forwardEvent)
+	})
+	return wsh
 }
 
-// ServeWS handles WebSocket upgrade requests
+// forwardEvent pushes a domain event to the connected clients of the event's tenant.
+// "payment.created" is sent as type "payment", action "created".
+func (wsh *WebSocketHandler) forwardEvent(event services.DomainEvent) {
+	eventType, action := event.Name, event.Name
+	if i := strings.Index(event.Name, "."); i >= 0 {
+		eventType, action = event.Name[:i], event.Name[i+1:]
+	}
+
+	data := make(map[string]interface{}, len(event.Data)+1)
+	for key, value := range event.Data {
+		data[key] = value
+	}
+	data["event"] = event.Name
+
+	wsh.Hub.Broadcast(services.WSMessage{
+		Type:     eventType,
+		Action:   action,
+		Data:     data,
+		TenantID: event.TenantID,
+	})
+}
+
+// ServeWS handles WebSocket upgrade requests. A new connection receives nothing until it subscribes to
+// channels with frames like {"action":"subscribe","channel":"dashboard"}.
 // @Summary WebSocket connection
-// @Description Establish a WebSocket connection for real-time updates
+// @Description Establish a WebSocket connection for real-time updates; subscribe to channels to receive events
 // @Tags websocket
 // @Security BearerAuth
 // @Router /ws [get]
@@ -64,9 +96,17 @@ func (wsh *WebSocketHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	client := &services.Client{
 		ID:       uuid.New().String(),
 		TenantID: *user.TenantID,
+		UserID:   user.ID,
 		Conn:     conn,
 		Send:     make(chan []byte, 256),
 		Hub:      wsh.Hub,
+
+		PingInterval:   services.WebSocketPingInterval(),
+		MaxMissedPongs: services.WebSocketMaxMissedPongs(),
+	}
+	// Branch staff are bound to their branch; owners and admins follow the whole tenant
+	if user.Role == models.RoleTenantUser {
+		client.BranchID = user.PrimaryBranchID
 	}
 
 	// Register client with hub
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestServeWS_DeliversOnlySubscribedChannels verifies a client subscribed to tickets gets ticket events but not payments
+func TestServeWS_DeliversOnlySubscribedChannels(t *testing.T) {
+	tenantID := uint(7101)
+	user := &models.User{ID: 1, Email: "ws@example.com", TenantID: &tenantID, Role: models.RoleTenantUser}
+
+	wsh := NewWebSocketHandler(nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wsh.ServeWS(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+	}))
+	defer server.Close()
+
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return conn
+	}
+	// Queued messages are written to one frame separated by newlines
+	receive := func(conn *websocket.Conn) []services.WSMessage {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read message: %v", err)
+		}
+		var messages []services.WSMessage
+		for _, line := range bytes.Split(frame, []byte{'\n'}) {
+			var message services.WSMessage
+			if err := json.Unmarshal(line, &message); err != nil {
+				t.Fatalf("Failed to decode message %q: %v", line, err)
+			}
+			messages = append(messages, message)
+		}
+		return messages
+	}
+	subscribe := func(conn *websocket.Conn, channel string) services.WSMessage {
+		t.Helper()
+		if err := conn.WriteJSON(map[string]string{"action": "subscribe", "channel": channel}); err != nil {
+			t.Fatalf("Failed to subscribe: %v", err)
+		}
+		return receive(conn)[0]
+	}
+
+	tickets := dial()
+	defer tickets.Close()
+	payments := dial()
+	defer payments.Close()
+
+	if ack := subscribe(tickets, services.ChannelTickets); ack.Type != "subscription" || ack.Action != "subscribed" {
+		t.Fatalf("Expected a subscription acknowledgement, got %s %s", ack.Type, ack.Action)
+	}
+	if ack := subscribe(payments, services.ChannelPayments); ack.Action != "subscribed" {
+		t.Fatalf("Expected a subscription acknowledgement, got %s %s", ack.Type, ack.Action)
+	}
+
+	services.GetEventBus().Publish(services.DomainEvent{
+		Name:     services.EventPaymentCreated,
+		TenantID: tenantID,
+		Data:     map[string]interface{}{"paymentId": 1},
+	})
+	wsh.Hub.BroadcastTicketUpdate(tenantID, "created", map[string]interface{}{"ticketId": 1})
+
+	// The hub delivers in order, so a leaked payment would arrive before the ticket
+	for _, message := range receive(tickets) {
+		if message.Type != "ticket" {
+			t.Errorf("Expected only ticket events on the tickets channel, got %s.%s", message.Type, message.Action)
+		}
+	}
+
+	if got := receive(payments)[0]; got.Type != "payment" || got.Action != "created" {
+		t.Errorf("Expected the payments subscriber to get payment.created, got %s.%s", got.Type, got.Action)
+	}
+
+	t.Run("AlertChannelsAccepted", func(t *testing.T) {
+		for _, channel := range []string{services.ChannelReconciliation, services.ChannelProfit} {
+			if ack := subscribe(payments, channel); ack.Action != "subscribed" {
+				t.Errorf("Expected the %s channel to be accepted, got %s", channel, ack.Action)
+			}
+		}
+	})
+
+	t.Run("UnknownChannelRejected", func(t *testing.T) {
+		if ack := subscribe(tickets, "everything"); ack.Action != "error" {
+			t.Errorf("Expected an error for an unknown channel, got %s", ack.Action)
+		}
+	})
+}
+
+// TestServeWS_DropsClientsThatStopAnsweringPings verifies a connection that ignores pings is closed and
+// deregistered while one that answers them stays connected
+func TestServeWS_DropsClientsThatStopAnsweringPings(t *testing.T) {
+	t.Setenv("WS_PING_INTERVAL", "20ms")
+	t.Setenv("WS_MAX_MISSED_PONGS", "2")
+
+	wsh := NewWebSocketHandler(nil)
+	serve := func(tenantID uint) *httptest.Server {
+		user := &models.User{ID: 1, Email: "heartbeat@example.com", TenantID: &tenantID, Role: models.RoleTenantOwner}
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wsh.ServeWS(w, r.WithContext(context.WithValue(r.Context(), "user", user)))
+		}))
+	}
+	dial := func(server *httptest.Server) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		return conn
+	}
+	waitForClients := func(tenantID uint, want int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for wsh.Hub.ClientCount(tenantID) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d clients for tenant %d, got %d", want, tenantID, wsh.Hub.ClientCount(tenantID))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	silentTenant, aliveTenant := uint(7102), uint(7103)
+	silentServer, aliveServer := serve(silentTenant), serve(aliveTenant)
+	defer silentServer.Close()
+	defer aliveServer.Close()
+
+	silent := dial(silentServer)
+	defer silent.Close()
+	alive := dial(aliveServer)
+	defer alive.Close()
+	waitForClients(silentTenant, 1)
+	waitForClients(aliveTenant, 1)
+
+	// The silent client still reads, so the server's pings arrive, but it never answers them
+	silent.SetPingHandler(func(string) error { return nil })
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := silent.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+	// The default ping handler answers each ping with a pong while reading
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the server to close the unresponsive connection")
+	}
+	waitForClients(silentTenant, 0)
+
+	time.Sleep(150 * time.Millisecond)
+	if got := wsh.Hub.ClientCount(aliveTenant); got != 1 {
+		t.Errorf("Expected the responsive client to stay connected, got %d clients", got)
+	}
+}
@@ -58,6 +58,7 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		// Core models
 		&models.User{},
 		&models.Tenant{},
+		&models.TenantSettings{},
 		&models.License{},
 		&models.Branch{},
 		&models.UserBranch{},
@@ -65,12 +66,25 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		&models.RolePermission{},
 		&models.OwnershipTransferLog{},
 		&models.AuditLog{},
+		&models.AuditLogDelivery{},
 		&models.PasswordResetCode{},
+		&models.PasswordHistory{},
 		// Security & Rate Limiting
 		&models.RefreshToken{},
+		&models.TwoFactorRecoveryCode{},
 		&models.RateLimitEntry{},
+		&models.APIKey{},
 		// Search
 		&models.SavedSearch{},
+		// Exports
+		&models.ExportProfile{},
+		&models.ScheduledExport{},
+		&models.UserPreferences{},
+		&models.Sequence{},
+		&models.MarginAlert{},
+		&models.CurrencyProfitFloor{},
+		&models.TransactionRateAudit{},
+		&models.DebtAgingBucket{},
 		// Idempotency
 		&models.IdempotencyRecord{},
 		// Existing models (now with TenantID)
@@ -80,19 +94,34 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		// Global models (NOT tenant-scoped)
 		&models.Customer{},
 		&models.CustomerTenantLink{},
+		&models.CustomerCampaign{},
 		// Cash management
 		&models.CashBalance{},
 		&models.CashAdjustment{},
+		&models.CashDenomination{},
+		&models.BranchFloatTarget{},
+		&models.CashBalanceThreshold{},
+		&models.CashAdjustmentApprovalLimit{},
+		&models.DailyClose{},
+		&models.DailyCloseBalance{},
 		// Payment system (NEW)
 		&models.Payment{},
+		&models.TransactionAttachment{},
+		&models.TransactionTemplate{},
+		&models.PaymentPlan{},
+		&models.PaymentInstallment{},
 		// Remittance system (NEW)
 		&models.OutgoingRemittance{},
 		&models.IncomingRemittance{},
 		&models.RemittanceSettlement{},
+		&models.PendingSettlement{},
+		&models.RemittanceWriteOff{},
 		// Exchange Rates
 		&models.ExchangeRate{},
+		&models.ExchangeRateSnapshot{},
 		// Reconciliation
 		&models.DailyReconciliation{},
+		&models.ReconciliationReminder{},
 		// Fee rules (Dynamic Fee Structure)
 		&models.FeeRule{},
 		// WAC (Weighted Average Cost) tracking
@@ -103,15 +132,24 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		&models.ComplianceDocument{},
 		&models.ComplianceAuditLog{},
 		&models.TransactionComplianceCheck{},
+		&models.ComplianceAlert{},
+		&models.StructuringRule{},
 		// Ticketing System
 		&models.Ticket{},
 		&models.TicketMessage{},
 		&models.TicketAttachment{},
 		&models.TicketActivity{},
+		// Webhooks
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
 		// Receipt Templates
 		&models.ReceiptTemplate{},
+		&models.BranchReceiptTemplate{},
+		&models.IssuedReceipt{},
 		// Ledger
 		&models.LedgerEntry{},
+		&models.StatementPeriod{},
 	)
 	if err != nil {
 		log.Printf("Warning: Failed to run auto-migrations: %v", err)
@@ -132,6 +170,27 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		// Don't fail if migration fails
 	}
 
+	// Link branch logins to explicit branch users
+	log.Println("Linking branch users...")
+	if err := migrations.LinkBranchUsers(db); err != nil {
+		log.Printf("Warning: Failed to link branch users: %v", err)
+		// Don't fail if migration fails
+	}
+
+	// One close record per branch and day
+	log.Println("Adding daily close unique index...")
+	if err := migrations.AddDailyCloseUniqueIndex(db); err != nil {
+		log.Printf("Warning: Failed to add daily close unique index: %v", err)
+		// Don't fail if index creation fails
+	}
+
+	// Receipt numbers are unique per branch
+	log.Println("Adding issued receipt number index...")
+	if err := migrations.AddIssuedReceiptNumberIndex(db); err != nil {
+		log.Printf("Warning: Failed to add issued receipt number index: %v", err)
+		// Don't fail if index creation fails
+	}
+
 	// Add performance indexes
 	log.Println("Adding performance indexes...")
 	if err := migrations.AddIndexes(db); err != nil {
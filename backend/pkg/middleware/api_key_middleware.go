@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyContextKey holds the API key a request authenticated with
+const APIKeyContextKey contextKey = "apiKey"
+
+// GetAPIKeyFromContext returns the API key the request authenticated with, if any
+func GetAPIKeyFromContext(r *http.Request) (*models.APIKey, bool) {
+	key, ok := r.Context().Value(APIKeyContextKey).(*models.APIKey)
+	return key, ok && key != nil
+}
+
+// APIKeyRoute lets keys holding Scope call Method on the paths matching Path
+type APIKeyRoute struct {
+	Method string
+	Path   string // path.Match pattern below the API root, e.g. "/reports/*"
+	Scope  string
+}
+
+// DefaultAPIKeyRoutes returns the read-only endpoints API keys can call; every other endpoint is refused
+func DefaultAPIKeyRoutes() []APIKeyRoute {
+	return []APIKeyRoute{
+		{http.MethodGet, "/reports/*", models.APIKeyScopeReportsRead},
+		{http.MethodGet, "/statistics", models.APIKeyScopeReportsRead},
+		{http.MethodGet, "/export/*", models.APIKeyScopeReportsRead},
+		{http.MethodGet, "/profit-analysis", models.APIKeyScopeReportsRead},
+		{http.MethodGet, "/transactions", models.APIKeyScopeTransactionsRead},
+		{http.MethodGet, "/transactions/*", models.APIKeyScopeTransactionsRead},
+	}
+}
+
+// requiredScope returns the scope a key needs for the request, or false if keys cannot call it at all
+func requiredScope(routes []APIKeyRoute, r *http.Request) (string, bool) {
+	p := apiPath(r)
+	for _, route := range routes {
+		if route.Method != r.Method {
+			continue
+		}
+		if matched, _ := path.Match(route.Path, p); matched {
+			return route.Scope, true
+		}
+	}
+	return "", false
+}
+
+// APIKeyAuthMiddleware authenticates requests carrying an X-API-Key header. The key must hold the scope
+// the route requires and its creator must still be an active user of the tenant; on success the request
+// carries the key and, like a JWT login, the creator acting as a tenant user, so AuthMiddleware and
+// TenantIsolationMiddleware scope it to the key's tenant.
+// Requests without the header are passed on for JWT authentication.
+func APIKeyAuthMiddleware(db *gorm.DB, routes []APIKeyRoute) func(http.Handler) http.Handler {
+	apiKeyService := services.NewAPIKeyService(db)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := strings.TrimSpace(r.Header.Get("X-API-Key"))
+			if secret == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := apiKeyService.Authenticate(secret)
+			if err != nil {
+				if errors.Is(err, services.ErrAPIKeyInvalid) || errors.Is(err, services.ErrAPIKeyRevoked) || errors.Is(err, services.ErrAPIKeyExpired) {
+					respondWithError(w, http.StatusUnauthorized, err.Error())
+					return
+				}
+				respondWithError(w, http.StatusInternalServerError, "API key check failed")
+				return
+			}
+
+			scope, ok := requiredScope(routes, r)
+			if !ok {
+				respondWithError(w, http.StatusForbidden, "This endpoint is not available to API keys")
+				return
+			}
+			if !key.HasScope(scope) {
+				respondWithError(w, http.StatusForbidden, "API key is missing the "+scope+" scope")
+				return
+			}
+
+			// The key acts for its creator, who must still be an active user of the key's tenant
+			var user models.User
+			if err := db.Preload("Tenant").Where("id = ? AND tenant_id = ?", key.CreatedBy, key.TenantID).First(&user).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					respondWithError(w, http.StatusUnauthorized, "API key creator no longer exists")
+					return
+				}
+				respondWithError(w, http.StatusInternalServerError, "API key check failed")
+				return
+			}
+			if user.Status != models.StatusActive {
+				respondWithError(w, http.StatusForbidden, "API key creator is not active")
+				return
+			}
+			user.Role = models.RoleTenantUser
+			ctx := context.WithValue(r.Context(), APIKeyContextKey, key)
+			ctx = context.WithValue(ctx, UserContextKey, &user)
+			ctx = context.WithValue(ctx, "user", &user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
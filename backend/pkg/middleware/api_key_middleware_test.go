@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestAPIKeyAuth_ScopesAndRevocation verifies a scoped key reaches an allowed endpoint under its tenant,
+// is refused endpoints outside its scopes or once its creator is suspended or removed, and stops working
+// once revoked
+func TestAPIKeyAuth_ScopesAndRevocation(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret-key-for-api-key-tests")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Tenant{}, &models.User{}, &models.APIKey{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	tenant := models.Tenant{Name: "API Key Tenant"}
+	db.Create(&tenant)
+	creator := models.User{ID: 7, Email: "creator@example.com", TenantID: &tenant.ID, Role: models.RoleTenantAdmin, Status: models.StatusActive}
+	db.Create(&creator)
+
+	apiKeys := services.NewAPIKeyService(db)
+	created, err := apiKeys.CreateKey(tenant.ID, services.APIKeyRequest{Name: "Accounting", Scopes: []string{models.APIKeyScopeReportsRead}}, 7)
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	var seenTenant *uint
+	var seenUser *models.User
+	handler := APIKeyAuthMiddleware(db, DefaultAPIKeyRoutes())(AuthMiddleware(db)(TenantIsolationMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenTenant = GetTenantID(r)
+			seenUser, _ = GetUserFromContext(r)
+			w.WriteHeader(http.StatusOK)
+		}))))
+	call := func(method, path, secret string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", secret)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := call(http.MethodGet, "/api/v1/reports/daily", created.Secret); code != http.StatusOK {
+		t.Fatalf("Expected the reports key to read reports, got %d", code)
+	}
+	if seenTenant == nil || *seenTenant != tenant.ID || seenUser == nil || seenUser.ID != 7 || seenUser.Role != models.RoleTenantUser {
+		t.Errorf("Expected the request scoped to tenant %d as a tenant user for the key's creator, got %v / %+v", tenant.ID, seenTenant, seenUser)
+	}
+	var used models.APIKey
+	db.First(&used, created.ID)
+	if used.LastUsedAt == nil {
+		t.Error("Expected the key's last use to be recorded")
+	}
+
+	if code := call(http.MethodGet, "/api/v1/transactions", created.Secret); code != http.StatusForbidden {
+		t.Errorf("Expected a reports-only key to be refused transactions, got %d", code)
+	}
+	if code := call(http.MethodPost, "/api/v1/api-keys", created.Secret); code != http.StatusForbidden {
+		t.Errorf("Expected API keys to be refused endpoints outside the key routes, got %d", code)
+	}
+	if code := call(http.MethodGet, "/api/v1/reports/daily", created.Secret+"0"); code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong secret to be rejected, got %d", code)
+	}
+
+	db.Model(&creator).Update("status", models.StatusSuspended)
+	if code := call(http.MethodGet, "/api/v1/reports/daily", created.Secret); code != http.StatusForbidden {
+		t.Errorf("Expected a suspended creator's key to be refused, got %d", code)
+	}
+	db.Delete(&creator)
+	if code := call(http.MethodGet, "/api/v1/reports/daily", created.Secret); code != http.StatusUnauthorized {
+		t.Errorf("Expected a deleted creator's key to be rejected, got %d", code)
+	}
+
+	if _, err := apiKeys.RevokeKey(tenant.ID, created.ID, 7); err != nil {
+		t.Fatalf("Failed to revoke API key: %v", err)
+	}
+	if code := call(http.MethodGet, "/api/v1/reports/daily", created.Secret); code != http.StatusUnauthorized {
+		t.Errorf("Expected the revoked key to be rejected, got %d", code)
+	}
+}
@@ -38,6 +38,12 @@ func AuthMiddleware(db *gorm.DB) func(http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Already authenticated by APIKeyAuthMiddleware
+			if _, ok := GetAPIKeyFromContext(r); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Get token from Authorization header or fallback to query param (for WebSocket)
 			tokenString := ""
 			authHeader := r.Header.Get("Authorization")
@@ -25,8 +25,10 @@ func WithIdempotency(db *gorm.DB, ttl time.Duration, next http.Handler) http.Han
 // Contract:
 // - Client sends: X-Idempotency-Key
 // - Uniqueness is scoped by tenant + key + route template + method.
-// - A completed response is replayed for subsequent identical requests.
+// - A completed response (status, Content-Type, body) is replayed for identical requests without invoking the handler.
+// - Replayed responses carry Idempotent-Replayed: true.
 // - If the same key is reused with a different request body, return 409.
+// - Completed responses are kept for ttl; after that the key can be processed again.
 func IdempotencyMiddleware(db *gorm.DB, ttl time.Duration) func(http.Handler) http.Handler {
 	if ttl <= 0 {
 		ttl = 24 * time.Hour
@@ -104,7 +106,7 @@ func IdempotencyMiddleware(db *gorm.DB, ttl time.Duration) func(http.Handler) ht
 						existing = existing2
 					} else {
 						// Claimed successfully on retry.
-						captureAndPersist(db, &rec, w, r, next)
+						captureAndPersist(db, ttl, &rec, w, r, next)
 						return
 					}
 				}
@@ -121,7 +123,12 @@ func IdempotencyMiddleware(db *gorm.DB, ttl time.Duration) func(http.Handler) ht
 				}
 
 				if existing.State == models.IdemStateCompleted {
-					w.Header().Set("Content-Type", "application/json")
+					contentType := existing.ContentType
+					if contentType == "" {
+						contentType = "application/json"
+					}
+					w.Header().Set("Content-Type", contentType)
+					w.Header().Set("Idempotent-Replayed", "true")
 					if existing.StatusCode > 0 {
 						w.WriteHeader(existing.StatusCode)
 					} else {
@@ -136,7 +143,7 @@ func IdempotencyMiddleware(db *gorm.DB, ttl time.Duration) func(http.Handler) ht
 			}
 
 			// Claimed successfully.
-			captureAndPersist(db, &rec, w, r, next)
+			captureAndPersist(db, ttl, &rec, w, r, next)
 		})
 	}
 }
@@ -165,7 +172,7 @@ func (w *captureResponseWriter) Write(p []byte) (int, error) {
 	return w.ResponseWriter.Write(p)
 }
 
-func captureAndPersist(db *gorm.DB, rec *models.IdempotencyRecord, w http.ResponseWriter, r *http.Request, next http.Handler) {
+func captureAndPersist(db *gorm.DB, ttl time.Duration, rec *models.IdempotencyRecord, w http.ResponseWriter, r *http.Request, next http.Handler) {
 	crw := &captureResponseWriter{ResponseWriter: w}
 	next.ServeHTTP(crw, r)
 
@@ -180,8 +187,9 @@ func captureAndPersist(db *gorm.DB, rec *models.IdempotencyRecord, w http.Respon
 			"state":         models.IdemStateCompleted,
 			"status_code":   crw.statusCode,
 			"response_body": crw.buf.Bytes(),
+			"content_type":  crw.Header().Get("Content-Type"),
 			"completed_at":  &completedAt,
-			"expires_at":    time.Now().Add(24 * time.Hour),
+			"expires_at":    completedAt.Add(ttl),
 		}
 		_ = db.Model(&models.IdempotencyRecord{}).Where("id = ?", rec.ID).Updates(updates).Error
 		return
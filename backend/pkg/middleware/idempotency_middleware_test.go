@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"api/pkg/models"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupIdempotencyTest returns a handler behind the idempotency middleware and a counter of how often it ran
+func setupIdempotencyTest(t *testing.T, ttl time.Duration) (*gorm.DB, http.Handler, *int) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.IdempotencyRecord{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	calls := 0
+	handler := WithIdempotency(db, ttl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+	return db, handler, &calls
+}
+
+func idempotentPost(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	tenantID := uint(1)
+	req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+	req.Header.Set("X-Idempotency-Key", key)
+	req = req.WithContext(context.WithValue(req.Context(), "tenantId", &tenantID))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestIdempotency_IdenticalRetryReplaysResponse verifies a retry returns the stored status, type and body
+// without running the handler again
+func TestIdempotency_IdenticalRetryReplaysResponse(t *testing.T) {
+	_, handler, calls := setupIdempotencyTest(t, time.Hour)
+
+	first := idempotentPost(handler, "key-1", `{"amount":100}`)
+	retry := idempotentPost(handler, "key-1", `{"amount":100}`)
+
+	if *calls != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", *calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Body.String() != `{"call":1}` {
+		t.Errorf("Expected the original 201 %s, got %d %s", first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Content-Type") != "application/json; charset=utf-8" || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected the original content type on a marked replay, got %q (replayed %q)",
+			retry.Header().Get("Content-Type"), retry.Header().Get("Idempotent-Replayed"))
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("Expected the first response not to be marked as a replay")
+	}
+}
+
+// TestIdempotency_DifferentBodyConflicts verifies reusing a key with another request body is rejected
+func TestIdempotency_DifferentBodyConflicts(t *testing.T) {
+	_, handler, calls := setupIdempotencyTest(t, time.Hour)
+
+	idempotentPost(handler, "key-2", `{"amount":100}`)
+	conflict := idempotentPost(handler, "key-2", `{"amount":999}`)
+
+	if conflict.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a different body, got %d", conflict.Code)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected the handler not to run for the conflicting request, ran %d times", *calls)
+	}
+}
+
+// TestIdempotency_ExpiredKeyIsReprocessed verifies completed responses are kept for the configured TTL
+// and a key past it runs the handler again
+func TestIdempotency_ExpiredKeyIsReprocessed(t *testing.T) {
+	db, handler, calls := setupIdempotencyTest(t, 2*time.Hour)
+
+	idempotentPost(handler, "key-3", `{"amount":100}`)
+
+	var record models.IdempotencyRecord
+	db.Where("key = ?", "key-3").First(&record)
+	if record.CompletedAt == nil || record.ExpiresAt.Sub(*record.CompletedAt) != 2*time.Hour {
+		t.Errorf("Expected the completed response to be kept for the configured 2h TTL, got expiry %v", record.ExpiresAt)
+	}
+
+	db.Model(&models.IdempotencyRecord{}).Where("id = ?", record.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	again := idempotentPost(handler, "key-3", `{"amount":100}`)
+
+	if *calls != 2 || again.Body.String() != `{"call":2}` {
+		t.Errorf("Expected the expired key to be processed again, got %d calls and %s", *calls, again.Body.String())
+	}
+	if again.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("Expected the reprocessed response not to be marked as a replay")
+	}
+}
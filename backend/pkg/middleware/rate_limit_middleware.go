@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path"
 	"strings"
 	"sync"
 	"time"
@@ -32,6 +33,106 @@ func DefaultRateLimitConfig() RateLimitConfig {
 	}
 }
 
+// RateLimitRule gives the routes matching any of its paths a budget of their own
+type RateLimitRule struct {
+	Name   string        // Requests are counted per rule, separately from the general budget
+	Paths  []string      // path.Match patterns below the API root, e.g. "/exports/*/run"
+	Limit  int           // Requests allowed per window
+	Window time.Duration // Length of the window
+}
+
+// RateLimitPolicy selects the limit for an authenticated request by route and role
+type RateLimitPolicy struct {
+	Limit          int             // General budget for routes no rule matches
+	Window         time.Duration   // Window for the general budget
+	RoleLimits     map[string]int  // Replaces the general Limit for these roles
+	UnlimitedRoles []string        // Roles that are never rate limited
+	Rules          []RateLimitRule // Checked in order; the first rule matching the path applies to every limited role
+}
+
+// DefaultRateLimitPolicy returns the limits applied to protected routes: 100/min in general, 10/min for
+// exports, and no limit for SuperAdmin
+func DefaultRateLimitPolicy() RateLimitPolicy {
+	return RateLimitPolicy{
+		Limit:          100,
+		Window:         time.Minute,
+		UnlimitedRoles: []string{models.RoleSuperAdmin},
+		Rules: []RateLimitRule{
+			{
+				Name:   "exports",
+				Paths:  []string{"/export/*", "/exports/*/run", "/exports/schedules", "/exports/schedules/*", "/profit-analysis/export"},
+				Limit:  10,
+				Window: time.Minute,
+			},
+		},
+	}
+}
+
+// resolve returns the budget that applies to a request by a user with role to apiPath. limited is false
+// when the request is not rate limited at all.
+func (p RateLimitPolicy) resolve(role, apiPath string) (budget string, limit int, window time.Duration, limited bool) {
+	for _, unlimited := range p.UnlimitedRoles {
+		if role == unlimited {
+			return "", 0, 0, false
+		}
+	}
+	for _, rule := range p.Rules {
+		for _, pattern := range rule.Paths {
+			if matched, _ := path.Match(pattern, apiPath); matched {
+				return rule.Name, rule.Limit, rule.Window, true
+			}
+		}
+	}
+	if roleLimit, ok := p.RoleLimits[role]; ok {
+		return "general", roleLimit, p.Window, true
+	}
+	return "general", p.Limit, p.Window, true
+}
+
+// apiPath strips the /api/v1 or legacy /api prefix so rules match both route trees
+func apiPath(r *http.Request) string {
+	p := r.URL.Path
+	if trimmed := strings.TrimPrefix(p, "/api/v1"); trimmed != p {
+		return trimmed
+	}
+	return strings.TrimPrefix(p, "/api")
+}
+
+// PolicyRateLimitMiddleware limits each user (or IP when unauthenticated) by the budget the policy selects
+// for the route and their role. Every limited response carries X-RateLimit-Limit, X-RateLimit-Remaining
+// and X-RateLimit-Reset.
+func PolicyRateLimitMiddleware(db *gorm.DB, policy RateLimitPolicy) func(http.Handler) http.Handler {
+	limiter := GetRateLimiter(db)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := ""
+			identifier := "ip_" + getClientIP(r)
+			if user, ok := r.Context().Value("user").(*models.User); ok && user != nil {
+				role = user.Role
+				identifier = fmt.Sprintf("user_%d", user.ID)
+			}
+
+			budget, limit, window, limited := policy.resolve(role, apiPath(r))
+			if !limited {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, remaining, resetTime := limiter.consume(budget+"_"+identifier, limit, window)
+			if !allowed {
+				respondRateLimited(w, limit, resetTime, "Too many requests")
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", limit))
+			w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetTime.Unix()))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RateLimiter manages rate limiting
 type RateLimiter struct {
 	db     *gorm.DB
@@ -228,8 +329,34 @@ func PaymentRateLimitMiddleware(db *gorm.DB, limit int, window time.Duration) fu
 	}
 }
 
+// TrackingRateLimitMiddleware limits public remittance status lookups per IP so tracking codes cannot be enumerated
+func TrackingRateLimitMiddleware(db *gorm.DB, limit int, window time.Duration) func(http.Handler) http.Handler {
+	limiter := GetRateLimiter(db)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identifier := "track_ip_" + getClientIP(r)
+
+			allowed, resetTime := limiter.checkRateLimit(identifier, limit, window)
+			if !allowed {
+				respondRateLimited(w, limit, resetTime, "Too many tracking requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // checkRateLimit checks if request is allowed and returns reset time
 func (rl *RateLimiter) checkRateLimit(identifier string, limit int, window time.Duration) (bool, time.Time) {
+	allowed, _, resetTime := rl.consume(identifier, limit, window)
+	return allowed, resetTime
+}
+
+// consume counts a request against the identifier's window and returns whether it is allowed, how many
+// requests remain in the window and when the window resets
+func (rl *RateLimiter) consume(identifier string, limit int, window time.Duration) (bool, int, time.Time) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -242,18 +369,18 @@ func (rl *RateLimiter) checkRateLimit(identifier string, limit int, window time.
 			count:       1,
 			windowStart: now,
 		}
-		return true, now.Add(window)
+		return true, limit - 1, now.Add(window)
 	}
 
 	if info.count >= limit {
 		// Rate limit exceeded
 		resetTime := info.windowStart.Add(window)
-		return false, resetTime
+		return false, 0, resetTime
 	}
 
 	// Increment count
 	info.count++
-	return true, info.windowStart.Add(window)
+	return true, limit - info.count, info.windowStart.Add(window)
 }
 
 // cleanupExpiredEntries periodically cleans up old entries from cache
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"api/pkg/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func rateLimitedGet(handler http.Handler, path string, user *models.User) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user", user))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestPolicyRateLimit_ExportsHitTheirLowerLimitFirst verifies export routes have their own smaller budget,
+// the headers report the remaining quota, and SuperAdmin is not limited
+func TestPolicyRateLimit_ExportsHitTheirLowerLimitFirst(t *testing.T) {
+	handler := PolicyRateLimitMiddleware(nil, DefaultRateLimitPolicy())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	user := &models.User{ID: 4101, Role: models.RoleTenantUser}
+
+	first := rateLimitedGet(handler, "/api/v1/export/csv", user)
+	if first.Header().Get("X-RateLimit-Limit") != "10" || first.Header().Get("X-RateLimit-Remaining") != "9" || first.Header().Get("X-RateLimit-Reset") == "" {
+		t.Errorf("Expected export headers 10 with 9 remaining and a reset, got %q/%q/%q", first.Header().Get("X-RateLimit-Limit"),
+			first.Header().Get("X-RateLimit-Remaining"), first.Header().Get("X-RateLimit-Reset"))
+	}
+	for i := 2; i <= 10; i++ {
+		// The legacy /api tree shares the same budget
+		if rec := rateLimitedGet(handler, "/api/exports/7/run", user); rec.Code != http.StatusOK {
+			t.Fatalf("Expected export %d to be allowed, got %d", i, rec.Code)
+		}
+	}
+	if rec := rateLimitedGet(handler, "/api/v1/export/pdf", user); rec.Code != http.StatusTooManyRequests || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Expected the 11th export to be rate limited, got %d", rec.Code)
+	}
+
+	read := rateLimitedGet(handler, "/api/v1/transactions", user)
+	if read.Code != http.StatusOK {
+		t.Fatalf("Expected a read to use its own budget after exports ran out, got %d", read.Code)
+	}
+	if read.Header().Get("X-RateLimit-Limit") != "100" || read.Header().Get("X-RateLimit-Remaining") != "99" {
+		t.Errorf("Expected read headers 100 with 99 remaining, got %q/%q", read.Header().Get("X-RateLimit-Limit"), read.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	admin := &models.User{ID: 4102, Role: models.RoleSuperAdmin}
+	for i := 0; i < 15; i++ {
+		if rec := rateLimitedGet(handler, "/api/v1/export/csv", admin); rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("Expected SuperAdmin exports to be unlimited, got %d on request %d", rec.Code, i+1)
+		}
+	}
+}
+
+// TestRateLimitPolicy_RoleLimits verifies a role limit replaces the general limit but not a route rule's
+func TestRateLimitPolicy_RoleLimits(t *testing.T) {
+	policy := DefaultRateLimitPolicy()
+	policy.RoleLimits = map[string]int{models.RoleTenantOwner: 300}
+
+	if budget, limit, _, limited := policy.resolve(models.RoleTenantOwner, "/transactions"); !limited || budget != "general" || limit != 300 {
+		t.Errorf("Expected the owner's general limit of 300, got %s %d", budget, limit)
+	}
+	if budget, limit, _, limited := policy.resolve(models.RoleTenantOwner, "/exports/3/run"); !limited || budget != "exports" || limit != 10 {
+		t.Errorf("Expected the export rule to apply to owners, got %s %d", budget, limit)
+	}
+	if budget, _, _, limited := policy.resolve(models.RoleTenantUser, "/exports/profiles"); !limited || budget != "general" {
+		t.Errorf("Expected profile management to fall under the general budget, got %s", budget)
+	}
+	for _, schedulePath := range []string{"/exports/schedules", "/exports/schedules/4"} {
+		if budget, limit, _, limited := policy.resolve(models.RoleTenantUser, schedulePath); !limited || budget != "exports" || limit != 10 {
+			t.Errorf("Expected the export rule to apply to %s, got %s %d", schedulePath, budget, limit)
+		}
+	}
+}
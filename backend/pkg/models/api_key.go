@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey lets another system call the API on behalf of a tenant without a user's JWT. Only a hash of
+// the secret is stored; the secret itself is shown once when the key is created.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint       `gorm:"type:bigint;not null;index" json:"tenantId"`
+	Name       string     `gorm:"type:varchar(255);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(20);not null;uniqueIndex" json:"prefix"` // Public part of the secret, used to find the key
+	SecretHash string     `gorm:"type:varchar(64);not null" json:"-"`                  // SHA-256 of the full secret
+	Scopes     string     `gorm:"type:text;not null" json:"scopes"`                    // Comma-separated, e.g. "reports:read"
+	ExpiresAt  *time.Time `gorm:"type:timestamp" json:"expiresAt,omitempty"`           // Nil never expires
+	LastUsedAt *time.Time `gorm:"type:timestamp" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `gorm:"type:timestamp" json:"revokedAt,omitempty"`
+	RevokedBy  *uint      `gorm:"type:bigint" json:"revokedBy,omitempty"`
+	CreatedBy  uint       `gorm:"type:bigint;not null" json:"createdBy"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// API key scopes
+const (
+	APIKeyScopeReportsRead      = "reports:read"      // Reports, statistics exports and profit analysis
+	APIKeyScopeTransactionsRead = "transactions:read" // Transaction lists and details
+)
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range strings.Split(k.Scopes, ",") {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
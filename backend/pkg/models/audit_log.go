@@ -49,6 +49,17 @@ const (
 	ActionActivateTenant    = "ACTIVATE_TENANT"
 	ActionUpdateUser        = "UPDATE_USER"
 	ActionDeleteUser        = "DELETE_USER"
+	// Profit floor audit actions
+	ActionUpdateProfitFloor   = "UPDATE_PROFIT_FLOOR"
+	ActionOverrideProfitFloor = "OVERRIDE_PROFIT_FLOOR"
+	ActionOverrideRateSpread  = "OVERRIDE_RATE_SPREAD"
+	// Transaction restore audit action (deletions use ActionDeleteTransaction)
+	ActionRestoreTransaction = "RESTORE_TRANSACTION"
+	ActionVoidTransaction    = "VOID_TRANSACTION"
+	// Removing the only supporting document of a completed transaction that required one
+	ActionDeleteRequiredDocument = "DELETE_REQUIRED_DOCUMENT"
+	// Customer messaging audit action
+	ActionSendCustomerCampaign = "SEND_CUSTOMER_CAMPAIGN"
 	// Payment audit actions
 	ActionCreatePayment = "CREATE_PAYMENT"
 	ActionUpdatePayment = "UPDATE_PAYMENT"
@@ -59,4 +70,49 @@ const (
 	ActionUpdateRemittance = "UPDATE_REMITTANCE"
 	ActionSettleRemittance = "SETTLE_REMITTANCE"
 	ActionCancelRemittance = "CANCEL_REMITTANCE"
+	// Settlement approval audit actions
+	ActionApproveSettlement = "APPROVE_SETTLEMENT"
+	ActionRejectSettlement  = "REJECT_SETTLEMENT"
+	// Daily close audit actions
+	ActionCloseDay  = "CLOSE_DAY"
+	ActionReopenDay = "REOPEN_DAY"
+	// API key audit actions
+	ActionCreateAPIKey = "CREATE_API_KEY"
+	ActionRevokeAPIKey = "REVOKE_API_KEY"
+	// Webhook audit actions
+	ActionCreateWebhook = "CREATE_WEBHOOK"
+	ActionUpdateWebhook = "UPDATE_WEBHOOK"
+	ActionDeleteWebhook = "DELETE_WEBHOOK"
+	// Cash adjustment approval limit audit actions
+	ActionSetCashApprovalLimit    = "SET_CASH_APPROVAL_LIMIT"
+	ActionDeleteCashApprovalLimit = "DELETE_CASH_APPROVAL_LIMIT"
+)
+
+// AuditLogDelivery tracks forwarding of an audit log entry to an external SIEM
+type AuditLogDelivery struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	AuditLogID    uint       `gorm:"type:bigint;not null;uniqueIndex" json:"auditLogId"`
+	TenantID      *uint      `gorm:"type:bigint;index" json:"tenantId"`
+	Status        string     `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"` // PENDING, DELIVERED, FAILED
+	Attempts      int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"lastError"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;index" json:"nextAttemptAt"`
+	DeliveredAt   *time.Time `gorm:"type:timestamp" json:"deliveredAt"`
+	CreatedAt     time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	AuditLog *AuditLog `gorm:"foreignKey:AuditLogID;constraint:OnDelete:CASCADE" json:"auditLog,omitempty"`
+}
+
+// TableName specifies the table name for AuditLogDelivery model
+func (AuditLogDelivery) TableName() string {
+	return "audit_log_deliveries"
+}
+
+// AuditLogDelivery status constants
+const (
+	AuditDeliveryPending   = "PENDING"
+	AuditDeliveryDelivered = "DELIVERED"
+	AuditDeliveryFailed    = "FAILED"
 )
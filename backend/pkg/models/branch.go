@@ -1,6 +1,9 @@
 package models
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"time"
 )
 
@@ -17,6 +20,9 @@ type Branch struct {
 	// Branch Login Credentials (optional - can be NULL)
 	Username     *string `gorm:"type:varchar(100);uniqueIndex:idx_tenant_username" json:"username,omitempty"` // Branch login username (unique within tenant)
 	PasswordHash *string `gorm:"type:varchar(255)" json:"-"`                                                  // Hashed password (hidden from JSON, nullable)
+	BranchUserID *uint   `gorm:"type:bigint;index" json:"branchUserId,omitempty"`                             // User that branch logins act as; created when credentials are first set
+
+	ComplianceOfficerID *uint `gorm:"type:bigint;index" json:"complianceOfficerId,omitempty"` // User who reviews KYC for customers routed to this branch
 
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
@@ -32,6 +38,26 @@ func (Branch) TableName() string {
 	return "branches"
 }
 
+// NewBranchUser builds the user a branch login acts as. The user has no password or username of its own
+// (it can only be reached through the branch credentials) and a random placeholder email, so it cannot
+// collide with, or be guessed from, another tenant's branch of the same name.
+func NewBranchUser(branch *Branch) (*User, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	tenantID := branch.TenantID
+	branchID := branch.ID
+	return &User{
+		Email:           fmt.Sprintf("branch-%d-%s@branch.invalid", branch.ID, hex.EncodeToString(suffix)),
+		EmailVerified:   true,
+		Role:            RoleTenantUser,
+		TenantID:        &tenantID,
+		PrimaryBranchID: &branchID,
+		Status:          StatusActive,
+	}, nil
+}
+
 // BranchStatus constants
 const (
 	BranchStatusActive   = "active"
@@ -29,7 +29,16 @@ func (CashBalance) TableName() string {
 	return "cash_balances"
 }
 
-// CashAdjustment represents manual adjustments to cash balance
+// Cash adjustment statuses
+const (
+	CashAdjustmentStatusApplied  = "APPLIED"  // Included in the cash balance
+	CashAdjustmentStatusPending  = "PENDING"  // Above the currency's approval limit; waiting for a second approver
+	CashAdjustmentStatusRejected = "REJECTED" // Declined by the approver; never applied
+)
+
+// CashAdjustment represents manual adjustments to cash balance.
+// An adjustment above its currency's approval limit is held as PENDING and only changes the balance once
+// an owner or admin other than AdjustedBy approves it. BalanceBefore and BalanceAfter are set when it applies.
 type CashAdjustment struct {
 	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	TenantID      uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
@@ -42,13 +51,111 @@ type CashAdjustment struct {
 	BalanceAfter  Decimal   `gorm:"type:decimal(20,4);not null" json:"balanceAfter"`
 	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 
+	// Approval
+	Status       string     `gorm:"type:varchar(20);not null;default:'APPLIED';index" json:"status"` // APPLIED, PENDING or REJECTED
+	DecidedBy    *uint      `gorm:"type:bigint" json:"decidedBy,omitempty"`                          // Owner or admin who approved or rejected it
+	DecidedAt    *time.Time `gorm:"type:timestamp" json:"decidedAt,omitempty"`
+	DecisionNote *string    `gorm:"type:text" json:"decisionNote,omitempty"`
+	AppliedAt    *time.Time `gorm:"type:timestamp" json:"appliedAt,omitempty"` // When the amount went into the manual adjustment; nil for payment postings
+
 	// Relations
 	Tenant         *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
 	Branch         *Branch `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
 	AdjustedByUser *User   `gorm:"foreignKey:AdjustedBy;constraint:OnDelete:SET NULL" json:"adjustedByUser,omitempty"`
+	DecidedByUser  *User   `gorm:"foreignKey:DecidedBy;constraint:OnDelete:SET NULL" json:"decidedByUser,omitempty"`
 }
 
 // TableName specifies the table name for CashAdjustment model
 func (CashAdjustment) TableName() string {
 	return "cash_adjustments"
 }
+
+// CashDenomination records how many notes/coins of one face value make up a counted cash amount.
+// Rows without a ReconciliationID describe the current breakdown of a cash balance;
+// rows with one belong to that reconciliation's physical count.
+type CashDenomination struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID         uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID         *uint     `gorm:"type:bigint;index" json:"branchId"`
+	Currency         string    `gorm:"type:varchar(10);not null;index" json:"currency"`
+	ReconciliationID *uint     `gorm:"type:bigint;index" json:"reconciliationId,omitempty"`
+	Denomination     Decimal   `gorm:"type:decimal(20,4);not null" json:"denomination"` // Face value, e.g. 50
+	Count            int       `gorm:"not null;default:0" json:"count"`
+	CountedBy        uint      `gorm:"type:bigint;not null" json:"countedBy"`
+	CreatedAt        time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Branch *Branch `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+}
+
+// TableName specifies the table name for CashDenomination model
+func (CashDenomination) TableName() string {
+	return "cash_denominations"
+}
+
+// BranchFloatTarget is the amount of cash a branch aims to hold in one currency.
+// The dashboard alerts when the branch's balance drifts more than BandPercent away from the target.
+type BranchFloatTarget struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID     uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID     uint      `gorm:"type:bigint;not null;uniqueIndex:idx_branch_float_currency" json:"branchId"`
+	Currency     string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_branch_float_currency" json:"currency"`
+	TargetAmount Decimal   `gorm:"type:decimal(20,4);not null" json:"targetAmount"`
+	BandPercent  Decimal   `gorm:"type:decimal(5,2);not null;default:20" json:"bandPercent"` // Allowed deviation either side of the target, in percent
+	CreatedBy    uint      `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Branch *Branch `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+}
+
+// TableName specifies the table name for BranchFloatTarget model
+func (BranchFloatTarget) TableName() string {
+	return "branch_float_targets"
+}
+
+// CashBalanceThreshold is the lowest cash balance a tenant wants to hold in one currency.
+// The dashboard warns when the balance falls below it; currencies without a threshold are not checked.
+type CashBalanceThreshold struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint      `gorm:"type:bigint;not null;uniqueIndex:idx_cash_threshold_currency" json:"tenantId"`
+	Currency       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_cash_threshold_currency" json:"currency"`
+	MinimumBalance Decimal   `gorm:"type:decimal(20,4);not null" json:"minimumBalance"`
+	CreatedBy      uint      `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+}
+
+// TableName specifies the table name for CashBalanceThreshold model
+func (CashBalanceThreshold) TableName() string {
+	return "cash_balance_thresholds"
+}
+
+// CashAdjustmentApprovalLimit is the largest manual cash adjustment, either way, a tenant lets apply in one
+// currency without a second approver. Currencies without a limit are applied at once.
+type CashAdjustmentApprovalLimit struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint      `gorm:"type:bigint;not null;uniqueIndex:idx_cash_adjustment_limit_currency" json:"tenantId"`
+	Currency  string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_cash_adjustment_limit_currency" json:"currency"`
+	Amount    Decimal   `gorm:"type:decimal(20,4);not null" json:"amount"`
+	CreatedBy uint      `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+}
+
+// TableName specifies the table name for CashAdjustmentApprovalLimit model
+func (CashAdjustmentApprovalLimit) TableName() string {
+	return "cash_adjustment_approval_limits"
+}
+
+// DefaultFloatBandPercent is the band used when a float target is saved without one
+const DefaultFloatBandPercent = 20
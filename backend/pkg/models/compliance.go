@@ -32,6 +32,7 @@ type CustomerCompliance struct {
 	ID         uint             `gorm:"primaryKey;autoIncrement" json:"id"`
 	CustomerID uint             `gorm:"type:bigint;not null;uniqueIndex" json:"customerId"`
 	TenantID   uint             `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID   *uint            `gorm:"type:bigint;index" json:"branchId"` // Branch whose compliance officer reviews this customer
 	Status     ComplianceStatus `gorm:"type:varchar(20);not null;default:'PENDING'" json:"status"`
 	RiskLevel  RiskLevel        `gorm:"type:varchar(10);default:'LOW'" json:"riskLevel"`
 
@@ -109,7 +110,7 @@ type ComplianceDocument struct {
 	TenantID             uint       `gorm:"type:bigint;not null;index" json:"tenantId"`
 	DocumentType         string     `gorm:"type:varchar(50);not null" json:"documentType"` // ID_FRONT, ID_BACK, SELFIE, ADDRESS_PROOF
 	FileName             string     `gorm:"type:varchar(255);not null" json:"fileName"`
-	FilePath             string     `gorm:"type:text;not null" json:"filePath"`
+	FilePath             string     `gorm:"type:text;not null" json:"-"` // Storage location, never sent to clients
 	FileSize             int64      `gorm:"type:bigint" json:"fileSize"`
 	MimeType             string     `gorm:"type:varchar(100)" json:"mimeType"`
 	Status               string     `gorm:"type:varchar(20);default:'PENDING'" json:"status"` // PENDING, APPROVED, REJECTED
@@ -163,3 +164,43 @@ type TransactionComplianceCheck struct {
 func (TransactionComplianceCheck) TableName() string {
 	return "transaction_compliance_checks"
 }
+
+// ComplianceAlert types
+const (
+	ComplianceAlertVolumeSpike = "VOLUME_SPIKE" // Recent volume far above the customer's baseline
+)
+
+// ComplianceAlert statuses
+const (
+	ComplianceAlertStatusOpen     = "OPEN"
+	ComplianceAlertStatusResolved = "RESOLVED"
+)
+
+// ComplianceAlert flags customer activity that compliance staff should review
+type ComplianceAlert struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	ClientID  string `gorm:"type:text;not null;index" json:"clientId"`
+	AlertType string `gorm:"type:varchar(30);not null" json:"alertType"`
+	Status    string `gorm:"type:varchar(20);not null;default:'OPEN';index" json:"status"`
+
+	// Volume spike details, valued in the tenant's base currency
+	Currency       string    `gorm:"type:varchar(10)" json:"currency"`
+	RecentVolume   Decimal   `gorm:"type:decimal(20,4);not null;default:0" json:"recentVolume"`   // Volume in the recent window
+	BaselineVolume Decimal   `gorm:"type:decimal(20,4);not null;default:0" json:"baselineVolume"` // Average volume per window over the baseline period
+	Multiple       Decimal   `gorm:"type:decimal(10,2);not null;default:0" json:"multiple"`       // RecentVolume / BaselineVolume
+	WindowStart    time.Time `gorm:"type:timestamp" json:"windowStart"`
+	WindowEnd      time.Time `gorm:"type:timestamp" json:"windowEnd"`
+	Details        string    `gorm:"type:text" json:"details"`
+
+	ResolvedAt       *time.Time `gorm:"type:timestamp" json:"resolvedAt"`
+	ResolvedByUserID *uint      `gorm:"type:bigint" json:"resolvedByUserId"`
+	CreatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+
+	// Relations
+	Client *Client `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE" json:"client,omitempty"`
+}
+
+func (ComplianceAlert) TableName() string {
+	return "compliance_alerts"
+}
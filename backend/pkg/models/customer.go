@@ -12,7 +12,8 @@ type Customer struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Phone     string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"phone"` // Primary identifier
 	FullName  string         `gorm:"type:varchar(255);not null" json:"fullName"`
-	Email     *string        `gorm:"type:varchar(255)" json:"email"` // Optional
+	Email     *string        `gorm:"type:varchar(255)" json:"email"`   // Optional
+	IDNumber  *string        `gorm:"type:varchar(50)" json:"idNumber"` // Government ID number (required when tenant enforces KYC)
 	CreatedAt time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt time.Time      `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"` // Soft delete support
@@ -38,6 +39,12 @@ type CustomerTenantLink struct {
 	CreatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 
+	// CRM segmentation and messaging consent, kept per tenant since customers are shared
+	Tags             string `gorm:"type:varchar(500)" json:"tags"`                      // Comma-separated, lower-case tags
+	MarketingConsent bool   `gorm:"type:boolean;default:false" json:"marketingConsent"` // Customer agreed to receive bulk messages from this tenant
+	EmailOptOut      bool   `gorm:"type:boolean;default:false" json:"emailOptOut"`
+	SMSOptOut        bool   `gorm:"type:boolean;default:false" json:"smsOptOut"`
+
 	// Relations
 	Customer *Customer `gorm:"foreignKey:CustomerID;constraint:OnDelete:CASCADE" json:"customer,omitempty"`
 	Tenant   *Tenant   `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
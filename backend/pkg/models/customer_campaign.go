@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Customer campaign channels
+const (
+	CampaignChannelEmail = "EMAIL"
+	CampaignChannelSMS   = "SMS"
+)
+
+// CustomerCampaign records one bulk message sent to the tenant's customers carrying a tag
+type CustomerCampaign struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	Tag      string `gorm:"type:varchar(100);not null" json:"tag"`    // Segment the message was sent to
+	Channel  string `gorm:"type:varchar(10);not null" json:"channel"` // EMAIL or SMS
+	Subject  string `gorm:"type:varchar(255)" json:"subject"`         // Email only
+	Message  string `gorm:"type:text;not null" json:"message"`
+
+	MatchedCount int `gorm:"type:integer;default:0" json:"matchedCount"` // Customers carrying the tag
+	SentCount    int `gorm:"type:integer;default:0" json:"sentCount"`
+	SkippedCount int `gorm:"type:integer;default:0" json:"skippedCount"` // No consent, opted out of the channel, no address, or no SMS gateway
+	FailedCount  int `gorm:"type:integer;default:0" json:"failedCount"`
+
+	CreatedBy uint      `gorm:"type:bigint;not null" json:"createdBy"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+// TableName specifies the table name for CustomerCampaign model
+func (CustomerCampaign) TableName() string {
+	return "customer_campaigns"
+}
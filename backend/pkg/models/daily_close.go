@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// Daily close statuses
+const (
+	DailyCloseStatusClosed   = "CLOSED"
+	DailyCloseStatusReopened = "REOPENED"
+)
+
+// DailyClose records a branch closing the till for a business day. While a day is CLOSED no payment or
+// cash adjustment may be posted with an effective date inside it; an owner or admin can reopen it, and
+// closing it again replaces the balance snapshot. There is one record per tenant, branch and day; the
+// unique index over them treats a NULL branch as a value, so migrations.AddDailyCloseUniqueIndex creates it.
+type DailyClose struct {
+	ID       uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID *uint     `gorm:"type:bigint" json:"branchId"` // NULL for the company-wide till
+	Date     time.Time `gorm:"type:date;not null" json:"date"`
+	Status   string    `gorm:"type:varchar(20);not null;default:'CLOSED'" json:"status"` // CLOSED or REOPENED
+
+	ClosedBy     uint       `gorm:"type:bigint;not null" json:"closedBy"`
+	ClosedAt     time.Time  `gorm:"type:timestamp;not null" json:"closedAt"`
+	ReopenedBy   *uint      `gorm:"type:bigint" json:"reopenedBy,omitempty"`
+	ReopenedAt   *time.Time `gorm:"type:timestamp" json:"reopenedAt,omitempty"`
+	ReopenReason *string    `gorm:"type:text" json:"reopenReason,omitempty"`
+
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant   *Tenant             `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Branch   *Branch             `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+	Balances []DailyCloseBalance `gorm:"foreignKey:DailyCloseID" json:"balances,omitempty"`
+}
+
+// TableName specifies the table name for DailyClose model
+func (DailyClose) TableName() string {
+	return "daily_closes"
+}
+
+// DailyCloseBalance is one currency's cash balance as it stood when the day was closed
+type DailyCloseBalance struct {
+	ID                    uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	DailyCloseID          uint    `gorm:"type:bigint;not null;index" json:"dailyCloseId"`
+	Currency              string  `gorm:"type:varchar(10);not null" json:"currency"`
+	AutoCalculatedBalance Decimal `gorm:"type:decimal(20,4);not null" json:"autoCalculatedBalance"`
+	ManualAdjustment      Decimal `gorm:"type:decimal(20,4);not null" json:"manualAdjustment"`
+	FinalBalance          Decimal `gorm:"type:decimal(20,4);not null" json:"finalBalance"`
+
+	DailyClose *DailyClose `gorm:"foreignKey:DailyCloseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for DailyCloseBalance model
+func (DailyCloseBalance) TableName() string {
+	return "daily_close_balances"
+}
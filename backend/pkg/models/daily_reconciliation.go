@@ -13,8 +13,10 @@ type DailyReconciliation struct {
 	OpeningBalance    float64   `gorm:"type:real;not null" json:"openingBalance"`
 	ClosingBalance    float64   `gorm:"type:real;not null" json:"closingBalance"`
 	ExpectedBalance   float64   `gorm:"type:real;not null" json:"expectedBalance"`
-	Variance          float64   `gorm:"type:real;not null" json:"variance"` // Closing - Expected
-	CurrencyBreakdown string    `gorm:"type:text" json:"currencyBreakdown"` // JSON: {"USD": 5000, "CAD": 3000}
+	Variance          float64   `gorm:"type:real;not null" json:"variance"`                // Closing - Expected
+	CurrencyBreakdown string    `gorm:"type:text" json:"currencyBreakdown"`                // JSON: {"USD": 5000, "CAD": 3000}
+	ExpectedBreakdown string    `gorm:"type:text" json:"expectedBreakdown,omitempty"`      // JSON expected closing per currency, set when auto-matched
+	VarianceFlagged   bool      `gorm:"type:boolean;default:false" json:"varianceFlagged"` // A currency's variance exceeded the tenant's tolerance
 	Notes             *string   `gorm:"type:text" json:"notes,omitempty"`
 	CreatedByUserID   uint      `gorm:"type:bigint;not null" json:"createdByUserId"`
 	CreatedAt         time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;autoCreateTime" json:"createdAt"`
@@ -29,3 +31,31 @@ type DailyReconciliation struct {
 func (DailyReconciliation) TableName() string {
 	return "daily_reconciliations"
 }
+
+// ReconciliationReminder records an escalation sent for a branch that missed reconciliation,
+// so the scheduler does not repeat the same level for the same missed day
+type ReconciliationReminder struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TenantID   uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID   uint      `gorm:"type:bigint;not null;uniqueIndex:idx_reconciliation_reminder" json:"branchId"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_reconciliation_reminder" json:"date"` // Missed business day
+	Level      string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_reconciliation_reminder" json:"level"`
+	MissedDays int       `gorm:"type:int;not null" json:"missedDays"` // Consecutive days missed as of Date
+	TicketID   *uint     `gorm:"type:bigint" json:"ticketId,omitempty"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;autoCreateTime" json:"createdAt"`
+
+	Branch *Branch `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+}
+
+// TableName specifies the table name for ReconciliationReminder model
+func (ReconciliationReminder) TableName() string {
+	return "reconciliation_reminders"
+}
+
+// Reconciliation escalation levels, in increasing severity
+const (
+	ReconciliationEscalationNone       = ""
+	ReconciliationEscalationWarning    = "WARNING"     // Notify the branch
+	ReconciliationEscalationAdminAlert = "ADMIN_ALERT" // Alert tenant owners and admins
+	ReconciliationEscalationTicket     = "TICKET"      // Open a support ticket
+)
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// DebtAgingBucket is one age band of the dashboard's debt aging report for a tenant.
+// A tenant without rows uses DefaultDebtAgingBuckets.
+type DebtAgingBucket struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	Position   int       `gorm:"type:int;not null" json:"position"`                     // Order of the bucket, youngest debt first
+	Label      string    `gorm:"type:varchar(50);not null" json:"label"`                // e.g. "31-60 days"
+	MaxDays    *int      `gorm:"type:int" json:"maxDays,omitempty"`                     // Oldest debt (in days) in the bucket; nil for the open-ended last bucket
+	IsWarning  bool      `gorm:"type:boolean;default:false" json:"isWarning"`           // Highlight the bucket as needing attention
+	IsCritical bool      `gorm:"type:boolean;default:false" json:"isCritical"`          // Highlight the bucket as overdue
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+}
+
+// TableName specifies the table name for DebtAgingBucket model
+func (DebtAgingBucket) TableName() string {
+	return "debt_aging_buckets"
+}
+
+// DefaultDebtAgingBuckets returns the buckets used when a tenant has not configured any
+func DefaultDebtAgingBuckets(tenantID uint) []DebtAgingBucket {
+	days := func(n int) *int { return &n }
+	return []DebtAgingBucket{
+		{TenantID: tenantID, Position: 0, Label: "0-7 days", MaxDays: days(7)},
+		{TenantID: tenantID, Position: 1, Label: "8-14 days", MaxDays: days(14)},
+		{TenantID: tenantID, Position: 2, Label: "15-30 days", MaxDays: days(30), IsWarning: true},
+		{TenantID: tenantID, Position: 3, Label: "30+ days", IsCritical: true},
+	}
+}
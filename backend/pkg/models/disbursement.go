@@ -32,6 +32,7 @@ type Disbursement struct {
 	// Recipient/Beneficiary Details
 	RecipientName  string  `gorm:"type:varchar(255);not null" json:"recipientName"`
 	RecipientPhone *string `gorm:"type:varchar(50)" json:"recipientPhone"`
+	RecipientEmail *string `gorm:"type:varchar(255)" json:"recipientEmail"`
 	RecipientIBAN  *string `gorm:"type:varchar(50)" json:"recipientIban"`
 
 	// TransactionType is the disbursement method (renamed in JSON to disbursementType)
@@ -45,9 +46,14 @@ type Disbursement struct {
 	ReceiverAmount   *float64 `gorm:"type:real" json:"receiverAmount"`
 	Fees             float64  `gorm:"type:real;default:0" json:"fees"`
 
-	// Status: PENDING, DISBURSED (PICKED_UP in DB), CANCELLED
+	// Status: PENDING, READY, DISBURSED (PICKED_UP in DB), CANCELLED
 	Status string `gorm:"type:varchar(50);not null;default:'PENDING'" json:"status"`
 
+	// Ready for pickup
+	ReadyAt         *time.Time `gorm:"type:timestamp" json:"readyAt"`
+	ReadyByUserID   *uint      `gorm:"type:bigint" json:"readyByUserId"`
+	ReadyNotifiedAt *time.Time `gorm:"type:timestamp" json:"readyNotifiedAt"` // When the recipient was told the funds are ready
+
 	// Edit Tracking
 	EditedAt         *time.Time `gorm:"type:timestamp" json:"editedAt"`
 	EditedByUserID   *uint      `gorm:"type:bigint" json:"editedByUserId"`
@@ -104,6 +110,7 @@ const (
 // DisbursementStatus constants
 const (
 	DisbursementStatusPending   = "PENDING"
+	DisbursementStatusReady     = "READY"     // Funds are at the receiver branch awaiting the recipient
 	DisbursementStatusDisbursed = "DISBURSED" // New terminology (maps to PICKED_UP in legacy)
 	DisbursementStatusCancelled = "CANCELLED"
 )
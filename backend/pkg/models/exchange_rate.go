@@ -15,6 +15,10 @@ type ExchangeRate struct {
 	CreatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;autoCreateTime" json:"createdAt"`
 	UpdatedAt      time.Time `gorm:"type:timestamp;autoUpdateTime" json:"updatedAt"`
 
+	// Manual overrides with an expiry take precedence over other rates until they expire, then the pair
+	// reverts to its latest external rate
+	ExpiresAt *time.Time `gorm:"type:timestamp" json:"expiresAt,omitempty"`
+
 	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
 }
 
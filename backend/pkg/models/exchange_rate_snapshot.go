@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRateSnapshot records a currency pair's buy and sell rate at a point in time so rate trends
+// exist even on days without trading
+type ExchangeRateSnapshot struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TenantID       uint      `gorm:"type:bigint;not null;index:idx_rate_snapshot_tenant_time" json:"tenantId"`
+	BaseCurrency   string    `gorm:"type:varchar(10);not null" json:"baseCurrency"`
+	TargetCurrency string    `gorm:"type:varchar(10);not null" json:"targetCurrency"`
+	BuyRate        Decimal   `gorm:"type:decimal(20,6);not null" json:"buyRate"`
+	SellRate       Decimal   `gorm:"type:decimal(20,6);not null" json:"sellRate"` // BuyRate and SellRate sit the tenant's quote margin either side of the stored rate
+	Source         string    `gorm:"type:varchar(20);not null" json:"source"`     // Source of the rate snapshotted: "API" or "MANUAL"
+	CapturedAt     time.Time `gorm:"type:timestamp;not null;index:idx_rate_snapshot_tenant_time" json:"capturedAt"`
+}
+
+// TableName specifies the table name for ExchangeRateSnapshot model
+func (ExchangeRateSnapshot) TableName() string {
+	return "exchange_rate_snapshots"
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+)
+
+// ExportProfile is a tenant's saved export configuration: which entity, which columns in what order,
+// which filters and which file format
+type ExportProfile struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint      `gorm:"not null;index" json:"tenantId"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Entity    string    `gorm:"type:varchar(50);not null" json:"entity"` // "transactions"
+	Columns   string    `gorm:"type:text;not null" json:"columns"`       // JSON array of column keys, in output order
+	Filters   string    `gorm:"type:text;not null" json:"filters"`       // JSON object of filter criteria
+	Format    string    `gorm:"type:varchar(10);not null" json:"format"` // CSV, JSON or PDF
+	CreatedBy uint      `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for ExportProfile model
+func (ExportProfile) TableName() string {
+	return "export_profiles"
+}
+
+// Export profile entities
+const (
+	ExportEntityTransactions = "transactions"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "CSV"
+	ExportFormatJSON = "JSON"
+	ExportFormatPDF  = "PDF"
+)
+
+// ScheduledExport runs an export profile on a recurring schedule and emails the file
+type ScheduledExport struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID        uint       `gorm:"not null;index" json:"tenantId"`
+	ExportProfileID uint       `gorm:"not null;index" json:"exportProfileId"`
+	Frequency       string     `gorm:"type:varchar(10);not null" json:"frequency"`       // DAILY, WEEKLY or MONTHLY
+	RecipientEmail  string     `gorm:"type:varchar(255);not null" json:"recipientEmail"` // Where each run's file is delivered
+	IsActive        bool       `gorm:"not null;default:true" json:"isActive"`
+	LastRunAt       *time.Time `gorm:"type:timestamp" json:"lastRunAt,omitempty"`
+	LastRunStatus   string     `gorm:"type:varchar(10)" json:"lastRunStatus,omitempty"` // SENT or FAILED
+	LastRunRows     int        `gorm:"not null;default:0" json:"lastRunRows"`
+	LastError       string     `gorm:"type:text" json:"lastError,omitempty"`
+	CreatedBy       uint       `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	ExportProfile *ExportProfile `gorm:"foreignKey:ExportProfileID;constraint:OnDelete:CASCADE" json:"exportProfile,omitempty"`
+}
+
+// TableName specifies the table name for ScheduledExport model
+func (ScheduledExport) TableName() string {
+	return "scheduled_exports"
+}
+
+// Scheduled export frequencies
+const (
+	ExportFrequencyDaily   = "DAILY"
+	ExportFrequencyWeekly  = "WEEKLY"
+	ExportFrequencyMonthly = "MONTHLY"
+)
+
+// Scheduled export run statuses
+const (
+	ExportRunStatusSent   = "SENT"
+	ExportRunStatusFailed = "FAILED"
+)
@@ -23,6 +23,8 @@ type IdempotencyRecord struct {
 	StatusCode int    `gorm:"not null;default:0" json:"statusCode"`
 	// ResponseBody stores the raw response bytes (typically JSON).
 	ResponseBody []byte `gorm:"type:bytea" json:"-"`
+	// ContentType is the original response's Content-Type, replayed with the body.
+	ContentType string `gorm:"type:varchar(255)" json:"contentType,omitempty"`
 
 	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	ExpiresAt   time.Time  `gorm:"type:timestamp;index" json:"expiresAt"`
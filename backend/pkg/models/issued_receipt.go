@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// IssuedReceipt records the receipt number given to a transaction or remittance. A record gets its
+// number once, so reprinting its receipt shows the same number and the sequence has no gaps. Numbers are
+// unique per branch (uidx_issued_receipt_number, created by migrations.AddIssuedReceiptNumberIndex).
+type IssuedReceipt struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID   uint      `gorm:"type:bigint;not null;uniqueIndex:idx_issued_receipt_source" json:"tenantId"`
+	BranchID   *uint     `gorm:"type:bigint;index" json:"branchId"`
+	SourceType string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_issued_receipt_source" json:"sourceType"` // transaction, outgoing_remittance or incoming_remittance
+	SourceID   string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_issued_receipt_source" json:"sourceId"`   // Transaction UUID or remittance ID
+	Number     string    `gorm:"type:varchar(50);not null" json:"number"`
+	IssuedAt   time.Time `gorm:"type:timestamp;not null" json:"issuedAt"`
+}
+
+// TableName specifies the table name for IssuedReceipt model
+func (IssuedReceipt) TableName() string {
+	return "issued_receipts"
+}
+
+// Receipt sources
+const (
+	ReceiptSourceTransaction        = "transaction"
+	ReceiptSourceOutgoingRemittance = "outgoing_remittance"
+	ReceiptSourceIncomingRemittance = "incoming_remittance"
+)
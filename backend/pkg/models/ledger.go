@@ -72,3 +72,23 @@ const (
 	LedgerTypeExchangeInLegacy  = "EXCHANGE_IN"
 	LedgerTypeExchangeOutLegacy = "EXCHANGE_OUT"
 )
+
+// StatementPeriod records the closing balance of a client's statement once a period is closed.
+// Later statements cross-check their opening balance against it, so back-dated or deleted
+// ledger entries in a closed period show up as a discrepancy instead of silently changing history.
+type StatementPeriod struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	ClientID       string    `gorm:"type:text;not null;uniqueIndex:idx_statement_period_end" json:"clientId"`
+	Currency       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_statement_period_end" json:"currency"`
+	PeriodStart    time.Time `gorm:"type:timestamp;not null" json:"periodStart"`
+	PeriodEnd      time.Time `gorm:"type:timestamp;not null;uniqueIndex:idx_statement_period_end" json:"periodEnd"` // Exclusive
+	OpeningBalance Decimal   `gorm:"type:decimal(20,4);not null" json:"openingBalance"`
+	ClosingBalance Decimal   `gorm:"type:decimal(20,4);not null" json:"closingBalance"`
+	ClosedAt       time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"closedAt"`
+	ClosedBy       uint      `gorm:"type:bigint" json:"closedBy"` // User ID
+
+	// Relations
+	Client Client `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE" json:"-"`
+	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// MarginAlert statuses
+const (
+	MarginAlertStatusOpen     = "OPEN"
+	MarginAlertStatusResolved = "RESOLVED"
+)
+
+// MarginAlert flags a currency pair whose average margin fell below the tenant's floor over a window
+type MarginAlert struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BaseCurrency   string `gorm:"type:varchar(10);not null" json:"baseCurrency"` // Currency the customer sent
+	TargetCurrency string `gorm:"type:varchar(10);not null" json:"targetCurrency"`
+	Status         string `gorm:"type:varchar(20);not null;default:'OPEN';index" json:"status"`
+
+	// Window figures; profit and volume are in the pair's base currency
+	MarginPct        Decimal   `gorm:"type:decimal(10,4);not null;default:0" json:"marginPct"` // Profit (incl. fees) as a percentage of volume
+	FloorPct         Decimal   `gorm:"type:decimal(10,4);not null;default:0" json:"floorPct"`  // Tenant floor at the time of the alert
+	Profit           Decimal   `gorm:"type:decimal(20,4);not null;default:0" json:"profit"`
+	Volume           Decimal   `gorm:"type:decimal(20,4);not null;default:0" json:"volume"`
+	TransactionCount int       `gorm:"type:int;not null;default:0" json:"transactionCount"`
+	WindowStart      time.Time `gorm:"type:timestamp" json:"windowStart"`
+	WindowEnd        time.Time `gorm:"type:timestamp" json:"windowEnd"`
+	Details          string    `gorm:"type:text" json:"details"`
+
+	ResolvedAt       *time.Time `gorm:"type:timestamp" json:"resolvedAt"`
+	ResolvedByUserID *uint      `gorm:"type:bigint" json:"resolvedByUserId"`
+	CreatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+}
+
+func (MarginAlert) TableName() string {
+	return "margin_alerts"
+}
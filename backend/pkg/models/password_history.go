@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordHistory keeps a user's previous password hashes so recent passwords cannot be reused
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint      `gorm:"type:bigint;not null;index" json:"userId"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index" json:"createdAt"` // When the hash was retired
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for PasswordHistory model
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
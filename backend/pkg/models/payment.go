@@ -14,9 +14,10 @@ type Payment struct {
 	// Payment Details
 	Amount       Decimal                `gorm:"type:decimal(20,4);not null" json:"amount"`
 	Currency     string                 `gorm:"type:varchar(10);not null" json:"currency"`
-	ExchangeRate Decimal                `gorm:"type:decimal(20,6);default:1" json:"exchangeRate"` // Rate used to convert to transaction currency
-	AmountInBase Decimal                `gorm:"type:decimal(20,4);not null" json:"amountInBase"`  // Amount converted to transaction's base currency
-	Details      map[string]interface{} `gorm:"serializer:json" json:"details"`                   // JSONB details for method-specific data
+	ExchangeRate Decimal                `gorm:"type:decimal(20,6);default:1" json:"exchangeRate"`             // Rate used to convert to transaction currency
+	RateSource   string                 `gorm:"type:varchar(20);not null;default:'manual'" json:"rateSource"` // manual, navasan, stored
+	AmountInBase Decimal                `gorm:"type:decimal(20,4);not null" json:"amountInBase"`              // Amount converted to transaction's base currency
+	Details      map[string]interface{} `gorm:"serializer:json" json:"details"`                               // JSONB details for method-specific data
 
 	// Payment Method
 	PaymentMethod string `gorm:"type:varchar(50);not null;default:'CASH'" json:"paymentMethod"` // CASH, BANK_TRANSFER, CARD, CHEQUE, ONLINE
@@ -43,6 +44,9 @@ type Payment struct {
 	CancelledBy  *uint      `gorm:"type:bigint" json:"cancelledBy"`
 	CancelReason *string    `gorm:"type:text" json:"cancelReason"`
 
+	// Refunds
+	RefundedAmount Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"refundedAmount"` // Cumulative partial refunds, in payment currency
+
 	// Relations
 	Tenant      Tenant      `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"-"`
 	Transaction Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"transaction,omitempty"`
@@ -57,6 +61,11 @@ func (Payment) TableName() string {
 	return "payments"
 }
 
+// RefundableAmount returns the portion of the payment that has not been refunded yet
+func (p *Payment) RefundableAmount() Decimal {
+	return p.Amount.Sub(p.RefundedAmount)
+}
+
 // PaymentMethod constants
 const (
 	PaymentMethodCash         = "CASH"
@@ -67,6 +76,13 @@ const (
 	PaymentMethodOther        = "OTHER"
 )
 
+// Payment rate source constants
+const (
+	PaymentRateSourceManual  = "manual"  // Rate entered by staff
+	PaymentRateSourceNavasan = "navasan" // Live market rate from Navasan
+	PaymentRateSourceStored  = "stored"  // Tenant's recorded rate in effect at PaidAt
+)
+
 // PaymentStatus constants
 const (
 	PaymentStatusPending   = "PENDING"
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// PaymentPlan is a structured installment schedule for a partial-payment transaction.
+// It covers the balance that was outstanding when the plan was created.
+type PaymentPlan struct {
+	ID             uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint    `gorm:"type:bigint;not null;index" json:"tenantId"`
+	TransactionID  string  `gorm:"type:text;not null;uniqueIndex" json:"transactionId"`
+	Currency       string  `gorm:"type:varchar(10);not null" json:"currency"`                   // Transaction's received currency
+	PaidBeforePlan Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"paidBeforePlan"` // TotalPaid when the plan was created
+	Notes          *string `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy      uint    `gorm:"type:bigint;not null" json:"createdBy"`
+
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Installments []PaymentInstallment `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE" json:"installments"`
+	Transaction  *Transaction         `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for PaymentPlan model
+func (PaymentPlan) TableName() string {
+	return "payment_plans"
+}
+
+// PaymentInstallment is one due amount of a payment plan
+type PaymentInstallment struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	PlanID         uint       `gorm:"type:bigint;not null;index" json:"planId"`
+	TenantID       uint       `gorm:"type:bigint;not null;index" json:"tenantId"`
+	TransactionID  string     `gorm:"type:text;not null;index" json:"transactionId"`
+	Sequence       int        `gorm:"type:int;not null" json:"sequence"` // 1-based order within the plan
+	DueDate        time.Time  `gorm:"type:timestamp;not null;index" json:"dueDate"`
+	ExpectedAmount Decimal    `gorm:"type:decimal(20,4);not null" json:"expectedAmount"`
+	Currency       string     `gorm:"type:varchar(10);not null" json:"currency"`
+	PaidAmount     Decimal    `gorm:"type:decimal(20,4);not null;default:0" json:"paidAmount"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"` // PENDING, PARTIAL, PAID
+	PaidAt         *time.Time `gorm:"type:timestamp" json:"paidAt,omitempty"`
+
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName specifies the table name for PaymentInstallment model
+func (PaymentInstallment) TableName() string {
+	return "payment_installments"
+}
+
+// Installment status constants
+const (
+	InstallmentStatusPending = "PENDING"
+	InstallmentStatusPartial = "PARTIAL"
+	InstallmentStatusPaid    = "PAID"
+)
+
+// OutstandingAmount returns how much of the installment is still unpaid
+func (i *PaymentInstallment) OutstandingAmount() Decimal {
+	return i.ExpectedAmount.Sub(i.PaidAmount)
+}
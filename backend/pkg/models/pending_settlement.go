@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// Pending settlement statuses
+const (
+	PendingSettlementStatusPending  = "PENDING"
+	PendingSettlementStatusApproved = "APPROVED"
+	PendingSettlementStatusRejected = "REJECTED"
+)
+
+// PendingSettlement is a settlement at or above the tenant's approval limits, held until an owner or admin
+// other than its creator approves it. Nothing is applied to either remittance until then.
+type PendingSettlement struct {
+	ID                   uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID             uint `gorm:"type:bigint;not null;index" json:"tenantId"`
+	OutgoingRemittanceID uint `gorm:"type:bigint;not null;index" json:"outgoingRemittanceId"`
+	IncomingRemittanceID uint `gorm:"type:bigint;not null;index" json:"incomingRemittanceId"`
+
+	// Settlement as requested, with the profit it was projected to make when it was held
+	AmountIRR          Decimal `gorm:"type:decimal(20,2);not null" json:"amountIrr"`
+	ProjectedProfitCAD Decimal `gorm:"type:decimal(20,2);not null" json:"projectedProfitCad"`
+	Notes              *string `gorm:"type:text" json:"notes"` // Copied to the settlement on approval
+
+	Status       string     `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"` // PENDING, APPROVED or REJECTED
+	DecidedBy    *uint      `gorm:"type:bigint" json:"decidedBy,omitempty"`                          // Owner or admin who approved or rejected it
+	DecidedAt    *time.Time `gorm:"type:timestamp" json:"decidedAt,omitempty"`
+	DecisionNote *string    `gorm:"type:text" json:"decisionNote,omitempty"`
+	SettlementID *uint      `gorm:"type:bigint;index" json:"settlementId,omitempty"` // Settlement created on approval
+
+	CreatedBy uint      `gorm:"type:bigint;not null" json:"createdBy"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	OutgoingRemittance *OutgoingRemittance `gorm:"foreignKey:OutgoingRemittanceID;constraint:OnDelete:CASCADE" json:"outgoingRemittance,omitempty"`
+	IncomingRemittance *IncomingRemittance `gorm:"foreignKey:IncomingRemittanceID;constraint:OnDelete:CASCADE" json:"incomingRemittance,omitempty"`
+}
+
+// TableName specifies the table name for PendingSettlement model
+func (PendingSettlement) TableName() string {
+	return "pending_settlements"
+}
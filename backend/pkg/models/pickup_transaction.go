@@ -20,6 +20,7 @@ const (
 // Deprecated pickup status constants - use DisbursementStatus* instead
 const (
 	PickupStatusPending   = DisbursementStatusPending
+	PickupStatusReady     = DisbursementStatusReady
 	PickupStatusPickedUp  = LegacyStatusPickedUp // Legacy value still in DB
 	PickupStatusCancelled = DisbursementStatusCancelled
 )
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// CurrencyProfitFloor is the lowest margin a tenant accepts on a single transaction for a currency pair.
+// Transactions below it are rejected unless an admin overrides the floor with a justification.
+type CurrencyProfitFloor struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint      `gorm:"type:bigint;not null;uniqueIndex:idx_profit_floor_pair" json:"tenantId"`
+	BaseCurrency   string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_profit_floor_pair" json:"baseCurrency"` // Currency the customer sends
+	TargetCurrency string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_profit_floor_pair" json:"targetCurrency"`
+	MinMarginPct   Decimal   `gorm:"type:decimal(10,4);not null;default:0" json:"minMarginPct"` // Net profit as a percentage of the send amount; 0 blocks only losses
+	UpdatedBy      *uint     `gorm:"type:bigint" json:"updatedBy,omitempty"`
+	CreatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt      time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName specifies the table name for CurrencyProfitFloor model
+func (CurrencyProfitFloor) TableName() string {
+	return "currency_profit_floors"
+}
@@ -6,6 +6,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Receipt locales
+const (
+	ReceiptLocaleEnglish = "en"
+	ReceiptLocalePersian = "fa"
+)
+
 // ReceiptTemplate represents a customizable receipt template
 type ReceiptTemplate struct {
 	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -30,6 +36,9 @@ type ReceiptTemplate struct {
 	MarginBottom int    `gorm:"type:int;default:20" json:"marginBottom"`
 	MarginLeft   int    `gorm:"type:int;default:20" json:"marginLeft"`
 
+	// Localization
+	Locale string `gorm:"type:varchar(10);default:'en'" json:"locale"` // en, or fa for right-to-left Persian with Jalali dates
+
 	// Logo
 	LogoPath     string `gorm:"type:text" json:"logoPath"`
 	LogoPosition string `gorm:"type:varchar(20);default:'center'" json:"logoPosition"` // left, center, right
@@ -54,6 +63,25 @@ func (ReceiptTemplate) TableName() string {
 	return "receipt_templates"
 }
 
+// BranchReceiptTemplate overrides the tenant's default template of a type for one branch
+type BranchReceiptTemplate struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID     uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID     uint      `gorm:"type:bigint;not null;uniqueIndex:idx_branch_template_type" json:"branchId"`
+	TemplateType string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_branch_template_type" json:"templateType"`
+	TemplateID   uint      `gorm:"type:bigint;not null;index" json:"templateId"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Branch   *Branch          `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+	Template *ReceiptTemplate `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE" json:"template,omitempty"`
+}
+
+func (BranchReceiptTemplate) TableName() string {
+	return "branch_receipt_templates"
+}
+
 // ReceiptVariable represents available template variables
 // This is used for documentation/UI, not stored in DB
 type ReceiptVariable struct {
@@ -103,6 +131,7 @@ func GetTransactionVariables() []ReceiptVariable {
 
 		// Reference
 		{Name: "{{reference.number}}", Description: "Reference number", Example: "REF-2023122001234", Category: "Reference"},
+		{Name: "{{receipt.number}}", Description: "Sequential receipt number", Example: "RCT-DT-000123", Category: "Reference"},
 		{Name: "{{confirmation.code}}", Description: "Confirmation code", Example: "ABCD1234", Category: "Reference"},
 
 		// Date/Time Formats
@@ -127,6 +156,13 @@ func GetRemittanceVariables() []ReceiptVariable {
 		{Name: "{{remittance.eta}}", Description: "Estimated delivery", Example: "1-2 business days", Category: "Remittance"},
 		{Name: "{{pickup.location}}", Description: "Pickup location", Example: "Bank Mellat - Vanak Branch", Category: "Remittance"},
 		{Name: "{{pickup.code}}", Description: "Pickup code", Example: "PU1234567890", Category: "Remittance"},
+
+		// Payments, listed with {{#each payments}}...{{/each}}
+		{Name: "{{#each payments}}", Description: "Repeat a block for each payment settling the remittance", Example: "{{#each payments}}{{amount}} {{currency}}{{/each}}", Category: "Payments"},
+		{Name: "{{amount}}", Description: "Payment amount, inside an each block", Example: "25,000,000", Category: "Payments"},
+		{Name: "{{currency}}", Description: "Payment currency, inside an each block", Example: "IRR", Category: "Payments"},
+		{Name: "{{date}}", Description: "Payment date, inside an each block", Example: "2023-12-20", Category: "Payments"},
+		{Name: "{{reference}}", Description: "Payment reference, inside an each block", Example: "IN-1041", Category: "Payments"},
 	}
 
 	return append(base, remittance...)
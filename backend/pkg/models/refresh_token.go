@@ -11,6 +11,7 @@ type RefreshToken struct {
 	Token     string    `gorm:"type:varchar(500);uniqueIndex;not null" json:"token"`
 	ExpiresAt time.Time `gorm:"not null" json:"expiresAt"`
 	IsRevoked bool      `gorm:"default:false" json:"isRevoked"`
+	ParentID  *uint     `gorm:"index" json:"parentId,omitempty"` // Token this one was rotated from; nil for tokens issued at login
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
 
 	// Relations
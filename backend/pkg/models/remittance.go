@@ -18,6 +18,9 @@ type OutgoingRemittance struct {
 	SenderName  string  `gorm:"type:varchar(255);not null" json:"senderName"`
 	SenderPhone string  `gorm:"type:varchar(50);not null;index:idx_outgoing_sender_phone" json:"senderPhone"`
 	SenderEmail *string `gorm:"type:varchar(255)" json:"senderEmail"`
+	ClientID    *string `gorm:"type:text;index" json:"clientId,omitempty"` // CRM client the sender is, matched by phone
+
+	TransactionID *string `gorm:"type:text;index" json:"transactionId,omitempty"` // Ledger transaction the customer paid this remittance through
 
 	// Recipient Info (Receiver in Iran)
 	RecipientName    string  `gorm:"type:varchar(255);not null" json:"recipientName"`
@@ -33,15 +36,34 @@ type OutgoingRemittance struct {
 	ReceivedCAD   Decimal `gorm:"type:decimal(20,2);not null" json:"receivedCad"`   // Amount received from customer in Canada
 	FeeCAD        Decimal `gorm:"type:decimal(20,2);default:0" json:"feeCAD"`       // Fee charged in CAD
 
+	// SettlementCurrency is the currency the customer paid in; the *CAD amounts and rates are denominated in it
+	SettlementCurrency string `gorm:"type:varchar(10);not null;default:'CAD'" json:"settlementCurrency"`
+
 	// Settlement Tracking
 	SettledAmountIRR Decimal `gorm:"type:decimal(20,2);default:0" json:"settledAmountIrr"`                                               // How much has been settled
 	RemainingIRR     Decimal `gorm:"type:decimal(20,2);not null;index:idx_outgoing_tenant_remaining" json:"remainingIrr"`                // Remaining debt
+	WrittenOffIRR    Decimal `gorm:"type:decimal(20,2);default:0" json:"writtenOffIrr"`                                                  // Residual rounded off on completion
 	Status           string  `gorm:"type:varchar(50);not null;default:'PENDING';index:idx_outgoing_tenant_status_created" json:"status"` // PENDING, PARTIAL, COMPLETED, CANCELLED
 
 	// Profit Tracking
 	TotalProfitCAD Decimal `gorm:"type:decimal(20,2);default:0" json:"totalProfitCad"` // Total profit from this remittance
 	TotalCostCAD   Decimal `gorm:"type:decimal(20,2);not null" json:"totalCostCad"`    // Cost = EquivalentCAD
 
+	// Compliance Hold - held remittances are excluded from settlement suggestions and auto-settlement
+	ComplianceHold       bool       `gorm:"type:boolean;default:false;index" json:"complianceHold"`
+	ComplianceHoldReason *string    `gorm:"type:text" json:"complianceHoldReason,omitempty"`
+	ComplianceHeldAt     *time.Time `gorm:"type:timestamp" json:"complianceHeldAt,omitempty"`
+	ComplianceHeldBy     *uint      `gorm:"type:bigint" json:"complianceHeldBy,omitempty"`
+	ComplianceClearedAt  *time.Time `gorm:"type:timestamp" json:"complianceClearedAt,omitempty"`
+	ComplianceClearedBy  *uint      `gorm:"type:bigint" json:"complianceClearedBy,omitempty"`
+	ComplianceNotifiedAt *time.Time `gorm:"type:timestamp" json:"complianceNotifiedAt,omitempty"` // When staff were reminded the hold is overdue
+
+	// SamePartyFlag is why the sender and recipient look like the same party, set under the FLAG policy
+	SamePartyFlag *string `gorm:"type:text" json:"samePartyFlag,omitempty"`
+
+	// TrackingCode is the random code the customer uses to follow the remittance on the public status page
+	TrackingCode *string `gorm:"type:varchar(20);uniqueIndex" json:"trackingCode,omitempty"`
+
 	// Additional Info
 	Notes         *string `gorm:"type:text" json:"notes"`
 	InternalNotes *string `gorm:"type:text" json:"internalNotes"` // Private notes for staff
@@ -95,14 +117,21 @@ type IncomingRemittance struct {
 	FeeCAD        Decimal `gorm:"type:decimal(20,2);default:0" json:"feeCAD"`       // Fee charged
 
 	// Settlement Tracking
-	AllocatedIRR Decimal `gorm:"type:decimal(20,2);default:0" json:"allocatedIrr"`                                                   // How much allocated to settlements
-	RemainingIRR Decimal `gorm:"type:decimal(20,2);not null;index:idx_incoming_tenant_remaining" json:"remainingIrr"`                // Remaining to allocate
-	Status       string  `gorm:"type:varchar(50);not null;default:'PENDING';index:idx_incoming_tenant_status_created" json:"status"` // PENDING, PARTIAL, COMPLETED, PAID, CANCELLED
+	AllocatedIRR  Decimal `gorm:"type:decimal(20,2);default:0" json:"allocatedIrr"`                                                   // How much allocated to settlements
+	RemainingIRR  Decimal `gorm:"type:decimal(20,2);not null;index:idx_incoming_tenant_remaining" json:"remainingIrr"`                // Remaining to allocate
+	WrittenOffIRR Decimal `gorm:"type:decimal(20,2);default:0" json:"writtenOffIrr"`                                                  // Residual rounded off on completion
+	Status        string  `gorm:"type:varchar(50);not null;default:'PENDING';index:idx_incoming_tenant_status_created" json:"status"` // PENDING, PARTIAL, COMPLETED, PAID, CANCELLED
 
 	// Payment Info
 	PaymentMethod    string  `gorm:"type:varchar(50);default:'CASH'" json:"paymentMethod"` // CASH, E_TRANSFER, BANK_TRANSFER, CHEQUE
 	PaymentReference *string `gorm:"type:varchar(255)" json:"paymentReference"`
 
+	// SamePartyFlag is why the sender and recipient look like the same party, set under the FLAG policy
+	SamePartyFlag *string `gorm:"type:text" json:"samePartyFlag,omitempty"`
+
+	// TrackingCode is the random code the customer uses to follow the remittance on the public status page
+	TrackingCode *string `gorm:"type:varchar(20);uniqueIndex" json:"trackingCode,omitempty"`
+
 	// Additional Info
 	Notes         *string `gorm:"type:text" json:"notes"`
 	InternalNotes *string `gorm:"type:text" json:"internalNotes"`
@@ -141,9 +170,10 @@ type RemittanceSettlement struct {
 	SettledAmountIRR Decimal `gorm:"type:decimal(20,2);not null" json:"settledAmountIrr"` // Amount in Toman used for settlement
 
 	// Rate Difference & Profit
-	OutgoingBuyRate  Decimal `gorm:"type:decimal(20,6);not null" json:"outgoingBuyRate"`  // Buy rate of outgoing
-	IncomingSellRate Decimal `gorm:"type:decimal(20,6);not null" json:"incomingSellRate"` // Sell rate of incoming
-	ProfitCAD        Decimal `gorm:"type:decimal(20,2);not null" json:"profitCad"`        // Profit from this settlement
+	OutgoingBuyRate  Decimal `gorm:"type:decimal(20,6);not null" json:"outgoingBuyRate"`      // Buy rate of outgoing
+	IncomingSellRate Decimal `gorm:"type:decimal(20,6);not null" json:"incomingSellRate"`     // Sell rate of incoming
+	ProfitCAD        Decimal `gorm:"type:decimal(20,2);not null" json:"profitCad"`            // Profit from this settlement
+	Currency         string  `gorm:"type:varchar(10);not null;default:'CAD'" json:"currency"` // Currency ProfitCAD is denominated in
 
 	// Metadata
 	Notes     *string   `gorm:"type:text" json:"notes"`
@@ -161,6 +191,30 @@ func (RemittanceSettlement) TableName() string {
 	return "remittance_settlements"
 }
 
+// RemittanceWriteOff records a settlement residual too small to settle that was written off
+// to the rounding ledger account so its remittance could complete
+type RemittanceWriteOff struct {
+	ID                   uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID             uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	SettlementID         uint   `gorm:"type:bigint;not null;index" json:"settlementId"`          // Settlement that left the residual
+	OutgoingRemittanceID *uint  `gorm:"type:bigint;index" json:"outgoingRemittanceId,omitempty"` // Set when the residual was outgoing debt
+	IncomingRemittanceID *uint  `gorm:"type:bigint;index" json:"incomingRemittanceId,omitempty"` // Set when the residual was unallocated incoming funds
+	Account              string `gorm:"type:varchar(50);not null;index" json:"account"`          // Ledger account the residual was posted to
+
+	Currency  string    `gorm:"type:varchar(10);not null;default:'IRR'" json:"currency"`
+	Amount    Decimal   `gorm:"type:decimal(20,2);not null" json:"amount"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	CreatedBy uint      `gorm:"type:bigint;not null" json:"createdBy"`
+
+	// Relations
+	Tenant     *Tenant               `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+	Settlement *RemittanceSettlement `gorm:"foreignKey:SettlementID;constraint:OnDelete:CASCADE" json:"settlement,omitempty"`
+}
+
+func (RemittanceWriteOff) TableName() string {
+	return "remittance_write_offs"
+}
+
 // Remittance Status Constants
 const (
 	RemittanceStatusPending   = "PENDING"   // Not yet settled/paid
@@ -169,3 +223,13 @@ const (
 	RemittanceStatusPaid      = "PAID"      // Payment made to recipient (incoming only)
 	RemittanceStatusCancelled = "CANCELLED" // Cancelled
 )
+
+// Actions taken on an outgoing remittance left on compliance hold too long
+const (
+	HeldRemittanceActionNone    = ""
+	HeldRemittanceActionNotify  = "NOTIFY"  // Remind the branch the hold still needs a decision
+	HeldRemittanceActionReverse = "REVERSE" // Cancel the remittance and credit the sender a refund
+)
+
+// RemittanceWriteOffAccount is the ledger account settlement residuals are written off to
+const RemittanceWriteOffAccount = "SETTLEMENT_ROUNDING"
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// Sequence is a per-tenant counter behind human-readable numbers such as transaction numbers.
+// Each key counts independently, and restarts when its reset period rolls over.
+type Sequence struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID uint   `gorm:"type:bigint;not null;uniqueIndex:idx_tenant_sequence_key" json:"tenantId"`
+	Key      string `gorm:"column:sequence_key;type:varchar(100);not null;uniqueIndex:idx_tenant_sequence_key" json:"key"` // What is being numbered, e.g. "TXN:3" for branch 3's transactions
+	Period   string `gorm:"type:varchar(8);not null;default:''" json:"period"`                                             // Period the current value belongs to: YYYYMMDD, YYYYMM, or empty if it never resets
+	Value    int64  `gorm:"type:bigint;not null;default:0" json:"value"`                                                   // Last number issued
+
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName specifies the table name for Sequence model
+func (Sequence) TableName() string {
+	return "sequences"
+}
+
+// Sequence reset policies
+const (
+	SequenceResetDaily   = "DAILY"   // Numbering restarts at 1 every day
+	SequenceResetMonthly = "MONTHLY" // Numbering restarts at 1 every month
+	SequenceResetNever   = "NEVER"   // Numbering keeps incrementing
+)
+
+// SequencePeriod returns the period a number issued at t belongs to under the given reset policy
+func SequencePeriod(policy string, t time.Time) string {
+	switch policy {
+	case SequenceResetDaily:
+		return t.Format("20060102")
+	case SequenceResetMonthly:
+		return t.Format("200601")
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// StructuringRule sets when a customer's transactions in one currency are flagged as possible structuring:
+// several transactions, each below the reporting threshold, that together reach it within a window.
+type StructuringRule struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID           uint      `gorm:"type:bigint;not null;uniqueIndex:idx_structuring_rule_currency" json:"tenantId"`
+	Currency           string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_structuring_rule_currency" json:"currency"` // Send currency, or "*" for currencies without their own rule
+	ReportingThreshold Decimal   `gorm:"type:decimal(20,4);not null;default:0" json:"reportingThreshold"`                     // 0 disables the rule
+	ShortWindowHours   int       `gorm:"type:int;not null;default:24" json:"shortWindowHours"`                                // 0 disables the short window
+	LongWindowDays     int       `gorm:"type:int;not null;default:7" json:"longWindowDays"`                                   // 0 disables the long window
+	UpdatedBy          *uint     `gorm:"type:bigint" json:"updatedBy,omitempty"`
+	CreatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt          time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+}
+
+// TableName specifies the table name for StructuringRule model
+func (StructuringRule) TableName() string {
+	return "structuring_rules"
+}
+
+// StructuringRuleAnyCurrency is the currency of a tenant's fallback structuring rule
+const StructuringRuleAnyCurrency = "*"
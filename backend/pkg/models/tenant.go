@@ -12,6 +12,8 @@ type Tenant struct {
 	CurrentLicenseID *uint      `gorm:"type:bigint;index" json:"currentLicenseId"`
 	UserLimit        int        `gorm:"type:int;default:1" json:"userLimit"` // Copied from license
 	Status           string     `gorm:"type:varchar(50);not null;default:'trial'" json:"status"` // trial, active, suspended, expired
+	AccessTokenTTL   int        `gorm:"type:int;default:0" json:"accessTokenTtl"`  // Access token lifetime in minutes; 0 uses the default
+	RefreshTokenTTL  int        `gorm:"type:int;default:0" json:"refreshTokenTtl"` // Refresh token lifetime in minutes; 0 uses the default
 	CreatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 
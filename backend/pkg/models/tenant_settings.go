@@ -0,0 +1,166 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// TenantSettings holds per-tenant feature toggles and policy configuration.
+// A tenant without a row uses the defaults returned by DefaultTenantSettings.
+type TenantSettings struct {
+	ID       uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID uint `gorm:"type:bigint;not null;uniqueIndex" json:"tenantId"`
+
+	// Security
+	PasswordHistoryDepth int `gorm:"type:int;not null;default:5" json:"passwordHistoryDepth"` // Previous passwords a user may not reuse; 0 only blocks the current one
+
+	// Customer KYC
+	RequireCustomerIDNumber bool `gorm:"type:boolean;default:false" json:"requireCustomerIdNumber"` // Name + phone + ID number required to save a customer
+
+	// Transactions
+	AutoFillTransactionRate   bool    `gorm:"type:boolean;default:true" json:"autoFillTransactionRate"`                  // Fill an omitted rate from the effective exchange rate
+	FullKYCThreshold          Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"fullKycThreshold"`             // Transactions at or above this amount (in base currency) need approved KYC to complete; 0 disables
+	CompletedTransactionEdits string  `gorm:"type:varchar(20);not null;default:'OPEN'" json:"completedTransactionEdits"` // Who may edit a completed transaction: OPEN, ADMIN_ONLY or LOCKED
+	TransactionNumberReset    string  `gorm:"type:varchar(10);not null;default:'DAILY'" json:"transactionNumberReset"`   // When each branch's transaction numbering restarts: DAILY, MONTHLY or NEVER
+	DocumentRequiredThreshold Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"documentRequiredThreshold"`    // Transactions at or above this amount (in base currency) need a supporting document attached to complete; 0 disables
+	RateSpreadWarnPct         Decimal `gorm:"type:decimal(10,4);not null;default:0" json:"rateSpreadWarnPct"`            // Warn when the applied rate deviates from the standard rate by more than this percent; 0 disables
+	RateSpreadBlockPct        Decimal `gorm:"type:decimal(10,4);not null;default:0" json:"rateSpreadBlockPct"`           // Reject such transactions unless an owner or admin overrides with a reason; 0 disables
+
+	// Compliance monitoring
+	VolumeSpikeMultiple Decimal `gorm:"type:decimal(10,2);not null;default:3" json:"volumeSpikeMultiple"` // Alert when a customer's last 7 days exceed this multiple of their weekly average; 0 disables
+	VolumeBaselineWeeks int     `gorm:"type:int;not null;default:8" json:"volumeBaselineWeeks"`           // Weeks before the recent window that make up the weekly average
+
+	// KYC renewal: approved records are reminded this many days before they or their ID expire (0 disables
+	// reminders) and drop to EXPIRED once lapsed
+	KYCRenewalReminderDays int  `gorm:"type:int;not null;default:30" json:"kycRenewalReminderDays"`
+	KYCExpiryTickets       bool `gorm:"type:boolean;default:false" json:"kycExpiryTickets"` // Also open a compliance ticket for each reminder and expiry
+
+	// Exchange rates
+	RateRefreshIntervalMinutes int        `gorm:"type:int;not null;default:0" json:"rateRefreshIntervalMinutes"`          // Minutes between automatic refreshes from the external rate API; 0 means rates are manual only
+	RateRefreshBaseCurrency    string     `gorm:"type:varchar(10);not null;default:'USD'" json:"rateRefreshBaseCurrency"` // Base currency requested on each automatic refresh
+	RatesLastRefreshedAt       *time.Time `gorm:"type:timestamp" json:"ratesLastRefreshedAt,omitempty"`                   // Last successful refresh from the external rate API, manual or scheduled
+	RateSnapshotMinutes        int        `gorm:"type:int;not null;default:60" json:"rateSnapshotMinutes"`                // Minutes between stored rate snapshots for dashboard trends; 0 disables snapshots
+	RateQuoteMarginPct         Decimal    `gorm:"type:decimal(10,4);not null;default:0" json:"rateQuoteMarginPct"`        // Percent the buy and sell quotes sit below and above the stored rate in snapshots; 0 records the stored rate as both
+
+	// Reporting
+	BaseCurrency         string  `gorm:"type:varchar(10);not null;default:'CAD'" json:"baseCurrency"`     // Currency profit totals are normalized to
+	PairMarginFloorPct   Decimal `gorm:"type:decimal(10,4);not null;default:0" json:"pairMarginFloorPct"` // Alert when a currency pair's average margin (%) falls below this; 0 disables
+	PairMarginWindowDays int     `gorm:"type:int;not null;default:7" json:"pairMarginWindowDays"`         // Days of transactions the pair margin is averaged over
+
+	// Dashboard
+	DashboardCacheSeconds int `gorm:"type:int;not null;default:30" json:"dashboardCacheSeconds"` // How long dashboard data is served from cache between changes; 0 disables caching
+
+	// Payments: largest residual that may be written off on completion. WriteOffCurrencyCaps sets it per currency code in
+	// that currency, e.g. {"IRR": 50000}; other currencies are valued in the base currency against WriteOffCap. 0 disables write-offs.
+	WriteOffCap          Decimal            `gorm:"type:decimal(20,4);not null;default:0" json:"writeOffCap"`
+	WriteOffCurrencyCaps map[string]float64 `gorm:"type:text;serializer:json" json:"writeOffCurrencyCaps"`
+
+	// Collections: days a partially paid transaction may stay open before a follow-up ticket is opened (0 disables)
+	PartialPaymentTicketAfterDays int `gorm:"type:int;not null;default:0" json:"partialPaymentTicketAfterDays"`
+
+	// Remittances
+	RoundSettlementResiduals bool   `gorm:"type:boolean;default:true" json:"roundSettlementResiduals"`           // Write off residuals within the currency's tolerance so settled remittances complete
+	NotifyBranchesOnSettle   bool   `gorm:"type:boolean;default:true" json:"notifyBranchesOnSettle"`             // Push a settlement event to the outgoing and incoming remittances' branches
+	EmailBranchesOnSettle    bool   `gorm:"type:boolean;default:false" json:"emailBranchesOnSettle"`             // Also email the users of those branches
+	AutoCreateSenderClients  bool   `gorm:"type:boolean;default:false" json:"autoCreateSenderClients"`           // Create a CRM client for an outgoing remittance sender whose phone matches none
+	SamePartyRemittances     string `gorm:"type:varchar(10);not null;default:'OFF'" json:"samePartyRemittances"` // Remittances where sender and recipient look like the same party: OFF, FLAG or BLOCK
+	PublicTrackingEnabled    bool   `gorm:"type:boolean;default:false" json:"publicTrackingEnabled"`             // Let customers follow their remittances by tracking code on the public status page
+
+	// Settlement approval: settlements at or above either limit wait for an owner or admin other than their creator (0 disables the limit)
+	SettlementApprovalAmountIRR Decimal `gorm:"type:decimal(20,2);not null;default:0" json:"settlementApprovalAmountIrr"`
+	SettlementApprovalProfitCAD Decimal `gorm:"type:decimal(20,2);not null;default:0" json:"settlementApprovalProfitCad"` // Compared against the size of the profit or loss
+
+	// Compliance holds: hours an outgoing remittance may stay held before each action (0 disables the action)
+	HoldNotifyAfterHours      int `gorm:"type:int;not null;default:72" json:"holdNotifyAfterHours"`
+	HoldAutoReverseAfterHours int `gorm:"type:int;not null;default:0" json:"holdAutoReverseAfterHours"`
+
+	// Notifications
+	TicketOnWebhookFailure bool `gorm:"type:boolean;default:false" json:"ticketOnWebhookFailure"` // Open a support ticket when a webhook exhausts its retries
+	NotifyPickupReady      bool `gorm:"type:boolean;default:true" json:"notifyPickupReady"`       // Text/email the recipient when a pickup is ready
+
+	// Webhooks: transactions at or above this value (in base currency) raise a transaction.large event (0 disables)
+	LargeTransactionWebhookAmount Decimal `gorm:"type:decimal(20,4);not null;default:0" json:"largeTransactionWebhookAmount"`
+
+	// Support tickets
+	TicketAssignmentCap       int   `gorm:"type:int;not null;default:0" json:"ticketAssignmentCap"` // Open tickets an agent may hold before auto-assignment skips them; 0 means no cap
+	TicketSLAEscalateToUserID *uint `gorm:"type:bigint" json:"ticketSlaEscalateToUserId,omitempty"` // User (e.g. the support lead) SLA-breached tickets are reassigned to; unset leaves the assignee alone
+
+	// Audit
+	ReadAuditEntities string `gorm:"type:varchar(255);not null;default:''" json:"readAuditEntities"` // Comma-separated entity types whose views are written to the audit log, e.g. "CustomerCompliance,Client"; empty disables read auditing
+
+	// Reconciliation reminders: consecutive missed days before each escalation level (0 disables the level)
+	ReconciliationWarnAfterDays   int `gorm:"type:int;not null;default:1" json:"reconciliationWarnAfterDays"`
+	ReconciliationAlertAfterDays  int `gorm:"type:int;not null;default:2" json:"reconciliationAlertAfterDays"`
+	ReconciliationTicketAfterDays int `gorm:"type:int;not null;default:3" json:"reconciliationTicketAfterDays"`
+
+	// Reconciliation auto-match: largest difference between counted and expected cash in a currency that is not flagged.
+	// ReconciliationCurrencyTolerances sets it per currency code, e.g. {"IRR": 500000}; other currencies use ReconciliationVarianceTolerance.
+	ReconciliationVarianceTolerance  Decimal            `gorm:"type:decimal(20,4);not null;default:0" json:"reconciliationVarianceTolerance"`
+	ReconciliationCurrencyTolerances map[string]float64 `gorm:"type:text;serializer:json" json:"reconciliationCurrencyTolerances"`
+
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+}
+
+// TableName specifies the table name for TenantSettings model
+func (TenantSettings) TableName() string {
+	return "tenant_settings"
+}
+
+// AuditsReadsOf reports whether views of the entity type are recorded in the audit log
+func (s *TenantSettings) AuditsReadsOf(entityType string) bool {
+	for _, entity := range strings.Split(s.ReadAuditEntities, ",") {
+		if strings.EqualFold(strings.TrimSpace(entity), entityType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Completed transaction edit policies
+const (
+	CompletedTransactionEditsOpen      = "OPEN"       // Anyone who can edit transactions
+	CompletedTransactionEditsAdminOnly = "ADMIN_ONLY" // Tenant owners and admins only
+	CompletedTransactionEditsLocked    = "LOCKED"     // Nobody; completed transactions must be reversed instead
+)
+
+// Same-party remittance policies
+const (
+	SamePartyRemittancesOff   = "OFF"   // No check
+	SamePartyRemittancesFlag  = "FLAG"  // Create the remittance but mark it for compliance review
+	SamePartyRemittancesBlock = "BLOCK" // Refuse to create the remittance
+)
+
+// DefaultPasswordHistoryDepth is how many previous passwords are remembered unless a tenant configures otherwise
+const DefaultPasswordHistoryDepth = 5
+
+// DefaultTenantSettings returns the settings used when a tenant has not configured any
+func DefaultTenantSettings(tenantID uint) TenantSettings {
+	return TenantSettings{
+		TenantID:                  tenantID,
+		PasswordHistoryDepth:      DefaultPasswordHistoryDepth,
+		AutoFillTransactionRate:   true,
+		CompletedTransactionEdits: CompletedTransactionEditsOpen,
+		TransactionNumberReset:    SequenceResetDaily,
+		VolumeSpikeMultiple:       NewDecimal(3),
+		VolumeBaselineWeeks:       8,
+		KYCRenewalReminderDays:    30,
+		RateRefreshBaseCurrency:   "USD",
+		RateSnapshotMinutes:       60,
+		BaseCurrency:              "CAD",
+		PairMarginWindowDays:      7,
+		DashboardCacheSeconds:     30,
+		RoundSettlementResiduals:  true,
+		NotifyBranchesOnSettle:    true,
+		NotifyPickupReady:         true,
+		SamePartyRemittances:      SamePartyRemittancesOff,
+		HoldNotifyAfterHours:      72,
+
+		ReconciliationWarnAfterDays:   1,
+		ReconciliationAlertAfterDays:  2,
+		ReconciliationTicketAfterDays: 3,
+	}
+}
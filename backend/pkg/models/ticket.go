@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -40,6 +42,41 @@ const (
 	TicketCategoryAccountAccess TicketCategory = "ACCOUNT_ACCESS"
 )
 
+// TicketCategoriesAll in a user's ticket categories makes them eligible for tickets of every category
+const TicketCategoriesAll = "ALL"
+
+// NormalizeTicketCategories cleans up a comma-separated list of ticket categories, rejecting unknown ones
+func NormalizeTicketCategories(value string) (string, error) {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		category := strings.ToUpper(strings.TrimSpace(part))
+		if category == "" || seen[category] {
+			continue
+		}
+		switch TicketCategory(category) {
+		case TicketCategoryGeneral, TicketCategoryTransaction, TicketCategoryRemittance, TicketCategoryCompliance,
+			TicketCategoryTechnical, TicketCategoryBilling, TicketCategoryAccountAccess, TicketCategoriesAll:
+		default:
+			return "", fmt.Errorf("unknown ticket category: %s", part)
+		}
+		seen[category] = true
+		categories = append(categories, category)
+	}
+	return strings.Join(categories, ","), nil
+}
+
+// HandlesTicketCategory reports whether the user takes auto-assigned tickets of the category
+func (u *User) HandlesTicketCategory(category TicketCategory) bool {
+	for _, part := range strings.Split(u.TicketCategories, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part == TicketCategoriesAll || part == string(category) {
+			return true
+		}
+	}
+	return false
+}
+
 // Ticket represents a support ticket in the system
 type Ticket struct {
 	ID         uint           `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -55,11 +92,13 @@ type Ticket struct {
 	CustomerID      *uint `gorm:"type:bigint;index" json:"customerId"` // If ticket is about/from a customer
 
 	// Assignment
-	AssignedToUserID *uint `gorm:"type:bigint;index" json:"assignedToUserId"`
+	AssignedToUserID *uint      `gorm:"type:bigint;index" json:"assignedToUserId"`
+	AssignedAt       *time.Time `gorm:"type:timestamp" json:"assignedAt"` // When the current assignee was given the ticket
 
 	// Related Entity - for context linking
 	RelatedEntityType string `gorm:"type:varchar(50)" json:"relatedEntityType"` // "transaction", "remittance", "pickup"
 	RelatedEntityID   uint   `gorm:"type:bigint" json:"relatedEntityId"`
+	RelatedEntityRef  string `gorm:"type:varchar(64);index" json:"relatedEntityRef,omitempty"` // ID of an entity keyed by string, such as a transaction's UUID
 
 	// Branch context
 	BranchID *uint `gorm:"type:bigint;index" json:"branchId"`
@@ -75,6 +114,12 @@ type Ticket struct {
 	Resolution       string     `gorm:"type:text" json:"resolution"`
 	ResolvedAt       *time.Time `gorm:"type:timestamp" json:"resolvedAt"`
 	ResolvedByUserID *uint      `gorm:"type:bigint" json:"resolvedByUserId"`
+	MergedIntoID     *uint      `gorm:"type:bigint;index" json:"mergedIntoId"` // Ticket this duplicate was merged into; merged tickets take no further replies
+
+	// Customer satisfaction, captured once the ticket is resolved
+	SatisfactionScore   *int       `gorm:"type:int" json:"satisfactionScore"` // 1 (very dissatisfied) to 5 (very satisfied)
+	SatisfactionComment string     `gorm:"type:text" json:"satisfactionComment"`
+	SatisfactionRatedAt *time.Time `gorm:"type:timestamp" json:"satisfactionRatedAt"`
 
 	// SLA Tracking
 	FirstResponseAt *time.Time `gorm:"type:timestamp" json:"firstResponseAt"`
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Transaction represents a financial transaction
@@ -9,13 +11,16 @@ type Transaction struct {
 	ID                 string  `gorm:"primaryKey;type:text" json:"id"`
 	TenantID           uint    `gorm:"type:bigint;not null;index" json:"tenantId"` // *** ADDED FOR TENANT ISOLATION ***
 	BranchID           *uint   `gorm:"type:bigint;index" json:"branchId"`          // Which branch created this transaction
+	TransactionNumber  *string `gorm:"column:transaction_number;type:varchar(40);index" json:"transactionNumber,omitempty"` // Human-readable number, e.g. TXN-DT-20250610-0001
 	ClientID           string  `gorm:"column:client_id;type:text;not null;index" json:"clientId" validate:"required"`
 	PaymentMethod      string  `gorm:"column:payment_method;type:text;not null" json:"paymentMethod" validate:"required"` // "CASH", "BANK_TRANSFER", etc.
 	SendCurrency       string  `gorm:"column:send_currency;type:text;not null" json:"sendCurrency" validate:"required,len=3"`
 	SendAmount         Decimal `gorm:"column:send_amount;type:decimal(20,4);not null" json:"sendAmount" validate:"required"`
 	ReceiveCurrency    string  `gorm:"column:receive_currency;type:text;not null" json:"receiveCurrency" validate:"required,len=3"`
 	ReceiveAmount      Decimal `gorm:"column:receive_amount;type:decimal(20,4);not null" json:"receiveAmount" validate:"required"`
-	RateApplied        Decimal `gorm:"column:rate_applied;type:decimal(20,4);not null" json:"rateApplied" validate:"required"`
+	RateApplied        Decimal `gorm:"column:rate_applied;type:decimal(20,4);not null" json:"rateApplied"` // Auto-filled from the effective rate when omitted
+	RateSource         string  `gorm:"column:rate_source;type:varchar(20);default:'ENTERED'" json:"rateSource"`
+	ExchangeRateID     *uint   `gorm:"column:exchange_rate_id;type:bigint" json:"exchangeRateId,omitempty"` // Rate record used when auto-filled
 	FeeCharged         Decimal `gorm:"column:fee_charged;type:decimal(20,4);default:0" json:"feeCharged"`
 	BeneficiaryName    *string `gorm:"column:beneficiary_name;type:text" json:"beneficiaryName"`
 	BeneficiaryDetails *string `gorm:"column:beneficiary_details;type:text" json:"beneficiaryDetails"`
@@ -24,6 +29,9 @@ type Transaction struct {
 	StandardRate            Decimal `gorm:"column:standard_rate;type:decimal(20,4);default:0" json:"standardRate"` // Market/Base rate at time of transaction
 	Profit                  Decimal `gorm:"column:profit;type:decimal(20,4);default:0" json:"profit"`             // Calculated profit (in Send Currency)
 	ProfitCalculationStatus string  `gorm:"column:profit_calc_status;type:varchar(20);default:'CALCULATED'" json:"profitCalcStatus"` // CALCULATED, PENDING, FAILED
+	DiscountAmount          Decimal `gorm:"column:discount_amount;type:decimal(20,4);default:0" json:"discountAmount"` // Discount given to the customer (in Send Currency)
+	CostAmount              Decimal `gorm:"column:cost_amount;type:decimal(20,4);default:0" json:"costAmount"`         // Direct costs incurred, e.g. bank charges (in Send Currency)
+	NetProfit               Decimal `gorm:"column:net_profit;type:decimal(20,4);default:0" json:"netProfit"`           // Profit + Fee - Cost - Discount
 
 	// Multi-Payment Support (NEW)
 	TotalReceived       Decimal    `gorm:"column:total_received;type:decimal(20,4)" json:"totalReceived"`                      // Total amount client gave us
@@ -35,15 +43,29 @@ type Transaction struct {
 	IsEdited            bool       `gorm:"column:is_edited;type:boolean;default:false" json:"isEdited"`
 	LastEditedAt        *time.Time `gorm:"column:last_edited_at;type:timestamp" json:"lastEditedAt"`
 	EditedByBranchID    *uint      `gorm:"column:edited_by_branch_id;type:bigint" json:"editedByBranchId"`  // *** ADDED FOR AUDIT ***
+	CreatedBy           *uint      `gorm:"column:created_by;type:bigint;index" json:"createdBy,omitempty"`  // User who entered the transaction
 	EditHistory         *string    `gorm:"column:edit_history;type:text" json:"editHistory"`                // JSON string
 	Status              string     `gorm:"column:status;type:text;default:'COMPLETED';index" json:"status"` // COMPLETED or CANCELLED
 	CancellationReason  *string    `gorm:"column:cancellation_reason;type:text" json:"cancellationReason,omitempty"`
 	CancelledAt         *time.Time `gorm:"column:cancelled_at;type:timestamp" json:"cancelledAt,omitempty"`
 	CancelledBy         *uint      `gorm:"column:cancelled_by;type:bigint" json:"cancelledBy,omitempty"` // User ID who cancelled
+	WrittenOffAmount    Decimal    `gorm:"column:written_off_amount;type:decimal(20,4);default:0" json:"writtenOffAmount"` // Residual balance written off on completion (in Received Currency)
+	WriteOffReason      *string    `gorm:"column:write_off_reason;type:text" json:"writeOffReason,omitempty"`
+	WrittenOffAt        *time.Time `gorm:"column:written_off_at;type:timestamp" json:"writtenOffAt,omitempty"`
+	WrittenOffBy        *uint      `gorm:"column:written_off_by;type:bigint" json:"writtenOffBy,omitempty"` // User ID who authorized the write-off
+	CollectionTicketID  *uint      `gorm:"column:collection_ticket_id;type:bigint;index" json:"collectionTicketId,omitempty"` // Follow-up ticket opened while the transaction stayed partially paid
+	MarginOverrideReason *string   `gorm:"column:margin_override_reason;type:text" json:"marginOverrideReason,omitempty"` // Justification for accepting a margin below the pair's profit floor
+	MarginOverrideBy     *uint     `gorm:"column:margin_override_by;type:bigint" json:"marginOverrideBy,omitempty"`       // Admin who overrode the profit floor
+	RateWarning          *string   `gorm:"column:rate_warning;type:text" json:"rateWarning,omitempty"`                    // Set when the applied rate is further from the standard rate than the tenant's warning spread
+	RateOverride         bool      `gorm:"column:rate_override;type:boolean;default:false" json:"rateOverride"`           // Accept a rate beyond the tenant's blocking spread; needs RateOverrideReason and an owner or admin
+	RateOverrideReason   *string   `gorm:"column:rate_override_reason;type:text" json:"rateOverrideReason,omitempty"`
+	RateOverrideBy       *uint     `gorm:"column:rate_override_by;type:bigint" json:"rateOverrideBy,omitempty"` // Admin who accepted the off-market rate
 	TransactionDate     time.Time  `gorm:"column:transaction_date;type:timestamp;default:CURRENT_TIMESTAMP;index" json:"transactionDate"`
 	Version             int        `gorm:"not null;default:0" json:"version"` // Optimistic locking
 	CreatedAt           time.Time  `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP;autoCreateTime" json:"createdAt"`
 	UpdatedAt           time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime" json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"`                           // Soft delete; the ledger and cash effects are reversed while deleted
+	DeletedBy           *uint      `gorm:"column:deleted_by;type:bigint" json:"deletedBy,omitempty"` // User ID who deleted
 
 	Client   *Client   `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE" json:"client"`
 	Tenant   Tenant    `gorm:"foreignKey:TenantID;constraint:OnDelete:RESTRICT" json:"tenant,omitempty"`
@@ -56,6 +78,19 @@ func (Transaction) TableName() string {
 	return "transactions"
 }
 
+// IsCompleted reports whether the transaction has been carried out in full: a single-payment
+// transaction or a fully paid multi-payment one that has not been cancelled
+func (t *Transaction) IsCompleted() bool {
+	if t.Status != "" && t.Status != StatusCompleted {
+		return false
+	}
+	switch t.PaymentStatus {
+	case "", PaymentStatusSingle, PaymentStatusFullyPaid:
+		return true
+	}
+	return false
+}
+
 // TransactionMethod Constants
 const (
 	TransactionMethodCash   = "CASH_EXCHANGE"
@@ -84,3 +119,9 @@ const (
 	ProfitStatusPending    = "PENDING"    // Awaiting background job to calculate (rate unavailable)
 	ProfitStatusFailed     = "FAILED"     // Calculation failed permanently
 )
+
+// RateSource constants
+const (
+	TransactionRateEntered   = "ENTERED"        // Rate typed in by the teller
+	TransactionRateEffective = "EFFECTIVE_RATE" // Rate filled from the tenant's current exchange rate
+)
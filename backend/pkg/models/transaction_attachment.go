@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// TransactionAttachment is a supporting document (invoice, ID copy, bank slip) filed against a transaction.
+// Transactions at or above the tenant's DocumentRequiredThreshold need one before they can complete.
+type TransactionAttachment struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID      uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	TransactionID string `gorm:"type:text;not null;index" json:"transactionId"`
+
+	FileName    string `gorm:"type:varchar(255);not null" json:"fileName"`
+	FilePath    string `gorm:"type:text;not null" json:"-"`
+	FileSize    int64  `gorm:"type:bigint" json:"fileSize"`
+	MimeType    string `gorm:"type:varchar(100)" json:"mimeType"`
+	Description string `gorm:"type:varchar(255)" json:"description,omitempty"`
+
+	UploadedBy uint      `gorm:"type:bigint;not null" json:"uploadedBy"`
+	CreatedAt  time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+
+	// Relations
+	Transaction *Transaction `gorm:"foreignKey:TransactionID;constraint:OnDelete:CASCADE" json:"transaction,omitempty"`
+}
+
+// TableName specifies the table name for TransactionAttachment model
+func (TransactionAttachment) TableName() string {
+	return "transaction_attachments"
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// TransactionRateAudit is an immutable snapshot of the exchange rate context a transaction was created under.
+// Rows are only ever inserted, so later rate edits or overrides cannot change what a regulator sees.
+type TransactionRateAudit struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID       uint   `gorm:"type:bigint;not null;index" json:"tenantId"`
+	TransactionID  string `gorm:"type:text;not null;uniqueIndex" json:"transactionId"`
+	BaseCurrency   string `gorm:"type:varchar(10);not null" json:"baseCurrency"` // Currency the customer sends
+	TargetCurrency string `gorm:"type:varchar(10);not null" json:"targetCurrency"`
+
+	// Rate the customer got and whether the teller typed it or it was filled in
+	RateApplied Decimal `gorm:"type:decimal(20,6);not null" json:"rateApplied"`
+	RateSource  string  `gorm:"type:varchar(20);not null" json:"rateSource"` // ENTERED or EFFECTIVE_RATE
+
+	// Effective rate the profit was measured against; a manual override when one was in force
+	StandardRate           *Decimal   `gorm:"type:decimal(20,6)" json:"standardRate,omitempty"`
+	StandardRateID         *uint      `gorm:"type:bigint" json:"standardRateId,omitempty"`
+	StandardRateSource     string     `gorm:"type:varchar(20)" json:"standardRateSource,omitempty"` // API or MANUAL
+	StandardRateRecordedAt *time.Time `gorm:"type:timestamp" json:"standardRateRecordedAt,omitempty"`
+
+	// Latest external market rate for the pair, whether or not an override was in force
+	MarketRate           *Decimal   `gorm:"type:decimal(20,6)" json:"marketRate,omitempty"`
+	MarketRateID         *uint      `gorm:"type:bigint" json:"marketRateId,omitempty"`
+	MarketRateRecordedAt *time.Time `gorm:"type:timestamp" json:"marketRateRecordedAt,omitempty"`
+
+	// Manual override in force, if any
+	ManualOverrideRate      *Decimal   `gorm:"type:decimal(20,6)" json:"manualOverrideRate,omitempty"`
+	ManualOverrideID        *uint      `gorm:"type:bigint" json:"manualOverrideId,omitempty"`
+	ManualOverrideExpiresAt *time.Time `gorm:"type:timestamp" json:"manualOverrideExpiresAt,omitempty"`
+
+	CapturedAt time.Time `gorm:"type:timestamp;not null" json:"capturedAt"`
+}
+
+// TableName specifies the table name for TransactionRateAudit model
+func (TransactionRateAudit) TableName() string {
+	return "transaction_rate_audits"
+}
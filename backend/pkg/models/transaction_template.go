@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// TransactionTemplate holds the details a regular customer repeats on every exchange, so a teller
+// only has to enter the amount and rate. A template without a branch is shared by the tenant's branches.
+type TransactionTemplate struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID           uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	BranchID           *uint     `gorm:"type:bigint;index" json:"branchId,omitempty"` // Nil for every branch
+	Name               string    `gorm:"type:varchar(255);not null" json:"name"`
+	ClientID           string    `gorm:"type:text;not null;index" json:"clientId"`
+	PaymentMethod      string    `gorm:"type:varchar(50);not null" json:"paymentMethod"` // CASH_EXCHANGE or BANK_TRANSFER
+	SendCurrency       string    `gorm:"type:varchar(10);not null" json:"sendCurrency"`
+	ReceiveCurrency    string    `gorm:"type:varchar(10);not null" json:"receiveCurrency"`
+	FeeCharged         Decimal   `gorm:"type:decimal(20,4);default:0" json:"feeCharged"` // Default fee, in the send currency
+	BeneficiaryName    *string   `gorm:"type:text" json:"beneficiaryName,omitempty"`
+	BeneficiaryDetails *string   `gorm:"type:text" json:"beneficiaryDetails,omitempty"`
+	UserNotes          *string   `gorm:"type:text" json:"userNotes,omitempty"`
+	CreatedBy          uint      `gorm:"type:bigint" json:"createdBy"`
+	CreatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt          time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Client *Client `gorm:"foreignKey:ClientID;constraint:OnDelete:CASCADE" json:"client,omitempty"`
+	Branch *Branch `gorm:"foreignKey:BranchID;constraint:OnDelete:CASCADE" json:"branch,omitempty"`
+}
+
+// TableName specifies the table name for TransactionTemplate model
+func (TransactionTemplate) TableName() string {
+	return "transaction_templates"
+}
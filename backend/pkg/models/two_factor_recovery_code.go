@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactorRecoveryCode is a single-use code that completes a 2FA login when the authenticator is unavailable
+type TwoFactorRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"userId"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"` // SHA-256 of the code; the code itself is shown once
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"createdAt"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for TwoFactorRecoveryCode model
+func (TwoFactorRecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
@@ -20,6 +20,12 @@ type User struct {
 	LicenseActivatedAt *time.Time `gorm:"type:timestamp" json:"licenseActivatedAt"`                 // When license was first activated
 	Status             string     `gorm:"type:varchar(50);not null;default:'active'" json:"status"` // active, suspended, trial_expired, license_expired
 	RecoveryEmail      *string    `gorm:"type:varchar(255)" json:"recoveryEmail,omitempty"`         // Alternative email for password resets
+	TwoFactorEnabled   bool       `gorm:"type:boolean;default:false" json:"twoFactorEnabled"`
+	TwoFactorSecret    *string    `gorm:"type:varchar(64)" json:"-"`                                     // Base32 TOTP secret; set on enrollment, active once TwoFactorEnabled
+	TwoFactorLastStep  int64      `gorm:"type:bigint;default:0" json:"-"`                                // Last TOTP time step accepted, so a code cannot be replayed
+	FailedLoginCount   int        `gorm:"type:int;not null;default:0" json:"failedLoginCount"`           // Consecutive bad passwords; reset on successful login
+	LockedUntil        *time.Time `gorm:"type:timestamp" json:"lockedUntil,omitempty"`                   // Login refused until this time after repeated failures
+	TicketCategories   string     `gorm:"type:varchar(255);not null;default:''" json:"ticketCategories"` // Comma-separated ticket categories auto-assigned to this user, or ALL; empty keeps them out of the rotation
 	CreatedAt          time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
 	UpdatedAt          time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 
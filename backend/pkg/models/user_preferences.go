@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// UserPreferences holds a user's personal defaults for the dashboard and reports.
+// A user without a row has no preferences and gets each endpoint's own defaults.
+type UserPreferences struct {
+	ID     uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint `gorm:"type:bigint;not null;uniqueIndex" json:"userId"`
+
+	// Date range applied by dashboard and report endpoints when the request gives none
+	DefaultDateRange string     `gorm:"type:varchar(20);not null;default:''" json:"defaultDateRange"` // today, 7d, 30d, custom or empty for none
+	CustomStartDate  *time.Time `gorm:"type:timestamp" json:"customStartDate,omitempty"`              // Used by the custom range
+	CustomEndDate    *time.Time `gorm:"type:timestamp" json:"customEndDate,omitempty"`
+
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for UserPreferences model
+func (UserPreferences) TableName() string {
+	return "user_preferences"
+}
+
+// Default date ranges
+const (
+	DateRangeNone   = ""
+	DateRangeToday  = "today"
+	DateRange7Days  = "7d"
+	DateRange30Days = "30d"
+	DateRangeCustom = "custom"
+)
+
+// DateRange returns the start and end of the preferred default range as of now. Rolling ranges
+// include today; a custom range runs to the end of its last day. ok is false when no range is set.
+func (p *UserPreferences) DateRange(now time.Time) (start, end time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch p.DefaultDateRange {
+	case DateRangeToday:
+		return today, now, true
+	case DateRange7Days:
+		return today.AddDate(0, 0, -6), now, true
+	case DateRange30Days:
+		return today.AddDate(0, 0, -29), now, true
+	case DateRangeCustom:
+		if p.CustomStartDate == nil || p.CustomEndDate == nil {
+			return time.Time{}, time.Time{}, false
+		}
+		return *p.CustomStartDate, p.CustomEndDate.Add(24*time.Hour - time.Second), true
+	}
+	return time.Time{}, time.Time{}, false
+}
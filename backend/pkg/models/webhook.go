@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+)
+
+// WebhookEndpoint is a tenant-configured URL that receives event notifications
+type WebhookEndpoint struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID  uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	Secret    string    `gorm:"type:varchar(255)" json:"-"` // HMAC signing secret
+	Events    string    `gorm:"type:text" json:"events"`    // Comma-separated event types, empty = all events
+	IsActive  bool      `gorm:"type:boolean;default:true;index" json:"isActive"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Tenant *Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:CASCADE" json:"tenant,omitempty"`
+}
+
+// TableName specifies the table name for WebhookEndpoint model
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery records a single event delivery attempt sequence to an endpoint
+type WebhookDelivery struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID      uint       `gorm:"type:bigint;not null;index" json:"tenantId"`
+	EndpointID    uint       `gorm:"type:bigint;not null;index" json:"endpointId"`
+	EventType     string     `gorm:"type:varchar(100);not null;index" json:"eventType"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`                               // JSON body sent to the endpoint
+	Status        string     `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"` // PENDING, DELIVERED, FAILED
+	Attempts      int        `gorm:"type:int;not null;default:0" json:"attempts"`
+	ResponseCode  int        `gorm:"type:int;default:0" json:"responseCode"`
+	LastError     string     `gorm:"type:text" json:"lastError"`
+	NextAttemptAt time.Time  `gorm:"type:timestamp;index" json:"nextAttemptAt"`
+	DeliveredAt   *time.Time `gorm:"type:timestamp" json:"deliveredAt"`
+	CreatedAt     time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt     time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+
+	// Relations
+	Endpoint *WebhookEndpoint         `gorm:"foreignKey:EndpointID;constraint:OnDelete:CASCADE" json:"endpoint,omitempty"`
+	History  []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID" json:"history,omitempty"`
+}
+
+// TableName specifies the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookDelivery status constants
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// WebhookDeliveryAttempt records the outcome of one POST of a delivery to its endpoint
+type WebhookDeliveryAttempt struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TenantID     uint      `gorm:"type:bigint;not null;index" json:"tenantId"`
+	DeliveryID   uint      `gorm:"type:bigint;not null;index" json:"deliveryId"`
+	Attempt      int       `gorm:"type:int;not null" json:"attempt"`       // 1 for the first try
+	ResponseCode int       `gorm:"type:int;default:0" json:"responseCode"` // 0 when the endpoint could not be reached
+	Error        string    `gorm:"type:text" json:"error,omitempty"`
+	DurationMs   int64     `gorm:"type:bigint;not null;default:0" json:"durationMs"`
+	AttemptedAt  time.Time `gorm:"type:timestamp;not null" json:"attemptedAt"`
+}
+
+// TableName specifies the table name for WebhookDeliveryAttempt model
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
@@ -151,6 +151,37 @@ func (s *AdminService) UpdateTenantStatus(id uint, status string) error {
 	return s.db.Model(&models.Tenant{}).Where("id = ?", id).Update("status", status).Error
 }
 
+// UpdateTenantTokenLifetimes sets a tenant's access and refresh token lifetimes in minutes; nil leaves a value unchanged
+// and 0 restores the default
+func (s *AdminService) UpdateTenantTokenLifetimes(id uint, accessMinutes, refreshMinutes *int) error {
+	updates := map[string]interface{}{}
+	if accessMinutes != nil && *accessMinutes >= 0 {
+		updates["access_token_ttl"] = *accessMinutes
+	}
+	if refreshMinutes != nil && *refreshMinutes >= 0 {
+		updates["refresh_token_ttl"] = *refreshMinutes
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return s.db.Model(&models.Tenant{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UnlockUser clears a user's failed login count and any active lockout
+func (s *AdminService) UnlockUser(id uint) error {
+	result := s.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_count": 0,
+		"locked_until":       nil,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
 // GetTenantCashBalances returns cash balances for a tenant
 func (s *AdminService) GetTenantCashBalances(tenantID uint) ([]models.CashBalance, error) {
 	var balances []models.CashBalance
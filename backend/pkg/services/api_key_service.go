@@ -0,0 +1,161 @@
+package services
+
+import (
+	"api/pkg/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAPIKeyInvalid is returned when an API key secret does not match any key
+	ErrAPIKeyInvalid = errors.New("invalid API key")
+	// ErrAPIKeyRevoked is returned when an API key has been revoked
+	ErrAPIKeyRevoked = errors.New("API key has been revoked")
+	// ErrAPIKeyExpired is returned when an API key is past its expiry
+	ErrAPIKeyExpired = errors.New("API key has expired")
+)
+
+// apiKeySecretPrefix starts every API key secret so leaked keys are easy to recognize
+const apiKeySecretPrefix = "dtl_"
+
+// APIKeyService issues, lists, revokes and authenticates tenant API keys
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// APIKeyRequest is the body for creating an API key
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Omit for a key that never expires
+}
+
+// CreatedAPIKey is a new key together with its secret, which is not retrievable later
+type CreatedAPIKey struct {
+	models.APIKey
+	Secret string `json:"secret"`
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateKey issues a new key for the tenant with the given scopes
+func (s *APIKeyService) CreateKey(tenantID uint, req APIKeyRequest, userID uint) (*CreatedAPIKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		switch scope {
+		case models.APIKeyScopeReportsRead, models.APIKeyScopeTransactionsRead:
+		default:
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	publicPart := make([]byte, 6)
+	secretPart := make([]byte, 24)
+	if _, err := rand.Read(publicPart); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secretPart); err != nil {
+		return nil, err
+	}
+	prefix := apiKeySecretPrefix + hex.EncodeToString(publicPart)
+	secret := prefix + "_" + hex.EncodeToString(secretPart)
+
+	key := models.APIKey{
+		TenantID:   tenantID,
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     strings.Join(scopes, ","),
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  userID,
+	}
+	if err := s.db.Create(&key).Error; err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: key, Secret: secret}, nil
+}
+
+// ListKeys returns the tenant's keys, newest first, without their secrets
+func (s *APIKeyService) ListKeys(tenantID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.db.Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeKey stops a key from authenticating; revoking twice is a no-op
+func (s *APIKeyService) RevokeKey(tenantID, keyID, userID uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := s.db.Where("id = ? AND tenant_id = ?", keyID, tenantID).First(&key).Error; err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return &key, nil
+	}
+	now := time.Now()
+	if err := s.db.Model(&key).Updates(map[string]interface{}{"revoked_at": now, "revoked_by": userID}).Error; err != nil {
+		return nil, err
+	}
+	key.RevokedAt = &now
+	key.RevokedBy = &userID
+	return &key, nil
+}
+
+// Authenticate resolves the key a secret belongs to and records that it was used
+func (s *APIKeyService) Authenticate(secret string) (*models.APIKey, error) {
+	secret = strings.TrimSpace(secret)
+	separator := strings.LastIndex(secret, "_")
+	if !strings.HasPrefix(secret, apiKeySecretPrefix) || separator <= len(apiKeySecretPrefix) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	var key models.APIKey
+	if err := s.db.Where("prefix = ?", secret[:separator]).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrAPIKeyInvalid
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	// Usage tracking must not fail the request
+	s.db.Model(&models.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", now)
+	key.LastUsedAt = &now
+	return &key, nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"api/pkg/models"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditForwarder streams new audit log entries to an external SIEM endpoint.
+// Entries are queued in the audit_log_deliveries table and POSTed in batches,
+// signed with HMAC-SHA256 so the receiver can verify their origin.
+type AuditForwarder struct {
+	db           *gorm.DB
+	Endpoint     string
+	Secret       string
+	BatchSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	HTTPClient   *http.Client
+}
+
+// AuditForwarderConfig holds the configuration for the audit forwarder
+type AuditForwarderConfig struct {
+	Endpoint     string
+	Secret       string
+	BatchSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration
+}
+
+// AuditForwardPayload is the JSON body POSTed to the SIEM endpoint
+type AuditForwardPayload struct {
+	SentAt  time.Time         `json:"sentAt"`
+	Entries []models.AuditLog `json:"entries"`
+}
+
+// AuditSignatureHeader carries the hex HMAC-SHA256 of the request body
+const AuditSignatureHeader = "X-Audit-Signature"
+
+var (
+	auditForwarder   *AuditForwarder
+	auditForwarderMu sync.RWMutex
+)
+
+// NewAuditForwarder creates a forwarder with the given configuration
+func NewAuditForwarder(db *gorm.DB, cfg AuditForwarderConfig) *AuditForwarder {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	return &AuditForwarder{
+		db:           db,
+		Endpoint:     cfg.Endpoint,
+		Secret:       cfg.Secret,
+		BatchSize:    cfg.BatchSize,
+		MaxAttempts:  cfg.MaxAttempts,
+		RetryBackoff: cfg.RetryBackoff,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// InitAuditForwarder configures the global forwarder from environment variables.
+// Forwarding stays disabled (returns nil) unless AUDIT_SIEM_ENDPOINT is set.
+func InitAuditForwarder(db *gorm.DB) *AuditForwarder {
+	endpoint := getEnv("AUDIT_SIEM_ENDPOINT", "")
+	if endpoint == "" {
+		SetAuditForwarder(nil)
+		return nil
+	}
+
+	batchSize, _ := strconv.Atoi(getEnv("AUDIT_SIEM_BATCH_SIZE", "50"))
+	maxAttempts, _ := strconv.Atoi(getEnv("AUDIT_SIEM_MAX_ATTEMPTS", "5"))
+
+	forwarder := NewAuditForwarder(db, AuditForwarderConfig{
+		Endpoint:     endpoint,
+		Secret:       getEnv("AUDIT_SIEM_SECRET", ""),
+		BatchSize:    batchSize,
+		MaxAttempts:  maxAttempts,
+		RetryBackoff: 30 * time.Second,
+	})
+	SetAuditForwarder(forwarder)
+	log.Printf("📤 Audit log forwarding enabled (endpoint: %s)", endpoint)
+	return forwarder
+}
+
+// SetAuditForwarder sets the global forwarder (nil disables forwarding)
+func SetAuditForwarder(f *AuditForwarder) {
+	auditForwarderMu.Lock()
+	defer auditForwarderMu.Unlock()
+	auditForwarder = f
+}
+
+// GetAuditForwarder returns the global forwarder, or nil if forwarding is disabled
+func GetAuditForwarder() *AuditForwarder {
+	auditForwarderMu.RLock()
+	defer auditForwarderMu.RUnlock()
+	return auditForwarder
+}
+
+// Enqueue records a pending delivery for a newly created audit log entry
+func (f *AuditForwarder) Enqueue(entry *models.AuditLog) error {
+	delivery := &models.AuditLogDelivery{
+		AuditLogID:    entry.ID,
+		TenantID:      entry.TenantID,
+		Status:        models.AuditDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	return f.db.Create(delivery).Error
+}
+
+// Flush sends one batch of due deliveries and records the outcome.
+// It returns the number of entries successfully delivered.
+func (f *AuditForwarder) Flush() (int, error) {
+	var deliveries []models.AuditLogDelivery
+	err := f.db.Preload("AuditLog").
+		Where("status = ? AND next_attempt_at <= ?", models.AuditDeliveryPending, time.Now()).
+		Order("id ASC").
+		Limit(f.BatchSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	entries := make([]models.AuditLog, 0, len(deliveries))
+	for _, d := range deliveries {
+		if d.AuditLog != nil {
+			entries = append(entries, *d.AuditLog)
+		}
+	}
+
+	now := time.Now()
+	sendErr := f.send(AuditForwardPayload{SentAt: now, Entries: entries})
+
+	ids := make([]uint, len(deliveries))
+	for i, d := range deliveries {
+		ids[i] = d.ID
+	}
+
+	if sendErr == nil {
+		err := f.db.Model(&models.AuditLogDelivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       models.AuditDeliveryDelivered,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+			"delivered_at": now,
+			"updated_at":   now,
+		}).Error
+		return len(deliveries), err
+	}
+
+	log.Printf("⚠️  Audit log forwarding failed (%d entries): %v", len(deliveries), sendErr)
+	for _, d := range deliveries {
+		attempts := d.Attempts + 1
+		updates := map[string]interface{}{
+			"attempts":        attempts,
+			"last_error":      sendErr.Error(),
+			"next_attempt_at": now.Add(f.RetryBackoff * time.Duration(attempts)),
+			"updated_at":      now,
+		}
+		if attempts >= f.MaxAttempts {
+			updates["status"] = models.AuditDeliveryFailed
+		}
+		if err := f.db.Model(&models.AuditLogDelivery{}).Where("id = ?", d.ID).Updates(updates).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, sendErr
+}
+
+// send POSTs a signed payload to the configured endpoint
+func (f *AuditForwarder) send(payload AuditForwardPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.Secret != "" {
+		req.Header.Set(AuditSignatureHeader, SignPayload(f.Secret, body))
+	}
+
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach SIEM endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("SIEM endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignPayload returns the hex-encoded HMAC-SHA256 of body using secret
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Start flushes pending deliveries on the given interval in the background
+func (f *AuditForwarder) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Audit forwarder started (every %v)", interval)
+
+		for range ticker.C {
+			for {
+				sent, err := f.Flush()
+				if err != nil || sent < f.BatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
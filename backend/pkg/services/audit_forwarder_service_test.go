@@ -0,0 +1,109 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestAuditForwarder_ForwardsAndRetries verifies new audit entries reach the sink and failures are retried
+func TestAuditForwarder_ForwardsAndRetries(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.AuditLog{}, &models.AuditLogDelivery{})
+
+	tenant := newTestTenant(t, db, "SIEM Test Exchange")
+	user := newTestUser(t, db, tenant.ID, "siem@example.com", "tenant_owner")
+
+	// Fake sink: fails the first request, accepts the rest
+	var mu sync.Mutex
+	calls := 0
+	var received []models.AuditLog
+	var signatures []string
+	var bodies [][]byte
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload AuditForwardPayload
+		json.Unmarshal(body, &payload)
+		received = append(received, payload.Entries...)
+		signatures = append(signatures, r.Header.Get(AuditSignatureHeader))
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sink.Close()
+
+	forwarder := NewAuditForwarder(db, AuditForwarderConfig{
+		Endpoint:     sink.URL,
+		Secret:       "test-secret",
+		BatchSize:    10,
+		MaxAttempts:  3,
+		RetryBackoff: 0,
+	})
+	SetAuditForwarder(forwarder)
+	defer SetAuditForwarder(nil)
+
+	auditService := NewAuditService(db)
+	req := httptest.NewRequest(http.MethodPost, "/api/transactions", nil)
+	auditService.LogAction(user.ID, &tenant.ID, models.ActionCreateTransaction, "Transaction", "tx-1", "Created new transaction", nil, nil, req)
+	auditService.LogAction(user.ID, &tenant.ID, models.ActionCreateClient, "Client", "c-1", "Created client", nil, nil, req)
+
+	var pending int64
+	db.Model(&models.AuditLogDelivery{}).Where("status = ?", models.AuditDeliveryPending).Count(&pending)
+	if pending != 2 {
+		t.Fatalf("Expected 2 pending deliveries, got %d", pending)
+	}
+
+	// First flush hits the failing sink
+	if _, err := forwarder.Flush(); err == nil {
+		t.Fatal("Expected first flush to fail")
+	}
+	var deliveries []models.AuditLogDelivery
+	db.Find(&deliveries)
+	for _, d := range deliveries {
+		if d.Status != models.AuditDeliveryPending || d.Attempts != 1 || d.LastError == "" {
+			t.Errorf("Expected failed attempt to be recorded, got status=%s attempts=%d", d.Status, d.Attempts)
+		}
+	}
+
+	// Retry succeeds
+	sent, err := forwarder.Flush()
+	if err != nil {
+		t.Fatalf("Expected retry to succeed: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("Expected 2 entries delivered, got %d", sent)
+	}
+
+	db.Find(&deliveries)
+	for _, d := range deliveries {
+		if d.Status != models.AuditDeliveryDelivered || d.Attempts != 2 || d.DeliveredAt == nil {
+			t.Errorf("Expected delivery to be marked delivered, got status=%s attempts=%d", d.Status, d.Attempts)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("Expected sink to receive 2 entries, got %d", len(received))
+	}
+	if received[0].EntityID != "tx-1" || received[1].EntityID != "c-1" {
+		t.Errorf("Unexpected entries forwarded: %s, %s", received[0].EntityID, received[1].EntityID)
+	}
+	if signatures[0] != SignPayload("test-secret", bodies[0]) {
+		t.Error("Expected payload to carry a valid HMAC signature")
+	}
+
+	// Nothing left to send
+	if sent, _ := forwarder.Flush(); sent != 0 {
+		t.Errorf("Expected no further deliveries, got %d", sent)
+	}
+}
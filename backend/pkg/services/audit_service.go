@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"reflect"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -27,6 +28,7 @@ const (
 	AuditActionSettlement    = "SETTLEMENT"
 	AuditActionExport        = "EXPORT"
 	AuditActionImport        = "IMPORT"
+	AuditActionView          = "VIEW"
 )
 
 // AuditEntityType constants for consistent entity naming
@@ -43,8 +45,13 @@ const (
 	AuditEntitySettlement   = "Settlement"
 	AuditEntityExchangeRate = "ExchangeRate"
 	AuditEntityCashBalance  = "CashBalance"
+
+	AuditEntityCustomerCompliance = "CustomerCompliance"
 )
 
+// readAuditableEntities are the entity types a tenant can opt in to read auditing for
+var readAuditableEntities = []string{AuditEntityCustomerCompliance, AuditEntityClient, AuditEntityCustomer}
+
 // AuditService handles audit logging
 type AuditService struct {
 	DB *gorm.DB
@@ -95,9 +102,12 @@ func (as *AuditService) LogAction(
 		newJSON = &str
 	}
 
-	// Get IP and User-Agent from request
-	ipAddress := getAuditClientIP(r)
-	userAgent := r.Header.Get("User-Agent")
+	// Get IP and User-Agent from request; scheduled system actions have none
+	var ipAddress, userAgent string
+	if r != nil {
+		ipAddress = getAuditClientIP(r)
+		userAgent = r.Header.Get("User-Agent")
+	}
 
 	auditLog := &models.AuditLog{
 		UserID:      userID,
@@ -117,6 +127,13 @@ func (as *AuditService) LogAction(
 		return err
 	}
 
+	// Queue for SIEM forwarding if configured
+	if forwarder := GetAuditForwarder(); forwarder != nil {
+		if err := forwarder.Enqueue(auditLog); err != nil {
+			log.Printf("⚠️  Failed to queue audit log for forwarding: %v", err)
+		}
+	}
+
 	log.Printf("📝 Audit: User %d - %s %s (ID: %s)", userID, action, entityType, entityID)
 	return nil
 }
@@ -166,6 +183,45 @@ func (as *AuditService) LogDelete(userID uint, tenantID *uint, entityType string
 	return as.LogAction(userID, tenantID, AuditActionDelete, entityType, entityID, "Deleted "+entityType, snapshot, nil, r)
 }
 
+// LogRead records that a user viewed an entity, if the tenant has read auditing enabled for its type.
+// Read auditing is off by default, so the common case costs only a settings lookup.
+func (as *AuditService) LogRead(tenantID, userID uint, entityType, entityID string, r *http.Request) error {
+	settings, err := NewTenantSettingsService(as.DB).GetSettings(tenantID)
+	if err != nil {
+		return err
+	}
+	if !settings.AuditsReadsOf(entityType) {
+		return nil
+	}
+	return as.LogAction(userID, &tenantID, AuditActionView, entityType, entityID, "Viewed "+entityType, nil, nil, r)
+}
+
+// normalizeReadAuditEntities cleans up a comma-separated list of entity types, reporting false if one cannot be read-audited
+func normalizeReadAuditEntities(value string) (string, bool) {
+	var entities []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		known := false
+		for _, entity := range readAuditableEntities {
+			if strings.EqualFold(part, entity) {
+				known = true
+				if !seen[entity] {
+					seen[entity] = true
+					entities = append(entities, entity)
+				}
+			}
+		}
+		if !known {
+			return "", false
+		}
+	}
+	return strings.Join(entities, ","), true
+}
+
 // CreateSnapshot creates a timestamped snapshot of an entity
 func (as *AuditService) CreateSnapshot(entity interface{}) *AuditSnapshot {
 	if entity == nil {
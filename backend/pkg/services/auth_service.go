@@ -14,6 +14,26 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected: all sessions have been signed out")
+	// ErrRefreshTokenExpired is returned when the refresh token is past its expiry
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+)
+
+// accessTokenIssuer marks access tokens, distinguishing them from refresh and 2FA challenge tokens
+const accessTokenIssuer = "digital-transaction-ledger"
+
+// Token lifetimes used when a tenant has not configured its own, and the range a configured value is clamped to
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	minAccessTokenTTL      = 1 * time.Minute
+	maxAccessTokenTTL      = 24 * time.Hour
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+	minRefreshTokenTTL     = 1 * time.Hour
+	maxRefreshTokenTTL     = 90 * 24 * time.Hour
+)
+
 // AuthService handles authentication operations
 type AuthService struct {
 	DB           *gorm.DB
@@ -280,11 +300,14 @@ func (as *AuthService) ResendVerificationCode(email string) error {
 	return nil
 }
 
-// LoginResponse contains both access and refresh tokens
+// LoginResponse contains both access and refresh tokens.
+// When the user has 2FA enabled, Login instead returns only a challenge token to pass to Verify2FA.
 type LoginResponse struct {
-	AccessToken  string       `json:"accessToken"`
-	RefreshToken string       `json:"refreshToken"`
-	User         *models.User `json:"user"`
+	AccessToken       string       `json:"accessToken"`
+	RefreshToken      string       `json:"refreshToken"`
+	User              *models.User `json:"user"`
+	TwoFactorRequired bool         `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string       `json:"challengeToken,omitempty"`
 }
 
 // Login authenticates a user and returns JWT tokens
@@ -301,45 +324,12 @@ func (as *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
 			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					// Try finding as branch login
-					var branch models.Branch
-					err = as.DB.Preload("Tenant").Where("username = ? AND status = ?", req.Email, models.BranchStatusActive).First(&branch).Error
-					if err == nil {
-						// Branch found, verify password
-						if branch.PasswordHash == nil || *branch.PasswordHash == "" {
-							return nil, errors.New("branch credentials not set")
-						}
-						if err := bcrypt.CompareHashAndPassword([]byte(*branch.PasswordHash), []byte(req.Password)); err != nil {
-							return nil, errors.New("invalid username or password")
-						}
-
-						// Find or create a user for this branch
-						var branchUser models.User
-						branchEmail := *branch.Username + "@branch.local"
-						err = as.DB.Preload("Tenant").Preload("PrimaryBranch").Where("email = ?", branchEmail).First(&branchUser).Error
-
-						if errors.Is(err, gorm.ErrRecordNotFound) {
-							// Create a user for this branch
-							branchUser = models.User{
-								Username:        branch.Username,
-								Email:           branchEmail,
-								PasswordHash:    *branch.PasswordHash, // Use same password hash
-								EmailVerified:   true,
-								Role:            models.RoleTenantUser,
-								TenantID:        &branch.TenantID,
-								PrimaryBranchID: &branch.ID,
-								Status:          models.StatusActive,
-							}
-							if err := as.DB.Create(&branchUser).Error; err != nil {
-								return nil, fmt.Errorf("failed to create branch user: %w", err)
-							}
-							// Reload with associations
-							as.DB.Preload("Tenant").Preload("PrimaryBranch").First(&branchUser, branchUser.ID)
-						} else if err != nil {
-							return nil, fmt.Errorf("database error: %w", err)
-						}
-
-						// Generate tokens for branch login
-						return as.generateLoginResponse(&branchUser)
+					branchUser, found, err := as.authenticateBranch(req.Email, req.Password)
+					if err != nil {
+						return nil, err
+					}
+					if found {
+						return as.completeLogin(branchUser)
 					}
 					return nil, errors.New("invalid email or password")
 				}
@@ -355,8 +345,17 @@ func (as *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
 		return nil, errors.New("email not verified. Please verify your email first")
 	}
 
+	// A locked account is refused even with the correct password
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		if lockErr := as.recordFailedLogin(&user, now); lockErr != nil {
+			return nil, lockErr
+		}
 		return nil, errors.New("invalid email or password")
 	}
 
@@ -379,8 +378,182 @@ func (as *AuthService) Login(req LoginRequest) (*LoginResponse, error) {
 		}
 	}
 
-	// Generate tokens
-	return as.generateLoginResponse(&user)
+	return as.completeLogin(&user)
+}
+
+// completeLogin finishes a login whose password has been checked, for user and branch credentials alike.
+// Password alone is not enough when 2FA is on: the user gets a challenge for Verify2FA, and failed
+// attempts are only cleared once the second factor is verified.
+func (as *AuthService) completeLogin(user *models.User) (*LoginResponse, error) {
+	if user.TwoFactorEnabled {
+		challenge, err := as.generateTwoFactorChallenge(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate two-factor challenge: %w", err)
+		}
+		return &LoginResponse{
+			User:              user,
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	if err := as.clearFailedLogins(user); err != nil {
+		return nil, err
+	}
+	return as.generateLoginResponse(user)
+}
+
+// Account lockout: every maxFailedLogins consecutive bad passwords lock the account,
+// starting at baseLockoutDuration and doubling with each further lockout up to maxLockoutDuration
+const (
+	maxFailedLogins     = 5
+	baseLockoutDuration = 15 * time.Minute
+	maxLockoutDuration  = 24 * time.Hour
+)
+
+// AccountLockedError is returned when a login is refused because of repeated failed attempts
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account temporarily locked until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// lockoutDuration returns how long the account is locked after the given number of consecutive failures
+func lockoutDuration(failures int) time.Duration {
+	duration := baseLockoutDuration
+	for level := failures / maxFailedLogins; level > 1 && duration < maxLockoutDuration; level-- {
+		duration *= 2
+	}
+	if duration > maxLockoutDuration {
+		duration = maxLockoutDuration
+	}
+	return duration
+}
+
+// recordFailedLogin counts a bad password and locks the account when the threshold is reached.
+// It returns an AccountLockedError if this attempt triggered a lockout. SuperAdmins are never locked out.
+func (as *AuthService) recordFailedLogin(user *models.User, now time.Time) error {
+	if err := as.DB.Model(user).UpdateColumn("failed_login_count", gorm.Expr("failed_login_count + 1")).Error; err != nil {
+		log.Printf("Failed to record failed login for user %d: %v", user.ID, err)
+		return nil
+	}
+	if err := as.DB.Model(user).Select("failed_login_count").First(user).Error; err != nil {
+		log.Printf("Failed to reload failed login count for user %d: %v", user.ID, err)
+		return nil
+	}
+
+	if user.Role == models.RoleSuperAdmin || user.FailedLoginCount%maxFailedLogins != 0 {
+		return nil
+	}
+
+	until := now.Add(lockoutDuration(user.FailedLoginCount))
+	if err := as.DB.Model(user).UpdateColumn("locked_until", until).Error; err != nil {
+		log.Printf("Failed to lock user %d: %v", user.ID, err)
+		return nil
+	}
+	user.LockedUntil = &until
+	log.Printf("🔒 User %d locked until %s after %d failed logins", user.ID, until.Format(time.RFC3339), user.FailedLoginCount)
+	return &AccountLockedError{Until: until}
+}
+
+// clearFailedLogins resets the failed attempt count and any lockout after a successful login
+func (as *AuthService) clearFailedLogins(user *models.User) error {
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	if err := as.DB.Model(user).Updates(map[string]interface{}{
+		"failed_login_count": 0,
+		"locked_until":       nil,
+	}).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+	return nil
+}
+
+// authenticateBranch resolves a branch login to the branch's linked user.
+// found is false when no active branch uses the username, so the caller can report a generic failure.
+// Branch usernames are only unique within a tenant, so every matching branch's password is checked.
+// Failed attempts count toward the linked user's lockout, the same as a bad user password.
+func (as *AuthService) authenticateBranch(username, password string) (*models.User, bool, error) {
+	var branches []models.Branch
+	if err := as.DB.Where("username = ? AND status = ?", username, models.BranchStatusActive).Find(&branches).Error; err != nil {
+		return nil, false, fmt.Errorf("database error: %w", err)
+	}
+	if len(branches) == 0 {
+		return nil, false, nil
+	}
+
+	var matched []models.Branch
+	for _, branch := range branches {
+		if branch.PasswordHash == nil || *branch.PasswordHash == "" {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(*branch.PasswordHash), []byte(password)) == nil {
+			matched = append(matched, branch)
+		}
+	}
+	now := time.Now()
+	switch len(matched) {
+	case 0:
+		for _, branch := range branches {
+			if err := as.recordFailedBranchLogin(branch, now); err != nil {
+				return nil, true, err
+			}
+		}
+		return nil, true, errors.New("invalid username or password")
+	case 1:
+	default:
+		log.Printf("⚠️ Branch login for %q matched %d branches in different tenants; refusing to guess", username, len(matched))
+		return nil, true, errors.New("branch login is ambiguous; please sign in with your user account")
+	}
+
+	branch := matched[0]
+	if branch.BranchUserID == nil {
+		log.Printf("❌ Branch %d has credentials but no linked user", branch.ID)
+		return nil, true, fmt.Errorf("branch %q has no linked user; reset its credentials to create one", branch.Name)
+	}
+
+	var branchUser models.User
+	if err := as.DB.Preload("Tenant").Preload("PrimaryBranch").
+		Where("id = ? AND tenant_id = ?", *branch.BranchUserID, branch.TenantID).
+		First(&branchUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("❌ Branch %d is linked to missing user %d", branch.ID, *branch.BranchUserID)
+			return nil, true, fmt.Errorf("branch %q has no linked user; reset its credentials to create one", branch.Name)
+		}
+		return nil, true, fmt.Errorf("database error: %w", err)
+	}
+	if branchUser.LockedUntil != nil && now.Before(*branchUser.LockedUntil) {
+		return nil, true, &AccountLockedError{Until: *branchUser.LockedUntil}
+	}
+	if branchUser.Status == models.StatusSuspended {
+		return nil, true, errors.New("account is suspended. Please contact support")
+	}
+
+	return &branchUser, true, nil
+}
+
+// recordFailedBranchLogin counts a bad password against the branch's linked user. A user who is already
+// locked is refused without extending the count, as on the user login path.
+func (as *AuthService) recordFailedBranchLogin(branch models.Branch, now time.Time) error {
+	if branch.PasswordHash == nil || *branch.PasswordHash == "" || branch.BranchUserID == nil {
+		return nil
+	}
+	var branchUser models.User
+	if err := as.DB.Where("id = ? AND tenant_id = ?", *branch.BranchUserID, branch.TenantID).First(&branchUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+	if branchUser.LockedUntil != nil && now.Before(*branchUser.LockedUntil) {
+		return &AccountLockedError{Until: *branchUser.LockedUntil}
+	}
+	return as.recordFailedLogin(&branchUser, now)
 }
 
 // generateLoginResponse creates access and refresh tokens
@@ -411,17 +584,53 @@ func (as *AuthService) GenerateJWT(user *models.User) (string, error) {
 	return as.GenerateAccessToken(user)
 }
 
-// GenerateAccessToken generates a short-lived access token (15 minutes)
+// clampTokenTTL converts a configured lifetime in minutes, using the default when unset
+// and clamping it into [min, max] otherwise
+func clampTokenTTL(minutes int, def, min, max time.Duration) time.Duration {
+	if minutes <= 0 {
+		return def
+	}
+	// Compare in minutes first so absurd values cannot overflow the Duration
+	if minutes > int(max/time.Minute) {
+		return max
+	}
+	if ttl := time.Duration(minutes) * time.Minute; ttl > min {
+		return ttl
+	}
+	return min
+}
+
+// tokenLifetimes returns the access and refresh token lifetimes configured for the user's tenant
+func (as *AuthService) tokenLifetimes(user *models.User) (time.Duration, time.Duration) {
+	if user.TenantID == nil {
+		return defaultAccessTokenTTL, defaultRefreshTokenTTL
+	}
+
+	tenant := user.Tenant
+	if tenant == nil || tenant.ID != *user.TenantID {
+		tenant = &models.Tenant{}
+		if err := as.DB.Select("id", "access_token_ttl", "refresh_token_ttl").First(tenant, *user.TenantID).Error; err != nil {
+			return defaultAccessTokenTTL, defaultRefreshTokenTTL
+		}
+	}
+
+	return clampTokenTTL(tenant.AccessTokenTTL, defaultAccessTokenTTL, minAccessTokenTTL, maxAccessTokenTTL),
+		clampTokenTTL(tenant.RefreshTokenTTL, defaultRefreshTokenTTL, minRefreshTokenTTL, maxRefreshTokenTTL)
+}
+
+// GenerateAccessToken generates a short-lived access token (15 minutes unless the tenant configures otherwise)
 func (as *AuthService) GenerateAccessToken(user *models.User) (string, error) {
+	accessTTL, _ := as.tokenLifetimes(user)
+	now := time.Now()
 	claims := JWTClaims{
 		UserID:   user.ID,
 		Email:    user.Email,
 		Role:     user.Role,
 		TenantID: user.TenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // 15 minutes
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "digital-transaction-ledger",
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    accessTokenIssuer,
 		},
 	}
 
@@ -434,18 +643,26 @@ func (as *AuthService) GenerateAccessToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken generates a long-lived refresh token (7 days)
+// GenerateRefreshToken generates a long-lived refresh token (7 days unless the tenant configures otherwise)
 func (as *AuthService) GenerateRefreshToken(user *models.User) (string, error) {
+	return as.issueRefreshToken(as.DB, user, nil)
+}
+
+// issueRefreshToken signs and stores a refresh token, recording the token it was rotated from
+func (as *AuthService) issueRefreshToken(db *gorm.DB, user *models.User, parentID *uint) (string, error) {
 	// Generate secure random ID for JWT
 	randomID, err := cryptorand.Int(cryptorand.Reader, big.NewInt(1<<62))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate random ID: %w", err)
 	}
 
+	_, refreshTTL := as.tokenLifetimes(user)
+	now := time.Now()
+	expiresAt := now.Add(refreshTTL)
 	claims := jwt.RegisteredClaims{
 		Subject:   fmt.Sprintf("%d", user.ID),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
 		Issuer:    "digital-transaction-ledger-refresh",
 		ID:        fmt.Sprintf("%d", randomID),
 	}
@@ -460,18 +677,22 @@ func (as *AuthService) GenerateRefreshToken(user *models.User) (string, error) {
 	refreshToken := models.RefreshToken{
 		UserID:    user.ID,
 		Token:     tokenString,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		ExpiresAt: expiresAt,
 		IsRevoked: false,
+		ParentID:  parentID,
 	}
 
-	if err := as.DB.Create(&refreshToken).Error; err != nil {
+	if err := db.Create(&refreshToken).Error; err != nil {
 		return "", fmt.Errorf("failed to store refresh token: %w", err)
 	}
 
 	return tokenString, nil
 }
 
-// RefreshAccessToken validates a refresh token and generates a new access token
+// RefreshAccessToken validates a refresh token, rotates it and generates a new access token.
+// The presented token is revoked and replaced; presenting a rotated token again is treated as
+// theft and revokes every token the user holds. Tokens revoked any other way, e.g. by logout,
+// are simply refused.
 func (as *AuthService) RefreshAccessToken(refreshTokenString string) (*LoginResponse, error) {
 	// Parse refresh token
 	token, err := jwt.ParseWithClaims(refreshTokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
@@ -482,6 +703,9 @@ func (as *AuthService) RefreshAccessToken(refreshTokenString string) (*LoginResp
 	})
 
 	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrRefreshTokenExpired
+		}
 		return nil, errors.New("invalid refresh token")
 	}
 
@@ -490,15 +714,19 @@ func (as *AuthService) RefreshAccessToken(refreshTokenString string) (*LoginResp
 		return nil, errors.New("invalid refresh token claims")
 	}
 
-	// Check if token exists and is not revoked in database
+	// Check if token exists in database
 	var refreshToken models.RefreshToken
-	if err := as.DB.Where("token = ? AND is_revoked = ?", refreshTokenString, false).First(&refreshToken).Error; err != nil {
+	if err := as.DB.Where("token = ?", refreshTokenString).First(&refreshToken).Error; err != nil {
 		return nil, errors.New("refresh token not found or revoked")
 	}
 
+	if refreshToken.IsRevoked {
+		return nil, as.rejectRevokedRefreshToken(&refreshToken)
+	}
+
 	// Check if token is expired
 	if time.Now().After(refreshToken.ExpiresAt) {
-		return nil, errors.New("refresh token expired")
+		return nil, ErrRefreshTokenExpired
 	}
 
 	// Get user
@@ -512,6 +740,36 @@ func (as *AuthService) RefreshAccessToken(refreshTokenString string) (*LoginResp
 		return nil, errors.New("account is suspended")
 	}
 
+	// Rotate: revoke the presented token and issue its replacement atomically
+	var newRefreshToken string
+	reused := false
+	err = as.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND is_revoked = ?", refreshToken.ID, false).
+			Update("is_revoked", true)
+		if result.Error != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// Another request rotated this token first
+			reused = true
+			return nil
+		}
+
+		issued, err := as.issueRefreshToken(tx, &user, &refreshToken.ID)
+		if err != nil {
+			return err
+		}
+		newRefreshToken = issued
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, as.rejectRevokedRefreshToken(&refreshToken)
+	}
+
 	// Generate new access token
 	accessToken, err := as.GenerateAccessToken(&user)
 	if err != nil {
@@ -522,11 +780,33 @@ func (as *AuthService) RefreshAccessToken(refreshTokenString string) (*LoginResp
 
 	return &LoginResponse{
 		AccessToken:  accessToken,
-		RefreshToken: refreshTokenString, // Return same refresh token
+		RefreshToken: newRefreshToken,
 		User:         &user,
 	}, nil
 }
 
+// rejectRevokedRefreshToken refuses a revoked refresh token. A token that already has a replacement
+// coming back means it was copied before being rotated, so every token the user holds is revoked.
+func (as *AuthService) rejectRevokedRefreshToken(refreshToken *models.RefreshToken) error {
+	var replacements int64
+	if err := as.DB.Model(&models.RefreshToken{}).Where("parent_id = ?", refreshToken.ID).Count(&replacements).Error; err != nil {
+		return fmt.Errorf("failed to check refresh token rotation: %w", err)
+	}
+	if replacements == 0 {
+		return errors.New("refresh token not found or revoked")
+	}
+	return as.handleRefreshTokenReuse(refreshToken)
+}
+
+// handleRefreshTokenReuse revokes all of a user's tokens after a rotated refresh token is replayed
+func (as *AuthService) handleRefreshTokenReuse(refreshToken *models.RefreshToken) error {
+	log.Printf("⚠️ Revoked refresh token %d presented again for user ID %d, revoking all tokens", refreshToken.ID, refreshToken.UserID)
+	if err := as.RevokeAllUserTokens(refreshToken.UserID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
 // RevokeRefreshToken revokes a refresh token
 func (as *AuthService) RevokeRefreshToken(tokenString string) error {
 	result := as.DB.Model(&models.RefreshToken{}).
@@ -571,7 +851,8 @@ func (as *AuthService) ValidateJWT(tokenString string) (*JWTClaims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+	// Refresh and 2FA challenge tokens share the secret but must not authenticate requests
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && claims.Issuer == accessTokenIssuer {
 		return claims, nil
 	}
 
@@ -590,16 +871,9 @@ func (as *AuthService) ChangePassword(userID uint, currentPassword, newPassword
 		return errors.New("current password is incorrect")
 	}
 
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return errors.New("failed to hash new password")
-	}
-
-	// Update password
-	user.PasswordHash = string(hashedPassword)
-	if err := as.DB.Save(&user).Error; err != nil {
-		return errors.New("failed to update password")
+	// Update password, refusing recently used ones
+	if err := as.setPassword(&user, newPassword); err != nil {
+		return err
 	}
 
 	log.Printf("✅ Password changed for user ID: %d", userID)
@@ -681,16 +955,9 @@ func (as *AuthService) ResetPasswordWithCode(emailOrPhone, code, newPassword str
 		return errors.New("invalid or expired reset code")
 	}
 
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return errors.New("failed to hash new password")
-	}
-
-	// Update password
-	user.PasswordHash = string(hashedPassword)
-	if err := as.DB.Save(&user).Error; err != nil {
-		return errors.New("failed to update password")
+	// Update password, refusing recently used ones
+	if err := as.setPassword(&user, newPassword); err != nil {
+		return err
 	}
 
 	// Mark reset code as used
@@ -0,0 +1,714 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func setupAuthTestService(t *testing.T) (*AuthService, *models.User) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.User{}, &models.RefreshToken{}, &models.TwoFactorRecoveryCode{},
+		&models.TenantSettings{}, &models.PasswordHistory{}, &models.PasswordResetCode{})
+
+	user := &models.User{
+		Email:        "rotation@example.com",
+		PasswordHash: "hash",
+		Role:         models.RoleTenantUser,
+		Status:       models.StatusActive,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	return &AuthService{DB: db, JWTSecret: "test-secret-that-is-at-least-32-characters"}, user
+}
+
+// TestRefreshAccessToken_RotatesToken verifies each refresh revokes the presented token and links its replacement
+func TestRefreshAccessToken_RotatesToken(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	original, err := as.GenerateRefreshToken(user)
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+
+	resp, err := as.RefreshAccessToken(original)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if resp.RefreshToken == "" || resp.RefreshToken == original {
+		t.Fatalf("Expected a new refresh token, got %q", resp.RefreshToken)
+	}
+	if resp.AccessToken == "" {
+		t.Error("Expected an access token")
+	}
+
+	var old, rotated models.RefreshToken
+	as.DB.Where("token = ?", original).First(&old)
+	as.DB.Where("token = ?", resp.RefreshToken).First(&rotated)
+	if !old.IsRevoked {
+		t.Error("Expected presented token to be revoked")
+	}
+	if rotated.IsRevoked {
+		t.Error("Expected rotated token to be active")
+	}
+	if rotated.ParentID == nil || *rotated.ParentID != old.ID {
+		t.Errorf("Expected rotated token parent %d, got %v", old.ID, rotated.ParentID)
+	}
+
+	// The new token keeps working
+	if _, err := as.RefreshAccessToken(resp.RefreshToken); err != nil {
+		t.Errorf("Expected rotated token to refresh, got %v", err)
+	}
+}
+
+// TestRefreshAccessToken_ReplayRevokesAllTokens verifies presenting a rotated token signs the user out everywhere
+func TestRefreshAccessToken_ReplayRevokesAllTokens(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	original, _ := as.GenerateRefreshToken(user)
+	otherSession, _ := as.GenerateRefreshToken(user)
+
+	resp, err := as.RefreshAccessToken(original)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	if _, err := as.RefreshAccessToken(original); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused on replay, got %v", err)
+	}
+
+	var active int64
+	as.DB.Model(&models.RefreshToken{}).Where("user_id = ? AND is_revoked = ?", user.ID, false).Count(&active)
+	if active != 0 {
+		t.Errorf("Expected all tokens revoked after replay, %d still active", active)
+	}
+
+	for _, token := range []string{resp.RefreshToken, otherSession} {
+		if _, err := as.RefreshAccessToken(token); err == nil {
+			t.Error("Expected revoked token to be rejected")
+		}
+	}
+}
+
+// TestRefreshAccessToken_LoggedOutTokenKeepsOtherSessions verifies a token revoked by logout is refused
+// without being mistaken for a replay
+func TestRefreshAccessToken_LoggedOutTokenKeepsOtherSessions(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	loggedOut, _ := as.GenerateRefreshToken(user)
+	otherSession, _ := as.GenerateRefreshToken(user)
+
+	if err := as.RevokeRefreshToken(loggedOut); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	_, err := as.RefreshAccessToken(loggedOut)
+	if err == nil {
+		t.Fatal("Expected a logged-out token to be refused")
+	}
+	if errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected a logged-out token not to count as reuse, got %v", err)
+	}
+
+	if _, err := as.RefreshAccessToken(otherSession); err != nil {
+		t.Errorf("Expected the other session to keep working, got %v", err)
+	}
+}
+
+// TestRefreshAccessToken_RejectsExpiredToken verifies an expired token is refused without rotating
+func TestRefreshAccessToken_RejectsExpiredToken(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	token, _ := as.GenerateRefreshToken(user)
+	as.DB.Model(&models.RefreshToken{}).Where("token = ?", token).Update("expires_at", time.Now().Add(-time.Hour))
+
+	if _, err := as.RefreshAccessToken(token); !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("Expected ErrRefreshTokenExpired, got %v", err)
+	}
+
+	var count int64
+	as.DB.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected no replacement token to be issued, found %d tokens", count)
+	}
+}
+
+// tokenExpiry returns how long after issue the token expires
+func tokenExpiry(t *testing.T, as *AuthService, tokenString string) time.Duration {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(as.JWTSecret), nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	claims := token.Claims.(*jwt.RegisteredClaims)
+	return claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+}
+
+// TestTokenLifetimes_UseTenantSettings verifies a tenant's configured lifetimes are embedded in issued tokens
+func TestTokenLifetimes_UseTenantSettings(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "High Security Bureau", OwnerID: user.ID, AccessTokenTTL: 5, RefreshTokenTTL: 12 * 60}
+	as.DB.Create(tenant)
+	user.TenantID = &tenant.ID
+	as.DB.Save(user)
+
+	accessToken, err := as.GenerateAccessToken(user)
+	if err != nil {
+		t.Fatalf("Failed to generate access token: %v", err)
+	}
+	if got := tokenExpiry(t, as, accessToken); got != 5*time.Minute {
+		t.Errorf("Expected 5 minute access token, got %v", got)
+	}
+
+	refreshToken, err := as.GenerateRefreshToken(user)
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+	if got := tokenExpiry(t, as, refreshToken); got != 12*time.Hour {
+		t.Errorf("Expected 12 hour refresh token, got %v", got)
+	}
+
+	var stored models.RefreshToken
+	as.DB.Where("token = ?", refreshToken).First(&stored)
+	if remaining := time.Until(stored.ExpiresAt); remaining > 12*time.Hour || remaining < 11*time.Hour {
+		t.Errorf("Expected stored refresh expiry about 12 hours out, got %v", remaining)
+	}
+}
+
+// TestTokenLifetimes_ClampAbsurdValues verifies unset and out-of-range lifetimes fall back to sane values
+func TestTokenLifetimes_ClampAbsurdValues(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "Absurd Bureau", OwnerID: user.ID}
+	as.DB.Create(tenant)
+	user.TenantID = &tenant.ID
+	as.DB.Save(user)
+
+	hundredYears := 100 * 365 * 24 * 60
+	tests := []struct {
+		name            string
+		access, refresh int
+		wantAccess      time.Duration
+		wantRefresh     time.Duration
+	}{
+		{"zero uses defaults", 0, 0, defaultAccessTokenTTL, defaultRefreshTokenTTL},
+		{"100 years clamps to max", hundredYears, hundredYears, maxAccessTokenTTL, maxRefreshTokenTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as.DB.Model(tenant).Updates(map[string]interface{}{"access_token_ttl": tt.access, "refresh_token_ttl": tt.refresh})
+
+			accessToken, _ := as.GenerateAccessToken(user)
+			if got := tokenExpiry(t, as, accessToken); got != tt.wantAccess {
+				t.Errorf("Expected access lifetime %v, got %v", tt.wantAccess, got)
+			}
+			refreshToken, _ := as.GenerateRefreshToken(user)
+			if got := tokenExpiry(t, as, refreshToken); got != tt.wantRefresh {
+				t.Errorf("Expected refresh lifetime %v, got %v", tt.wantRefresh, got)
+			}
+		})
+	}
+}
+
+// enableTwoFactorForTest enrolls and activates 2FA for the user, returning the secret and recovery codes
+func enableTwoFactorForTest(t *testing.T, as *AuthService, user *models.User) (string, []string) {
+	setup, err := as.EnableTwoFactor(user.ID)
+	if err != nil {
+		t.Fatalf("EnableTwoFactor failed: %v", err)
+	}
+	if setup.ProvisioningURI == "" || setup.Secret == "" {
+		t.Fatalf("Expected secret and provisioning URI, got %+v", setup)
+	}
+
+	code, _ := totpCode(setup.Secret, totpCounter(time.Now()))
+	recoveryCodes, err := as.VerifyAndActivateTwoFactor(user.ID, code)
+	if err != nil {
+		t.Fatalf("VerifyAndActivateTwoFactor failed: %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("Expected %d recovery codes, got %d", recoveryCodeCount, len(recoveryCodes))
+	}
+	return setup.Secret, recoveryCodes
+}
+
+// setPasswordForTest gives the user a verified email and a known password so they can log in
+func setPasswordForTest(t *testing.T, as *AuthService, user *models.User, password string) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	as.DB.Model(user).Updates(map[string]interface{}{"password_hash": string(hash), "email_verified": true})
+}
+
+// TestTwoFactor_EnableLoginVerify verifies the full enable -> login -> verify flow
+func TestTwoFactor_EnableLoginVerify(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "correct-horse")
+	secret, recoveryCodes := enableTwoFactorForTest(t, as, user)
+
+	// The password step only returns a challenge
+	resp, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if !resp.TwoFactorRequired || resp.ChallengeToken == "" {
+		t.Fatalf("Expected a 2FA challenge, got %+v", resp)
+	}
+	if resp.AccessToken != "" || resp.RefreshToken != "" {
+		t.Fatal("Expected no tokens before the second factor")
+	}
+	if _, err := as.ValidateJWT(resp.ChallengeToken); err == nil {
+		t.Error("Expected challenge token to be rejected as an access token")
+	}
+
+	// The code used for activation cannot be replayed; the next step's code is accepted within the window
+	activationCode, _ := totpCode(secret, totpCounter(time.Now()))
+	if _, err := as.Verify2FA(resp.ChallengeToken, activationCode); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected replayed code to be rejected, got %v", err)
+	}
+	nextCode, _ := totpCode(secret, totpCounter(time.Now())+1)
+	verified, err := as.Verify2FA(resp.ChallengeToken, nextCode)
+	if err != nil {
+		t.Fatalf("Verify2FA failed: %v", err)
+	}
+	if verified.AccessToken == "" || verified.RefreshToken == "" {
+		t.Fatal("Expected access and refresh tokens after 2FA")
+	}
+	if _, err := as.ValidateJWT(verified.AccessToken); err != nil {
+		t.Errorf("Expected a valid access token, got %v", err)
+	}
+
+	// Recovery codes work once
+	if _, err := as.Verify2FA(resp.ChallengeToken, recoveryCodes[0]); err != nil {
+		t.Errorf("Expected recovery code to be accepted, got %v", err)
+	}
+	if _, err := as.Verify2FA(resp.ChallengeToken, recoveryCodes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected used recovery code to be rejected, got %v", err)
+	}
+}
+
+// TestTwoFactor_RejectsInvalidCode verifies a wrong code neither activates 2FA nor completes a login
+func TestTwoFactor_RejectsInvalidCode(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "correct-horse")
+
+	setup, err := as.EnableTwoFactor(user.ID)
+	if err != nil {
+		t.Fatalf("EnableTwoFactor failed: %v", err)
+	}
+
+	// Pick a code that is not valid anywhere in the accepted window
+	valid := map[string]bool{}
+	current := totpCounter(time.Now())
+	for step := current - totpSkew - 1; step <= current+totpSkew+1; step++ {
+		code, _ := totpCode(setup.Secret, step)
+		valid[code] = true
+	}
+	wrong := "123456"
+	for valid[wrong] {
+		wrong = wrong[1:] + wrong[:1]
+	}
+
+	if _, err := as.VerifyAndActivateTwoFactor(user.ID, wrong); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("Expected ErrInvalidTwoFactorCode on activation, got %v", err)
+	}
+	var stored models.User
+	as.DB.First(&stored, user.ID)
+	if stored.TwoFactorEnabled {
+		t.Fatal("Expected 2FA to stay disabled after an invalid code")
+	}
+
+	code, _ := totpCode(setup.Secret, current)
+	if _, err := as.VerifyAndActivateTwoFactor(user.ID, code); err != nil {
+		t.Fatalf("Activation failed: %v", err)
+	}
+
+	resp, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := as.Verify2FA(resp.ChallengeToken, wrong); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("Expected ErrInvalidTwoFactorCode on login, got %v", err)
+	}
+	if _, err := as.Verify2FA("not-a-token", code); !errors.Is(err, ErrInvalidChallenge) {
+		t.Errorf("Expected ErrInvalidChallenge for a bad challenge token, got %v", err)
+	}
+}
+
+// wrongTOTPCode returns a six-digit code that is not valid for the secret anywhere in the accepted window
+func wrongTOTPCode(secret string) string {
+	valid := map[string]bool{}
+	current := totpCounter(time.Now())
+	for step := current - totpSkew - 1; step <= current+totpSkew+1; step++ {
+		code, _ := totpCode(secret, step)
+		valid[code] = true
+	}
+	wrong := "123456"
+	for valid[wrong] {
+		wrong = wrong[1:] + wrong[:1]
+	}
+	return wrong
+}
+
+// TestTwoFactor_WrongCodesLockAccount verifies failed codes count toward the lockout and that passing the
+// password step again does not reset the count
+func TestTwoFactor_WrongCodesLockAccount(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "correct-horse")
+	secret, _ := enableTwoFactorForTest(t, as, user)
+	wrong := wrongTOTPCode(secret)
+
+	for i := 1; i < maxFailedLogins; i++ {
+		resp, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"})
+		if err != nil {
+			t.Fatalf("Attempt %d: login failed: %v", i, err)
+		}
+		if _, err := as.Verify2FA(resp.ChallengeToken, wrong); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("Attempt %d: expected ErrInvalidTwoFactorCode, got %v", i, err)
+		}
+	}
+
+	resp, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	var locked *AccountLockedError
+	if _, err := as.Verify2FA(resp.ChallengeToken, wrong); !errors.As(err, &locked) {
+		t.Fatalf("Expected the fifth wrong code to lock the account, got %v", err)
+	}
+
+	code, _ := totpCode(secret, totpCounter(time.Now())+1)
+	if _, err := as.Verify2FA(resp.ChallengeToken, code); !errors.As(err, &locked) {
+		t.Errorf("Expected a valid code during lockout to be refused, got %v", err)
+	}
+	if _, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"}); !errors.As(err, &locked) {
+		t.Errorf("Expected the password step during lockout to be refused, got %v", err)
+	}
+}
+
+// TestBranchLogin_RequiresTwoFactor verifies branch credentials cannot skip the linked user's second factor
+func TestBranchLogin_RequiresTwoFactor(t *testing.T) {
+	as, _ := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "Two Factor Exchange"}
+	as.DB.Create(tenant)
+	branch, err := NewBranchService(as.DB).CreateBranch(tenant.ID, CreateBranchRequest{
+		Name:     "Harbour",
+		Username: "harbour",
+		Password: "branch-secret",
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	var branchUser models.User
+	as.DB.First(&branchUser, *branch.BranchUserID)
+	secret, _ := enableTwoFactorForTest(t, as, &branchUser)
+
+	resp, err := as.Login(LoginRequest{Email: "harbour", Password: "branch-secret"})
+	if err != nil {
+		t.Fatalf("Branch login failed: %v", err)
+	}
+	if !resp.TwoFactorRequired || resp.ChallengeToken == "" || resp.AccessToken != "" {
+		t.Fatalf("Expected a 2FA challenge and no tokens, got %+v", resp)
+	}
+
+	code, _ := totpCode(secret, totpCounter(time.Now())+1)
+	verified, err := as.Verify2FA(resp.ChallengeToken, code)
+	if err != nil {
+		t.Fatalf("Verify2FA failed: %v", err)
+	}
+	if verified.User.ID != branchUser.ID || verified.AccessToken == "" {
+		t.Errorf("Expected tokens for branch user %d, got %+v", branchUser.ID, verified)
+	}
+}
+
+// TestBranchLogin_IsolatesTenantsWithSameBranchUsername verifies identically named branches in two tenants log in as separate users
+func TestBranchLogin_IsolatesTenantsWithSameBranchUsername(t *testing.T) {
+	as, _ := setupAuthTestService(t)
+	branchService := NewBranchService(as.DB)
+
+	createTenantBranch := func(name, password string) *models.Branch {
+		tenant := &models.Tenant{Name: name}
+		if err := as.DB.Create(tenant).Error; err != nil {
+			t.Fatalf("Failed to create tenant: %v", err)
+		}
+		branch, err := branchService.CreateBranch(tenant.ID, CreateBranchRequest{
+			Name:     "Downtown",
+			Username: "downtown",
+			Password: password,
+		}, 0)
+		if err != nil {
+			t.Fatalf("Failed to create branch for %s: %v", name, err)
+		}
+		if branch.BranchUserID == nil {
+			t.Fatalf("Expected branch for %s to get a linked user when credentials were set", name)
+		}
+		return branch
+	}
+
+	branchA := createTenantBranch("Exchange A", "password-a")
+	branchB := createTenantBranch("Exchange B", "password-b")
+	if *branchA.BranchUserID == *branchB.BranchUserID {
+		t.Fatal("Expected each branch to get its own linked user")
+	}
+
+	for _, tc := range []struct {
+		password string
+		branch   *models.Branch
+	}{
+		{"password-a", branchA},
+		{"password-b", branchB},
+	} {
+		resp, err := as.Login(LoginRequest{Email: "downtown", Password: tc.password})
+		if err != nil {
+			t.Fatalf("Branch login failed: %v", err)
+		}
+		if resp.User.ID != *tc.branch.BranchUserID {
+			t.Errorf("Expected login to resolve to user %d, got %d", *tc.branch.BranchUserID, resp.User.ID)
+		}
+		if resp.User.TenantID == nil || *resp.User.TenantID != tc.branch.TenantID {
+			t.Errorf("Expected branch user in tenant %d, got %v", tc.branch.TenantID, resp.User.TenantID)
+		}
+		if strings.HasSuffix(resp.User.Email, "@branch.local") {
+			t.Errorf("Expected no guessable shadow email, got %s", resp.User.Email)
+		}
+	}
+
+	var shadowCount int64
+	as.DB.Model(&models.User{}).Where("email = ?", "downtown@branch.local").Count(&shadowCount)
+	if shadowCount != 0 {
+		t.Errorf("Expected no shadow user to be synthesized, found %d", shadowCount)
+	}
+}
+
+// TestBranchLogin_FailsWithoutLinkedUser verifies a branch login never creates a user on the fly
+func TestBranchLogin_FailsWithoutLinkedUser(t *testing.T) {
+	as, _ := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "Unlinked Exchange"}
+	as.DB.Create(tenant)
+
+	username := "uptown"
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	hashStr := string(hash)
+	branch := &models.Branch{
+		TenantID:     tenant.ID,
+		Name:         "Uptown",
+		BranchCode:   "UPT-001",
+		Status:       models.BranchStatusActive,
+		Username:     &username,
+		PasswordHash: &hashStr,
+	}
+	if err := as.DB.Create(branch).Error; err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	var before int64
+	as.DB.Model(&models.User{}).Count(&before)
+
+	if _, err := as.Login(LoginRequest{Email: "uptown", Password: "secret"}); err == nil || !strings.Contains(err.Error(), "no linked user") {
+		t.Fatalf("Expected a missing linked user error, got %v", err)
+	}
+
+	var after int64
+	as.DB.Model(&models.User{}).Count(&after)
+	if after != before {
+		t.Errorf("Expected no user to be created on login, user count went from %d to %d", before, after)
+	}
+}
+
+// TestLogin_LocksAccountAfterRepeatedFailures verifies the fifth bad password locks the account, even for the correct password
+func TestLogin_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "correct-horse")
+
+	for i := 1; i < maxFailedLogins; i++ {
+		_, err := as.Login(LoginRequest{Email: user.Email, Password: "wrong"})
+		var locked *AccountLockedError
+		if err == nil || errors.As(err, &locked) {
+			t.Fatalf("Attempt %d: expected a plain invalid password error, got %v", i, err)
+		}
+	}
+
+	_, err := as.Login(LoginRequest{Email: user.Email, Password: "wrong"})
+	var locked *AccountLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Expected the fifth failure to lock the account, got %v", err)
+	}
+	if d := time.Until(locked.Until); d < baseLockoutDuration-time.Minute || d > baseLockoutDuration {
+		t.Errorf("Expected a lockout of about %s, got %s", baseLockoutDuration, d)
+	}
+
+	_, err = as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"})
+	if !errors.As(err, &locked) {
+		t.Fatalf("Expected login during lockout to be refused, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "account temporarily locked") {
+		t.Errorf("Expected a distinct locked message, got %q", err.Error())
+	}
+}
+
+// TestLogin_SuccessResetsFailedCount verifies a good password before the threshold clears earlier failures
+func TestLogin_SuccessResetsFailedCount(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "correct-horse")
+
+	for i := 0; i < maxFailedLogins-1; i++ {
+		as.Login(LoginRequest{Email: user.Email, Password: "wrong"})
+	}
+	if _, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"}); err != nil {
+		t.Fatalf("Expected login before the threshold to succeed, got %v", err)
+	}
+
+	var reloaded models.User
+	as.DB.First(&reloaded, user.ID)
+	if reloaded.FailedLoginCount != 0 {
+		t.Errorf("Expected failed login count to reset, got %d", reloaded.FailedLoginCount)
+	}
+
+	// A fresh run of failures below the threshold must not lock
+	for i := 0; i < maxFailedLogins-1; i++ {
+		as.Login(LoginRequest{Email: user.Email, Password: "wrong"})
+	}
+	if _, err := as.Login(LoginRequest{Email: user.Email, Password: "correct-horse"}); err != nil {
+		t.Fatalf("Expected account to remain unlocked, got %v", err)
+	}
+}
+
+// TestBranchLogin_LocksAfterRepeatedFailures verifies bad branch passwords lock the linked user like bad user passwords
+func TestBranchLogin_LocksAfterRepeatedFailures(t *testing.T) {
+	as, _ := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "Lockout Exchange"}
+	as.DB.Create(tenant)
+	branch, err := NewBranchService(as.DB).CreateBranch(tenant.ID, CreateBranchRequest{
+		Name:     "Quay",
+		Username: "quay",
+		Password: "branch-secret",
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create branch: %v", err)
+	}
+
+	for i := 1; i < maxFailedLogins; i++ {
+		_, err := as.Login(LoginRequest{Email: "quay", Password: "wrong"})
+		var locked *AccountLockedError
+		if err == nil || errors.As(err, &locked) {
+			t.Fatalf("Attempt %d: expected a plain invalid password error, got %v", i, err)
+		}
+	}
+
+	_, err = as.Login(LoginRequest{Email: "quay", Password: "wrong"})
+	var locked *AccountLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Expected the fifth failure to lock the branch account, got %v", err)
+	}
+
+	var branchUser models.User
+	as.DB.First(&branchUser, *branch.BranchUserID)
+	if branchUser.FailedLoginCount != maxFailedLogins || branchUser.LockedUntil == nil {
+		t.Errorf("Expected the linked user to record %d failures and a lockout, got %d and %v",
+			maxFailedLogins, branchUser.FailedLoginCount, branchUser.LockedUntil)
+	}
+
+	if _, err := as.Login(LoginRequest{Email: "quay", Password: "branch-secret"}); !errors.As(err, &locked) {
+		t.Errorf("Expected the correct branch password during lockout to be refused, got %v", err)
+	}
+}
+
+// TestLockoutDuration_Escalates verifies repeated lockouts get longer up to the cap
+func TestLockoutDuration_Escalates(t *testing.T) {
+	if got := lockoutDuration(maxFailedLogins); got != baseLockoutDuration {
+		t.Errorf("Expected first lockout of %s, got %s", baseLockoutDuration, got)
+	}
+	if got := lockoutDuration(2 * maxFailedLogins); got != 2*baseLockoutDuration {
+		t.Errorf("Expected second lockout of %s, got %s", 2*baseLockoutDuration, got)
+	}
+	if got := lockoutDuration(100 * maxFailedLogins); got != maxLockoutDuration {
+		t.Errorf("Expected lockouts to cap at %s, got %s", maxLockoutDuration, got)
+	}
+}
+
+// TestChangePassword_RejectsPreviousPassword verifies the immediately-previous password cannot be reused but a new one can
+func TestChangePassword_RejectsPreviousPassword(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "first-password")
+
+	if err := as.ChangePassword(user.ID, "first-password", "second-password"); err != nil {
+		t.Fatalf("Expected change to a new password to succeed, got %v", err)
+	}
+
+	if err := as.ChangePassword(user.ID, "second-password", "first-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Fatalf("Expected the previous password to be rejected, got %v", err)
+	}
+	if err := as.ChangePassword(user.ID, "second-password", "second-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Fatalf("Expected the current password to be rejected, got %v", err)
+	}
+
+	if err := as.ChangePassword(user.ID, "second-password", "third-password"); err != nil {
+		t.Fatalf("Expected a genuinely new password to be accepted, got %v", err)
+	}
+
+	var history []models.PasswordHistory
+	as.DB.Where("user_id = ?", user.ID).Find(&history)
+	if len(history) != 2 {
+		t.Errorf("Expected both retired hashes in history, got %d", len(history))
+	}
+}
+
+// TestResetPasswordWithCode_RejectsRecentPassword verifies resets are subject to the same history check
+func TestResetPasswordWithCode_RejectsRecentPassword(t *testing.T) {
+	as, user := setupAuthTestService(t)
+	setPasswordForTest(t, as, user, "first-password")
+	if err := as.ChangePassword(user.ID, "first-password", "second-password"); err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+
+	resetCode := models.PasswordResetCode{Email: user.Email, Code: "123456", ExpiresAt: time.Now().Add(time.Hour)}
+	as.DB.Create(&resetCode)
+
+	if err := as.ResetPasswordWithCode(user.Email, "123456", "first-password"); !errors.Is(err, ErrPasswordReused) {
+		t.Fatalf("Expected reset to a previous password to be rejected, got %v", err)
+	}
+	if err := as.ResetPasswordWithCode(user.Email, "123456", "brand-new-password"); err != nil {
+		t.Fatalf("Expected reset to a new password to succeed, got %v", err)
+	}
+}
+
+// TestChangePassword_HistoryDepthIsConfigurable verifies passwords older than the tenant's depth may be reused
+func TestChangePassword_HistoryDepthIsConfigurable(t *testing.T) {
+	as, user := setupAuthTestService(t)
+
+	tenant := &models.Tenant{Name: "Short Memory Exchange"}
+	as.DB.Create(tenant)
+	as.DB.Model(user).Update("tenant_id", tenant.ID)
+	user.TenantID = &tenant.ID
+	depth := 1
+	if _, err := NewTenantSettingsService(as.DB).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{PasswordHistoryDepth: &depth}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	setPasswordForTest(t, as, user, "password-one")
+	for _, change := range [][2]string{{"password-one", "password-two"}, {"password-two", "password-three"}} {
+		if err := as.ChangePassword(user.ID, change[0], change[1]); err != nil {
+			t.Fatalf("Failed to change password: %v", err)
+		}
+	}
+
+	if err := as.ChangePassword(user.ID, "password-three", "password-two"); !errors.Is(err, ErrPasswordReused) {
+		t.Fatalf("Expected the remembered password to be rejected, got %v", err)
+	}
+	if err := as.ChangePassword(user.ID, "password-three", "password-one"); err != nil {
+		t.Fatalf("Expected a password beyond the history depth to be accepted, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -30,6 +31,20 @@ type SettlementSuggestion struct {
 	Reason             string                    `json:"reason"`
 }
 
+// SkippedRemittance explains why an outstanding outgoing remittance was not suggested
+type SkippedRemittance struct {
+	OutgoingRemittanceID uint    `json:"outgoingRemittanceId"`
+	RemittanceCode       string  `json:"remittanceCode"`
+	RemainingIRR         float64 `json:"remainingIrr"`
+	Reason               string  `json:"reason"`
+}
+
+// SettlementSuggestionResult holds the suggestions and the remittances excluded from them
+type SettlementSuggestionResult struct {
+	Suggestions []SettlementSuggestion `json:"suggestions"`
+	Skipped     []SkippedRemittance    `json:"skipped"`
+}
+
 // AutoSettlementResult represents the result of auto-settlement
 type AutoSettlementResult struct {
 	Settlements     []models.RemittanceSettlement `json:"settlements"`
@@ -37,6 +52,9 @@ type AutoSettlementResult struct {
 	TotalProfitCAD  float64                       `json:"totalProfitCad"`
 	RemainingIRR    float64                       `json:"remainingIrr"`
 	SettlementCount int                           `json:"settlementCount"`
+	Skipped         []SkippedRemittance           `json:"skipped"`
+	PendingApproval []models.PendingSettlement    `json:"pendingApproval"`        // Settlements held for a second admin's approval
+	MaxAmountIRR    *float64                      `json:"maxAmountIrr,omitempty"` // Cap the allocation was limited to, if any
 }
 
 // AutoSettlementService handles automatic settlement logic
@@ -55,6 +73,21 @@ func NewAutoSettlementService(db *gorm.DB) *AutoSettlementService {
 
 // GetSettlementSuggestions returns suggested settlements for an incoming remittance
 func (s *AutoSettlementService) GetSettlementSuggestions(tenantID, incomingID uint, strategy SettlementStrategy, limit int) ([]SettlementSuggestion, error) {
+	result, err := s.GetSettlementCandidates(tenantID, incomingID, strategy, limit)
+	if err != nil {
+		return nil, err
+	}
+	return result.Suggestions, nil
+}
+
+// GetSettlementCandidates returns suggested settlements for an incoming remittance along with
+// the outstanding outgoing remittances that were excluded and why
+func (s *AutoSettlementService) GetSettlementCandidates(tenantID, incomingID uint, strategy SettlementStrategy, limit int) (*SettlementSuggestionResult, error) {
+	return s.settlementCandidates(tenantID, incomingID, strategy, limit, nil)
+}
+
+// settlementCandidates builds the suggestions, allocating at most maxAmountIRR of the incoming remittance when set
+func (s *AutoSettlementService) settlementCandidates(tenantID, incomingID uint, strategy SettlementStrategy, limit int, maxAmountIRR *models.Decimal) (*SettlementSuggestionResult, error) {
 	// Get the incoming remittance
 	var incoming models.IncomingRemittance
 	if err := s.db.Where("id = ? AND tenant_id = ?", incomingID, tenantID).First(&incoming).Error; err != nil {
@@ -74,16 +107,43 @@ func (s *AutoSettlementService) GetSettlementSuggestions(tenantID, incomingID ui
 		return nil, err
 	}
 
+	// Exclude remittances that compliance has not cleared
+	result := &SettlementSuggestionResult{
+		Suggestions: make([]SettlementSuggestion, 0),
+		Skipped:     make([]SkippedRemittance, 0),
+	}
+	eligible := outgoings[:0]
+	for _, outgoing := range outgoings {
+		if outgoing.ComplianceHold {
+			reason := "On compliance hold"
+			if outgoing.ComplianceHoldReason != nil && *outgoing.ComplianceHoldReason != "" {
+				reason += ": " + *outgoing.ComplianceHoldReason
+			}
+			result.Skipped = append(result.Skipped, SkippedRemittance{
+				OutgoingRemittanceID: outgoing.ID,
+				RemittanceCode:       outgoing.RemittanceCode,
+				RemainingIRR:         outgoing.RemainingIRR.Float64(),
+				Reason:               reason,
+			})
+			continue
+		}
+		eligible = append(eligible, outgoing)
+	}
+	outgoings = eligible
+
 	if len(outgoings) == 0 {
-		return []SettlementSuggestion{}, nil
+		return result, nil
 	}
 
 	// Sort based on strategy
-	s.sortByStrategy(outgoings, incoming, strategy)
+	orderByStrategy(outgoings, incoming, strategy)
 
 	// Generate suggestions
-	suggestions := make([]SettlementSuggestion, 0)
+	suggestions := result.Suggestions
 	remainingToAllocate := incoming.RemainingIRR
+	if maxAmountIRR != nil && maxAmountIRR.LessThan(remainingToAllocate) {
+		remainingToAllocate = *maxAmountIRR
+	}
 
 	for _, outgoing := range outgoings {
 		if remainingToAllocate.LessThanOrEqual(models.Zero()) {
@@ -125,18 +185,38 @@ func (s *AutoSettlementService) GetSettlementSuggestions(tenantID, incomingID ui
 		remainingToAllocate = remainingToAllocate.Sub(suggestedAmount)
 	}
 
-	return suggestions, nil
+	result.Suggestions = suggestions
+	return result, nil
 }
 
 // AutoSettle automatically settles an incoming remittance using the specified strategy
 func (s *AutoSettlementService) AutoSettle(tenantID, incomingID, userID uint, strategy SettlementStrategy) (*AutoSettlementResult, error) {
+	return s.AutoSettleUpTo(tenantID, incomingID, userID, strategy, nil)
+}
+
+// AutoSettleUpTo automatically settles an incoming remittance like AutoSettle, but allocates at most
+// maxAmountIRR of it (nil means no cap). Whatever is not allocated stays on the incoming remittance,
+// which is left PARTIAL; TotalSettledIRR reports what was actually allocated.
+// Settlements held for approval are reported in PendingApproval and outgoing remittances paid down since the
+// suggestions were made in Skipped. Any other failure stops the run; settlements made before it stand.
+func (s *AutoSettlementService) AutoSettleUpTo(tenantID, incomingID, userID uint, strategy SettlementStrategy, maxAmountIRR *models.Decimal) (*AutoSettlementResult, error) {
+	if strategy == StrategyManual {
+		return nil, errors.New("the MANUAL strategy cannot auto-settle; settle remittances individually")
+	}
+	if maxAmountIRR != nil && !maxAmountIRR.IsPositive() {
+		return nil, errors.New("maximum settlement amount must be greater than 0")
+	}
+
 	// Get suggestions first
-	suggestions, err := s.GetSettlementSuggestions(tenantID, incomingID, strategy, 0)
+	candidates, err := s.settlementCandidates(tenantID, incomingID, strategy, 0, maxAmountIRR)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(suggestions) == 0 {
+	if len(candidates.Suggestions) == 0 {
+		if len(candidates.Skipped) > 0 {
+			return nil, fmt.Errorf("no eligible outgoing remittances to settle (%d on compliance hold)", len(candidates.Skipped))
+		}
 		return nil, errors.New("no pending outgoing remittances to settle")
 	}
 
@@ -144,10 +224,16 @@ func (s *AutoSettlementService) AutoSettle(tenantID, incomingID, userID uint, st
 		Settlements:     make([]models.RemittanceSettlement, 0),
 		TotalSettledIRR: 0,
 		TotalProfitCAD:  0,
+		Skipped:         candidates.Skipped,
+		PendingApproval: make([]models.PendingSettlement, 0),
+	}
+	if maxAmountIRR != nil {
+		maxAmount := maxAmountIRR.Float64()
+		result.MaxAmountIRR = &maxAmount
 	}
 
 	// Execute settlements
-	for _, suggestion := range suggestions {
+	for _, suggestion := range candidates.Suggestions {
 		settlement, err := s.remittanceService.SettleRemittance(
 			tenantID,
 			suggestion.OutgoingRemittance.ID,
@@ -155,9 +241,21 @@ func (s *AutoSettlementService) AutoSettle(tenantID, incomingID, userID uint, st
 			models.NewDecimal(suggestion.SuggestedAmountIRR),
 			userID,
 		)
-		if err != nil {
-			// Log error but continue with next
+		var pendingErr *SettlementPendingApprovalError
+		switch {
+		case errors.As(err, &pendingErr):
+			result.PendingApproval = append(result.PendingApproval, *pendingErr.Pending)
+			continue
+		case errors.Is(err, ErrSettlementExceedsDebt):
+			result.Skipped = append(result.Skipped, SkippedRemittance{
+				OutgoingRemittanceID: suggestion.OutgoingRemittance.ID,
+				RemittanceCode:       suggestion.OutgoingRemittance.RemittanceCode,
+				RemainingIRR:         suggestion.OutgoingRemittance.RemainingIRR.Float64(),
+				Reason:               "Settled elsewhere since the suggestions were made",
+			})
 			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to settle %s: %w", suggestion.OutgoingRemittance.RemittanceCode, err)
 		}
 
 		result.Settlements = append(result.Settlements, *settlement)
@@ -175,35 +273,64 @@ func (s *AutoSettlementService) AutoSettle(tenantID, incomingID, userID uint, st
 	return result, nil
 }
 
-// sortByStrategy sorts outgoing remittances based on the strategy
-func (s *AutoSettlementService) sortByStrategy(outgoings []models.OutgoingRemittance, incoming models.IncomingRemittance, strategy SettlementStrategy) {
+// orderByStrategy orders candidate outgoing remittances for settlement against the incoming one.
+// Ties fall back to oldest first, then lowest ID, so the order is deterministic.
+//   - FIFO: oldest outgoing first
+//   - LIFO: newest outgoing first
+//   - BEST_RATE: highest profit per IRR first. Every IRR allocated earns 1/BuyRate - 1/SellRate CAD and the
+//     incoming amount is the only limit, so filling the best margins first maximizes the estimated profit.
+func orderByStrategy(outgoings []models.OutgoingRemittance, incoming models.IncomingRemittance, strategy SettlementStrategy) {
+	oldestFirst := func(a, b models.OutgoingRemittance) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+
 	switch strategy {
-	case StrategyFIFO:
-		// Sort by created_at ascending (oldest first)
-		sort.Slice(outgoings, func(i, j int) bool {
-			return outgoings[i].CreatedAt.Before(outgoings[j].CreatedAt)
-		})
 	case StrategyLIFO:
-		// Sort by created_at descending (newest first)
-		sort.Slice(outgoings, func(i, j int) bool {
-			return outgoings[i].CreatedAt.After(outgoings[j].CreatedAt)
+		sort.SliceStable(outgoings, func(i, j int) bool {
+			if !outgoings[i].CreatedAt.Equal(outgoings[j].CreatedAt) {
+				return outgoings[i].CreatedAt.After(outgoings[j].CreatedAt)
+			}
+			return outgoings[i].ID > outgoings[j].ID
 		})
 	case StrategyBestRate:
-		// Sort by profit potential (highest profit first)
-		// Profit = AmountIRR * (1/BuyRate - 1/SellRate)
-		sort.Slice(outgoings, func(i, j int) bool {
-			profitI := models.NewDecimal(1).Div(outgoings[i].BuyRateCAD).Sub(models.NewDecimal(1).Div(incoming.SellRateCAD))
-			profitJ := models.NewDecimal(1).Div(outgoings[j].BuyRateCAD).Sub(models.NewDecimal(1).Div(incoming.SellRateCAD))
-			return profitI.GreaterThan(profitJ)
+		sellCost := models.NewDecimal(1).Div(incoming.SellRateCAD)
+		margins := make(map[uint]models.Decimal, len(outgoings))
+		for _, outgoing := range outgoings {
+			margins[outgoing.ID] = models.NewDecimal(1).Div(outgoing.BuyRateCAD).Sub(sellCost)
+		}
+		sort.SliceStable(outgoings, func(i, j int) bool {
+			marginI, marginJ := margins[outgoings[i].ID], margins[outgoings[j].ID]
+			if marginI.GreaterThan(marginJ) {
+				return true
+			}
+			if marginJ.GreaterThan(marginI) {
+				return false
+			}
+			return oldestFirst(outgoings[i], outgoings[j])
 		})
 	default:
-		// Default to FIFO
-		sort.Slice(outgoings, func(i, j int) bool {
-			return outgoings[i].CreatedAt.Before(outgoings[j].CreatedAt)
+		// FIFO, and the fallback for any other strategy
+		sort.SliceStable(outgoings, func(i, j int) bool {
+			return oldestFirst(outgoings[i], outgoings[j])
 		})
 	}
 }
 
+// ParseSettlementStrategy resolves a strategy name (case-insensitive); an empty name means FIFO
+func ParseSettlementStrategy(name string) (SettlementStrategy, error) {
+	strategy := SettlementStrategy(strings.ToUpper(strings.TrimSpace(name)))
+	switch strategy {
+	case "":
+		return StrategyFIFO, nil
+	case StrategyFIFO, StrategyLIFO, StrategyBestRate, StrategyManual:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown settlement strategy %q (use FIFO, LIFO or BEST_RATE)", name)
+}
+
 // calculateMatchScore calculates a 0-100 score for how good a match is
 func (s *AutoSettlementService) calculateMatchScore(outgoing models.OutgoingRemittance, incoming models.IncomingRemittance, strategy SettlementStrategy, daysOutstanding int) float64 {
 	score := 50.0 // Base score
@@ -251,6 +378,8 @@ func (s *AutoSettlementService) getMatchReason(outgoing models.OutgoingRemittanc
 			return fmt.Sprintf("📅 Priority - outstanding for %d days", daysOutstanding)
 		}
 		return "📋 FIFO order - created " + outgoing.CreatedAt.Format("Jan 2")
+	case StrategyLIFO:
+		return "🆕 LIFO order - most recent, created " + outgoing.CreatedAt.Format("Jan 2")
 	case StrategyBestRate:
 		profit := (models.NewDecimal(1).Div(outgoing.BuyRateCAD).Sub(models.NewDecimal(1).Div(incoming.SellRateCAD))).Mul(outgoing.RemainingIRR)
 		if profit.GreaterThan(models.NewDecimal(100)) {
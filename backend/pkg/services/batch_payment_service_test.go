@@ -24,9 +24,12 @@ func setupBatchPaymentTestDB(t *testing.T) *gorm.DB {
 		&models.Client{},
 		&models.Transaction{},
 		&models.Payment{},
+		&models.PaymentPlan{},
+		&models.PaymentInstallment{},
 		&models.LedgerEntry{},
 		&models.CashBalance{},
 		&models.CashAdjustment{},
+		&models.DailyClose{},
 	)
 
 	return db
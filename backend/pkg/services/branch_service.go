@@ -77,7 +77,7 @@ func (bs *BranchService) CreateBranch(tenantID uint, req CreateBranchRequest, cr
 	// Validate username if provided
 	if req.Username != "" {
 		var existingBranch models.Branch
-		if err := bs.DB.Where("username = ?", req.Username).First(&existingBranch).Error; err == nil {
+		if err := bs.DB.Where("tenant_id = ? AND username = ?", tenantID, req.Username).First(&existingBranch).Error; err == nil {
 			return nil, errors.New("username already taken")
 		}
 	}
@@ -108,8 +108,17 @@ func (bs *BranchService) CreateBranch(tenantID uint, req CreateBranchRequest, cr
 		branch.PasswordHash = &hashedPasswordStr
 	}
 
-	if err := bs.DB.Create(branch).Error; err != nil {
-		return nil, fmt.Errorf("failed to create branch: %w", err)
+	err := bs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(branch).Error; err != nil {
+			return fmt.Errorf("failed to create branch: %w", err)
+		}
+		if branch.Username != nil {
+			return bs.ensureBranchUser(tx, branch)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Printf("✅ Branch created: %s (ID: %d) for Tenant ID: %d", branch.Name, branch.ID, tenantID)
@@ -161,7 +170,7 @@ func (bs *BranchService) GetBranchesWithStats(tenantID uint) ([]BranchWithStats,
 		Select(`
 			branches.*, 
 			COALESCE((SELECT SUM(final_balance) FROM cash_balances WHERE cash_balances.branch_id = branches.id AND cash_balances.tenant_id = branches.tenant_id), 0) as current_cash,
-			COALESCE((SELECT SUM(send_amount) FROM transactions WHERE transactions.branch_id = branches.id AND transactions.tenant_id = branches.tenant_id AND DATE(transactions.transaction_date) = DATE('now')), 0) as today_volume
+			COALESCE((SELECT SUM(send_amount) FROM transactions WHERE transactions.branch_id = branches.id AND transactions.tenant_id = branches.tenant_id AND transactions.deleted_at IS NULL AND DATE(transactions.transaction_date) = DATE('now')), 0) as today_volume
 		`).
 		Where("branches.tenant_id = ?", tenantID).
 		Order("branches.is_primary DESC, branches.created_at ASC").
@@ -229,7 +238,8 @@ func (bs *BranchService) DeactivateBranch(branchID, tenantID uint) error {
 	// Check for pending pickup transactions (just log warning, don't block)
 	var pendingCount int64
 	if err := bs.DB.Table("pickup_transactions").
-		Where("(sender_branch_id = ? OR receiver_branch_id = ?) AND status = ?", branchID, branchID, "PENDING").
+		Where("(sender_branch_id = ? OR receiver_branch_id = ?) AND status IN ?", branchID, branchID,
+			[]string{models.PickupStatusPending, models.PickupStatusReady}).
 		Count(&pendingCount).Error; err == nil && pendingCount > 0 {
 		log.Printf("⚠️  Warning: Deactivating branch with %d pending pickup transaction(s)", pendingCount)
 	}
@@ -347,7 +357,7 @@ func (bs *BranchService) SetBranchCredentials(branchID, tenantID uint, username,
 	// Check if username is already taken by another branch
 	if username != "" {
 		var existingBranch models.Branch
-		err := bs.DB.Where("username = ? AND id != ?", username, branchID).First(&existingBranch).Error
+		err := bs.DB.Where("tenant_id = ? AND username = ? AND id != ?", tenantID, username, branchID).First(&existingBranch).Error
 		if err == nil {
 			return errors.New("username already taken by another branch")
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -363,14 +373,145 @@ func (bs *BranchService) SetBranchCredentials(branchID, tenantID uint, username,
 
 	hashedPasswordStr := string(hashedPassword)
 
-	// Update branch credentials
-	if err := bs.DB.Model(&branch).Updates(map[string]interface{}{
-		"username":      &username,
-		"password_hash": &hashedPasswordStr,
+	// Update branch credentials and make sure the branch has a user to log in as
+	return bs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&branch).Updates(map[string]interface{}{
+			"username":      &username,
+			"password_hash": &hashedPasswordStr,
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update branch credentials: %w", err)
+		}
+		return bs.ensureBranchUser(tx, &branch)
+	})
+}
+
+// ensureBranchUser creates and links the user a branch login acts as, if the branch does not have one yet
+func (bs *BranchService) ensureBranchUser(tx *gorm.DB, branch *models.Branch) error {
+	if branch.BranchUserID != nil {
+		return nil
+	}
+
+	branchUser, err := models.NewBranchUser(branch)
+	if err != nil {
+		return fmt.Errorf("failed to build branch user: %w", err)
+	}
+	if err := tx.Create(branchUser).Error; err != nil {
+		return fmt.Errorf("failed to create branch user: %w", err)
+	}
+	if err := tx.Model(branch).Update("branch_user_id", branchUser.ID).Error; err != nil {
+		return fmt.Errorf("failed to link branch user: %w", err)
+	}
+	branch.BranchUserID = &branchUser.ID
+	return nil
+}
+
+// FloatTargetRequest creates or updates a branch cash float target
+type FloatTargetRequest struct {
+	Currency     string   `json:"currency"`
+	TargetAmount float64  `json:"targetAmount"`
+	BandPercent  *float64 `json:"bandPercent"` // Defaults to models.DefaultFloatBandPercent
+}
+
+// validate normalizes the request and checks the target and band are usable
+func (req *FloatTargetRequest) validate() error {
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if req.Currency == "" {
+		return errors.New("currency is required")
+	}
+	if req.TargetAmount <= 0 {
+		return errors.New("target amount must be greater than 0")
+	}
+	if req.BandPercent != nil && (*req.BandPercent <= 0 || *req.BandPercent > 100) {
+		return errors.New("band percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// GetFloatTargets lists a branch's cash float targets
+func (bs *BranchService) GetFloatTargets(branchID, tenantID uint) ([]models.BranchFloatTarget, error) {
+	if _, err := bs.GetBranchByID(branchID, tenantID); err != nil {
+		return nil, err
+	}
+
+	var targets []models.BranchFloatTarget
+	if err := bs.DB.Where("tenant_id = ? AND branch_id = ?", tenantID, branchID).
+		Order("currency ASC").Find(&targets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get float targets: %w", err)
+	}
+	return targets, nil
+}
+
+// CreateFloatTarget sets the cash float a branch should hold in a currency
+func (bs *BranchService) CreateFloatTarget(branchID, tenantID uint, req FloatTargetRequest, userID uint) (*models.BranchFloatTarget, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := bs.GetBranchByID(branchID, tenantID); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	bs.DB.Model(&models.BranchFloatTarget{}).Where("branch_id = ? AND currency = ?", branchID, req.Currency).Count(&count)
+	if count > 0 {
+		return nil, fmt.Errorf("branch already has a %s float target", req.Currency)
+	}
+
+	band := models.NewDecimal(models.DefaultFloatBandPercent)
+	if req.BandPercent != nil {
+		band = models.NewDecimal(*req.BandPercent)
+	}
+	target := &models.BranchFloatTarget{
+		TenantID:     tenantID,
+		BranchID:     branchID,
+		Currency:     req.Currency,
+		TargetAmount: models.NewDecimal(req.TargetAmount),
+		BandPercent:  band,
+		CreatedBy:    userID,
+	}
+	if err := bs.DB.Create(target).Error; err != nil {
+		return nil, fmt.Errorf("failed to create float target: %w", err)
+	}
+	return target, nil
+}
+
+// UpdateFloatTarget changes a branch's float target amount or band
+func (bs *BranchService) UpdateFloatTarget(branchID, tenantID, targetID uint, req FloatTargetRequest) (*models.BranchFloatTarget, error) {
+	var target models.BranchFloatTarget
+	if err := bs.DB.Where("id = ? AND branch_id = ? AND tenant_id = ?", targetID, branchID, tenantID).First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("float target not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// The currency identifies the target and cannot be changed
+	req.Currency = target.Currency
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"target_amount": models.NewDecimal(req.TargetAmount),
 		"updated_at":    time.Now(),
-	}).Error; err != nil {
-		return fmt.Errorf("failed to update branch credentials: %w", err)
 	}
+	if req.BandPercent != nil {
+		updates["band_percent"] = models.NewDecimal(*req.BandPercent)
+	}
+	if err := bs.DB.Model(&target).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update float target: %w", err)
+	}
+	return &target, nil
+}
 
+// DeleteFloatTarget removes a branch's float target
+func (bs *BranchService) DeleteFloatTarget(branchID, tenantID, targetID uint) error {
+	result := bs.DB.Where("id = ? AND branch_id = ? AND tenant_id = ?", targetID, branchID, tenantID).Delete(&models.BranchFloatTarget{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete float target: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("float target not found")
+	}
 	return nil
 }
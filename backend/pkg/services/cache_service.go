@@ -123,26 +123,25 @@ func (cs *CacheService) GetExchangeRate(tenantID uint, baseCurrency, targetCurre
 	}
 
 	// Fetch from database
-	var rate models.ExchangeRate
-	err := cs.db.Where("tenant_id = ? AND base_currency = ? AND target_currency = ?",
-		tenantID, baseCurrency, targetCurrency).
-		Order("created_at DESC").
-		First(&rate).Error
-
+	rate, err := findEffectiveRate(cs.db, tenantID, baseCurrency, targetCurrency, time.Now(), false)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache the result
+	// Cache the result, but not past the end of a temporary override
+	expiresAt := time.Now().Add(cs.exchangeRateTTL)
+	if rate.ExpiresAt != nil && rate.ExpiresAt.Before(expiresAt) {
+		expiresAt = *rate.ExpiresAt
+	}
 	cs.mu.Lock()
 	cs.exchangeRates[key] = &CacheEntry{
-		Value:      &rate,
-		ExpiresAt:  time.Now().Add(cs.exchangeRateTTL),
+		Value:      rate,
+		ExpiresAt:  expiresAt,
 		LastAccess: time.Now(),
 	}
 	cs.mu.Unlock()
 
-	return &rate, nil
+	return rate, nil
 }
 
 // InvalidateExchangeRate removes an exchange rate from cache
@@ -3,6 +3,9 @@ package services
 import (
 	"api/pkg/models"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,28 +22,7 @@ func NewCashBalanceService(db *gorm.DB) *CashBalanceService {
 
 // CalculateBalanceFromTransactions calculates cash balance from transactions
 func (s *CashBalanceService) CalculateBalanceFromTransactions(tenantID uint, branchID *uint, currency string) (models.Decimal, error) {
-	var balance models.Decimal
-
-	// NOTE: transactions table does not have generic (currency, amount) columns.
-	// Cash balances are primarily affected by CASH payments.
-	query := s.DB.Model(&models.Payment{}).
-		Where("tenant_id = ? AND currency = ? AND payment_method = ? AND status = ?",
-			tenantID, currency, models.PaymentMethodCash, models.PaymentStatusCompleted)
-
-	// Filter by branch, including NULL branch balances
-	if branchID != nil {
-		query = query.Where("branch_id = ?", *branchID)
-	} else {
-		query = query.Where("branch_id IS NULL")
-	}
-
-	// Sum all completed cash payments
-	err := query.Select("COALESCE(SUM(amount), 0)").Scan(&balance).Error
-	if err != nil {
-		return models.Zero(), err
-	}
-
-	return balance, nil
+	return s.calculateBalanceWithTx(s.DB, tenantID, branchID, currency)
 }
 
 // GetOrCreateCashBalance gets or creates a cash balance record
@@ -121,6 +103,28 @@ func (s *CashBalanceService) RefreshCashBalance(id uint) (*models.CashBalance, e
 	return &cashBalance, nil
 }
 
+// RefreshBalanceFor recalculates the stored balance for the branch and currency, if one exists yet.
+// A balance created later is calculated from scratch, so there is nothing to do without one.
+func (s *CashBalanceService) RefreshBalanceFor(tenantID uint, branchID *uint, currency string) error {
+	query := s.DB.Model(&models.CashBalance{}).Where("tenant_id = ? AND currency = ?", tenantID, currency)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := s.RefreshCashBalance(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetAllBalancesForTenant retrieves all cash balances for a tenant
 func (s *CashBalanceService) GetAllBalancesForTenant(tenantID uint, branchID *uint) ([]models.CashBalance, error) {
 	var balances []models.CashBalance
@@ -139,115 +143,281 @@ func (s *CashBalanceService) GetBalanceByCurrency(tenantID uint, branchID *uint,
 	return s.GetOrCreateCashBalance(tenantID, branchID, currency)
 }
 
-// CreateManualAdjustment creates a manual adjustment to the cash balance
-// This operation is wrapped in a transaction to ensure atomicity
+var (
+	// ErrCashAdjustmentSelfApproval is returned when the creator of a pending cash adjustment tries to approve it
+	ErrCashAdjustmentSelfApproval = errors.New("a cash adjustment must be approved by someone other than its creator")
+	// ErrCashAdjustmentApprovalForbidden is returned when someone other than an owner or admin decides a pending cash adjustment
+	ErrCashAdjustmentApprovalForbidden = errors.New("only an owner or admin can approve or reject cash adjustments")
+	// ErrApprovalLimitForbidden is returned when someone other than an owner or admin changes a cash adjustment approval limit
+	ErrApprovalLimitForbidden = errors.New("only an owner or admin can change cash adjustment approval limits")
+	// ErrApprovalLimitNotFound is returned when removing an approval limit a currency does not have
+	ErrApprovalLimitNotFound = errors.New("cash adjustment approval limit not found")
+)
+
+// canApproveCashAdjustments reports whether the role may decide pending cash adjustments and set the limits above which they are held
+func canApproveCashAdjustments(role string) bool {
+	switch role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+		return true
+	}
+	return false
+}
+
+// CreateManualAdjustment creates a manual adjustment to the cash balance.
+// An adjustment larger, either way, than its currency's approval limit is stored as PENDING without
+// touching the balance; ApproveAdjustment applies it. Otherwise it is applied at once.
 func (s *CashBalanceService) CreateManualAdjustment(tenantID uint, branchID *uint, currency string, amount float64, reason string, adjustedBy uint) (*models.CashAdjustment, error) {
-	var adjustment *models.CashAdjustment
+	limit, err := s.GetApprovalLimit(tenantID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil || !models.NewDecimal(amount).Abs().GreaterThan(limit.Amount) {
+		return s.CreateAppliedAdjustment(tenantID, branchID, currency, amount, reason, adjustedBy)
+	}
+
+	adjustment := &models.CashAdjustment{
+		TenantID:      tenantID,
+		BranchID:      branchID,
+		Currency:      currency,
+		Amount:        models.NewDecimal(amount),
+		Reason:        reason,
+		AdjustedBy:    adjustedBy,
+		BalanceBefore: models.Zero(),
+		BalanceAfter:  models.Zero(),
+		Status:        models.CashAdjustmentStatusPending,
+	}
+	if err := s.DB.Create(adjustment).Error; err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
+
+// CreateAppliedAdjustment applies an adjustment to the cash balance at once, regardless of approval limits.
+// It is meant for movements approved by their own workflow, such as branch transfers.
+// This operation is wrapped in a transaction to ensure atomicity
+func (s *CashBalanceService) CreateAppliedAdjustment(tenantID uint, branchID *uint, currency string, amount float64, reason string, adjustedBy uint) (*models.CashAdjustment, error) {
+	adjustment := &models.CashAdjustment{
+		TenantID:   tenantID,
+		BranchID:   branchID,
+		Currency:   currency,
+		Amount:     models.NewDecimal(amount),
+		Reason:     reason,
+		AdjustedBy: adjustedBy,
+		Status:     models.CashAdjustmentStatusApplied,
+	}
 
 	err := s.DB.Transaction(func(tx *gorm.DB) error {
-		// Get or create cash balance within transaction
-		var cashBalance models.CashBalance
-
-		query := tx.Where("tenant_id = ? AND currency = ?", tenantID, currency)
-		if branchID != nil {
-			query = query.Where("branch_id = ?", *branchID)
-		} else {
-			query = query.Where("branch_id IS NULL")
+		if err := s.applyAdjustmentWithTx(tx, adjustment); err != nil {
+			return err
 		}
+		return tx.Create(adjustment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
 
-		err := query.First(&cashBalance).Error
+// applyAdjustmentWithTx adds the adjustment's amount to its cash balance, creating the balance if needed,
+// and records the balance before and after on the adjustment. The caller saves the adjustment.
+// Adjustments take effect now, so they are refused once the branch has closed today.
+func (s *CashBalanceService) applyAdjustmentWithTx(tx *gorm.DB, adjustment *models.CashAdjustment) error {
+	if err := ensureDayOpen(tx, adjustment.TenantID, adjustment.BranchID, time.Now()); err != nil {
+		return err
+	}
+
+	// Get or create cash balance within transaction
+	var cashBalance models.CashBalance
+
+	query := tx.Where("tenant_id = ? AND currency = ?", adjustment.TenantID, adjustment.Currency)
+	if adjustment.BranchID != nil {
+		query = query.Where("branch_id = ?", *adjustment.BranchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+
+	err := query.First(&cashBalance).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// Create new balance record
+		autoBalance, err := s.calculateBalanceWithTx(tx, adjustment.TenantID, adjustment.BranchID, adjustment.Currency)
 		if err != nil {
-			if !errors.Is(err, gorm.ErrRecordNotFound) {
-				return err
-			}
-			// Create new balance record
-			autoBalance, err := s.calculateBalanceWithTx(tx, tenantID, branchID, currency)
-			if err != nil {
-				return err
-			}
-			cashBalance = models.CashBalance{
-				TenantID:              tenantID,
-				BranchID:              branchID,
-				Currency:              currency,
-				AutoCalculatedBalance: autoBalance,
-				ManualAdjustment:      models.Zero(),
-				FinalBalance:          autoBalance,
-				LastCalculatedAt:      time.Now(),
-			}
-			if err := tx.Create(&cashBalance).Error; err != nil {
-				return err
-			}
+			return err
 		}
+		cashBalance = models.CashBalance{
+			TenantID:              adjustment.TenantID,
+			BranchID:              adjustment.BranchID,
+			Currency:              adjustment.Currency,
+			AutoCalculatedBalance: autoBalance,
+			ManualAdjustment:      models.Zero(),
+			FinalBalance:          autoBalance,
+			LastCalculatedAt:      time.Now(),
+		}
+		if err := tx.Create(&cashBalance).Error; err != nil {
+			return err
+		}
+	}
+
+	// Lock row version for optimistic update
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashBalance, cashBalance.ID).Error; err != nil {
+		return err
+	}
+
+	adjustment.BalanceBefore = cashBalance.FinalBalance
+	adjustment.BalanceAfter = cashBalance.FinalBalance.Add(adjustment.Amount)
 
-		balanceBefore := cashBalance.FinalBalance
-		amountDec := models.NewDecimal(amount)
-
-		// Create adjustment record
-		adjustment = &models.CashAdjustment{
-			TenantID:      tenantID,
-			BranchID:      branchID,
-			Currency:      currency,
-			Amount:        amountDec,
-			Reason:        reason,
-			AdjustedBy:    adjustedBy,
-			BalanceBefore: balanceBefore,
-			BalanceAfter:  balanceBefore.Add(amountDec),
+	// Update cash balance
+	now := time.Now()
+	adjustment.AppliedAt = &now
+	updates := map[string]interface{}{
+		"manual_adjustment":         cashBalance.ManualAdjustment.Add(adjustment.Amount),
+		"final_balance":             adjustment.BalanceAfter,
+		"last_manual_adjustment_at": &now,
+		"updated_at":                now,
+		"version":                   gorm.Expr("version + 1"),
+	}
+
+	res := tx.Model(&models.CashBalance{}).
+		Where("id = ? AND version = ?", cashBalance.ID, cashBalance.Version).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("cash balance was updated concurrently; please retry")
+	}
+
+	return nil
+}
+
+// GetPendingAdjustments lists the tenant's cash adjustments waiting for approval, oldest first
+func (s *CashBalanceService) GetPendingAdjustments(tenantID uint, branchID *uint) ([]models.CashAdjustment, error) {
+	var adjustments []models.CashAdjustment
+
+	query := s.DB.Where("tenant_id = ? AND status = ?", tenantID, models.CashAdjustmentStatusPending)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+
+	err := query.
+		Preload("Branch").
+		Preload("AdjustedByUser").
+		Order("created_at ASC, id ASC").
+		Find(&adjustments).Error
+	return adjustments, err
+}
+
+// ApproveAdjustment applies a pending cash adjustment. The approver must be an owner or admin other than
+// the adjustment's creator.
+func (s *CashBalanceService) ApproveAdjustment(tenantID, adjustmentID uint, approver *models.User) (*models.CashAdjustment, error) {
+	var adjustment *models.CashAdjustment
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		adjustment, err = s.lockPendingAdjustment(tx, tenantID, adjustmentID, approver)
+		if err != nil {
+			return err
 		}
 
-		if err := tx.Create(adjustment).Error; err != nil {
+		if err := s.applyAdjustmentWithTx(tx, adjustment); err != nil {
 			return err
 		}
 
-		// Update cash balance
 		now := time.Now()
+		adjustment.Status = models.CashAdjustmentStatusApplied
+		adjustment.DecidedBy = &approver.ID
+		adjustment.DecidedAt = &now
+		return tx.Model(adjustment).Updates(map[string]interface{}{
+			"status":         adjustment.Status,
+			"decided_by":     approver.ID,
+			"decided_at":     now,
+			"balance_before": adjustment.BalanceBefore,
+			"balance_after":  adjustment.BalanceAfter,
+			"applied_at":     adjustment.AppliedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adjustment, nil
+}
 
-		// Lock row version for optimistic update
-		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashBalance, cashBalance.ID).Error; err != nil {
+// RejectAdjustment declines a pending cash adjustment; the balance is never changed
+func (s *CashBalanceService) RejectAdjustment(tenantID, adjustmentID uint, approver *models.User, note string) (*models.CashAdjustment, error) {
+	var adjustment *models.CashAdjustment
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		adjustment, err = s.lockPendingAdjustment(tx, tenantID, adjustmentID, approver)
+		if err != nil {
 			return err
 		}
 
+		now := time.Now()
+		adjustment.Status = models.CashAdjustmentStatusRejected
+		adjustment.DecidedBy = &approver.ID
+		adjustment.DecidedAt = &now
 		updates := map[string]interface{}{
-			"manual_adjustment":         cashBalance.ManualAdjustment.Add(amountDec),
-			"final_balance":             cashBalance.FinalBalance.Add(amountDec),
-			"last_manual_adjustment_at": &now,
-			"updated_at":                now,
-			"version":                   gorm.Expr("version + 1"),
-		}
-
-		res := tx.Model(&models.CashBalance{}).
-			Where("id = ? AND version = ?", cashBalance.ID, cashBalance.Version).
-			Updates(updates)
-		if res.Error != nil {
-			return res.Error
+			"status":     adjustment.Status,
+			"decided_by": approver.ID,
+			"decided_at": now,
 		}
-		if res.RowsAffected == 0 {
-			return errors.New("cash balance was updated concurrently; please retry")
+		if note = strings.TrimSpace(note); note != "" {
+			adjustment.DecisionNote = &note
+			updates["decision_note"] = note
 		}
-
-		return nil
+		return tx.Model(adjustment).Updates(updates).Error
 	})
-
 	if err != nil {
 		return nil, err
 	}
-
 	return adjustment, nil
 }
 
-// calculateBalanceWithTx calculates balance within a transaction
-func (s *CashBalanceService) calculateBalanceWithTx(tx *gorm.DB, tenantID uint, branchID *uint, currency string) (models.Decimal, error) {
-	var balance models.Decimal
+// lockPendingAdjustment loads a pending cash adjustment for a decision and checks the approver may make it
+func (s *CashBalanceService) lockPendingAdjustment(tx *gorm.DB, tenantID, adjustmentID uint, approver *models.User) (*models.CashAdjustment, error) {
+	if !canApproveCashAdjustments(approver.Role) {
+		return nil, ErrCashAdjustmentApprovalForbidden
+	}
 
+	var adjustment models.CashAdjustment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", adjustmentID, tenantID).
+		First(&adjustment).Error; err != nil {
+		return nil, fmt.Errorf("cash adjustment not found: %w", err)
+	}
+	if adjustment.Status != models.CashAdjustmentStatusPending {
+		return nil, fmt.Errorf("cash adjustment has already been %s", strings.ToLower(adjustment.Status))
+	}
+	if adjustment.AdjustedBy == approver.ID {
+		return nil, ErrCashAdjustmentSelfApproval
+	}
+	return &adjustment, nil
+}
+
+// cashPaymentsScope selects the payments that make up a branch's auto-calculated balance in one currency
+func cashPaymentsScope(tx *gorm.DB, tenantID uint, branchID *uint, currency string) *gorm.DB {
+	// NOTE: transactions table does not have generic (currency, amount) columns.
+	// Cash balances are primarily affected by CASH payments; payments of deleted transactions no longer count.
 	query := tx.Model(&models.Payment{}).
 		Where("tenant_id = ? AND currency = ? AND payment_method = ? AND status = ?",
-			tenantID, currency, models.PaymentMethodCash, models.PaymentStatusCompleted)
+			tenantID, currency, models.PaymentMethodCash, models.PaymentStatusCompleted).
+		Where("transaction_id NOT IN (?)", tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.Transaction{}).Select("id").Where("deleted_at IS NOT NULL"))
+
+	// Filter by branch, including NULL branch balances
 	if branchID != nil {
-		query = query.Where("branch_id = ?", *branchID)
-	} else {
-		query = query.Where("branch_id IS NULL")
+		return query.Where("branch_id = ?", *branchID)
 	}
+	return query.Where("branch_id IS NULL")
+}
+
+// calculateBalanceWithTx calculates balance within a transaction
+func (s *CashBalanceService) calculateBalanceWithTx(tx *gorm.DB, tenantID uint, branchID *uint, currency string) (models.Decimal, error) {
+	var balance models.Decimal
 
-	err := query.Select("COALESCE(SUM(amount), 0)").Scan(&balance).Error
+	// Sum all completed cash payments
+	err := cashPaymentsScope(tx, tenantID, branchID, currency).Select("COALESCE(SUM(amount), 0)").Scan(&balance).Error
 	if err != nil {
 		return models.Zero(), err
 	}
@@ -255,8 +425,40 @@ func (s *CashBalanceService) calculateBalanceWithTx(tx *gorm.DB, tenantID uint,
 	return balance, nil
 }
 
-// UpdateCashBalance updates the cash balance transactionally
+// balanceAtWithTx works out what the cash balance stood at just before at: the cash payments made
+// before then, and its current manual adjustment less the adjustments applied since.
+func (s *CashBalanceService) balanceAtWithTx(tx *gorm.DB, cashBalance *models.CashBalance, at time.Time) (models.Decimal, models.Decimal, error) {
+	var autoBalance models.Decimal
+	err := cashPaymentsScope(tx, cashBalance.TenantID, cashBalance.BranchID, cashBalance.Currency).
+		Where("paid_at < ?", at).
+		Select("COALESCE(SUM(amount), 0)").Scan(&autoBalance).Error
+	if err != nil {
+		return models.Zero(), models.Zero(), err
+	}
+
+	var appliedSince models.Decimal
+	query := tx.Model(&models.CashAdjustment{}).
+		Where("tenant_id = ? AND currency = ? AND status = ? AND applied_at >= ?",
+			cashBalance.TenantID, cashBalance.Currency, models.CashAdjustmentStatusApplied, at)
+	if cashBalance.BranchID != nil {
+		query = query.Where("branch_id = ?", *cashBalance.BranchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+	if err := query.Select("COALESCE(SUM(amount), 0)").Scan(&appliedSince).Error; err != nil {
+		return models.Zero(), models.Zero(), err
+	}
+
+	return autoBalance, cashBalance.ManualAdjustment.Sub(appliedSince), nil
+}
+
+// UpdateCashBalance updates the cash balance transactionally. The adjustment is posted into today, so it is
+// rejected with ErrDayClosed once the branch has closed the day.
 func (s *CashBalanceService) UpdateCashBalance(tx *gorm.DB, tenantID uint, branchID *uint, currency string, amount float64, reason string, adjustedBy uint) error {
+	if err := ensureDayOpen(tx, tenantID, branchID, time.Now()); err != nil {
+		return err
+	}
+
 	// Get or create cash balance within transaction
 	var cashBalance models.CashBalance
 
@@ -434,3 +636,300 @@ func (s *CashBalanceService) GetActiveCurrencies(tenantID uint, branchID *uint)
 
 	return currencies, nil
 }
+
+// DenominationCount is one line of a physical cash count, e.g. 12 x 50
+type DenominationCount struct {
+	Denomination float64 `json:"denomination"`
+	Count        int     `json:"count"`
+}
+
+// DenominationMismatchError is returned when a denomination breakdown does not add up to its stated total
+type DenominationMismatchError struct {
+	Currency string
+	Expected models.Decimal
+	Counted  models.Decimal
+}
+
+func (e *DenominationMismatchError) Error() string {
+	return fmt.Sprintf("%s denominations total %s but the stated total is %s", e.Currency, e.Counted.String(), e.Expected.String())
+}
+
+// CashDenominationBreakdown is the denomination view of a single currency balance
+type CashDenominationBreakdown struct {
+	Currency      string                    `json:"currency"`
+	BranchID      *uint                     `json:"branchId"`
+	Balance       models.Decimal            `json:"balance"`
+	CountedTotal  models.Decimal            `json:"countedTotal"`
+	Consistent    bool                      `json:"consistent"` // CountedTotal matches the current balance
+	Denominations []models.CashDenomination `json:"denominations"`
+}
+
+// ValidateDenominations checks each line and that the breakdown sums to total.
+// It returns the counted sum.
+func ValidateDenominations(currency string, total models.Decimal, counts []DenominationCount) (models.Decimal, error) {
+	sum := models.Zero()
+	seen := make(map[string]bool)
+	for _, c := range counts {
+		if c.Denomination <= 0 {
+			return models.Zero(), fmt.Errorf("%s denomination must be positive", currency)
+		}
+		if c.Count < 0 {
+			return models.Zero(), fmt.Errorf("%s count for denomination %v cannot be negative", currency, c.Denomination)
+		}
+		denomination := models.NewDecimal(c.Denomination)
+		if seen[denomination.String()] {
+			return models.Zero(), fmt.Errorf("%s denomination %v listed more than once", currency, c.Denomination)
+		}
+		seen[denomination.String()] = true
+		sum = sum.Add(denomination.Mul(models.NewDecimal(float64(c.Count))))
+	}
+
+	if !sum.Sub(total).IsZero() {
+		return sum, &DenominationMismatchError{Currency: currency, Expected: total, Counted: sum}
+	}
+	return sum, nil
+}
+
+// buildDenominationRows converts validated counts into rows ready to insert
+func buildDenominationRows(tenantID uint, branchID *uint, currency string, reconciliationID *uint, counts []DenominationCount, countedBy uint) []models.CashDenomination {
+	rows := make([]models.CashDenomination, 0, len(counts))
+	for _, c := range counts {
+		rows = append(rows, models.CashDenomination{
+			TenantID:         tenantID,
+			BranchID:         branchID,
+			Currency:         currency,
+			ReconciliationID: reconciliationID,
+			Denomination:     models.NewDecimal(c.Denomination),
+			Count:            c.Count,
+			CountedBy:        countedBy,
+		})
+	}
+	return rows
+}
+
+// denominationScope restricts a query to the balance-level breakdown of one currency
+func denominationScope(db *gorm.DB, tenantID uint, branchID *uint, currency string) *gorm.DB {
+	query := db.Model(&models.CashDenomination{}).
+		Where("tenant_id = ? AND currency = ? AND reconciliation_id IS NULL", tenantID, currency)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+	return query
+}
+
+// SetBalanceDenominations replaces the denomination breakdown of a cash balance.
+// The breakdown must add up exactly to the balance's final balance.
+func (s *CashBalanceService) SetBalanceDenominations(tenantID uint, branchID *uint, currency string, counts []DenominationCount, countedBy uint) (*CashDenominationBreakdown, error) {
+	balance, err := s.GetOrCreateCashBalance(tenantID, branchID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ValidateDenominations(currency, balance.FinalBalance, counts); err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := denominationScope(tx, tenantID, branchID, currency).Delete(&models.CashDenomination{}).Error; err != nil {
+			return err
+		}
+		rows := buildDenominationRows(tenantID, branchID, currency, nil, counts, countedBy)
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBalanceDenominations(tenantID, branchID, currency)
+}
+
+// GetBalanceDenominations returns the recorded denomination breakdown of a cash balance
+func (s *CashBalanceService) GetBalanceDenominations(tenantID uint, branchID *uint, currency string) (*CashDenominationBreakdown, error) {
+	balance, err := s.GetOrCreateCashBalance(tenantID, branchID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.CashDenomination
+	if err := denominationScope(s.DB, tenantID, branchID, currency).Order("denomination DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counted := models.Zero()
+	for _, row := range rows {
+		counted = counted.Add(row.Denomination.Mul(models.NewDecimal(float64(row.Count))))
+	}
+
+	return &CashDenominationBreakdown{
+		Currency:      currency,
+		BranchID:      branchID,
+		Balance:       balance.FinalBalance,
+		CountedTotal:  counted,
+		Consistent:    len(rows) > 0 && counted.Sub(balance.FinalBalance).IsZero(),
+		Denominations: rows,
+	}, nil
+}
+
+// GetThresholds lists the tenant's minimum cash balance per currency
+func (s *CashBalanceService) GetThresholds(tenantID uint) ([]models.CashBalanceThreshold, error) {
+	var thresholds []models.CashBalanceThreshold
+	if err := s.DB.Where("tenant_id = ?", tenantID).Order("currency ASC").Find(&thresholds).Error; err != nil {
+		return nil, fmt.Errorf("failed to get cash thresholds: %w", err)
+	}
+	return thresholds, nil
+}
+
+// SetThreshold creates or replaces the minimum cash balance the tenant wants to hold in a currency
+func (s *CashBalanceService) SetThreshold(tenantID uint, currency string, minimum float64, userID uint) (*models.CashBalanceThreshold, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if minimum <= 0 {
+		return nil, errors.New("minimum balance must be greater than 0")
+	}
+
+	var threshold models.CashBalanceThreshold
+	err := s.DB.Where("tenant_id = ? AND currency = ?", tenantID, currency).First(&threshold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		threshold = models.CashBalanceThreshold{
+			TenantID:       tenantID,
+			Currency:       currency,
+			MinimumBalance: models.NewDecimal(minimum),
+			CreatedBy:      userID,
+		}
+		if err := s.DB.Create(&threshold).Error; err != nil {
+			return nil, fmt.Errorf("failed to create cash threshold: %w", err)
+		}
+		return &threshold, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := s.DB.Model(&threshold).Updates(map[string]interface{}{
+		"minimum_balance": models.NewDecimal(minimum),
+		"updated_at":      time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update cash threshold: %w", err)
+	}
+	return &threshold, nil
+}
+
+// DeleteThreshold stops low-cash alerts for a currency
+func (s *CashBalanceService) DeleteThreshold(tenantID uint, currency string) error {
+	result := s.DB.Where("tenant_id = ? AND currency = ?", tenantID, strings.ToUpper(strings.TrimSpace(currency))).
+		Delete(&models.CashBalanceThreshold{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete cash threshold: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cash threshold not found")
+	}
+	return nil
+}
+
+// GetApprovalLimits lists the tenant's cash adjustment approval limit per currency
+func (s *CashBalanceService) GetApprovalLimits(tenantID uint) ([]models.CashAdjustmentApprovalLimit, error) {
+	var limits []models.CashAdjustmentApprovalLimit
+	if err := s.DB.Where("tenant_id = ?", tenantID).Order("currency ASC").Find(&limits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get cash adjustment approval limits: %w", err)
+	}
+	return limits, nil
+}
+
+// GetApprovalLimit returns the approval limit for a currency, or nil if adjustments in it never need approval
+func (s *CashBalanceService) GetApprovalLimit(tenantID uint, currency string) (*models.CashAdjustmentApprovalLimit, error) {
+	var limit models.CashAdjustmentApprovalLimit
+	err := s.DB.Where("tenant_id = ? AND currency = ?", tenantID, strings.ToUpper(strings.TrimSpace(currency))).First(&limit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash adjustment approval limit: %w", err)
+	}
+	return &limit, nil
+}
+
+// SetApprovalLimit creates or replaces the largest adjustment in a currency that applies without a second
+// approver. Only owners and admins may change it, and every change is written to the audit log.
+func (s *CashBalanceService) SetApprovalLimit(tenantID uint, currency string, amount float64, user *models.User) (*models.CashAdjustmentApprovalLimit, error) {
+	if !canApproveCashAdjustments(user.Role) {
+		return nil, ErrApprovalLimitForbidden
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if amount < 0 {
+		return nil, errors.New("approval limit cannot be negative")
+	}
+
+	var limit models.CashAdjustmentApprovalLimit
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var previous interface{}
+		err := tx.Where("tenant_id = ? AND currency = ?", tenantID, currency).First(&limit).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			limit = models.CashAdjustmentApprovalLimit{
+				TenantID:  tenantID,
+				Currency:  currency,
+				Amount:    models.NewDecimal(amount),
+				CreatedBy: user.ID,
+			}
+			if err := tx.Create(&limit).Error; err != nil {
+				return fmt.Errorf("failed to create cash adjustment approval limit: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("database error: %w", err)
+		default:
+			previous = limit
+			if err := tx.Model(&limit).Updates(map[string]interface{}{
+				"amount":     models.NewDecimal(amount),
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update cash adjustment approval limit: %w", err)
+			}
+		}
+
+		return NewAuditService(tx).LogAction(user.ID, &tenantID, models.ActionSetCashApprovalLimit, "CashAdjustmentApprovalLimit",
+			strconv.FormatUint(uint64(limit.ID), 10), fmt.Sprintf("Set %s cash adjustment approval limit to %s", currency, limit.Amount.String()),
+			previous, limit, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+// DeleteApprovalLimit lets adjustments in a currency apply without approval again. Only owners and admins
+// may remove it, and the removal is written to the audit log.
+func (s *CashBalanceService) DeleteApprovalLimit(tenantID uint, currency string, user *models.User) error {
+	if !canApproveCashAdjustments(user.Role) {
+		return ErrApprovalLimitForbidden
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		var limit models.CashAdjustmentApprovalLimit
+		err := tx.Where("tenant_id = ? AND currency = ?", tenantID, currency).First(&limit).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrApprovalLimitNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if err := tx.Delete(&limit).Error; err != nil {
+			return fmt.Errorf("failed to delete cash adjustment approval limit: %w", err)
+		}
+
+		return NewAuditService(tx).LogAction(user.ID, &tenantID, models.ActionDeleteCashApprovalLimit, "CashAdjustmentApprovalLimit",
+			strconv.FormatUint(uint64(limit.ID), 10), fmt.Sprintf("Removed %s cash adjustment approval limit of %s", currency, limit.Amount.String()),
+			limit, nil, nil)
+	})
+}
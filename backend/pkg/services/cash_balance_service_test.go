@@ -0,0 +1,273 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestDenominations_RejectInconsistentCount verifies a breakdown that does not sum to its stated total is rejected
+func TestDenominations_RejectInconsistentCount(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Payment{}, &models.Transaction{},
+		&models.CashBalance{}, &models.CashAdjustment{}, &models.CashAdjustmentApprovalLimit{}, &models.CashDenomination{}, &models.DailyReconciliation{},
+		&models.DailyClose{})
+
+	tenant := newTestTenant(t, db, "Denomination Exchange")
+	user := newTestUser(t, db, tenant.ID, "teller@example.com", "tenant_owner")
+
+	cashService := NewCashBalanceService(db)
+	if _, err := cashService.CreateManualAdjustment(tenant.ID, nil, "USD", 1250, "Opening float", user.ID); err != nil {
+		t.Fatalf("Failed to seed balance: %v", err)
+	}
+
+	t.Run("BalanceMismatchRejected", func(t *testing.T) {
+		// 10 x 100 + 5 x 20 = 1100, balance is 1250
+		_, err := cashService.SetBalanceDenominations(tenant.ID, nil, "USD", []DenominationCount{
+			{Denomination: 100, Count: 10},
+			{Denomination: 20, Count: 5},
+		}, user.ID)
+
+		var mismatch *DenominationMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("Expected DenominationMismatchError, got: %v", err)
+		}
+		if mismatch.Counted.Float64() != 1100 || mismatch.Expected.Float64() != 1250 {
+			t.Errorf("Unexpected mismatch values: counted=%v expected=%v", mismatch.Counted, mismatch.Expected)
+		}
+
+		var count int64
+		db.Model(&models.CashDenomination{}).Count(&count)
+		if count != 0 {
+			t.Errorf("Expected no denominations to be saved, got %d", count)
+		}
+	})
+
+	t.Run("BalanceMatchAccepted", func(t *testing.T) {
+		breakdown, err := cashService.SetBalanceDenominations(tenant.ID, nil, "USD", []DenominationCount{
+			{Denomination: 100, Count: 12},
+			{Denomination: 20, Count: 2},
+			{Denomination: 10, Count: 1},
+		}, user.ID)
+		if err != nil {
+			t.Fatalf("Expected consistent breakdown to be accepted, got: %v", err)
+		}
+		if !breakdown.Consistent || len(breakdown.Denominations) != 3 {
+			t.Errorf("Expected 3 consistent denomination rows, got consistent=%v rows=%d", breakdown.Consistent, len(breakdown.Denominations))
+		}
+	})
+
+	t.Run("ReconciliationMismatchRejected", func(t *testing.T) {
+		reconciliationService := NewReconciliationService(db)
+		breakdownJSON, _ := json.Marshal(map[string]float64{"USD": 500})
+
+		reconciliation := &models.DailyReconciliation{
+			TenantID:          tenant.ID,
+			BranchID:          1,
+			Date:              time.Now(),
+			ClosingBalance:    500,
+			CurrencyBreakdown: string(breakdownJSON),
+			CreatedByUserID:   user.ID,
+		}
+
+		err := reconciliationService.CreateReconciliation(reconciliation, map[string][]DenominationCount{
+			"USD": {{Denomination: 50, Count: 9}},
+		})
+		var mismatch *DenominationMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("Expected DenominationMismatchError, got: %v", err)
+		}
+
+		var count int64
+		db.Model(&models.DailyReconciliation{}).Count(&count)
+		if count != 0 {
+			t.Errorf("Expected reconciliation not to be saved, got %d", count)
+		}
+	})
+}
+
+// TestCashAdjustment_ApprovalAboveLimit verifies an adjustment within its currency's limit applies at once while
+// a larger one leaves the balance untouched until a second approver approves it
+func TestCashAdjustment_ApprovalAboveLimit(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Payment{}, &models.Transaction{},
+		&models.CashBalance{}, &models.CashAdjustment{}, &models.CashAdjustmentApprovalLimit{}, &models.DailyClose{},
+		&models.AuditLog{})
+
+	tenant := newTestTenant(t, db, "Approval Exchange")
+	teller := newTestUser(t, db, tenant.ID, "teller@example.com", models.RoleTenantUser)
+	admin := newTestUser(t, db, tenant.ID, "admin@example.com", models.RoleTenantAdmin)
+
+	cashService := NewCashBalanceService(db)
+	if _, err := cashService.SetApprovalLimit(tenant.ID, "usd", 1000, admin); err != nil {
+		t.Fatalf("Failed to set approval limit: %v", err)
+	}
+	finalBalance := func() float64 {
+		balance, err := cashService.GetBalanceByCurrency(tenant.ID, nil, "USD")
+		if err != nil {
+			t.Fatalf("Failed to get balance: %v", err)
+		}
+		return balance.FinalBalance.Float64()
+	}
+
+	small, err := cashService.CreateManualAdjustment(tenant.ID, nil, "USD", 400, "Till top-up", teller.ID)
+	if err != nil {
+		t.Fatalf("Failed to create small adjustment: %v", err)
+	}
+	if small.Status != models.CashAdjustmentStatusApplied || finalBalance() != 400 {
+		t.Fatalf("Expected the below-limit adjustment to apply at once, got %s with balance %v", small.Status, finalBalance())
+	}
+
+	large, err := cashService.CreateManualAdjustment(tenant.ID, nil, "USD", -2500, "Cash sent to bank", teller.ID)
+	if err != nil {
+		t.Fatalf("Failed to create large adjustment: %v", err)
+	}
+	if large.Status != models.CashAdjustmentStatusPending || finalBalance() != 400 {
+		t.Fatalf("Expected the above-limit adjustment to wait for approval, got %s with balance %v", large.Status, finalBalance())
+	}
+
+	if _, err := cashService.ApproveAdjustment(tenant.ID, large.ID, teller); !errors.Is(err, ErrCashAdjustmentApprovalForbidden) {
+		t.Errorf("Expected a teller not to approve, got %v", err)
+	}
+	if _, err := cashService.ApproveAdjustment(tenant.ID+1, large.ID, admin); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected another tenant not to see the adjustment, got %v", err)
+	}
+
+	approved, err := cashService.ApproveAdjustment(tenant.ID, large.ID, admin)
+	if err != nil {
+		t.Fatalf("Failed to approve adjustment: %v", err)
+	}
+	if finalBalance() != -2100 || approved.BalanceBefore.Float64() != 400 || approved.BalanceAfter.Float64() != -2100 {
+		t.Errorf("Expected approval to apply the adjustment, got balance %v (%v -> %v)",
+			finalBalance(), approved.BalanceBefore.Float64(), approved.BalanceAfter.Float64())
+	}
+	var saved models.CashAdjustment
+	db.First(&saved, large.ID)
+	if saved.Status != models.CashAdjustmentStatusApplied || saved.AdjustedBy != teller.ID || saved.DecidedBy == nil || *saved.DecidedBy != admin.ID {
+		t.Errorf("Expected both the submitter and approver to be recorded, got %+v", saved)
+	}
+	if _, err := cashService.RejectAdjustment(tenant.ID, large.ID, admin, ""); err == nil {
+		t.Error("Expected a decided adjustment not to be decided again")
+	}
+
+	t.Run("RejectedNeverApplies", func(t *testing.T) {
+		pending, err := cashService.CreateManualAdjustment(tenant.ID, nil, "USD", 1500, "Unexplained surplus", admin.ID)
+		if err != nil {
+			t.Fatalf("Failed to create adjustment: %v", err)
+		}
+		if _, err := cashService.ApproveAdjustment(tenant.ID, pending.ID, admin); !errors.Is(err, ErrCashAdjustmentSelfApproval) {
+			t.Errorf("Expected the submitter not to approve their own adjustment, got %v", err)
+		}
+
+		owner := newTestUser(t, db, tenant.ID, "owner@example.com", models.RoleTenantOwner)
+		rejected, err := cashService.RejectAdjustment(tenant.ID, pending.ID, owner, "Recount first")
+		if err != nil {
+			t.Fatalf("Failed to reject adjustment: %v", err)
+		}
+		if rejected.Status != models.CashAdjustmentStatusRejected || finalBalance() != -2100 {
+			t.Errorf("Expected the rejected adjustment to leave the balance alone, got %s with balance %v", rejected.Status, finalBalance())
+		}
+	})
+}
+
+// TestApprovalLimit_OwnersAndAdminsOnly verifies a cashier can neither set nor remove an approval limit, and
+// that every change an admin makes is written to the audit log
+func TestApprovalLimit_OwnersAndAdminsOnly(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.CashAdjustmentApprovalLimit{}, &models.AuditLog{})
+
+	tenant := newTestTenant(t, db, "Limit Exchange")
+	cashier := newTestUser(t, db, tenant.ID, "cashier@limit.test", models.RoleTenantUser)
+	admin := newTestUser(t, db, tenant.ID, "admin@limit.test", models.RoleTenantAdmin)
+	cashService := NewCashBalanceService(db)
+
+	if _, err := cashService.SetApprovalLimit(tenant.ID, "USD", 1000000, cashier); !errors.Is(err, ErrApprovalLimitForbidden) {
+		t.Fatalf("Expected a cashier to be refused, got %v", err)
+	}
+	if _, err := cashService.SetApprovalLimit(tenant.ID, "USD", 1000, admin); err != nil {
+		t.Fatalf("Failed to set approval limit: %v", err)
+	}
+	if err := cashService.DeleteApprovalLimit(tenant.ID, "USD", cashier); !errors.Is(err, ErrApprovalLimitForbidden) {
+		t.Fatalf("Expected a cashier to be refused, got %v", err)
+	}
+	if limit, _ := cashService.GetApprovalLimit(tenant.ID, "USD"); limit == nil || limit.Amount.Float64() != 1000 {
+		t.Fatalf("Expected the admin's limit to stand, got %+v", limit)
+	}
+
+	if _, err := cashService.SetApprovalLimit(tenant.ID, "USD", 5000, admin); err != nil {
+		t.Fatalf("Failed to raise approval limit: %v", err)
+	}
+	if err := cashService.DeleteApprovalLimit(tenant.ID, "USD", admin); err != nil {
+		t.Fatalf("Failed to remove approval limit: %v", err)
+	}
+	if err := cashService.DeleteApprovalLimit(tenant.ID, "USD", admin); !errors.Is(err, ErrApprovalLimitNotFound) {
+		t.Errorf("Expected removing a missing limit to report it, got %v", err)
+	}
+
+	var logs []models.AuditLog
+	db.Where("tenant_id = ? AND entity_type = ?", tenant.ID, "CashAdjustmentApprovalLimit").Order("id").Find(&logs)
+	wantActions := []string{models.ActionSetCashApprovalLimit, models.ActionSetCashApprovalLimit, models.ActionDeleteCashApprovalLimit}
+	if len(logs) != len(wantActions) {
+		t.Fatalf("Expected %d audit entries, got %d", len(wantActions), len(logs))
+	}
+	for i, entry := range logs {
+		if entry.Action != wantActions[i] || entry.UserID != admin.ID {
+			t.Errorf("Audit entry %d: expected %s by %d, got %s by %d", i, wantActions[i], admin.ID, entry.Action, entry.UserID)
+		}
+	}
+	if logs[0].OldValues != nil || logs[1].OldValues == nil || !strings.Contains(*logs[1].OldValues, `"amount":1000`) {
+		t.Errorf("Expected the raise to record the previous limit, got %v then %v", logs[0].OldValues, logs[1].OldValues)
+	}
+}
+
+// TestUpdateCashBalance_IgnoresDeletedTransactionPayments verifies a balance first created inside a posting
+// leaves out payments of deleted transactions, the same as CalculateBalanceFromTransactions
+func TestUpdateCashBalance_IgnoresDeletedTransactionPayments(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Payment{}, &models.Transaction{},
+		&models.CashBalance{}, &models.CashAdjustment{}, &models.DailyClose{})
+
+	tenant := newTestTenant(t, db, "Deleted Payments Exchange")
+	user := newTestUser(t, db, tenant.ID, "teller@deleted.test", models.RoleTenantOwner)
+
+	pay := func(transactionID string, amount float64) {
+		transaction := &models.Transaction{ID: transactionID, TenantID: tenant.ID, ClientID: "client", PaymentMethod: "CASH",
+			SendCurrency: "CAD", SendAmount: models.NewDecimal(amount), ReceiveCurrency: "USD", ReceiveAmount: models.NewDecimal(amount)}
+		if err := db.Create(transaction).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		if err := db.Create(&models.Payment{TenantID: tenant.ID, TransactionID: transactionID, Amount: models.NewDecimal(amount),
+			Currency: "CAD", AmountInBase: models.NewDecimal(amount), PaymentMethod: models.PaymentMethodCash,
+			Status: models.PaymentStatusCompleted, PaidBy: user.ID, PaidAt: time.Now()}).Error; err != nil {
+			t.Fatalf("Failed to create payment: %v", err)
+		}
+	}
+	pay("txn-kept", 300)
+	pay("txn-deleted", 500)
+	if err := db.Delete(&models.Transaction{}, "id = ?", "txn-deleted").Error; err != nil {
+		t.Fatalf("Failed to delete transaction: %v", err)
+	}
+
+	service := NewCashBalanceService(db)
+	expected, err := service.CalculateBalanceFromTransactions(tenant.ID, nil, "CAD")
+	if err != nil {
+		t.Fatalf("Failed to calculate balance: %v", err)
+	}
+	if expected.Float64() != 300 {
+		t.Fatalf("Expected 300 from the kept transaction only, got %s", expected.String())
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return service.UpdateCashBalance(tx, tenant.ID, nil, "CAD", 0, "Recount", user.ID)
+	}); err != nil {
+		t.Fatalf("UpdateCashBalance failed: %v", err)
+	}
+	balance, err := service.GetBalanceByCurrency(tenant.ID, nil, "CAD")
+	if err != nil {
+		t.Fatalf("Failed to load balance: %v", err)
+	}
+	if balance.AutoCalculatedBalance.Float64() != expected.Float64() {
+		t.Errorf("Expected the new balance to start at %s, got %s", expected.String(), balance.AutoCalculatedBalance.String())
+	}
+}
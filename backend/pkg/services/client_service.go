@@ -0,0 +1,286 @@
+package services
+
+import (
+	"api/pkg/models"
+	"api/pkg/validation"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrClientImportHeader is returned when an import file has no header row or lacks a required column
+var ErrClientImportHeader = errors.New("client import file must have a header row with name and phone columns")
+
+// Client import row outcomes
+const (
+	ClientImportCreated     = "CREATED"      // Row was saved as a new client
+	ClientImportWouldCreate = "WOULD_CREATE" // Row is valid and would be saved outside a dry run
+	ClientImportInvalid     = "INVALID"      // Row failed validation
+	ClientImportDuplicate   = "DUPLICATE"    // Row matches an existing client or an earlier row in the file
+)
+
+// clientImportBatchSize is the number of clients saved per insert statement
+const clientImportBatchSize = 200
+
+// clientImportColumns maps accepted header spellings to the field they fill
+var clientImportColumns = map[string]string{
+	"name":         "name",
+	"full name":    "name",
+	"phone":        "phone",
+	"phone number": "phone",
+	"phonenumber":  "phone",
+	"phone_number": "phone",
+	"mobile":       "phone",
+	"email":        "email",
+	"e-mail":       "email",
+	"join date":    "joinDate",
+	"joindate":     "joinDate",
+	"join_date":    "joinDate",
+}
+
+// ClientService handles client operations that go beyond single-record CRUD
+type ClientService struct {
+	db *gorm.DB
+}
+
+// NewClientService creates a new ClientService
+func NewClientService(db *gorm.DB) *ClientService {
+	return &ClientService{db: db}
+}
+
+// ClientImportRow is the outcome of one data row of an import file
+type ClientImportRow struct {
+	Row         int               `json:"row"` // 1-based line number in the file, counting the header
+	Name        string            `json:"name" validate:"required,min=2"`
+	PhoneNumber string            `json:"phoneNumber" validate:"required,phone"`
+	Email       string            `json:"email,omitempty" validate:"omitempty,email"`
+	JoinDate    string            `json:"joinDate,omitempty"`
+	Status      string            `json:"status"`
+	ClientID    string            `json:"clientId,omitempty"`
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+// ClientImportResult summarises an import and lists the outcome of every row
+type ClientImportResult struct {
+	DryRun     bool              `json:"dryRun"`
+	TotalRows  int               `json:"totalRows"`
+	Valid      int               `json:"valid"`
+	Invalid    int               `json:"invalid"`
+	Duplicates int               `json:"duplicates"`
+	Created    int               `json:"created"`
+	Rows       []ClientImportRow `json:"rows"`
+}
+
+// ImportClientsCSV reads clients from a CSV file with a header row. Name and phone columns are required;
+// email and join date (YYYY-MM-DD) are optional. Each row is validated and checked against the tenant's
+// clients and earlier rows by phone number and email. In a dry run nothing is saved and valid rows are
+// reported as WOULD_CREATE; otherwise the valid rows are saved together and invalid ones are reported.
+func (s *ClientService) ImportClientsCSV(tenantID uint, reader io.Reader, dryRun bool) (*ClientImportResult, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, ErrClientImportHeader
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client import header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := clientImportColumns[key]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, ErrClientImportHeader
+	}
+	if _, ok := columns["phone"]; !ok {
+		return nil, ErrClientImportHeader
+	}
+
+	phones, emails, err := s.existingClientKeys(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ClientImportResult{DryRun: dryRun, Rows: make([]ClientImportRow, 0)}
+	pending := make([]models.Client, 0)
+	pendingRows := make([]int, 0)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read client import file: %w", err)
+			}
+			result.Rows = append(result.Rows, ClientImportRow{
+				Row:    parseErr.StartLine,
+				Status: ClientImportInvalid,
+				Errors: map[string]string{"row": parseErr.Err.Error()},
+			})
+			result.TotalRows++
+			result.Invalid++
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		result.TotalRows++
+
+		line, _ := csvReader.FieldPos(0)
+		row := ClientImportRow{
+			Row:         line,
+			Name:        importField(record, columns, "name"),
+			PhoneNumber: importField(record, columns, "phone"),
+			Email:       importField(record, columns, "email"),
+			JoinDate:    importField(record, columns, "joinDate"),
+		}
+		client, errs := validateClientImportRow(&row)
+		if errs != nil {
+			row.Status = ClientImportInvalid
+			row.Errors = errs
+			result.Invalid++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+
+		phoneKey := normalizePhone(row.PhoneNumber)
+		emailKey := strings.ToLower(row.Email)
+		switch {
+		case phones[phoneKey]:
+			row.Status = ClientImportDuplicate
+			row.Errors = map[string]string{"phoneNumber": "A client with this phone number already exists"}
+		case emailKey != "" && emails[emailKey]:
+			row.Status = ClientImportDuplicate
+			row.Errors = map[string]string{"email": "A client with this email already exists"}
+		}
+		if row.Status == ClientImportDuplicate {
+			result.Duplicates++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		phones[phoneKey] = true
+		if emailKey != "" {
+			emails[emailKey] = true
+		}
+
+		result.Valid++
+		row.Status = ClientImportWouldCreate
+		if !dryRun {
+			client.ID = uuid.New().String()
+			client.TenantID = tenantID
+			row.ClientID = client.ID
+			pending = append(pending, *client)
+			pendingRows = append(pendingRows, len(result.Rows))
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	if dryRun || len(pending) == 0 {
+		return result, nil
+	}
+
+	// Batches keep each insert under the database's bind-parameter limit; the transaction keeps the import
+	// all-or-nothing
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&pending, clientImportBatchSize).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported clients: %w", err)
+	}
+	for _, i := range pendingRows {
+		result.Rows[i].Status = ClientImportCreated
+	}
+	result.Created = len(pending)
+	return result, nil
+}
+
+// existingClientKeys returns the normalised phone numbers and lower-cased emails of the tenant's clients
+func (s *ClientService) existingClientKeys(tenantID uint) (map[string]bool, map[string]bool, error) {
+	var clients []models.Client
+	if err := s.db.Select("phone_number", "email").Where("tenant_id = ?", tenantID).Find(&clients).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load existing clients: %w", err)
+	}
+	phones := make(map[string]bool, len(clients))
+	emails := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		phones[normalizePhone(client.PhoneNumber)] = true
+		if client.Email != nil && *client.Email != "" {
+			emails[strings.ToLower(strings.TrimSpace(*client.Email))] = true
+		}
+	}
+	return phones, emails, nil
+}
+
+// validateClientImportRow checks a row's fields and builds the client it describes
+func validateClientImportRow(row *ClientImportRow) (*models.Client, map[string]string) {
+	errs := validation.ValidateStruct(row)
+	if errs == nil {
+		errs = make(map[string]string)
+	}
+
+	client := &models.Client{Name: row.Name, PhoneNumber: row.PhoneNumber}
+	if row.Email != "" {
+		email := row.Email
+		client.Email = &email
+	}
+	if row.JoinDate != "" {
+		joinDate, err := time.Parse("2006-01-02", row.JoinDate)
+		if err != nil {
+			errs["joinDate"] = "Must be a date in YYYY-MM-DD format"
+		} else {
+			client.JoinDate = joinDate
+		}
+	} else {
+		client.JoinDate = time.Now()
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return client, nil
+}
+
+// importField returns the trimmed value of a mapped column, or "" if the column is absent or the row is short
+func importField(record []string, columns map[string]int, field string) string {
+	i, ok := columns[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// isBlankRecord reports whether every cell of a CSV record is empty
+func isBlankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePhone strips formatting so numbers written differently compare equal
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
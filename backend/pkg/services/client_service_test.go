@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const clientImportCSV = `Name,Phone Number,Email,Join Date
+Sara Ahmadi,+1-416-555-0101,sara@example.com,2024-03-01
+X,+1-416-555-0102,,
+Reza Karimi,not a phone,reza@example.com,
+Existing Twin,(416) 555-0100,,
+Mina Rostami,416-555-0103,mina@,
+Ali Moradi,416.555.0104,ali@example.com,01/02/2024
+
+Omid Jafari,+1 416 555 0105,,
+Omid Again,+1-416-555-0105,,
+`
+
+// TestClientImport_DryRunAndCommit verifies a mixed file is validated row by row, that a dry run saves nothing,
+// and that a committed import saves only the valid rows
+func TestClientImport_DryRunAndCommit(t *testing.T) {
+	db := setupTestDB(t)
+	defer services.ResetGlobalCacheService()
+
+	tenant := models.Tenant{Name: "Import Tenant"}
+	db.Create(&tenant)
+	db.Create(&models.Client{ID: uuid.New().String(), TenantID: tenant.ID, Name: "Existing Client", PhoneNumber: "416-555-0100"})
+	other := models.Tenant{Name: "Other Tenant"}
+	db.Create(&other)
+	db.Create(&models.Client{ID: uuid.New().String(), TenantID: other.ID, Name: "Other Client", PhoneNumber: "+1 416 555 0101"})
+
+	clientService := services.NewClientService(db)
+
+	preview, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader(clientImportCSV), true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if preview.TotalRows != 8 || preview.Valid != 2 || preview.Invalid != 4 || preview.Duplicates != 2 || preview.Created != 0 {
+		t.Errorf("Expected 8 rows (2 valid, 4 invalid, 2 duplicate, none created), got %+v", preview)
+	}
+
+	wantStatus := map[int]string{
+		2:  services.ClientImportWouldCreate, // Duplicate only in another tenant
+		3:  services.ClientImportInvalid,     // Name too short
+		4:  services.ClientImportInvalid,     // Bad phone
+		5:  services.ClientImportDuplicate,   // Same number as the existing client, formatted differently
+		6:  services.ClientImportInvalid,     // Bad email
+		7:  services.ClientImportInvalid,     // Bad join date
+		9:  services.ClientImportWouldCreate,
+		10: services.ClientImportDuplicate, // Same number as row 9
+	}
+	for _, row := range preview.Rows {
+		if want, ok := wantStatus[row.Row]; !ok || row.Status != want {
+			t.Errorf("Row %d: expected %q, got %q (%v)", row.Row, want, row.Status, row.Errors)
+		}
+	}
+	if errs := preview.Rows[1].Errors; errs["name"] == "" {
+		t.Errorf("Expected a name error on row 3, got %v", errs)
+	}
+	if errs := preview.Rows[2].Errors; errs["phoneNumber"] == "" {
+		t.Errorf("Expected a phone error on row 4, got %v", errs)
+	}
+	if errs := preview.Rows[4].Errors; errs["email"] == "" {
+		t.Errorf("Expected an email error on row 6, got %v", errs)
+	}
+
+	var count int64
+	db.Model(&models.Client{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+	if count != 1 {
+		t.Fatalf("Expected a dry run to save nothing, found %d clients", count)
+	}
+
+	result, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader(clientImportCSV), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Created != 2 || result.Invalid != 4 || result.Duplicates != 2 {
+		t.Errorf("Expected 2 created, 4 invalid and 2 duplicates, got %+v", result)
+	}
+	db.Model(&models.Client{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+	if count != 3 {
+		t.Errorf("Expected 3 clients after the import, found %d", count)
+	}
+
+	var sara models.Client
+	if err := db.Where("tenant_id = ? AND name = ?", tenant.ID, "Sara Ahmadi").First(&sara).Error; err != nil {
+		t.Fatalf("Expected the imported client to be saved: %v", err)
+	}
+	if sara.ID != result.Rows[0].ClientID || sara.Email == nil || *sara.Email != "sara@example.com" ||
+		sara.JoinDate.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("Imported client does not match its row: %+v", sara)
+	}
+
+	// Running the same file again finds every valid row already imported
+	again, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader(clientImportCSV), false)
+	if err != nil {
+		t.Fatalf("Re-import failed: %v", err)
+	}
+	if again.Created != 0 || again.Duplicates != 4 {
+		t.Errorf("Expected a re-import to create nothing and report 4 duplicates, got %+v", again)
+	}
+
+	if _, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader("Email\nsomeone@example.com\n"), true); !errors.Is(err, services.ErrClientImportHeader) {
+		t.Errorf("Expected a missing name and phone header to be rejected, got %v", err)
+	}
+}
+
+// TestClientImport_LargeFileSavedInBatchesAtomically verifies a file larger than one insert batch is saved in
+// full, and that a batch failing part-way through leaves none of the file's clients saved
+func TestClientImport_LargeFileSavedInBatchesAtomically(t *testing.T) {
+	db := setupTestDB(t)
+	defer services.ResetGlobalCacheService()
+
+	tenant := models.Tenant{Name: "Bulk Import Tenant"}
+	db.Create(&tenant)
+	clientService := services.NewClientService(db)
+
+	const rows = 450
+	file := func(offset int) string {
+		var sb strings.Builder
+		sb.WriteString("Name,Phone\n")
+		for i := offset; i < offset+rows; i++ {
+			fmt.Fprintf(&sb, "Client %04d,+1 416 555 %04d\n", i, i)
+		}
+		return sb.String()
+	}
+	count := func() int64 {
+		var n int64
+		db.Model(&models.Client{}).Where("tenant_id = ?", tenant.ID).Count(&n)
+		return n
+	}
+
+	result, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader(file(0)), false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Created != rows || count() != rows {
+		t.Errorf("Expected all %d clients to be saved, reported %d and found %d", rows, result.Created, count())
+	}
+
+	// Fail an insert in the last batch; the batches before it must be rolled back with it
+	if err := db.Exec(`CREATE TRIGGER reject_client BEFORE INSERT ON clients WHEN NEW.name = 'Client 0949'
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`).Error; err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	if _, err := clientService.ImportClientsCSV(tenant.ID, strings.NewReader(file(500)), false); err == nil {
+		t.Fatal("Expected the failing import to return an error")
+	}
+	if n := count(); n != rows {
+		t.Errorf("Expected a failed import to save nothing, found %d clients instead of %d", n, rows)
+	}
+}
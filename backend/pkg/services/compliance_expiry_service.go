@@ -0,0 +1,189 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Compliance expiry actions
+const (
+	ComplianceExpiryActionRemind = "REMIND"
+	ComplianceExpiryActionExpire = "EXPIRE"
+)
+
+// ComplianceExpiryService reminds staff of approved KYC records about to lapse and downgrades lapsed
+// records to EXPIRED so the customer must re-verify before transacting again
+type ComplianceExpiryService struct {
+	db                *gorm.DB
+	settingsService   *TenantSettingsService
+	complianceService *ComplianceService
+	ticketService     *TicketService
+}
+
+// NewComplianceExpiryService creates a new ComplianceExpiryService
+func NewComplianceExpiryService(db *gorm.DB) *ComplianceExpiryService {
+	return &ComplianceExpiryService{
+		db:                db,
+		settingsService:   NewTenantSettingsService(db),
+		complianceService: NewComplianceService(db),
+		ticketService:     NewTicketService(db),
+	}
+}
+
+// ComplianceExpiryResult is an action taken on an expiring or expired compliance record
+type ComplianceExpiryResult struct {
+	ComplianceID uint       `json:"complianceId"`
+	CustomerID   uint       `json:"customerId"`
+	Action       string     `json:"action"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+}
+
+// complianceRenewalDue returns the earlier of the record's verification and ID expiry, if any
+func complianceRenewalDue(compliance *models.CustomerCompliance) *time.Time {
+	due := compliance.ExpiresAt
+	if compliance.IDExpiryDate != nil && (due == nil || compliance.IDExpiryDate.Before(*due)) {
+		due = compliance.IDExpiryDate
+	}
+	return due
+}
+
+// CheckTenant downgrades the tenant's lapsed approved records and sends one renewal reminder for each
+// record expiring within the tenant's lead time, returning what was done
+func (s *ComplianceExpiryService) CheckTenant(tenantID uint, now time.Time) ([]ComplianceExpiryResult, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	horizon := now
+	if settings.KYCRenewalReminderDays > 0 {
+		horizon = now.AddDate(0, 0, settings.KYCRenewalReminderDays)
+	}
+
+	var records []models.CustomerCompliance
+	if err := s.db.Preload("Customer").
+		Where("tenant_id = ? AND status = ?", tenantID, models.ComplianceStatusApproved).
+		Where("(expires_at IS NOT NULL AND expires_at <= ?) OR (id_expiry_date IS NOT NULL AND id_expiry_date <= ?)", horizon, horizon).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	var results []ComplianceExpiryResult
+	for i := range records {
+		compliance := &records[i]
+		action := ComplianceExpiryActionRemind
+		if _, expired := complianceExpiry(compliance, now); expired {
+			action = ComplianceExpiryActionExpire
+		} else if settings.KYCRenewalReminderDays <= 0 || compliance.RenewalReminderSent {
+			continue
+		}
+
+		done, err := s.apply(compliance, action, settings, now)
+		if err != nil {
+			log.Printf("⚠️  Compliance record %d: %s failed: %v", compliance.ID, action, err)
+			continue
+		}
+		if done {
+			results = append(results, ComplianceExpiryResult{
+				ComplianceID: compliance.ID,
+				CustomerID:   compliance.CustomerID,
+				Action:       action,
+				ExpiresAt:    complianceRenewalDue(compliance),
+			})
+		}
+	}
+	return results, nil
+}
+
+// apply downgrades or reminds one record, guarding the update so concurrent runs act only once
+func (s *ComplianceExpiryService) apply(compliance *models.CustomerCompliance, action string, settings *models.TenantSettings, now time.Time) (bool, error) {
+	due := complianceRenewalDue(compliance)
+	dueDate := ""
+	if due != nil {
+		dueDate = due.Format("2006-01-02")
+	}
+	customerName := fmt.Sprintf("customer #%d", compliance.CustomerID)
+	if compliance.Customer != nil {
+		customerName = compliance.Customer.FullName
+	}
+
+	var result *gorm.DB
+	var issue string
+	if action == ComplianceExpiryActionExpire {
+		result = s.db.Model(&models.CustomerCompliance{}).
+			Where("id = ? AND status = ?", compliance.ID, models.ComplianceStatusApproved).
+			Updates(map[string]interface{}{"status": models.ComplianceStatusExpired, "updated_at": now})
+		issue = fmt.Sprintf("KYC for %s lapsed on %s and was moved to EXPIRED. The customer must re-verify before new transactions.", customerName, dueDate)
+	} else {
+		result = s.db.Model(&models.CustomerCompliance{}).
+			Where("id = ? AND renewal_reminder_sent = ?", compliance.ID, false).
+			Updates(map[string]interface{}{"renewal_reminder_sent": true, "updated_at": now})
+		issue = fmt.Sprintf("KYC for %s expires on %s. Collect updated documents before then.", customerName, dueDate)
+	}
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if action == ComplianceExpiryActionExpire {
+		s.complianceService.logAction(compliance.ID, compliance.TenantID, "STATUS_CHANGE",
+			string(models.ComplianceStatusApproved), string(models.ComplianceStatusExpired), nil, true)
+	} else {
+		s.complianceService.logAction(compliance.ID, compliance.TenantID, "RENEWAL_REMINDER", "", dueDate, nil, true)
+	}
+
+	if settings.KYCExpiryTickets {
+		s.raiseTicket(compliance, issue)
+	}
+	return true, nil
+}
+
+// raiseTicket opens a compliance ticket on behalf of the tenant owner; failures are only logged
+func (s *ComplianceExpiryService) raiseTicket(compliance *models.CustomerCompliance, issue string) {
+	var tenant models.Tenant
+	if err := s.db.Select("id", "owner_id").First(&tenant, compliance.TenantID).Error; err != nil {
+		log.Printf("⚠️  Could not load tenant %d for compliance expiry ticket: %v", compliance.TenantID, err)
+		return
+	}
+	if _, err := s.ticketService.CreateQuickTicket(tenant.ID, tenant.OwnerID, "compliance", compliance.ID, issue); err != nil {
+		log.Printf("⚠️  Failed to create ticket for compliance record %d: %v", compliance.ID, err)
+	}
+}
+
+// CheckAll runs the compliance expiry check for every active tenant
+func (s *ComplianceExpiryService) CheckAll(now time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, now); err != nil {
+			log.Printf("⚠️  Compliance expiry check failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start runs the compliance expiry check on the given interval in the background
+func (s *ComplianceExpiryService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Compliance expiry scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now()); err != nil {
+				log.Printf("⚠️  Compliance expiry check failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestComplianceExpiry_DowngradesLapsedRecords verifies lapsed approved records drop to EXPIRED with an
+// audit entry and a ticket, records inside the lead time are reminded once, and valid ones are left alone
+func TestComplianceExpiry_DowngradesLapsedRecords(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{}))
+
+	owner := models.User{Email: "expiry-owner@example.com", Role: models.RoleTenantOwner}
+	db.Create(&owner)
+	tenant := models.Tenant{Name: "Expiry Tenant", OwnerID: owner.ID}
+	db.Create(&tenant)
+
+	reminderDays, tickets := 14, true
+	if _, err := services.NewTenantSettingsService(db).UpdateSettings(tenant.ID, services.UpdateTenantSettingsRequest{
+		KYCRenewalReminderDays: &reminderDays,
+		KYCExpiryTickets:       &tickets,
+	}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	now := time.Now()
+	newRecord := func(phone string, expiresAt time.Time) *models.CustomerCompliance {
+		customer := &models.Customer{FullName: "Customer " + phone, Phone: phone}
+		db.Create(customer)
+		compliance := &models.CustomerCompliance{
+			TenantID:   tenant.ID,
+			CustomerID: customer.ID,
+			Status:     models.ComplianceStatusApproved,
+			ExpiresAt:  &expiresAt,
+		}
+		db.Create(compliance)
+		return compliance
+	}
+	lapsed := newRecord("+14165550301", now.AddDate(0, 0, -1))
+	expiring := newRecord("+14165550302", now.AddDate(0, 0, 10))
+	valid := newRecord("+14165550303", now.AddDate(0, 6, 0))
+
+	service := services.NewComplianceExpiryService(db)
+	results, err := service.CheckTenant(tenant.ID, now)
+	if err != nil {
+		t.Fatalf("Failed to check compliance expiry: %v", err)
+	}
+	actions := map[uint]string{}
+	for _, result := range results {
+		actions[result.ComplianceID] = result.Action
+	}
+	if len(results) != 2 || actions[lapsed.ID] != services.ComplianceExpiryActionExpire || actions[expiring.ID] != services.ComplianceExpiryActionRemind {
+		t.Fatalf("Expected the lapsed record to expire and the expiring one to be reminded, got %+v", results)
+	}
+
+	db.First(lapsed, lapsed.ID)
+	db.First(expiring, expiring.ID)
+	db.First(valid, valid.ID)
+	if lapsed.Status != models.ComplianceStatusExpired {
+		t.Errorf("Expected the lapsed record to be EXPIRED, got %s", lapsed.Status)
+	}
+	if expiring.Status != models.ComplianceStatusApproved || !expiring.RenewalReminderSent {
+		t.Errorf("Expected the expiring record to stay APPROVED with a reminder sent, got %s (%v)", expiring.Status, expiring.RenewalReminderSent)
+	}
+	if valid.Status != models.ComplianceStatusApproved || valid.RenewalReminderSent {
+		t.Errorf("Expected the valid record to be untouched, got %s (%v)", valid.Status, valid.RenewalReminderSent)
+	}
+
+	var entry models.ComplianceAuditLog
+	if err := db.Where("customer_compliance_id = ? AND action = ?", lapsed.ID, "STATUS_CHANGE").First(&entry).Error; err != nil {
+		t.Fatalf("Expected the downgrade in the compliance audit log: %v", err)
+	}
+	if entry.PreviousValue != string(models.ComplianceStatusApproved) || entry.NewValue != string(models.ComplianceStatusExpired) || !entry.PerformedBySystem {
+		t.Errorf("Unexpected audit entry %+v", entry)
+	}
+	var validEntries int64
+	db.Model(&models.ComplianceAuditLog{}).Where("customer_compliance_id = ?", valid.ID).Count(&validEntries)
+	if validEntries != 0 {
+		t.Errorf("Expected no audit entries for the valid record, got %d", validEntries)
+	}
+
+	var ticketCount int64
+	db.Model(&models.Ticket{}).Where("tenant_id = ? AND category = ?", tenant.ID, models.TicketCategoryCompliance).Count(&ticketCount)
+	if ticketCount != 2 {
+		t.Errorf("Expected a compliance ticket for the expiry and the reminder, got %d", ticketCount)
+	}
+
+	// A second run finds nothing new to do
+	results, err = service.CheckTenant(tenant.ID, now)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected the second run to do nothing, got %+v (%v)", results, err)
+	}
+}
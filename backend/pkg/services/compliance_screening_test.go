@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"testing"
+)
+
+// TestScreenCustomer verifies a watchlist hit sends the compliance record to review and a clean
+// screening leaves its status and earlier match flags alone
+func TestScreenCustomer(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Screening Tenant"}
+	db.Create(&tenant)
+	officer := models.User{Email: "screening-officer@example.com", TenantID: &tenant.ID, Role: models.RoleTenantAdmin}
+	db.Create(&officer)
+
+	service := services.NewComplianceService(db)
+	provider := services.NewMockScreeningProvider()
+	provider.SetMockHit("Viktor Blacklisted", services.AMLMatchResult{
+		MatchType:  "SANCTIONS",
+		MatchScore: 0.92,
+		MatchName:  "Viktor Blacklisted",
+		ListName:   "OFAC SDN",
+	})
+
+	newCompliance := func(name, phone string, status models.ComplianceStatus) *models.CustomerCompliance {
+		customer := &models.Customer{FullName: name, Phone: phone}
+		db.Create(customer)
+		compliance, err := service.GetOrCreateCompliance(tenant.ID, customer.ID)
+		if err != nil {
+			t.Fatalf("Failed to create compliance record: %v", err)
+		}
+		db.Model(compliance).Update("status", status)
+		return compliance
+	}
+
+	flagged := newCompliance("Viktor Blacklisted", "+14165550201", models.ComplianceStatusApproved)
+	result, err := service.ScreenCustomer(tenant.ID, flagged.ID, "", provider, &officer.ID)
+	if err != nil {
+		t.Fatalf("Failed to screen customer: %v", err)
+	}
+	if result.Status != services.ScreeningStatusPotentialMatch || len(result.Matches) != 1 {
+		t.Fatalf("Expected one potential match, got %+v", result)
+	}
+	db.First(flagged, flagged.ID)
+	if flagged.Status != models.ComplianceStatusInReview || !flagged.SanctionsMatch || flagged.PEPMatch || flagged.AMLScreenedAt == nil {
+		t.Errorf("Expected an IN_REVIEW record with a sanctions match, got status %s, sanctions %v, PEP %v",
+			flagged.Status, flagged.SanctionsMatch, flagged.PEPMatch)
+	}
+
+	clean := newCompliance("Jane Ordinary", "+14165550202", models.ComplianceStatusApproved)
+	result, err = service.ScreenCustomer(tenant.ID, clean.ID, "1985-04-12", provider, &officer.ID)
+	if err != nil {
+		t.Fatalf("Failed to screen customer: %v", err)
+	}
+	if result.Status != services.ScreeningStatusClear {
+		t.Errorf("Expected a clear screening, got %s", result.Status)
+	}
+	db.First(clean, clean.ID)
+	if clean.Status != models.ComplianceStatusApproved || clean.SanctionsMatch || clean.AMLScreenedAt == nil {
+		t.Errorf("Expected the APPROVED status to be kept with no match, got %s (sanctions %v)", clean.Status, clean.SanctionsMatch)
+	}
+
+	// A clean rescreen must not clear the flag the earlier hit raised
+	provider.Hits = map[string][]services.AMLMatchResult{}
+	if _, err := service.ScreenCustomer(tenant.ID, flagged.ID, "", provider, &officer.ID); err != nil {
+		t.Fatalf("Failed to rescreen customer: %v", err)
+	}
+	db.First(flagged, flagged.ID)
+	if !flagged.SanctionsMatch {
+		t.Error("Expected a clean rescreen to keep the sanctions match for a reviewer to clear")
+	}
+
+	if _, err := service.ScreenCustomer(tenant.ID+1, clean.ID, "", provider, &officer.ID); err == nil {
+		t.Error("Expected another tenant not to screen the record")
+	}
+}
@@ -2,12 +2,30 @@ package services
 
 import (
 	"api/pkg/models"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrComplianceExpired is returned when a customer's KYC has lapsed and must be renewed before transacting
+var ErrComplianceExpired = errors.New("customer KYC has expired")
+
+// ErrComplianceApprovalRequired is returned when an operation needs the customer's KYC to be approved first
+var ErrComplianceApprovalRequired = errors.New("customer KYC approval required")
+
+// ErrNotBranchComplianceOfficer is returned when a compliance officer asks for another branch's reviews
+var ErrNotBranchComplianceOfficer = errors.New("you are not the compliance officer for this branch")
+
+// ErrComplianceRecordNotFound is returned when the compliance record does not exist in the tenant
+var ErrComplianceRecordNotFound = errors.New("compliance record not found")
+
 // ComplianceService handles KYC/AML verification operations
 type ComplianceService struct {
 	DB *gorm.DB
@@ -29,6 +47,31 @@ type ComplianceCheckResult struct {
 	MonthlyUsage   float64 `json:"monthlyUsage"`
 	DailyLimit     float64 `json:"dailyLimit"`
 	MonthlyLimit   float64 `json:"monthlyLimit"`
+
+	// Review flags raised by the checks, e.g. STRUCTURING_SUSPECTED
+	Flags       []string         `json:"flags,omitempty"`
+	Structuring *StructuringFlag `json:"structuring,omitempty"`
+}
+
+// ComplianceFlagStructuring marks a transaction that, with the customer's recent sub-threshold
+// transactions, reaches the reporting threshold
+const ComplianceFlagStructuring = "STRUCTURING_SUSPECTED"
+
+// StructuringFlag describes the window in which a customer's transactions reached the reporting threshold
+type StructuringFlag struct {
+	Currency           string  `json:"currency"`
+	WindowHours        int     `json:"windowHours"`
+	TransactionCount   int     `json:"transactionCount"` // Including the transaction being checked
+	Total              float64 `json:"total"`
+	ReportingThreshold float64 `json:"reportingThreshold"`
+}
+
+// StructuringRuleRequest is the body for setting a structuring rule
+type StructuringRuleRequest struct {
+	Currency           string  `json:"currency"` // "*" for currencies without their own rule
+	ReportingThreshold float64 `json:"reportingThreshold"`
+	ShortWindowHours   int     `json:"shortWindowHours"`
+	LongWindowDays     int     `json:"longWindowDays"`
 }
 
 // GetOrCreateCompliance gets or creates a compliance record for a customer
@@ -55,6 +98,19 @@ func (s *ComplianceService) GetOrCreateCompliance(tenantID, customerID uint) (*m
 	return &compliance, nil
 }
 
+// ViewCompliance returns a customer's compliance record for display to a user, recording the view
+// in the audit log when the tenant audits reads of compliance records
+func (s *ComplianceService) ViewCompliance(tenantID, customerID, userID uint, r *http.Request) (*models.CustomerCompliance, error) {
+	compliance, err := s.GetOrCreateCompliance(tenantID, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := NewAuditService(s.DB).LogRead(tenantID, userID, AuditEntityCustomerCompliance, fmt.Sprintf("%d", compliance.ID), r); err != nil {
+		log.Printf("⚠️  Failed to audit compliance view: %v", err)
+	}
+	return compliance, nil
+}
+
 // GetComplianceByCustomer retrieves compliance record for a customer
 func (s *ComplianceService) GetComplianceByCustomer(tenantID, customerID uint) (*models.CustomerCompliance, error) {
 	var compliance models.CustomerCompliance
@@ -148,9 +204,250 @@ func (s *ComplianceService) CheckTransactionCompliance(tenantID, customerID uint
 		return result, nil
 	}
 
+	// Check 8: Structuring - sub-threshold transactions that together reach the reporting threshold
+	flag, err := s.checkStructuring(tenantID, customerID, amount, currency, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if flag != nil {
+		result.RequiresReview = true
+		result.Flags = append(result.Flags, ComplianceFlagStructuring)
+		result.Structuring = flag
+	}
+
 	return result, nil
 }
 
+// checkStructuring flags an amount below the reporting threshold that, added to the customer's other
+// sub-threshold transactions in the same currency, reaches the threshold within the rule's short or long window
+func (s *ComplianceService) checkStructuring(tenantID, customerID uint, amount float64, currency string, now time.Time) (*StructuringFlag, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	rule, err := s.GetStructuringRule(tenantID, currency)
+	if err != nil || rule == nil || !rule.ReportingThreshold.IsPositive() {
+		return nil, err
+	}
+	threshold := rule.ReportingThreshold
+	current := models.NewDecimal(amount)
+	if !current.LessThan(threshold) {
+		// A single transaction at the threshold is reported on its own, not structured
+		return nil, nil
+	}
+
+	windows := make([]time.Duration, 0, 2)
+	if rule.ShortWindowHours > 0 {
+		windows = append(windows, time.Duration(rule.ShortWindowHours)*time.Hour)
+	}
+	if rule.LongWindowDays > 0 {
+		windows = append(windows, time.Duration(rule.LongWindowDays)*24*time.Hour)
+	}
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	longest := windows[len(windows)-1]
+	if windows[0] > longest {
+		longest = windows[0]
+	}
+
+	var customer models.Customer
+	if err := s.DB.Select("id", "phone").First(&customer, customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if customer.Phone == "" {
+		// Without a phone number the customer's transactions cannot be told apart from other clients'
+		return nil, nil
+	}
+
+	// The customer's transactions are those of the clients sharing their phone number
+	var recent []models.Transaction
+	err = s.DB.Select("send_amount", "transaction_date").
+		Where("tenant_id = ? AND send_currency = ? AND status <> ? AND transaction_date >= ? AND transaction_date <= ?",
+			tenantID, currency, models.StatusCancelled, now.Add(-longest), now).
+		Where("client_id IN (?)", s.DB.Model(&models.Client{}).Select("id").
+			Where("tenant_id = ? AND phone_number = ?", tenantID, customer.Phone)).
+		Find(&recent).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		since := now.Add(-window)
+		total, count := current, 1
+		for _, transaction := range recent {
+			if transaction.TransactionDate.Before(since) || !transaction.SendAmount.LessThan(threshold) {
+				continue
+			}
+			total = total.Add(transaction.SendAmount)
+			count++
+		}
+		if count > 1 && !total.LessThan(threshold) {
+			return &StructuringFlag{
+				Currency:           currency,
+				WindowHours:        int(window / time.Hour),
+				TransactionCount:   count,
+				Total:              total.Round(2).Float64(),
+				ReportingThreshold: threshold.Float64(),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListStructuringRules returns the tenant's structuring rules ordered by currency
+func (s *ComplianceService) ListStructuringRules(tenantID uint) ([]models.StructuringRule, error) {
+	var rules []models.StructuringRule
+	err := s.DB.Where("tenant_id = ?", tenantID).Order("currency ASC").Find(&rules).Error
+	return rules, err
+}
+
+// GetStructuringRule returns the rule for the currency, falling back to the tenant's "*" rule,
+// or nil if neither is configured
+func (s *ComplianceService) GetStructuringRule(tenantID uint, currency string) (*models.StructuringRule, error) {
+	var rule models.StructuringRule
+	err := s.DB.Where("tenant_id = ? AND currency IN ?", tenantID, []string{strings.ToUpper(currency), models.StructuringRuleAnyCurrency}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "currency"}, Desc: true}).
+		First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// SetStructuringRule creates or replaces the tenant's structuring rule for a currency
+func (s *ComplianceService) SetStructuringRule(tenantID uint, req StructuringRuleRequest, userID uint) (*models.StructuringRule, error) {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		return nil, errors.New("currency is required; use * for all other currencies")
+	}
+	if req.ReportingThreshold < 0 || req.ShortWindowHours < 0 || req.LongWindowDays < 0 {
+		return nil, errors.New("threshold and windows cannot be negative")
+	}
+	if req.ShortWindowHours == 0 && req.LongWindowDays == 0 {
+		return nil, errors.New("at least one window is required")
+	}
+
+	rule := models.StructuringRule{
+		TenantID:           tenantID,
+		Currency:           currency,
+		ReportingThreshold: models.NewDecimal(req.ReportingThreshold),
+		ShortWindowHours:   req.ShortWindowHours,
+		LongWindowDays:     req.LongWindowDays,
+		UpdatedBy:          &userID,
+	}
+	if err := s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reporting_threshold", "short_window_hours", "long_window_days", "updated_by", "updated_at"}),
+	}).Create(&rule).Error; err != nil {
+		return nil, err
+	}
+
+	var saved models.StructuringRule
+	if err := s.DB.Where("tenant_id = ? AND currency = ?", tenantID, currency).First(&saved).Error; err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteStructuringRule removes a structuring rule
+func (s *ComplianceService) DeleteStructuringRule(tenantID, ruleID uint) error {
+	result := s.DB.Where("id = ? AND tenant_id = ?", ruleID, tenantID).Delete(&models.StructuringRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// complianceExpiry reports whether the record has lapsed and when, checking the verification
+// expiry and the ID document expiry
+func complianceExpiry(compliance *models.CustomerCompliance, now time.Time) (*time.Time, bool) {
+	if compliance.ExpiresAt != nil && now.After(*compliance.ExpiresAt) {
+		return compliance.ExpiresAt, true
+	}
+	if compliance.IDExpiryDate != nil && now.After(*compliance.IDExpiryDate) {
+		return compliance.IDExpiryDate, true
+	}
+	if compliance.Status == models.ComplianceStatusExpired {
+		return compliance.ExpiresAt, true
+	}
+	return nil, false
+}
+
+// findClientCompliance returns the compliance record of the customer sharing the client's phone number,
+// or nil if the client has none
+func (s *ComplianceService) findClientCompliance(tenantID uint, clientID string) (*models.CustomerCompliance, error) {
+	var client models.Client
+	if err := s.DB.Select("id", "phone_number").Where("id = ? AND tenant_id = ?", clientID, tenantID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var compliance models.CustomerCompliance
+	err := s.DB.Joins("JOIN customers ON customers.id = customer_compliance.customer_id").
+		Where("customer_compliance.tenant_id = ? AND customers.phone = ?", tenantID, client.PhoneNumber).
+		First(&compliance).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &compliance, nil
+}
+
+// EnsureClientComplianceCurrent blocks new transactions for a client whose customer KYC has expired.
+// Clients without a linked compliance record are not affected.
+func (s *ComplianceService) EnsureClientComplianceCurrent(tenantID uint, clientID string) error {
+	compliance, err := s.findClientCompliance(tenantID, clientID)
+	if err != nil || compliance == nil {
+		return err
+	}
+
+	expiredAt, expired := complianceExpiry(compliance, time.Now())
+	if !expired {
+		return nil
+	}
+
+	if compliance.Status != models.ComplianceStatusExpired {
+		s.DB.Model(compliance).Update("status", models.ComplianceStatusExpired)
+		s.logAction(compliance.ID, tenantID, "STATUS_CHANGE", string(compliance.Status), string(models.ComplianceStatusExpired), nil, true)
+	}
+
+	if expiredAt != nil {
+		return fmt.Errorf("%w on %s: renew the customer's compliance documents before creating new transactions",
+			ErrComplianceExpired, expiredAt.Format("2006-01-02"))
+	}
+	return fmt.Errorf("%w: renew the customer's compliance documents before creating new transactions", ErrComplianceExpired)
+}
+
+// EnsureClientComplianceApproved requires the client's customer KYC to be approved and unexpired.
+// Unlike EnsureClientComplianceCurrent, a client without a compliance record is rejected.
+func (s *ComplianceService) EnsureClientComplianceApproved(tenantID uint, clientID string) error {
+	compliance, err := s.findClientCompliance(tenantID, clientID)
+	if err != nil {
+		return err
+	}
+	if compliance == nil {
+		return fmt.Errorf("%w: the customer has no compliance record", ErrComplianceApprovalRequired)
+	}
+	if _, expired := complianceExpiry(compliance, time.Now()); expired {
+		return fmt.Errorf("%w: the customer's KYC has expired", ErrComplianceApprovalRequired)
+	}
+	if compliance.Status != models.ComplianceStatusApproved {
+		return fmt.Errorf("%w: the customer's compliance status is %s", ErrComplianceApprovalRequired, compliance.Status)
+	}
+	return nil
+}
+
 // UpdateComplianceStatus updates the compliance status with audit logging
 func (s *ComplianceService) UpdateComplianceStatus(complianceID uint, newStatus models.ComplianceStatus, userID *uint, reason string) error {
 	var compliance models.CustomerCompliance
@@ -171,6 +468,7 @@ func (s *ComplianceService) UpdateComplianceStatus(complianceID uint, newStatus
 		// Set expiration (e.g., 1 year from approval)
 		expiresAt := now.AddDate(1, 0, 0)
 		updates["expires_at"] = expiresAt
+		updates["renewal_reminder_sent"] = false
 	case models.ComplianceStatusRejected:
 		updates["rejected_at"] = now
 		updates["rejected_by_user_id"] = userID
@@ -187,6 +485,59 @@ func (s *ComplianceService) UpdateComplianceStatus(complianceID uint, newStatus
 	return nil
 }
 
+// ScreenCustomer screens the customer's name against sanctions and PEP lists and records the outcome
+// on the compliance record. A potential match sends the record to review; a clean result leaves the
+// status unchanged. Match flags are only ever raised here; clearing one is left to a reviewer.
+func (s *ComplianceService) ScreenCustomer(tenantID, complianceID uint, dob string, provider ScreeningProvider, userID *uint) (*ScreeningResult, error) {
+	var compliance models.CustomerCompliance
+	if err := s.DB.Preload("Customer").Where("id = ? AND tenant_id = ?", complianceID, tenantID).First(&compliance).Error; err != nil {
+		return nil, err
+	}
+	if compliance.Customer == nil {
+		return nil, errors.New("compliance record has no customer to screen")
+	}
+
+	result, err := provider.ScreenName(compliance.Customer.FullName, strings.TrimSpace(dob), compliance.Country)
+	if err != nil {
+		return nil, fmt.Errorf("screening failed: %w", err)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode screening result: %w", err)
+	}
+	updates := map[string]interface{}{
+		"aml_screening_result": string(encoded),
+		"pep_match":            compliance.PEPMatch || result.HasMatchType("PEP"),
+		"sanctions_match":      compliance.SanctionsMatch || result.HasMatchType("SANCTIONS"),
+		"adverse_media_match":  compliance.AdverseMediaMatch || result.HasMatchType("ADVERSE_MEDIA"),
+		"aml_screened_at":      result.ScreenedAt,
+	}
+	hit := result.Status == ScreeningStatusPotentialMatch || len(result.Matches) > 0
+	toReview := false
+	if hit {
+		switch compliance.Status {
+		case models.ComplianceStatusInReview, models.ComplianceStatusRejected, models.ComplianceStatusSuspended:
+		default:
+			toReview = true
+			updates["status"] = models.ComplianceStatusInReview
+		}
+	}
+	if err := s.DB.Model(&compliance).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	outcome := ScreeningStatusClear
+	if hit {
+		outcome = fmt.Sprintf("%s (%d matches)", ScreeningStatusPotentialMatch, len(result.Matches))
+	}
+	s.logAction(compliance.ID, tenantID, "SCREENED", "", outcome, userID, false)
+	if toReview {
+		s.logAction(compliance.ID, tenantID, "STATUS_CHANGE", string(compliance.Status), string(models.ComplianceStatusInReview), userID, true)
+	}
+	return result, nil
+}
+
 // UploadDocument records a compliance document upload
 func (s *ComplianceService) UploadDocument(complianceID uint, tenantID uint, docType, fileName, filePath string, fileSize int64, mimeType string) (*models.ComplianceDocument, error) {
 	doc := &models.ComplianceDocument{
@@ -245,6 +596,16 @@ func (s *ComplianceService) ReviewDocument(docID uint, approved bool, notes stri
 	return nil
 }
 
+// GetDocument returns one of the tenant's compliance documents. Another tenant's document is reported as
+// not found so its existence is not revealed.
+func (s *ComplianceService) GetDocument(tenantID, docID uint) (*models.ComplianceDocument, error) {
+	var doc models.ComplianceDocument
+	if err := s.DB.Where("id = ? AND tenant_id = ?", docID, tenantID).First(&doc).Error; err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
 // SetRiskLevel updates the risk level for a customer
 func (s *ComplianceService) SetRiskLevel(complianceID uint, riskLevel models.RiskLevel, userID *uint, reason string) error {
 	var compliance models.CustomerCompliance
@@ -279,19 +640,116 @@ func (s *ComplianceService) SetTransactionLimits(complianceID uint, dailyLimit,
 	return nil
 }
 
-// GetPendingReviews returns compliance records pending review
-func (s *ComplianceService) GetPendingReviews(tenantID uint, limit int) ([]models.CustomerCompliance, error) {
+// GetPendingReviews returns compliance records pending review, limited to the given branches when branchIDs is not nil
+func (s *ComplianceService) GetPendingReviews(tenantID uint, branchIDs []uint, limit int) ([]models.CustomerCompliance, error) {
 	var records []models.CustomerCompliance
 	query := s.DB.Where("status IN (?, ?)", models.ComplianceStatusPending, models.ComplianceStatusInReview)
 	if tenantID > 0 {
 		query = query.Where("tenant_id = ?", tenantID)
 	}
+	if branchIDs != nil {
+		query = query.Where("branch_id IN ?", branchIDs)
+	}
 	if err := query.Order("created_at ASC").Limit(limit).Find(&records).Error; err != nil {
 		return nil, err
 	}
 	return records, nil
 }
 
+// ReviewBranches returns the branches whose pending reviews the user may see: nil for every branch, or the
+// requested branch. Owners and admins may view any branch; a branch's compliance officer sees only the
+// branches they are assigned to, and other users keep the tenant-wide view.
+func (s *ComplianceService) ReviewBranches(tenantID uint, user *models.User, requested *uint) ([]uint, error) {
+	var officerOf []uint
+	if err := s.DB.Model(&models.Branch{}).
+		Where("tenant_id = ? AND compliance_officer_id = ?", tenantID, user.ID).
+		Pluck("id", &officerOf).Error; err != nil {
+		return nil, err
+	}
+
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+		officerOf = nil
+	}
+
+	if len(officerOf) == 0 {
+		if requested != nil {
+			return []uint{*requested}, nil
+		}
+		return nil, nil
+	}
+	if requested == nil {
+		return officerOf, nil
+	}
+	for _, branchID := range officerOf {
+		if branchID == *requested {
+			return []uint{branchID}, nil
+		}
+	}
+	return nil, ErrNotBranchComplianceOfficer
+}
+
+// IsComplianceReviewer reports whether the user may act on compliance records: owners, admins and the
+// compliance officer of any of the tenant's branches
+func (s *ComplianceService) IsComplianceReviewer(tenantID uint, user *models.User) (bool, error) {
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+		return true, nil
+	}
+	var count int64
+	if err := s.DB.Model(&models.Branch{}).
+		Where("tenant_id = ? AND compliance_officer_id = ?", tenantID, user.ID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// AssignComplianceOfficer designates the user who reviews KYC for the branch; a nil officer clears it
+func (s *ComplianceService) AssignComplianceOfficer(tenantID, branchID uint, officerID *uint) (*models.Branch, error) {
+	var branch models.Branch
+	if err := s.DB.Where("id = ? AND tenant_id = ?", branchID, tenantID).First(&branch).Error; err != nil {
+		return nil, fmt.Errorf("branch not found: %w", err)
+	}
+	if officerID != nil {
+		var count int64
+		s.DB.Model(&models.User{}).Where("id = ? AND tenant_id = ?", *officerID, tenantID).Count(&count)
+		if count == 0 {
+			return nil, errors.New("compliance officer must be a user of this tenant")
+		}
+	}
+
+	if err := s.DB.Model(&branch).Update("compliance_officer_id", officerID).Error; err != nil {
+		return nil, err
+	}
+	branch.ComplianceOfficerID = officerID
+	return &branch, nil
+}
+
+// RouteToBranch sends a compliance record's reviews to the branch's compliance officer. Unless force is set,
+// a record already routed to a branch keeps it; with force, a record missing from the tenant returns
+// ErrComplianceRecordNotFound.
+func (s *ComplianceService) RouteToBranch(tenantID, complianceID, branchID uint, force bool) error {
+	var count int64
+	s.DB.Model(&models.Branch{}).Where("id = ? AND tenant_id = ?", branchID, tenantID).Count(&count)
+	if count == 0 {
+		return errors.New("branch not found")
+	}
+
+	query := s.DB.Model(&models.CustomerCompliance{}).Where("id = ? AND tenant_id = ?", complianceID, tenantID)
+	if !force {
+		query = query.Where("branch_id IS NULL")
+	}
+	result := query.Update("branch_id", branchID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if force && result.RowsAffected == 0 {
+		return ErrComplianceRecordNotFound
+	}
+	return nil
+}
+
 // GetExpiringCompliance returns records expiring within the specified days
 func (s *ComplianceService) GetExpiringCompliance(tenantID uint, days int) ([]models.CustomerCompliance, error) {
 	expiryThreshold := time.Now().AddDate(0, 0, days)
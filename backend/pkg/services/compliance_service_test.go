@@ -2,6 +2,7 @@ package services
 
 import (
 	"api/pkg/models"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -27,11 +28,11 @@ func TestComplianceWorkflow(t *testing.T) {
 		&models.ComplianceDocument{},
 		&models.ComplianceAuditLog{},
 		&models.TransactionComplianceCheck{},
+		&models.StructuringRule{},
 	)
 
 	// Create test data
-	tenant := &models.Tenant{Name: "Test Exchange Bureau"}
-	db.Create(tenant)
+	tenant := newTestTenant(t, db, "Test Exchange Bureau")
 
 	user := &models.User{
 		Email:    "compliance@example.com",
@@ -273,7 +274,7 @@ func TestComplianceWorkflow(t *testing.T) {
 		compliance, _ := service.GetOrCreateCompliance(tenant.ID, customer3.ID)
 		service.UpdateComplianceStatus(compliance.ID, models.ComplianceStatusInReview, &user.ID, "Ready for review")
 
-		pending, err := service.GetPendingReviews(tenant.ID, 50)
+		pending, err := service.GetPendingReviews(tenant.ID, nil, 50)
 		if err != nil {
 			t.Fatalf("Failed to get pending reviews: %v", err)
 		}
@@ -437,3 +438,112 @@ func TestVerificationProvider(t *testing.T) {
 	fmt.Println("\n✅ ALL VERIFICATION PROVIDER TESTS PASSED!")
 	fmt.Println("=====================================")
 }
+
+// TestViewCompliance_ReadAudit verifies viewing a compliance record is audited only when the tenant enables it
+func TestViewCompliance_ReadAudit(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.CustomerCompliance{},
+		&models.ComplianceAuditLog{}, &models.TenantSettings{}, &models.AuditLog{})
+
+	tenant := newTestTenant(t, db, "Read Audit Exchange")
+	user := newTestUser(t, db, tenant.ID, "viewer@example.com", models.RoleTenantUser)
+	customer := &models.Customer{FullName: "Jane Roe", Phone: "+14165559876"}
+	db.Create(customer)
+
+	service := NewComplianceService(db)
+	countViews := func() int64 {
+		var count int64
+		db.Model(&models.AuditLog{}).Where("tenant_id = ? AND action = ? AND entity_type = ?",
+			tenant.ID, AuditActionView, AuditEntityCustomerCompliance).Count(&count)
+		return count
+	}
+
+	// Off by default
+	if _, err := service.ViewCompliance(tenant.ID, customer.ID, user.ID, nil); err != nil {
+		t.Fatalf("ViewCompliance failed: %v", err)
+	}
+	if views := countViews(); views != 0 {
+		t.Fatalf("Expected no view audited with read auditing off, got %d", views)
+	}
+
+	entities := "customercompliance, "
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{ReadAuditEntities: &entities}); err != nil {
+		t.Fatalf("Failed to enable read auditing: %v", err)
+	}
+
+	compliance, err := service.ViewCompliance(tenant.ID, customer.ID, user.ID, nil)
+	if err != nil {
+		t.Fatalf("ViewCompliance failed: %v", err)
+	}
+	var entry models.AuditLog
+	if err := db.Where("tenant_id = ? AND action = ?", tenant.ID, AuditActionView).First(&entry).Error; err != nil {
+		t.Fatalf("Expected a view audit entry: %v", err)
+	}
+	if entry.UserID != user.ID || entry.EntityType != AuditEntityCustomerCompliance || entry.EntityID != fmt.Sprintf("%d", compliance.ID) {
+		t.Errorf("Unexpected audit entry: user %d, %s %s", entry.UserID, entry.EntityType, entry.EntityID)
+	}
+	if views := countViews(); views != 1 {
+		t.Errorf("Expected exactly 1 audited view, got %d", views)
+	}
+}
+
+// TestGetPendingReviews_OfficerSeesOnlyOwnBranch verifies a branch compliance officer sees only the reviews
+// routed to their branch while an admin still sees every branch
+func TestGetPendingReviews_OfficerSeesOnlyOwnBranch(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Customer{},
+		&models.CustomerCompliance{}, &models.ComplianceAuditLog{})
+
+	tenant := newTestTenant(t, db, "Branch Routing Exchange")
+	admin := newTestUser(t, db, tenant.ID, "owner@example.com", models.RoleTenantOwner)
+	service := NewComplianceService(db)
+
+	officers := make([]*models.User, 2)
+	branches := make([]*models.Branch, 2)
+	routed := make([]uint, 2)
+	for i := range branches {
+		officers[i] = &models.User{Email: fmt.Sprintf("officer%d@example.com", i), TenantID: &tenant.ID, Role: models.RoleTenantUser}
+		db.Create(officers[i])
+		branches[i] = &models.Branch{TenantID: tenant.ID, Name: fmt.Sprintf("Branch %d", i), BranchCode: fmt.Sprintf("BR%d", i)}
+		db.Create(branches[i])
+		if _, err := service.AssignComplianceOfficer(tenant.ID, branches[i].ID, &officers[i].ID); err != nil {
+			t.Fatalf("Failed to assign officer: %v", err)
+		}
+
+		customer := &models.Customer{FullName: fmt.Sprintf("Customer %d", i), Phone: fmt.Sprintf("+1416555000%d", i)}
+		db.Create(customer)
+		compliance, err := service.GetOrCreateCompliance(tenant.ID, customer.ID)
+		if err != nil {
+			t.Fatalf("Failed to create compliance: %v", err)
+		}
+		if err := service.RouteToBranch(tenant.ID, compliance.ID, branches[i].ID, false); err != nil {
+			t.Fatalf("Failed to route compliance: %v", err)
+		}
+		routed[i] = compliance.ID
+	}
+
+	pendingFor := func(user *models.User, requested *uint) ([]models.CustomerCompliance, error) {
+		branchIDs, err := service.ReviewBranches(tenant.ID, user, requested)
+		if err != nil {
+			return nil, err
+		}
+		return service.GetPendingReviews(tenant.ID, branchIDs, 50)
+	}
+
+	pending, err := pendingFor(officers[0], nil)
+	if err != nil {
+		t.Fatalf("Failed to get pending reviews: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != routed[0] {
+		t.Errorf("Expected the officer to see only their branch's review %d, got %d reviews", routed[0], len(pending))
+	}
+
+	if _, err := pendingFor(officers[0], &branches[1].ID); !errors.Is(err, ErrNotBranchComplianceOfficer) {
+		t.Errorf("Expected ErrNotBranchComplianceOfficer for another branch, got %v", err)
+	}
+
+	if pending, _ := pendingFor(admin, nil); len(pending) != 2 {
+		t.Errorf("Expected the admin to see both branches' reviews, got %d", len(pending))
+	}
+	if pending, _ := pendingFor(admin, &branches[1].ID); len(pending) != 1 || pending[0].ID != routed[1] {
+		t.Errorf("Expected the admin's branch filter to return review %d, got %d reviews", routed[1], len(pending))
+	}
+}
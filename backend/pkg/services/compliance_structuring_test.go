@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestCheckTransactionCompliance_Structuring verifies sub-threshold transactions are flagged when they
+// reach the reporting threshold inside a window and not when spread beyond it
+func TestCheckTransactionCompliance_Structuring(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Structuring Tenant"}
+	db.Create(&tenant)
+	owner := models.User{Email: "structuring-owner@example.com", TenantID: &tenant.ID, Role: models.RoleTenantOwner}
+	db.Create(&owner)
+
+	service := services.NewComplianceService(db)
+	if _, err := service.SetStructuringRule(tenant.ID, services.StructuringRuleRequest{
+		Currency:           "cad",
+		ReportingThreshold: 10000,
+		ShortWindowHours:   24,
+		LongWindowDays:     7,
+	}, owner.ID); err != nil {
+		t.Fatalf("Failed to set structuring rule: %v", err)
+	}
+
+	// newCustomer creates a customer and a client sharing its phone, with CAD transactions at the given ages
+	newCustomer := func(phone string, amount float64, ages ...time.Duration) *models.Customer {
+		customer := &models.Customer{FullName: "Customer " + phone, Phone: phone}
+		db.Create(customer)
+		client := models.Client{ID: uuid.New().String(), TenantID: tenant.ID, Name: customer.FullName, PhoneNumber: phone}
+		db.Create(&client)
+		for _, age := range ages {
+			db.Create(&models.Transaction{
+				ID:              uuid.New().String(),
+				TenantID:        tenant.ID,
+				ClientID:        client.ID,
+				PaymentMethod:   models.TransactionMethodCash,
+				SendCurrency:    "CAD",
+				SendAmount:      models.NewDecimal(amount),
+				ReceiveCurrency: "USD",
+				ReceiveAmount:   models.NewDecimal(amount * 0.73),
+				RateApplied:     models.NewDecimal(0.73),
+				TransactionDate: time.Now().Add(-age),
+				Status:          models.StatusCompleted,
+			})
+		}
+		return customer
+	}
+
+	// Two earlier transactions today plus this one make 10,500 CAD in 24 hours
+	burst := newCustomer("+14165550101", 3500, 3*time.Hour, 10*time.Hour)
+	result, err := service.CheckTransactionCompliance(tenant.ID, burst.ID, 3500, "CAD")
+	if err != nil {
+		t.Fatalf("Failed to check compliance: %v", err)
+	}
+	if result.Structuring == nil || len(result.Flags) != 1 || result.Flags[0] != services.ComplianceFlagStructuring {
+		t.Fatalf("Expected a structuring flag, got %+v", result)
+	}
+	if !result.RequiresReview || result.Structuring.WindowHours != 24 || result.Structuring.TransactionCount != 3 || result.Structuring.Total != 10500 {
+		t.Errorf("Expected 3 transactions totalling 10500 in the 24h window, got %+v", result.Structuring)
+	}
+
+	// The same amounts a week apart stay below the threshold in both windows
+	spread := newCustomer("+14165550102", 3500, 7*24*time.Hour+time.Hour, 14*24*time.Hour)
+	result, err = service.CheckTransactionCompliance(tenant.ID, spread.ID, 3500, "CAD")
+	if err != nil {
+		t.Fatalf("Failed to check compliance: %v", err)
+	}
+	if result.Structuring != nil || len(result.Flags) != 0 {
+		t.Errorf("Expected no structuring flag for transactions spread over two weeks, got %+v", result.Structuring)
+	}
+
+	// A customer without a phone number is not matched to the clients that have none
+	unlinked := newCustomer("", 3500, 3*time.Hour, 10*time.Hour)
+	result, err = service.CheckTransactionCompliance(tenant.ID, unlinked.ID, 3500, "CAD")
+	if err != nil {
+		t.Fatalf("Failed to check compliance: %v", err)
+	}
+	if result.Structuring != nil {
+		t.Errorf("Expected no structuring flag for a customer without a phone number, got %+v", result.Structuring)
+	}
+
+	// Other currencies have no rule of their own and are not checked
+	result, err = service.CheckTransactionCompliance(tenant.ID, burst.ID, 3500, "USD")
+	if err != nil {
+		t.Fatalf("Failed to check compliance: %v", err)
+	}
+	if result.Structuring != nil {
+		t.Errorf("Expected no structuring check for USD, got %+v", result.Structuring)
+	}
+}
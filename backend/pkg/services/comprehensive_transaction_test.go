@@ -24,12 +24,21 @@ func TestComprehensiveTransactions(t *testing.T) {
 		&models.User{},
 		&models.Branch{},
 		&models.Client{},
+		&models.Customer{},
+		&models.CustomerCompliance{},
+		&models.ComplianceAuditLog{},
 		&models.Transaction{},
 		&models.ExchangeRate{},
 		&models.Payment{},
+		&models.PaymentPlan{},
+		&models.PaymentInstallment{},
 		&models.LedgerEntry{},
 		&models.CashBalance{},
 		&models.CashAdjustment{},
+		&models.DailyClose{},
+		&models.CurrencyProfitFloor{},
+		&models.TransactionRateAudit{},
+		&models.TenantSettings{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate: %v", err)
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"html"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CustomerCampaignService sends bulk messages to a tagged segment of the tenant's customers
+type CustomerCampaignService struct {
+	db       *gorm.DB
+	Notifier NotificationProvider
+}
+
+// NewCustomerCampaignService creates a new CustomerCampaignService
+func NewCustomerCampaignService(db *gorm.DB) *CustomerCampaignService {
+	return &CustomerCampaignService{
+		db:       db,
+		Notifier: NewNotificationProvider(),
+	}
+}
+
+// CustomerMessageRequest is the body for messaging a customer segment
+type CustomerMessageRequest struct {
+	Tag     string `json:"tag"`
+	Channel string `json:"channel"` // EMAIL or SMS
+	Subject string `json:"subject"` // Required for email
+	Message string `json:"message"`
+}
+
+// campaignRecipient is a tagged customer together with the consent the tenant holds for them
+type campaignRecipient struct {
+	CustomerID       uint
+	Phone            string
+	Email            *string
+	MarketingConsent bool
+	EmailOptOut      bool
+	SMSOptOut        bool
+}
+
+// SendToSegment messages every customer carrying the tag and records the campaign. Customers who have
+// not consented to bulk messages, or who opted out of the channel, are skipped rather than contacted, as
+// are SMS recipients while no SMS gateway is configured. A failed send is counted and does not stop the
+// rest of the segment.
+func (s *CustomerCampaignService) SendToSegment(tenantID uint, req CustomerMessageRequest, userID uint) (*models.CustomerCampaign, error) {
+	tag := NormalizeCustomerTag(req.Tag)
+	if tag == "" {
+		return nil, errors.New("a tag is required to choose the customers to message")
+	}
+	channel := strings.ToUpper(strings.TrimSpace(req.Channel))
+	if channel != models.CampaignChannelEmail && channel != models.CampaignChannelSMS {
+		return nil, errors.New("channel must be EMAIL or SMS")
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return nil, errors.New("message is required")
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if channel == models.CampaignChannelEmail && subject == "" {
+		return nil, errors.New("subject is required for email")
+	}
+
+	var recipients []campaignRecipient
+	if err := s.db.Table("customer_tenant_links").
+		Select("customers.id AS customer_id, customers.phone, customers.email, customer_tenant_links.marketing_consent, customer_tenant_links.email_opt_out, customer_tenant_links.sms_opt_out").
+		Joins("JOIN customers ON customers.id = customer_tenant_links.customer_id AND customers.deleted_at IS NULL").
+		Where("customer_tenant_links.tenant_id = ?", tenantID).
+		Where(customerTagClause, escapeLikePattern(tag)).
+		Order("customers.id ASC").
+		Scan(&recipients).Error; err != nil {
+		return nil, err
+	}
+
+	campaign := &models.CustomerCampaign{
+		TenantID:     tenantID,
+		Tag:          tag,
+		Channel:      channel,
+		Subject:      subject,
+		Message:      message,
+		MatchedCount: len(recipients),
+		CreatedBy:    userID,
+	}
+	emailBody := "<p>" + strings.ReplaceAll(html.EscapeString(message), "\n", "<br>") + "</p>"
+
+	for _, recipient := range recipients {
+		if !recipient.MarketingConsent {
+			campaign.SkippedCount++
+			continue
+		}
+
+		var err error
+		switch channel {
+		case models.CampaignChannelEmail:
+			if recipient.EmailOptOut || recipient.Email == nil || *recipient.Email == "" {
+				campaign.SkippedCount++
+				continue
+			}
+			err = s.Notifier.SendEmail(*recipient.Email, subject, emailBody)
+		case models.CampaignChannelSMS:
+			if recipient.SMSOptOut || recipient.Phone == "" {
+				campaign.SkippedCount++
+				continue
+			}
+			err = s.Notifier.SendSMS(recipient.Phone, message)
+		}
+		if errors.Is(err, ErrSMSNotConfigured) {
+			campaign.SkippedCount++
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️  Campaign message to customer %d failed: %v", recipient.CustomerID, err)
+			campaign.FailedCount++
+			continue
+		}
+		campaign.SentCount++
+	}
+
+	if err := s.db.Create(campaign).Error; err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// GetCampaigns lists the tenant's campaigns, newest first
+func (s *CustomerCampaignService) GetCampaigns(tenantID uint) ([]models.CustomerCampaign, error) {
+	var campaigns []models.CustomerCampaign
+	err := s.db.Where("tenant_id = ?", tenantID).Order("created_at DESC, id DESC").Find(&campaigns).Error
+	return campaigns, err
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"api/pkg/models"
+	"sort"
+	"testing"
+)
+
+// TestSendToSegment_ReachesOnlyTaggedConsentedCustomers verifies a segment message skips untagged,
+// unconsented and opted-out customers, and customers tagged by another tenant
+func TestSendToSegment_ReachesOnlyTaggedConsentedCustomers(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.Customer{}, &models.CustomerTenantLink{}, &models.CustomerCampaign{})
+
+	tenant := newTestTenant(t, db, "Campaign Exchange")
+	other := newTestTenant(t, db, "Other Exchange")
+
+	customerService := NewCustomerService(db)
+	consentYes, optOut := true, true
+	addCustomer := func(phone, email string, tenantID uint, tags []string, consent CustomerConsentUpdate) {
+		t.Helper()
+		customer, err := customerService.FindOrCreateCustomer(tenantID, phone, "Customer "+phone, &email, nil)
+		if err != nil {
+			t.Fatalf("Failed to create customer: %v", err)
+		}
+		if err := customerService.LinkCustomerToTenant(customer.ID, tenantID); err != nil {
+			t.Fatalf("Failed to link customer: %v", err)
+		}
+		if _, err := customerService.SetCustomerTags(customer.ID, tenantID, tags); err != nil {
+			t.Fatalf("Failed to tag customer: %v", err)
+		}
+		if _, err := customerService.UpdateConsent(customer.ID, tenantID, consent); err != nil {
+			t.Fatalf("Failed to update consent: %v", err)
+		}
+	}
+
+	addCustomer("+14165550001", "vip1@example.com", tenant.ID, []string{" VIP ", "gold"}, CustomerConsentUpdate{MarketingConsent: &consentYes})
+	addCustomer("+14165550002", "vip2@example.com", tenant.ID, []string{"vip"}, CustomerConsentUpdate{MarketingConsent: &consentYes})
+	addCustomer("+14165550003", "noconsent@example.com", tenant.ID, []string{"vip"}, CustomerConsentUpdate{})
+	addCustomer("+14165550004", "optout@example.com", tenant.ID, []string{"vip"}, CustomerConsentUpdate{MarketingConsent: &consentYes, EmailOptOut: &optOut})
+	addCustomer("+14165550005", "gold@example.com", tenant.ID, []string{"gold", "vipx"}, CustomerConsentUpdate{MarketingConsent: &consentYes})
+	addCustomer("+14165550006", "elsewhere@example.com", other.ID, []string{"vip"}, CustomerConsentUpdate{MarketingConsent: &consentYes})
+
+	notifier := NewMockNotificationProvider()
+	campaignService := NewCustomerCampaignService(db)
+	campaignService.Notifier = notifier
+
+	campaign, err := campaignService.SendToSegment(tenant.ID, CustomerMessageRequest{
+		Tag:     "vip",
+		Channel: "email",
+		Subject: "Better rates this week",
+		Message: "Our CAD/USD rate is <best> in town.",
+	}, 1)
+	if err != nil {
+		t.Fatalf("Failed to send campaign: %v", err)
+	}
+
+	var reached []string
+	for _, sent := range notifier.Sent {
+		reached = append(reached, sent.To)
+	}
+	sort.Strings(reached)
+	if len(reached) != 2 || reached[0] != "vip1@example.com" || reached[1] != "vip2@example.com" {
+		t.Errorf("Expected only the two consenting VIP customers to be emailed, got %v", reached)
+	}
+	if campaign.MatchedCount != 4 || campaign.SentCount != 2 || campaign.SkippedCount != 2 || campaign.FailedCount != 0 {
+		t.Errorf("Expected 4 matched, 2 sent, 2 skipped, got %+v", campaign)
+	}
+	if len(notifier.Sent) > 0 && notifier.Sent[0].Body != "<p>Our CAD/USD rate is &lt;best&gt; in town.</p>" {
+		t.Errorf("Expected the message to be escaped for email, got %q", notifier.Sent[0].Body)
+	}
+
+	// The opted-out customer only opted out of email, so an SMS still reaches them
+	smsCampaign, err := campaignService.SendToSegment(tenant.ID, CustomerMessageRequest{Tag: "VIP", Channel: "SMS", Message: "Rates updated"}, 1)
+	if err != nil {
+		t.Fatalf("Failed to send SMS campaign: %v", err)
+	}
+	if smsCampaign.SentCount != 3 || smsCampaign.SkippedCount != 1 {
+		t.Errorf("Expected 3 SMS sent and 1 skipped, got %+v", smsCampaign)
+	}
+
+	// Without an SMS gateway nothing reaches the customers, so they are skipped rather than counted as sent
+	unconfigured := NewCustomerCampaignService(db)
+	unsent, err := unconfigured.SendToSegment(tenant.ID, CustomerMessageRequest{Tag: "vip", Channel: "SMS", Message: "Rates updated"}, 1)
+	if err != nil {
+		t.Fatalf("Failed to send SMS campaign: %v", err)
+	}
+	if unsent.SentCount != 0 || unsent.FailedCount != 0 || unsent.SkippedCount != 4 {
+		t.Errorf("Expected all 4 SMS skipped without a gateway, got %+v", unsent)
+	}
+
+	// LIKE wildcards in a tag match literally
+	wildcard, err := campaignService.SendToSegment(tenant.ID, CustomerMessageRequest{Tag: "v_p", Channel: "SMS", Message: "Rates updated"}, 1)
+	if err != nil {
+		t.Fatalf("Failed to send SMS campaign: %v", err)
+	}
+	if wildcard.MatchedCount != 0 {
+		t.Errorf("Expected the tag v_p to match no vip customers, got %d", wildcard.MatchedCount)
+	}
+
+	campaigns, err := campaignService.GetCampaigns(tenant.ID)
+	if err != nil || len(campaigns) != 4 {
+		t.Errorf("Expected all campaigns to be recorded, got %d (%v)", len(campaigns), err)
+	}
+	if others, _ := campaignService.GetCampaigns(other.ID); len(others) != 0 {
+		t.Errorf("Expected no campaigns for the other tenant, got %d", len(others))
+	}
+
+	if _, err := campaignService.SendToSegment(tenant.ID, CustomerMessageRequest{Tag: "vip", Channel: "EMAIL", Message: "No subject"}, 1); err == nil {
+		t.Error("Expected an email campaign without a subject to be rejected")
+	}
+
+	tagged, err := customerService.GetCustomersForTenant(tenant.ID, "gold")
+	if err != nil || len(tagged) != 2 {
+		t.Errorf("Expected two customers tagged gold, got %d (%v)", len(tagged), err)
+	}
+	if tagged, _ := customerService.GetCustomersForTenant(tenant.ID, "g%"); len(tagged) != 0 {
+		t.Errorf("Expected the tag g%% to match no gold customers, got %d", len(tagged))
+	}
+}
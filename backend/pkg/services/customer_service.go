@@ -3,6 +3,7 @@ package services
 import (
 	"api/pkg/models"
 	"errors"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,21 +17,50 @@ func NewCustomerService(db *gorm.DB) *CustomerService {
 	return &CustomerService{DB: db}
 }
 
-// FindOrCreateCustomer finds a customer by phone or creates a new one
-func (s *CustomerService) FindOrCreateCustomer(phone, fullName string, email *string) (*models.Customer, error) {
+// MissingCustomerFieldError is returned when a required customer field is absent
+type MissingCustomerFieldError struct {
+	Field string
+}
+
+func (e *MissingCustomerFieldError) Error() string {
+	return e.Field + " is required"
+}
+
+// FindOrCreateCustomer finds a customer by phone or creates a new one.
+// When the tenant requires KYC, the customer must also carry an ID number.
+func (s *CustomerService) FindOrCreateCustomer(tenantID uint, phone, fullName string, email, idNumber *string) (*models.Customer, error) {
 	if phone == "" {
-		return nil, errors.New("phone is required")
+		return nil, &MissingCustomerFieldError{Field: "phone"}
 	}
 	if fullName == "" {
-		return nil, errors.New("full_name is required")
+		return nil, &MissingCustomerFieldError{Field: "full_name"}
 	}
 
 	var customer models.Customer
 
 	// Try to find existing customer by phone
 	err := s.DB.Where("phone = ?", phone).First(&customer).Error
-	if err == nil {
-		// Customer exists, update name and email if provided
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	exists := err == nil
+
+	hasIDNumber := idNumber != nil && *idNumber != ""
+	if !hasIDNumber && exists && customer.IDNumber != nil && *customer.IDNumber != "" {
+		hasIDNumber = true
+	}
+	if !hasIDNumber {
+		settings, err := NewTenantSettingsService(s.DB).GetSettings(tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.RequireCustomerIDNumber {
+			return nil, &MissingCustomerFieldError{Field: "id_number"}
+		}
+	}
+
+	if exists {
+		// Customer exists, update name, email and ID number if provided
 		updates := map[string]interface{}{
 			"full_name":  fullName,
 			"updated_at": time.Now(),
@@ -38,19 +68,19 @@ func (s *CustomerService) FindOrCreateCustomer(phone, fullName string, email *st
 		if email != nil && *email != "" {
 			updates["email"] = email
 		}
+		if idNumber != nil && *idNumber != "" {
+			updates["id_number"] = idNumber
+		}
 		s.DB.Model(&customer).Updates(updates)
 		return &customer, nil
 	}
 
-	if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
-
 	// Customer doesn't exist, create new one
 	customer = models.Customer{
 		Phone:    phone,
 		FullName: fullName,
 		Email:    email,
+		IDNumber: idNumber,
 	}
 
 	if err := s.DB.Create(&customer).Error; err != nil {
@@ -198,7 +228,7 @@ func (s *CustomerService) SearchCustomersGlobal(query string) ([]CustomerSearchR
 
 		s.DB.Table("transactions").
 			Select("MIN(transaction_date) as first_transaction, MAX(transaction_date) as last_transaction, COUNT(*) as total_count").
-			Where("client_id = ? AND tenant_id = ?", client.ID, client.TenantID).
+			Where("client_id = ? AND tenant_id = ? AND deleted_at IS NULL", client.ID, client.TenantID).
 			Scan(&stats)
 
 		// Get distinct branches
@@ -211,7 +241,7 @@ func (s *CustomerService) SearchCustomersGlobal(query string) ([]CustomerSearchR
 		s.DB.Table("transactions").
 			Select("DISTINCT branches.id as branch_id, branches.name as branch_name").
 			Joins("LEFT JOIN branches ON transactions.branch_id = branches.id").
-			Where("transactions.client_id = ? AND transactions.tenant_id = ? AND transactions.branch_id IS NOT NULL",
+			Where("transactions.client_id = ? AND transactions.tenant_id = ? AND transactions.branch_id IS NOT NULL AND transactions.deleted_at IS NULL",
 				client.ID, client.TenantID).
 			Scan(&branches)
 
@@ -254,18 +284,100 @@ func (s *CustomerService) SearchCustomersGlobal(query string) ([]CustomerSearchR
 	return results, nil
 }
 
-// GetCustomersForTenant retrieves all customers that have transacted with a specific tenant
-func (s *CustomerService) GetCustomersForTenant(tenantID uint) ([]models.Customer, error) {
+// GetCustomersForTenant retrieves all customers that have transacted with a specific tenant,
+// optionally only those the tenant has tagged with tag. Each customer carries the tenant's own link.
+func (s *CustomerService) GetCustomersForTenant(tenantID uint, tag string) ([]models.Customer, error) {
 	var customers []models.Customer
 
-	err := s.DB.Joins("JOIN customer_tenant_links ON customer_tenant_links.customer_id = customers.id").
+	query := s.DB.Joins("JOIN customer_tenant_links ON customer_tenant_links.customer_id = customers.id").
 		Where("customer_tenant_links.tenant_id = ?", tenantID).
-		Order("customer_tenant_links.last_transaction_at DESC").
+		Preload("TenantLinks", "tenant_id = ?", tenantID)
+	if tag = NormalizeCustomerTag(tag); tag != "" {
+		query = query.Where(customerTagClause, escapeLikePattern(tag))
+	}
+	err := query.Order("customer_tenant_links.last_transaction_at DESC").
 		Find(&customers).Error
 
 	return customers, err
 }
 
+// customerTagClause matches links whose comma-separated tags include the bound tag, which must be passed
+// through escapeLikePattern
+const customerTagClause = "(',' || customer_tenant_links.tags || ',') LIKE '%,' || ? || ',%' ESCAPE '\\'"
+
+// likeEscaper escapes the LIKE wildcards, and the escape character itself, with a backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLikePattern makes s match literally inside a LIKE pattern declared with ESCAPE '\'
+func escapeLikePattern(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// NormalizeCustomerTag trims and lower-cases a tag; commas are dropped since they separate tags
+func NormalizeCustomerTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", "")))
+}
+
+// SetCustomerTags replaces the tags the tenant keeps on one of its customers
+func (s *CustomerService) SetCustomerTags(customerID, tenantID uint, tags []string) (*models.CustomerTenantLink, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = NormalizeCustomerTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > 50 {
+			return nil, errors.New("tags must be at most 50 characters")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	joined := strings.Join(normalized, ",")
+	if len(joined) > 500 {
+		return nil, errors.New("too many tags for one customer")
+	}
+
+	return s.updateTenantLink(customerID, tenantID, map[string]interface{}{"tags": joined})
+}
+
+// CustomerConsentUpdate changes a customer's messaging consent with the tenant; nil fields are left alone
+type CustomerConsentUpdate struct {
+	MarketingConsent *bool `json:"marketingConsent"`
+	EmailOptOut      *bool `json:"emailOptOut"`
+	SMSOptOut        *bool `json:"smsOptOut"`
+}
+
+// UpdateConsent records a customer's consent to bulk messages and their per-channel opt-outs
+func (s *CustomerService) UpdateConsent(customerID, tenantID uint, req CustomerConsentUpdate) (*models.CustomerTenantLink, error) {
+	updates := make(map[string]interface{})
+	if req.MarketingConsent != nil {
+		updates["marketing_consent"] = *req.MarketingConsent
+	}
+	if req.EmailOptOut != nil {
+		updates["email_opt_out"] = *req.EmailOptOut
+	}
+	if req.SMSOptOut != nil {
+		updates["sms_opt_out"] = *req.SMSOptOut
+	}
+	return s.updateTenantLink(customerID, tenantID, updates)
+}
+
+// updateTenantLink applies updates to the customer's link with the tenant and returns the link
+func (s *CustomerService) updateTenantLink(customerID, tenantID uint, updates map[string]interface{}) (*models.CustomerTenantLink, error) {
+	var link models.CustomerTenantLink
+	if err := s.DB.Where("customer_id = ? AND tenant_id = ?", customerID, tenantID).First(&link).Error; err != nil {
+		return nil, err
+	}
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now()
+		if err := s.DB.Model(&link).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &link, nil
+}
+
 // UpdateCustomer updates customer information
 func (s *CustomerService) UpdateCustomer(customerID uint, fullName string, email *string) error {
 	updates := map[string]interface{}{
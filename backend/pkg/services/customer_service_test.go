@@ -0,0 +1,59 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"testing"
+)
+
+// TestFindOrCreateCustomer_RequiredIDNumber verifies the tenant KYC setting gates customer creation
+func TestFindOrCreateCustomer_RequiredIDNumber(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.Customer{})
+
+	tenant := newTestTenant(t, db, "KYC Exchange")
+
+	service := NewCustomerService(db)
+	settingsService := NewTenantSettingsService(db)
+
+	t.Run("AllowedWhenSettingOff", func(t *testing.T) {
+		customer, err := service.FindOrCreateCustomer(tenant.ID, "+14165550001", "No ID Customer", nil, nil)
+		if err != nil {
+			t.Fatalf("Expected customer without ID number to be allowed, got: %v", err)
+		}
+		if customer.ID == 0 {
+			t.Error("Expected customer to be saved")
+		}
+	})
+
+	enabled := true
+	if _, err := settingsService.UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{RequireCustomerIDNumber: &enabled}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	t.Run("RejectedWhenSettingOn", func(t *testing.T) {
+		_, err := service.FindOrCreateCustomer(tenant.ID, "+14165550002", "Missing ID Customer", nil, nil)
+		var missing *MissingCustomerFieldError
+		if !errors.As(err, &missing) {
+			t.Fatalf("Expected MissingCustomerFieldError, got: %v", err)
+		}
+		if missing.Field != "id_number" {
+			t.Errorf("Expected missing field id_number, got %s", missing.Field)
+		}
+
+		var count int64
+		db.Model(&models.Customer{}).Where("phone = ?", "+14165550002").Count(&count)
+		if count != 0 {
+			t.Error("Customer should not have been saved")
+		}
+	})
+
+	t.Run("AcceptedWithIDNumber", func(t *testing.T) {
+		customer, err := service.FindOrCreateCustomer(tenant.ID, "+14165550002", "Missing ID Customer", nil, testStringPtr("P1234567"))
+		if err != nil {
+			t.Fatalf("Expected customer with ID number to be accepted, got: %v", err)
+		}
+		if customer.IDNumber == nil || *customer.IDNumber != "P1234567" {
+			t.Error("Expected ID number to be stored")
+		}
+	})
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDayClosed is returned when a posting's effective date falls in a day its branch has closed
+	ErrDayClosed = errors.New("the day is closed for this branch; reopen it before posting into it")
+	// ErrDayAlreadyClosed is returned when closing a day that is already closed
+	ErrDayAlreadyClosed = errors.New("the day is already closed for this branch")
+	// ErrDayNotClosed is returned when reopening a day that is not closed
+	ErrDayNotClosed = errors.New("the day is not closed for this branch")
+	// ErrDayReopenForbidden is returned when someone other than an owner or admin tries to reopen a day
+	ErrDayReopenForbidden = errors.New("only an owner or admin can reopen a closed day")
+	// ErrDailyCloseBranchNotFound is returned when the branch to close or reopen is not one of the tenant's
+	ErrDailyCloseBranchNotFound = errors.New("branch not found")
+)
+
+// DailyCloseService closes branches' tills at the end of the day and keeps postings out of closed days
+type DailyCloseService struct {
+	db *gorm.DB
+}
+
+// NewDailyCloseService creates a new DailyCloseService
+func NewDailyCloseService(db *gorm.DB) *DailyCloseService {
+	return &DailyCloseService{db: db}
+}
+
+// dailyCloseScope selects the branch's close records for the day starting at dayStart
+func dailyCloseScope(db *gorm.DB, tenantID uint, branchID *uint, dayStart time.Time) *gorm.DB {
+	query := db.Where("tenant_id = ? AND date >= ? AND date < ?", tenantID, dayStart, dayStart.AddDate(0, 0, 1))
+	if branchID != nil {
+		return query.Where("branch_id = ?", *branchID)
+	}
+	return query.Where("branch_id IS NULL")
+}
+
+// ensureTenantBranch returns ErrDailyCloseBranchNotFound unless branchID is nil or one of the tenant's branches
+func ensureTenantBranch(db *gorm.DB, tenantID uint, branchID *uint) error {
+	if branchID == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&models.Branch{}).Where("id = ? AND tenant_id = ?", *branchID, tenantID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrDailyCloseBranchNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint failure from SQLite or PostgreSQL
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || strings.Contains(msg, "duplicate key value")
+}
+
+// ensureDayOpen returns ErrDayClosed if the branch has closed the day containing at
+func ensureDayOpen(db *gorm.DB, tenantID uint, branchID *uint, at time.Time) error {
+	dayStart := startOfDay(at)
+
+	var closed int64
+	if err := dailyCloseScope(db.Model(&models.DailyClose{}), tenantID, branchID, dayStart).
+		Where("status = ?", models.DailyCloseStatusClosed).
+		Count(&closed).Error; err != nil {
+		return err
+	}
+	if closed > 0 {
+		return fmt.Errorf("%w (%s)", ErrDayClosed, dayStart.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// CloseDay snapshots every currency's cash balance for the branch as it stood at the end of the day and
+// closes the day, after which no payment or cash adjustment may be posted into it. A day that was reopened
+// can be closed again; its snapshot is then replaced.
+func (s *DailyCloseService) CloseDay(tenantID uint, branchID *uint, date time.Time, userID uint) (*models.DailyClose, error) {
+	dayStart := startOfDay(date)
+	if dayStart.After(startOfDay(time.Now().In(dayStart.Location()))) {
+		return nil, errors.New("cannot close a day that has not started")
+	}
+	if err := ensureTenantBranch(s.db, tenantID, branchID); err != nil {
+		return nil, err
+	}
+
+	var dailyClose models.DailyClose
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := dailyCloseScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, branchID, dayStart).
+			First(&dailyClose).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && dailyClose.Status == models.DailyCloseStatusClosed {
+			return ErrDayAlreadyClosed
+		}
+
+		now := time.Now()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dailyClose = models.DailyClose{
+				TenantID: tenantID,
+				BranchID: branchID,
+				Date:     dayStart,
+				Status:   models.DailyCloseStatusClosed,
+				ClosedBy: userID,
+				ClosedAt: now,
+			}
+			if err := tx.Create(&dailyClose).Error; err != nil {
+				// Another request closed the day between our lookup and insert
+				if isUniqueViolation(err) {
+					return ErrDayAlreadyClosed
+				}
+				return err
+			}
+		} else {
+			if err := tx.Model(&dailyClose).Updates(map[string]interface{}{
+				"status":     models.DailyCloseStatusClosed,
+				"closed_by":  userID,
+				"closed_at":  now,
+				"updated_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("daily_close_id = ?", dailyClose.ID).Delete(&models.DailyCloseBalance{}).Error; err != nil {
+				return err
+			}
+		}
+
+		balances, err := s.snapshotBalances(tx, tenantID, branchID, dayStart.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		for i := range balances {
+			balances[i].DailyCloseID = dailyClose.ID
+		}
+		if len(balances) > 0 {
+			if err := tx.Create(&balances).Error; err != nil {
+				return err
+			}
+		}
+		dailyClose.Balances = balances
+
+		return NewAuditService(tx).LogAction(userID, &tenantID, models.ActionCloseDay, "DailyClose", strconv.FormatUint(uint64(dailyClose.ID), 10),
+			fmt.Sprintf("Closed %s with %d currency balances", dayStart.Format("2006-01-02"), len(balances)),
+			nil, balances, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dailyClose, nil
+}
+
+// snapshotBalances returns the branch's cash balances at dayEnd as close rows. While the day is still
+// running the balances are simply recalculated; for an earlier day, payments made and adjustments
+// applied since it ended are left out.
+func (s *DailyCloseService) snapshotBalances(tx *gorm.DB, tenantID uint, branchID *uint, dayEnd time.Time) ([]models.DailyCloseBalance, error) {
+	query := tx.Model(&models.CashBalance{}).Where("tenant_id = ?", tenantID)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+
+	var ids []uint
+	if err := query.Order("currency ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	cashService := NewCashBalanceService(tx)
+	dayOver := !time.Now().Before(dayEnd)
+	balances := make([]models.DailyCloseBalance, 0, len(ids))
+	for _, id := range ids {
+		balance, err := cashService.RefreshCashBalance(id)
+		if err != nil {
+			return nil, err
+		}
+		autoBalance, manualAdjustment := balance.AutoCalculatedBalance, balance.ManualAdjustment
+		if dayOver {
+			autoBalance, manualAdjustment, err = cashService.balanceAtWithTx(tx, balance, dayEnd)
+			if err != nil {
+				return nil, err
+			}
+		}
+		balances = append(balances, models.DailyCloseBalance{
+			Currency:              balance.Currency,
+			AutoCalculatedBalance: autoBalance,
+			ManualAdjustment:      manualAdjustment,
+			FinalBalance:          autoBalance.Add(manualAdjustment),
+		})
+	}
+	return balances, nil
+}
+
+// ReopenDay lets postings into a closed day again. Only an owner or admin may reopen a day, and the
+// reason is written to the audit log; the balance snapshot is kept until the day is closed again.
+func (s *DailyCloseService) ReopenDay(tenantID uint, branchID *uint, date time.Time, user *models.User, reason string) (*models.DailyClose, error) {
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+	default:
+		return nil, ErrDayReopenForbidden
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to reopen a day")
+	}
+
+	if err := ensureTenantBranch(s.db, tenantID, branchID); err != nil {
+		return nil, err
+	}
+
+	dayStart := startOfDay(date)
+	var dailyClose models.DailyClose
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := dailyCloseScope(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, branchID, dayStart).
+			Where("status = ?", models.DailyCloseStatusClosed).
+			First(&dailyClose).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDayNotClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&dailyClose).Updates(map[string]interface{}{
+			"status":        models.DailyCloseStatusReopened,
+			"reopened_by":   user.ID,
+			"reopened_at":   now,
+			"reopen_reason": reason,
+			"updated_at":    now,
+		}).Error; err != nil {
+			return err
+		}
+		dailyClose.Status = models.DailyCloseStatusReopened
+		dailyClose.ReopenedBy = &user.ID
+		dailyClose.ReopenedAt = &now
+		dailyClose.ReopenReason = &reason
+
+		return NewAuditService(tx).LogAction(user.ID, &tenantID, models.ActionReopenDay, "DailyClose", strconv.FormatUint(uint64(dailyClose.ID), 10),
+			fmt.Sprintf("Reopened %s: %s", dayStart.Format("2006-01-02"), reason),
+			map[string]interface{}{"status": models.DailyCloseStatusClosed},
+			map[string]interface{}{"status": models.DailyCloseStatusReopened, "reason": reason}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dailyClose, nil
+}
+
+// GetCloses lists the tenant's day closes with their balance snapshots, newest first
+func (s *DailyCloseService) GetCloses(tenantID uint, branchID *uint, startDate, endDate *time.Time) ([]models.DailyClose, error) {
+	query := s.db.Where("tenant_id = ?", tenantID)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+	if startDate != nil {
+		query = query.Where("date >= ?", startOfDay(*startDate))
+	}
+	if endDate != nil {
+		query = query.Where("date < ?", startOfDay(*endDate).AddDate(0, 0, 1))
+	}
+
+	var closes []models.DailyClose
+	err := query.Preload("Balances").Preload("Branch").Order("date DESC, id DESC").Find(&closes).Error
+	return closes, err
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"api/migrations"
+	"api/pkg/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestDailyClose_BlocksPostingsUntilReopened verifies a closed day rejects back-dated payments and cash
+// adjustments, and that reopening it by an admin lets them through again
+func TestDailyClose_BlocksPostingsUntilReopened(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DailyCloseBalance{}, &models.CashAdjustmentApprovalLimit{}, &models.AuditLog{}))
+	tenant, owner := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+	teller := newTestUser(t, db, tenant.ID, "close-teller@example.com", models.RoleTenantUser)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	cashService := NewCashBalanceService(db)
+	closeService := NewDailyCloseService(db)
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	pay := func(amount float64, paidAt time.Time) error {
+		return paymentService.CreatePayment(&models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(amount),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodCash,
+			PaidAt:        paidAt,
+		}, owner.ID)
+	}
+
+	if err := pay(100, yesterday); err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+
+	closed, err := closeService.CloseDay(tenant.ID, nil, yesterday, owner.ID)
+	if err != nil {
+		t.Fatalf("Failed to close the day: %v", err)
+	}
+	if len(closed.Balances) != 1 || closed.Balances[0].Currency != "CAD" || closed.Balances[0].FinalBalance.Float64() != 100 {
+		t.Errorf("Expected a CAD snapshot of 100, got %+v", closed.Balances)
+	}
+	if _, err := closeService.CloseDay(tenant.ID, nil, yesterday, owner.ID); !errors.Is(err, ErrDayAlreadyClosed) {
+		t.Errorf("Expected closing twice to fail, got %v", err)
+	}
+
+	if err := pay(50, yesterday); !errors.Is(err, ErrDayClosed) {
+		t.Errorf("Expected a back-dated payment into the closed day to be rejected, got %v", err)
+	}
+	if err := pay(50, time.Now()); err != nil {
+		t.Errorf("Expected a payment dated today to be accepted, got %v", err)
+	}
+
+	t.Run("AdjustmentsIntoClosedDayRejected", func(t *testing.T) {
+		if _, err := closeService.CloseDay(tenant.ID, nil, time.Now(), owner.ID); err != nil {
+			t.Fatalf("Failed to close today: %v", err)
+		}
+		if _, err := cashService.CreateManualAdjustment(tenant.ID, nil, "CAD", 20, "Found in drawer", owner.ID); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected an adjustment into the closed day to be rejected, got %v", err)
+		}
+		if _, err := closeService.ReopenDay(tenant.ID, nil, time.Now(), owner, "Late adjustment"); err != nil {
+			t.Fatalf("Failed to reopen today: %v", err)
+		}
+	})
+
+	t.Run("ReopenPermitsPostings", func(t *testing.T) {
+		if _, err := closeService.ReopenDay(tenant.ID, nil, yesterday, teller, "Missed receipt"); !errors.Is(err, ErrDayReopenForbidden) {
+			t.Errorf("Expected a teller not to reopen the day, got %v", err)
+		}
+		if _, err := closeService.ReopenDay(tenant.ID, nil, yesterday, owner, " "); err == nil {
+			t.Error("Expected a reason to be required")
+		}
+
+		reopened, err := closeService.ReopenDay(tenant.ID, nil, yesterday, owner, "Missed receipt")
+		if err != nil {
+			t.Fatalf("Failed to reopen the day: %v", err)
+		}
+		if reopened.Status != models.DailyCloseStatusReopened || reopened.ReopenedBy == nil || *reopened.ReopenedBy != owner.ID {
+			t.Errorf("Expected the day to be reopened by the owner, got %+v", reopened)
+		}
+		var audits int64
+		db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ?", models.ActionReopenDay, "1").Count(&audits)
+		if audits != 1 {
+			t.Errorf("Expected one reopen audit entry, got %d", audits)
+		}
+
+		if err := pay(50, yesterday); err != nil {
+			t.Errorf("Expected a back-dated payment into the reopened day to be accepted, got %v", err)
+		}
+		if _, err := cashService.CreateManualAdjustment(tenant.ID, nil, "CAD", 20, "Found in drawer", owner.ID); err != nil {
+			t.Errorf("Expected an adjustment into the reopened day to be accepted, got %v", err)
+		}
+
+		reclosed, err := closeService.CloseDay(tenant.ID, nil, yesterday, owner.ID)
+		if err != nil {
+			t.Fatalf("Failed to close the day again: %v", err)
+		}
+		// Today's payment and adjustment came after the day ended, so they stay out of its snapshot
+		if reclosed.ID != closed.ID || len(reclosed.Balances) != 1 || reclosed.Balances[0].FinalBalance.Float64() != 150 {
+			t.Errorf("Expected the snapshot to be replaced with the end-of-day balance of 150, got %+v", reclosed.Balances)
+		}
+		if reclosed.Balances[0].ManualAdjustment.Float64() != 0 {
+			t.Errorf("Expected no manual adjustment at the end of the day, got %v", reclosed.Balances[0].ManualAdjustment)
+		}
+	})
+}
+
+// TestDailyClose_OnePerBranchAndDay verifies the company-wide till can't be closed twice for the same day at
+// the database level, and that a day can't be closed for another tenant's branch
+func TestDailyClose_OnePerBranchAndDay(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DailyCloseBalance{}, &models.AuditLog{}))
+	require.NoError(t, migrations.AddDailyCloseUniqueIndex(db))
+	tenant, owner := createTestTenantAndUser(db)
+	other := newTestTenant(t, db, "Other Exchange")
+	otherBranch := newTestBranch(t, db, other.ID, "Other Branch", "OTH")
+
+	closeService := NewDailyCloseService(db)
+	yesterday := startOfDay(time.Now().AddDate(0, 0, -1))
+
+	_, err := closeService.CloseDay(tenant.ID, nil, yesterday, owner.ID)
+	require.NoError(t, err)
+
+	duplicate := models.DailyClose{TenantID: tenant.ID, Date: yesterday, Status: models.DailyCloseStatusClosed, ClosedBy: owner.ID, ClosedAt: time.Now()}
+	err = db.Create(&duplicate).Error
+	if err == nil || !isUniqueViolation(err) {
+		t.Errorf("Expected a second company-wide close for the day to violate the unique index, got %v", err)
+	}
+
+	if _, err := closeService.CloseDay(tenant.ID, &otherBranch.ID, yesterday, owner.ID); !errors.Is(err, ErrDailyCloseBranchNotFound) {
+		t.Errorf("Expected closing another tenant's branch to be refused, got %v", err)
+	}
+	if _, err := closeService.ReopenDay(tenant.ID, &otherBranch.ID, yesterday, owner, "Wrong branch"); !errors.Is(err, ErrDailyCloseBranchNotFound) {
+		t.Errorf("Expected reopening another tenant's branch to be refused, got %v", err)
+	}
+}
+
+// TestDailyClose_BlocksEditsAndReversalsIntoClosedDay verifies payment edits, direct cash balance updates and
+// transaction deletes and restores are rejected when either the day they change or today is closed
+func TestDailyClose_BlocksEditsAndReversalsIntoClosedDay(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.DailyCloseBalance{}, &models.AuditLog{}))
+	tenant, owner := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	transactionService := NewTransactionService(db, NewExchangeRateService(db))
+	cashService := NewCashBalanceService(db)
+	closeService := NewDailyCloseService(db)
+	ctx := context.Background()
+	twoDaysAgo, yesterday := time.Now().AddDate(0, 0, -2), time.Now().AddDate(0, 0, -1)
+
+	pay := func(transactionID string, paidAt time.Time) *models.Payment {
+		t.Helper()
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: transactionID,
+			Amount:        models.NewDecimal(100),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodCash,
+			PaidAt:        paidAt,
+		}
+		require.NoError(t, paymentService.CreatePayment(payment, owner.ID), "failed to create payment")
+		return payment
+	}
+	edit := func(payment *models.Payment) error {
+		return paymentService.UpdatePayment(payment.ID, tenant.ID, map[string]interface{}{"amount": 120.0}, owner.ID, "Miscounted")
+	}
+
+	restorable := pay("txn-batch-001", twoDaysAgo)
+	closedDayPayment := pay("txn-batch-002", yesterday)
+	openDayPayment := pay("txn-batch-003", twoDaysAgo)
+
+	_, err := closeService.CloseDay(tenant.ID, nil, yesterday, owner.ID)
+	require.NoError(t, err, "failed to close yesterday")
+
+	t.Run("PaymentDayClosed", func(t *testing.T) {
+		if err := edit(closedDayPayment); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected editing a payment made on a closed day to be rejected, got %v", err)
+		}
+		if _, err := transactionService.DeleteTransaction(ctx, "txn-batch-002", tenant.ID, owner.ID); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected deleting a transaction paid on a closed day to be rejected, got %v", err)
+		}
+	})
+
+	_, err = transactionService.DeleteTransaction(ctx, "txn-batch-001", tenant.ID, owner.ID)
+	require.NoError(t, err, "failed to delete a transaction while its days are open")
+	_, err = closeService.CloseDay(tenant.ID, nil, time.Now(), owner.ID)
+	require.NoError(t, err, "failed to close today")
+
+	t.Run("TodayClosed", func(t *testing.T) {
+		if err := edit(openDayPayment); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected an edit posting into a closed today to be rejected, got %v", err)
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return cashService.UpdateCashBalance(tx, tenant.ID, nil, "CAD", 20, "Recount", owner.ID)
+		}); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected a cash balance update into a closed today to be rejected, got %v", err)
+		}
+		if _, err := transactionService.DeleteTransaction(ctx, "txn-batch-003", tenant.ID, owner.ID); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected a delete posting reversals into a closed today to be rejected, got %v", err)
+		}
+		if _, err := transactionService.RestoreTransaction(ctx, "txn-batch-001", tenant.ID, owner.ID); !errors.Is(err, ErrDayClosed) {
+			t.Errorf("Expected a restore posting into a closed today to be rejected, got %v", err)
+		}
+	})
+
+	var stored models.Payment
+	require.NoError(t, db.First(&stored, openDayPayment.ID).Error)
+	if stored.Amount.Float64() != 100 || stored.IsEdited {
+		t.Errorf("Expected the rejected edit to leave the payment unchanged, got %s", stored.Amount.String())
+	}
+	var deleted models.Transaction
+	require.NoError(t, db.Unscoped().First(&deleted, "id = ?", restorable.TransactionID).Error)
+	if !deleted.DeletedAt.Valid {
+		t.Error("Expected the rejected restore to leave the transaction deleted")
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DashboardCache holds recently computed dashboard data per tenant and branch. Entries expire after the
+// tenant's DashboardCacheSeconds and are dropped as soon as one of the tenant's transactions, payments or
+// settlements changes.
+type DashboardCache struct {
+	mu          sync.Mutex
+	entries     map[dashboardCacheKey]dashboardCacheEntry
+	generations map[uint]uint64 // Bumped on every invalidation so data computed before it is not stored
+	now         func() time.Time
+}
+
+type dashboardCacheKey struct {
+	tenantID uint
+	branchID uint // 0 for the tenant-wide dashboard
+}
+
+type dashboardCacheEntry struct {
+	data      *DashboardData
+	expiresAt time.Time
+}
+
+// dashboardInvalidatingEvents are the event name prefixes that change dashboard figures
+var dashboardInvalidatingEvents = []string{"transaction.", "payment.", "settlement."}
+
+// NewDashboardCache creates an empty dashboard cache
+func NewDashboardCache() *DashboardCache {
+	return &DashboardCache{
+		entries:     make(map[dashboardCacheKey]dashboardCacheEntry),
+		generations: make(map[uint]uint64),
+		now:         time.Now,
+	}
+}
+
+func newDashboardCacheKey(tenantID uint, branchID *uint) dashboardCacheKey {
+	key := dashboardCacheKey{tenantID: tenantID}
+	if branchID != nil {
+		key.branchID = *branchID
+	}
+	return key
+}
+
+// Get returns the cached dashboard for the tenant and branch if it has not expired
+func (c *DashboardCache) Get(tenantID uint, branchID *uint) (*DashboardData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := newDashboardCacheKey(tenantID, branchID)
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Generation returns the tenant's invalidation counter; pass it to Set once the dashboard has been computed
+func (c *DashboardCache) Generation(tenantID uint) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[tenantID]
+}
+
+// Set caches the dashboard for ttl, unless the tenant was invalidated since generation was read
+func (c *DashboardCache) Set(tenantID uint, branchID *uint, data *DashboardData, ttl time.Duration, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generations[tenantID] != generation {
+		return
+	}
+	c.entries[newDashboardCacheKey(tenantID, branchID)] = dashboardCacheEntry{data: data, expiresAt: c.now().Add(ttl)}
+}
+
+// InvalidateTenant drops every cached dashboard of the tenant
+func (c *DashboardCache) InvalidateTenant(tenantID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[tenantID]++
+	for key := range c.entries {
+		if key.tenantID == tenantID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// HandleEvent invalidates the event's tenant when the event changes dashboard figures
+func (c *DashboardCache) HandleEvent(event DomainEvent) {
+	for _, prefix := range dashboardInvalidatingEvents {
+		if strings.HasPrefix(event.Name, prefix) {
+			c.InvalidateTenant(event.TenantID)
+			return
+		}
+	}
+}
+
+var (
+	dashboardCacheInstance *DashboardCache
+	dashboardCacheOnce     sync.Once
+)
+
+// GetDashboardCache returns the process-wide dashboard cache, subscribed to the event bus
+func GetDashboardCache() *DashboardCache {
+	dashboardCacheOnce.Do(func() {
+		dashboardCacheInstance = NewDashboardCache()
+		GetEventBus().Subscribe(dashboardCacheInstance.HandleEvent)
+	})
+	return dashboardCacheInstance
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"api/pkg/models"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,12 +14,13 @@ import (
 
 // DashboardService provides real-time dashboard metrics
 type DashboardService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache *DashboardCache
 }
 
 // NewDashboardService creates a new DashboardService
 func NewDashboardService(db *gorm.DB) *DashboardService {
-	return &DashboardService{db: db}
+	return &DashboardService{db: db, cache: GetDashboardCache()}
 }
 
 // RemittanceSummary represents remittance overview
@@ -51,12 +55,39 @@ type DebtAging struct {
 	IsCritical bool    `json:"isCritical"`
 }
 
-// RateTrend represents exchange rate trend
+// InstallmentAging buckets unpaid payment plan installments by how far past their due date they are
+type InstallmentAging struct {
+	Bucket      string             `json:"bucket"` // e.g., "Not yet due", "1-7 days overdue"
+	Count       int                `json:"count"`
+	Outstanding map[string]float64 `json:"outstanding"` // Unpaid amount per currency
+	IsWarning   bool               `json:"isWarning"`
+	IsCritical  bool               `json:"isCritical"`
+}
+
+// CashFlowForecastPoint is the cash expected to move on one day of a forecast, in the tenant's base currency
+type CashFlowForecastPoint struct {
+	Date        string  `json:"date"` // YYYY-MM-DD
+	ExpectedIn  float64 `json:"expectedIn"`
+	ExpectedOut float64 `json:"expectedOut"`
+	NetPosition float64 `json:"netPosition"` // Running total of expected in minus expected out since the first day
+}
+
+// CashFlowForecast projects expected inflows and outflows over the coming days
+type CashFlowForecast struct {
+	BaseCurrency          string                  `json:"baseCurrency"`
+	Days                  int                     `json:"days"`
+	Series                []CashFlowForecastPoint `json:"series"`
+	UnconvertedCurrencies []string                `json:"unconvertedCurrencies"` // Currencies left out because no rate to the base currency was found
+}
+
+// RateTrend represents a currency pair's exchange rate on one day
 type RateTrend struct {
-	Date     string  `json:"date"`
-	BuyRate  float64 `json:"buyRate"`
-	SellRate float64 `json:"sellRate"`
-	Spread   float64 `json:"spread"`
+	Date         string  `json:"date"`
+	BaseCurrency string  `json:"baseCurrency"`
+	Currency     string  `json:"currency"` // Target currency of the pair
+	BuyRate      float64 `json:"buyRate"`
+	SellRate     float64 `json:"sellRate"`
+	Spread       float64 `json:"spread"`
 }
 
 // Alert represents a dashboard alert
@@ -117,10 +148,11 @@ type DashboardData struct {
 	MonthMetrics    DailyMetrics         `json:"monthMetrics"`
 
 	// Charts Data
-	DebtAging    []DebtAging   `json:"debtAging"`
-	RateTrends   []RateTrend   `json:"rateTrends"`
-	DailyProfit  []DailyProfit `json:"dailyProfit"`
-	DailyVolumes []DailyVolume `json:"dailyVolumes"` // New field
+	DebtAging        []DebtAging        `json:"debtAging"`
+	InstallmentAging []InstallmentAging `json:"installmentAging"` // Partial-payment plans, by installment due date
+	RateTrends       []RateTrend        `json:"rateTrends"`
+	DailyProfit      []DailyProfit      `json:"dailyProfit"`
+	DailyVolumes     []DailyVolume      `json:"dailyVolumes"` // New field
 
 	// Alerts
 	Alerts []Alert `json:"alerts"`
@@ -134,6 +166,33 @@ type DashboardData struct {
 	LastUpdated time.Time `json:"lastUpdated"`
 }
 
+// GetCachedDashboardData returns the dashboard from cache while it is fresh and computes and caches it
+// otherwise. forceRefresh skips the cached copy; tenants with DashboardCacheSeconds 0 are never cached.
+func (s *DashboardService) GetCachedDashboardData(tenantID uint, branchID *uint, forceRefresh bool) (*DashboardData, error) {
+	settings, err := NewTenantSettingsService(s.db).GetSettings(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	ttl := time.Duration(settings.DashboardCacheSeconds) * time.Second
+	if s.cache == nil || ttl <= 0 {
+		return s.GetDashboardData(tenantID, branchID)
+	}
+
+	if !forceRefresh {
+		if data, ok := s.cache.Get(tenantID, branchID); ok {
+			return data, nil
+		}
+	}
+
+	generation := s.cache.Generation(tenantID)
+	data, err := s.GetDashboardData(tenantID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(tenantID, branchID, data, ttl, generation)
+	return data, nil
+}
+
 // GetDashboardData returns comprehensive dashboard data
 // Uses parallel query execution for improved performance
 func (s *DashboardService) GetDashboardData(tenantID uint, branchID *uint) (*DashboardData, error) {
@@ -145,7 +204,7 @@ func (s *DashboardService) GetDashboardData(tenantID uint, branchID *uint) (*Das
 	var wg sync.WaitGroup
 
 	// Parallel fetching of independent data
-	wg.Add(11) // Increased from 10
+	wg.Add(12)
 
 	// Get outgoing summary
 	go func() {
@@ -187,6 +246,12 @@ func (s *DashboardService) GetDashboardData(tenantID uint, branchID *uint) (*Das
 		dashboard.DebtAging = s.getDebtAging(tenantID, branchID)
 	}()
 
+	// Get installment aging
+	go func() {
+		defer wg.Done()
+		dashboard.InstallmentAging = s.getInstallmentAging(tenantID, branchID, time.Now())
+	}()
+
 	// Get rate trends (last 7 days)
 	go func() {
 		defer wg.Done()
@@ -430,31 +495,40 @@ func (s *DashboardService) getMetrics(tenantID uint, branchID *uint, startDate,
 	return metrics
 }
 
+// getDebtAging buckets unsettled outgoing remittances by age using the tenant's debt aging buckets
 func (s *DashboardService) getDebtAging(tenantID uint, branchID *uint) []DebtAging {
-	buckets := []DebtAging{
-		{Bucket: "0-7 days", IsWarning: false, IsCritical: false},
-		{Bucket: "8-14 days", IsWarning: false, IsCritical: false},
-		{Bucket: "15-30 days", IsWarning: true, IsCritical: false},
-		{Bucket: "30+ days", IsWarning: false, IsCritical: true},
+	definitions := s.GetDebtAgingBuckets(tenantID)
+	buckets := make([]DebtAging, len(definitions))
+	for i, definition := range definitions {
+		buckets[i] = DebtAging{Bucket: definition.Label, IsWarning: definition.IsWarning, IsCritical: definition.IsCritical}
+	}
+
+	// One WHEN per bounded bucket; debt older than all of them falls into the last bucket
+	caseExpr := "CASE"
+	args := make([]interface{}, 0, len(definitions)+4)
+	for i, definition := range definitions {
+		if definition.MaxDays == nil {
+			break
+		}
+		caseExpr += fmt.Sprintf(" WHEN julianday('now') - julianday(created_at) <= ? THEN %d", i)
+		args = append(args, *definition.MaxDays)
 	}
+	caseExpr += fmt.Sprintf(" ELSE %d END", len(definitions)-1)
 
 	query := `
 		SELECT 
-			CASE 
-				WHEN julianday('now') - julianday(created_at) <= 7 THEN 0
-				WHEN julianday('now') - julianday(created_at) <= 14 THEN 1
-				WHEN julianday('now') - julianday(created_at) <= 30 THEN 2
-				ELSE 3
-			END as bucket_idx,
+			` + caseExpr + ` as bucket_idx,
 			COUNT(*) as count,
 			COALESCE(SUM(remaining_irr), 0) as total_irr,
 			COALESCE(SUM(remaining_irr / buy_rate_cad), 0) as total_cad
 		FROM outgoing_remittances
 		WHERE tenant_id = ? AND status IN (?, ?) AND remaining_irr > 0
 	`
+	args = append(args, tenantID, models.RemittanceStatusPending, models.RemittanceStatusPartial)
 
 	if branchID != nil {
 		query += " AND branch_id = ?"
+		args = append(args, *branchID)
 	}
 	query += " GROUP BY bucket_idx ORDER BY bucket_idx"
 
@@ -465,11 +539,7 @@ func (s *DashboardService) getDebtAging(tenantID uint, branchID *uint) []DebtAgi
 		TotalCAD  float64
 	}
 
-	if branchID != nil {
-		s.db.Raw(query, tenantID, models.RemittanceStatusPending, models.RemittanceStatusPartial, *branchID).Scan(&results)
-	} else {
-		s.db.Raw(query, tenantID, models.RemittanceStatusPending, models.RemittanceStatusPartial).Scan(&results)
-	}
+	s.db.Raw(query, args...).Scan(&results)
 
 	for _, r := range results {
 		if r.BucketIdx >= 0 && r.BucketIdx < len(buckets) {
@@ -482,38 +552,270 @@ func (s *DashboardService) getDebtAging(tenantID uint, branchID *uint) []DebtAgi
 	return buckets
 }
 
+// GetDebtAgingBuckets returns the tenant's debt aging buckets in order, falling back to the defaults if none are configured
+func (s *DashboardService) GetDebtAgingBuckets(tenantID uint) []models.DebtAgingBucket {
+	var buckets []models.DebtAgingBucket
+	if err := s.db.Where("tenant_id = ?", tenantID).Order("position ASC").Find(&buckets).Error; err != nil || len(buckets) == 0 {
+		return models.DefaultDebtAgingBuckets(tenantID)
+	}
+	return buckets
+}
+
+// DebtAgingBucketInput describes one bucket when configuring debt aging
+type DebtAgingBucketInput struct {
+	Label      string `json:"label"`
+	MaxDays    *int   `json:"maxDays"`
+	IsWarning  bool   `json:"isWarning"`
+	IsCritical bool   `json:"isCritical"`
+}
+
+// SetDebtAgingBuckets replaces the tenant's debt aging buckets. Buckets are given youngest first with
+// increasing MaxDays, and only the last one is open-ended. An empty list restores the defaults.
+func (s *DashboardService) SetDebtAgingBuckets(tenantID uint, inputs []DebtAgingBucketInput) ([]models.DebtAgingBucket, error) {
+	buckets := make([]models.DebtAgingBucket, 0, len(inputs))
+	for i, input := range inputs {
+		label := strings.TrimSpace(input.Label)
+		if label == "" {
+			return nil, fmt.Errorf("bucket %d needs a label", i+1)
+		}
+		last := i == len(inputs)-1
+		if last && input.MaxDays != nil {
+			return nil, errors.New("the last bucket must be open-ended so every debt is counted")
+		}
+		if !last {
+			if input.MaxDays == nil || *input.MaxDays < 0 {
+				return nil, fmt.Errorf("bucket %q needs a maximum age in days", label)
+			}
+			if i > 0 && *input.MaxDays <= *inputs[i-1].MaxDays {
+				return nil, fmt.Errorf("bucket %q must cover older debt than the one before it", label)
+			}
+		}
+		buckets = append(buckets, models.DebtAgingBucket{
+			TenantID:   tenantID,
+			Position:   i,
+			Label:      label,
+			MaxDays:    input.MaxDays,
+			IsWarning:  input.IsWarning,
+			IsCritical: input.IsCritical,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", tenantID).Delete(&models.DebtAgingBucket{}).Error; err != nil {
+			return err
+		}
+		if len(buckets) == 0 {
+			return nil
+		}
+		return tx.Create(&buckets).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save debt aging buckets: %w", err)
+	}
+
+	return s.GetDebtAgingBuckets(tenantID), nil
+}
+
+// getInstallmentAging buckets unpaid installments by days past their due date
+func (s *DashboardService) getInstallmentAging(tenantID uint, branchID *uint, now time.Time) []InstallmentAging {
+	buckets := []InstallmentAging{
+		{Bucket: "Not yet due"},
+		{Bucket: "1-7 days overdue"},
+		{Bucket: "8-30 days overdue", IsWarning: true},
+		{Bucket: "30+ days overdue", IsCritical: true},
+	}
+	for i := range buckets {
+		buckets[i].Outstanding = make(map[string]float64)
+	}
+
+	query := s.db.Model(&models.PaymentInstallment{}).
+		Joins("JOIN transactions ON transactions.id = payment_installments.transaction_id AND transactions.deleted_at IS NULL").
+		Where("payment_installments.tenant_id = ? AND payment_installments.status <> ? AND transactions.status <> ?",
+			tenantID, models.InstallmentStatusPaid, models.StatusCancelled)
+	if branchID != nil {
+		query = query.Where("transactions.branch_id = ?", *branchID)
+	}
+
+	var installments []models.PaymentInstallment
+	if err := query.Find(&installments).Error; err != nil {
+		return buckets
+	}
+
+	for _, installment := range installments {
+		daysOverdue := int(now.Sub(installment.DueDate).Hours() / 24)
+		idx := 3
+		switch {
+		case !now.After(installment.DueDate):
+			idx = 0
+		case daysOverdue <= 7:
+			idx = 1
+		case daysOverdue <= 30:
+			idx = 2
+		}
+		buckets[idx].Count++
+		buckets[idx].Outstanding[installment.Currency] += installment.OutstandingAmount().Float64()
+	}
+
+	return buckets
+}
+
+// GetCashFlowForecast projects, for each of the next days starting today, the cash expected in from
+// incoming remittances still to be allocated and unpaid payment plan installments, and the cash
+// expected out to outgoing remittances still to be paid out. Remittances carry no due date, so an
+// outstanding one is expected today; installments are expected on their due date, or today if overdue.
+func (s *DashboardService) GetCashFlowForecast(tenantID uint, branchID *uint, days int) (*CashFlowForecast, error) {
+	if days <= 0 {
+		days = 30
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	baseCurrency := NewTenantSettingsService(s.db).GetBaseCurrency(tenantID)
+
+	forecast := &CashFlowForecast{
+		BaseCurrency:          baseCurrency,
+		Days:                  days,
+		Series:                make([]CashFlowForecastPoint, days),
+		UnconvertedCurrencies: make([]string, 0),
+	}
+	for i := range forecast.Series {
+		forecast.Series[i].Date = today.AddDate(0, 0, i).Format("2006-01-02")
+	}
+
+	rateService := NewExchangeRateService(s.db)
+	rates := make(map[string]*models.Decimal)
+	toBase := func(amount models.Decimal, currency string) (float64, bool) {
+		currency = strings.ToUpper(currency)
+		if currency == baseCurrency {
+			return amount.Float64(), true
+		}
+		rate, seen := rates[currency]
+		if !seen {
+			if found, err := rateService.GetHistoricalRate(tenantID, currency, baseCurrency, now); err == nil {
+				rate = &found
+			} else {
+				forecast.UnconvertedCurrencies = append(forecast.UnconvertedCurrencies, currency)
+			}
+			rates[currency] = rate
+		}
+		if rate == nil {
+			return 0, false
+		}
+		return amount.Mul(*rate).Float64(), true
+	}
+
+	// dayIndex places a date on the forecast, pulling anything overdue onto today
+	dayIndex := func(at time.Time) int {
+		idx := int(at.Sub(today).Hours() / 24)
+		if idx < 0 {
+			return 0
+		}
+		return idx
+	}
+
+	openStatuses := []string{models.RemittanceStatusPending, models.RemittanceStatusPartial}
+
+	var incoming []models.IncomingRemittance
+	incomingQuery := s.db.Where("tenant_id = ? AND status IN ? AND remaining_irr > 0", tenantID, openStatuses)
+	if branchID != nil {
+		incomingQuery = incomingQuery.Where("branch_id = ?", *branchID)
+	}
+	if err := incomingQuery.Find(&incoming).Error; err != nil {
+		return nil, fmt.Errorf("failed to load incoming remittances: %w", err)
+	}
+	for _, remittance := range incoming {
+		if !remittance.SellRateCAD.IsPositive() {
+			continue
+		}
+		if amount, ok := toBase(remittance.RemainingIRR.Div(remittance.SellRateCAD), "CAD"); ok {
+			forecast.Series[0].ExpectedIn += amount
+		}
+	}
+
+	var outgoing []models.OutgoingRemittance
+	outgoingQuery := s.db.Where("tenant_id = ? AND status IN ? AND remaining_irr > 0", tenantID, openStatuses)
+	if branchID != nil {
+		outgoingQuery = outgoingQuery.Where("branch_id = ?", *branchID)
+	}
+	if err := outgoingQuery.Find(&outgoing).Error; err != nil {
+		return nil, fmt.Errorf("failed to load outgoing remittances: %w", err)
+	}
+	for _, remittance := range outgoing {
+		if !remittance.BuyRateCAD.IsPositive() {
+			continue
+		}
+		if amount, ok := toBase(remittance.RemainingIRR.Div(remittance.BuyRateCAD), remittance.SettlementCurrency); ok {
+			forecast.Series[0].ExpectedOut += amount
+		}
+	}
+
+	var installments []models.PaymentInstallment
+	installmentQuery := s.db.Model(&models.PaymentInstallment{}).
+		Joins("JOIN transactions ON transactions.id = payment_installments.transaction_id AND transactions.deleted_at IS NULL").
+		Where("payment_installments.tenant_id = ? AND payment_installments.status <> ? AND transactions.status <> ? AND payment_installments.due_date < ?",
+			tenantID, models.InstallmentStatusPaid, models.StatusCancelled, today.AddDate(0, 0, days))
+	if branchID != nil {
+		installmentQuery = installmentQuery.Where("transactions.branch_id = ?", *branchID)
+	}
+	if err := installmentQuery.Find(&installments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payment installments: %w", err)
+	}
+	for _, installment := range installments {
+		if amount, ok := toBase(installment.OutstandingAmount(), installment.Currency); ok {
+			forecast.Series[dayIndex(installment.DueDate)].ExpectedIn += amount
+		}
+	}
+
+	net := 0.0
+	for i := range forecast.Series {
+		net += forecast.Series[i].ExpectedIn - forecast.Series[i].ExpectedOut
+		forecast.Series[i].NetPosition = net
+	}
+
+	sort.Strings(forecast.UnconvertedCurrencies)
+	return forecast, nil
+}
+
+// getRateTrends averages the stored rate snapshots of each currency pair per day over the last `days` days.
+// Snapshots are taken on a schedule, so days without trading still have a point.
 func (s *DashboardService) getRateTrends(tenantID uint, days int) []RateTrend {
 	var trends []RateTrend
 
-	// Get average rates from settlements per day
-	query := `
-		SELECT 
-			DATE(created_at) as date,
-			AVG(outgoing_buy_rate) as buy_rate,
-			AVG(incoming_sell_rate) as sell_rate
-		FROM remittance_settlements
-		WHERE tenant_id = ? AND created_at >= date('now', ?)
-		GROUP BY DATE(created_at)
-		ORDER BY date DESC
-		LIMIT ?
-	`
-
 	var results []struct {
-		Date     string
-		BuyRate  float64
-		SellRate float64
+		Date           string
+		BaseCurrency   string
+		TargetCurrency string
+		BuyRate        float64
+		SellRate       float64
+		Spread         float64
 	}
 
-	// Fixed: Use fmt.Sprintf instead of string(rune(days)) which produces wrong output
-	daysParam := fmt.Sprintf("-%d days", days)
-	s.db.Raw(query, tenantID, daysParam, days).Scan(&results)
+	since := startOfDay(time.Now()).AddDate(0, 0, -(days - 1))
+	s.db.Model(&models.ExchangeRateSnapshot{}).
+		Select(`
+			DATE(captured_at) as date,
+			base_currency,
+			target_currency,
+			AVG(buy_rate) as buy_rate,
+			AVG(sell_rate) as sell_rate,
+			AVG(buy_rate - sell_rate) as spread
+		`).
+		Where("tenant_id = ? AND captured_at >= ?", tenantID, since).
+		Group("DATE(captured_at), base_currency, target_currency").
+		Order("date DESC, base_currency, target_currency").
+		Scan(&results)
 
 	for _, r := range results {
 		trends = append(trends, RateTrend{
-			Date:     r.Date,
-			BuyRate:  r.BuyRate,
-			SellRate: r.SellRate,
-			Spread:   r.BuyRate - r.SellRate,
+			Date:         r.Date,
+			BaseCurrency: r.BaseCurrency,
+			Currency:     r.TargetCurrency,
+			BuyRate:      r.BuyRate,
+			SellRate:     r.SellRate,
+			Spread:       r.Spread,
 		})
 	}
 
@@ -556,7 +858,7 @@ func (s *DashboardService) getActiveRemittanceCount(tenantID uint) int {
 func (s *DashboardService) getPendingPickupCount(tenantID uint) int {
 	var count int64
 	s.db.Model(&models.PickupTransaction{}).
-		Where("tenant_id = ? AND status = ?", tenantID, "PENDING").
+		Where("tenant_id = ? AND status IN ?", tenantID, []string{models.PickupStatusPending, models.PickupStatusReady}).
 		Count(&count)
 	return int(count)
 }
@@ -593,17 +895,11 @@ func (s *DashboardService) generateAlerts(tenantID uint, branchID *uint, dashboa
 		})
 	}
 
-	// Low cash balance alert (example threshold)
-	for _, balance := range dashboard.CashBalances {
-		if balance.Currency == "CAD" && balance.Balance < 1000 {
-			alerts = append(alerts, Alert{
-				Type:    "warning",
-				Title:   "Low Cash Balance",
-				Message: "CAD cash balance is below $1,000",
-				Link:    "/cash-balances",
-			})
-		}
-	}
+	// Currencies below the tenant's configured minimum
+	alerts = append(alerts, s.getLowCashAlerts(tenantID, dashboard.CashBalances)...)
+
+	// Branch cash floats outside their target band
+	alerts = append(alerts, s.getFloatTargetAlerts(tenantID, branchID)...)
 
 	// Pending pickups
 	if dashboard.PendingPickupsCount > 0 {
@@ -617,3 +913,91 @@ func (s *DashboardService) generateAlerts(tenantID uint, branchID *uint, dashboa
 
 	return alerts
 }
+
+// getLowCashAlerts warns about every currency whose balance is below the tenant's configured minimum.
+// A currency with a threshold but no balance on record counts as holding nothing.
+func (s *DashboardService) getLowCashAlerts(tenantID uint, balances []CashBalanceSummary) []Alert {
+	alerts := make([]Alert, 0)
+
+	var thresholds []models.CashBalanceThreshold
+	if err := s.db.Where("tenant_id = ?", tenantID).Order("currency ASC").Find(&thresholds).Error; err != nil {
+		return alerts
+	}
+
+	held := make(map[string]models.Decimal, len(balances))
+	for _, balance := range balances {
+		held[balance.Currency] = models.NewDecimal(balance.Balance)
+	}
+
+	for _, threshold := range thresholds {
+		balance, ok := held[threshold.Currency]
+		if !ok {
+			balance = models.Zero()
+		}
+		if !balance.LessThan(threshold.MinimumBalance) {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Type:  "warning",
+			Title: "Low Cash Balance",
+			Message: fmt.Sprintf("%s cash balance of %s is %s below its minimum of %s",
+				threshold.Currency, balance.StringFixed(2),
+				threshold.MinimumBalance.Sub(balance).StringFixed(2), threshold.MinimumBalance.StringFixed(2)),
+			Link: "/cash-balances",
+		})
+	}
+
+	return alerts
+}
+
+// getFloatTargetAlerts warns about branches whose cash balance has drifted outside a float target's band
+func (s *DashboardService) getFloatTargetAlerts(tenantID uint, branchID *uint) []Alert {
+	alerts := make([]Alert, 0)
+
+	var targets []models.BranchFloatTarget
+	query := s.db.Preload("Branch").Where("tenant_id = ?", tenantID)
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+	if err := query.Order("branch_id ASC, currency ASC").Find(&targets).Error; err != nil {
+		return alerts
+	}
+
+	hundred := models.NewDecimal(100)
+	for _, target := range targets {
+		if !target.TargetAmount.IsPositive() {
+			continue
+		}
+
+		var balance struct{ Total models.Decimal }
+		s.db.Model(&models.CashBalance{}).
+			Select("COALESCE(SUM(final_balance), 0) as total").
+			Where("tenant_id = ? AND branch_id = ? AND currency = ?", tenantID, target.BranchID, target.Currency).
+			Scan(&balance)
+
+		deviation := balance.Total.Sub(target.TargetAmount).Div(target.TargetAmount).Mul(hundred)
+		if deviation.Abs().LessThanOrEqual(target.BandPercent) {
+			continue
+		}
+
+		branchName := fmt.Sprintf("Branch %d", target.BranchID)
+		if target.Branch != nil {
+			branchName = target.Branch.Name
+		}
+		direction, title := "above", "Cash Float Above Target"
+		if deviation.IsNegative() {
+			direction, title = "below", "Cash Float Below Target"
+		}
+		alerts = append(alerts, Alert{
+			Type:  "warning",
+			Title: title,
+			Message: fmt.Sprintf("%s %s balance of %s is %s%% %s its float target of %s",
+				branchName, target.Currency, balance.Total.StringFixed(2),
+				deviation.Abs().StringFixed(0), direction, target.TargetAmount.StringFixed(2)),
+			EntityID: target.BranchID,
+			Link:     "/cash-balances",
+		})
+	}
+
+	return alerts
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFloatTargetAlerts_OnlyOutsideBand verifies a branch below its float band raises an alert and one within it does not
+func TestFloatTargetAlerts_OnlyOutsideBand(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.CashBalance{}, &models.BranchFloatTarget{})
+
+	tenant := newTestTenant(t, db, "Float Exchange")
+
+	branchService := NewBranchService(db)
+	newBranchWithBalance := func(name, code string, balance float64) *models.Branch {
+		branch := &models.Branch{TenantID: tenant.ID, Name: name, BranchCode: code, Status: models.BranchStatusActive}
+		if err := db.Create(branch).Error; err != nil {
+			t.Fatalf("Failed to create branch: %v", err)
+		}
+		db.Create(&models.CashBalance{
+			TenantID:     tenant.ID,
+			BranchID:     &branch.ID,
+			Currency:     "CAD",
+			FinalBalance: models.NewDecimal(balance),
+		})
+		band := 10.0
+		if _, err := branchService.CreateFloatTarget(branch.ID, tenant.ID, FloatTargetRequest{
+			Currency:     "cad",
+			TargetAmount: 10000,
+			BandPercent:  &band,
+		}, 0); err != nil {
+			t.Fatalf("Failed to create float target: %v", err)
+		}
+		return branch
+	}
+
+	short := newBranchWithBalance("Short Branch", "SHO-001", 7000)   // 30% below target
+	steady := newBranchWithBalance("Steady Branch", "STE-001", 9500) // 5% below target, within band
+
+	service := NewDashboardService(db)
+
+	alerts := service.getFloatTargetAlerts(tenant.ID, nil)
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 float alert, got %d: %+v", len(alerts), alerts)
+	}
+	if alerts[0].EntityID != short.ID || alerts[0].Title != "Cash Float Below Target" {
+		t.Errorf("Expected a below-target alert for the short branch, got %+v", alerts[0])
+	}
+	if !strings.Contains(alerts[0].Message, "30% below") {
+		t.Errorf("Expected the alert to report a 30%% shortfall, got %q", alerts[0].Message)
+	}
+
+	if alerts := service.getFloatTargetAlerts(tenant.ID, &steady.ID); len(alerts) != 0 {
+		t.Errorf("Expected no alert for a balance within the band, got %+v", alerts)
+	}
+}
+
+// TestLowCashAlerts_PerCurrencyThresholds verifies only currencies below their configured minimum raise alerts
+func TestLowCashAlerts_PerCurrencyThresholds(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.CashBalance{}, &models.CashBalanceThreshold{})
+
+	tenant := newTestTenant(t, db, "Threshold Exchange")
+
+	cashService := NewCashBalanceService(db)
+	for currency, minimum := range map[string]float64{"usd": 5000, "EUR": 2000} {
+		if _, err := cashService.SetThreshold(tenant.ID, currency, minimum, 0); err != nil {
+			t.Fatalf("Failed to set %s threshold: %v", currency, err)
+		}
+	}
+
+	// USD is short, EUR is above its floor, and CAD has no threshold despite a tiny balance
+	balances := []CashBalanceSummary{
+		{Currency: "USD", Balance: 3200},
+		{Currency: "EUR", Balance: 2500},
+		{Currency: "CAD", Balance: 10},
+	}
+
+	service := NewDashboardService(db)
+	alerts := service.getLowCashAlerts(tenant.ID, balances)
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 low cash alert, got %d: %+v", len(alerts), alerts)
+	}
+	if !strings.Contains(alerts[0].Message, "USD") || !strings.Contains(alerts[0].Message, "1800.00 below") {
+		t.Errorf("Expected the alert to name USD and its 1800.00 shortfall, got %q", alerts[0].Message)
+	}
+
+	t.Run("BothBelow", func(t *testing.T) {
+		balances[1].Balance = 1500
+		alerts := service.getLowCashAlerts(tenant.ID, balances)
+		if len(alerts) != 2 {
+			t.Fatalf("Expected alerts for EUR and USD, got %d: %+v", len(alerts), alerts)
+		}
+		if !strings.HasPrefix(alerts[0].Message, "EUR") || !strings.HasPrefix(alerts[1].Message, "USD") {
+			t.Errorf("Expected EUR then USD alerts, got %q and %q", alerts[0].Message, alerts[1].Message)
+		}
+	})
+}
+
+// TestDebtAging_CustomBuckets verifies remittances land in a tenant's configured buckets with their flags
+func TestDebtAging_CustomBuckets(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.OutgoingRemittance{}, &models.DebtAgingBucket{})
+
+	tenant := newTestTenant(t, db, "Aging Exchange")
+
+	now := time.Now()
+	for i, age := range []int{5, 20, 45, 75, 120} {
+		db.Create(&models.OutgoingRemittance{
+			TenantID:       tenant.ID,
+			RemittanceCode: "OUT-AGE-" + string(rune('A'+i)),
+			SenderName:     "Sender",
+			SenderPhone:    "+14165550400",
+			RecipientName:  "Recipient",
+			AmountIRR:      models.NewDecimal(80000000),
+			BuyRateCAD:     models.NewDecimal(80000),
+			RemainingIRR:   models.NewDecimal(80000000),
+			Status:         models.RemittanceStatusPending,
+			CreatedAt:      now.AddDate(0, 0, -age),
+		})
+	}
+
+	service := NewDashboardService(db)
+
+	t.Run("DefaultsWhenUnconfigured", func(t *testing.T) {
+		aging := service.getDebtAging(tenant.ID, nil)
+		if len(aging) != 4 || aging[0].Bucket != "0-7 days" || aging[3].Count != 3 {
+			t.Errorf("Expected the default buckets with 3 remittances over 30 days, got %+v", aging)
+		}
+	})
+
+	thirty, sixty := 30, 60
+	if _, err := service.SetDebtAgingBuckets(tenant.ID, []DebtAgingBucketInput{
+		{Label: "0-30 days", MaxDays: &thirty},
+		{Label: "31-60 days", MaxDays: &sixty, IsWarning: true},
+		{Label: "60+ days", IsCritical: true},
+	}); err != nil {
+		t.Fatalf("Failed to configure buckets: %v", err)
+	}
+
+	aging := service.getDebtAging(tenant.ID, nil)
+	if len(aging) != 3 {
+		t.Fatalf("Expected 3 buckets, got %d", len(aging))
+	}
+	expected := []struct {
+		label      string
+		count      int
+		isWarning  bool
+		isCritical bool
+	}{
+		{"0-30 days", 2, false, false},
+		{"31-60 days", 1, true, false},
+		{"60+ days", 2, false, true},
+	}
+	for i, want := range expected {
+		got := aging[i]
+		if got.Bucket != want.label || got.Count != want.count || got.IsWarning != want.isWarning || got.IsCritical != want.isCritical {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	t.Run("RejectsUnorderedBuckets", func(t *testing.T) {
+		if _, err := service.SetDebtAgingBuckets(tenant.ID, []DebtAgingBucketInput{
+			{Label: "0-60 days", MaxDays: &sixty},
+			{Label: "0-30 days", MaxDays: &thirty},
+			{Label: "Older"},
+		}); err == nil {
+			t.Error("Expected buckets with decreasing ages to be rejected")
+		}
+		if _, err := service.SetDebtAgingBuckets(tenant.ID, []DebtAgingBucketInput{
+			{Label: "0-30 days", MaxDays: &thirty},
+		}); err == nil {
+			t.Error("Expected a bounded last bucket to be rejected")
+		}
+	})
+}
+
+// TestCashFlowForecast_PlacesRemittancesAndInstallments verifies expected cash lands on the right forecast days
+func TestCashFlowForecast_PlacesRemittancesAndInstallments(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TenantSettings{}, &models.ExchangeRate{}, &models.OutgoingRemittance{}, &models.IncomingRemittance{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	db.Create(&models.IncomingRemittance{
+		TenantID:       tenant.ID,
+		RemittanceCode: "IN-FORECAST-1",
+		SenderName:     "Tehran Sender",
+		SenderPhone:    "+989121110000",
+		RecipientName:  "Toronto Recipient",
+		AmountIRR:      models.NewDecimal(81000000),
+		SellRateCAD:    models.NewDecimal(81000),
+		EquivalentCAD:  models.NewDecimal(1000),
+		RemainingIRR:   models.NewDecimal(81000000),
+		Status:         models.RemittanceStatusPending,
+		CreatedBy:      user.ID,
+	})
+	db.Create(&models.OutgoingRemittance{
+		TenantID:       tenant.ID,
+		RemittanceCode: "OUT-FORECAST-1",
+		SenderName:     "Toronto Sender",
+		SenderPhone:    "+14165550500",
+		RecipientName:  "Tehran Recipient",
+		AmountIRR:      models.NewDecimal(40000000),
+		BuyRateCAD:     models.NewDecimal(80000),
+		EquivalentCAD:  models.NewDecimal(500),
+		RemainingIRR:   models.NewDecimal(40000000),
+		Status:         models.RemittanceStatusPending,
+		CreatedBy:      user.ID,
+	})
+	// Fully paid out remittances are not expected to move cash again
+	db.Create(&models.IncomingRemittance{
+		TenantID:       tenant.ID,
+		RemittanceCode: "IN-FORECAST-2",
+		SenderName:     "Tehran Sender",
+		SenderPhone:    "+989121110000",
+		RecipientName:  "Toronto Recipient",
+		AmountIRR:      models.NewDecimal(81000000),
+		SellRateCAD:    models.NewDecimal(81000),
+		EquivalentCAD:  models.NewDecimal(1000),
+		RemainingIRR:   models.NewDecimal(81000000),
+		Status:         models.RemittanceStatusPaid,
+		CreatedBy:      user.ID,
+	})
+
+	now := time.Now()
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	if _, err := paymentService.CreatePaymentPlan(tenant.ID, "txn-batch-001", []PaymentInstallmentInput{
+		{DueDate: now.AddDate(0, 0, 5), ExpectedAmount: models.NewDecimal(600)},
+		{DueDate: now.AddDate(0, 0, 40), ExpectedAmount: models.NewDecimal(400)}, // beyond the horizon
+	}, "", user.ID); err != nil {
+		t.Fatalf("Failed to create payment plan: %v", err)
+	}
+
+	forecast, err := NewDashboardService(db).GetCashFlowForecast(tenant.ID, nil, 30)
+	if err != nil {
+		t.Fatalf("Forecast failed: %v", err)
+	}
+	if len(forecast.Series) != 30 || forecast.BaseCurrency != "CAD" {
+		t.Fatalf("Expected 30 CAD forecast days, got %d in %s", len(forecast.Series), forecast.BaseCurrency)
+	}
+
+	today := forecast.Series[0]
+	if today.Date != now.Format("2006-01-02") || today.ExpectedIn != 1000 || today.ExpectedOut != 500 || today.NetPosition != 500 {
+		t.Errorf("Expected today to carry 1000 in and 500 out, got %+v", today)
+	}
+
+	dueDay := forecast.Series[5]
+	if dueDay.Date != now.AddDate(0, 0, 5).Format("2006-01-02") || dueDay.ExpectedIn != 600 || dueDay.NetPosition != 1100 {
+		t.Errorf("Expected the installment on day 5, got %+v", dueDay)
+	}
+
+	last := forecast.Series[len(forecast.Series)-1]
+	if last.NetPosition != 1100 {
+		t.Errorf("Expected the out-of-horizon installment to be left out, got net %v", last.NetPosition)
+	}
+}
+
+// TestDashboardCache_HitExpiryAndInvalidation verifies the dashboard is served from cache within the TTL,
+// recomputed after it, and dropped when a payment is recorded
+func TestDashboardCache_HitExpiryAndInvalidation(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TenantSettings{}, &models.ExchangeRate{}))
+	// Keep the parallel dashboard queries on the one in-memory database
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.DashboardCacheSeconds = 30
+	db.Create(&settings)
+
+	now := time.Now()
+	cache := NewDashboardCache()
+	cache.now = func() time.Time { return now }
+	bus := NewInMemoryEventBus()
+	bus.Subscribe(cache.HandleEvent)
+
+	service := NewDashboardService(db)
+	service.cache = cache
+
+	load := func(forceRefresh bool) *DashboardData {
+		t.Helper()
+		data, err := service.GetCachedDashboardData(tenant.ID, nil, forceRefresh)
+		if err != nil {
+			t.Fatalf("Failed to load dashboard: %v", err)
+		}
+		return data
+	}
+
+	first := load(false)
+
+	t.Run("HitWithinTTL", func(t *testing.T) {
+		now = now.Add(20 * time.Second)
+		if load(false) != first {
+			t.Error("Expected the cached dashboard within the TTL")
+		}
+	})
+
+	t.Run("ForceRefreshBypassesCache", func(t *testing.T) {
+		refreshed := load(true)
+		if refreshed == first {
+			t.Fatal("Expected forceRefresh to recompute the dashboard")
+		}
+		first = refreshed
+	})
+
+	t.Run("ExpiresAfterTTL", func(t *testing.T) {
+		now = now.Add(31 * time.Second)
+		expired := load(false)
+		if expired == first {
+			t.Fatal("Expected the dashboard to be recomputed after the TTL")
+		}
+		first = expired
+	})
+
+	t.Run("InvalidatedByPayment", func(t *testing.T) {
+		if load(false) != first {
+			t.Fatal("Expected the recomputed dashboard to be cached")
+		}
+
+		paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+		paymentService.Events = bus
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(100),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodCash,
+			PaidBy:        user.ID,
+		}
+		if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+			t.Fatalf("Failed to create payment: %v", err)
+		}
+
+		if load(false) == first {
+			t.Error("Expected a new payment to invalidate the cached dashboard")
+		}
+	})
+
+	t.Run("OtherTenantsUnaffected", func(t *testing.T) {
+		cached := load(false)
+		bus.Publish(DomainEvent{Name: EventPaymentCreated, TenantID: tenant.ID + 1})
+		if load(false) != cached {
+			t.Error("Expected another tenant's payment to leave the cache alone")
+		}
+	})
+}
+
+// TestRateTrends_FromScheduledSnapshots verifies snapshots on three days give a three-point trend without any settlements
+func TestRateTrends_FromScheduledSnapshots(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.ExchangeRate{}, &models.ExchangeRateSnapshot{})
+
+	tenant := &models.Tenant{Name: "Snapshot Exchange", Status: models.TenantStatusActive}
+	db.Create(tenant)
+
+	snapshots := NewRateSnapshotService(db)
+	today := startOfDay(time.Now()).Add(12 * time.Hour)
+	for i, rate := range []float64{0.72, 0.73, 0.75} {
+		capturedAt := today.AddDate(0, 0, i-2)
+		db.Create(&models.ExchangeRate{
+			TenantID:       tenant.ID,
+			BaseCurrency:   "CAD",
+			TargetCurrency: "USD",
+			Rate:           models.NewDecimal(rate),
+			Source:         models.RateSourceManual,
+			CreatedAt:      capturedAt,
+		})
+		captured, err := snapshots.CaptureDue(capturedAt)
+		if err != nil {
+			t.Fatalf("Snapshot on day %d failed: %v", i+1, err)
+		}
+		if len(captured) != 1 {
+			t.Fatalf("Expected a snapshot on day %d, got %v", i+1, captured)
+		}
+
+		// A second run within the interval takes no snapshot
+		if captured, _ := snapshots.CaptureDue(capturedAt.Add(10 * time.Minute)); len(captured) != 0 {
+			t.Errorf("Expected no snapshot within the interval on day %d, got %v", i+1, captured)
+		}
+	}
+
+	trends := NewDashboardService(db).getRateTrends(tenant.ID, 7)
+	if len(trends) != 3 {
+		t.Fatalf("Expected a 3-point trend, got %d: %+v", len(trends), trends)
+	}
+	// Newest first
+	if trends[0].BuyRate != 0.75 || trends[2].BuyRate != 0.72 {
+		t.Errorf("Expected rates 0.75 ... 0.72 newest first, got %+v", trends)
+	}
+	for _, trend := range trends {
+		if trend.BaseCurrency != "CAD" || trend.Currency != "USD" || trend.Spread != trend.BuyRate-trend.SellRate {
+			t.Errorf("Unexpected trend point %+v", trend)
+		}
+	}
+}
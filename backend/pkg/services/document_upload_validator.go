@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrDocumentTypeNotAllowed is returned when an uploaded file is not a JPEG, PNG, GIF or PDF
+	ErrDocumentTypeNotAllowed = errors.New("invalid file type; allowed: JPEG, PNG, GIF, PDF")
+	// ErrDocumentTypeMismatch is returned when a file's content does not match its declared Content-Type
+	ErrDocumentTypeMismatch = errors.New("file content does not match its declared type")
+	// ErrDocumentTooLarge is returned when a file exceeds the size limit for its document type
+	ErrDocumentTooLarge = errors.New("file exceeds the size limit for this document type")
+	// ErrDocumentRejectedByScan is returned when the malware scanner rejects a file
+	ErrDocumentRejectedByScan = errors.New("file was rejected by the malware scan")
+)
+
+// DocumentScanner checks an uploaded file for malware before it is stored; a non-nil error rejects the file
+type DocumentScanner interface {
+	Scan(filename string, content io.Reader) error
+}
+
+// documentFileExtensions maps each accepted content type to the extension files are stored with
+var documentFileExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+// DocumentUploadValidator checks compliance document uploads by their actual content rather than the
+// client-supplied Content-Type, enforces per-document-type size limits and runs the optional scanner
+type DocumentUploadValidator struct {
+	DefaultMaxBytes int64
+	MaxBytes        map[string]int64 // Per document type, e.g. "SELFIE"
+	Scanner         DocumentScanner  // nil skips scanning
+}
+
+// NewDocumentUploadValidator creates a validator configured from COMPLIANCE_UPLOAD_MAX_MB (default 10) and
+// COMPLIANCE_UPLOAD_LIMITS_MB, a comma-separated list of per-type limits such as "SELFIE=5,PASSPORT=5"
+func NewDocumentUploadValidator() *DocumentUploadValidator {
+	v := &DocumentUploadValidator{
+		DefaultMaxBytes: 10 << 20,
+		MaxBytes: map[string]int64{
+			"ID_FRONT": 5 << 20,
+			"ID_BACK":  5 << 20,
+			"SELFIE":   5 << 20,
+		},
+	}
+	if mb, err := strconv.Atoi(getEnv("COMPLIANCE_UPLOAD_MAX_MB", "")); err == nil && mb > 0 {
+		v.DefaultMaxBytes = int64(mb) << 20
+	}
+	for _, limit := range strings.Split(getEnv("COMPLIANCE_UPLOAD_LIMITS_MB", ""), ",") {
+		docType, value, found := strings.Cut(strings.TrimSpace(limit), "=")
+		if !found {
+			continue
+		}
+		mb, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || mb <= 0 {
+			log.Printf("⚠️  Ignoring invalid compliance upload limit %q", limit)
+			continue
+		}
+		v.MaxBytes[strings.ToUpper(strings.TrimSpace(docType))] = int64(mb) << 20
+	}
+	return v
+}
+
+// MaxBytesFor returns the size limit for a document type
+func (v *DocumentUploadValidator) MaxBytesFor(docType string) int64 {
+	if limit, ok := v.MaxBytes[strings.ToUpper(docType)]; ok && limit > 0 {
+		return limit
+	}
+	return v.DefaultMaxBytes
+}
+
+// LargestMaxBytes returns the largest limit of any document type, to bound the request body
+func (v *DocumentUploadValidator) LargestMaxBytes() int64 {
+	largest := v.DefaultMaxBytes
+	for _, limit := range v.MaxBytes {
+		if limit > largest {
+			largest = limit
+		}
+	}
+	return largest
+}
+
+// normalizeContentType drops parameters and maps common aliases to the sniffed name
+func normalizeContentType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if contentType == "image/jpg" || contentType == "image/pjpeg" {
+		return "image/jpeg"
+	}
+	return contentType
+}
+
+// Save validates the upload and stores it at basePath plus the extension of its detected type. The file
+// is written next to its destination first and only moved into place once every check passes, so nothing
+// is left behind when it is rejected. It returns the stored path, the detected content type and the size.
+func (v *DocumentUploadValidator) Save(docType, declaredType string, src io.Reader, basePath string) (string, string, int64, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", "", 0, fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+
+	sniffed := normalizeContentType(http.DetectContentType(head))
+	declared := normalizeContentType(declaredType)
+	if declared != "" {
+		if _, ok := documentFileExtensions[declared]; !ok {
+			return "", "", 0, ErrDocumentTypeNotAllowed
+		}
+		if declared != sniffed {
+			return "", "", 0, fmt.Errorf("%w: declared %s but the content is %s", ErrDocumentTypeMismatch, declared, sniffed)
+		}
+	}
+	extension, ok := documentFileExtensions[sniffed]
+	if !ok {
+		return "", "", 0, ErrDocumentTypeNotAllowed
+	}
+
+	path := basePath + extension
+	partial := path + ".part"
+	dst, err := os.OpenFile(partial, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	reject := func(err error) (string, string, int64, error) {
+		dst.Close()
+		os.Remove(partial)
+		return "", "", 0, err
+	}
+
+	limit := v.MaxBytesFor(docType)
+	size, err := io.Copy(dst, io.LimitReader(io.MultiReader(bytes.NewReader(head), src), limit+1))
+	if err != nil {
+		return reject(fmt.Errorf("failed to save file: %w", err))
+	}
+	if size > limit {
+		return reject(fmt.Errorf("%w (%d MB)", ErrDocumentTooLarge, limit>>20))
+	}
+	if err := dst.Close(); err != nil {
+		return reject(fmt.Errorf("failed to save file: %w", err))
+	}
+
+	if v.Scanner != nil {
+		scanned, err := os.Open(partial)
+		if err != nil {
+			return reject(fmt.Errorf("failed to scan file: %w", err))
+		}
+		scanErr := v.Scanner.Scan(path, scanned)
+		scanned.Close()
+		if scanErr != nil {
+			return reject(fmt.Errorf("%w: %v", ErrDocumentRejectedByScan, scanErr))
+		}
+	}
+
+	if err := os.Rename(partial, path); err != nil {
+		return reject(fmt.Errorf("failed to save file: %w", err))
+	}
+	return path, sniffed, size, nil
+}
@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"api/pkg/services"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rejectingScanner fails every file, as an antivirus finding malware would
+type rejectingScanner struct{ scanned int }
+
+func (s *rejectingScanner) Scan(filename string, content io.Reader) error {
+	s.scanned++
+	return errors.New("Eicar-Test-Signature FOUND")
+}
+
+// assertNoFiles fails if anything, including a partial upload, was left in dir
+func assertNoFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected the rejected upload to be cleaned up, found %d files", len(entries))
+	}
+}
+
+// TestDocumentUploadValidator verifies uploads are checked by their content, size and scan result
+func TestDocumentUploadValidator(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	executable := append([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00"), bytes.Repeat([]byte{0}, 256)...)
+
+	t.Run("ExecutableRenamedToPDF", func(t *testing.T) {
+		dir := t.TempDir()
+		_, _, _, err := services.NewDocumentUploadValidator().Save("PASSPORT", "application/pdf",
+			bytes.NewReader(executable), filepath.Join(dir, "invoice"))
+		if !errors.Is(err, services.ErrDocumentTypeMismatch) {
+			t.Fatalf("Expected a type mismatch, got %v", err)
+		}
+		assertNoFiles(t, dir)
+	})
+
+	t.Run("ExecutableWithoutDeclaredType", func(t *testing.T) {
+		dir := t.TempDir()
+		_, _, _, err := services.NewDocumentUploadValidator().Save("PASSPORT", "", bytes.NewReader(executable), filepath.Join(dir, "invoice"))
+		if !errors.Is(err, services.ErrDocumentTypeNotAllowed) {
+			t.Fatalf("Expected the type to be refused, got %v", err)
+		}
+		assertNoFiles(t, dir)
+	})
+
+	t.Run("ValidPDF", func(t *testing.T) {
+		dir := t.TempDir()
+		path, contentType, size, err := services.NewDocumentUploadValidator().Save("PASSPORT", "application/pdf",
+			bytes.NewReader(pdf), filepath.Join(dir, "passport"))
+		if err != nil {
+			t.Fatalf("Expected the PDF to be accepted: %v", err)
+		}
+		if contentType != "application/pdf" || size != int64(len(pdf)) || !strings.HasSuffix(path, ".pdf") {
+			t.Errorf("Unexpected result %s (%s, %d bytes)", path, contentType, size)
+		}
+		stored, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(stored, pdf) {
+			t.Errorf("Expected the PDF to be stored unchanged: %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("Expected only the stored file, found %d files", len(entries))
+		}
+	})
+
+	t.Run("PerTypeSizeLimit", func(t *testing.T) {
+		dir := t.TempDir()
+		validator := services.NewDocumentUploadValidator()
+		validator.MaxBytes["SELFIE"] = 64
+		large := append(append([]byte{}, pdf...), bytes.Repeat([]byte(" "), 64)...)
+		if _, _, _, err := validator.Save("selfie", "application/pdf", bytes.NewReader(large), filepath.Join(dir, "selfie")); !errors.Is(err, services.ErrDocumentTooLarge) {
+			t.Fatalf("Expected the selfie limit to apply, got %v", err)
+		}
+		assertNoFiles(t, dir)
+		if _, _, _, err := validator.Save("BANK_STATEMENT", "application/pdf", bytes.NewReader(large), filepath.Join(dir, "statement")); err != nil {
+			t.Errorf("Expected other types to keep the default limit: %v", err)
+		}
+	})
+
+	t.Run("ScannerRejection", func(t *testing.T) {
+		dir := t.TempDir()
+		scanner := &rejectingScanner{}
+		validator := services.NewDocumentUploadValidator()
+		validator.Scanner = scanner
+		if _, _, _, err := validator.Save("PASSPORT", "application/pdf", bytes.NewReader(pdf), filepath.Join(dir, "passport")); !errors.Is(err, services.ErrDocumentRejectedByScan) {
+			t.Fatalf("Expected the scanner to reject the file, got %v", err)
+		}
+		if scanner.scanned != 1 {
+			t.Errorf("Expected one scan, got %d", scanner.scanned)
+		}
+		assertNoFiles(t, dir)
+	})
+}
@@ -1,11 +1,16 @@
 package services
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"math/big"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
 	"strings"
 	"time"
@@ -99,7 +104,7 @@ func (es *EmailService) SendVerificationEmail(toEmail, code string) error {
 	// Send via configured provider
 	if es.Provider == "resend" {
 		log.Printf("📧 Attempting to send via Resend to %s...", toEmail)
-		err := es.sendViaResend(toEmail, subject, body)
+		err := es.sendViaResend(toEmail, subject, body, nil)
 		if err != nil {
 			log.Printf("❌ RESEND ERROR: %v", err)
 			return err
@@ -107,7 +112,7 @@ func (es *EmailService) SendVerificationEmail(toEmail, code string) error {
 		return nil
 	}
 
-	return es.sendViasmtp(toEmail, subject, body)
+	return es.sendViasmtp(toEmail, subject, body, nil)
 }
 
 // getVerificationEmailHTML returns the HTML template for verification email
@@ -160,8 +165,36 @@ func (es *EmailService) getVerificationEmailHTML(code string) string {
 	`, code, time.Now().Year())
 }
 
+// EmailAttachment is a file sent along with an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// SendEmail sends an arbitrary HTML email through the configured provider
+func (es *EmailService) SendEmail(toEmail, subject, body string) error {
+	return es.SendEmailWithAttachments(toEmail, subject, body, nil)
+}
+
+// SendEmailWithAttachments sends an HTML email with files attached through the configured provider
+func (es *EmailService) SendEmailWithAttachments(toEmail, subject, body string, attachments []EmailAttachment) error {
+	if es.Provider == "dev" {
+		if !es.AllowDevEmail() {
+			return fmt.Errorf("email provider not configured; set RESEND_API_KEY or SMTP credentials")
+		}
+		log.Printf("📧 [DEV MODE] Email to %s: %s (%d attachments)", toEmail, subject, len(attachments))
+		return nil
+	}
+
+	if es.Provider == "resend" {
+		return es.sendViaResend(toEmail, subject, body, attachments)
+	}
+	return es.sendViasmtp(toEmail, subject, body, attachments)
+}
+
 // sendViaResend sends email using Resend SDK
-func (es *EmailService) sendViaResend(to, subject, body string) error {
+func (es *EmailService) sendViaResend(to, subject, body string, attachments []EmailAttachment) error {
 	client := resend.NewClient(es.ResendAPIKey)
 
 	// Log the attempt
@@ -173,6 +206,13 @@ func (es *EmailService) sendViaResend(to, subject, body string) error {
 		Subject: subject,
 		Html:    body,
 	}
+	for _, attachment := range attachments {
+		params.Attachments = append(params.Attachments, &resend.Attachment{
+			Content:     attachment.Content,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+		})
+	}
 
 	sent, err := client.Emails.Send(params)
 	if err != nil {
@@ -189,8 +229,15 @@ func (es *EmailService) sendViaResend(to, subject, body string) error {
 	return nil
 }
 
+// headerSubject makes a subject safe for a raw Subject header: line breaks become spaces so they cannot
+// inject headers, and non-ASCII text is Q-encoded
+func headerSubject(subject string) string {
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+	return mime.QEncoding.Encode("utf-8", subject)
+}
+
 // sendViasmtp sends an email using SMTP
-func (es *EmailService) sendViasmtp(to, subject, body string) error {
+func (es *EmailService) sendViasmtp(to, subject, body string, attachments []EmailAttachment) error {
 	auth := smtp.PlainAuth("", es.SMTPUsername, es.SMTPPassword, es.SMTPHost)
 
 	msg := []byte(fmt.Sprintf("From: %s\r\n"+
@@ -199,7 +246,13 @@ func (es *EmailService) sendViasmtp(to, subject, body string) error {
 		"MIME-version: 1.0;\r\n"+
 		"Content-Type: text/html; charset=\"UTF-8\";\r\n"+
 		"\r\n"+
-		"%s\r\n", es.FromEmail, to, subject, body))
+		"%s\r\n", es.FromEmail, to, headerSubject(subject), body))
+	if len(attachments) > 0 {
+		var err error
+		if msg, err = es.buildMultipartMessage(to, subject, body, attachments); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+	}
 
 	err := smtp.SendMail(
 		es.SMTPHost+":"+es.SMTPPort,
@@ -218,6 +271,50 @@ func (es *EmailService) sendViasmtp(to, subject, body string) error {
 	return nil
 }
 
+// buildMultipartMessage encodes an HTML body and base64 attachments as a multipart/mixed message
+func (es *EmailService) buildMultipartMessage(to, subject, body string, attachments []EmailAttachment) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n", es.FromEmail, to, headerSubject(subject))
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {`text/html; charset="UTF-8"`}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		// Keep lines within the 76 characters mail servers expect
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // SendPasswordResetCode sends a password reset code to the user's email
 func (es *EmailService) SendPasswordResetCode(toEmail, code string) error {
 	subject := "Reset your password - Velopay"
@@ -235,10 +332,10 @@ func (es *EmailService) SendPasswordResetCode(toEmail, code string) error {
 
 	// Send via configured provider
 	if es.Provider == "resend" {
-		return es.sendViaResend(toEmail, subject, body)
+		return es.sendViaResend(toEmail, subject, body, nil)
 	}
 
-	return es.sendViasmtp(toEmail, subject, body)
+	return es.sendViasmtp(toEmail, subject, body, nil)
 }
 
 // getPasswordResetEmailHTML returns the HTML template for password reset email
@@ -348,10 +445,10 @@ func (es *EmailService) SendPasswordResetEmail(toEmail, resetToken string) error
 
 	// Send via configured provider
 	if es.Provider == "resend" {
-		return es.sendViaResend(toEmail, subject, body)
+		return es.sendViaResend(toEmail, subject, body, nil)
 	}
 
-	return es.sendViasmtp(toEmail, subject, body)
+	return es.sendViasmtp(toEmail, subject, body, nil)
 }
 
 // getEnv gets environment variable with a default fallback
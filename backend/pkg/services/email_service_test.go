@@ -0,0 +1,27 @@
+package services
+
+import (
+	"mime"
+	"strings"
+	"testing"
+)
+
+// TestHeaderSubject verifies subjects cannot break out of the Subject header and non-ASCII text is encoded
+func TestHeaderSubject(t *testing.T) {
+	injected := headerSubject("Scheduled export: Daily\r\nBcc: attacker@example.com (2026-01-02)")
+	if strings.ContainsAny(injected, "\r\n") {
+		t.Errorf("Expected line breaks to be removed, got %q", injected)
+	}
+
+	encoded := headerSubject("Scheduled export: گزارش روزانه")
+	if !strings.HasPrefix(encoded, "=?utf-8?q?") {
+		t.Errorf("Expected a Q-encoded subject, got %q", encoded)
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(encoded); err != nil || decoded != "Scheduled export: گزارش روزانه" {
+		t.Errorf("Expected the subject to decode back, got %q (%v)", decoded, err)
+	}
+
+	if plain := headerSubject("Scheduled export: Daily CAD (2026-01-02)"); plain != "Scheduled export: Daily CAD (2026-01-02)" {
+		t.Errorf("Expected an ASCII subject to be unchanged, got %q", plain)
+	}
+}
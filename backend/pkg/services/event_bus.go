@@ -0,0 +1,104 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Payment domain event names
+const (
+	EventPaymentCreated   = "payment.created"
+	EventPaymentUpdated   = "payment.updated"
+	EventPaymentDeleted   = "payment.deleted"
+	EventPaymentCancelled = "payment.cancelled"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+// Transaction and settlement domain event names
+const (
+	EventTransactionCreated   = "transaction.created"
+	EventTransactionUpdated   = "transaction.updated"
+	EventTransactionCancelled = "transaction.cancelled"
+	EventTransactionDeleted   = "transaction.deleted"
+	EventTransactionRestored  = "transaction.restored"
+	EventTransactionVoided    = "transaction.voided"
+	EventSettlementCreated    = "settlement.created"
+	EventTransactionLarge     = "transaction.large" // Created at or above the tenant's LargeTransactionWebhookAmount
+)
+
+// Support ticket domain event names
+const (
+	EventTicketSLABreached = "ticket.sla_breached"
+)
+
+// DomainEvent is something that happened in the domain, published after it has been committed
+type DomainEvent struct {
+	Name       string                 `json:"name"` // e.g. "payment.created"
+	TenantID   uint                   `json:"tenantId"`
+	Data       map[string]interface{} `json:"data"`
+	OccurredAt time.Time              `json:"occurredAt"`
+}
+
+// EventHandler reacts to a published domain event
+type EventHandler func(event DomainEvent)
+
+// EventBus delivers domain events to subscribers
+type EventBus interface {
+	Publish(event DomainEvent)
+	Subscribe(handler EventHandler)
+}
+
+// InMemoryEventBus delivers events synchronously to in-process subscribers
+type InMemoryEventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// NewInMemoryEventBus creates an empty in-memory event bus
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{}
+}
+
+// Publish calls every subscriber with the event; a panicking subscriber does not affect the others
+func (b *InMemoryEventBus) Publish(event DomainEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("⚠️  Event handler for %s panicked: %v", event.Name, r)
+				}
+			}()
+			handler(event)
+		}()
+	}
+}
+
+// Subscribe registers a handler for all future events
+func (b *InMemoryEventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+var (
+	eventBusInstance EventBus
+	eventBusOnce     sync.Once
+)
+
+// GetEventBus returns the process-wide event bus
+func GetEventBus() EventBus {
+	eventBusOnce.Do(func() {
+		eventBusInstance = NewInMemoryEventBus()
+	})
+	return eventBusInstance
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"api/pkg/models"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,10 +14,18 @@ import (
 
 type ExchangeRateService struct {
 	DB *gorm.DB
+
+	// Provider supplies the latest market rates for FetchRatesFromAPI
+	Provider RateProvider
 }
 
 func NewExchangeRateService(db *gorm.DB) *ExchangeRateService {
-	return &ExchangeRateService{DB: db}
+	return &ExchangeRateService{DB: db, Provider: &OpenERAPIProvider{}}
+}
+
+// RateProvider fetches the latest market rates from one base currency to every currency it quotes
+type RateProvider interface {
+	FetchLatestRates(baseCurrency string) (map[string]float64, error)
 }
 
 // ExchangeRateAPIResponse represents the response from open.er-api.com
@@ -26,37 +35,49 @@ type ExchangeRateAPIResponse struct {
 	Rates    map[string]float64 `json:"rates"`
 }
 
-// FetchRatesFromAPI fetches latest rates from open.er-api.com (Supports IRR)
-// Invalidates cache for all fetched rates
-func (s *ExchangeRateService) FetchRatesFromAPI(tenantID uint, baseCurrency string) error {
-	// Using open.er-api.com which supports more currencies including IRR
+// OpenERAPIProvider fetches rates from open.er-api.com, which supports more currencies including IRR
+type OpenERAPIProvider struct{}
+
+// FetchLatestRates returns open.er-api.com's latest rates for the base currency
+func (p *OpenERAPIProvider) FetchLatestRates(baseCurrency string) (map[string]float64, error) {
 	url := fmt.Sprintf("https://open.er-api.com/v6/latest/%s", baseCurrency)
 
 	resp, err := http.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to fetch rates: %w", err)
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var apiResp ExchangeRateAPIResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if apiResp.Result != "success" {
-		return fmt.Errorf("api returned error status: %s", apiResp.Result)
+		return nil, fmt.Errorf("api returned error status: %s", apiResp.Result)
+	}
+
+	return apiResp.Rates, nil
+}
+
+// FetchRatesFromAPI fetches latest rates from the rate provider and records when the tenant was last refreshed
+// Invalidates cache for all fetched rates
+func (s *ExchangeRateService) FetchRatesFromAPI(tenantID uint, baseCurrency string) error {
+	rates, err := s.Provider.FetchLatestRates(baseCurrency)
+	if err != nil {
+		return err
 	}
 
 	// Get cache service for invalidation
 	cache := GetCacheService(s.DB)
 
 	// Store rates in database and invalidate cache
-	for targetCurrency, rate := range apiResp.Rates {
+	for targetCurrency, rate := range rates {
 		exchangeRate := models.ExchangeRate{
 			TenantID:       tenantID,
 			BaseCurrency:   baseCurrency,
@@ -74,18 +95,26 @@ func (s *ExchangeRateService) FetchRatesFromAPI(tenantID uint, baseCurrency stri
 		cache.InvalidateExchangeRate(tenantID, baseCurrency, targetCurrency)
 	}
 
+	// Tenants without a settings row keep the defaults, which never refresh automatically
+	if err := s.DB.Model(&models.TenantSettings{}).
+		Where("tenant_id = ?", tenantID).
+		Update("rates_last_refreshed_at", time.Now()).Error; err != nil {
+		fmt.Printf("Error recording rate refresh for tenant %d: %v\n", tenantID, err)
+	}
+
 	return nil
 }
 
-// UpdateRate manually sets a custom exchange rate
-// Also invalidates the cache for this rate
-func (s *ExchangeRateService) UpdateRate(tenantID uint, baseCurrency, targetCurrency string, rate float64) error {
+// UpdateRate manually sets a custom exchange rate. With expiresAt the rate is a temporary override that
+// takes precedence until it expires. Also invalidates the cache for this rate
+func (s *ExchangeRateService) UpdateRate(tenantID uint, baseCurrency, targetCurrency string, rate float64, expiresAt *time.Time) error {
 	exchangeRate := models.ExchangeRate{
 		TenantID:       tenantID,
 		BaseCurrency:   baseCurrency,
 		TargetCurrency: targetCurrency,
 		Rate:           models.NewDecimal(rate),
 		Source:         models.RateSourceManual,
+		ExpiresAt:      expiresAt,
 	}
 
 	if err := s.DB.Create(&exchangeRate).Error; err != nil {
@@ -126,22 +155,47 @@ func (s *ExchangeRateService) GetAllCurrentRates(tenantID uint) ([]models.Exchan
 		return nil, err
 	}
 
-	// Get latest rate for each pair
+	// Get the effective rate for each pair
+	now := time.Now()
 	for _, pair := range pairs {
-		var rate models.ExchangeRate
-		err := s.DB.Where("tenant_id = ? AND base_currency = ? AND target_currency = ?",
-			tenantID, pair.BaseCurrency, pair.TargetCurrency).
-			Order("created_at DESC").
-			First(&rate).Error
-
+		rate, err := findEffectiveRate(s.DB, tenantID, pair.BaseCurrency, pair.TargetCurrency, now, false)
 		if err == nil {
-			rates = append(rates, rate)
+			rates = append(rates, *rate)
 		}
 	}
 
 	return rates, nil
 }
 
+// findEffectiveRate returns the pair's rate in effect at the given time: the latest manual override still
+// within its expiry, otherwise the latest rate that is not an expired override. With recordedBy set, only
+// rates recorded by then are considered.
+func findEffectiveRate(db *gorm.DB, tenantID uint, baseCurrency, targetCurrency string, at time.Time, recordedBy bool) (*models.ExchangeRate, error) {
+	pair := func() *gorm.DB {
+		query := db.Where("tenant_id = ? AND base_currency = ? AND target_currency = ?", tenantID, baseCurrency, targetCurrency)
+		if recordedBy {
+			query = query.Where("created_at <= ?", at)
+		}
+		return query.Order("created_at DESC, id DESC")
+	}
+
+	var rate models.ExchangeRate
+	err := pair().Where("source = ? AND expires_at > ?", models.RateSourceManual, at).
+		First(&rate).Error
+	if err == nil {
+		return &rate, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := pair().Where("(expires_at IS NULL OR expires_at > ?)", at).
+		First(&rate).Error; err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
 // GetRateHistory retrieves historical rates for charting
 func (s *ExchangeRateService) GetRateHistory(tenantID uint, baseCurrency, targetCurrency string, days int) ([]models.ExchangeRate, error) {
 	var rates []models.ExchangeRate
@@ -155,3 +209,23 @@ func (s *ExchangeRateService) GetRateHistory(tenantID uint, baseCurrency, target
 
 	return rates, err
 }
+
+// GetHistoricalRate returns the rate for a currency pair in effect at the given time.
+// If only the inverse pair was recorded, its reciprocal is used.
+func (s *ExchangeRateService) GetHistoricalRate(tenantID uint, baseCurrency, targetCurrency string, at time.Time) (models.Decimal, error) {
+	if baseCurrency == targetCurrency {
+		return models.NewDecimal(1), nil
+	}
+
+	rate, err := findEffectiveRate(s.DB, tenantID, baseCurrency, targetCurrency, at, true)
+	if err == nil && rate.Rate.IsPositive() {
+		return rate.Rate, nil
+	}
+
+	rate, err = findEffectiveRate(s.DB, tenantID, targetCurrency, baseCurrency, at, true)
+	if err == nil && rate.Rate.IsPositive() {
+		return models.NewDecimal(1).Div(rate.Rate), nil
+	}
+
+	return models.Zero(), fmt.Errorf("no %s/%s rate available at %s", baseCurrency, targetCurrency, at.Format(time.RFC3339))
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// TestManualRateOverride_ExpiresBackToExternalRate verifies an active manual override takes precedence over
+// newer external rates and an expired one is ignored
+func TestManualRateOverride_ExpiresBackToExternalRate(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.ExchangeRate{})
+	ResetGlobalCacheService()
+	defer ResetGlobalCacheService()
+
+	tenant := newTestTenant(t, db, "Override Exchange")
+
+	provider := &stubRateProvider{rates: map[string]float64{"USD": 0.74}}
+	service := NewExchangeRateService(db)
+	service.Provider = provider
+
+	current := func() *models.ExchangeRate {
+		t.Helper()
+		rates, err := service.GetAllCurrentRates(tenant.ID)
+		if err != nil || len(rates) != 1 {
+			t.Fatalf("Expected one current rate, got %v (%v)", rates, err)
+		}
+		return &rates[0]
+	}
+
+	if err := service.FetchRatesFromAPI(tenant.ID, "CAD"); err != nil {
+		t.Fatalf("Failed to fetch rates: %v", err)
+	}
+
+	// Set for the rest of today, then the provider publishes a newer rate
+	expiresAt := time.Now().Add(time.Hour)
+	if err := service.UpdateRate(tenant.ID, "CAD", "USD", 0.76, &expiresAt); err != nil {
+		t.Fatalf("Failed to set override: %v", err)
+	}
+	provider.rates["USD"] = 0.75
+	if err := service.FetchRatesFromAPI(tenant.ID, "CAD"); err != nil {
+		t.Fatalf("Failed to fetch rates: %v", err)
+	}
+
+	if rate := current(); rate.Rate.Float64() != 0.76 || rate.Source != models.RateSourceManual {
+		t.Errorf("Expected the active MANUAL override 0.76, got %s %v", rate.Source, rate.Rate.Float64())
+	}
+	if rate, err := service.GetCurrentRate(tenant.ID, "CAD", "USD"); err != nil || rate.Rate.Float64() != 0.76 {
+		t.Errorf("Expected transactions to use the override 0.76, got %v (%v)", rate, err)
+	}
+
+	t.Run("ExpiredOverrideIgnored", func(t *testing.T) {
+		db.Model(&models.ExchangeRate{}).Where("source = ?", models.RateSourceManual).
+			Update("expires_at", time.Now().Add(-time.Minute))
+		GetCacheService(db).InvalidateExchangeRate(tenant.ID, "CAD", "USD")
+
+		if rate := current(); rate.Rate.Float64() != 0.75 || rate.Source != models.RateSourceAPI {
+			t.Errorf("Expected the latest API rate 0.75 after expiry, got %s %v", rate.Source, rate.Rate.Float64())
+		}
+		if rate, err := service.GetCurrentRate(tenant.ID, "CAD", "USD"); err != nil || rate.Rate.Float64() != 0.75 {
+			t.Errorf("Expected transactions to revert to 0.75, got %v (%v)", rate, err)
+		}
+	})
+}
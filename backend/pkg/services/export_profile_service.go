@@ -0,0 +1,387 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"gorm.io/gorm"
+)
+
+// ExportProfileService manages saved export profiles and runs them through the transaction export
+type ExportProfileService struct {
+	DB         *gorm.DB
+	statistics *StatisticsService
+}
+
+// NewExportProfileService creates a new ExportProfileService
+func NewExportProfileService(db *gorm.DB) *ExportProfileService {
+	return &ExportProfileService{DB: db, statistics: NewStatisticsService(db)}
+}
+
+// ExportProfileFilters narrows the rows an export profile produces
+type ExportProfileFilters struct {
+	BranchID  *uint  `json:"branchId,omitempty"`
+	StartDate string `json:"startDate,omitempty"` // YYYY-MM-DD, inclusive
+	EndDate   string `json:"endDate,omitempty"`   // YYYY-MM-DD, inclusive
+	Currency  string `json:"currency,omitempty"`  // Matches either the send or the receive currency
+}
+
+// ExportProfileRequest is the body for creating or updating an export profile
+type ExportProfileRequest struct {
+	Name    string               `json:"name"`
+	Entity  string               `json:"entity"`
+	Columns []string             `json:"columns"`
+	Filters ExportProfileFilters `json:"filters"`
+	Format  string               `json:"format"`
+}
+
+// exportAmount is a number exported with a fixed number of decimal places
+type exportAmount struct {
+	value  float64
+	places int
+}
+
+func (a exportAmount) String() string {
+	return strconv.FormatFloat(a.value, 'f', a.places, 64)
+}
+
+func (a exportAmount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// exportColumn is one column a transaction export profile can select
+type exportColumn struct {
+	header string
+	value  func(row TransactionExportRow) interface{}
+}
+
+// transactionExportColumns are the selectable transaction columns, keyed by their JSON field name
+var transactionExportColumns = map[string]exportColumn{
+	"id":                 {"ID", func(r TransactionExportRow) interface{} { return r.ID }},
+	"date":               {"Date", func(r TransactionExportRow) interface{} { return r.Date }},
+	"type":               {"Type", func(r TransactionExportRow) interface{} { return r.Type }},
+	"sendCurrency":       {"Send Currency", func(r TransactionExportRow) interface{} { return r.SendCurrency }},
+	"sendAmount":         {"Send Amount", func(r TransactionExportRow) interface{} { return exportAmount{r.SendAmount, 2} }},
+	"receiveCurrency":    {"Receive Currency", func(r TransactionExportRow) interface{} { return r.ReceiveCurrency }},
+	"receiveAmount":      {"Receive Amount", func(r TransactionExportRow) interface{} { return exportAmount{r.ReceiveAmount, 2} }},
+	"rateApplied":        {"Rate Applied", func(r TransactionExportRow) interface{} { return exportAmount{r.RateApplied, 4} }},
+	"feeCharged":         {"Fee Charged", func(r TransactionExportRow) interface{} { return exportAmount{r.FeeCharged, 2} }},
+	"beneficiaryName":    {"Beneficiary Name", func(r TransactionExportRow) interface{} { return r.BeneficiaryName }},
+	"beneficiaryPhone":   {"Beneficiary Phone", func(r TransactionExportRow) interface{} { return r.BeneficiaryPhone }},
+	"beneficiaryBank":    {"Beneficiary Bank", func(r TransactionExportRow) interface{} { return r.BeneficiaryBank }},
+	"beneficiaryAccount": {"Beneficiary Account", func(r TransactionExportRow) interface{} { return r.BeneficiaryAccount }},
+	"branchName":         {"Branch", func(r TransactionExportRow) interface{} { return r.BranchName }},
+	"status":             {"Status", func(r TransactionExportRow) interface{} { return r.Status }},
+	"notes":              {"Notes", func(r TransactionExportRow) interface{} { return r.Notes }},
+}
+
+// validate normalizes the request and checks the entity, columns, filters and format
+func (req *ExportProfileRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+
+	req.Entity = strings.ToLower(strings.TrimSpace(req.Entity))
+	if req.Entity == "" {
+		req.Entity = models.ExportEntityTransactions
+	}
+	if req.Entity != models.ExportEntityTransactions {
+		return fmt.Errorf("unsupported export entity %q", req.Entity)
+	}
+
+	if len(req.Columns) == 0 {
+		return errors.New("at least one column is required")
+	}
+	seen := make(map[string]bool, len(req.Columns))
+	for _, column := range req.Columns {
+		if _, ok := transactionExportColumns[column]; !ok {
+			return fmt.Errorf("unknown column %q", column)
+		}
+		if seen[column] {
+			return fmt.Errorf("column %q is listed more than once", column)
+		}
+		seen[column] = true
+	}
+
+	req.Format = strings.ToUpper(strings.TrimSpace(req.Format))
+	switch req.Format {
+	case "":
+		req.Format = models.ExportFormatCSV
+	case models.ExportFormatCSV, models.ExportFormatJSON, models.ExportFormatPDF:
+	default:
+		return fmt.Errorf("unsupported export format %q (use CSV, JSON or PDF)", req.Format)
+	}
+
+	req.Filters.Currency = strings.ToUpper(strings.TrimSpace(req.Filters.Currency))
+	start, end, err := req.Filters.dateRange()
+	if err != nil {
+		return err
+	}
+	if start != nil && end != nil && end.Before(*start) {
+		return errors.New("filter end date must not be before its start date")
+	}
+	return nil
+}
+
+// dateRange parses the filter's optional start and end dates
+func (f ExportProfileFilters) dateRange() (*time.Time, *time.Time, error) {
+	var start, end *time.Time
+	if f.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", f.StartDate)
+		if err != nil {
+			return nil, nil, errors.New("invalid filter start date, use YYYY-MM-DD")
+		}
+		start = &parsed
+	}
+	if f.EndDate != "" {
+		parsed, err := time.Parse("2006-01-02", f.EndDate)
+		if err != nil {
+			return nil, nil, errors.New("invalid filter end date, use YYYY-MM-DD")
+		}
+		end = &parsed
+	}
+	return start, end, nil
+}
+
+// apply copies the validated request onto the profile
+func (req *ExportProfileRequest) apply(profile *models.ExportProfile) error {
+	columns, err := json.Marshal(req.Columns)
+	if err != nil {
+		return err
+	}
+	filters, err := json.Marshal(req.Filters)
+	if err != nil {
+		return err
+	}
+	profile.Name = req.Name
+	profile.Entity = req.Entity
+	profile.Columns = string(columns)
+	profile.Filters = string(filters)
+	profile.Format = req.Format
+	return nil
+}
+
+// GetProfiles returns the tenant's export profiles
+func (s *ExportProfileService) GetProfiles(tenantID uint) ([]models.ExportProfile, error) {
+	var profiles []models.ExportProfile
+	err := s.DB.Where("tenant_id = ?", tenantID).Order("name ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+// GetProfile returns one of the tenant's export profiles
+func (s *ExportProfileService) GetProfile(tenantID, profileID uint) (*models.ExportProfile, error) {
+	var profile models.ExportProfile
+	if err := s.DB.Where("id = ? AND tenant_id = ?", profileID, tenantID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("export profile not found")
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// CreateProfile saves a new export profile
+func (s *ExportProfileService) CreateProfile(tenantID uint, req ExportProfileRequest, userID uint) (*models.ExportProfile, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	profile := &models.ExportProfile{TenantID: tenantID, CreatedBy: userID}
+	if err := req.apply(profile); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to save export profile: %w", err)
+	}
+	return profile, nil
+}
+
+// UpdateProfile replaces an export profile's configuration
+func (s *ExportProfileService) UpdateProfile(tenantID, profileID uint, req ExportProfileRequest) (*models.ExportProfile, error) {
+	profile, err := s.GetProfile(tenantID, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+	if err := req.apply(profile); err != nil {
+		return nil, err
+	}
+	if err := s.DB.Save(profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to update export profile: %w", err)
+	}
+	return profile, nil
+}
+
+// DeleteProfile removes an export profile
+func (s *ExportProfileService) DeleteProfile(tenantID, profileID uint) error {
+	result := s.DB.Where("id = ? AND tenant_id = ?", profileID, tenantID).Delete(&models.ExportProfile{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("export profile not found")
+	}
+	return nil
+}
+
+// ExportFileType returns the content type and file extension of an export format
+func ExportFileType(format string) (contentType, extension string) {
+	switch format {
+	case models.ExportFormatJSON:
+		return "application/json", "json"
+	case models.ExportFormatPDF:
+		return "application/pdf", "pdf"
+	}
+	return "text/csv", "csv"
+}
+
+// RunProfile writes the profile's export to w in its configured format and returns the number of rows written
+func (s *ExportProfileService) RunProfile(profile *models.ExportProfile, w io.Writer) (int, error) {
+	var columnKeys []string
+	if err := json.Unmarshal([]byte(profile.Columns), &columnKeys); err != nil {
+		return 0, fmt.Errorf("export profile has invalid columns: %w", err)
+	}
+	var filters ExportProfileFilters
+	if err := json.Unmarshal([]byte(profile.Filters), &filters); err != nil {
+		return 0, fmt.Errorf("export profile has invalid filters: %w", err)
+	}
+
+	columns := make([]exportColumn, 0, len(columnKeys))
+	for _, key := range columnKeys {
+		column, ok := transactionExportColumns[key]
+		if !ok {
+			return 0, fmt.Errorf("export profile has unknown column %q", key)
+		}
+		columns = append(columns, column)
+	}
+
+	start, end, err := filters.dateRange()
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.statistics.GetTransactionsForExport(profile.TenantID, filters.BranchID, start, end)
+	if err != nil {
+		return 0, err
+	}
+	if filters.Currency != "" {
+		matching := rows[:0]
+		for _, row := range rows {
+			if row.SendCurrency == filters.Currency || row.ReceiveCurrency == filters.Currency {
+				matching = append(matching, row)
+			}
+		}
+		rows = matching
+	}
+
+	switch profile.Format {
+	case models.ExportFormatJSON:
+		err = writeProfileJSON(w, columnKeys, columns, rows)
+	case models.ExportFormatPDF:
+		err = writeProfilePDF(w, profile.Name, columns, rows)
+	default:
+		err = writeProfileCSV(w, columns, rows)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// writeProfileCSV streams the rows as CSV with one column per selected field
+func writeProfileCSV(w io.Writer, columns []exportColumn, rows []TransactionExportRow) error {
+	writer := csv.NewWriter(w)
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.header
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = fmt.Sprint(column.value(row))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeProfileJSON streams the rows as a JSON array of objects keyed by column
+func writeProfileJSON(w io.Writer, keys []string, columns []exportColumn, rows []TransactionExportRow) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for n, row := range rows {
+		object := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			object[keys[i]] = column.value(row)
+		}
+		encoded, err := json.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// writeProfilePDF renders the rows as a landscape table with the selected columns sharing the page width
+func writeProfilePDF(w io.Writer, title string, columns []exportColumn, rows []TransactionExportRow) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(40, 10, title)
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(40, 10, "Generated: "+time.Now().Format("2006-01-02 15:04:05"))
+	pdf.Ln(12)
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := (pageWidth - left - right) / float64(len(columns))
+
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetFont("Arial", "B", 9)
+	for _, column := range columns {
+		pdf.CellFormat(width, 8, column.header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 8)
+	for _, row := range rows {
+		for _, column := range columns {
+			value := column.value(row)
+			align := "L"
+			if _, ok := value.(exportAmount); ok {
+				align = "R"
+			}
+			pdf.CellFormat(width, 8, fmt.Sprint(value), "1", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	return pdf.Output(w)
+}
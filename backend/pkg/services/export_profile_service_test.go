@@ -0,0 +1,109 @@
+package services
+
+import (
+	"api/pkg/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestRunExportProfile_UsesConfiguredColumnsAndFilters verifies a saved profile exports only its columns and matching rows
+func TestRunExportProfile_UsesConfiguredColumnsAndFilters(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{}, &models.ExportProfile{})
+
+	tenant := newTestTenant(t, db, "Export Exchange")
+	downtown := newTestBranch(t, db, tenant.ID, "Downtown", "DT")
+	airport := newTestBranch(t, db, tenant.ID, "Airport", "AP")
+	client := &models.Client{ID: "client-export", TenantID: tenant.ID, Name: "Export Client", PhoneNumber: "+14165550122"}
+	db.Create(client)
+
+	addTransaction := func(id string, branchID uint, sendCurrency string, sendAmount float64, receiveCurrency string) {
+		db.Create(&models.Transaction{
+			ID:              id,
+			TenantID:        tenant.ID,
+			BranchID:        &branchID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    sendCurrency,
+			SendAmount:      models.NewDecimal(sendAmount),
+			ReceiveCurrency: receiveCurrency,
+			ReceiveAmount:   models.NewDecimal(sendAmount * 0.7),
+			RateApplied:     models.NewDecimal(0.7),
+			CreatedAt:       time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	addTransaction("txn-downtown-cad", downtown.ID, "CAD", 100, "USD")
+	addTransaction("txn-downtown-eur", downtown.ID, "EUR", 250, "GBP")
+	addTransaction("txn-airport-cad", airport.ID, "CAD", 400, "USD")
+
+	service := NewExportProfileService(db)
+	profile, err := service.CreateProfile(tenant.ID, ExportProfileRequest{
+		Name:    "Downtown CAD",
+		Columns: []string{"id", "sendAmount", "branchName"},
+		Filters: ExportProfileFilters{BranchID: &downtown.ID, Currency: "cad", StartDate: "2026-04-01", EndDate: "2026-04-30"},
+		Format:  "csv",
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create export profile: %v", err)
+	}
+
+	var out bytes.Buffer
+	count, err := service.RunProfile(profile, &out)
+	if err != nil {
+		t.Fatalf("Failed to run export profile: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 exported row, got %d", count)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	expected := [][]string{
+		{"ID", "Send Amount", "Branch"},
+		{"txn-downtown-cad", "100.00", "Downtown"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d CSV records, got %d: %v", len(expected), len(records), records)
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if records[i][j] != expected[i][j] {
+				t.Errorf("Record %d: expected %v, got %v", i, expected[i], records[i])
+				break
+			}
+		}
+	}
+
+	// The same profile as JSON keys each row by exactly the configured columns
+	profile, err = service.UpdateProfile(tenant.ID, profile.ID, ExportProfileRequest{
+		Name:    "Downtown CAD",
+		Columns: []string{"id", "sendAmount", "branchName"},
+		Filters: ExportProfileFilters{BranchID: &downtown.ID, Currency: "CAD"},
+		Format:  "json",
+	})
+	if err != nil {
+		t.Fatalf("Failed to update export profile: %v", err)
+	}
+	out.Reset()
+	if _, err := service.RunProfile(profile, &out); err != nil {
+		t.Fatalf("Failed to run JSON export profile: %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if len(rows) != 1 || len(rows[0]) != 3 {
+		t.Fatalf("Expected one row with 3 fields, got %v", rows)
+	}
+	if rows[0]["id"] != "txn-downtown-cad" || rows[0]["sendAmount"] != 100.0 || rows[0]["branchName"] != "Downtown" {
+		t.Errorf("Unexpected JSON row: %v", rows[0])
+	}
+
+	if _, err := service.CreateProfile(tenant.ID, ExportProfileRequest{Name: "Bad", Columns: []string{"password"}}, 0); err == nil {
+		t.Error("Expected an unknown column to be rejected")
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HeldRemittanceService follows up on outgoing remittances left on compliance hold, reminding
+// staff once a hold is overdue and, if the tenant allows it, reversing the remittance
+type HeldRemittanceService struct {
+	db              *gorm.DB
+	settingsService *TenantSettingsService
+	ledgerService   *LedgerService
+	auditService    *AuditService
+}
+
+// NewHeldRemittanceService creates a new HeldRemittanceService
+func NewHeldRemittanceService(db *gorm.DB) *HeldRemittanceService {
+	return &HeldRemittanceService{
+		db:              db,
+		settingsService: NewTenantSettingsService(db),
+		ledgerService:   NewLedgerService(db),
+		auditService:    NewAuditService(db),
+	}
+}
+
+// HeldRemittanceResult is an action taken on an overdue held remittance
+type HeldRemittanceResult struct {
+	RemittanceID   uint   `json:"remittanceId"`
+	RemittanceCode string `json:"remittanceCode"`
+	Action         string `json:"action"`
+}
+
+// HeldRemittanceAction returns the action due for an outgoing remittance at the given time.
+// A threshold of 0 disables that action. Staff are notified once per hold; partially settled
+// remittances cannot be cancelled, so they are never reversed.
+func HeldRemittanceAction(outgoing *models.OutgoingRemittance, settings *models.TenantSettings, now time.Time) string {
+	if !outgoing.ComplianceHold || outgoing.ComplianceHeldAt == nil {
+		return models.HeldRemittanceActionNone
+	}
+	if outgoing.Status != models.RemittanceStatusPending && outgoing.Status != models.RemittanceStatusPartial {
+		return models.HeldRemittanceActionNone
+	}
+
+	heldFor := now.Sub(*outgoing.ComplianceHeldAt)
+	reached := func(hours int) bool {
+		return hours > 0 && heldFor >= time.Duration(hours)*time.Hour
+	}
+
+	switch {
+	case reached(settings.HoldAutoReverseAfterHours) && outgoing.SettledAmountIRR.IsZero():
+		return models.HeldRemittanceActionReverse
+	case reached(settings.HoldNotifyAfterHours) && outgoing.ComplianceNotifiedAt == nil:
+		return models.HeldRemittanceActionNotify
+	}
+	return models.HeldRemittanceActionNone
+}
+
+// CheckTenant applies the due action to each of the tenant's held remittances and returns what was done
+func (s *HeldRemittanceService) CheckTenant(tenantID uint, now time.Time) ([]HeldRemittanceResult, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.HoldNotifyAfterHours <= 0 && settings.HoldAutoReverseAfterHours <= 0 {
+		return nil, nil
+	}
+
+	var tenant models.Tenant
+	if err := s.db.First(&tenant, tenantID).Error; err != nil {
+		return nil, fmt.Errorf("tenant not found: %w", err)
+	}
+
+	var held []models.OutgoingRemittance
+	if err := s.db.Where("tenant_id = ? AND compliance_hold = ? AND status IN ?", tenantID, true,
+		[]string{models.RemittanceStatusPending, models.RemittanceStatusPartial}).
+		Find(&held).Error; err != nil {
+		return nil, err
+	}
+
+	var results []HeldRemittanceResult
+	for i := range held {
+		outgoing := &held[i]
+		action := HeldRemittanceAction(outgoing, settings, now)
+
+		// The action follows from the hold, so it is attributed to whoever placed it
+		actorID := tenant.OwnerID
+		if outgoing.ComplianceHeldBy != nil {
+			actorID = *outgoing.ComplianceHeldBy
+		}
+
+		switch action {
+		case models.HeldRemittanceActionNotify:
+			err = s.notifyOverdue(outgoing, actorID, now)
+		case models.HeldRemittanceActionReverse:
+			err = s.reverse(outgoing, settings, actorID, now)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️  Held remittance %s: %s failed: %v", outgoing.RemittanceCode, action, err)
+			continue
+		}
+		results = append(results, HeldRemittanceResult{
+			RemittanceID:   outgoing.ID,
+			RemittanceCode: outgoing.RemittanceCode,
+			Action:         action,
+		})
+	}
+
+	return results, nil
+}
+
+// notifyOverdue reminds the remittance's branch that the hold still needs a decision
+func (s *HeldRemittanceService) notifyOverdue(outgoing *models.OutgoingRemittance, actorID uint, now time.Time) error {
+	if err := s.db.Model(outgoing).Update("compliance_notified_at", now).Error; err != nil {
+		return err
+	}
+
+	heldHours := int(now.Sub(*outgoing.ComplianceHeldAt).Hours())
+	s.broadcast(outgoing, "hold_overdue", map[string]interface{}{
+		"remittanceId":   outgoing.ID,
+		"remittanceCode": outgoing.RemittanceCode,
+		"heldHours":      heldHours,
+		"reason":         outgoing.ComplianceHoldReason,
+	})
+
+	tenantID := outgoing.TenantID
+	s.auditService.LogAction(actorID, &tenantID, "HOLD_OVERDUE", "OUTGOING_REMITTANCE",
+		strconv.FormatUint(uint64(outgoing.ID), 10),
+		fmt.Sprintf("Remittance %s has been on compliance hold for %d hours", outgoing.RemittanceCode, heldHours),
+		nil, nil, nil)
+	return nil
+}
+
+// reverse cancels a held remittance and credits the sender the amount they paid so the refund shows on
+// their ledger. A sender not yet linked to a CRM client is linked to, or recorded as, one first.
+func (s *HeldRemittanceService) reverse(outgoing *models.OutgoingRemittance, settings *models.TenantSettings, actorID uint, now time.Time) error {
+	reason := fmt.Sprintf("Automatically reversed after %d hours on compliance hold", settings.HoldAutoReverseAfterHours)
+	previousStatus := outgoing.Status
+	refunded := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.OutgoingRemittance{}).
+			Where("id = ? AND compliance_hold = ? AND status = ?", outgoing.ID, true, previousStatus).
+			Updates(map[string]interface{}{
+				"status":              models.RemittanceStatusCancelled,
+				"cancelled_at":        now,
+				"cancellation_reason": reason,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("remittance changed before it could be reversed")
+		}
+
+		if !outgoing.ReceivedCAD.IsPositive() {
+			return nil
+		}
+		if outgoing.ClientID == nil {
+			client, err := findOrCreateSenderClient(tx, outgoing)
+			if err != nil {
+				return fmt.Errorf("failed to link sender client: %w", err)
+			}
+			if err := tx.Model(&models.OutgoingRemittance{}).Where("id = ?", outgoing.ID).Update("client_id", client.ID).Error; err != nil {
+				return err
+			}
+			outgoing.ClientID = &client.ID
+		}
+		if _, err := s.ledgerService.AddEntryWithTx(tx, models.LedgerEntry{
+			TenantID:    outgoing.TenantID,
+			ClientID:    *outgoing.ClientID,
+			BranchID:    outgoing.BranchID,
+			Type:        models.LedgerTypeReversal,
+			Currency:    outgoing.SettlementCurrency,
+			Amount:      outgoing.ReceivedCAD,
+			Description: fmt.Sprintf("Refund of held remittance %s: %s", outgoing.RemittanceCode, reason),
+			CreatedBy:   actorID,
+		}); err != nil {
+			return fmt.Errorf("failed to post refund entry: %w", err)
+		}
+		refunded = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	outgoing.Status = models.RemittanceStatusCancelled
+	outgoing.CancelledAt = &now
+	outgoing.CancellationReason = &reason
+
+	s.broadcast(outgoing, "hold_reversed", map[string]interface{}{
+		"remittanceId":   outgoing.ID,
+		"remittanceCode": outgoing.RemittanceCode,
+		"refunded":       refunded,
+	})
+
+	tenantID := outgoing.TenantID
+	s.auditService.LogAction(actorID, &tenantID, "AUTO_REVERSE", "OUTGOING_REMITTANCE",
+		strconv.FormatUint(uint64(outgoing.ID), 10),
+		fmt.Sprintf("Remittance %s: %s", outgoing.RemittanceCode, reason),
+		map[string]interface{}{"status": previousStatus, "complianceHold": true},
+		map[string]interface{}{
+			"status":         models.RemittanceStatusCancelled,
+			"refundCurrency": outgoing.SettlementCurrency,
+			"refundAmount":   outgoing.ReceivedCAD.String(),
+			"refunded":       refunded,
+		},
+		nil)
+	return nil
+}
+
+// broadcast pushes a remittance event to the remittance's branch, or the whole tenant if it has none
+func (s *HeldRemittanceService) broadcast(outgoing *models.OutgoingRemittance, action string, data map[string]interface{}) {
+	var branchIDs []uint
+	if outgoing.BranchID != nil {
+		branchIDs = []uint{*outgoing.BranchID}
+	}
+	GetHub().BroadcastToBranches(outgoing.TenantID, branchIDs, "remittance", action, data)
+}
+
+// CheckAll runs the held remittance check for every active tenant
+func (s *HeldRemittanceService) CheckAll(now time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, now); err != nil {
+			log.Printf("⚠️  Held remittance check failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start runs the held remittance check on the given interval in the background
+func (s *HeldRemittanceService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Held remittance scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now()); err != nil {
+				log.Printf("⚠️  Held remittance check failed: %v", err)
+			}
+		}
+	}()
+}
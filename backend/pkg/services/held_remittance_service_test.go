@@ -0,0 +1,221 @@
+package services
+
+import (
+	"api/pkg/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestHeldRemittanceAction verifies which action is due for a held remittance
+func TestHeldRemittanceAction(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	settings := models.DefaultTenantSettings(1)
+	settings.HoldNotifyAfterHours = 24
+	settings.HoldAutoReverseAfterHours = 72
+
+	held := func(hours int) *models.OutgoingRemittance {
+		heldAt := now.Add(-time.Duration(hours) * time.Hour)
+		return &models.OutgoingRemittance{
+			Status:           models.RemittanceStatusPending,
+			ComplianceHold:   true,
+			ComplianceHeldAt: &heldAt,
+			SettledAmountIRR: models.Zero(),
+		}
+	}
+
+	notified := held(30)
+	notified.ComplianceNotifiedAt = &now
+	partial := held(100)
+	partial.Status = models.RemittanceStatusPartial
+	partial.SettledAmountIRR = models.NewDecimal(1000000)
+	released := held(100)
+	released.ComplianceHold = false
+	cancelled := held(100)
+	cancelled.Status = models.RemittanceStatusCancelled
+
+	cases := []struct {
+		name     string
+		outgoing *models.OutgoingRemittance
+		want     string
+	}{
+		{"NotYetDue", held(10), models.HeldRemittanceActionNone},
+		{"Overdue", held(30), models.HeldRemittanceActionNotify},
+		{"AlreadyNotified", notified, models.HeldRemittanceActionNone},
+		{"PastAutoReverse", held(72), models.HeldRemittanceActionReverse},
+		{"PartiallySettledOnlyNotified", partial, models.HeldRemittanceActionNotify},
+		{"Released", released, models.HeldRemittanceActionNone},
+		{"Cancelled", cancelled, models.HeldRemittanceActionNone},
+	}
+	for _, c := range cases {
+		if got := HeldRemittanceAction(c.outgoing, &settings, now); got != c.want {
+			t.Errorf("%s: expected %q, got %q", c.name, c.want, got)
+		}
+	}
+
+	t.Run("AutoReverseDisabled", func(t *testing.T) {
+		disabled := settings
+		disabled.HoldAutoReverseAfterHours = 0
+		if got := HeldRemittanceAction(held(500), &disabled, now); got != models.HeldRemittanceActionNotify {
+			t.Errorf("Expected only a notification when auto-reverse is disabled, got %q", got)
+		}
+	})
+}
+
+// TestHeldRemittanceService_AutoReversePostsRefund verifies an auto-reversal cancels the remittance,
+// credits the sender what they paid and leaves an audit trail
+func TestHeldRemittanceService_AutoReversePostsRefund(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{},
+		&models.OutgoingRemittance{}, &models.LedgerEntry{}, &models.TenantSettings{}, &models.AuditLog{})
+
+	owner := &models.User{Email: "owner@held.test", Role: models.RoleTenantOwner}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Held Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+	db.Create(&models.TenantSettings{TenantID: tenant.ID, BaseCurrency: "CAD", HoldNotifyAfterHours: 24, HoldAutoReverseAfterHours: 72})
+	client := &models.Client{ID: "client-held", TenantID: tenant.ID, Name: "Held Sender", PhoneNumber: "+14165550190"}
+	db.Create(client)
+
+	now := time.Now()
+	create := func(code string, heldHours int, settled float64) *models.OutgoingRemittance {
+		heldAt := now.Add(-time.Duration(heldHours) * time.Hour)
+		status := models.RemittanceStatusPending
+		if settled > 0 {
+			status = models.RemittanceStatusPartial
+		}
+		outgoing := &models.OutgoingRemittance{
+			TenantID:           tenant.ID,
+			RemittanceCode:     code,
+			SenderName:         client.Name,
+			SenderPhone:        client.PhoneNumber,
+			ClientID:           &client.ID,
+			RecipientName:      "Recipient",
+			AmountIRR:          models.NewDecimal(100000000),
+			BuyRateCAD:         models.NewDecimal(80000),
+			EquivalentCAD:      models.NewDecimal(1250),
+			ReceivedCAD:        models.NewDecimal(1270),
+			SettlementCurrency: "CAD",
+			SettledAmountIRR:   models.NewDecimal(settled),
+			RemainingIRR:       models.NewDecimal(100000000 - settled),
+			Status:             status,
+			ComplianceHold:     true,
+			ComplianceHeldAt:   &heldAt,
+		}
+		db.Create(outgoing)
+		return outgoing
+	}
+
+	reversible := create("OUT-HELD-1", 80, 0)
+	partial := create("OUT-HELD-2", 80, 40000000)
+	recent := create("OUT-HELD-3", 2, 0)
+
+	results, err := NewHeldRemittanceService(db).CheckTenant(tenant.ID, now)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	actions := make(map[uint]string)
+	for _, result := range results {
+		actions[result.RemittanceID] = result.Action
+	}
+	if len(results) != 2 || actions[reversible.ID] != models.HeldRemittanceActionReverse ||
+		actions[partial.ID] != models.HeldRemittanceActionNotify {
+		t.Fatalf("Expected reverse and notify actions, got %+v", results)
+	}
+
+	var reloaded models.OutgoingRemittance
+	db.First(&reloaded, reversible.ID)
+	if reloaded.Status != models.RemittanceStatusCancelled || reloaded.CancellationReason == nil {
+		t.Errorf("Expected reversed remittance to be cancelled with a reason, got %s", reloaded.Status)
+	}
+
+	var entries []models.LedgerEntry
+	db.Where("client_id = ?", client.ID).Find(&entries)
+	if len(entries) != 1 {
+		t.Fatalf("Expected one refund entry, got %d", len(entries))
+	}
+	if entries[0].Type != models.LedgerTypeReversal || entries[0].Currency != "CAD" || entries[0].Amount.String() != "1270" {
+		t.Errorf("Expected a +1270 CAD reversal credit, got %s %s %s", entries[0].Type, entries[0].Amount.String(), entries[0].Currency)
+	}
+
+	var audits int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND entity_type = ?", "AUTO_REVERSE", "OUTGOING_REMITTANCE").Count(&audits)
+	if audits != 1 {
+		t.Errorf("Expected one auto-reverse audit log, got %d", audits)
+	}
+
+	reloaded = models.OutgoingRemittance{}
+	db.First(&reloaded, partial.ID)
+	if reloaded.Status != models.RemittanceStatusPartial || reloaded.ComplianceNotifiedAt == nil {
+		t.Errorf("Expected partially settled remittance to stay open and be marked notified, got %s", reloaded.Status)
+	}
+	reloaded = models.OutgoingRemittance{}
+	db.First(&reloaded, recent.ID)
+	if reloaded.Status != models.RemittanceStatusPending || reloaded.ComplianceNotifiedAt != nil {
+		t.Errorf("Expected recently held remittance to be left alone")
+	}
+
+	t.Run("NotifiesOnlyOnce", func(t *testing.T) {
+		again, err := NewHeldRemittanceService(db).CheckTenant(tenant.ID, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if len(again) != 0 {
+			t.Errorf("Expected no further actions, got %+v", again)
+		}
+	})
+
+	t.Run("UnlinkedSenderRefundedAndHolderAttributed", func(t *testing.T) {
+		officer := &models.User{Email: "officer@held.test", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+		db.Create(officer)
+		heldAt := now.Add(-80 * time.Hour)
+		unlinked := &models.OutgoingRemittance{
+			TenantID:           tenant.ID,
+			RemittanceCode:     "OUT-HELD-4",
+			SenderName:         "Walk-in Sender",
+			SenderPhone:        "+14165550191",
+			RecipientName:      "Recipient",
+			AmountIRR:          models.NewDecimal(40000000),
+			BuyRateCAD:         models.NewDecimal(80000),
+			EquivalentCAD:      models.NewDecimal(500),
+			ReceivedCAD:        models.NewDecimal(510),
+			SettlementCurrency: "CAD",
+			SettledAmountIRR:   models.Zero(),
+			RemainingIRR:       models.NewDecimal(40000000),
+			Status:             models.RemittanceStatusPending,
+			ComplianceHold:     true,
+			ComplianceHeldAt:   &heldAt,
+			ComplianceHeldBy:   &officer.ID,
+		}
+		db.Create(unlinked)
+
+		if _, err := NewHeldRemittanceService(db).CheckTenant(tenant.ID, now); err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+
+		var reversed models.OutgoingRemittance
+		db.First(&reversed, unlinked.ID)
+		if reversed.Status != models.RemittanceStatusCancelled || reversed.ClientID == nil {
+			t.Fatalf("Expected the remittance to be cancelled and linked to a sender client, got %s client=%v", reversed.Status, reversed.ClientID)
+		}
+		var sender models.Client
+		db.First(&sender, "id = ?", *reversed.ClientID)
+		if sender.PhoneNumber != "+14165550191" {
+			t.Errorf("Expected a client for the sender's phone, got %q", sender.PhoneNumber)
+		}
+
+		var refund models.LedgerEntry
+		if err := db.Where("client_id = ? AND type = ?", *reversed.ClientID, models.LedgerTypeReversal).First(&refund).Error; err != nil {
+			t.Fatalf("Expected a refund entry for the unlinked sender: %v", err)
+		}
+		if refund.Amount.String() != "510" || refund.CreatedBy != officer.ID {
+			t.Errorf("Expected a +510 refund by the officer, got %s by %d", refund.Amount.String(), refund.CreatedBy)
+		}
+
+		var audit models.AuditLog
+		db.Where("action = ? AND entity_id = ?", "AUTO_REVERSE", strconv.FormatUint(uint64(unlinked.ID), 10)).First(&audit)
+		if audit.UserID != officer.ID {
+			t.Errorf("Expected the reversal to be attributed to the officer who placed the hold, got user %d", audit.UserID)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MarginAlertService raises alerts when a currency pair's average margin drops below the tenant's floor
+type MarginAlertService struct {
+	db              *gorm.DB
+	settingsService *TenantSettingsService
+	profitService   *ProfitAnalysisService
+}
+
+// NewMarginAlertService creates a new MarginAlertService
+func NewMarginAlertService(db *gorm.DB) *MarginAlertService {
+	return &MarginAlertService{
+		db:              db,
+		settingsService: NewTenantSettingsService(db),
+		profitService:   NewProfitAnalysisService(db),
+	}
+}
+
+// CheckTenant computes each currency pair's margin (profit including fees over volume) across the
+// tenant's window ending now and raises an alert for pairs under the floor. Pairs with an alert
+// already open for an overlapping window are skipped.
+func (s *MarginAlertService) CheckTenant(tenantID uint, now time.Time) ([]models.MarginAlert, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.PairMarginFloorPct.IsPositive() || settings.PairMarginWindowDays <= 0 {
+		return nil, nil
+	}
+
+	windowStart := now.AddDate(0, 0, -settings.PairMarginWindowDays)
+	floor := settings.PairMarginFloorPct.Float64()
+
+	alerts := make([]models.MarginAlert, 0)
+	for _, pair := range s.profitService.GetProfitByCurrencyPair(tenantID, nil, windowStart, now) {
+		if pair.VolumeBase <= 0 {
+			continue
+		}
+		margin := pair.TotalProfitCAD / pair.VolumeBase * 100
+		if margin >= floor {
+			continue
+		}
+
+		var existing int64
+		s.db.Model(&models.MarginAlert{}).
+			Where("tenant_id = ? AND base_currency = ? AND target_currency = ? AND status = ? AND window_end > ?",
+				tenantID, pair.BaseCurrency, pair.TargetCurrency, models.MarginAlertStatusOpen, windowStart).
+			Count(&existing)
+		if existing > 0 {
+			continue
+		}
+
+		alert := models.MarginAlert{
+			TenantID:         tenantID,
+			BaseCurrency:     pair.BaseCurrency,
+			TargetCurrency:   pair.TargetCurrency,
+			Status:           models.MarginAlertStatusOpen,
+			MarginPct:        models.NewDecimal(margin).Round(4),
+			FloorPct:         settings.PairMarginFloorPct,
+			Profit:           models.NewDecimal(pair.TotalProfitCAD).Round(4),
+			Volume:           models.NewDecimal(pair.VolumeBase).Round(4),
+			TransactionCount: pair.TransactionCount,
+			WindowStart:      windowStart,
+			WindowEnd:        now,
+			Details: fmt.Sprintf("%s/%s margin over the last %d days is %.2f%%, below the %s%% floor (%d transactions)",
+				pair.BaseCurrency, pair.TargetCurrency, settings.PairMarginWindowDays, margin,
+				settings.PairMarginFloorPct.String(), pair.TransactionCount),
+		}
+		if err := s.db.Create(&alert).Error; err != nil {
+			return alerts, fmt.Errorf("failed to record margin alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+
+		GetHub().BroadcastToBranches(tenantID, nil, "profit", "margin_alert", map[string]interface{}{
+			"alertId":        alert.ID,
+			"baseCurrency":   alert.BaseCurrency,
+			"targetCurrency": alert.TargetCurrency,
+			"marginPct":      margin,
+			"floorPct":       settings.PairMarginFloorPct.Float64(),
+		})
+	}
+
+	return alerts, nil
+}
+
+// GetAlerts returns the tenant's margin alerts, newest first, optionally filtered by status
+func (s *MarginAlertService) GetAlerts(tenantID uint, status string, limit int) ([]models.MarginAlert, error) {
+	var alerts []models.MarginAlert
+	query := s.db.Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// ResolveAlert closes an open alert after review
+func (s *MarginAlertService) ResolveAlert(tenantID, alertID, userID uint) error {
+	now := time.Now()
+	result := s.db.Model(&models.MarginAlert{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", alertID, tenantID, models.MarginAlertStatusOpen).
+		Updates(map[string]interface{}{
+			"status":              models.MarginAlertStatusResolved,
+			"resolved_at":         now,
+			"resolved_by_user_id": userID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CheckAll runs the margin check for every active tenant
+func (s *MarginAlertService) CheckAll(now time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, now); err != nil {
+			log.Printf("⚠️  Margin alert check failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start runs the margin check on the given interval in the background
+func (s *MarginAlertService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Margin alert scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now()); err != nil {
+				log.Printf("⚠️  Margin alert check failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestMarginAlert_CompressedPairTriggersAlert verifies a pair under the margin floor raises one alert while a healthy pair does not
+func TestMarginAlert_CompressedPairTriggersAlert(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Client{}, &models.Transaction{},
+		&models.TenantSettings{}, &models.MarginAlert{})
+
+	tenant := newTestTenant(t, db, "Margin Exchange")
+	client := &models.Client{ID: "client-margin", TenantID: tenant.ID, Name: "Margin Client", PhoneNumber: "+14165550301"}
+	db.Create(client)
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.PairMarginFloorPct = models.NewDecimal(1)
+	db.Create(&settings)
+
+	now := time.Now()
+	seq := 0
+	record := func(receiveCurrency string, profit float64, at time.Time) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-margin-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: receiveCurrency,
+			ReceiveAmount:   models.NewDecimal(700),
+			RateApplied:     models.NewDecimal(0.7),
+			Profit:          models.NewDecimal(profit),
+			Status:          models.StatusCompleted,
+			TransactionDate: at,
+		})
+	}
+
+	// CAD/USD earns 0.2% this week; CAD/EUR earns 3%
+	record("USD", 2, now.AddDate(0, 0, -1))
+	record("USD", 2, now.AddDate(0, 0, -3))
+	record("EUR", 30, now.AddDate(0, 0, -2))
+	// A thin USD trade outside the 7-day window must not count
+	record("USD", 0, now.AddDate(0, 0, -20))
+
+	service := NewMarginAlertService(db)
+	alerts, err := service.CheckTenant(tenant.ID, now)
+	if err != nil {
+		t.Fatalf("CheckTenant failed: %v", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.BaseCurrency != "CAD" || alert.TargetCurrency != "USD" {
+		t.Errorf("Expected the CAD/USD pair to be flagged, got %s/%s", alert.BaseCurrency, alert.TargetCurrency)
+	}
+	if alert.TransactionCount != 2 {
+		t.Errorf("Expected 2 transactions in the window, got %d", alert.TransactionCount)
+	}
+	if margin := alert.MarginPct.Float64(); margin < 0.199 || margin > 0.201 {
+		t.Errorf("Expected a 0.2%% margin, got %v", margin)
+	}
+
+	t.Run("NoDuplicateWhileOpen", func(t *testing.T) {
+		again, err := service.CheckTenant(tenant.ID, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("CheckTenant failed: %v", err)
+		}
+		if len(again) != 0 {
+			t.Errorf("Expected no new alerts while one is open, got %d", len(again))
+		}
+	})
+
+	t.Run("DisabledWithoutFloor", func(t *testing.T) {
+		db.Model(&models.TenantSettings{}).Where("tenant_id = ?", tenant.ID).Update("pair_margin_floor_pct", models.Zero())
+		if err := service.ResolveAlert(tenant.ID, alert.ID, 1); err != nil {
+			t.Fatalf("ResolveAlert failed: %v", err)
+		}
+		disabled, err := service.CheckTenant(tenant.ID, now.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("CheckTenant failed: %v", err)
+		}
+		if len(disabled) != 0 {
+			t.Errorf("Expected no alerts with the floor disabled, got %d", len(disabled))
+		}
+	})
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"strings"
@@ -24,14 +26,42 @@ type NavasanRate struct {
 	FetchedAt     time.Time       `json:"fetched_at"`
 }
 
+// ErrNavasanRateNotFound is returned when Navasan does not quote the requested currency
+var ErrNavasanRateNotFound = errors.New("navasan rate not found")
+
+const (
+	navasanAPIURL          = "http://api.navasan.tech/latest/"
+	defaultNavasanCacheTTL = 60 * time.Second
+)
+
+// NavasanCacheTTL returns how long fetched rates are served without asking Navasan again,
+// from NAVASAN_CACHE_TTL (e.g. "2m")
+func NavasanCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(getEnv("NAVASAN_CACHE_TTL", defaultNavasanCacheTTL.String()))
+	if err != nil || ttl <= 0 {
+		return defaultNavasanCacheTTL
+	}
+	return ttl
+}
+
+// NavasanCacheStatus describes the rates served: when they were fetched and whether they are a
+// last-known-good fallback because Navasan could not be reached
+type NavasanCacheStatus struct {
+	FetchedAt time.Time
+	Stale     bool
+	Age       time.Duration
+}
+
 // NavasanService fetches exchange rates from navasan.tech
 type NavasanService struct {
 	client          *http.Client
+	apiURL          string
 	cachedRates     map[string]NavasanRate
 	cachedRaw       map[string]NavasanItem
 	cacheMutex      sync.RWMutex
 	cacheExpiry     time.Time
 	cacheTTL        time.Duration
+	fetchedAt       time.Time // Time of the last successful fetch; kept when a later fetch fails
 	updateThreshold float64
 }
 
@@ -41,9 +71,10 @@ func NewNavasanService() *NavasanService {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		apiURL:          navasanAPIURL,
 		cachedRates:     make(map[string]NavasanRate),
 		cachedRaw:       make(map[string]NavasanItem),
-		cacheTTL:        60 * time.Second,
+		cacheTTL:        NavasanCacheTTL(),
 		updateThreshold: 0.25,
 	}
 }
@@ -75,7 +106,36 @@ func (s *NavasanService) GetRates() (map[string]NavasanRate, error) {
 	return s.FetchRates()
 }
 
-// GetRawRates returns all Navasan items (from cache if valid, otherwise fetches fresh)
+// GetRatesWithStatus returns all Navasan rates like GetRates, but when Navasan cannot be reached it falls
+// back to the last successfully fetched rates, flagged stale in the returned status. It only fails if no
+// rates have ever been fetched.
+func (s *NavasanService) GetRatesWithStatus() (map[string]NavasanRate, NavasanCacheStatus, error) {
+	rates, err := s.GetRates()
+	if err == nil {
+		return rates, s.cacheStatus(false), nil
+	}
+
+	cached := s.cloneCachedRates()
+	if len(cached) == 0 {
+		return nil, NavasanCacheStatus{}, err
+	}
+	log.Printf("⚠️  Navasan fetch failed, serving last-known-good rates: %v", err)
+	return cached, s.cacheStatus(true), nil
+}
+
+// cacheStatus describes the cached rates
+func (s *NavasanService) cacheStatus(stale bool) NavasanCacheStatus {
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+	return NavasanCacheStatus{
+		FetchedAt: s.fetchedAt,
+		Stale:     stale,
+		Age:       time.Since(s.fetchedAt),
+	}
+}
+
+// GetRawRates returns all Navasan items (from cache if valid, otherwise fetches fresh). Like
+// GetRatesWithStatus it falls back to the last fetched items when Navasan cannot be reached.
 func (s *NavasanService) GetRawRates() (map[string]NavasanItem, error) {
 	s.cacheMutex.RLock()
 	if time.Now().Before(s.cacheExpiry) && len(s.cachedRaw) > 0 {
@@ -86,6 +146,11 @@ func (s *NavasanService) GetRawRates() (map[string]NavasanItem, error) {
 	s.cacheMutex.RUnlock()
 
 	if _, err := s.FetchRates(); err != nil {
+		s.cacheMutex.RLock()
+		defer s.cacheMutex.RUnlock()
+		if len(s.cachedRaw) > 0 {
+			return s.cloneCachedRaw(), nil
+		}
 		return nil, err
 	}
 
@@ -104,7 +169,7 @@ func (s *NavasanService) FetchRates() (map[string]NavasanRate, error) {
 		return nil, fmt.Errorf("NAVASAN_API_KEY not set")
 	}
 
-	url := fmt.Sprintf("http://api.navasan.tech/latest/?api_key=%s", apiKey)
+	url := fmt.Sprintf("%s?api_key=%s", s.apiURL, apiKey)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -148,6 +213,7 @@ func (s *NavasanService) FetchRates() (map[string]NavasanRate, error) {
 	s.cachedRates = rates
 	s.cachedRaw = s.cloneRawRates(apiResponse)
 	s.cacheExpiry = now.Add(s.cacheTTL)
+	s.fetchedAt = now
 	s.cacheMutex.Unlock()
 
 	return rates, nil
@@ -364,12 +430,28 @@ func (s *NavasanService) GetRate(currency string) (*NavasanRate, error) {
 
 	rate, ok := rates[strings.ToUpper(currency)]
 	if !ok {
-		return nil, fmt.Errorf("rate for %s not found", currency)
+		return nil, fmt.Errorf("%w: %s", ErrNavasanRateNotFound, currency)
 	}
 
 	return &rate, nil
 }
 
+// GetRateWithStatus returns the rate for a specific currency, falling back to the last-known-good rate
+// like GetRatesWithStatus. Use GetRate where a stale rate must not be used, e.g. to price a payment.
+func (s *NavasanService) GetRateWithStatus(currency string) (*NavasanRate, NavasanCacheStatus, error) {
+	rates, status, err := s.GetRatesWithStatus()
+	if err != nil {
+		return nil, status, err
+	}
+
+	rate, ok := rates[strings.ToUpper(currency)]
+	if !ok {
+		return nil, status, fmt.Errorf("%w: %s", ErrNavasanRateNotFound, currency)
+	}
+
+	return &rate, status, nil
+}
+
 // GetUSDToIRR returns the USD to IRR (Toman) rate
 func (s *NavasanService) GetUSDToIRR() (*NavasanRate, error) {
 	return s.GetRate("USD")
@@ -425,11 +507,11 @@ func formatWithCommas(n float64) string {
 	return result.String()
 }
 
-// FormatRatesForDisplay formats Navasan rates for API response
-func (s *NavasanService) FormatRatesForDisplay() ([]map[string]interface{}, error) {
-	rates, err := s.GetRates()
+// FormatRatesForDisplay formats Navasan rates for API response, with the status of the rates served
+func (s *NavasanService) FormatRatesForDisplay() ([]map[string]interface{}, NavasanCacheStatus, error) {
+	rates, status, err := s.GetRatesWithStatus()
 	if err != nil {
-		return nil, err
+		return nil, status, err
 	}
 
 	result := make([]map[string]interface{}, 0, len(rates))
@@ -448,7 +530,7 @@ func (s *NavasanService) FormatRatesForDisplay() ([]map[string]interface{}, erro
 		result = append(result, item)
 	}
 
-	return result, nil
+	return result, status, nil
 }
 
 // FormatRawRatesForDisplay returns the full list of API items for client use.
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestNavasanService_CachesAndFallsBackToLastKnownGood verifies cached rates are served within the TTL and
+// returned flagged stale when Navasan fails afterwards
+func TestNavasanService_CachesAndFallsBackToLastKnownGood(t *testing.T) {
+	t.Setenv("NAVASAN_API_KEY", "test-key")
+
+	var requests int32
+	var failing atomic.Bool
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if failing.Load() {
+			http.Error(w, "upstream down", http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"usd_sell":{"value":"84,500","change":500,"timestamp":1718000000,"date":"1403-03-21 12:00:00"},"cad_sell":{"value":"61200","change":-100,"timestamp":1718000000,"date":"1403-03-21 12:00:00"}}`))
+	}))
+	defer upstream.Close()
+
+	service := NewNavasanService()
+	service.apiURL = upstream.URL
+
+	t.Run("FreshHit", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			rate, status, err := service.GetRateWithStatus("usd")
+			if err != nil {
+				t.Fatalf("Failed to get USD rate: %v", err)
+			}
+			if rate.Value.String() != "84500" || status.Stale {
+				t.Errorf("Expected a fresh USD rate of 84500, got %s (stale=%v)", rate.Value, status.Stale)
+			}
+		}
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Errorf("Expected the second lookup to be served from cache, got %d upstream requests", got)
+		}
+	})
+
+	t.Run("StaleFallbackOnUpstreamError", func(t *testing.T) {
+		failing.Store(true)
+		service.cacheMutex.Lock()
+		service.cacheExpiry = time.Now().Add(-time.Second)
+		service.fetchedAt = time.Now().Add(-5 * time.Minute)
+		service.cacheMutex.Unlock()
+
+		rate, status, err := service.GetRateWithStatus("CAD")
+		if err != nil {
+			t.Fatalf("Expected last-known-good rates, got error: %v", err)
+		}
+		if rate.Value.String() != "61200" {
+			t.Errorf("Expected the cached CAD rate of 61200, got %s", rate.Value)
+		}
+		if !status.Stale || status.Age < 5*time.Minute {
+			t.Errorf("Expected stale rates at least 5 minutes old, got stale=%v age=%v", status.Stale, status.Age)
+		}
+		if got := atomic.LoadInt32(&requests); got != 2 {
+			t.Errorf("Expected one more upstream attempt, got %d requests", got)
+		}
+
+		// Pricing must not silently use a stale rate
+		if _, err := service.GetRate("CAD"); err == nil {
+			t.Error("Expected GetRate to fail while Navasan is down")
+		}
+	})
+
+	t.Run("UnknownCurrency", func(t *testing.T) {
+		if _, _, err := service.GetRateWithStatus("XYZ"); !errors.Is(err, ErrNavasanRateNotFound) {
+			t.Errorf("Expected ErrNavasanRateNotFound, got %v", err)
+		}
+	})
+}
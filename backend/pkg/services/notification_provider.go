@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// NotificationProvider delivers customer-facing messages over SMS and email
+type NotificationProvider interface {
+	SendSMS(to, message string) error
+	SendEmail(to, subject, body string) error
+	SendEmailWithAttachments(to, subject, body string, attachments []EmailAttachment) error
+}
+
+// DefaultNotificationProvider sends email through EmailService. No SMS gateway is
+// integrated yet, so SMS messages are logged.
+type DefaultNotificationProvider struct {
+	email *EmailService
+}
+
+// NewNotificationProvider returns the provider configured for this deployment
+func NewNotificationProvider() NotificationProvider {
+	return &DefaultNotificationProvider{email: NewEmailService()}
+}
+
+// ErrSMSNotConfigured is returned for SMS messages while no SMS gateway is integrated
+var ErrSMSNotConfigured = errors.New("no SMS gateway is configured")
+
+// SendSMS logs the message until an SMS gateway is configured. Nothing reaches the recipient, so it
+// reports ErrSMSNotConfigured rather than success.
+func (p *DefaultNotificationProvider) SendSMS(to, message string) error {
+	log.Printf("📱 [SMS] To %s: %s", to, message)
+	return ErrSMSNotConfigured
+}
+
+// SendEmail sends the message via the configured email provider
+func (p *DefaultNotificationProvider) SendEmail(to, subject, body string) error {
+	return p.email.SendEmail(to, subject, body)
+}
+
+// SendEmailWithAttachments sends the message and files via the configured email provider
+func (p *DefaultNotificationProvider) SendEmailWithAttachments(to, subject, body string, attachments []EmailAttachment) error {
+	return p.email.SendEmailWithAttachments(to, subject, body, attachments)
+}
+
+// SentNotification is a message captured by MockNotificationProvider
+type SentNotification struct {
+	Channel     string // "sms" or "email"
+	To          string
+	Subject     string
+	Body        string
+	Attachments []EmailAttachment
+}
+
+// MockNotificationProvider records messages instead of sending them, for testing
+type MockNotificationProvider struct {
+	mu   sync.Mutex
+	Sent []SentNotification
+}
+
+// NewMockNotificationProvider creates a mock provider
+func NewMockNotificationProvider() *MockNotificationProvider {
+	return &MockNotificationProvider{}
+}
+
+// SendSMS records an SMS
+func (m *MockNotificationProvider) SendSMS(to, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Sent = append(m.Sent, SentNotification{Channel: "sms", To: to, Body: message})
+	return nil
+}
+
+// SendEmail records an email
+func (m *MockNotificationProvider) SendEmail(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Sent = append(m.Sent, SentNotification{Channel: "email", To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// SendEmailWithAttachments records an email and its attachments
+func (m *MockNotificationProvider) SendEmailWithAttachments(to, subject, body string, attachments []EmailAttachment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Sent = append(m.Sent, SentNotification{Channel: "email", To: to, Subject: subject, Body: body, Attachments: attachments})
+	return nil
+}
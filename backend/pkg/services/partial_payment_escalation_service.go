@@ -0,0 +1,150 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errCollectionTicketLinked rolls back a follow-up ticket when another run linked one to the transaction first
+var errCollectionTicketLinked = errors.New("transaction already has a collection ticket")
+
+// PartialPaymentEscalationService opens a follow-up ticket for transactions left partially paid for too long
+type PartialPaymentEscalationService struct {
+	db              *gorm.DB
+	settingsService *TenantSettingsService
+}
+
+// NewPartialPaymentEscalationService creates a new PartialPaymentEscalationService
+func NewPartialPaymentEscalationService(db *gorm.DB) *PartialPaymentEscalationService {
+	return &PartialPaymentEscalationService{
+		db:              db,
+		settingsService: NewTenantSettingsService(db),
+	}
+}
+
+// CheckTenant opens a ticket for every partially paid transaction of the tenant older than its
+// PartialPaymentTicketAfterDays and returns the tickets opened. Each transaction gets at most one ticket.
+func (s *PartialPaymentEscalationService) CheckTenant(tenantID uint, now time.Time) ([]models.Ticket, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.PartialPaymentTicketAfterDays <= 0 {
+		return nil, nil
+	}
+
+	var transactions []models.Transaction
+	if err := s.db.Where("tenant_id = ? AND payment_status = ? AND status <> ? AND collection_ticket_id IS NULL AND created_at <= ?",
+		tenantID, models.PaymentStatusPartial, models.StatusCancelled, now.AddDate(0, 0, -settings.PartialPaymentTicketAfterDays)).
+		Order("created_at ASC").
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	var tenant models.Tenant
+	if err := s.db.First(&tenant, tenantID).Error; err != nil {
+		return nil, fmt.Errorf("tenant not found: %w", err)
+	}
+
+	var opened []models.Ticket
+	for _, txn := range transactions {
+		var ticket *models.Ticket
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			ticket, err = s.openTicket(tx, &tenant, &txn, now)
+			if err != nil {
+				return err
+			}
+
+			// Only keep the ticket if no other run linked one first
+			result := tx.Model(&models.Transaction{}).
+				Where("id = ? AND collection_ticket_id IS NULL", txn.ID).
+				Update("collection_ticket_id", ticket.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errCollectionTicketLinked
+			}
+			return nil
+		})
+		if errors.Is(err, errCollectionTicketLinked) {
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️  Partial payment escalation for transaction %s failed: %v", txn.ID, err)
+			continue
+		}
+		opened = append(opened, *ticket)
+	}
+
+	return opened, nil
+}
+
+// openTicket creates the follow-up ticket for one partially paid transaction
+func (s *PartialPaymentEscalationService) openTicket(tx *gorm.DB, tenant *models.Tenant, txn *models.Transaction, now time.Time) (*models.Ticket, error) {
+	reference := txn.ID
+	if txn.TransactionNumber != nil && *txn.TransactionNumber != "" {
+		reference = *txn.TransactionNumber
+	}
+	currency := txn.ReceivedCurrency
+	if currency == "" {
+		currency = txn.SendCurrency
+	}
+	daysOpen := int(now.Sub(txn.CreatedAt).Hours() / 24)
+
+	return NewTicketService(tx).CreateTicket(tenant.ID, tenant.OwnerID, CreateTicketRequest{
+		Subject: fmt.Sprintf("Transaction %s is still partially paid after %d days", reference, daysOpen),
+		Description: fmt.Sprintf(
+			"Transaction %s (client %s) has been partially paid for %d days. Remaining balance: %.2f %s.",
+			txn.ID, txn.ClientID, daysOpen, txn.RemainingBalance.Float64(), currency,
+		),
+		Priority:          models.TicketPriorityMedium,
+		Category:          models.TicketCategoryTransaction,
+		BranchID:          txn.BranchID,
+		RelatedEntityType: "transaction",
+		RelatedEntityRef:  txn.ID,
+		Tags:              "collections,automated",
+	})
+}
+
+// CheckAll runs the partial payment check for every active tenant
+func (s *PartialPaymentEscalationService) CheckAll(now time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, now); err != nil {
+			log.Printf("⚠️  Partial payment escalation failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start runs the partial payment check on the given interval in the background
+func (s *PartialPaymentEscalationService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Partial payment escalation scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now()); err != nil {
+				log.Printf("⚠️  Partial payment escalation check failed: %v", err)
+			}
+		}
+	}()
+}
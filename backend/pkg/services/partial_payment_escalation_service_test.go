@@ -0,0 +1,126 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestPartialPaymentEscalation_TicketsAgedTransactionsOnce verifies an aged partial transaction gets exactly one ticket and a recent one none
+func TestPartialPaymentEscalation_TicketsAgedTransactionsOnce(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.Ticket{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	owner := &models.User{Email: "owner@collections.test", Role: models.RoleTenantOwner}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Collections Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.PartialPaymentTicketAfterDays = 7
+	db.Create(&settings)
+	db.Create(&models.Client{ID: "client-collections", TenantID: tenant.ID, Name: "Slow Payer", PhoneNumber: "+14165550177"})
+
+	now := time.Now()
+	create := func(id string, createdAt time.Time) {
+		db.Create(&models.Transaction{
+			ID:               id,
+			TenantID:         tenant.ID,
+			ClientID:         "client-collections",
+			PaymentMethod:    "CASH",
+			SendCurrency:     "CAD",
+			SendAmount:       models.NewDecimal(1000),
+			ReceiveCurrency:  "USD",
+			ReceiveAmount:    models.NewDecimal(730),
+			TotalReceived:    models.NewDecimal(1000),
+			ReceivedCurrency: "CAD",
+			TotalPaid:        models.NewDecimal(400),
+			RemainingBalance: models.NewDecimal(600),
+			PaymentStatus:    models.PaymentStatusPartial,
+			Status:           models.StatusCompleted,
+			CreatedAt:        createdAt,
+		})
+	}
+	create("txn-aged-partial", now.AddDate(0, 0, -10))
+	create("txn-recent-partial", now.AddDate(0, 0, -1))
+
+	service := NewPartialPaymentEscalationService(db)
+	for run := 0; run < 2; run++ {
+		if _, err := service.CheckTenant(tenant.ID, now); err != nil {
+			t.Fatalf("Escalation run %d failed: %v", run+1, err)
+		}
+	}
+
+	var tickets []models.Ticket
+	db.Where("tenant_id = ?", tenant.ID).Find(&tickets)
+	if len(tickets) != 1 {
+		t.Fatalf("Expected exactly one ticket after two runs, got %d", len(tickets))
+	}
+	if tickets[0].RelatedEntityType != "transaction" || tickets[0].RelatedEntityRef != "txn-aged-partial" || tickets[0].Category != models.TicketCategoryTransaction {
+		t.Errorf("Expected a transaction ticket for txn-aged-partial, got %s %q/%s", tickets[0].RelatedEntityType, tickets[0].RelatedEntityRef, tickets[0].Category)
+	}
+
+	var aged, recent models.Transaction
+	db.First(&aged, "id = ?", "txn-aged-partial")
+	db.First(&recent, "id = ?", "txn-recent-partial")
+	if aged.CollectionTicketID == nil || *aged.CollectionTicketID != tickets[0].ID {
+		t.Errorf("Expected the aged transaction to link ticket %d, got %v", tickets[0].ID, aged.CollectionTicketID)
+	}
+	if recent.CollectionTicketID != nil {
+		t.Errorf("Expected no ticket for the recent transaction, got %d", *recent.CollectionTicketID)
+	}
+}
+
+// TestPartialPaymentEscalation_RollsBackTicketLinkedElsewhere verifies the ticket is discarded when another
+// run links one to the transaction while it is being opened
+func TestPartialPaymentEscalation_RollsBackTicketLinkedElsewhere(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.Ticket{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	owner := &models.User{Email: "owner@race.test", Role: models.RoleTenantOwner}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Race Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.PartialPaymentTicketAfterDays = 7
+	db.Create(&settings)
+	db.Create(&models.Transaction{
+		ID:               "txn-race",
+		TenantID:         tenant.ID,
+		ClientID:         "client-race",
+		PaymentMethod:    "CASH",
+		SendCurrency:     "CAD",
+		SendAmount:       models.NewDecimal(1000),
+		ReceiveCurrency:  "USD",
+		ReceiveAmount:    models.NewDecimal(730),
+		RemainingBalance: models.NewDecimal(600),
+		PaymentStatus:    models.PaymentStatusPartial,
+		Status:           models.StatusCompleted,
+		CreatedAt:        time.Now().AddDate(0, 0, -10),
+	})
+
+	// Another run links its own ticket as soon as this one has created its ticket
+	err := db.Callback().Create().After("gorm:create").Register("test:link_elsewhere", func(tx *gorm.DB) {
+		if tx.Statement.Table == "tickets" {
+			tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE transactions SET collection_ticket_id = 999 WHERE id = ?", "txn-race")
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+
+	opened, err := NewPartialPaymentEscalationService(db).CheckTenant(tenant.ID, time.Now())
+	if err != nil {
+		t.Fatalf("Escalation failed: %v", err)
+	}
+	if len(opened) != 0 {
+		t.Errorf("Expected no ticket to be reported, got %d", len(opened))
+	}
+
+	var tickets int64
+	db.Model(&models.Ticket{}).Where("tenant_id = ?", tenant.ID).Count(&tickets)
+	if tickets != 0 {
+		t.Errorf("Expected the ticket to be rolled back, got %d", tickets)
+	}
+}
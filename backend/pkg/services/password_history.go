@@ -0,0 +1,87 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// maxPasswordHistoryDepth bounds the configurable history so a change never needs an unbounded number of bcrypt comparisons
+const maxPasswordHistoryDepth = 24
+
+// ErrPasswordReused is returned when a new password matches the current one or one in the user's history
+var ErrPasswordReused = errors.New("new password must not match any of your recent passwords")
+
+// passwordHistoryDepth returns how many previous passwords the user's tenant remembers
+func passwordHistoryDepth(tx *gorm.DB, user *models.User) (int, error) {
+	if user.TenantID == nil {
+		return models.DefaultPasswordHistoryDepth, nil
+	}
+	settings, err := NewTenantSettingsService(tx).GetSettings(*user.TenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if settings.PasswordHistoryDepth > maxPasswordHistoryDepth {
+		return maxPasswordHistoryDepth, nil
+	}
+	return settings.PasswordHistoryDepth, nil
+}
+
+// setPassword replaces the user's password, refusing the current password or any remembered one,
+// and moves the old hash into the user's password history
+func (as *AuthService) setPassword(user *models.User, newPassword string) error {
+	return as.DB.Transaction(func(tx *gorm.DB) error {
+		depth, err := passwordHistoryDepth(tx, user)
+		if err != nil {
+			return err
+		}
+
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(newPassword)) == nil {
+			return ErrPasswordReused
+		}
+
+		var history []models.PasswordHistory
+		if depth > 0 {
+			if err := tx.Where("user_id = ?", user.ID).Order("created_at DESC, id DESC").Limit(depth).Find(&history).Error; err != nil {
+				return fmt.Errorf("failed to load password history: %w", err)
+			}
+		}
+		for _, previous := range history {
+			if bcrypt.CompareHashAndPassword([]byte(previous.PasswordHash), []byte(newPassword)) == nil {
+				return ErrPasswordReused
+			}
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return errors.New("failed to hash new password")
+		}
+
+		if depth > 0 && user.PasswordHash != "" {
+			if err := tx.Create(&models.PasswordHistory{UserID: user.ID, PasswordHash: user.PasswordHash}).Error; err != nil {
+				return fmt.Errorf("failed to record password history: %w", err)
+			}
+		}
+
+		// Keep only the most recent depth entries
+		var ids []uint
+		if err := tx.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).
+			Order("created_at DESC, id DESC").Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("failed to prune password history: %w", err)
+		}
+		if len(ids) > depth {
+			if err := tx.Where("id IN ?", ids[depth:]).Delete(&models.PasswordHistory{}).Error; err != nil {
+				return fmt.Errorf("failed to prune password history: %w", err)
+			}
+		}
+
+		if err := tx.Model(user).Update("password_hash", string(hashedPassword)).Error; err != nil {
+			return errors.New("failed to update password")
+		}
+		user.PasswordHash = string(hashedPassword)
+		return nil
+	})
+}
@@ -6,16 +6,47 @@ import (
 	"api/pkg/utils"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// ErrPaymentRateUnavailable is returned when the service cannot resolve a rate for a payment
+var ErrPaymentRateUnavailable = errors.New("payment exchange rate could not be resolved")
+
+// PaymentRejectedError is a reason a payment was refused, as opposed to a database failure
+type PaymentRejectedError struct {
+	Err error
+}
+
+func (e *PaymentRejectedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PaymentRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// rejectPayment marks err as a refusal of the payment itself
+func rejectPayment(err error) error {
+	return &PaymentRejectedError{Err: err}
+}
+
+// ErrTransactionSettled is returned when voiding a transaction whose funds have already been paid out or settled
+var ErrTransactionSettled = errors.New("transaction is part of a completed settlement and cannot be voided")
+
 type PaymentService struct {
 	db                 *gorm.DB
 	ledgerService      *LedgerService
 	cashBalanceService *CashBalanceService
+	navasanService     *NavasanService
+
+	// Events receives payment domain events once their database transaction has committed
+	Events EventBus
 }
 
 func NewPaymentService(db *gorm.DB, ledgerService *LedgerService, cashBalanceService *CashBalanceService) *PaymentService {
@@ -23,46 +54,117 @@ func NewPaymentService(db *gorm.DB, ledgerService *LedgerService, cashBalanceSer
 		db:                 db,
 		ledgerService:      ledgerService,
 		cashBalanceService: cashBalanceService,
+		navasanService:     NewNavasanService(),
+		Events:             GetEventBus(),
 	}
 }
 
 // CreatePayment adds a new payment to a transaction and updates totals
 func (s *PaymentService) CreatePayment(payment *models.Payment, userID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		return s.CreatePaymentWithTx(tx, payment, userID)
+	var transaction *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = s.createPaymentWithTx(tx, payment, userID)
+		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(paymentEvent(EventPaymentCreated, payment, transaction))
+	return nil
 }
 
-// CreatePaymentWithTx internal logic for creating payment within a transaction
+// CreatePaymentWithTx internal logic for creating payment within a transaction.
+// No event is published: the caller owns the transaction and its commit.
 func (s *PaymentService) CreatePaymentWithTx(tx *gorm.DB, payment *models.Payment, userID uint) error {
+	_, err := s.createPaymentWithTx(tx, payment, userID)
+	return err
+}
+
+// paymentEvent builds a payment domain event carrying the parent transaction's new balance
+func paymentEvent(name string, payment *models.Payment, transaction *models.Transaction) DomainEvent {
+	branchID := payment.BranchID
+	if branchID == nil {
+		branchID = transaction.BranchID
+	}
+	return DomainEvent{
+		Name:     name,
+		TenantID: payment.TenantID,
+		Data: map[string]interface{}{
+			"paymentId":        payment.ID,
+			"transactionId":    transaction.ID,
+			"tenantId":         payment.TenantID,
+			"branchId":         branchID,
+			"amount":           payment.Amount.Float64(),
+			"currency":         payment.Currency,
+			"paymentMethod":    payment.PaymentMethod,
+			"totalPaid":        transaction.TotalPaid.Float64(),
+			"remainingBalance": transaction.RemainingBalance.Float64(),
+			"paymentStatus":    transaction.PaymentStatus,
+		},
+		OccurredAt: time.Now(),
+	}
+}
+
+// publish hands committed events to the event bus
+func (s *PaymentService) publish(events ...DomainEvent) {
+	if s.Events == nil {
+		return
+	}
+	for _, event := range events {
+		s.Events.Publish(event)
+	}
+}
+
+// createPaymentWithTx creates the payment and returns the updated parent transaction
+func (s *PaymentService) createPaymentWithTx(tx *gorm.DB, payment *models.Payment, userID uint) (*models.Transaction, error) {
 	// 1. Load the transaction with FOR UPDATE lock to prevent race conditions
 	var transaction models.Transaction
 	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ? AND tenant_id = ?", payment.TransactionID, payment.TenantID).
 		First(&transaction).Error; err != nil {
-		return fmt.Errorf("transaction not found: %w", err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, rejectPayment(fmt.Errorf("transaction not found: %w", err))
+		}
+		return nil, fmt.Errorf("failed to load transaction: %w", err)
 	}
 
 	// 2. Validate transaction allows partial payments
 	if !transaction.AllowPartialPayment {
-		return errors.New("this transaction does not support partial payments")
+		return nil, rejectPayment(errors.New("this transaction does not support partial payments"))
 	}
 
 	// 3. Validate transaction is not cancelled or already completed
 	if transaction.Status == models.StatusCancelled {
-		return errors.New("cannot add payment to cancelled transaction")
+		return nil, rejectPayment(errors.New("cannot add payment to cancelled transaction"))
 	}
 	if transaction.PaymentStatus == models.PaymentStatusFullyPaid {
-		return errors.New("transaction is already fully paid")
+		return nil, rejectPayment(errors.New("transaction is already fully paid"))
 	}
 
 	// 4. Validate Payment Details using Strategy
 	strategy := strategies.GetPaymentStrategy(payment.PaymentMethod)
 	if err := strategy.Validate(payment.Details); err != nil {
-		return fmt.Errorf("invalid payment details for %s: %w", payment.PaymentMethod, err)
+		return nil, rejectPayment(fmt.Errorf("invalid payment details for %s: %w", payment.PaymentMethod, err))
 	}
 
-	// 5. Convert payment amount to transaction's base currency
+	// 5. Resolve the rate and convert payment amount to transaction's base currency
+	if payment.PaidAt.IsZero() {
+		payment.PaidAt = time.Now()
+	}
+	if err := ensureDayOpen(tx, payment.TenantID, payment.BranchID, payment.PaidAt); err != nil {
+		if errors.Is(err, ErrDayClosed) {
+			return nil, rejectPayment(err)
+		}
+		return nil, err
+	}
+	if err := s.resolvePaymentRate(tx, payment, transaction.ReceivedCurrency); err != nil {
+		if errors.Is(err, ErrPaymentRateUnavailable) {
+			return nil, rejectPayment(err)
+		}
+		return nil, err
+	}
 	// payment.AmountInBase = payment.Amount * payment.ExchangeRate
 	payment.AmountInBase = payment.Amount.Mul(payment.ExchangeRate)
 
@@ -71,22 +173,19 @@ func (s *PaymentService) CreatePaymentWithTx(tx *gorm.DB, payment *models.Paymen
 	newTotalPaid := transaction.TotalPaid.Add(payment.AmountInBase)
 	// if newTotalPaid > transaction.TotalReceived {
 	if newTotalPaid.GreaterThan(transaction.TotalReceived) {
-		return fmt.Errorf("payment exceeds remaining balance. Remaining: %s %s",
-			transaction.RemainingBalance.String(), transaction.ReceivedCurrency)
+		return nil, rejectPayment(fmt.Errorf("payment exceeds remaining balance. Remaining: %s %s",
+			transaction.RemainingBalance.String(), transaction.ReceivedCurrency))
 	}
 
 	// 6. Set default values
 	if payment.Status == "" {
 		payment.Status = models.PaymentStatusCompleted
 	}
-	if payment.PaidAt.IsZero() {
-		payment.PaidAt = time.Now()
-	}
 	payment.PaidBy = userID
 
 	// 7. Create payment
 	if err := tx.Create(payment).Error; err != nil {
-		return fmt.Errorf("failed to create payment: %w", err)
+		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
 
 	// 8. Update transaction totals
@@ -105,7 +204,10 @@ func (s *PaymentService) CreatePaymentWithTx(tx *gorm.DB, payment *models.Paymen
 
 	// 10. Save transaction
 	if err := tx.Save(&transaction).Error; err != nil {
-		return fmt.Errorf("failed to update transaction: %w", err)
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	if err := s.syncPaymentPlan(tx, &transaction, payment.PaidAt); err != nil {
+		return nil, err
 	}
 
 	// 11. Create Ledger Entry (Credit Client)
@@ -124,7 +226,7 @@ func (s *PaymentService) CreatePaymentWithTx(tx *gorm.DB, payment *models.Paymen
 		CreatedAt:     time.Now(),
 	}
 	if _, err := s.ledgerService.AddEntryWithTx(tx, ledgerEntry); err != nil {
-		return fmt.Errorf("failed to create ledger entry: %w", err)
+		return nil, fmt.Errorf("failed to create ledger entry: %w", err)
 	}
 
 	// 12. Update Cash Balance (Increase Cash)
@@ -132,11 +234,135 @@ func (s *PaymentService) CreatePaymentWithTx(tx *gorm.DB, payment *models.Paymen
 	if payment.PaymentMethod == models.PaymentMethodCash {
 		err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, payment.Amount.Float64(), fmt.Sprintf("Payment for Transaction #%s", transaction.ID), userID)
 		if err != nil {
-			return fmt.Errorf("failed to update cash balance: %w", err)
+			return nil, fmt.Errorf("failed to update cash balance: %w", err)
 		}
 	}
 
-	return nil
+	return &transaction, nil
+}
+
+// PaymentBatchItemResult reports the outcome of one payment in a batch
+type PaymentBatchItemResult struct {
+	Index         int             `json:"index"`
+	TransactionID string          `json:"transactionId"`
+	Success       bool            `json:"success"`
+	Payment       *models.Payment `json:"payment,omitempty"`
+	Error         string          `json:"error,omitempty"`
+}
+
+// CreatePaymentsBatch posts many payments in a single database transaction. Each payment gets the
+// same validation, ledger and cash updates as CreatePayment; a payment that is rejected is rolled back
+// to its savepoint and reported without aborting the rest. Any other failure, such as a database error,
+// rolls back the whole batch.
+func (s *PaymentService) CreatePaymentsBatch(payments []*models.Payment, userID uint) ([]PaymentBatchItemResult, error) {
+	results := make([]PaymentBatchItemResult, 0, len(payments))
+	var events []DomainEvent
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, payment := range payments {
+			result := PaymentBatchItemResult{Index: i, TransactionID: payment.TransactionID}
+
+			savepoint := fmt.Sprintf("payment_batch_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+
+			// createPaymentWithTx locks the parent transaction FOR UPDATE and re-reads its totals,
+			// so several payments against one transaction accumulate correctly
+			transaction, err := s.createPaymentWithTx(tx, payment, userID)
+			if err != nil {
+				var rejected *PaymentRejectedError
+				if !errors.As(err, &rejected) {
+					return fmt.Errorf("payment %d: %w", i, err)
+				}
+				if rbErr := tx.RollbackTo(savepoint).Error; rbErr != nil {
+					return fmt.Errorf("failed to roll back payment %d: %w", i, rbErr)
+				}
+				payment.ID = 0
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+				result.Payment = payment
+				events = append(events, paymentEvent(EventPaymentCreated, payment, transaction))
+			}
+
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events...)
+	return results, nil
+}
+
+// resolvePaymentRate sets ExchangeRate for the payment currency -> received currency pair
+// according to RateSource. Manual rates must be supplied; other sources are looked up,
+// stored rates through tx so they see the same connection as the payment.
+func (s *PaymentService) resolvePaymentRate(tx *gorm.DB, payment *models.Payment, receivedCurrency string) error {
+	if payment.RateSource == "" {
+		payment.RateSource = models.PaymentRateSourceManual
+	}
+
+	switch payment.RateSource {
+	case models.PaymentRateSourceManual:
+		if !payment.ExchangeRate.IsPositive() {
+			return fmt.Errorf("%w: a positive manual rate is required", ErrPaymentRateUnavailable)
+		}
+		return nil
+
+	case models.PaymentRateSourceStored:
+		rate, err := NewExchangeRateService(tx).GetHistoricalRate(payment.TenantID, payment.Currency, receivedCurrency, payment.PaidAt)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrPaymentRateUnavailable, err)
+		}
+		payment.ExchangeRate = rate
+		return nil
+
+	case models.PaymentRateSourceNavasan:
+		rate, err := s.navasanCrossRate(payment.Currency, receivedCurrency)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrPaymentRateUnavailable, err)
+		}
+		payment.ExchangeRate = rate
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown rate source %q", ErrPaymentRateUnavailable, payment.RateSource)
+}
+
+// navasanCrossRate derives from -> to from Navasan's Toman quotes
+func (s *PaymentService) navasanCrossRate(from, to string) (models.Decimal, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return models.NewDecimal(1), nil
+	}
+
+	tomanValue := func(currency string) (models.Decimal, error) {
+		if currency == "IRR" {
+			return models.NewDecimal(1), nil
+		}
+		rate, err := s.navasanService.GetRate(currency)
+		if err != nil {
+			return models.Zero(), err
+		}
+		if !rate.Value.IsPositive() {
+			return models.Zero(), fmt.Errorf("rate for %s is zero", currency)
+		}
+		return models.Decimal{Decimal: rate.Value}, nil
+	}
+
+	fromValue, err := tomanValue(from)
+	if err != nil {
+		return models.Zero(), err
+	}
+	toValue, err := tomanValue(to)
+	if err != nil {
+		return models.Zero(), err
+	}
+	return fromValue.Div(toValue), nil
 }
 
 // GetPayments retrieves all payments for a transaction
@@ -171,7 +397,8 @@ func (s *PaymentService) GetPayment(paymentID uint, tenantID uint) (*models.Paym
 
 // UpdatePayment updates a payment and recalculates transaction totals
 func (s *PaymentService) UpdatePayment(paymentID uint, tenantID uint, updates map[string]interface{}, userID uint, reason string) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var event DomainEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Load current payment
 		var payment models.Payment
 		if err := tx.Where("id = ? AND tenant_id = ?", paymentID, tenantID).First(&payment).Error; err != nil {
@@ -182,6 +409,15 @@ func (s *PaymentService) UpdatePayment(paymentID uint, tenantID uint, updates ma
 		if payment.Status == models.PaymentStatusCancelled {
 			return errors.New("cannot edit cancelled payment")
 		}
+		if payment.RefundedAmount.IsPositive() {
+			return errors.New("cannot edit a payment that has been partially refunded")
+		}
+		// The edit changes the day the payment was made and posts its adjustments into today
+		for _, day := range []time.Time{payment.PaidAt, time.Now()} {
+			if err := ensureDayOpen(tx, payment.TenantID, payment.BranchID, day); err != nil {
+				return err
+			}
+		}
 
 		oldAmountInBase := payment.AmountInBase
 		oldAmount := payment.Amount
@@ -256,143 +492,133 @@ func (s *PaymentService) UpdatePayment(paymentID uint, tenantID uint, updates ma
 		if err := tx.Save(&transaction).Error; err != nil {
 			return fmt.Errorf("failed to update transaction: %w", err)
 		}
+		if err := s.syncPaymentPlan(tx, &transaction, time.Now()); err != nil {
+			return err
+		}
 
 		// 11. Handle Ledger and Cash Balance Adjustments
-		// Case A: Amount Changed (Same Currency)
-		if payment.Currency == oldCurrency {
-			amountDifference := payment.Amount.Sub(oldAmount)
-			if !amountDifference.IsZero() {
-				// Create Ledger Entry for the difference
-				ledgerType := models.LedgerTypeDeposit
-				if amountDifference.IsNegative() {
-					ledgerType = models.LedgerTypeWithdrawal // Correction (Debit)
-				}
+		from := paymentPosition{Amount: oldAmount, Currency: oldCurrency, Method: oldPaymentMethod}
+		to := paymentPosition{Amount: payment.Amount, Currency: payment.Currency, Method: payment.PaymentMethod}
+		if err := s.postPaymentAdjustment(tx, &payment, &transaction, from, to, userID, reason); err != nil {
+			return err
+		}
 
-				ledgerEntry := models.LedgerEntry{
-					TenantID:      payment.TenantID,
-					ClientID:      transaction.ClientID,
-					BranchID:      payment.BranchID,
-					TransactionID: &transaction.ID,
-					Type:          ledgerType,
-					Currency:      payment.Currency,
-					Amount:        amountDifference,
-					Description:   fmt.Sprintf("Adjustment for Payment #%d (Update): %s", payment.ID, reason),
-					ExchangeRate:  &payment.ExchangeRate,
-					CreatedBy:     userID,
-					CreatedAt:     time.Now(),
-				}
-				if err := tx.Create(&ledgerEntry).Error; err != nil {
-					return fmt.Errorf("failed to create ledger adjustment: %w", err)
-				}
+		event = paymentEvent(EventPaymentUpdated, &payment, &transaction)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-				// --- FIXED CASH BALANCE LOGIC ---
-				// 1. If stayed Cash: Adjust by difference
-				if payment.PaymentMethod == models.PaymentMethodCash && oldPaymentMethod == models.PaymentMethodCash {
-					err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, amountDifference.Float64(), fmt.Sprintf("Adjustment for Payment #%d (Update): %s", payment.ID, reason), userID)
-					if err != nil {
-						return fmt.Errorf("failed to update cash balance: %w", err)
-					}
-				} else if oldPaymentMethod == models.PaymentMethodCash && payment.PaymentMethod != models.PaymentMethodCash {
-					// 2. Was Cash, became Non-Cash: Reverse OLD amount (remove from cash)
-					err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -oldAmount.Float64(), fmt.Sprintf("Adjustment (Method Change) for Payment #%d: %s", payment.ID, reason), userID)
-					if err != nil {
-						return fmt.Errorf("failed to reverse old cash balance: %w", err)
-					}
-				} else if oldPaymentMethod != models.PaymentMethodCash && payment.PaymentMethod == models.PaymentMethodCash {
-					// 3. Was Non-Cash, became Cash: Add NEW amount (add to cash)
-					err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, payment.Amount.Float64(), fmt.Sprintf("Adjustment (Method Change) for Payment #%d: %s", payment.ID, reason), userID)
-					if err != nil {
-						return fmt.Errorf("failed to add new cash balance: %w", err)
-					}
-				}
-			} else {
-				// Amount didn't change, but Method might have
-				if oldPaymentMethod == models.PaymentMethodCash && payment.PaymentMethod != models.PaymentMethodCash {
-					// Remove old amount
-					err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -oldAmount.Float64(), fmt.Sprintf("Method Change (Cash->%s) Payment #%d", payment.PaymentMethod, payment.ID), userID)
-					if err != nil {
-						return err
-					}
-				} else if oldPaymentMethod != models.PaymentMethodCash && payment.PaymentMethod == models.PaymentMethodCash {
-					// Add new amount (same as old amount)
-					err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, payment.Amount.Float64(), fmt.Sprintf("Method Change (%s->Cash) Payment #%d", oldPaymentMethod, payment.ID), userID)
-					if err != nil {
-						return err
-					}
-				}
-			}
-		} else {
-			// Case B: Currency Changed (Complex)
-			// Reversing old amount and adding new amount is safer but more complex to track.
-			// For now, let's assume currency change is rare or handled by reversing and creating new.
-			// But if we must support it:
-			// 1. Reverse old amount (Debit Client, Decrease Cash)
-			// 2. Add new amount (Credit Client, Increase Cash)
-
-			// Reverse Old
-			ledgerEntryReverse := models.LedgerEntry{
-				TenantID:      payment.TenantID,
-				ClientID:      transaction.ClientID,
-				BranchID:      payment.BranchID,
-				TransactionID: &transaction.ID,
-				Type:          models.LedgerTypeWithdrawal,
-				Currency:      oldCurrency,
-				Amount:        oldAmount.Neg(),
-				Description:   fmt.Sprintf("Reversal (Currency Change) for Payment #%d: %s", payment.ID, reason),
-				CreatedBy:     userID,
-				CreatedAt:     time.Now(),
-			}
-			if err := tx.Create(&ledgerEntryReverse).Error; err != nil {
-				return err
-			}
+	s.publish(event)
+	return nil
+}
 
-			if oldPaymentMethod == models.PaymentMethodCash {
-				// Decrease Cash for old currency
-				err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, oldCurrency, -oldAmount.Float64(), fmt.Sprintf("Currency change reversal for Payment #%d: %s", payment.ID, reason), userID)
-				if err != nil {
-					return fmt.Errorf("failed to update old currency cash balance: %w", err)
-				}
-			}
+// paymentPosition is the amount, currency and method a payment is booked under
+type paymentPosition struct {
+	Amount   models.Decimal
+	Currency string
+	Method   string
+}
 
-			// Add New
-			ledgerEntryNew := models.LedgerEntry{
-				TenantID:      payment.TenantID,
-				ClientID:      transaction.ClientID,
-				BranchID:      payment.BranchID,
-				TransactionID: &transaction.ID,
-				Type:          models.LedgerTypeDeposit,
-				Currency:      payment.Currency,
-				Amount:        payment.Amount,
-				Description:   fmt.Sprintf("New Entry (Currency Change) for Payment #%d: %s", payment.ID, reason),
-				ExchangeRate:  &payment.ExchangeRate,
-				CreatedBy:     userID,
-				CreatedAt:     time.Now(),
-			}
-			if err := tx.Create(&ledgerEntryNew).Error; err != nil {
-				return err
-			}
+// netDeltas returns the per-currency change from removing `from` and adding `to`, omitting currencies that net to zero
+func netDeltas(from, to paymentPosition, fromCounts, toCounts bool) map[string]models.Decimal {
+	deltas := make(map[string]models.Decimal)
+	if fromCounts {
+		deltas[from.Currency] = from.Amount.Neg()
+	}
+	if toCounts {
+		deltas[to.Currency] = deltas[to.Currency].Add(to.Amount) // zero value is 0 when the currency is new
+	}
+	for currency, delta := range deltas {
+		if delta.IsZero() {
+			delete(deltas, currency)
+		}
+	}
+	return deltas
+}
 
-			if payment.PaymentMethod == models.PaymentMethodCash {
-				// Increase Cash for new currency
-				err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, payment.Amount.Float64(), fmt.Sprintf("Currency change credit for Payment #%d: %s", payment.ID, reason), userID)
-				if err != nil {
-					return fmt.Errorf("failed to update new currency cash balance: %w", err)
-				}
-			}
+// paymentLedgerDeltas returns the net client ledger change per currency when a payment is edited
+func paymentLedgerDeltas(from, to paymentPosition) map[string]models.Decimal {
+	return netDeltas(from, to, true, true)
+}
+
+// paymentCashDeltas returns the net cash drawer change per currency when a payment is edited.
+// Only cash payments move the drawer, so every method/currency combination reduces to
+// "take the old amount out if it was cash, put the new amount in if it is cash".
+func paymentCashDeltas(from, to paymentPosition) map[string]models.Decimal {
+	return netDeltas(from, to, from.Method == models.PaymentMethodCash, to.Method == models.PaymentMethodCash)
+}
+
+// sortedCurrencies returns the keys of a delta map in a stable order
+func sortedCurrencies(deltas map[string]models.Decimal) []string {
+	currencies := make([]string, 0, len(deltas))
+	for currency := range deltas {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// postPaymentAdjustment records the ledger entries and cash movements for an edited payment
+func (s *PaymentService) postPaymentAdjustment(tx *gorm.DB, payment *models.Payment, transaction *models.Transaction, from, to paymentPosition, userID uint, reason string) error {
+	description := fmt.Sprintf("Adjustment for Payment #%d (Update): %s", payment.ID, reason)
+	if from.Method != to.Method || from.Currency != to.Currency {
+		description = fmt.Sprintf("Adjustment for Payment #%d (%s %s -> %s %s): %s",
+			payment.ID, from.Method, from.Currency, to.Method, to.Currency, reason)
+	}
+
+	ledgerDeltas := paymentLedgerDeltas(from, to)
+	for _, currency := range sortedCurrencies(ledgerDeltas) {
+		delta := ledgerDeltas[currency]
+		ledgerType := models.LedgerTypeDeposit
+		if delta.IsNegative() {
+			ledgerType = models.LedgerTypeWithdrawal // Correction (Debit)
 		}
 
-		return nil
-	})
+		ledgerEntry := models.LedgerEntry{
+			TenantID:      payment.TenantID,
+			ClientID:      transaction.ClientID,
+			BranchID:      payment.BranchID,
+			TransactionID: &transaction.ID,
+			Type:          ledgerType,
+			Currency:      currency,
+			Amount:        delta,
+			Description:   description,
+			CreatedBy:     userID,
+			CreatedAt:     time.Now(),
+		}
+		if currency == payment.Currency {
+			ledgerEntry.ExchangeRate = &payment.ExchangeRate
+		}
+		if err := tx.Create(&ledgerEntry).Error; err != nil {
+			return fmt.Errorf("failed to create ledger adjustment: %w", err)
+		}
+	}
+
+	cashDeltas := paymentCashDeltas(from, to)
+	for _, currency := range sortedCurrencies(cashDeltas) {
+		err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, currency, cashDeltas[currency].Float64(), description, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update %s cash balance: %w", currency, err)
+		}
+	}
+
+	return nil
 }
 
 // DeletePayment removes a payment and recalculates transaction totals
 func (s *PaymentService) DeletePayment(paymentID uint, tenantID uint, userID uint) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var event DomainEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Load payment
 		var payment models.Payment
 		if err := tx.Where("id = ? AND tenant_id = ?", paymentID, tenantID).First(&payment).Error; err != nil {
 			return fmt.Errorf("payment not found: %w", err)
 		}
+		if err := ensureDayOpen(tx, payment.TenantID, payment.BranchID, payment.PaidAt); err != nil {
+			return err
+		}
 
 		// 2. Load transaction with FOR UPDATE lock to prevent race conditions
 		var transaction models.Transaction
@@ -402,9 +628,9 @@ func (s *PaymentService) DeletePayment(paymentID uint, tenantID uint, userID uin
 			return fmt.Errorf("transaction not found: %w", err)
 		}
 
-		// 3. Update transaction totals
-		// transaction.TotalPaid -= payment.AmountInBase
-		transaction.TotalPaid = transaction.TotalPaid.Sub(payment.AmountInBase)
+		// 3. Update transaction totals (refunded portions were already reversed)
+		reversed := payment.RefundableAmount()
+		transaction.TotalPaid = transaction.TotalPaid.Sub(reversed.Mul(payment.ExchangeRate))
 		// transaction.RemainingBalance = transaction.TotalReceived - transaction.TotalPaid
 		transaction.RemainingBalance = transaction.TotalReceived.Sub(transaction.TotalPaid)
 
@@ -426,6 +652,9 @@ func (s *PaymentService) DeletePayment(paymentID uint, tenantID uint, userID uin
 		if err := tx.Save(&transaction).Error; err != nil {
 			return fmt.Errorf("failed to update transaction: %w", err)
 		}
+		if err := s.syncPaymentPlan(tx, &transaction, time.Now()); err != nil {
+			return err
+		}
 
 		// 7. Create Ledger Entry (Debit Client - Reversal)
 		ledgerEntry := models.LedgerEntry{
@@ -435,7 +664,7 @@ func (s *PaymentService) DeletePayment(paymentID uint, tenantID uint, userID uin
 			TransactionID: &transaction.ID,
 			Type:          models.LedgerTypeWithdrawal,
 			Currency:      payment.Currency,
-			Amount:        payment.Amount.Neg(),
+			Amount:        reversed.Neg(),
 			Description:   fmt.Sprintf("Reversal (Deletion) of Payment #%d", payment.ID),
 			ExchangeRate:  &payment.ExchangeRate,
 			CreatedBy:     userID,
@@ -447,19 +676,27 @@ func (s *PaymentService) DeletePayment(paymentID uint, tenantID uint, userID uin
 
 		// 8. Update Cash Balance (Decrease Cash - Reversal)
 		if payment.PaymentMethod == models.PaymentMethodCash {
-			err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -payment.Amount.Float64(), fmt.Sprintf("Reversal (Deletion) of Payment #%d", payment.ID), userID)
+			err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -reversed.Float64(), fmt.Sprintf("Reversal (Deletion) of Payment #%d", payment.ID), userID)
 			if err != nil {
 				return fmt.Errorf("failed to update cash balance reversal: %w", err)
 			}
 		}
 
+		event = paymentEvent(EventPaymentDeleted, &payment, &transaction)
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(event)
+	return nil
 }
 
 // CancelPayment marks a payment as cancelled
 func (s *PaymentService) CancelPayment(paymentID uint, tenantID uint, userID uint, reason string) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var event DomainEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Load payment
 		var payment models.Payment
 		if err := tx.Where("id = ? AND tenant_id = ?", paymentID, tenantID).First(&payment).Error; err != nil {
@@ -478,90 +715,322 @@ func (s *PaymentService) CancelPayment(paymentID uint, tenantID uint, userID uin
 			return fmt.Errorf("transaction not found: %w", err)
 		}
 
-		// 3. Update payment
+		// 3. Cancel the payment and reverse its effects
+		var err error
+		event, err = s.cancelPaymentWithTx(tx, &payment, &transaction, userID, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(event)
+	return nil
+}
+
+// cancelPaymentWithTx cancels a locked payment inside tx, taking it off the transaction's totals and
+// reversing its ledger and cash drawer effects. The returned event is published once tx commits.
+func (s *PaymentService) cancelPaymentWithTx(tx *gorm.DB, payment *models.Payment, transaction *models.Transaction, userID uint, reason string) (DomainEvent, error) {
+	// 1. Update payment
+	now := time.Now()
+	if err := ensureDayOpen(tx, payment.TenantID, payment.BranchID, now); err != nil {
+		return DomainEvent{}, err
+	}
+	payment.Status = models.PaymentStatusCancelled
+	payment.CancelledAt = &now
+	payment.CancelledBy = &userID
+	payment.CancelReason = &reason
+
+	// 2. Update transaction totals (subtract cancelled payment, less anything already refunded)
+	reversed := payment.RefundableAmount()
+	transaction.TotalPaid = transaction.TotalPaid.Sub(reversed.Mul(payment.ExchangeRate))
+	// transaction.RemainingBalance = transaction.TotalReceived - transaction.TotalPaid
+	transaction.RemainingBalance = transaction.TotalReceived.Sub(transaction.TotalPaid)
+
+	// 3. Update payment status (using currency-aware tolerance)
+	tolerance := models.NewDecimal(utils.GetPaymentTolerance(transaction.ReceivedCurrency))
+	// if transaction.TotalPaid <= 0 {
+	if transaction.TotalPaid.IsZero() || transaction.TotalPaid.IsNegative() {
+		transaction.PaymentStatus = models.PaymentStatusOpen
+	} else if transaction.RemainingBalance.GreaterThan(tolerance) {
+		transaction.PaymentStatus = models.PaymentStatusPartial
+	}
+
+	// 4. Save both
+	if err := tx.Save(payment).Error; err != nil {
+		return DomainEvent{}, fmt.Errorf("failed to cancel payment: %w", err)
+	}
+	if err := tx.Save(transaction).Error; err != nil {
+		return DomainEvent{}, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	if err := s.syncPaymentPlan(tx, transaction, time.Now()); err != nil {
+		return DomainEvent{}, err
+	}
+
+	// 5. Create Ledger Entry (Debit Client - Reversal)
+	// We are reversing a payment, so we DEBIT the client (increase their debt back)
+	ledgerEntry := models.LedgerEntry{
+		TenantID:      payment.TenantID,
+		ClientID:      transaction.ClientID,
+		BranchID:      payment.BranchID,
+		TransactionID: &transaction.ID,
+		Type:          models.LedgerTypeWithdrawal, // Or a specific REVERSAL type
+		Currency:      payment.Currency,
+		Amount:        reversed.Neg(), // Negative amount to reverse the deposit
+		Description:   fmt.Sprintf("Reversal of Payment #%d for Transaction #%s: %s", payment.ID, transaction.ID, reason),
+		ExchangeRate:  &payment.ExchangeRate,
+		CreatedBy:     userID,
+		CreatedAt:     time.Now(),
+	}
+	if err := tx.Create(&ledgerEntry).Error; err != nil {
+		return DomainEvent{}, fmt.Errorf("failed to create ledger reversal entry: %w", err)
+	}
+
+	// 6. Update Cash Balance (Decrease Cash - Reversal)
+	if payment.PaymentMethod == models.PaymentMethodCash {
+		err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -reversed.Float64(), fmt.Sprintf("Reversal of Payment #%d: %s", payment.ID, reason), userID)
+		if err != nil {
+			return DomainEvent{}, fmt.Errorf("failed to update cash balance reversal: %w", err)
+		}
+	}
+
+	return paymentEvent(EventPaymentCancelled, payment, transaction), nil
+}
+
+// VoidTransaction cancels a transaction and every payment on it that is not already cancelled, in one
+// database transaction, so the client's ledger and the cash drawers net back to where they were before
+// the payments. Unlike a plain cancel, which only changes the status, nothing stays collected. A
+// transaction whose funds were disbursed or settled cannot be voided.
+func (s *PaymentService) VoidTransaction(transactionID string, tenantID uint, userID uint, reason string) (*models.Transaction, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to void a transaction")
+	}
+
+	var transaction models.Transaction
+	var events []DomainEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Load transaction with FOR UPDATE lock so no payment lands while it is voided
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", transactionID, tenantID).
+			First(&transaction).Error; err != nil {
+			return fmt.Errorf("transaction not found: %w", err)
+		}
+		if transaction.Status == models.StatusCancelled {
+			return errors.New("transaction is already cancelled")
+		}
+
+		// 2. Refuse once the money has left: a completed disbursement, or a settled remittance paid through it
+		var disbursed int64
+		if err := tx.Model(&models.Disbursement{}).
+			Where("tenant_id = ? AND transaction_id = ? AND status IN ?", tenantID, transaction.ID,
+				[]string{models.DisbursementStatusDisbursed, models.LegacyStatusPickedUp}).
+			Count(&disbursed).Error; err != nil {
+			return fmt.Errorf("failed to check disbursements: %w", err)
+		}
+		var settlements int64
+		if err := tx.Model(&models.RemittanceSettlement{}).
+			Joins("JOIN outgoing_remittances ON outgoing_remittances.id = remittance_settlements.outgoing_remittance_id").
+			Where("remittance_settlements.tenant_id = ? AND outgoing_remittances.transaction_id = ?", tenantID, transaction.ID).
+			Count(&settlements).Error; err != nil {
+			return fmt.Errorf("failed to check settlements: %w", err)
+		}
+		if disbursed > 0 || settlements > 0 {
+			return ErrTransactionSettled
+		}
+
+		// 3. Cancel every live payment, reversing its ledger and cash effects
+		var payments []models.Payment
+		if err := tx.Where("transaction_id = ? AND tenant_id = ? AND status <> ?", transaction.ID, tenantID, models.PaymentStatusCancelled).
+			Order("id ASC").
+			Find(&payments).Error; err != nil {
+			return fmt.Errorf("failed to load payments: %w", err)
+		}
+		for i := range payments {
+			event, err := s.cancelPaymentWithTx(tx, &payments[i], &transaction, userID, "Transaction voided: "+reason)
+			if err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+
+		// 4. Cancel the transaction itself
 		now := time.Now()
-		payment.Status = models.PaymentStatusCancelled
-		payment.CancelledAt = &now
-		payment.CancelledBy = &userID
-		payment.CancelReason = &reason
-
-		// 4. Update transaction totals (subtract cancelled payment)
-		// transaction.TotalPaid -= payment.AmountInBase
-		transaction.TotalPaid = transaction.TotalPaid.Sub(payment.AmountInBase)
-		// transaction.RemainingBalance = transaction.TotalReceived - transaction.TotalPaid
+		transaction.Status = models.StatusCancelled
+		transaction.CancellationReason = &reason
+		transaction.CancelledAt = &now
+		transaction.CancelledBy = &userID
+		if err := tx.Save(&transaction).Error; err != nil {
+			return fmt.Errorf("failed to void transaction: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events...)
+	s.publish(DomainEvent{
+		Name:     EventTransactionVoided,
+		TenantID: transaction.TenantID,
+		Data: map[string]interface{}{
+			"transactionId":     transaction.ID,
+			"tenantId":          transaction.TenantID,
+			"branchId":          transaction.BranchID,
+			"status":            transaction.Status,
+			"cancelledPayments": len(events),
+		},
+		OccurredAt: time.Now(),
+	})
+	return &transaction, nil
+}
+
+// RefundPayment issues a partial refund against a completed payment, reversing the
+// refunded portion on the ledger, the cash drawer and the transaction totals
+func (s *PaymentService) RefundPayment(paymentID uint, tenantID uint, amount models.Decimal, reason string, userID uint) (*models.Payment, error) {
+	if !amount.IsPositive() {
+		return nil, errors.New("refund amount must be positive")
+	}
+
+	var payment models.Payment
+	var event DomainEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Load payment with lock so concurrent refunds accumulate correctly
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", paymentID, tenantID).
+			First(&payment).Error; err != nil {
+			return fmt.Errorf("payment not found: %w", err)
+		}
+
+		if payment.Status != models.PaymentStatusCompleted {
+			return fmt.Errorf("cannot refund a payment with status %s", payment.Status)
+		}
+		if err := ensureDayOpen(tx, payment.TenantID, payment.BranchID, time.Now()); err != nil {
+			return err
+		}
+
+		// 2. Validate against the remaining refundable balance
+		refundable := payment.RefundableAmount()
+		if amount.GreaterThan(refundable) {
+			return fmt.Errorf("refund exceeds refundable balance. Refundable: %s %s", refundable.String(), payment.Currency)
+		}
+
+		// 3. Load transaction with FOR UPDATE lock to prevent race conditions
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", payment.TransactionID, tenantID).
+			First(&transaction).Error; err != nil {
+			return fmt.Errorf("transaction not found: %w", err)
+		}
+
+		// 4. Record the refund on the payment
+		payment.RefundedAmount = payment.RefundedAmount.Add(amount)
+		if err := tx.Model(&payment).Update("refunded_amount", payment.RefundedAmount).Error; err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+
+		// 5. Update transaction totals
+		transaction.TotalPaid = transaction.TotalPaid.Sub(amount.Mul(payment.ExchangeRate))
 		transaction.RemainingBalance = transaction.TotalReceived.Sub(transaction.TotalPaid)
 
-		// 5. Update payment status (using currency-aware tolerance)
 		tolerance := models.NewDecimal(utils.GetPaymentTolerance(transaction.ReceivedCurrency))
-		// if transaction.TotalPaid <= 0 {
 		if transaction.TotalPaid.IsZero() || transaction.TotalPaid.IsNegative() {
 			transaction.PaymentStatus = models.PaymentStatusOpen
 		} else if transaction.RemainingBalance.GreaterThan(tolerance) {
 			transaction.PaymentStatus = models.PaymentStatusPartial
 		}
 
-		// 6. Save both
-		if err := tx.Save(&payment).Error; err != nil {
-			return fmt.Errorf("failed to cancel payment: %w", err)
-		}
 		if err := tx.Save(&transaction).Error; err != nil {
 			return fmt.Errorf("failed to update transaction: %w", err)
 		}
+		if err := s.syncPaymentPlan(tx, &transaction, time.Now()); err != nil {
+			return err
+		}
 
-		// 7. Create Ledger Entry (Debit Client - Reversal)
-		// We are reversing a payment, so we DEBIT the client (increase their debt back)
+		// 6. Create Ledger Entry (Debit Client - Refund)
+		description := fmt.Sprintf("Refund of %s %s on Payment #%d", amount.String(), payment.Currency, payment.ID)
+		if reason != "" {
+			description = fmt.Sprintf("%s: %s", description, reason)
+		}
 		ledgerEntry := models.LedgerEntry{
 			TenantID:      payment.TenantID,
 			ClientID:      transaction.ClientID,
 			BranchID:      payment.BranchID,
 			TransactionID: &transaction.ID,
-			Type:          models.LedgerTypeWithdrawal, // Or a specific REVERSAL type
+			Type:          models.LedgerTypeWithdrawal,
 			Currency:      payment.Currency,
-			Amount:        payment.Amount.Neg(), // Negative amount to reverse the deposit
-			Description:   fmt.Sprintf("Reversal of Payment #%d for Transaction #%s: %s", payment.ID, transaction.ID, reason),
+			Amount:        amount.Neg(),
+			Description:   description,
 			ExchangeRate:  &payment.ExchangeRate,
 			CreatedBy:     userID,
 			CreatedAt:     time.Now(),
 		}
 		if err := tx.Create(&ledgerEntry).Error; err != nil {
-			return fmt.Errorf("failed to create ledger reversal entry: %w", err)
+			return fmt.Errorf("failed to create ledger refund entry: %w", err)
 		}
 
-		// 8. Update Cash Balance (Decrease Cash - Reversal)
+		// 7. Update Cash Balance (Decrease Cash - Refund paid out)
 		if payment.PaymentMethod == models.PaymentMethodCash {
-			err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -payment.Amount.Float64(), fmt.Sprintf("Reversal of Payment #%d: %s", payment.ID, reason), userID)
+			err := s.cashBalanceService.UpdateCashBalance(tx, payment.TenantID, payment.BranchID, payment.Currency, -amount.Float64(), description, userID)
 			if err != nil {
-				return fmt.Errorf("failed to update cash balance reversal: %w", err)
+				return fmt.Errorf("failed to update cash balance for refund: %w", err)
 			}
 		}
 
+		event = paymentEvent(EventPaymentRefunded, &payment, &transaction)
+		event.Data["refundAmount"] = amount.Float64()
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(event)
+	return &payment, nil
+}
+
+// CompleteTransactionOptions controls how CompleteTransaction treats an outstanding balance
+type CompleteTransactionOptions struct {
+	WriteOff bool   // Write the residual balance off instead of rejecting completion
+	Reason   string // Why the residual is being written off
+	UserID   uint   // User authorizing the completion
 }
 
-// CompleteTransaction marks a transaction as completed (manually)
-func (s *PaymentService) CompleteTransaction(transactionID string, tenantID uint) error {
+// CompleteTransaction marks a transaction as completed (manually).
+// With opts.WriteOff, a residual up to the tenant's write-off cap is posted to the ledger and cleared.
+func (s *PaymentService) CompleteTransaction(transactionID string, tenantID uint, opts CompleteTransactionOptions) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		var transaction models.Transaction
-		if err := tx.Where("id = ? AND tenant_id = ?", transactionID, tenantID).
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", transactionID, tenantID).
 			First(&transaction).Error; err != nil {
 			return fmt.Errorf("transaction not found: %w", err)
 		}
 
-		// Allow completion if remaining is small (using currency-aware tolerance)
-		tolerance := models.NewDecimal(utils.GetPaymentTolerance(transaction.ReceivedCurrency))
-		// Also allow 1% tolerance for larger transactions
-		// percentTolerance := transaction.TotalReceived * 0.01
-		percentTolerance := transaction.TotalReceived.Mul(models.NewDecimal(0.01))
+		if opts.WriteOff && transaction.RemainingBalance.IsPositive() {
+			if err := s.writeOffResidual(tx, &transaction, opts); err != nil {
+				return err
+			}
+		} else {
+			// Allow completion if remaining is small (using currency-aware tolerance)
+			tolerance := models.NewDecimal(utils.GetPaymentTolerance(transaction.ReceivedCurrency))
+			// Also allow 1% tolerance for larger transactions
+			// percentTolerance := transaction.TotalReceived * 0.01
+			percentTolerance := transaction.TotalReceived.Mul(models.NewDecimal(0.01))
+
+			if percentTolerance.GreaterThan(tolerance) {
+				tolerance = percentTolerance
+			}
 
-		if percentTolerance.GreaterThan(tolerance) {
-			tolerance = percentTolerance
+			// if transaction.RemainingBalance > tolerance {
+			if transaction.RemainingBalance.GreaterThan(tolerance) {
+				return fmt.Errorf("cannot complete transaction with remaining balance: %s %s",
+					transaction.RemainingBalance.String(), transaction.ReceivedCurrency)
+			}
 		}
 
-		// if transaction.RemainingBalance > tolerance {
-		if transaction.RemainingBalance.GreaterThan(tolerance) {
-			return fmt.Errorf("cannot complete transaction with remaining balance: %s %s",
-				transaction.RemainingBalance.String(), transaction.ReceivedCurrency)
+		if err := s.ensureCompletionCompliance(tx, &transaction); err != nil {
+			return err
 		}
 
 		transaction.PaymentStatus = models.PaymentStatusFullyPaid
@@ -574,3 +1043,289 @@ func (s *PaymentService) CompleteTransaction(transactionID string, tenantID uint
 		return nil
 	})
 }
+
+// ensureCompletionCompliance requires approved KYC and a supporting document before completing a
+// transaction at or above the tenant's respective thresholds. Both are off unless a tenant sets them, and a
+// transaction that cannot be valued in the base currency for want of a rate is not held back by them.
+func (s *PaymentService) ensureCompletionCompliance(tx *gorm.DB, transaction *models.Transaction) error {
+	settings, err := NewTenantSettingsService(tx).GetSettings(transaction.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if !settings.FullKYCThreshold.IsPositive() && !settings.DocumentRequiredThreshold.IsPositive() {
+		return nil
+	}
+
+	value, err := completionValue(tx, transaction, settings.BaseCurrency)
+	if err != nil {
+		log.Printf("Skipping completion compliance checks for transaction %s: %v", transaction.ID, err)
+		return nil
+	}
+
+	if settings.DocumentRequiredThreshold.IsPositive() && !value.LessThan(settings.DocumentRequiredThreshold) {
+		attached, err := NewTransactionAttachmentService(tx).HasAttachment(transaction.TenantID, transaction.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check transaction attachments: %w", err)
+		}
+		if !attached {
+			return fmt.Errorf("%w: %s %s is at or above the %s %s threshold", ErrSupportingDocumentRequired,
+				value.Round(2).String(), settings.BaseCurrency, settings.DocumentRequiredThreshold.String(), settings.BaseCurrency)
+		}
+	}
+
+	if !settings.FullKYCThreshold.IsPositive() || value.LessThan(settings.FullKYCThreshold) {
+		return nil
+	}
+	return NewComplianceService(tx).EnsureClientComplianceApproved(transaction.TenantID, transaction.ClientID)
+}
+
+// completionValue values a transaction in the base currency for the completion thresholds: the amount
+// received, or the amount sent when nothing is to be received. It fails when there is no rate to value it with.
+func completionValue(tx *gorm.DB, transaction *models.Transaction, baseCurrency string) (models.Decimal, error) {
+	amount, currency := transaction.TotalReceived, transaction.ReceivedCurrency
+	if !amount.IsPositive() || currency == "" {
+		amount, currency = transaction.SendAmount, transaction.SendCurrency
+	}
+	if currency == baseCurrency {
+		return amount, nil
+	}
+	rate, err := NewExchangeRateService(tx).GetHistoricalRate(transaction.TenantID, currency, baseCurrency, time.Now())
+	if err != nil {
+		return models.Zero(), err
+	}
+	return amount.Mul(rate), nil
+}
+
+// writeOffResidual posts the remaining balance as a write-off if it is within the tenant's cap. A cap set for
+// the residual's own currency is compared directly; otherwise the residual is valued in the base currency.
+func (s *PaymentService) writeOffResidual(tx *gorm.DB, transaction *models.Transaction, opts CompleteTransactionOptions) error {
+	reason := strings.TrimSpace(opts.Reason)
+	if reason == "" {
+		return errors.New("a reason is required to write off the remaining balance")
+	}
+
+	settings, err := NewTenantSettingsService(tx).GetSettings(transaction.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+
+	residual := transaction.RemainingBalance
+	currency := transaction.ReceivedCurrency
+	if currencyCap, ok := settings.WriteOffCurrencyCaps[currency]; ok {
+		limit := models.NewDecimal(currencyCap)
+		if !limit.IsPositive() {
+			return fmt.Errorf("write-offs are not enabled for %s", currency)
+		}
+		if residual.GreaterThan(limit) {
+			return fmt.Errorf("remaining balance %s %s exceeds the write-off cap of %s %s",
+				residual.String(), currency, limit.String(), currency)
+		}
+	} else {
+		if !settings.WriteOffCap.IsPositive() {
+			return errors.New("write-offs are not enabled for this tenant")
+		}
+		residualInBase := residual
+		if currency != settings.BaseCurrency {
+			rate, err := NewExchangeRateService(tx).GetHistoricalRate(transaction.TenantID, currency, settings.BaseCurrency, time.Now())
+			if err != nil {
+				return fmt.Errorf("cannot value write-off in %s: %w", settings.BaseCurrency, err)
+			}
+			residualInBase = residual.Mul(rate)
+		}
+		if residualInBase.GreaterThan(settings.WriteOffCap) {
+			return fmt.Errorf("remaining balance %s %s (%s %s) exceeds the write-off cap of %s %s",
+				residual.String(), currency,
+				residualInBase.Round(2).String(), settings.BaseCurrency,
+				settings.WriteOffCap.String(), settings.BaseCurrency)
+		}
+	}
+
+	ledgerEntry := models.LedgerEntry{
+		TenantID:      transaction.TenantID,
+		ClientID:      transaction.ClientID,
+		BranchID:      transaction.BranchID,
+		TransactionID: &transaction.ID,
+		Type:          models.LedgerTypeWithdrawal,
+		Currency:      transaction.ReceivedCurrency,
+		Amount:        residual.Neg(),
+		Description:   fmt.Sprintf("Write-off of remaining balance for Transaction %s: %s", transaction.ID, reason),
+		CreatedBy:     opts.UserID,
+		CreatedAt:     time.Now(),
+	}
+	if err := tx.Create(&ledgerEntry).Error; err != nil {
+		return fmt.Errorf("failed to create write-off ledger entry: %w", err)
+	}
+
+	now := time.Now()
+	userID := opts.UserID
+	transaction.WrittenOffAmount = residual
+	transaction.WriteOffReason = &reason
+	transaction.WrittenOffAt = &now
+	transaction.WrittenOffBy = &userID
+	transaction.RemainingBalance = models.Zero()
+
+	return nil
+}
+
+// PaymentInstallmentInput describes one installment of a new payment plan
+type PaymentInstallmentInput struct {
+	DueDate        time.Time      `json:"dueDate"`
+	ExpectedAmount models.Decimal `json:"expectedAmount"`
+	Currency       string         `json:"currency"` // Defaults to the transaction's received currency
+}
+
+// CreatePaymentPlan attaches an installment schedule covering the transaction's remaining balance
+func (s *PaymentService) CreatePaymentPlan(tenantID uint, transactionID string, installments []PaymentInstallmentInput, notes string, userID uint) (*models.PaymentPlan, error) {
+	if len(installments) == 0 {
+		return nil, errors.New("a payment plan needs at least one installment")
+	}
+
+	var plan models.PaymentPlan
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", transactionID, tenantID).
+			First(&transaction).Error; err != nil {
+			return fmt.Errorf("transaction not found: %w", err)
+		}
+		if !transaction.AllowPartialPayment {
+			return errors.New("payment plans require a transaction that allows partial payments")
+		}
+		if transaction.Status == models.StatusCancelled {
+			return errors.New("cannot create a payment plan for a cancelled transaction")
+		}
+		if !transaction.RemainingBalance.IsPositive() {
+			return errors.New("transaction has no remaining balance to schedule")
+		}
+
+		var existing int64
+		tx.Model(&models.PaymentPlan{}).Where("transaction_id = ?", transaction.ID).Count(&existing)
+		if existing > 0 {
+			return errors.New("transaction already has a payment plan")
+		}
+
+		sorted := make([]PaymentInstallmentInput, len(installments))
+		copy(sorted, installments)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DueDate.Before(sorted[j].DueDate) })
+
+		total := models.Zero()
+		for i, installment := range sorted {
+			if installment.DueDate.IsZero() {
+				return fmt.Errorf("installment %d: due date is required", i+1)
+			}
+			if !installment.ExpectedAmount.IsPositive() {
+				return fmt.Errorf("installment %d: expected amount must be positive", i+1)
+			}
+			if installment.Currency != "" && !strings.EqualFold(installment.Currency, transaction.ReceivedCurrency) {
+				return fmt.Errorf("installment %d: currency must be %s", i+1, transaction.ReceivedCurrency)
+			}
+			total = total.Add(installment.ExpectedAmount)
+		}
+
+		tolerance := models.NewDecimal(utils.GetPaymentTolerance(transaction.ReceivedCurrency))
+		if total.Sub(transaction.RemainingBalance).Abs().GreaterThan(tolerance) {
+			return fmt.Errorf("installments total %s %s but the remaining balance is %s %s",
+				total.String(), transaction.ReceivedCurrency, transaction.RemainingBalance.String(), transaction.ReceivedCurrency)
+		}
+
+		plan = models.PaymentPlan{
+			TenantID:       tenantID,
+			TransactionID:  transaction.ID,
+			Currency:       transaction.ReceivedCurrency,
+			PaidBeforePlan: transaction.TotalPaid,
+			CreatedBy:      userID,
+		}
+		if notes != "" {
+			plan.Notes = &notes
+		}
+		for i, installment := range sorted {
+			plan.Installments = append(plan.Installments, models.PaymentInstallment{
+				TenantID:       tenantID,
+				TransactionID:  transaction.ID,
+				Sequence:       i + 1,
+				DueDate:        installment.DueDate,
+				ExpectedAmount: installment.ExpectedAmount,
+				Currency:       transaction.ReceivedCurrency,
+				PaidAmount:     models.Zero(),
+				Status:         models.InstallmentStatusPending,
+			})
+		}
+		if err := tx.Create(&plan).Error; err != nil {
+			return fmt.Errorf("failed to create payment plan: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// GetPaymentPlan returns the transaction's payment plan with installments in due order
+func (s *PaymentService) GetPaymentPlan(tenantID uint, transactionID string) (*models.PaymentPlan, error) {
+	var plan models.PaymentPlan
+	if err := s.db.Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("transaction_id = ? AND tenant_id = ?", transactionID, tenantID).First(&plan).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// syncPaymentPlan allocates what has been paid since the plan was created to its installments,
+// earliest first. It is re-run whenever TotalPaid changes so edits, cancellations and refunds
+// reopen installments the same way payments settle them.
+func (s *PaymentService) syncPaymentPlan(tx *gorm.DB, transaction *models.Transaction, paidAt time.Time) error {
+	var plan models.PaymentPlan
+	if err := tx.Where("transaction_id = ? AND tenant_id = ?", transaction.ID, transaction.TenantID).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load payment plan: %w", err)
+	}
+
+	var installments []models.PaymentInstallment
+	if err := tx.Where("plan_id = ?", plan.ID).Order("sequence ASC").Find(&installments).Error; err != nil {
+		return fmt.Errorf("failed to load installments: %w", err)
+	}
+
+	available := transaction.TotalPaid.Sub(plan.PaidBeforePlan)
+	for i := range installments {
+		installment := &installments[i]
+
+		paid := models.Zero()
+		if available.IsPositive() {
+			paid = installment.ExpectedAmount
+			if available.LessThan(paid) {
+				paid = available
+			}
+			available = available.Sub(paid)
+		}
+
+		status := models.InstallmentStatusPending
+		var settledAt *time.Time
+		switch {
+		case !paid.LessThan(installment.ExpectedAmount):
+			status = models.InstallmentStatusPaid
+			settledAt = installment.PaidAt
+			if settledAt == nil {
+				settledAt = &paidAt
+			}
+		case paid.IsPositive():
+			status = models.InstallmentStatusPartial
+		}
+
+		if status == installment.Status && paid.Sub(installment.PaidAmount).IsZero() {
+			continue
+		}
+		installment.PaidAmount = paid
+		installment.Status = status
+		installment.PaidAt = settledAt
+		if err := tx.Save(installment).Error; err != nil {
+			return fmt.Errorf("failed to update installment: %w", err)
+		}
+	}
+
+	return nil
+}
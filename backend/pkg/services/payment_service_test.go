@@ -0,0 +1,980 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestCreatePayment_ResolvesRateFromSource verifies the service looks up rates itself and refuses to guess
+func TestCreatePayment_ResolvesRateFromSource(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ExchangeRate{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	paidAt := time.Now()
+	db.Create(&models.ExchangeRate{TenantID: tenant.ID, BaseCurrency: "USD", TargetCurrency: "CAD", Rate: models.NewDecimal(1.30), Source: models.RateSourceManual, CreatedAt: paidAt.Add(-48 * time.Hour)})
+	db.Create(&models.ExchangeRate{TenantID: tenant.ID, BaseCurrency: "USD", TargetCurrency: "CAD", Rate: models.NewDecimal(1.35), Source: models.RateSourceManual, CreatedAt: paidAt.Add(-time.Hour)})
+	db.Create(&models.ExchangeRate{TenantID: tenant.ID, BaseCurrency: "USD", TargetCurrency: "CAD", Rate: models.NewDecimal(1.50), Source: models.RateSourceManual, CreatedAt: paidAt.Add(time.Hour)})
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	t.Run("StoredRateAtPaidAt", func(t *testing.T) {
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(100),
+			Currency:      "USD",
+			RateSource:    models.PaymentRateSourceStored,
+			PaymentMethod: models.PaymentMethodCash,
+			PaidAt:        paidAt,
+		}
+		if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+			t.Fatalf("Expected stored rate to resolve, got: %v", err)
+		}
+
+		var saved models.Payment
+		db.First(&saved, payment.ID)
+		if saved.ExchangeRate.Float64() != 1.35 {
+			t.Errorf("Expected rate 1.35 in effect at PaidAt, got %v", saved.ExchangeRate)
+		}
+		if saved.RateSource != models.PaymentRateSourceStored {
+			t.Errorf("Expected rate source %q, got %q", models.PaymentRateSourceStored, saved.RateSource)
+		}
+		if saved.AmountInBase.Float64() != 135 {
+			t.Errorf("Expected 135 CAD in base, got %v", saved.AmountInBase)
+		}
+	})
+
+	t.Run("MissingStoredRateRejected", func(t *testing.T) {
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-002",
+			Amount:        models.NewDecimal(100),
+			Currency:      "EUR",
+			RateSource:    models.PaymentRateSourceStored,
+			PaymentMethod: models.PaymentMethodCash,
+		}
+		err := paymentService.CreatePayment(payment, user.ID)
+		if !errors.Is(err, ErrPaymentRateUnavailable) {
+			t.Fatalf("Expected ErrPaymentRateUnavailable, got: %v", err)
+		}
+
+		var count int64
+		db.Model(&models.Payment{}).Where("transaction_id = ?", "txn-batch-002").Count(&count)
+		if count != 0 {
+			t.Errorf("Expected no payment to be saved, got %d", count)
+		}
+	})
+
+	t.Run("ManualRateRequired", func(t *testing.T) {
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-002",
+			Amount:        models.NewDecimal(100),
+			Currency:      "CAD",
+			PaymentMethod: models.PaymentMethodCash,
+		}
+		err := paymentService.CreatePayment(payment, user.ID)
+		if !errors.Is(err, ErrPaymentRateUnavailable) {
+			t.Fatalf("Expected missing manual rate to be rejected, got: %v", err)
+		}
+	})
+}
+
+// TestRefundPayment_PartialRefunds verifies refunds accumulate, reverse totals and cannot exceed the payment
+func TestRefundPayment_PartialRefunds(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	payment := &models.Payment{
+		TenantID:      tenant.ID,
+		TransactionID: "txn-batch-001",
+		Amount:        models.NewDecimal(400),
+		Currency:      "CAD",
+		ExchangeRate:  models.NewDecimal(1),
+		PaymentMethod: models.PaymentMethodCash,
+	}
+	if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+
+	t.Run("RefundMoreThanPayment", func(t *testing.T) {
+		if _, err := paymentService.RefundPayment(payment.ID, tenant.ID, models.NewDecimal(450), "Overcharge", user.ID); err == nil {
+			t.Fatal("Expected refund larger than the payment to fail")
+		}
+	})
+
+	t.Run("SequentialPartialRefunds", func(t *testing.T) {
+		if _, err := paymentService.RefundPayment(payment.ID, tenant.ID, models.NewDecimal(100), "Overcharge", user.ID); err != nil {
+			t.Fatalf("First refund failed: %v", err)
+		}
+		refunded, err := paymentService.RefundPayment(payment.ID, tenant.ID, models.NewDecimal(150), "Fee waived", user.ID)
+		if err != nil {
+			t.Fatalf("Second refund failed: %v", err)
+		}
+		if refunded.RefundedAmount.Float64() != 250 {
+			t.Errorf("Expected 250 refunded, got %v", refunded.RefundedAmount)
+		}
+
+		var transaction models.Transaction
+		db.First(&transaction, "id = ?", "txn-batch-001")
+		if transaction.TotalPaid.Float64() != 150 || transaction.RemainingBalance.Float64() != 850 {
+			t.Errorf("Expected paid=150 remaining=850, got paid=%v remaining=%v", transaction.TotalPaid, transaction.RemainingBalance)
+		}
+
+		var refundEntries int64
+		db.Model(&models.LedgerEntry{}).Where("transaction_id = ? AND type = ?", "txn-batch-001", models.LedgerTypeWithdrawal).Count(&refundEntries)
+		if refundEntries != 2 {
+			t.Errorf("Expected 2 withdrawal ledger entries, got %d", refundEntries)
+		}
+
+		var cashOut int64
+		db.Model(&models.CashAdjustment{}).Where("amount < 0").Count(&cashOut)
+		if cashOut != 2 {
+			t.Errorf("Expected 2 cash decreases, got %d", cashOut)
+		}
+
+		// Only 150 left; refunding 200 must not push RefundedAmount past Amount
+		if _, err := paymentService.RefundPayment(payment.ID, tenant.ID, models.NewDecimal(200), "Too much", user.ID); err == nil {
+			t.Error("Expected refund beyond the remaining refundable balance to fail")
+		}
+	})
+
+	t.Run("RefundCancelledPayment", func(t *testing.T) {
+		if err := paymentService.CancelPayment(payment.ID, tenant.ID, user.ID, "Customer walked out"); err != nil {
+			t.Fatalf("Failed to cancel payment: %v", err)
+		}
+
+		var transaction models.Transaction
+		db.First(&transaction, "id = ?", "txn-batch-001")
+		if !transaction.TotalPaid.IsZero() {
+			t.Errorf("Expected cancel to reverse only the unrefunded 150, got TotalPaid=%v", transaction.TotalPaid)
+		}
+
+		if _, err := paymentService.RefundPayment(payment.ID, tenant.ID, models.NewDecimal(50), "After cancel", user.ID); err == nil {
+			t.Error("Expected refund of a cancelled payment to fail")
+		}
+	})
+}
+
+// TestCreatePaymentsBatch_PartialFailures verifies failed items are reported without undoing the rest
+func TestCreatePaymentsBatch_PartialFailures(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	newPayment := func(transactionID string, amount float64) *models.Payment {
+		return &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: transactionID,
+			Amount:        models.NewDecimal(amount),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodBankTransfer,
+			Details:       map[string]interface{}{"referenceId": "WIRE-" + transactionID},
+		}
+	}
+
+	results, err := paymentService.CreatePaymentsBatch([]*models.Payment{
+		newPayment("txn-batch-001", 300),
+		newPayment("txn-batch-002", 600), // exceeds 500 remaining
+		newPayment("txn-batch-001", 400), // same transaction as the first
+		newPayment("txn-missing", 50),
+	}, user.ID)
+	if err != nil {
+		t.Fatalf("Batch should not abort on item failures: %v", err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	expected := []bool{true, false, true, false}
+	for i, want := range expected {
+		if results[i].Success != want {
+			t.Errorf("Item %d: expected success=%v, got %v (%s)", i, want, results[i].Success, results[i].Error)
+		}
+		if !want && results[i].Error == "" {
+			t.Errorf("Item %d: expected a failure reason", i)
+		}
+	}
+
+	var transaction models.Transaction
+	db.First(&transaction, "id = ?", "txn-batch-001")
+	if transaction.TotalPaid.Float64() != 700 || transaction.RemainingBalance.Float64() != 300 {
+		t.Errorf("Expected paid=700 remaining=300, got paid=%v remaining=%v", transaction.TotalPaid, transaction.RemainingBalance)
+	}
+
+	var untouched models.Transaction
+	db.First(&untouched, "id = ?", "txn-batch-002")
+	if !untouched.TotalPaid.IsZero() {
+		t.Errorf("Expected failed payment to leave totals untouched, got %v", untouched.TotalPaid)
+	}
+
+	var count int64
+	db.Model(&models.Payment{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 payments saved, got %d", count)
+	}
+}
+
+// TestCreatePaymentsBatch_DatabaseErrorAbortsBatch verifies a database failure rolls back every payment in the
+// batch instead of being reported as one failed item
+func TestCreatePaymentsBatch_DatabaseErrorAbortsBatch(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+	// Without the cash balance table the cash payment's balance update fails
+	require.NoError(t, db.Migrator().DropTable(&models.CashBalance{}))
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	_, err := paymentService.CreatePaymentsBatch([]*models.Payment{
+		{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(300),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodBankTransfer,
+			Details:       map[string]interface{}{"referenceId": "WIRE-001"},
+		},
+		{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-002",
+			Amount:        models.NewDecimal(100),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodCash,
+		},
+	}, user.ID)
+	if err == nil {
+		t.Fatal("Expected the database failure to abort the batch")
+	}
+
+	var count int64
+	db.Model(&models.Payment{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no payments saved, got %d", count)
+	}
+	var transaction models.Transaction
+	db.First(&transaction, "id = ?", "txn-batch-001")
+	if !transaction.TotalPaid.IsZero() {
+		t.Errorf("Expected the first payment to be rolled back, got paid=%v", transaction.TotalPaid)
+	}
+}
+
+// TestCompleteTransaction_WriteOff verifies small residuals can be written off and large ones are rejected
+func TestCompleteTransaction_WriteOff(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ExchangeRate{}, &models.TenantSettings{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	db.Create(&models.ExchangeRate{TenantID: tenant.ID, BaseCurrency: "CAD", TargetCurrency: "IRR", Rate: models.NewDecimal(85000), Source: models.RateSourceManual, CreatedAt: time.Now().Add(-time.Hour)})
+	writeOffCap := 5.0
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{WriteOffCap: &writeOffCap}); err != nil {
+		t.Fatalf("Failed to set write-off cap: %v", err)
+	}
+
+	newIRRTransaction := func(id string, remaining float64) {
+		db.Create(&models.Transaction{
+			ID:                  id,
+			TenantID:            tenant.ID,
+			ClientID:            "test-client-batch-001",
+			PaymentMethod:       models.TransactionMethodCash,
+			SendCurrency:        "CAD",
+			SendAmount:          models.NewDecimal(1000),
+			ReceiveCurrency:     "IRR",
+			ReceiveAmount:       models.NewDecimal(85000000),
+			ReceivedCurrency:    "IRR",
+			TotalReceived:       models.NewDecimal(85000000),
+			TotalPaid:           models.NewDecimal(85000000 - remaining),
+			RemainingBalance:    models.NewDecimal(remaining),
+			RateApplied:         models.NewDecimal(85000),
+			AllowPartialPayment: true,
+			PaymentStatus:       models.PaymentStatusPartial,
+			Status:              models.StatusCompleted,
+		})
+	}
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	t.Run("SmallResidualWrittenOff", func(t *testing.T) {
+		newIRRTransaction("txn-writeoff-small", 5000)
+
+		err := paymentService.CompleteTransaction("txn-writeoff-small", tenant.ID, CompleteTransactionOptions{WriteOff: true, Reason: "Rounding", UserID: user.ID})
+		if err != nil {
+			t.Fatalf("Expected 5,000 IRR residual to be written off, got: %v", err)
+		}
+
+		var transaction models.Transaction
+		db.First(&transaction, "id = ?", "txn-writeoff-small")
+		if transaction.PaymentStatus != models.PaymentStatusFullyPaid || !transaction.RemainingBalance.IsZero() {
+			t.Errorf("Expected fully paid with no remaining balance, got %s remaining=%v", transaction.PaymentStatus, transaction.RemainingBalance)
+		}
+		if transaction.WrittenOffAmount.Float64() != 5000 || transaction.WrittenOffBy == nil || *transaction.WrittenOffBy != user.ID {
+			t.Errorf("Expected write-off of 5000 by user %d, got %v by %v", user.ID, transaction.WrittenOffAmount, transaction.WrittenOffBy)
+		}
+
+		var entry models.LedgerEntry
+		if err := db.Where("transaction_id = ? AND type = ?", "txn-writeoff-small", models.LedgerTypeWithdrawal).First(&entry).Error; err != nil {
+			t.Fatalf("Expected a write-off ledger entry: %v", err)
+		}
+		if entry.Amount.Float64() != -5000 || entry.Currency != "IRR" {
+			t.Errorf("Expected -5000 IRR ledger entry, got %v %s", entry.Amount, entry.Currency)
+		}
+	})
+
+	t.Run("LargeResidualRejected", func(t *testing.T) {
+		newIRRTransaction("txn-writeoff-large", 2000000)
+
+		err := paymentService.CompleteTransaction("txn-writeoff-large", tenant.ID, CompleteTransactionOptions{WriteOff: true, Reason: "Customer left", UserID: user.ID})
+		if err == nil {
+			t.Fatal("Expected residual above the write-off cap to be rejected")
+		}
+
+		var transaction models.Transaction
+		db.First(&transaction, "id = ?", "txn-writeoff-large")
+		if transaction.PaymentStatus != models.PaymentStatusPartial || transaction.RemainingBalance.Float64() != 2000000 {
+			t.Errorf("Expected transaction to be left untouched, got %s remaining=%v", transaction.PaymentStatus, transaction.RemainingBalance)
+		}
+
+		var entries int64
+		db.Model(&models.LedgerEntry{}).Where("transaction_id = ?", "txn-writeoff-large").Count(&entries)
+		if entries != 0 {
+			t.Errorf("Expected no ledger entries, got %d", entries)
+		}
+	})
+
+	t.Run("CurrencyCapNeedsNoRate", func(t *testing.T) {
+		require.NoError(t, db.Where("tenant_id = ?", tenant.ID).Delete(&models.ExchangeRate{}).Error)
+		if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{WriteOffCurrencyCaps: map[string]float64{"irr": 10000}}); err != nil {
+			t.Fatalf("Failed to set the IRR write-off cap: %v", err)
+		}
+
+		newIRRTransaction("txn-writeoff-irr-small", 8000)
+		if err := paymentService.CompleteTransaction("txn-writeoff-irr-small", tenant.ID, CompleteTransactionOptions{WriteOff: true, Reason: "Rounding", UserID: user.ID}); err != nil {
+			t.Errorf("Expected 8,000 IRR to be written off against the IRR cap without a rate, got: %v", err)
+		}
+
+		newIRRTransaction("txn-writeoff-irr-large", 20000)
+		err := paymentService.CompleteTransaction("txn-writeoff-irr-large", tenant.ID, CompleteTransactionOptions{WriteOff: true, Reason: "Rounding", UserID: user.ID})
+		if err == nil || !strings.Contains(err.Error(), "exceeds the write-off cap of 10000 IRR") {
+			t.Errorf("Expected 20,000 IRR to exceed the IRR cap, got: %v", err)
+		}
+	})
+}
+
+// TestCompleteTransaction_RequiresApprovedKYCForLargeAmounts verifies large transactions wait for compliance approval
+func TestCompleteTransaction_RequiresApprovedKYCForLargeAmounts(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ExchangeRate{}, &models.TenantSettings{}, &models.Customer{}, &models.CustomerCompliance{}, &models.ComplianceAuditLog{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.FullKYCThreshold = models.NewDecimal(10000)
+	require.NoError(t, db.Create(&settings).Error)
+
+	customer := models.Customer{FullName: "Batch Test Client", Phone: "+14165551234"}
+	db.Create(&customer)
+	compliance := models.CustomerCompliance{TenantID: tenant.ID, CustomerID: customer.ID, Status: models.ComplianceStatusPending}
+	db.Create(&compliance)
+
+	db.Create(&models.Transaction{
+		ID:                  "txn-kyc-large",
+		TenantID:            tenant.ID,
+		ClientID:            "test-client-batch-001",
+		PaymentMethod:       models.TransactionMethodCash,
+		SendCurrency:        "CAD",
+		SendAmount:          models.NewDecimal(25000),
+		ReceiveCurrency:     "IRR",
+		ReceiveAmount:       models.NewDecimal(2125000000),
+		ReceivedCurrency:    "CAD",
+		TotalReceived:       models.NewDecimal(25000),
+		TotalPaid:           models.NewDecimal(25000),
+		RemainingBalance:    models.NewDecimal(0),
+		RateApplied:         models.NewDecimal(85000),
+		AllowPartialPayment: true,
+		PaymentStatus:       models.PaymentStatusPartial,
+		Status:              models.StatusCompleted,
+	})
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	err := paymentService.CompleteTransaction("txn-kyc-large", tenant.ID, CompleteTransactionOptions{UserID: user.ID})
+	if !errors.Is(err, ErrComplianceApprovalRequired) {
+		t.Fatalf("Expected ErrComplianceApprovalRequired for pending KYC, got: %v", err)
+	}
+
+	var transaction models.Transaction
+	db.First(&transaction, "id = ?", "txn-kyc-large")
+	if transaction.PaymentStatus == models.PaymentStatusFullyPaid {
+		t.Error("Expected transaction to remain incomplete while KYC is pending")
+	}
+
+	if err := NewComplianceService(db).UpdateComplianceStatus(compliance.ID, models.ComplianceStatusApproved, &user.ID, "Documents verified"); err != nil {
+		t.Fatalf("Failed to approve compliance: %v", err)
+	}
+
+	if err := paymentService.CompleteTransaction("txn-kyc-large", tenant.ID, CompleteTransactionOptions{UserID: user.ID}); err != nil {
+		t.Fatalf("Expected completion after KYC approval, got: %v", err)
+	}
+	db.First(&transaction, "id = ?", "txn-kyc-large")
+	if transaction.PaymentStatus != models.PaymentStatusFullyPaid {
+		t.Errorf("Expected FULLY_PAID after approval, got %s", transaction.PaymentStatus)
+	}
+}
+
+// TestCompleteTransaction_ComplianceThresholdsNeedARate verifies the completion checks are off by default and
+// that a transaction with no rate to the base currency is not held back by them
+func TestCompleteTransaction_ComplianceThresholdsNeedARate(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ExchangeRate{}, &models.TenantSettings{}, &models.Customer{}, &models.CustomerCompliance{}))
+	tenant, user := createTestTenantAndUser(db)
+
+	complete := func(id string) error {
+		t.Helper()
+		require.NoError(t, db.Create(&models.Transaction{
+			ID:                  id,
+			TenantID:            tenant.ID,
+			ClientID:            "test-client-batch-001",
+			PaymentMethod:       models.TransactionMethodCash,
+			SendCurrency:        "USD",
+			SendAmount:          models.NewDecimal(50000),
+			ReceiveCurrency:     "IRR",
+			ReceiveAmount:       models.NewDecimal(3000000000),
+			ReceivedCurrency:    "USD",
+			TotalReceived:       models.NewDecimal(50000),
+			TotalPaid:           models.NewDecimal(50000),
+			RemainingBalance:    models.NewDecimal(0),
+			RateApplied:         models.NewDecimal(60000),
+			AllowPartialPayment: true,
+			PaymentStatus:       models.PaymentStatusPartial,
+			Status:              models.StatusCompleted,
+		}).Error)
+		return NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db)).
+			CompleteTransaction(id, tenant.ID, CompleteTransactionOptions{UserID: user.ID})
+	}
+
+	if err := complete("txn-default-settings"); err != nil {
+		t.Errorf("Expected completion with the checks off by default, got: %v", err)
+	}
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.FullKYCThreshold = models.NewDecimal(10000)
+	settings.DocumentRequiredThreshold = models.NewDecimal(10000)
+	require.NoError(t, db.Create(&settings).Error)
+	if err := complete("txn-no-usd-rate"); err != nil {
+		t.Errorf("Expected completion of a transaction with no USD/CAD rate, got: %v", err)
+	}
+}
+
+// TestCompleteTransaction_RequiresSupportingDocumentForLargeAmounts verifies large transactions need an attachment to complete
+func TestCompleteTransaction_RequiresSupportingDocumentForLargeAmounts(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.ExchangeRate{}, &models.TenantSettings{}, &models.TransactionAttachment{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.FullKYCThreshold = models.Zero()
+	settings.DocumentRequiredThreshold = models.NewDecimal(5000)
+	db.Create(&settings)
+
+	newTransaction := func(id string, amount float64) {
+		db.Create(&models.Transaction{
+			ID:                  id,
+			TenantID:            tenant.ID,
+			ClientID:            "test-client-batch-001",
+			PaymentMethod:       models.TransactionMethodCash,
+			SendCurrency:        "CAD",
+			SendAmount:          models.NewDecimal(amount),
+			ReceiveCurrency:     "IRR",
+			ReceiveAmount:       models.NewDecimal(amount * 85000),
+			ReceivedCurrency:    "CAD",
+			TotalReceived:       models.NewDecimal(amount),
+			TotalPaid:           models.NewDecimal(amount),
+			RemainingBalance:    models.NewDecimal(0),
+			RateApplied:         models.NewDecimal(85000),
+			AllowPartialPayment: true,
+			PaymentStatus:       models.PaymentStatusPartial,
+			Status:              models.StatusCompleted,
+		})
+	}
+	newTransaction("txn-doc-large", 8000)
+	newTransaction("txn-doc-small", 1200)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	err := paymentService.CompleteTransaction("txn-doc-large", tenant.ID, CompleteTransactionOptions{UserID: user.ID})
+	if !errors.Is(err, ErrSupportingDocumentRequired) {
+		t.Fatalf("Expected ErrSupportingDocumentRequired without an attachment, got: %v", err)
+	}
+
+	var transaction models.Transaction
+	db.First(&transaction, "id = ?", "txn-doc-large")
+	if transaction.PaymentStatus == models.PaymentStatusFullyPaid {
+		t.Error("Expected transaction to remain incomplete without a supporting document")
+	}
+
+	t.Run("BelowThresholdNeedsNoDocument", func(t *testing.T) {
+		if err := paymentService.CompleteTransaction("txn-doc-small", tenant.ID, CompleteTransactionOptions{UserID: user.ID}); err != nil {
+			t.Fatalf("Expected a small transaction to complete without an attachment, got: %v", err)
+		}
+	})
+
+	attachment := &models.TransactionAttachment{FileName: "invoice.pdf", FilePath: "/tmp/invoice.pdf", FileSize: 1024, MimeType: "application/pdf"}
+	if err := NewTransactionAttachmentService(db).AddAttachment(tenant.ID, "txn-doc-large", attachment, user.ID); err != nil {
+		t.Fatalf("Failed to attach document: %v", err)
+	}
+
+	if err := paymentService.CompleteTransaction("txn-doc-large", tenant.ID, CompleteTransactionOptions{UserID: user.ID}); err != nil {
+		t.Fatalf("Expected completion once a document is attached, got: %v", err)
+	}
+	transaction = models.Transaction{}
+	db.First(&transaction, "id = ?", "txn-doc-large")
+	if transaction.PaymentStatus != models.PaymentStatusFullyPaid {
+		t.Errorf("Expected FULLY_PAID after attaching a document, got %s", transaction.PaymentStatus)
+	}
+
+	t.Run("RequiredDocumentKeptFromTellers", func(t *testing.T) {
+		require.NoError(t, db.AutoMigrate(&models.AuditLog{}))
+		attachmentService := NewTransactionAttachmentService(db)
+		teller := newTestUser(t, db, tenant.ID, "doc-teller@example.com", models.RoleTenantUser)
+
+		if _, err := attachmentService.DeleteAttachment(tenant.ID, "txn-doc-large", attachment.ID, teller); !errors.Is(err, ErrRequiredDocumentProtected) {
+			t.Fatalf("Expected a teller not to remove the only required document, got: %v", err)
+		}
+		if attached, _ := attachmentService.HasAttachment(tenant.ID, "txn-doc-large"); !attached {
+			t.Fatal("Expected the document to be kept")
+		}
+
+		if _, err := attachmentService.DeleteAttachment(tenant.ID, "txn-doc-large", attachment.ID, user); err != nil {
+			t.Fatalf("Expected the owner to remove the document, got: %v", err)
+		}
+		var audits int64
+		db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ?", models.ActionDeleteRequiredDocument, "txn-doc-large").Count(&audits)
+		if audits != 1 {
+			t.Errorf("Expected one audit entry for the removal, got %d", audits)
+		}
+	})
+}
+
+// TestUpdatePayment_CashDeltasForMethodAndCurrencyChanges verifies every method/currency edit moves the cash drawers by the net amount and is reversible
+func TestUpdatePayment_CashDeltasForMethodAndCurrencyChanges(t *testing.T) {
+	const (
+		cash = models.PaymentMethodCash
+		bank = models.PaymentMethodBankTransfer
+	)
+
+	type edit struct {
+		amount   float64
+		currency string
+		rate     float64
+	}
+	sameAmount := edit{100, "CAD", 1}
+	newAmount := edit{150, "CAD", 1}
+	newCurrency := edit{80, "USD", 1.35}
+
+	cases := []struct {
+		name       string
+		fromMethod string
+		toMethod   string
+		to         edit
+		want       map[string]float64
+	}{
+		{"CashToCash/SameAmount", cash, cash, sameAmount, map[string]float64{"CAD": 0, "USD": 0}},
+		{"CashToCash/AmountChanged", cash, cash, newAmount, map[string]float64{"CAD": 50, "USD": 0}},
+		{"CashToCash/CurrencyChanged", cash, cash, newCurrency, map[string]float64{"CAD": -100, "USD": 80}},
+		{"CashToBank/SameAmount", cash, bank, sameAmount, map[string]float64{"CAD": -100, "USD": 0}},
+		{"CashToBank/AmountChanged", cash, bank, newAmount, map[string]float64{"CAD": -100, "USD": 0}},
+		{"CashToBank/CurrencyChanged", cash, bank, newCurrency, map[string]float64{"CAD": -100, "USD": 0}},
+		{"BankToCash/SameAmount", bank, cash, sameAmount, map[string]float64{"CAD": 100, "USD": 0}},
+		{"BankToCash/AmountChanged", bank, cash, newAmount, map[string]float64{"CAD": 150, "USD": 0}},
+		{"BankToCash/CurrencyChanged", bank, cash, newCurrency, map[string]float64{"CAD": 0, "USD": 80}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupBatchPaymentTestDB(t)
+			tenant, user := createTestTenantAndUser(db)
+			createTestTransactions(db, tenant.ID)
+			for _, currency := range []string{"CAD", "USD"} {
+				db.Create(&models.CashBalance{TenantID: tenant.ID, Currency: currency, LastCalculatedAt: time.Now()})
+			}
+
+			balances := func() map[string]float64 {
+				var rows []models.CashBalance
+				db.Where("tenant_id = ?", tenant.ID).Find(&rows)
+				result := make(map[string]float64)
+				for _, row := range rows {
+					result[row.Currency] = row.FinalBalance.Float64()
+				}
+				return result
+			}
+
+			paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+			payment := &models.Payment{
+				TenantID:      tenant.ID,
+				TransactionID: "txn-batch-001",
+				Amount:        models.NewDecimal(100),
+				Currency:      "CAD",
+				ExchangeRate:  models.NewDecimal(1),
+				PaymentMethod: tc.fromMethod,
+				Details:       map[string]interface{}{"referenceId": "WIRE-EDIT"},
+			}
+			if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+				t.Fatalf("Failed to create payment: %v", err)
+			}
+			before := balances()
+
+			err := paymentService.UpdatePayment(payment.ID, tenant.ID, map[string]interface{}{
+				"amount":        tc.to.amount,
+				"currency":      tc.to.currency,
+				"exchangeRate":  tc.to.rate,
+				"paymentMethod": tc.toMethod,
+			}, user.ID, "Correction")
+			if err != nil {
+				t.Fatalf("Failed to update payment: %v", err)
+			}
+
+			after := balances()
+			for currency, want := range tc.want {
+				if got := after[currency] - before[currency]; got != want {
+					t.Errorf("%s cash delta: expected %v, got %v", currency, want, got)
+				}
+			}
+
+			// Reverting the edit must restore the drawers exactly
+			err = paymentService.UpdatePayment(payment.ID, tenant.ID, map[string]interface{}{
+				"amount":        100.0,
+				"currency":      "CAD",
+				"exchangeRate":  1.0,
+				"paymentMethod": tc.fromMethod,
+			}, user.ID, "Revert")
+			if err != nil {
+				t.Fatalf("Failed to revert payment: %v", err)
+			}
+			for currency, want := range before {
+				if got := balances()[currency]; got != want {
+					t.Errorf("%s balance after revert: expected %v, got %v", currency, want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestPaymentPlan_PaymentsSettleInstallmentsInOrder verifies payments are applied FIFO to the earliest unpaid installment
+func TestPaymentPlan_PaymentsSettleInstallmentsInOrder(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+
+	now := time.Now()
+	plan, err := paymentService.CreatePaymentPlan(tenant.ID, "txn-batch-001", []PaymentInstallmentInput{
+		{DueDate: now.AddDate(0, 0, 30), ExpectedAmount: models.NewDecimal(400)},
+		{DueDate: now.AddDate(0, 0, -10), ExpectedAmount: models.NewDecimal(300)}, // listed out of order on purpose
+		{DueDate: now.AddDate(0, 0, 10), ExpectedAmount: models.NewDecimal(300)},
+	}, "Three monthly installments", user.ID)
+	if err != nil {
+		t.Fatalf("Failed to create payment plan: %v", err)
+	}
+	if len(plan.Installments) != 3 || plan.Installments[0].ExpectedAmount.Float64() != 300 || plan.Installments[2].ExpectedAmount.Float64() != 400 {
+		t.Fatalf("Expected installments ordered by due date, got %+v", plan.Installments)
+	}
+
+	if _, err := paymentService.CreatePaymentPlan(tenant.ID, "txn-batch-002", []PaymentInstallmentInput{
+		{DueDate: now, ExpectedAmount: models.NewDecimal(100)},
+	}, "", user.ID); err == nil {
+		t.Error("Expected a plan that does not cover the remaining balance to be rejected")
+	}
+
+	aging := NewDashboardService(db).getInstallmentAging(tenant.ID, nil, now)
+	if aging[2].Count != 1 || aging[2].Outstanding["CAD"] != 300 {
+		t.Errorf("Expected the overdue first installment in the 8-30 day bucket, got %+v", aging[2])
+	}
+
+	statuses := func() []string {
+		current, err := paymentService.GetPaymentPlan(tenant.ID, "txn-batch-001")
+		if err != nil {
+			t.Fatalf("Failed to load payment plan: %v", err)
+		}
+		result := make([]string, len(current.Installments))
+		for i, installment := range current.Installments {
+			result[i] = installment.Status
+		}
+		return result
+	}
+
+	pay := func(amount float64) {
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(amount),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodBankTransfer,
+			Details:       map[string]interface{}{"referenceId": "WIRE-PLAN"},
+		}
+		if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+			t.Fatalf("Failed to create payment: %v", err)
+		}
+	}
+
+	steps := []struct {
+		amount float64
+		want   []string
+	}{
+		{300, []string{models.InstallmentStatusPaid, models.InstallmentStatusPending, models.InstallmentStatusPending}},
+		{350, []string{models.InstallmentStatusPaid, models.InstallmentStatusPaid, models.InstallmentStatusPartial}},
+		{350, []string{models.InstallmentStatusPaid, models.InstallmentStatusPaid, models.InstallmentStatusPaid}},
+	}
+	for i, step := range steps {
+		pay(step.amount)
+		got := statuses()
+		for j := range step.want {
+			if got[j] != step.want[j] {
+				t.Errorf("After payment %d: expected installment %d to be %s, got %s", i+1, j+1, step.want[j], got[j])
+			}
+		}
+	}
+
+	aging = NewDashboardService(db).getInstallmentAging(tenant.ID, nil, now)
+	for _, bucket := range aging {
+		if bucket.Count != 0 {
+			t.Errorf("Expected no unpaid installments in %q, got %d", bucket.Bucket, bucket.Count)
+		}
+	}
+}
+
+// TestCreatePayment_PublishesEventsOnlyAfterCommit verifies payment events carry the new balance and never fire on rollback
+func TestCreatePayment_PublishesEventsOnlyAfterCommit(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+
+	var published []DomainEvent
+	bus := NewInMemoryEventBus()
+	bus.Subscribe(func(event DomainEvent) { published = append(published, event) })
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	paymentService.Events = bus
+
+	newPayment := func(amount float64) *models.Payment {
+		return &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: "txn-batch-001",
+			Amount:        models.NewDecimal(amount),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: models.PaymentMethodCash,
+		}
+	}
+
+	payment := newPayment(250)
+	if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+		t.Fatalf("Failed to create payment: %v", err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(published))
+	}
+	event := published[0]
+	if event.Name != EventPaymentCreated || event.TenantID != tenant.ID {
+		t.Errorf("Expected %s for tenant %d, got %s for tenant %d", EventPaymentCreated, tenant.ID, event.Name, event.TenantID)
+	}
+	if event.Data["transactionId"] != "txn-batch-001" || event.Data["paymentId"] != payment.ID || event.Data["remainingBalance"] != 750.0 {
+		t.Errorf("Unexpected event payload: %+v", event.Data)
+	}
+
+	// A failure after the payment row was written rolls everything back and publishes nothing
+	db.Callback().Create().After("gorm:create").Register("test:fail_ledger", func(tx *gorm.DB) {
+		if tx.Statement.Table == "ledger_entries" {
+			tx.AddError(errors.New("ledger unavailable"))
+		}
+	})
+	if err := paymentService.CreatePayment(newPayment(100), user.ID); err == nil {
+		t.Fatal("Expected payment to fail when the ledger write fails")
+	}
+	db.Callback().Create().Remove("test:fail_ledger")
+
+	if len(published) != 1 {
+		t.Errorf("Expected no event for the rolled back payment, got %d events", len(published))
+	}
+	var count int64
+	db.Model(&models.Payment{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the failed payment to be rolled back, got %d payments", count)
+	}
+
+	if err := paymentService.CancelPayment(payment.ID, tenant.ID, user.ID, "Entered twice"); err != nil {
+		t.Fatalf("Failed to cancel payment: %v", err)
+	}
+	if last := published[len(published)-1]; last.Name != EventPaymentCancelled || last.Data["remainingBalance"] != 1000.0 {
+		t.Errorf("Expected cancel event restoring the balance, got %s %+v", last.Name, last.Data)
+	}
+}
+
+// TestVoidTransaction_ReversesPartialPayments verifies voiding a partially-paid transaction cancels its payments
+// and puts the cash drawer and the client's ledger back where they were, unless the funds were already disbursed or settled
+func TestVoidTransaction_ReversesPartialPayments(t *testing.T) {
+	db := setupBatchPaymentTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Disbursement{}, &models.TenantSettings{}, &models.OutgoingRemittance{},
+		&models.IncomingRemittance{}, &models.RemittanceSettlement{}))
+	tenant, user := createTestTenantAndUser(db)
+	createTestTransactions(db, tenant.ID)
+	db.Create(&models.CashBalance{TenantID: tenant.ID, Currency: "CAD", LastCalculatedAt: time.Now()})
+
+	ledgerService := NewLedgerService(db)
+	paymentService := NewPaymentService(db, ledgerService, NewCashBalanceService(db))
+
+	balances := func() (float64, float64) {
+		t.Helper()
+		var cash models.CashBalance
+		db.Where("tenant_id = ? AND currency = ?", tenant.ID, "CAD").First(&cash)
+		ledger, err := ledgerService.GetClientBalances("test-client-batch-001", tenant.ID)
+		if err != nil {
+			t.Fatalf("Failed to get ledger balances: %v", err)
+		}
+		return cash.FinalBalance.Float64(), ledger["CAD"].Float64()
+	}
+	cashBefore, ledgerBefore := balances()
+
+	pay := func(transactionID string, amount float64, method string) *models.Payment {
+		t.Helper()
+		payment := &models.Payment{
+			TenantID:      tenant.ID,
+			TransactionID: transactionID,
+			Amount:        models.NewDecimal(amount),
+			Currency:      "CAD",
+			ExchangeRate:  models.NewDecimal(1),
+			PaymentMethod: method,
+			Details:       map[string]interface{}{"referenceId": "WIRE-" + transactionID},
+		}
+		if err := paymentService.CreatePayment(payment, user.ID); err != nil {
+			t.Fatalf("Failed to create payment: %v", err)
+		}
+		return payment
+	}
+
+	t.Run("PartiallyPaid", func(t *testing.T) {
+		cashPayment := pay("txn-batch-001", 300, models.PaymentMethodCash)
+		pay("txn-batch-001", 200, models.PaymentMethodBankTransfer)
+		if _, err := paymentService.RefundPayment(cashPayment.ID, tenant.ID, models.NewDecimal(50), "Overcharge", user.ID); err != nil {
+			t.Fatalf("Failed to refund payment: %v", err)
+		}
+		if cash, ledger := balances(); cash != cashBefore+250 || ledger != ledgerBefore+450 {
+			t.Fatalf("Expected payments to add 250 cash and 450 ledger credit, got cash %v ledger %v", cash, ledger)
+		}
+
+		voided, err := paymentService.VoidTransaction("txn-batch-001", tenant.ID, user.ID, "Entered for the wrong client")
+		if err != nil {
+			t.Fatalf("Failed to void transaction: %v", err)
+		}
+		if voided.Status != models.StatusCancelled || !voided.TotalPaid.IsZero() || voided.RemainingBalance.Float64() != 1000 {
+			t.Errorf("Expected a cancelled transaction with nothing paid, got status %s paid %v remaining %v",
+				voided.Status, voided.TotalPaid, voided.RemainingBalance)
+		}
+		if cash, ledger := balances(); cash != cashBefore || ledger != ledgerBefore {
+			t.Errorf("Expected cash %v and ledger %v restored, got cash %v ledger %v", cashBefore, ledgerBefore, cash, ledger)
+		}
+
+		var live int64
+		db.Model(&models.Payment{}).Where("transaction_id = ? AND status <> ?", "txn-batch-001", models.PaymentStatusCancelled).Count(&live)
+		if live != 0 {
+			t.Errorf("Expected every payment to be cancelled, %d still live", live)
+		}
+
+		if _, err := paymentService.VoidTransaction("txn-batch-001", tenant.ID, user.ID, "Again"); err == nil {
+			t.Error("Expected voiding a cancelled transaction to fail")
+		}
+	})
+
+	t.Run("SettledRejected", func(t *testing.T) {
+		pay("txn-batch-002", 100, models.PaymentMethodCash)
+		transactionID := "txn-batch-002"
+		db.Create(&models.Disbursement{
+			TenantID:      tenant.ID,
+			TransactionID: &transactionID,
+			PickupCode:    "VOID01",
+			SenderName:    "Batch Test Client",
+			RecipientName: "Recipient",
+			Amount:        100,
+			Currency:      "CAD",
+			Status:        models.DisbursementStatusDisbursed,
+		})
+		cashBefore, ledgerBefore := balances()
+
+		_, err := paymentService.VoidTransaction(transactionID, tenant.ID, user.ID, "Too late")
+		if !errors.Is(err, ErrTransactionSettled) {
+			t.Fatalf("Expected ErrTransactionSettled, got %v", err)
+		}
+
+		var transaction models.Transaction
+		db.First(&transaction, "id = ?", transactionID)
+		if transaction.Status != models.StatusCompleted || transaction.TotalPaid.Float64() != 100 {
+			t.Errorf("Expected the settled transaction to be untouched, got status %s paid %v", transaction.Status, transaction.TotalPaid)
+		}
+		if cash, ledger := balances(); cash != cashBefore || ledger != ledgerBefore {
+			t.Errorf("Expected balances unchanged, got cash %v ledger %v", cash, ledger)
+		}
+	})
+
+	t.Run("RemittanceSettledRejected", func(t *testing.T) {
+		transactionID := "txn-batch-003"
+		pay(transactionID, 750, models.PaymentMethodCash)
+
+		remittanceService := NewRemittanceService(db)
+		newRemittance := func(linked string) *models.OutgoingRemittance {
+			return &models.OutgoingRemittance{
+				TenantID:      tenant.ID,
+				SenderName:    "Batch Test Client",
+				SenderPhone:   "+14165551234",
+				RecipientName: "Recipient",
+				AmountIRR:     models.NewDecimal(63750000),
+				BuyRateCAD:    models.NewDecimal(85000),
+				ReceivedCAD:   models.NewDecimal(750),
+				TransactionID: &linked,
+				CreatedBy:     user.ID,
+			}
+		}
+		if err := remittanceService.CreateOutgoingRemittance(newRemittance("no-such-transaction")); !errors.Is(err, ErrRemittanceTransactionNotFound) {
+			t.Fatalf("Expected ErrRemittanceTransactionNotFound, got %v", err)
+		}
+		remittance := newRemittance(transactionID)
+		require.NoError(t, remittanceService.CreateOutgoingRemittance(remittance))
+
+		require.NoError(t, db.Create(&models.RemittanceSettlement{
+			TenantID:             tenant.ID,
+			OutgoingRemittanceID: remittance.ID,
+			IncomingRemittanceID: 1,
+			SettledAmountIRR:     models.NewDecimal(63750000),
+			OutgoingBuyRate:      models.NewDecimal(85000),
+			IncomingSellRate:     models.NewDecimal(84000),
+			ProfitCAD:            models.NewDecimal(8.93),
+			CreatedBy:            user.ID,
+		}).Error)
+		cashBefore, ledgerBefore := balances()
+
+		_, err := paymentService.VoidTransaction(transactionID, tenant.ID, user.ID, "Already settled")
+		if !errors.Is(err, ErrTransactionSettled) {
+			t.Fatalf("Expected ErrTransactionSettled, got %v", err)
+		}
+		if cash, ledger := balances(); cash != cashBefore || ledger != ledgerBefore {
+			t.Errorf("Expected balances unchanged, got cash %v ledger %v", cash, ledger)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"api/pkg/models"
 	"errors"
 	"fmt"
+	"html"
 	"log"
 	"math/rand"
 	"strconv"
@@ -14,7 +15,8 @@ import (
 )
 
 type PickupService struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Notifier NotificationProvider
 }
 
 // PickupSearchFilters defines optional filters for pickup search.
@@ -86,7 +88,7 @@ func parseAmountQuery(query string) (float64, bool) {
 }
 
 func NewPickupService(db *gorm.DB) *PickupService {
-	return &PickupService{DB: db}
+	return &PickupService{DB: db, Notifier: NewNotificationProvider()}
 }
 
 // generatePickupCode generates a unique 4-digit code with letter prefix (e.g., T-1234, B-2753)
@@ -411,7 +413,7 @@ func (s *PickupService) MarkAsPickedUp(id uint, tenantID uint, userID uint) erro
 	}
 
 	// Validate status
-	if pickup.Status != models.PickupStatusPending {
+	if pickup.Status != models.PickupStatusPending && pickup.Status != models.PickupStatusReady {
 		return fmt.Errorf("cannot mark as picked up: current status is %s", pickup.Status)
 	}
 
@@ -431,6 +433,83 @@ func (s *PickupService) MarkAsPickedUp(id uint, tenantID uint, userID uint) erro
 	return nil
 }
 
+// MarkAsReady marks a pending pickup as ready for collection and notifies the recipient
+func (s *PickupService) MarkAsReady(id uint, tenantID uint, userID uint) (*models.PickupTransaction, error) {
+	var pickup models.PickupTransaction
+	if err := s.DB.Preload("ReceiverBranch").Where("id = ? AND tenant_id = ?", id, tenantID).First(&pickup).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pickup transaction not found")
+		}
+		return nil, err
+	}
+
+	if pickup.Status != models.PickupStatusPending {
+		return nil, fmt.Errorf("cannot mark as ready: current status is %s", pickup.Status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":           models.PickupStatusReady,
+		"ready_at":         &now,
+		"ready_by_user_id": &userID,
+		"updated_at":       now,
+	}
+	if err := s.DB.Model(&pickup).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	if s.notifyReady(&pickup) {
+		s.DB.Model(&pickup).Update("ready_notified_at", now)
+		pickup.ReadyNotifiedAt = &now
+	}
+
+	return &pickup, nil
+}
+
+// notifyReady tells the recipient their pickup is ready, if the tenant has notifications on
+// and contact details exist. Delivery failures are logged and do not block the transition.
+func (s *PickupService) notifyReady(pickup *models.PickupTransaction) bool {
+	if s.Notifier == nil {
+		return false
+	}
+	settings, err := NewTenantSettingsService(s.DB).GetSettings(pickup.TenantID)
+	if err != nil || !settings.NotifyPickupReady {
+		return false
+	}
+
+	location := ""
+	if pickup.ReceiverBranch != nil {
+		location = pickup.ReceiverBranch.Name
+		if pickup.ReceiverBranch.Location != "" {
+			location = fmt.Sprintf("%s, %s", location, pickup.ReceiverBranch.Location)
+		}
+	}
+
+	message := fmt.Sprintf("Hello %s, your transfer of %.2f %s is ready for pickup. Pickup code: %s.",
+		pickup.RecipientName, pickup.Amount, pickup.Currency, pickup.PickupCode)
+	if location != "" {
+		message = fmt.Sprintf("%s Location: %s.", message, location)
+	}
+
+	notified := false
+	if pickup.RecipientPhone != nil && strings.TrimSpace(*pickup.RecipientPhone) != "" {
+		if err := s.Notifier.SendSMS(*pickup.RecipientPhone, message); err != nil {
+			log.Printf("⚠️  Failed to send pickup-ready SMS for pickup %d: %v", pickup.ID, err)
+		} else {
+			notified = true
+		}
+	}
+	if pickup.RecipientEmail != nil && strings.TrimSpace(*pickup.RecipientEmail) != "" {
+		subject := fmt.Sprintf("Your transfer is ready for pickup (code %s)", pickup.PickupCode)
+		if err := s.Notifier.SendEmail(*pickup.RecipientEmail, subject, "<p>"+html.EscapeString(message)+"</p>"); err != nil {
+			log.Printf("⚠️  Failed to send pickup-ready email for pickup %d: %v", pickup.ID, err)
+		} else {
+			notified = true
+		}
+	}
+	return notified
+}
+
 // CancelPickupTransaction cancels a pickup transaction
 func (s *PickupService) CancelPickupTransaction(id uint, tenantID uint, userID uint, reason string) error {
 	var pickup models.PickupTransaction
@@ -442,7 +521,7 @@ func (s *PickupService) CancelPickupTransaction(id uint, tenantID uint, userID u
 	}
 
 	// Validate status
-	if pickup.Status != models.PickupStatusPending {
+	if pickup.Status != models.PickupStatusPending && pickup.Status != models.PickupStatusReady {
 		return fmt.Errorf("cannot cancel: current status is %s", pickup.Status)
 	}
 
@@ -474,9 +553,9 @@ func (s *PickupService) EditPickupTransaction(id uint, tenantID uint, userID uin
 		return err
 	}
 
-	// Only allow editing PENDING pickups
-	if pickup.Status != models.PickupStatusPending {
-		return fmt.Errorf("cannot edit: current status is %s (only PENDING pickups can be edited)", pickup.Status)
+	// Only allow editing pickups that have not been collected or cancelled
+	if pickup.Status != models.PickupStatusPending && pickup.Status != models.PickupStatusReady {
+		return fmt.Errorf("cannot edit: current status is %s (only PENDING or READY pickups can be edited)", pickup.Status)
 	}
 
 	// Build updates map
@@ -531,12 +610,12 @@ func (s *PickupService) EditPickupTransaction(id uint, tenantID uint, userID uin
 	return nil
 }
 
-// GetPendingPickupsCount returns count of pending pickups for a branch
+// GetPendingPickupsCount returns count of pickups still awaiting collection (PENDING or READY) for a branch
 func (s *PickupService) GetPendingPickupsCount(branchID uint, tenantID uint) (int64, error) {
 	var count int64
 	err := s.DB.Model(&models.PickupTransaction{}).
-		Where("tenant_id = ? AND receiver_branch_id = ? AND status = ?",
-			tenantID, branchID, models.PickupStatusPending).
+		Where("tenant_id = ? AND receiver_branch_id = ? AND status IN ?",
+			tenantID, branchID, []string{models.PickupStatusPending, models.PickupStatusReady}).
 		Count(&count).Error
 
 	return count, err
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"api/pkg/models"
+	"strings"
+	"testing"
+)
+
+// TestMarkAsReady_NotifiesRecipient verifies marking a pickup ready sends the pickup code to the recipient
+func TestMarkAsReady_NotifiesRecipient(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.TenantSettings{}, &models.PickupTransaction{})
+
+	tenant := newTestTenant(t, db, "Pickup Exchange")
+	user := newTestUser(t, db, tenant.ID, "teller@example.com", "tenant_owner")
+	sender := newTestBranch(t, db, tenant.ID, "Toronto", "TOR")
+	receiver := &models.Branch{TenantID: tenant.ID, Name: "Vancouver", BranchCode: "VAN", Location: "123 Main St", Status: models.BranchStatusActive}
+	db.Create(receiver)
+
+	notifier := NewMockNotificationProvider()
+	pickupService := NewPickupService(db)
+	pickupService.Notifier = notifier
+
+	phone := "+16045550123"
+	email := "recipient@example.com"
+	pickup := &models.PickupTransaction{
+		TenantID:         tenant.ID,
+		SenderBranchID:   sender.ID,
+		ReceiverBranchID: receiver.ID,
+		SenderName:       "Sara Sender",
+		SenderPhone:      "+14165550100",
+		RecipientName:    "Reza Recipient",
+		RecipientPhone:   &phone,
+		RecipientEmail:   &email,
+		TransactionType:  models.TransactionTypeCashExchange,
+		Amount:           500,
+		Currency:         "CAD",
+	}
+	if err := pickupService.CreatePickupTransaction(pickup); err != nil {
+		t.Fatalf("Failed to create pickup: %v", err)
+	}
+
+	ready, err := pickupService.MarkAsReady(pickup.ID, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to mark pickup ready: %v", err)
+	}
+	if ready.Status != models.PickupStatusReady || ready.ReadyNotifiedAt == nil {
+		t.Errorf("Expected READY with notification recorded, got status=%s notified=%v", ready.Status, ready.ReadyNotifiedAt)
+	}
+
+	if len(notifier.Sent) != 2 {
+		t.Fatalf("Expected SMS and email notifications, got %d", len(notifier.Sent))
+	}
+	for _, sent := range notifier.Sent {
+		if !strings.Contains(sent.Body, pickup.PickupCode) {
+			t.Errorf("Expected %s notification to include pickup code %s, got %q", sent.Channel, pickup.PickupCode, sent.Body)
+		}
+		if !strings.Contains(sent.Body, "Vancouver") {
+			t.Errorf("Expected %s notification to include the pickup location", sent.Channel)
+		}
+	}
+	if notifier.Sent[0].To != phone || notifier.Sent[1].To != email {
+		t.Errorf("Unexpected recipients: %s, %s", notifier.Sent[0].To, notifier.Sent[1].To)
+	}
+
+	// A ready pickup is still awaiting collection
+	if pending, err := pickupService.GetPendingPickupsCount(receiver.ID, tenant.ID); err != nil || pending != 1 {
+		t.Errorf("Expected the ready pickup to count as pending, got %d (%v)", pending, err)
+	}
+
+	// A second transition is rejected and sends nothing further
+	if _, err := pickupService.MarkAsReady(pickup.ID, tenant.ID, user.ID); err == nil {
+		t.Error("Expected marking an already-ready pickup to fail")
+	}
+	if len(notifier.Sent) != 2 {
+		t.Errorf("Expected no further notifications, got %d", len(notifier.Sent))
+	}
+
+	// The recipient can still collect a ready pickup
+	if err := pickupService.MarkAsPickedUp(pickup.ID, tenant.ID, user.ID); err != nil {
+		t.Errorf("Expected ready pickup to be collectable: %v", err)
+	}
+}
+
+// TestMarkAsReady_LoggedSMSIsNotANotification verifies an SMS that was only logged does not mark the
+// recipient as notified
+func TestMarkAsReady_LoggedSMSIsNotANotification(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.TenantSettings{}, &models.PickupTransaction{})
+
+	tenant := newTestTenant(t, db, "Pickup Exchange")
+	user := newTestUser(t, db, tenant.ID, "teller@example.com", "tenant_owner")
+	sender := newTestBranch(t, db, tenant.ID, "Toronto", "TOR")
+	receiver := newTestBranch(t, db, tenant.ID, "Vancouver", "VAN")
+
+	pickupService := NewPickupService(db)
+	pickupService.Notifier = &DefaultNotificationProvider{}
+
+	phone := "+16045550123"
+	pickup := &models.PickupTransaction{
+		TenantID:         tenant.ID,
+		SenderBranchID:   sender.ID,
+		ReceiverBranchID: receiver.ID,
+		SenderName:       "Sara Sender",
+		SenderPhone:      "+14165550100",
+		RecipientName:    "Reza Recipient",
+		RecipientPhone:   &phone,
+		TransactionType:  models.TransactionTypeCashExchange,
+		Amount:           500,
+		Currency:         "CAD",
+	}
+	if err := pickupService.CreatePickupTransaction(pickup); err != nil {
+		t.Fatalf("Failed to create pickup: %v", err)
+	}
+
+	ready, err := pickupService.MarkAsReady(pickup.ID, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to mark pickup ready: %v", err)
+	}
+	if ready.Status != models.PickupStatusReady || ready.ReadyNotifiedAt != nil {
+		t.Errorf("Expected READY without a recorded notification, got status=%s notified=%v", ready.Status, ready.ReadyNotifiedAt)
+	}
+}
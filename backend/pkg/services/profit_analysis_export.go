@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Profit analysis export formats
+const (
+	ProfitExportFormatCSV  = "csv"
+	ProfitExportFormatXLSX = "xlsx"
+)
+
+// profitExportSection is one breakdown of a profit analysis, written as a sheet or CSV section
+type profitExportSection struct {
+	Name   string
+	Header []interface{}
+	Rows   [][]interface{}
+}
+
+// profitExportSections flattens a profit analysis into its summary and one section per breakdown,
+// each breakdown ending with a totals row
+func profitExportSections(result *ProfitAnalysisResult) []profitExportSection {
+	summary := profitExportSection{
+		Name:   "Summary",
+		Header: []interface{}{"Metric", "Value"},
+		Rows: [][]interface{}{
+			{"Start Date", result.StartDate.Format("2006-01-02")},
+			{"End Date", result.EndDate.Format("2006-01-02")},
+			{"Base Currency", result.BaseCurrency},
+			{"Total Profit", roundMoney(result.TotalProfitCAD)},
+			{"Total Volume IRR", roundMoney(result.TotalVolumeIRR)},
+			{"Total Settlements", result.TotalSettlements},
+			{"Avg Profit per Settlement", roundMoney(result.AvgProfitPerSettlement)},
+			{"Unconverted Transactions", result.UnconvertedTransactions},
+		},
+	}
+
+	byPeriod := profitExportSection{
+		Name:   "By Period",
+		Header: []interface{}{"Period", "Profit", "Settlements", "Volume IRR"},
+	}
+	var periodProfit, periodVolume float64
+	var periodCount int
+	for _, p := range result.ByPeriod {
+		byPeriod.Rows = append(byPeriod.Rows, []interface{}{p.Period, roundMoney(p.TotalProfitCAD), p.SettlementCount, roundMoney(p.VolumeIRR)})
+		periodProfit += p.TotalProfitCAD
+		periodCount += p.SettlementCount
+		periodVolume += p.VolumeIRR
+	}
+	byPeriod.Rows = append(byPeriod.Rows, []interface{}{"Total", roundMoney(periodProfit), periodCount, roundMoney(periodVolume)})
+
+	byBranch := profitExportSection{
+		Name:   "By Branch",
+		Header: []interface{}{"Branch", "Profit", "Settlements", "Volume IRR", "Percentage"},
+	}
+	var branchProfit, branchVolume, branchPct float64
+	var branchCount int
+	for _, b := range result.ByBranch {
+		byBranch.Rows = append(byBranch.Rows, []interface{}{b.BranchName, roundMoney(b.TotalProfitCAD), b.SettlementCount, roundMoney(b.VolumeIRR), roundMoney(b.Percentage)})
+		branchProfit += b.TotalProfitCAD
+		branchCount += b.SettlementCount
+		branchVolume += b.VolumeIRR
+		branchPct += b.Percentage
+	}
+	byBranch.Rows = append(byBranch.Rows, []interface{}{"Total", roundMoney(branchProfit), branchCount, roundMoney(branchVolume), roundMoney(branchPct)})
+
+	byPair := profitExportSection{
+		Name:   "By Currency Pair",
+		Header: []interface{}{"Pair", "Profit", "Transactions", "Volume Base", "Volume Target", "Avg Rate", "Percentage"},
+	}
+	var pairProfit, pairPct float64
+	var pairCount int
+	for _, p := range result.ByCurrencyPair {
+		byPair.Rows = append(byPair.Rows, []interface{}{p.BaseCurrency + "/" + p.TargetCurrency, roundMoney(p.TotalProfitCAD),
+			p.TransactionCount, roundMoney(p.VolumeBase), roundMoney(p.VolumeTarget), p.AvgRate, roundMoney(p.Percentage)})
+		pairProfit += p.TotalProfitCAD
+		pairCount += p.TransactionCount
+		pairPct += p.Percentage
+	}
+	// Volumes are in different currencies per pair, so they are not totalled
+	byPair.Rows = append(byPair.Rows, []interface{}{"Total", roundMoney(pairProfit), pairCount, nil, nil, nil, roundMoney(pairPct)})
+
+	bySegment := profitExportSection{
+		Name:   "By Customer Segment",
+		Header: []interface{}{"Segment", "Customers", "Profit", "Transactions", "Avg Profit per Txn", "Percentage"},
+	}
+	var segmentProfit, segmentPct float64
+	var segmentCustomers, segmentCount int
+	for _, s := range result.ByCustomerSegment {
+		bySegment.Rows = append(bySegment.Rows, []interface{}{s.Segment, s.CustomerCount, roundMoney(s.TotalProfitCAD),
+			s.TransactionCount, roundMoney(s.AvgProfitPerTxn), roundMoney(s.Percentage)})
+		segmentCustomers += s.CustomerCount
+		segmentProfit += s.TotalProfitCAD
+		segmentCount += s.TransactionCount
+		segmentPct += s.Percentage
+	}
+	var segmentAvg float64
+	if segmentCount > 0 {
+		segmentAvg = segmentProfit / float64(segmentCount)
+	}
+	bySegment.Rows = append(bySegment.Rows, []interface{}{"Total", segmentCustomers, roundMoney(segmentProfit),
+		segmentCount, roundMoney(segmentAvg), roundMoney(segmentPct)})
+
+	return []profitExportSection{summary, byPeriod, byBranch, byPair, bySegment}
+}
+
+// WriteProfitAnalysisXLSX writes the profit analysis as an Excel workbook with one sheet per breakdown
+func WriteProfitAnalysisXLSX(w io.Writer, result *ProfitAnalysisResult) error {
+	var sheets []xlsxSheet
+	for _, section := range profitExportSections(result) {
+		rows := append([][]interface{}{section.Header}, section.Rows...)
+		sheets = append(sheets, xlsxSheet{Name: section.Name, Rows: rows})
+	}
+	return writeXLSX(w, sheets)
+}
+
+// WriteProfitAnalysisCSV writes the profit analysis as a single CSV with a titled section per breakdown
+func WriteProfitAnalysisCSV(w io.Writer, result *ProfitAnalysisResult) error {
+	writer := csv.NewWriter(w)
+	for i, section := range profitExportSections(result) {
+		if i > 0 {
+			if err := writer.Write([]string{}); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write([]string{section.Name}); err != nil {
+			return err
+		}
+		if err := writer.Write(csvRecord(section.Header)); err != nil {
+			return err
+		}
+		for _, row := range section.Rows {
+			if err := writer.Write(csvRecord(row)); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvRecord formats a row of cell values as CSV fields
+func csvRecord(row []interface{}) []string {
+	record := make([]string, len(row))
+	for i, value := range row {
+		if number, ok := xlsxNumber(value); ok {
+			record[i] = number
+		} else if value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return record
+}
+
+// roundMoney rounds a value to two decimal places for display
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"api/pkg/models"
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProfitAnalysisExport verifies the workbook has one sheet per breakdown with a totals row,
+// and the CSV carries the same sections
+func TestProfitAnalysisExport(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.ExchangeRate{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "Export Exchange")
+	downtown := &models.Branch{TenantID: tenant.ID, Name: "Downtown", BranchCode: "DT"}
+	db.Create(downtown)
+	airport := &models.Branch{TenantID: tenant.ID, Name: "Airport", BranchCode: "AP"}
+	db.Create(airport)
+	client := &models.Client{ID: "client-export", TenantID: tenant.ID, Name: "Export Client", PhoneNumber: "+14165550124"}
+	db.Create(client)
+
+	date := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	seq := 0
+	create := func(branchID uint, profit float64) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-export-%d", seq),
+			TenantID:        tenant.ID,
+			BranchID:        &branchID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			FeeCharged:      models.Zero(),
+			Status:          models.StatusCompleted,
+			TransactionDate: date,
+		})
+	}
+	create(downtown.ID, 50)
+	create(downtown.ID, 30)
+	create(airport.ID, 20)
+
+	result, err := NewProfitAnalysisService(db).GetProfitAnalysis(tenant.ID, nil, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1), "CAD")
+	if err != nil {
+		t.Fatalf("Profit analysis failed: %v", err)
+	}
+
+	var workbook bytes.Buffer
+	if err := WriteProfitAnalysisXLSX(&workbook, result); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(workbook.Bytes()), int64(workbook.Len()))
+	if err != nil {
+		t.Fatalf("Workbook is not a valid zip archive: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var book struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(files["xl/workbook.xml"], &book); err != nil {
+		t.Fatalf("Failed to parse workbook.xml: %v", err)
+	}
+	var names []string
+	for _, sheet := range book.Sheets {
+		names = append(names, sheet.Name)
+	}
+	expected := "Summary,By Period,By Branch,By Currency Pair,By Customer Segment"
+	if strings.Join(names, ",") != expected {
+		t.Fatalf("Expected sheets %s, got %v", expected, names)
+	}
+
+	// By Branch is the third sheet: header, Downtown, Airport, then the totals row
+	var worksheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(files["xl/worksheets/sheet3.xml"], &worksheet); err != nil {
+		t.Fatalf("Failed to parse By Branch sheet: %v", err)
+	}
+	cells := make(map[string]string)
+	for _, row := range worksheet.Rows {
+		for _, cell := range row.Cells {
+			cells[cell.Ref] = cell.Value + cell.Inline
+		}
+	}
+	if cells["A2"] != "Downtown" || cells["B2"] != "80" {
+		t.Errorf("Expected Downtown with 80 profit on row 2, got %s / %s", cells["A2"], cells["B2"])
+	}
+	if cells["A4"] != "Total" || cells["B4"] != "100" || cells["C4"] != "3" {
+		t.Errorf("Expected totals row Total / 100 / 3, got %s / %s / %s", cells["A4"], cells["B4"], cells["C4"])
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteProfitAnalysisCSV(&csvOut, result); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	for _, want := range []string{"\nBy Branch\n", "\nBy Customer Segment\n", "Total,100,3,3000,100\n"} {
+		if !strings.Contains(csvOut.String(), want) {
+			t.Errorf("Expected CSV to contain %q, got:\n%s", want, csvOut.String())
+		}
+	}
+}
@@ -2,11 +2,31 @@ package services
 
 import (
 	"api/pkg/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// profitCountedSQL is the condition for transactions whose profit has been realized: completed and, for
+// multi-payment transactions, fully paid (as models.Transaction.IsCompleted), and not deleted. Cancelled
+// transactions and ones still awaiting payment keep their Profit value, so every profit query must apply it,
+// including raw SQL that bypasses the soft-delete scope. table qualifies the columns for joined queries.
+func profitCountedSQL(table string) string {
+	column := func(name string) string {
+		if table == "" {
+			return name
+		}
+		return table + "." + name
+	}
+	return fmt.Sprintf("%s = '%s' AND %s IS NULL AND COALESCE(%s, '') IN ('', '%s', '%s')",
+		column("status"), models.StatusCompleted, column("deleted_at"),
+		column("payment_status"), models.PaymentStatusSingle, models.PaymentStatusFullyPaid)
+}
+
 // ProfitAnalysisService provides detailed profit/loss analysis
 type ProfitAnalysisService struct {
 	db *gorm.DB
@@ -48,6 +68,25 @@ type ProfitByCurrencyPair struct {
 	Percentage       float64 `json:"percentage"`
 }
 
+// ProfitByPaymentMethod represents profit data for a payment channel (cash, bank transfer, ...)
+type ProfitByPaymentMethod struct {
+	PaymentMethod    string  `json:"paymentMethod"`
+	TotalProfitCAD   float64 `json:"totalProfitCad"`
+	TransactionCount int     `json:"transactionCount"`
+	Volume           float64 `json:"volume"` // Sum of send amounts
+	Percentage       float64 `json:"percentage"`
+}
+
+// ProfitByAgent represents profit data for the staff member who entered the transactions
+type ProfitByAgent struct {
+	UserID           uint    `json:"userId"` // 0 for transactions recorded before the creator was tracked
+	AgentName        string  `json:"agentName"`
+	TotalProfitCAD   float64 `json:"totalProfitCad"`
+	TransactionCount int     `json:"transactionCount"`
+	Volume           float64 `json:"volume"` // Sum of send amounts
+	Percentage       float64 `json:"percentage"`
+}
+
 // ProfitByCustomerSegment represents profit by customer type
 type ProfitByCustomerSegment struct {
 	Segment          string  `json:"segment"` // VIP, Regular, New
@@ -79,12 +118,17 @@ type TrendComparison struct {
 
 // ProfitAnalysisResult is the comprehensive profit analysis result
 type ProfitAnalysisResult struct {
-	// Summary
+	// Summary; TotalProfitCAD and AvgProfitPerSettlement are expressed in BaseCurrency
+	BaseCurrency           string  `json:"baseCurrency"`
 	TotalProfitCAD         float64 `json:"totalProfitCad"`
 	TotalVolumeIRR         float64 `json:"totalVolumeIrr"`
 	TotalSettlements       int     `json:"totalSettlements"`
 	AvgProfitPerSettlement float64 `json:"avgProfitPerSettlement"`
 
+	// Transactions left out of TotalProfitCAD because no rate to BaseCurrency was available
+	UnconvertedTransactions int      `json:"unconvertedTransactions"`
+	UnconvertedCurrencies   []string `json:"unconvertedCurrencies"`
+
 	// Breakdowns
 	ByPeriod          []ProfitByPeriod          `json:"byPeriod"`
 	ByBranch          []ProfitByBranch          `json:"byBranch"`
@@ -95,24 +139,31 @@ type ProfitAnalysisResult struct {
 	RateSpread RateSpreadAnalysis `json:"rateSpread"`
 
 	// Trends
-	VsLastMonth TrendComparison `json:"vsLastMonth"`
-	VsLastYear  TrendComparison `json:"vsLastYear"`
+	VsLastMonth TrendComparison  `json:"vsLastMonth"`
+	VsLastYear  TrendComparison  `json:"vsLastYear"`
+	VsBaseline  *TrendComparison `json:"vsBaseline,omitempty"` // Only set when a custom baseline period is requested
 
 	// Period
 	StartDate time.Time `json:"startDate"`
 	EndDate   time.Time `json:"endDate"`
 }
 
-// GetProfitAnalysis returns comprehensive profit analysis
-func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint, startDate, endDate time.Time) (*ProfitAnalysisResult, error) {
+// GetProfitAnalysis returns comprehensive profit analysis. Profit totals are normalized to baseCurrency;
+// an empty baseCurrency uses the tenant's reporting currency.
+func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint, startDate, endDate time.Time, baseCurrency string) (*ProfitAnalysisResult, error) {
+	baseCurrency = strings.ToUpper(strings.TrimSpace(baseCurrency))
+	if baseCurrency == "" {
+		baseCurrency = NewTenantSettingsService(s.db).GetBaseCurrency(tenantID)
+	}
+
 	result := &ProfitAnalysisResult{
-		StartDate: startDate,
-		EndDate:   endDate,
+		BaseCurrency: baseCurrency,
+		StartDate:    startDate,
+		EndDate:      endDate,
 	}
 
 	// Get summary from transactions
 	var summaryResult struct {
-		TotalProfit     float64
 		TotalVolume     float64
 		SettlementCount int
 		AvgRate         float64
@@ -121,13 +172,13 @@ func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint,
 	// We use transactions table now
 	query := s.db.Model(&models.Transaction{}).
 		Select(`
-			COALESCE(SUM(profit + fee_charged), 0) as total_profit,
 			COALESCE(SUM(send_amount), 0) as total_volume,
 			COUNT(*) as settlement_count,
 			COALESCE(AVG(rate_applied), 0) as avg_rate
 		`).
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
 
 	if branchID != nil {
 		query = query.Where("branch_id = ?", *branchID)
@@ -135,11 +186,18 @@ func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint,
 
 	query.Scan(&summaryResult)
 
-	result.TotalProfitCAD = summaryResult.TotalProfit
+	normalized, err := s.normalizedProfit(tenantID, branchID, startDate, endDate, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	result.TotalProfitCAD = normalized.total.Round(2).Float64()
+	result.UnconvertedTransactions = normalized.unconverted
+	result.UnconvertedCurrencies = normalized.unconvertedCurrencies
 	result.TotalVolumeIRR = summaryResult.TotalVolume // Note: This might be mixed currencies if not filtered
 	result.TotalSettlements = summaryResult.SettlementCount
-	if result.TotalSettlements > 0 {
-		result.AvgProfitPerSettlement = result.TotalProfitCAD / float64(result.TotalSettlements)
+	if converted := result.TotalSettlements - result.UnconvertedTransactions; converted > 0 {
+		result.AvgProfitPerSettlement = normalized.total.Float64() / float64(converted)
 	}
 
 	// Rate spread analysis (Simplified for now as we aggregate mixed pairs)
@@ -151,13 +209,13 @@ func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint,
 	result.ByPeriod = s.GetProfitByPeriod(tenantID, branchID, startDate, endDate)
 
 	// Profit by branch (if branchID is set, this will just return the one branch, which is fine)
-	result.ByBranch = s.GetProfitByBranch(tenantID, branchID, startDate, endDate, result.TotalProfitCAD)
+	result.ByBranch = s.profitByBranch(tenantID, branchID, startDate, endDate, normalized)
 
 	// Profit by currency pair (from transactions)
 	result.ByCurrencyPair = s.GetProfitByCurrencyPair(tenantID, branchID, startDate, endDate)
 
 	// Customer segments
-	result.ByCustomerSegment = s.GetProfitByCustomerSegment(tenantID, branchID, startDate, endDate, result.TotalProfitCAD)
+	result.ByCustomerSegment = profitByCustomerSegment(normalized)
 
 	// Trends
 	result.VsLastMonth = s.GetTrendComparison(tenantID, branchID, startDate, endDate, "month")
@@ -166,6 +224,103 @@ func (s *ProfitAnalysisService) GetProfitAnalysis(tenantID uint, branchID *uint,
 	return result, nil
 }
 
+// normalizedProfitTotal is the profit of a set of transactions converted to one currency, in total and
+// broken down by branch (0 for unassigned transactions) and by client
+type normalizedProfitTotal struct {
+	total                 models.Decimal
+	unconverted           int
+	unconvertedCurrencies []string
+	byBranch              map[uint]models.Decimal
+	byClient              map[string]*clientProfit
+}
+
+// clientProfit is one client's normalized profit; txnCount includes transactions that could not be converted
+type clientProfit struct {
+	txnCount  int
+	converted int
+	total     models.Decimal
+}
+
+// normalizedProfit sums profit + fee of the completed transactions in the range, converting each from its
+// send currency to baseCurrency. The transaction's own rate is used when it received baseCurrency;
+// otherwise the exchange rate in effect on the transaction date is looked up.
+func (s *ProfitAnalysisService) normalizedProfit(tenantID uint, branchID *uint, startDate, endDate time.Time, baseCurrency string) (*normalizedProfitTotal, error) {
+	var transactions []struct {
+		BranchID        uint
+		ClientID        string
+		SendCurrency    string
+		ReceiveCurrency string
+		Profit          models.Decimal
+		FeeCharged      models.Decimal
+		RateApplied     models.Decimal
+		TransactionDate time.Time
+	}
+
+	query := s.db.Model(&models.Transaction{}).
+		Select("COALESCE(branch_id, 0) as branch_id, client_id, send_currency, receive_currency, profit, fee_charged, rate_applied, transaction_date").
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+	if err := query.Scan(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transaction profit: %w", err)
+	}
+
+	rateService := NewExchangeRateService(s.db)
+	result := &normalizedProfitTotal{
+		total:                 models.Zero(),
+		unconvertedCurrencies: make([]string, 0),
+		byBranch:              make(map[uint]models.Decimal),
+		byClient:              make(map[string]*clientProfit),
+	}
+	missing := make(map[string]bool)
+
+	for _, txn := range transactions {
+		client := result.byClient[txn.ClientID]
+		if client == nil {
+			client = &clientProfit{total: models.Zero()}
+			result.byClient[txn.ClientID] = client
+		}
+		client.txnCount++
+
+		profit := txn.Profit.Add(txn.FeeCharged)
+		currency := strings.ToUpper(txn.SendCurrency)
+
+		var rate models.Decimal
+		switch {
+		case currency == baseCurrency:
+			rate = models.NewDecimal(1)
+		case strings.ToUpper(txn.ReceiveCurrency) == baseCurrency && txn.RateApplied.IsPositive():
+			rate = txn.RateApplied
+		default:
+			historical, err := rateService.GetHistoricalRate(tenantID, currency, baseCurrency, txn.TransactionDate)
+			if err != nil {
+				result.unconverted++
+				if !missing[currency] {
+					missing[currency] = true
+					result.unconvertedCurrencies = append(result.unconvertedCurrencies, currency)
+				}
+				continue
+			}
+			rate = historical
+		}
+		converted := profit.Mul(rate)
+		result.total = result.total.Add(converted)
+		if branchTotal, ok := result.byBranch[txn.BranchID]; ok {
+			result.byBranch[txn.BranchID] = branchTotal.Add(converted)
+		} else {
+			result.byBranch[txn.BranchID] = converted
+		}
+		client.converted++
+		client.total = client.total.Add(converted)
+	}
+
+	sort.Strings(result.unconvertedCurrencies)
+	return result, nil
+}
+
 func (s *ProfitAnalysisService) GetProfitByPeriod(tenantID uint, branchID *uint, startDate, endDate time.Time) []ProfitByPeriod {
 	var results []struct {
 		Period          string
@@ -181,8 +336,9 @@ func (s *ProfitAnalysisService) GetProfitByPeriod(tenantID uint, branchID *uint,
 			COUNT(*) as settlement_count,
 			COALESCE(SUM(send_amount), 0) as volume
 		`).
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
 
 	if branchID != nil {
 		query = query.Where("branch_id = ?", *branchID)
@@ -205,11 +361,12 @@ func (s *ProfitAnalysisService) GetProfitByPeriod(tenantID uint, branchID *uint,
 	return periods
 }
 
-func (s *ProfitAnalysisService) GetProfitByBranch(tenantID uint, branchID *uint, startDate, endDate time.Time, totalProfit float64) []ProfitByBranch {
+// profitByBranch breaks the normalized profit down by branch; shares are of the normalized total, so
+// branches that trade in different currencies are compared in the same one
+func (s *ProfitAnalysisService) profitByBranch(tenantID uint, branchID *uint, startDate, endDate time.Time, normalized *normalizedProfitTotal) []ProfitByBranch {
 	var results []struct {
 		BranchID        uint
 		BranchName      string
-		TotalProfit     float64
 		SettlementCount int
 		Volume          float64
 	}
@@ -218,37 +375,44 @@ func (s *ProfitAnalysisService) GetProfitByBranch(tenantID uint, branchID *uint,
 		Select(`
 			COALESCE(transactions.branch_id, 0) as branch_id,
 			COALESCE(branches.name, 'Unassigned') as branch_name,
-			COALESCE(SUM(transactions.profit + transactions.fee_charged), 0) as total_profit,
 			COUNT(*) as settlement_count,
 			COALESCE(SUM(transactions.send_amount), 0) as volume
 		`).
 		Joins("LEFT JOIN branches ON transactions.branch_id = branches.id").
-		Where("transactions.tenant_id = ? AND transactions.status = ? AND transactions.transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("transactions.tenant_id = ? AND transactions.transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL("transactions"))
 
 	if branchID != nil {
 		query = query.Where("transactions.branch_id = ?", *branchID)
 	}
 
 	query.Group("transactions.branch_id, branches.name").
-		Order("total_profit DESC").
 		Scan(&results)
 
+	totalProfit := normalized.total.Float64()
 	branches := make([]ProfitByBranch, len(results))
 	for i, r := range results {
+		profit := 0.0
+		if branchTotal, ok := normalized.byBranch[r.BranchID]; ok {
+			profit = branchTotal.Round(2).Float64()
+		}
 		pct := 0.0
 		if totalProfit > 0 {
-			pct = (r.TotalProfit / totalProfit) * 100
+			pct = (profit / totalProfit) * 100
 		}
 		branches[i] = ProfitByBranch{
 			BranchID:        r.BranchID,
 			BranchName:      r.BranchName,
-			TotalProfitCAD:  r.TotalProfit,
+			TotalProfitCAD:  profit,
 			SettlementCount: r.SettlementCount,
 			VolumeIRR:       r.Volume,
 			Percentage:      pct,
 		}
 	}
+	sort.SliceStable(branches, func(i, j int) bool {
+		return branches[i].TotalProfitCAD > branches[j].TotalProfitCAD
+	})
 	return branches
 }
 
@@ -273,8 +437,9 @@ func (s *ProfitAnalysisService) GetProfitByCurrencyPair(tenantID uint, branchID
 			COALESCE(SUM(receive_amount), 0) as volume_receive,
 			COALESCE(AVG(rate_applied), 0) as avg_rate
 		`).
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
 
 	if branchID != nil {
 		query = query.Where("branch_id = ?", *branchID)
@@ -309,68 +474,155 @@ func (s *ProfitAnalysisService) GetProfitByCurrencyPair(tenantID uint, branchID
 	return pairs
 }
 
-func (s *ProfitAnalysisService) GetProfitByCustomerSegment(tenantID uint, branchID *uint, startDate, endDate time.Time, totalProfit float64) []ProfitByCustomerSegment {
+// GetProfitByPaymentMethod groups completed transactions by payment method
+func (s *ProfitAnalysisService) GetProfitByPaymentMethod(tenantID uint, branchID *uint, startDate, endDate time.Time) []ProfitByPaymentMethod {
 	var results []struct {
-		Segment     string
-		CustCount   int
+		PaymentMethod string
+		TotalProfit   float64
+		TxnCount      int
+		Volume        float64
+	}
+
+	query := s.db.Model(&models.Transaction{}).
+		Select(`
+			payment_method,
+			COALESCE(SUM(profit + fee_charged), 0) as total_profit,
+			COUNT(*) as txn_count,
+			COALESCE(SUM(send_amount), 0) as volume
+		`).
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
+
+	if branchID != nil {
+		query = query.Where("branch_id = ?", *branchID)
+	}
+
+	query.Group("payment_method").
+		Order("total_profit DESC").
+		Scan(&results)
+
+	totalProfit := 0.0
+	for _, r := range results {
+		totalProfit += r.TotalProfit
+	}
+
+	methods := make([]ProfitByPaymentMethod, len(results))
+	for i, r := range results {
+		pct := 0.0
+		if totalProfit > 0 {
+			pct = (r.TotalProfit / totalProfit) * 100
+		}
+		methods[i] = ProfitByPaymentMethod{
+			PaymentMethod:    r.PaymentMethod,
+			TotalProfitCAD:   r.TotalProfit,
+			TransactionCount: r.TxnCount,
+			Volume:           r.Volume,
+			Percentage:       pct,
+		}
+	}
+	return methods
+}
+
+// GetProfitByAgent groups completed transactions by the user who created them, for commission reports
+func (s *ProfitAnalysisService) GetProfitByAgent(tenantID uint, branchID *uint, startDate, endDate time.Time) []ProfitByAgent {
+	var results []struct {
+		UserID      uint
+		AgentName   string
 		TotalProfit float64
 		TxnCount    int
+		Volume      float64
 	}
 
-	segmentSQL := `
-		WITH customer_stats AS (
-			SELECT 
-				client_id,
-				COUNT(*) as txn_count,
-				SUM(profit + fee_charged) as total_gain
-			FROM transactions 
-			WHERE tenant_id = ? AND status = 'COMPLETED' AND transaction_date BETWEEN ? AND ?
-	`
-	args := []interface{}{tenantID, startDate, endDate}
+	query := s.db.Model(&models.Transaction{}).
+		Select(`
+			COALESCE(transactions.created_by, 0) as user_id,
+			COALESCE(users.username, users.email, 'Unattributed') as agent_name,
+			COALESCE(SUM(transactions.profit + transactions.fee_charged), 0) as total_profit,
+			COUNT(*) as txn_count,
+			COALESCE(SUM(transactions.send_amount), 0) as volume
+		`).
+		Joins("LEFT JOIN users ON transactions.created_by = users.id").
+		Where("transactions.tenant_id = ? AND transactions.transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL("transactions"))
 
 	if branchID != nil {
-		segmentSQL += " AND branch_id = ?"
-		args = append(args, *branchID)
-	}
-
-	segmentSQL += `
-			GROUP BY client_id
-		)
-		SELECT 
-			CASE 
-				WHEN txn_count >= 10 THEN 'VIP'
-				WHEN txn_count >= 3 THEN 'Regular'
-				ELSE 'New'
-			END as segment,
-			COUNT(DISTINCT client_id) as cust_count,
-			SUM(total_gain) as total_profit,
-			SUM(txn_count) as txn_count
-		FROM customer_stats
-		GROUP BY segment
-		ORDER BY total_profit DESC
-	`
-
-	s.db.Raw(segmentSQL, args...).Scan(&results)
-
-	segments := make([]ProfitByCustomerSegment, len(results))
+		query = query.Where("transactions.branch_id = ?", *branchID)
+	}
+
+	query.Group("transactions.created_by, users.username, users.email").
+		Order("total_profit DESC").
+		Scan(&results)
+
+	totalProfit := 0.0
+	for _, r := range results {
+		totalProfit += r.TotalProfit
+	}
+
+	agents := make([]ProfitByAgent, len(results))
 	for i, r := range results {
-		avgProfit := 0.0
-		if r.TxnCount > 0 {
-			avgProfit = r.TotalProfit / float64(r.TxnCount)
-		}
 		pct := 0.0
 		if totalProfit > 0 {
 			pct = (r.TotalProfit / totalProfit) * 100
 		}
-		segments[i] = ProfitByCustomerSegment{
-			Segment:          r.Segment,
-			CustomerCount:    r.CustCount,
+		agents[i] = ProfitByAgent{
+			UserID:           r.UserID,
+			AgentName:        r.AgentName,
 			TotalProfitCAD:   r.TotalProfit,
 			TransactionCount: r.TxnCount,
-			AvgProfitPerTxn:  avgProfit,
+			Volume:           r.Volume,
 			Percentage:       pct,
 		}
 	}
+	return agents
+}
+
+// profitByCustomerSegment groups clients by how often they transacted in the period (VIP: 10+, Regular: 3+,
+// otherwise New) and sums their normalized profit
+func profitByCustomerSegment(normalized *normalizedProfitTotal) []ProfitByCustomerSegment {
+	bySegment := make(map[string]*ProfitByCustomerSegment)
+	totals := make(map[string]models.Decimal)
+	converted := make(map[string]int)
+
+	for _, client := range normalized.byClient {
+		segment := "New"
+		switch {
+		case client.txnCount >= 10:
+			segment = "VIP"
+		case client.txnCount >= 3:
+			segment = "Regular"
+		}
+		r := bySegment[segment]
+		if r == nil {
+			r = &ProfitByCustomerSegment{Segment: segment}
+			bySegment[segment] = r
+			totals[segment] = models.Zero()
+		}
+		r.CustomerCount++
+		r.TransactionCount += client.txnCount
+		totals[segment] = totals[segment].Add(client.total)
+		converted[segment] += client.converted
+	}
+
+	totalProfit := normalized.total.Float64()
+	segments := make([]ProfitByCustomerSegment, 0, len(bySegment))
+	for segment, r := range bySegment {
+		r.TotalProfitCAD = totals[segment].Round(2).Float64()
+		if converted[segment] > 0 {
+			r.AvgProfitPerTxn = totals[segment].Float64() / float64(converted[segment])
+		}
+		if totalProfit > 0 {
+			r.Percentage = (totals[segment].Float64() / totalProfit) * 100
+		}
+		segments = append(segments, *r)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		if segments[i].TotalProfitCAD != segments[j].TotalProfitCAD {
+			return segments[i].TotalProfitCAD > segments[j].TotalProfitCAD
+		}
+		return segments[i].Segment < segments[j].Segment
+	})
 	return segments
 }
 
@@ -386,12 +638,24 @@ func (s *ProfitAnalysisService) GetTrendComparison(tenantID uint, branchID *uint
 		prevEnd = endDate.AddDate(-1, 0, 0)
 	}
 
+	return s.compareProfit(tenantID, branchID, startDate, endDate, prevStart, prevEnd)
+}
+
+// GetBaselineComparison compares the period's profit to an arbitrary baseline period,
+// e.g. this quarter against a specific past quarter
+func (s *ProfitAnalysisService) GetBaselineComparison(tenantID uint, branchID *uint, startDate, endDate, baselineStart, baselineEnd time.Time) TrendComparison {
+	return s.compareProfit(tenantID, branchID, startDate, endDate, baselineStart, baselineEnd)
+}
+
+// compareProfit compares the profit of the current period to that of the previous one
+func (s *ProfitAnalysisService) compareProfit(tenantID uint, branchID *uint, startDate, endDate, prevStart, prevEnd time.Time) TrendComparison {
 	// Current period profit
 	var currentProfit float64
 	queryCurr := s.db.Model(&models.Transaction{}).
 		Select("COALESCE(SUM(profit + fee_charged), 0)").
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
 	
 	if branchID != nil {
 		queryCurr = queryCurr.Where("branch_id = ?", *branchID)
@@ -402,8 +666,9 @@ func (s *ProfitAnalysisService) GetTrendComparison(tenantID uint, branchID *uint
 	var previousProfit float64
 	queryPrev := s.db.Model(&models.Transaction{}).
 		Select("COALESCE(SUM(profit + fee_charged), 0)").
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, prevStart, prevEnd)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, prevStart, prevEnd).
+		Where(profitCountedSQL(""))
 	
 	if branchID != nil {
 		queryPrev = queryPrev.Where("branch_id = ?", *branchID)
@@ -432,6 +697,60 @@ func (s *ProfitAnalysisService) GetDailyProfit(tenantID uint, branchID *uint, da
 	return s.GetProfitByPeriod(tenantID, branchID, startDate, endDate), nil
 }
 
+// ProfitMovingAverage is one day's profit with its trailing moving average
+type ProfitMovingAverage struct {
+	Date          string  `json:"date"`
+	Profit        float64 `json:"profit"`
+	MovingAverage float64 `json:"movingAverage"`
+	WindowDays    int     `json:"windowDays"` // Days actually averaged; fewer than the window at the start of the range
+}
+
+// GetProfitMovingAverage returns the daily profit for the last `days` days, oldest first, with the
+// average of each day and the window-1 days before it. Days without transactions count as zero profit.
+func (s *ProfitAnalysisService) GetProfitMovingAverage(tenantID uint, branchID *uint, window, days int) ([]ProfitMovingAverage, error) {
+	return s.profitMovingAverage(tenantID, branchID, window, days, time.Now())
+}
+
+func (s *ProfitAnalysisService) profitMovingAverage(tenantID uint, branchID *uint, window, days int, now time.Time) ([]ProfitMovingAverage, error) {
+	if window < 1 {
+		return nil, errors.New("window must be at least 1 day")
+	}
+	if days < 1 {
+		return nil, errors.New("days must be at least 1")
+	}
+
+	startDate := startOfDay(now).AddDate(0, 0, -(days - 1))
+	daily := make(map[string]float64)
+	for _, period := range s.GetProfitByPeriod(tenantID, branchID, startDate, now) {
+		daily[period.Period] = period.TotalProfitCAD
+	}
+
+	series := make([]ProfitMovingAverage, days)
+	sum := 0.0
+	for i := range series {
+		date := startDate.AddDate(0, 0, i).Format("2006-01-02")
+		profit := daily[date]
+
+		// Only the days within the range are averaged, so early points use a shorter window
+		sum += profit
+		if i >= window {
+			sum -= series[i-window].Profit
+		}
+		averaged := window
+		if i+1 < window {
+			averaged = i + 1
+		}
+
+		series[i] = ProfitMovingAverage{
+			Date:          date,
+			Profit:        profit,
+			MovingAverage: sum / float64(averaged),
+			WindowDays:    averaged,
+		}
+	}
+	return series, nil
+}
+
 // GetMonthlyProfit returns monthly profit summary
 func (s *ProfitAnalysisService) GetMonthlyProfit(tenantID uint, branchID *uint, months int) ([]ProfitByPeriod, error) {
 	var results []struct {
@@ -451,8 +770,9 @@ func (s *ProfitAnalysisService) GetMonthlyProfit(tenantID uint, branchID *uint,
 			COUNT(*) as settlement_count,
 			COALESCE(SUM(send_amount), 0) as volume
 		`).
-		Where("tenant_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("tenant_id = ? AND transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL(""))
 
 	if branchID != nil {
 		query = query.Where("branch_id = ?", *branchID)
@@ -500,8 +820,9 @@ func (s *ProfitAnalysisService) GetTopCustomers(tenantID uint, branchID *uint, l
 			COUNT(*) as txn_count
 		`).
 		Joins("LEFT JOIN clients ON transactions.client_id = clients.id").
-		Where("transactions.tenant_id = ? AND transactions.status = ? AND transactions.transaction_date BETWEEN ? AND ?",
-			tenantID, models.StatusCompleted, startDate, endDate)
+		Where("transactions.tenant_id = ? AND transactions.transaction_date BETWEEN ? AND ?",
+			tenantID, startDate, endDate).
+		Where(profitCountedSQL("transactions"))
 
 	if branchID != nil {
 		query = query.Where("transactions.branch_id = ?", *branchID)
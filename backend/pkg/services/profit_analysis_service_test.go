@@ -0,0 +1,507 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestGetProfitByPaymentMethod verifies each payment method reports its own profit, volume and count
+func TestGetProfitByPaymentMethod(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Channel Exchange")
+	client := &models.Client{ID: "client-channel", TenantID: tenant.ID, Name: "Channel Client", PhoneNumber: "+14165550122"}
+	db.Create(client)
+
+	date := time.Date(2025, 5, 15, 12, 0, 0, 0, time.UTC)
+	seq := 0
+	create := func(method, status string, amount, profit, fee float64) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-channel-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   method,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(amount),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(amount * 0.73),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			FeeCharged:      models.NewDecimal(fee),
+			Status:          status,
+			TransactionDate: date,
+		})
+	}
+
+	create(models.TransactionMethodCash, models.StatusCompleted, 1000, 20, 5)
+	create(models.TransactionMethodCash, models.StatusCompleted, 500, 10, 5)
+	create(models.TransactionMethodCash, models.StatusCancelled, 9000, 200, 0) // ignored
+	create(models.TransactionMethodBank, models.StatusCompleted, 3000, 45, 15)
+
+	result := NewProfitAnalysisService(db).GetProfitByPaymentMethod(tenant.ID, nil, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1))
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 payment methods, got %d", len(result))
+	}
+
+	byMethod := make(map[string]ProfitByPaymentMethod)
+	for _, method := range result {
+		byMethod[method.PaymentMethod] = method
+	}
+
+	cash := byMethod[models.TransactionMethodCash]
+	if cash.TransactionCount != 2 || cash.TotalProfitCAD != 40 || cash.Volume != 1500 {
+		t.Errorf("Expected cash 2 txns / 40 profit / 1500 volume, got %+v", cash)
+	}
+	bank := byMethod[models.TransactionMethodBank]
+	if bank.TransactionCount != 1 || bank.TotalProfitCAD != 60 || bank.Volume != 3000 {
+		t.Errorf("Expected bank 1 txn / 60 profit / 3000 volume, got %+v", bank)
+	}
+	if math.Abs(cash.Percentage-40) > 1e-9 || math.Abs(bank.Percentage-60) > 1e-9 {
+		t.Errorf("Expected 40%%/60%% split, got %v/%v", cash.Percentage, bank.Percentage)
+	}
+}
+
+// TestGetProfitAnalysis_NormalizesToBaseCurrency verifies mixed send currencies are converted before summing
+func TestGetProfitAnalysis_NormalizesToBaseCurrency(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.ExchangeRate{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "Base Currency Exchange")
+	client := &models.Client{ID: "client-base", TenantID: tenant.ID, Name: "Base Client", PhoneNumber: "+14165550123"}
+	db.Create(client)
+
+	date := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "USD",
+		TargetCurrency: "CAD",
+		Rate:           models.NewDecimal(1.35),
+		Source:         "MANUAL",
+		CreatedAt:      date.AddDate(0, 0, -1),
+	})
+
+	create := func(id, sendCurrency, receiveCurrency string, rate, profit float64) {
+		db.Create(&models.Transaction{
+			ID:              id,
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    sendCurrency,
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: receiveCurrency,
+			ReceiveAmount:   models.NewDecimal(1000 * rate),
+			RateApplied:     models.NewDecimal(rate),
+			Profit:          models.NewDecimal(profit),
+			Status:          models.StatusCompleted,
+			TransactionDate: date,
+		})
+	}
+
+	create("txn-base-cad", "CAD", "IRR", 45000, 20)
+	create("txn-base-usd", "USD", "IRR", 60000, 10) // converted with the USD/CAD lookup
+	create("txn-base-eur", "EUR", "CAD", 1.5, 10)   // converted with its own rate
+	create("txn-base-gbp", "GBP", "IRR", 70000, 50) // no GBP rate on file
+
+	result, err := NewProfitAnalysisService(db).GetProfitAnalysis(tenant.ID, nil, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1), "cad")
+	if err != nil {
+		t.Fatalf("Failed to get profit analysis: %v", err)
+	}
+
+	naive := 20.0 + 10.0 + 10.0
+	expected := 20 + 10*1.35 + 10*1.5
+	if result.BaseCurrency != "CAD" {
+		t.Errorf("Expected base currency CAD, got %s", result.BaseCurrency)
+	}
+	if math.Abs(result.TotalProfitCAD-expected) > 1e-9 {
+		t.Errorf("Expected normalized profit %v, got %v", expected, result.TotalProfitCAD)
+	}
+	if math.Abs(result.TotalProfitCAD-naive) < 1e-9 {
+		t.Errorf("Normalized profit should differ from the naive sum %v", naive)
+	}
+	if result.UnconvertedTransactions != 1 || len(result.UnconvertedCurrencies) != 1 || result.UnconvertedCurrencies[0] != "GBP" {
+		t.Errorf("Expected GBP transaction to be reported as unconverted, got %d %v",
+			result.UnconvertedTransactions, result.UnconvertedCurrencies)
+	}
+	if math.Abs(result.AvgProfitPerSettlement-expected/3) > 1e-9 {
+		t.Errorf("Expected average over converted settlements %v, got %v", expected/3, result.AvgProfitPerSettlement)
+	}
+}
+
+// TestGetProfitAnalysis_NormalizesBreakdowns verifies that branch and customer segment shares are computed
+// from profit converted to the base currency, not from raw amounts in mixed currencies
+func TestGetProfitAnalysis_NormalizesBreakdowns(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.ExchangeRate{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "Breakdown Exchange")
+	toronto := newTestBranch(t, db, tenant.ID, "Toronto", "TOR")
+	newYork := newTestBranch(t, db, tenant.ID, "New York", "NYC")
+	regular := &models.Client{ID: "client-regular", TenantID: tenant.ID, Name: "Regular Client", PhoneNumber: "+14165550124"}
+	newcomer := &models.Client{ID: "client-new", TenantID: tenant.ID, Name: "New Client", PhoneNumber: "+14165550125"}
+	db.Create(regular)
+	db.Create(newcomer)
+
+	date := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "USD",
+		TargetCurrency: "CAD",
+		Rate:           models.NewDecimal(1.35),
+		Source:         "MANUAL",
+		CreatedAt:      date.AddDate(0, 0, -1),
+	})
+
+	create := func(id string, branchID uint, clientID, sendCurrency string, profit float64) {
+		db.Create(&models.Transaction{
+			ID:              id,
+			TenantID:        tenant.ID,
+			BranchID:        &branchID,
+			ClientID:        clientID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    sendCurrency,
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "IRR",
+			ReceiveAmount:   models.NewDecimal(1000 * 50000),
+			RateApplied:     models.NewDecimal(50000),
+			Profit:          models.NewDecimal(profit),
+			Status:          models.StatusCompleted,
+			TransactionDate: date,
+		})
+	}
+
+	// Toronto: 3 x 10 CAD from the regular client; New York: 25 USD (33.75 CAD) from the new client
+	create("txn-brk-tor-1", toronto.ID, regular.ID, "CAD", 10)
+	create("txn-brk-tor-2", toronto.ID, regular.ID, "CAD", 10)
+	create("txn-brk-tor-3", toronto.ID, regular.ID, "CAD", 10)
+	create("txn-brk-nyc-1", newYork.ID, newcomer.ID, "USD", 25)
+
+	result, err := NewProfitAnalysisService(db).GetProfitAnalysis(tenant.ID, nil, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1), "CAD")
+	if err != nil {
+		t.Fatalf("Failed to get profit analysis: %v", err)
+	}
+
+	total := 30 + 25*1.35
+	if len(result.ByBranch) != 2 {
+		t.Fatalf("Expected 2 branches, got %d", len(result.ByBranch))
+	}
+	nyc, tor := result.ByBranch[0], result.ByBranch[1]
+	if nyc.BranchID != newYork.ID {
+		t.Errorf("Expected the USD branch first once converted, got %s", nyc.BranchName)
+	}
+	if math.Abs(nyc.TotalProfitCAD-25*1.35) > 1e-9 || math.Abs(nyc.Percentage-25*1.35/total*100) > 1e-9 {
+		t.Errorf("New York: expected %v (%v%%), got %v (%v%%)", 25*1.35, 25*1.35/total*100, nyc.TotalProfitCAD, nyc.Percentage)
+	}
+	if math.Abs(tor.TotalProfitCAD-30) > 1e-9 || math.Abs(tor.Percentage-30/total*100) > 1e-9 {
+		t.Errorf("Toronto: expected 30 (%v%%), got %v (%v%%)", 30/total*100, tor.TotalProfitCAD, tor.Percentage)
+	}
+
+	if len(result.ByCustomerSegment) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(result.ByCustomerSegment))
+	}
+	newSegment, regularSegment := result.ByCustomerSegment[0], result.ByCustomerSegment[1]
+	if newSegment.Segment != "New" || math.Abs(newSegment.TotalProfitCAD-25*1.35) > 1e-9 ||
+		math.Abs(newSegment.Percentage-25*1.35/total*100) > 1e-9 {
+		t.Errorf("Expected New segment with %v (%v%%), got %+v", 25*1.35, 25*1.35/total*100, newSegment)
+	}
+	if regularSegment.Segment != "Regular" || regularSegment.CustomerCount != 1 || regularSegment.TransactionCount != 3 {
+		t.Errorf("Expected Regular segment with 1 customer and 3 transactions, got %+v", regularSegment)
+	}
+	if math.Abs(regularSegment.AvgProfitPerTxn-10) > 1e-9 || math.Abs(regularSegment.Percentage-30/total*100) > 1e-9 {
+		t.Errorf("Regular: expected 10 per transaction (%v%%), got %v (%v%%)", 30/total*100, regularSegment.AvgProfitPerTxn, regularSegment.Percentage)
+	}
+}
+
+// TestGetProfitMovingAverage verifies the trailing average of a known daily series, including the
+// shorter windows at the start of the range and days without transactions
+func TestGetProfitMovingAverage(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Trend Exchange")
+	client := &models.Client{ID: "client-trend", TenantID: tenant.ID, Name: "Trend Client", PhoneNumber: "+14165550124"}
+	db.Create(client)
+
+	now := time.Date(2025, 8, 5, 18, 0, 0, 0, time.UTC)
+	// Daily profit for Aug 1-5 is 10, 20, 0 (no transactions), 40, 50; Aug 2 is split across two transactions
+	seq := 0
+	create := func(day int, profit float64) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-trend-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			Status:          models.StatusCompleted,
+			TransactionDate: time.Date(2025, 8, day, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	create(1, 10)
+	create(2, 15)
+	create(2, 5)
+	create(4, 40)
+	create(5, 50)
+
+	service := NewProfitAnalysisService(db)
+	series, err := service.profitMovingAverage(tenant.ID, nil, 3, 5, now)
+	if err != nil {
+		t.Fatalf("Failed to get moving average: %v", err)
+	}
+
+	expected := []struct {
+		date    string
+		profit  float64
+		average float64
+		window  int
+	}{
+		{"2025-08-01", 10, 10, 1},
+		{"2025-08-02", 20, 15, 2},
+		{"2025-08-03", 0, 10, 3},
+		{"2025-08-04", 40, 20, 3},
+		{"2025-08-05", 50, 30, 3},
+	}
+	if len(series) != len(expected) {
+		t.Fatalf("Expected %d points, got %d", len(expected), len(series))
+	}
+	for i, want := range expected {
+		got := series[i]
+		if got.Date != want.date || got.Profit != want.profit || got.WindowDays != want.window ||
+			math.Abs(got.MovingAverage-want.average) > 1e-9 {
+			t.Errorf("Point %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	t.Run("WindowLargerThanRange", func(t *testing.T) {
+		series, err := service.profitMovingAverage(tenant.ID, nil, 30, 5, now)
+		if err != nil {
+			t.Fatalf("Failed to get moving average: %v", err)
+		}
+		last := series[len(series)-1]
+		if last.WindowDays != 5 || math.Abs(last.MovingAverage-24) > 1e-9 {
+			t.Errorf("Expected the 5 available days averaged to 24, got %+v", last)
+		}
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		if _, err := service.GetProfitMovingAverage(tenant.ID, nil, 0, 5); err == nil {
+			t.Error("Expected an error for a zero-day window")
+		}
+	})
+}
+
+// TestGetProfitByAgent verifies profit is split by the user who created each transaction
+func TestGetProfitByAgent(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Agent Exchange")
+	alice := newTestUser(t, db, tenant.ID, "alice@agent.test", models.RoleTenantUser)
+	bob := newTestUser(t, db, tenant.ID, "bob@agent.test", models.RoleTenantUser)
+	client := &models.Client{ID: "client-agent", TenantID: tenant.ID, Name: "Agent Client", PhoneNumber: "+14165550125"}
+	db.Create(client)
+
+	date := time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+	seq := 0
+	create := func(createdBy *uint, status string, profit, fee float64) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-agent-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			FeeCharged:      models.NewDecimal(fee),
+			Status:          status,
+			CreatedBy:       createdBy,
+			TransactionDate: date,
+		})
+	}
+
+	create(&alice.ID, models.StatusCompleted, 50, 10)
+	create(&alice.ID, models.StatusCompleted, 30, 0)
+	create(&alice.ID, models.StatusCancelled, 500, 0) // ignored
+	create(&bob.ID, models.StatusCompleted, 25, 5)
+	create(nil, models.StatusCompleted, 10, 0) // recorded before the creator was tracked
+
+	result := NewProfitAnalysisService(db).GetProfitByAgent(tenant.ID, nil, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1))
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 agents, got %d", len(result))
+	}
+
+	byUser := make(map[uint]ProfitByAgent)
+	for _, agent := range result {
+		byUser[agent.UserID] = agent
+	}
+
+	if a := byUser[alice.ID]; a.TransactionCount != 2 || a.TotalProfitCAD != 90 || a.AgentName != alice.Email || math.Abs(a.Percentage-90.0/130*100) > 1e-9 {
+		t.Errorf("Expected alice 2 txns / 90 of 130 profit, got %+v", a)
+	}
+	if b := byUser[bob.ID]; b.TransactionCount != 1 || b.TotalProfitCAD != 30 || b.AgentName != bob.Email || math.Abs(b.Percentage-30.0/130*100) > 1e-9 {
+		t.Errorf("Expected bob 1 txn / 30 of 130 profit, got %+v", b)
+	}
+	if u := byUser[0]; u.TransactionCount != 1 || u.AgentName != "Unattributed" {
+		t.Errorf("Expected an unattributed bucket, got %+v", u)
+	}
+}
+
+// TestGetBaselineComparison verifies a custom baseline period yields the change against exactly that period
+func TestGetBaselineComparison(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Baseline Exchange")
+	client := &models.Client{ID: "client-baseline", TenantID: tenant.ID, Name: "Baseline Client", PhoneNumber: "+14165550125"}
+	db.Create(client)
+
+	seq := 0
+	create := func(date time.Time, profit, fee float64) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-baseline-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			FeeCharged:      models.NewDecimal(fee),
+			Status:          models.StatusCompleted,
+			TransactionDate: date,
+		})
+	}
+
+	// Q2 2025 against Q4 2024; Q1 2025 sits between them and must not count
+	create(time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC), 100, 10)
+	create(time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC), 40, 0)
+	create(time.Date(2024, 11, 5, 12, 0, 0, 0, time.UTC), 80, 20)
+	create(time.Date(2025, 2, 14, 12, 0, 0, 0, time.UTC), 500, 0)
+
+	service := NewProfitAnalysisService(db)
+	comparison := service.GetBaselineComparison(tenant.ID, nil,
+		time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC),
+		time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+
+	if comparison.CurrentPeriodProfit != 150 || comparison.PreviousPeriodProfit != 100 {
+		t.Fatalf("Expected 150 against a 100 baseline, got %v against %v", comparison.CurrentPeriodProfit, comparison.PreviousPeriodProfit)
+	}
+	if comparison.ChangeAmount != 50 || math.Abs(comparison.ChangePercentage-50) > 1e-9 || !comparison.IsPositive {
+		t.Errorf("Expected +50 (+50%%), got %v (%v%%)", comparison.ChangeAmount, comparison.ChangePercentage)
+	}
+
+	t.Run("EmptyBaseline", func(t *testing.T) {
+		empty := service.GetBaselineComparison(tenant.ID, nil,
+			time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 30, 23, 59, 59, 0, time.UTC),
+			time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 3, 31, 23, 59, 59, 0, time.UTC))
+		if empty.ChangeAmount != 150 || empty.ChangePercentage != 0 {
+			t.Errorf("Expected +150 with no percentage against an empty baseline, got %v (%v%%)", empty.ChangeAmount, empty.ChangePercentage)
+		}
+	})
+}
+
+// TestGetProfitAnalysis_ExcludesReversedAndUnpaid verifies a reversed transaction drops out of every profit
+// breakdown once cancelled, and that multi-payment transactions only count once fully paid
+func TestGetProfitAnalysis_ExcludesReversedAndUnpaid(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Reversal Exchange")
+	agent := newTestUser(t, db, tenant.ID, "agent@reversal.test", models.RoleTenantUser)
+	branch := newTestBranch(t, db, tenant.ID, "Main", "M1")
+	client := &models.Client{ID: "client-reversal", TenantID: tenant.ID, Name: "Reversal Client", PhoneNumber: "+14165550133"}
+	db.Create(client)
+
+	date := time.Now().Add(-time.Hour)
+	create := func(id, paymentStatus string, profit float64) {
+		db.Create(&models.Transaction{
+			ID:              id,
+			TenantID:        tenant.ID,
+			BranchID:        &branch.ID,
+			CreatedBy:       &agent.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(profit),
+			PaymentStatus:   paymentStatus,
+			Status:          models.StatusCompleted,
+			TransactionDate: date,
+		})
+	}
+	create("txn-kept", models.PaymentStatusSingle, 30)
+	create("txn-reversed", models.PaymentStatusSingle, 70)
+	create("txn-paid-in-full", models.PaymentStatusFullyPaid, 5)
+	create("txn-awaiting-payment", models.PaymentStatusPartial, 500) // never counted until fully paid
+
+	service := NewProfitAnalysisService(db)
+	start, end := date.AddDate(0, 0, -1), date.AddDate(0, 0, 1)
+
+	// breakdownTotals returns the profit and transaction count reported by each breakdown
+	breakdownTotals := func() map[string][2]float64 {
+		t.Helper()
+		result, err := service.GetProfitAnalysis(tenant.ID, nil, start, end, "CAD")
+		if err != nil {
+			t.Fatalf("Failed to get profit analysis: %v", err)
+		}
+		totals := map[string][2]float64{
+			"summary": {result.TotalProfitCAD, float64(result.TotalSettlements)},
+		}
+		add := func(name string, profit float64, count int) {
+			total := totals[name]
+			totals[name] = [2]float64{total[0] + profit, total[1] + float64(count)}
+		}
+		for _, p := range result.ByPeriod {
+			add("period", p.TotalProfitCAD, p.SettlementCount)
+		}
+		for _, b := range result.ByBranch {
+			add("branch", b.TotalProfitCAD, b.SettlementCount)
+		}
+		for _, c := range result.ByCurrencyPair {
+			add("currencyPair", c.TotalProfitCAD, c.TransactionCount)
+		}
+		for _, s := range result.ByCustomerSegment {
+			add("customerSegment", s.TotalProfitCAD, s.TransactionCount)
+		}
+		for _, m := range service.GetProfitByPaymentMethod(tenant.ID, nil, start, end) {
+			add("paymentMethod", m.TotalProfitCAD, m.TransactionCount)
+		}
+		for _, a := range service.GetProfitByAgent(tenant.ID, nil, start, end) {
+			add("agent", a.TotalProfitCAD, a.TransactionCount)
+		}
+		monthly, _ := service.GetMonthlyProfit(tenant.ID, nil, 1)
+		for _, m := range monthly {
+			add("monthly", m.TotalProfitCAD, m.SettlementCount)
+		}
+		return totals
+	}
+	expectTotals := func(profit float64, count int) {
+		t.Helper()
+		totals := breakdownTotals()
+		for _, name := range []string{"summary", "period", "branch", "currencyPair", "customerSegment", "paymentMethod", "agent", "monthly"} {
+			got := totals[name]
+			if math.Abs(got[0]-profit) > 1e-9 || int(got[1]) != count {
+				t.Errorf("%s: expected profit %v over %d transactions, got %v over %v", name, profit, count, got[0], got[1])
+			}
+		}
+	}
+
+	expectTotals(105, 3)
+
+	db.Model(&models.Transaction{}).Where("id = ?", "txn-reversed").Update("status", models.StatusCancelled)
+	expectTotals(35, 2)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrBelowProfitFloor is returned when a transaction's margin is below its currency pair's floor
+	ErrBelowProfitFloor = errors.New("transaction margin is below the minimum profit floor for this currency pair")
+	// ErrProfitFloorOverrideForbidden is returned when someone other than an admin tries to override a profit floor
+	ErrProfitFloorOverrideForbidden = errors.New("only an owner or admin can override the profit floor")
+)
+
+// ProfitFloorService manages per-currency-pair minimum margins and enforces them on new transactions
+type ProfitFloorService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// NewProfitFloorService creates a new ProfitFloorService
+func NewProfitFloorService(db *gorm.DB) *ProfitFloorService {
+	return &ProfitFloorService{
+		db:           db,
+		auditService: NewAuditService(db),
+	}
+}
+
+// ListFloors returns the tenant's profit floors ordered by currency pair
+func (s *ProfitFloorService) ListFloors(tenantID uint) ([]models.CurrencyProfitFloor, error) {
+	var floors []models.CurrencyProfitFloor
+	err := s.db.Where("tenant_id = ?", tenantID).
+		Order("base_currency ASC, target_currency ASC").
+		Find(&floors).Error
+	return floors, err
+}
+
+// GetFloor returns the floor configured for the currency pair, or nil if the pair has none
+func (s *ProfitFloorService) GetFloor(tenantID uint, baseCurrency, targetCurrency string) (*models.CurrencyProfitFloor, error) {
+	var floor models.CurrencyProfitFloor
+	err := s.db.Where("tenant_id = ? AND base_currency = ? AND target_currency = ?",
+		tenantID, strings.ToUpper(baseCurrency), strings.ToUpper(targetCurrency)).
+		First(&floor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &floor, nil
+}
+
+// SetFloor creates or replaces the floor for a currency pair
+func (s *ProfitFloorService) SetFloor(tenantID uint, baseCurrency, targetCurrency string, minMarginPct models.Decimal, userID uint) (*models.CurrencyProfitFloor, error) {
+	baseCurrency = strings.ToUpper(strings.TrimSpace(baseCurrency))
+	targetCurrency = strings.ToUpper(strings.TrimSpace(targetCurrency))
+	if baseCurrency == "" || targetCurrency == "" || baseCurrency == targetCurrency {
+		return nil, errors.New("a profit floor needs two different currencies")
+	}
+
+	floor := models.CurrencyProfitFloor{
+		TenantID:       tenantID,
+		BaseCurrency:   baseCurrency,
+		TargetCurrency: targetCurrency,
+		MinMarginPct:   minMarginPct,
+		UpdatedBy:      &userID,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "base_currency"}, {Name: "target_currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_margin_pct", "updated_by", "updated_at"}),
+	}).Create(&floor).Error; err != nil {
+		return nil, err
+	}
+	return s.GetFloor(tenantID, baseCurrency, targetCurrency)
+}
+
+// DeleteFloor removes a floor so the pair is no longer checked
+func (s *ProfitFloorService) DeleteFloor(tenantID, floorID uint) error {
+	result := s.db.Where("id = ? AND tenant_id = ?", floorID, tenantID).Delete(&models.CurrencyProfitFloor{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarginPct returns the transaction's net profit as a percentage of its send amount
+func MarginPct(transaction *models.Transaction) models.Decimal {
+	if !transaction.SendAmount.IsPositive() {
+		return models.Zero()
+	}
+	return transaction.NetProfit.Div(transaction.SendAmount).Mul(models.NewDecimal(100))
+}
+
+// Enforce rejects a new transaction whose margin is below its pair's floor. The floor can be overridden
+// by giving a MarginOverrideReason when the transaction is entered by an owner or admin; the override is
+// recorded on the transaction and the overridden floor returned so it can be audited once saved.
+// Transactions without a calculated profit are not checked.
+func (s *ProfitFloorService) Enforce(transaction *models.Transaction) (*models.CurrencyProfitFloor, error) {
+	reason := ""
+	if transaction.MarginOverrideReason != nil {
+		reason = strings.TrimSpace(*transaction.MarginOverrideReason)
+	}
+	// Only an override that was actually needed is kept on the transaction
+	transaction.MarginOverrideReason = nil
+	transaction.MarginOverrideBy = nil
+
+	if transaction.ProfitCalculationStatus != models.ProfitStatusCalculated {
+		return nil, nil
+	}
+	floor, err := s.GetFloor(transaction.TenantID, transaction.SendCurrency, transaction.ReceiveCurrency)
+	if err != nil || floor == nil {
+		return nil, err
+	}
+
+	margin := MarginPct(transaction)
+	if !margin.LessThan(floor.MinMarginPct) {
+		return nil, nil
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: margin %s%% is below the %s/%s floor of %s%%", ErrBelowProfitFloor,
+			margin.Round(2).String(), floor.BaseCurrency, floor.TargetCurrency, floor.MinMarginPct.String())
+	}
+
+	user, err := overridingAdmin(s.db, transaction.CreatedBy, ErrProfitFloorOverrideForbidden)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.MarginOverrideReason = &reason
+	transaction.MarginOverrideBy = &user.ID
+	return floor, nil
+}
+
+// overridingAdmin loads the user entering a transaction and returns forbidden unless they are an owner or admin
+func overridingAdmin(db *gorm.DB, userID *uint, forbidden error) (*models.User, error) {
+	if userID == nil {
+		return nil, forbidden
+	}
+	var user models.User
+	if err := db.Select("id", "role").First(&user, *userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load overriding user: %w", err)
+	}
+	switch user.Role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+		return &user, nil
+	}
+	return nil, forbidden
+}
+
+// LogOverride records an accepted profit floor override in the audit log
+func (s *ProfitFloorService) LogOverride(transaction *models.Transaction, floor *models.CurrencyProfitFloor) {
+	if transaction.MarginOverrideBy == nil || transaction.MarginOverrideReason == nil {
+		return
+	}
+	margin := MarginPct(transaction)
+	tenantID := transaction.TenantID
+	s.auditService.LogAction(*transaction.MarginOverrideBy, &tenantID, models.ActionOverrideProfitFloor, "Transaction", transaction.ID,
+		fmt.Sprintf("Accepted margin %s%% below the %s/%s floor of %s%%: %s",
+			margin.Round(2).String(), floor.BaseCurrency, floor.TargetCurrency, floor.MinMarginPct.String(), *transaction.MarginOverrideReason),
+		nil, map[string]interface{}{
+			"marginPct":    margin.Round(4),
+			"minMarginPct": floor.MinMarginPct,
+			"reason":       *transaction.MarginOverrideReason,
+		}, nil)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"api/pkg/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RateRefreshService refreshes external exchange rates for tenants that have an auto-refresh interval
+type RateRefreshService struct {
+	db          *gorm.DB
+	rateService *ExchangeRateService
+}
+
+// NewRateRefreshService creates a new RateRefreshService
+func NewRateRefreshService(db *gorm.DB) *RateRefreshService {
+	return &RateRefreshService{
+		db:          db,
+		rateService: NewExchangeRateService(db),
+	}
+}
+
+// RateRefreshDue reports whether the tenant's rates should be refreshed at the given time.
+// Tenants without an interval use manual rates only and are never due.
+func RateRefreshDue(settings *models.TenantSettings, now time.Time) bool {
+	if settings.RateRefreshIntervalMinutes <= 0 {
+		return false
+	}
+	if settings.RatesLastRefreshedAt == nil {
+		return true
+	}
+	interval := time.Duration(settings.RateRefreshIntervalMinutes) * time.Minute
+	return !now.Before(settings.RatesLastRefreshedAt.Add(interval))
+}
+
+// RefreshDue refreshes every active tenant whose interval has elapsed and returns the tenants refreshed
+func (s *RateRefreshService) RefreshDue(now time.Time) ([]uint, error) {
+	var configured []models.TenantSettings
+	if err := s.db.Joins("JOIN tenants ON tenants.id = tenant_settings.tenant_id").
+		Where("tenant_settings.rate_refresh_interval_minutes > 0 AND tenants.status IN ?",
+			[]string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Find(&configured).Error; err != nil {
+		return nil, err
+	}
+
+	refreshed := make([]uint, 0)
+	for i := range configured {
+		settings := &configured[i]
+		if !RateRefreshDue(settings, now) {
+			continue
+		}
+
+		baseCurrency := settings.RateRefreshBaseCurrency
+		if baseCurrency == "" {
+			baseCurrency = models.DefaultTenantSettings(settings.TenantID).RateRefreshBaseCurrency
+		}
+		if err := s.rateService.FetchRatesFromAPI(settings.TenantID, baseCurrency); err != nil {
+			log.Printf("⚠️  Rate refresh failed for tenant %d: %v", settings.TenantID, err)
+			continue
+		}
+		refreshed = append(refreshed, settings.TenantID)
+	}
+
+	return refreshed, nil
+}
+
+// Start checks for tenants due a refresh on the given interval in the background
+func (s *RateRefreshService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Rate refresh scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if _, err := s.RefreshDue(time.Now()); err != nil {
+				log.Printf("⚠️  Rate refresh check failed: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// stubRateProvider returns fixed rates and counts how often it was asked
+type stubRateProvider struct {
+	rates map[string]float64
+	calls int
+}
+
+func (p *stubRateProvider) FetchLatestRates(baseCurrency string) (map[string]float64, error) {
+	p.calls++
+	return p.rates, nil
+}
+
+// TestRateRefreshDue verifies only tenants with an interval that has elapsed are due
+func TestRateRefreshDue(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) *time.Time {
+		refreshed := now.Add(-ago)
+		return &refreshed
+	}
+
+	cases := []struct {
+		name        string
+		interval    int
+		lastRefresh *time.Time
+		want        bool
+	}{
+		{"ManualOnly", 0, nil, false},
+		{"NeverRefreshed", 30, nil, true},
+		{"IntervalNotElapsed", 30, at(10 * time.Minute), false},
+		{"IntervalElapsed", 30, at(45 * time.Minute), true},
+		{"ExactlyOnInterval", 30, at(30 * time.Minute), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := models.DefaultTenantSettings(1)
+			settings.RateRefreshIntervalMinutes = tc.interval
+			settings.RatesLastRefreshedAt = tc.lastRefresh
+			if got := RateRefreshDue(&settings, now); got != tc.want {
+				t.Errorf("Expected due=%v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestRateRefreshService_RefreshesDueTenants verifies a due tenant's rates are stored from the provider and manual-only tenants are skipped
+func TestRateRefreshService_RefreshesDueTenants(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.ExchangeRate{})
+
+	scheduled := &models.Tenant{Name: "Scheduled Exchange", Status: models.TenantStatusActive}
+	db.Create(scheduled)
+	manual := &models.Tenant{Name: "Manual Exchange", Status: models.TenantStatusActive}
+	db.Create(manual)
+
+	scheduledSettings := models.DefaultTenantSettings(scheduled.ID)
+	scheduledSettings.RateRefreshIntervalMinutes = 60
+	db.Create(&scheduledSettings)
+	manualSettings := models.DefaultTenantSettings(manual.ID)
+	db.Create(&manualSettings)
+
+	provider := &stubRateProvider{rates: map[string]float64{"CAD": 1.37, "EUR": 0.92}}
+	service := NewRateRefreshService(db)
+	service.rateService.Provider = provider
+
+	refreshed, err := service.RefreshDue(time.Now())
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(refreshed) != 1 || refreshed[0] != scheduled.ID {
+		t.Fatalf("Expected only the scheduled tenant to be refreshed, got %v", refreshed)
+	}
+
+	var rate models.ExchangeRate
+	if err := db.Where("tenant_id = ? AND base_currency = ? AND target_currency = ?", scheduled.ID, "USD", "CAD").
+		First(&rate).Error; err != nil {
+		t.Fatalf("Expected a stored USD/CAD rate: %v", err)
+	}
+	if rate.Rate.Float64() != 1.37 || rate.Source != models.RateSourceAPI {
+		t.Errorf("Expected an API rate of 1.37, got %v from %s", rate.Rate, rate.Source)
+	}
+
+	var manualRates int64
+	db.Model(&models.ExchangeRate{}).Where("tenant_id = ?", manual.ID).Count(&manualRates)
+	if manualRates != 0 {
+		t.Errorf("Expected no rates for the manual-only tenant, got %d", manualRates)
+	}
+
+	var reloaded models.TenantSettings
+	db.Where("tenant_id = ?", scheduled.ID).First(&reloaded)
+	if reloaded.RatesLastRefreshedAt == nil {
+		t.Fatal("Expected the refresh time to be recorded")
+	}
+
+	t.Run("NotDueAgainWithinInterval", func(t *testing.T) {
+		again, err := service.RefreshDue(time.Now().Add(10 * time.Minute))
+		if err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if len(again) != 0 || provider.calls != 1 {
+			t.Errorf("Expected no refresh within the interval, got %v after %d provider calls", again, provider.calls)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"api/pkg/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RateSnapshotService periodically stores each tenant's current exchange rates for the dashboard rate trends
+type RateSnapshotService struct {
+	db          *gorm.DB
+	rateService *ExchangeRateService
+}
+
+// NewRateSnapshotService creates a new RateSnapshotService
+func NewRateSnapshotService(db *gorm.DB) *RateSnapshotService {
+	return &RateSnapshotService{
+		db:          db,
+		rateService: NewExchangeRateService(db),
+	}
+}
+
+// RateSnapshotDue reports whether a new snapshot should be taken, given when the last one was captured.
+// Tenants with an interval of 0 never take snapshots.
+func RateSnapshotDue(settings *models.TenantSettings, lastCapturedAt *time.Time, now time.Time) bool {
+	if settings.RateSnapshotMinutes <= 0 {
+		return false
+	}
+	if lastCapturedAt == nil {
+		return true
+	}
+	interval := time.Duration(settings.RateSnapshotMinutes) * time.Minute
+	return !now.Before(lastCapturedAt.Add(interval))
+}
+
+// CaptureTenant stores the current rate of every currency pair the tenant quotes and returns the snapshots.
+// Rates are stored as a single mid rate, so the buy and sell quotes are taken the tenant's quote margin
+// below and above it.
+func (s *RateSnapshotService) CaptureTenant(settings *models.TenantSettings, now time.Time) ([]models.ExchangeRateSnapshot, error) {
+	tenantID := settings.TenantID
+	rates, err := s.rateService.GetAllCurrentRates(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	margin := settings.RateQuoteMarginPct.Div(models.NewDecimal(100))
+	buyFactor := models.NewDecimal(1).Sub(margin)
+	sellFactor := models.NewDecimal(1).Add(margin)
+
+	snapshots := make([]models.ExchangeRateSnapshot, 0, len(rates))
+	for _, rate := range rates {
+		snapshots = append(snapshots, models.ExchangeRateSnapshot{
+			TenantID:       tenantID,
+			BaseCurrency:   rate.BaseCurrency,
+			TargetCurrency: rate.TargetCurrency,
+			BuyRate:        rate.Rate.Mul(buyFactor),
+			SellRate:       rate.Rate.Mul(sellFactor),
+			Source:         rate.Source,
+			CapturedAt:     now,
+		})
+	}
+	if len(snapshots) == 0 {
+		return snapshots, nil
+	}
+
+	if err := s.db.Create(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// CaptureDue snapshots every active tenant whose interval has elapsed and returns the tenants captured
+func (s *RateSnapshotService) CaptureDue(now time.Time) ([]uint, error) {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return nil, err
+	}
+
+	settingsService := NewTenantSettingsService(s.db)
+	captured := make([]uint, 0)
+	for _, tenantID := range tenantIDs {
+		settings, err := settingsService.GetSettings(tenantID)
+		if err != nil {
+			log.Printf("⚠️  Rate snapshot skipped for tenant %d: %v", tenantID, err)
+			continue
+		}
+
+		var last models.ExchangeRateSnapshot
+		var lastCapturedAt *time.Time
+		if err := s.db.Where("tenant_id = ?", tenantID).Order("captured_at DESC").Limit(1).Find(&last).Error; err != nil {
+			log.Printf("⚠️  Rate snapshot skipped for tenant %d: %v", tenantID, err)
+			continue
+		}
+		if last.ID != 0 {
+			lastCapturedAt = &last.CapturedAt
+		}
+		if !RateSnapshotDue(settings, lastCapturedAt, now) {
+			continue
+		}
+
+		if _, err := s.CaptureTenant(settings, now); err != nil {
+			log.Printf("⚠️  Rate snapshot failed for tenant %d: %v", tenantID, err)
+			continue
+		}
+		captured = append(captured, tenantID)
+	}
+
+	return captured, nil
+}
+
+// Start checks for tenants due a snapshot on the given interval in the background
+func (s *RateSnapshotService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Rate snapshot scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if _, err := s.CaptureDue(time.Now()); err != nil {
+				log.Printf("⚠️  Rate snapshot check failed: %v", err)
+			}
+		}
+	}()
+}
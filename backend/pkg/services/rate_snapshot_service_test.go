@@ -0,0 +1,41 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCaptureTenant_QuotesAroundStoredRate verifies snapshots take the buy and sell rates the tenant's quote
+// margin either side of the stored rate, and record the stored rate as both when there is no margin
+func TestCaptureTenant_QuotesAroundStoredRate(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.ExchangeRate{}, &models.ExchangeRateSnapshot{})
+	tenant := newTestTenant(t, db, "Snapshot Exchange")
+	require.NoError(t, db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "IRR",
+		Rate:           models.NewDecimal(80000),
+		Source:         models.RateSourceManual,
+	}).Error)
+
+	service := NewRateSnapshotService(db)
+	settings := models.DefaultTenantSettings(tenant.ID)
+
+	snapshots, err := service.CaptureTenant(&settings, time.Now())
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	if snapshots[0].BuyRate.Float64() != 80000 || snapshots[0].SellRate.Float64() != 80000 {
+		t.Errorf("Expected both quotes at the stored rate without a margin, got buy %v sell %v", snapshots[0].BuyRate, snapshots[0].SellRate)
+	}
+
+	settings.RateQuoteMarginPct = models.NewDecimal(1.5)
+	snapshots, err = service.CaptureTenant(&settings, time.Now())
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	if snapshots[0].BuyRate.Float64() != 78800 || snapshots[0].SellRate.Float64() != 81200 {
+		t.Errorf("Expected buy 78800 and sell 81200 with a 1.5%% margin, got buy %v sell %v", snapshots[0].BuyRate, snapshots[0].SellRate)
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"time"
+)
+
+// receiptLabels translates the labels used by the built-in receipt templates, available to templates
+// as {{label.<key>}}
+var receiptLabels = map[string]map[string]string{
+	models.ReceiptLocaleEnglish: {
+		"receipt_title":   "Transaction Receipt",
+		"transaction_id":  "Transaction ID:",
+		"date":            "Date:",
+		"customer":        "Customer:",
+		"beneficiary":     "Beneficiary:",
+		"amount_sent":     "Amount Sent:",
+		"exchange_rate":   "Exchange Rate:",
+		"amount_received": "Amount Received:",
+		"fee":             "Fee:",
+		"total_paid":      "Total Paid:",
+		"status":          "Status:",
+		"reference":       "Reference:",
+		"receipt_number":  "Receipt No.:",
+		"thank_you":       "Thank you for choosing",
+	},
+	models.ReceiptLocalePersian: {
+		"receipt_title":   "رسید تراکنش",
+		"transaction_id":  "شماره تراکنش:",
+		"date":            "تاریخ:",
+		"customer":        "مشتری:",
+		"beneficiary":     "گیرنده:",
+		"amount_sent":     "مبلغ ارسالی:",
+		"exchange_rate":   "نرخ تبدیل:",
+		"amount_received": "مبلغ دریافتی:",
+		"fee":             "کارمزد:",
+		"total_paid":      "مبلغ کل پرداختی:",
+		"status":          "وضعیت:",
+		"reference":       "شماره پیگیری:",
+		"receipt_number":  "شماره رسید:",
+		"thank_you":       "سپاس از انتخاب",
+	},
+}
+
+// withReceiptLabels returns data with the locale's labels added under label.<key>, leaving any label
+// the caller already set untouched
+func withReceiptLabels(data map[string]interface{}, locale string) map[string]interface{} {
+	labels, ok := receiptLabels[locale]
+	if !ok {
+		labels = receiptLabels[models.ReceiptLocaleEnglish]
+	}
+	merged := make(map[string]interface{}, len(data)+len(labels))
+	for key, label := range labels {
+		merged["label."+key] = label
+	}
+	for key, value := range data {
+		merged[key] = value
+	}
+	return merged
+}
+
+// formatReceiptValue formats a template value, writing dates in the locale's calendar
+func formatReceiptValue(value interface{}, locale string) string {
+	switch v := value.(type) {
+	case time.Time:
+		return formatReceiptDate(v, locale)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return formatReceiptDate(*v, locale)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ParseReceiptDates returns JSON-decoded receipt data with its RFC 3339 and yyyy-mm-dd strings, including
+// those inside lists for each blocks, turned into times, so dates sent to the render endpoint are written
+// in the locale's calendar like the ones the server fills in
+func ParseReceiptDates(data map[string]interface{}) map[string]interface{} {
+	parsed, _ := parseReceiptDateValue(data).(map[string]interface{})
+	return parsed
+}
+
+func parseReceiptDateValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	case map[string]interface{}:
+		parsed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			parsed[key] = parseReceiptDateValue(item)
+		}
+		return parsed
+	case []interface{}:
+		parsed := make([]interface{}, len(v))
+		for i, item := range v {
+			parsed[i] = parseReceiptDateValue(item)
+		}
+		return parsed
+	}
+	return value
+}
+
+// formatReceiptDate writes a date as "January 2, 2006", or as a Jalali yyyy/mm/dd date for Persian receipts
+func formatReceiptDate(t time.Time, locale string) string {
+	if locale != models.ReceiptLocalePersian {
+		return t.Format("January 2, 2006")
+	}
+	jy, jm, jd := gregorianToJalali(t.Year(), int(t.Month()), t.Day())
+	return fmt.Sprintf("%04d/%02d/%02d", jy, jm, jd)
+}
+
+// gregorianToJalali converts a Gregorian date to the Jalali (Solar Hijri) calendar used in Iran
+func gregorianToJalali(gy, gm, gd int) (jy, jm, jd int) {
+	daysBeforeMonth := [12]int{0, 31, 59, 90, 120, 151, 181, 212, 243, 273, 304, 334}
+	leapYear := gy
+	if gm > 2 {
+		leapYear = gy + 1
+	}
+	days := 355666 + 365*gy + (leapYear+3)/4 - (leapYear+99)/100 + (leapYear+399)/400 + gd + daysBeforeMonth[gm-1]
+
+	jy = -1595 + 33*(days/12053)
+	days %= 12053
+	jy += 4 * (days / 1461)
+	days %= 1461
+	if days > 365 {
+		jy += (days - 1) / 365
+		days = (days - 1) % 365
+	}
+	if days < 186 {
+		return jy, 1 + days/31, 1 + days%31
+	}
+	return jy, 7 + (days-186)/30, 1 + (days-186)%30
+}
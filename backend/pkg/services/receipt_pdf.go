@@ -0,0 +1,120 @@
+package services
+
+import (
+	"api/pkg/models"
+	"bytes"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ReceiptPDFContentType is the content type of receipts rendered by RenderReceiptPDF
+const ReceiptPDFContentType = "application/pdf"
+
+// ErrReceiptPDFLocaleUnsupported is returned for PDF receipts in a locale the built-in PDF fonts cannot write
+var ErrReceiptPDFLocaleUnsupported = errors.New("PDF receipts are not available for right-to-left locales")
+
+// receiptPageSizes are the page sizes in millimetres a template can choose; Receipt is an 80mm thermal roll
+var receiptPageSizes = map[string]fpdf.SizeType{
+	"A4":      {Wd: 210, Ht: 297},
+	"LETTER":  {Wd: 215.9, Ht: 279.4},
+	"RECEIPT": {Wd: 80, Ht: 297},
+}
+
+var (
+	receiptBodyRe      = regexp.MustCompile(`(?is)<body[^>]*>(.*)</body>`)
+	receiptHiddenRe    = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>|<!--.*?-->`)
+	receiptHeadingRe   = regexp.MustCompile(`(?i)<h[1-3]\b[^>]*>`)
+	receiptHeadingEnd  = regexp.MustCompile(`(?i)</h[1-3]\s*>`)
+	receiptBoldRe      = regexp.MustCompile(`(?i)<(/?)(strong|b|th)\b[^>]*>`)
+	receiptItalicRe    = regexp.MustCompile(`(?i)<(/?)(em|i)\b[^>]*>`)
+	receiptCellEndRe   = regexp.MustCompile(`(?i)</t[dh]\s*>`)
+	receiptBlockRe     = regexp.MustCompile(`(?i)</?(p|div|h[4-6]|tr|li|ul|ol|table|hr|br)\b[^>]*>`)
+	receiptUnknownRe   = regexp.MustCompile(`(?i)<(/?)([a-z0-9]+)\b[^>]*>`)
+	receiptLineBreakRe = regexp.MustCompile(`\s*<br>\s*`)
+	receiptBlankRe     = regexp.MustCompile(`(<br>){3,}`)
+	receiptTagRe       = regexp.MustCompile(`<[^>]*>`)
+	receiptSpaceRe     = regexp.MustCompile(`\s+`)
+)
+
+// RenderReceiptPDF lays out HTML produced by RenderWithTemplate on the template's page size and orientation,
+// with the template margins taken as millimetres. Only the receipt's text, line structure, headings and
+// bold or italic runs carry over; styling from CSS is not applied. The core PDF fonts cannot write
+// Persian, so right-to-left receipts are refused.
+func (s *ReceiptService) RenderReceiptPDF(template *models.ReceiptTemplate, receiptHTML string) ([]byte, error) {
+	if template.Locale == models.ReceiptLocalePersian {
+		return nil, ErrReceiptPDFLocaleUnsupported
+	}
+	size, ok := receiptPageSizes[strings.ToUpper(template.PageSize)]
+	if !ok {
+		size = receiptPageSizes["A4"]
+	}
+	orientation := "P"
+	if strings.EqualFold(template.Orientation, "landscape") {
+		orientation = "L"
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{OrientationStr: orientation, UnitStr: "mm", Size: size})
+	pdf.SetMargins(float64(template.MarginLeft), float64(template.MarginTop), float64(template.MarginRight))
+	pdf.SetAutoPageBreak(true, float64(template.MarginBottom))
+	pdf.SetTitle(template.Name, true)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 10)
+
+	// The core fonts are cp1252 encoded, so UTF-8 text is translated before it is written
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	writer := pdf.HTMLBasicNew()
+	writer.Write(5, translate(receiptBasicHTML(receiptHTML)))
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// receiptBasicHTML reduces a rendered receipt to the few tags fpdf's basic HTML writer understands:
+// block elements become line breaks, headings and table headers become bold and table cells are
+// separated by spaces
+func receiptBasicHTML(receiptHTML string) string {
+	if match := receiptBodyRe.FindStringSubmatch(receiptHTML); match != nil {
+		receiptHTML = match[1]
+	}
+	out := receiptHiddenRe.ReplaceAllString(receiptHTML, "")
+	out = receiptSpaceRe.ReplaceAllString(out, " ")
+	out = receiptHeadingRe.ReplaceAllString(out, "<br><b>")
+	out = receiptHeadingEnd.ReplaceAllString(out, "</b><br>")
+	out = receiptBoldRe.ReplaceAllString(out, "<${1}b>")
+	out = receiptItalicRe.ReplaceAllString(out, "<${1}i>")
+	out = receiptCellEndRe.ReplaceAllString(out, " ")
+	out = receiptBlockRe.ReplaceAllString(out, "<br>")
+	out = receiptUnknownRe.ReplaceAllStringFunc(out, func(tag string) string {
+		switch strings.ToLower(receiptUnknownRe.FindStringSubmatch(tag)[2]) {
+		case "b", "i", "u", "br":
+			return tag
+		}
+		return ""
+	})
+	out = receiptLineBreakRe.ReplaceAllString(out, "<br>")
+	out = receiptBlankRe.ReplaceAllString(out, "<br><br>")
+	out = strings.TrimPrefix(out, "<br>")
+
+	// Decode entities in the text between tags; a decoded < or > would otherwise be read as a tag
+	var b strings.Builder
+	last := 0
+	for _, loc := range receiptTagRe.FindAllStringIndex(out, -1) {
+		b.WriteString(receiptText(out[last:loc[0]]))
+		b.WriteString(out[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(receiptText(out[last:]))
+	return b.String()
+}
+
+// receiptText decodes HTML entities in a run of receipt text, swapping angle brackets for lookalikes
+func receiptText(text string) string {
+	return strings.NewReplacer("<", "‹", ">", "›").Replace(html.UnescapeString(text))
+}
@@ -38,14 +38,30 @@ type CreateTemplateRequest struct {
 	MarginLeft   int    `json:"marginLeft"`
 	LogoPath     string `json:"logoPath"`
 	LogoPosition string `json:"logoPosition"`
+	Locale       string `json:"locale"` // en or fa, defaults to en
 	IsDefault    bool   `json:"isDefault"`
 }
 
+// NormalizeReceiptLocale returns the supported locale for a requested one, defaulting to English
+func NormalizeReceiptLocale(locale string) (string, error) {
+	switch locale = strings.ToLower(strings.TrimSpace(locale)); locale {
+	case "":
+		return models.ReceiptLocaleEnglish, nil
+	case models.ReceiptLocaleEnglish, models.ReceiptLocalePersian:
+		return locale, nil
+	}
+	return "", errors.New("locale must be en or fa")
+}
+
 // CreateTemplate creates a new receipt template
 func (s *ReceiptService) CreateTemplate(tenantID uint, userID uint, req CreateTemplateRequest) (*models.ReceiptTemplate, error) {
 	if req.Name == "" {
 		return nil, errors.New("template name is required")
 	}
+	locale, err := NormalizeReceiptLocale(req.Locale)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set defaults
 	if req.TemplateType == "" {
@@ -90,6 +106,7 @@ func (s *ReceiptService) CreateTemplate(tenantID uint, userID uint, req CreateTe
 		MarginLeft:   req.MarginLeft,
 		LogoPath:     req.LogoPath,
 		LogoPosition: req.LogoPosition,
+		Locale:       locale,
 		IsDefault:    req.IsDefault,
 		IsActive:     true,
 		CreatedBy:    &userID,
@@ -120,9 +137,21 @@ func (s *ReceiptService) GetTemplate(tenantID, templateID uint) (*models.Receipt
 	return &template, nil
 }
 
-// GetDefaultTemplate retrieves the default template for a type
-func (s *ReceiptService) GetDefaultTemplate(tenantID uint, templateType string) (*models.ReceiptTemplate, error) {
+// GetDefaultTemplate retrieves the default template for a type: the branch's assigned template if
+// branchID is given and has one, then the tenant default, then the built-in template
+func (s *ReceiptService) GetDefaultTemplate(tenantID uint, branchID *uint, templateType string) (*models.ReceiptTemplate, error) {
 	var template models.ReceiptTemplate
+	if branchID != nil {
+		err := s.DB.Joins("JOIN branch_receipt_templates ON branch_receipt_templates.template_id = receipt_templates.id").
+			Where("branch_receipt_templates.tenant_id = ? AND branch_receipt_templates.branch_id = ? AND branch_receipt_templates.template_type = ?",
+				tenantID, *branchID, templateType).
+			Where("receipt_templates.is_active = ?", true).
+			First(&template).Error
+		if err == nil {
+			return &template, nil
+		}
+	}
+
 	err := s.DB.Where("tenant_id = ? AND template_type = ? AND is_default = ? AND is_active = ?",
 		tenantID, templateType, true, true).First(&template).Error
 
@@ -140,6 +169,43 @@ func (s *ReceiptService) GetDefaultTemplate(tenantID uint, templateType string)
 	return &template, nil
 }
 
+// SetBranchDefaultTemplate assigns a template as the branch's default for the template's type
+func (s *ReceiptService) SetBranchDefaultTemplate(tenantID, branchID, templateID uint) (*models.BranchReceiptTemplate, error) {
+	var branch models.Branch
+	if err := s.DB.Where("id = ? AND tenant_id = ?", branchID, tenantID).First(&branch).Error; err != nil {
+		return nil, fmt.Errorf("branch not found: %w", err)
+	}
+
+	var template models.ReceiptTemplate
+	if err := s.DB.Where("id = ? AND tenant_id = ?", templateID, tenantID).First(&template).Error; err != nil {
+		return nil, fmt.Errorf("template not found: %w", err)
+	}
+	if !template.IsActive {
+		return nil, errors.New("cannot assign an inactive template")
+	}
+
+	var assignment models.BranchReceiptTemplate
+	err := s.DB.Where("branch_id = ? AND template_type = ?", branchID, template.TemplateType).First(&assignment).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	assignment.TenantID = tenantID
+	assignment.BranchID = branchID
+	assignment.TemplateType = template.TemplateType
+	assignment.TemplateID = template.ID
+	if err := s.DB.Save(&assignment).Error; err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ClearBranchDefaultTemplate removes the branch's override so it falls back to the tenant default
+func (s *ReceiptService) ClearBranchDefaultTemplate(tenantID, branchID uint, templateType string) error {
+	return s.DB.Where("tenant_id = ? AND branch_id = ? AND template_type = ?", tenantID, branchID, templateType).
+		Delete(&models.BranchReceiptTemplate{}).Error
+}
+
 // ListTemplates lists all templates for a tenant
 func (s *ReceiptService) ListTemplates(tenantID uint, includeInactive bool) ([]models.ReceiptTemplate, error) {
 	query := s.DB.Where("tenant_id = ?", tenantID)
@@ -177,6 +243,13 @@ func (s *ReceiptService) UpdateTemplate(tenantID, templateID uint, userID uint,
 	template.MarginLeft = req.MarginLeft
 	template.LogoPath = req.LogoPath
 	template.LogoPosition = req.LogoPosition
+	if req.Locale != "" {
+		locale, err := NormalizeReceiptLocale(req.Locale)
+		if err != nil {
+			return nil, err
+		}
+		template.Locale = locale
+	}
 	template.UpdatedBy = &userID
 	template.Version++
 
@@ -251,6 +324,7 @@ func (s *ReceiptService) DuplicateTemplate(tenantID, templateID uint, userID uin
 		MarginLeft:   orig.MarginLeft,
 		LogoPath:     orig.LogoPath,
 		LogoPosition: orig.LogoPosition,
+		Locale:       orig.Locale,
 		IsDefault:    false,
 		IsActive:     true,
 		CreatedBy:    &userID,
@@ -264,9 +338,9 @@ func (s *ReceiptService) DuplicateTemplate(tenantID, templateID uint, userID uin
 	return copyTemplate, nil
 }
 
-// RenderReceipt renders a receipt using a template and data
-func (s *ReceiptService) RenderReceipt(tenantID uint, templateType string, data map[string]interface{}) (string, error) {
-	template, err := s.GetDefaultTemplate(tenantID, templateType)
+// RenderReceipt renders a receipt using the default template for the branch and data
+func (s *ReceiptService) RenderReceipt(tenantID uint, branchID *uint, templateType string, data map[string]interface{}) (string, error) {
+	template, err := s.GetDefaultTemplate(tenantID, branchID, templateType)
 	if err != nil {
 		return "", err
 	}
@@ -276,15 +350,32 @@ func (s *ReceiptService) RenderReceipt(tenantID uint, templateType string, data
 
 // RenderWithTemplate renders content using a specific template
 func (s *ReceiptService) RenderWithTemplate(template *models.ReceiptTemplate, data map[string]interface{}) string {
+	return s.renderWithTemplate(template, data, false)
+}
+
+// renderWithTemplate renders the template's sections into a page in the template's locale; preview marks
+// unknown variables
+func (s *ReceiptService) renderWithTemplate(template *models.ReceiptTemplate, data map[string]interface{}, preview bool) string {
+	locale, err := NormalizeReceiptLocale(template.Locale)
+	if err != nil {
+		locale = models.ReceiptLocaleEnglish
+	}
+	data = withReceiptLabels(data, locale)
+	dir, font := "ltr", "Arial, sans-serif"
+	if locale == models.ReceiptLocalePersian {
+		dir, font = "rtl", `Vazirmatn, Tahoma, "Segoe UI", sans-serif`
+	}
+
 	fullHTML := fmt.Sprintf(`<!DOCTYPE html>
-<html>
+<html lang="%s" dir="%s">
 <head>
     <meta charset="UTF-8">
     <title>Receipt</title>
     <style>
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body { 
-            font-family: Arial, sans-serif; 
+            font-family: %s; 
+            direction: %s;
             padding: %dpx %dpx %dpx %dpx;
         }
         .receipt-header { margin-bottom: 20px; }
@@ -305,44 +396,88 @@ func (s *ReceiptService) RenderWithTemplate(template *models.ReceiptTemplate, da
     </div>
 </body>
 </html>`,
+		locale, dir, font, dir,
 		template.MarginTop, template.MarginRight, template.MarginBottom, template.MarginLeft,
 		template.StyleCSS,
-		s.replaceVariables(template.HeaderHTML, data),
-		s.replaceVariables(template.BodyHTML, data),
-		s.replaceVariables(template.FooterHTML, data),
+		renderReceiptTemplate(template.HeaderHTML, data, locale, preview),
+		renderReceiptTemplate(template.BodyHTML, data, locale, preview),
+		renderReceiptTemplate(template.FooterHTML, data, locale, preview),
 	)
 
 	return fullHTML
 }
 
-// replaceVariables replaces template variables with actual values
-func (s *ReceiptService) replaceVariables(content string, data map[string]interface{}) string {
-	result := content
+// NextReceiptNumber issues the next receipt number of the branch, or of the tenant when branchID is nil.
+// Numbers never reset and are counted under a row lock, so they are unique and contiguous as long as
+// every number issued is kept; IssueReceiptNumber ties each one to the record it was printed for.
+func (s *ReceiptService) NextReceiptNumber(tenantID uint, branchID *uint) (string, error) {
+	var branchCode string
+	if branchID != nil {
+		// Each branch counts separately, so its numbers need a prefix of their own
+		branchCode = fmt.Sprintf("B%d", *branchID)
+		var branch models.Branch
+		if err := s.DB.Select("branch_code").Where("id = ? AND tenant_id = ?", *branchID, tenantID).
+			First(&branch).Error; err == nil && branch.BranchCode != "" {
+			branchCode = branch.BranchCode
+		}
+	}
 
-	// Replace all {{variable.path}} patterns
-	re := regexp.MustCompile(`\{\{([a-zA-Z0-9_.]+)\}\}`)
-	result = re.ReplaceAllStringFunc(result, func(match string) string {
-		// Extract variable name (remove {{ and }})
-		varName := match[2 : len(match)-2]
+	value, _, err := NewSequenceService(s.DB).Next(tenantID, receiptSequenceKey(branchID), models.SequenceResetNever, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return FormatReceiptNumber(branchCode, value), nil
+}
 
-		// Look up value in data map
-		if value, exists := data[varName]; exists {
-			return fmt.Sprintf("%v", value)
+// IssueReceiptNumber returns the receipt number of a transaction or remittance, issuing the next one the
+// first time its receipt is produced. The number and its record are saved together, so a failed save
+// gives the number back instead of leaving a gap.
+func (s *ReceiptService) IssueReceiptNumber(tenantID uint, branchID *uint, sourceType, sourceID string) (string, error) {
+	find := func(db *gorm.DB) (*models.IssuedReceipt, error) {
+		var issued models.IssuedReceipt
+		if err := db.Where("tenant_id = ? AND source_type = ? AND source_id = ?", tenantID, sourceType, sourceID).
+			First(&issued).Error; err != nil {
+			return nil, err
 		}
+		return &issued, nil
+	}
 
-		// Try nested lookup
-		parts := strings.Split(varName, ".")
-		if len(parts) == 2 {
-			key := parts[0] + "." + parts[1]
-			if value, exists := data[key]; exists {
-				return fmt.Sprintf("%v", value)
-			}
+	var number string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		issued, err := find(tx)
+		if err == nil {
+			number = issued.Number
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
 		}
 
-		return ""
+		next, err := (&ReceiptService{DB: tx}).NextReceiptNumber(tenantID, branchID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&models.IssuedReceipt{
+			TenantID:   tenantID,
+			BranchID:   branchID,
+			SourceType: sourceType,
+			SourceID:   sourceID,
+			Number:     next,
+			IssuedAt:   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		number = next
+		return nil
 	})
-
-	return result
+	if err != nil {
+		// A concurrent request numbered the record first; its number stands and ours was rolled back
+		if issued, findErr := find(s.DB); findErr == nil {
+			return issued.Number, nil
+		}
+		return "", fmt.Errorf("failed to issue receipt number: %w", err)
+	}
+	return number, nil
 }
 
 // GetAvailableVariables returns available template variables
@@ -368,6 +503,15 @@ func (s *ReceiptService) ValidateTemplate(template *models.ReceiptTemplate) []st
 		errs = append(errs, "Unclosed variable detected")
 	}
 
+	sections := []struct{ name, content string }{
+		{"Header", template.HeaderHTML}, {"Body", template.BodyHTML}, {"Footer", template.FooterHTML},
+	}
+	for _, section := range sections {
+		if _, err := parseReceiptTemplate(section.content); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", section.name, err))
+		}
+	}
+
 	return errs
 }
 
@@ -379,7 +523,7 @@ func (s *ReceiptService) PreviewReceipt(tenantID uint, templateID uint) (string,
 	}
 
 	sampleData := s.getSampleData(template.TemplateType)
-	return s.RenderWithTemplate(template, sampleData), nil
+	return s.renderWithTemplate(template, sampleData, true), nil
 }
 
 func (s *ReceiptService) getSampleData(templateType string) map[string]interface{} {
@@ -392,7 +536,7 @@ func (s *ReceiptService) getSampleData(templateType string) map[string]interface
 		"business.email":     "info@torontex.com",
 		"business.license":   "MSB-12345-ON",
 		"transaction.id":     "TXN-20231220-0001",
-		"transaction.date":   now.Format("January 2, 2006"),
+		"transaction.date":   now,
 		"transaction.time":   now.Format("3:04 PM"),
 		"transaction.type":   "Currency Exchange",
 		"transaction.status": "Completed",
@@ -412,6 +556,7 @@ func (s *ReceiptService) getSampleData(templateType string) map[string]interface
 		"branch.name":        "Downtown Branch",
 		"branch.address":     "456 Bay Street",
 		"reference.number":   "REF-2023122001234",
+		"receipt.number":     "RCT-DT-000123",
 		"confirmation.code":  "ABCD1234",
 		"current.date":       now.Format("2006-01-02"),
 		"current.datetime":   now.Format("2006-01-02 15:04:05"),
@@ -427,6 +572,10 @@ func (s *ReceiptService) getSampleData(templateType string) map[string]interface
 		data["remittance.eta"] = "1-2 business days"
 		data["pickup.location"] = "Bank Mellat - Vanak Branch"
 		data["pickup.code"] = "PU1234567890"
+		data["payments"] = []map[string]interface{}{
+			{"amount": "25,000,000", "currency": "IRR", "date": now.AddDate(0, 0, -1).Format("2006-01-02"), "reference": "IN-1041"},
+			{"amount": "17,500,000", "currency": "IRR", "date": now.Format("2006-01-02"), "reference": "IN-1057"},
+		}
 	}
 
 	return data
@@ -442,20 +591,21 @@ func (s *ReceiptService) getBuiltInTemplate(templateType string) *models.Receipt
     <p style="font-size: 12px; color: #666;">{{business.phone}} | {{business.email}}</p>
 </div>`,
 		BodyHTML: `<div style="margin: 20px 0;">
-    <h2 style="font-size: 18px; margin-bottom: 15px;">Transaction Receipt</h2>
+    <h2 style="font-size: 18px; margin-bottom: 15px;">{{label.receipt_title}}</h2>
     <table style="width: 100%; border-collapse: collapse;">
-        <tr><td style="padding: 8px 0;"><strong>Transaction ID:</strong></td><td style="text-align: right;">{{transaction.id}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Date:</strong></td><td style="text-align: right;">{{transaction.date}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Customer:</strong></td><td style="text-align: right;">{{customer.name}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Amount Sent:</strong></td><td style="text-align: right;">{{send.currency}} {{send.amount}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Exchange Rate:</strong></td><td style="text-align: right;">{{exchange.rate}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Amount Received:</strong></td><td style="text-align: right;">{{receive.currency}} {{receive.amount}}</td></tr>
-        <tr><td style="padding: 8px 0;"><strong>Total Paid:</strong></td><td style="text-align: right; font-weight: bold;">{{send.currency}} {{total.amount}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.transaction_id}}</strong></td><td style="text-align: end;">{{transaction.id}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.date}}</strong></td><td style="text-align: end;">{{transaction.date}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.customer}}</strong></td><td style="text-align: end;">{{customer.name}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.amount_sent}}</strong></td><td style="text-align: end;">{{send.currency}} {{send.amount}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.exchange_rate}}</strong></td><td style="text-align: end;">{{exchange.rate}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.amount_received}}</strong></td><td style="text-align: end;">{{receive.currency}} {{receive.amount}}</td></tr>
+        <tr><td style="padding: 8px 0;"><strong>{{label.total_paid}}</strong></td><td style="text-align: end; font-weight: bold;">{{send.currency}} {{total.amount}}</td></tr>
     </table>
 </div>`,
 		FooterHTML: `<div style="margin-top: 30px; font-size: 11px; color: #888; text-align: center;">
-    <p>Reference: {{reference.number}}</p>
-    <p>Thank you for choosing {{business.name}}</p>
+    {{#if receipt.number}}<p>{{label.receipt_number}} {{receipt.number}}</p>{{/if}}
+    <p>{{label.reference}} {{reference.number}}</p>
+    <p>{{label.thank_you}} {{business.name}}</p>
 </div>`,
 		PageSize:     "A4",
 		Orientation:  "portrait",
@@ -464,6 +614,7 @@ func (s *ReceiptService) getBuiltInTemplate(templateType string) *models.Receipt
 		MarginBottom: 20,
 		MarginLeft:   20,
 		LogoPosition: "center",
+		Locale:       models.ReceiptLocaleEnglish,
 		IsDefault:    true,
 		IsActive:     true,
 	}
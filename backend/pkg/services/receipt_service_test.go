@@ -1,11 +1,18 @@
 package services
 
 import (
+	"api/migrations"
 	"api/pkg/models"
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -22,8 +29,7 @@ func TestReceiptTemplateWorkflow(t *testing.T) {
 	db.AutoMigrate(&models.Tenant{}, &models.User{}, &models.ReceiptTemplate{})
 
 	// Create test data
-	tenant := &models.Tenant{Name: "Test Exchange Bureau"}
-	db.Create(tenant)
+	tenant := newTestTenant(t, db, "Test Exchange Bureau")
 
 	user := &models.User{
 		Email:    "admin@example.com",
@@ -172,7 +178,7 @@ func TestReceiptTemplateWorkflow(t *testing.T) {
 	})
 
 	t.Run("GetDefaultTemplate", func(t *testing.T) {
-		template, err := service.GetDefaultTemplate(tenant.ID, "transaction")
+		template, err := service.GetDefaultTemplate(tenant.ID, nil, "transaction")
 		if err != nil {
 			t.Fatalf("Failed to get default template: %v", err)
 		}
@@ -204,7 +210,7 @@ func TestReceiptTemplateWorkflow(t *testing.T) {
 		}
 
 		// Verify new template is default
-		defaultTemplate, _ := service.GetDefaultTemplate(tenant.ID, "transaction")
+		defaultTemplate, _ := service.GetDefaultTemplate(tenant.ID, nil, "transaction")
 		if defaultTemplate.ID != newTemplate.ID {
 			t.Error("New template should be default")
 		}
@@ -237,8 +243,7 @@ func TestReceiptTemplateWorkflow(t *testing.T) {
 
 	t.Run("CreateDefaultTemplates", func(t *testing.T) {
 		// Create a new tenant
-		tenant2 := &models.Tenant{Name: "New Tenant"}
-		db.Create(tenant2)
+		tenant2 := newTestTenant(t, db, "New Tenant")
 
 		err := service.CreateDefaultTemplates(tenant2.ID, user.ID)
 		if err != nil {
@@ -283,3 +288,306 @@ func TestReceiptTemplateWorkflow(t *testing.T) {
 	fmt.Println("\n✅ ALL RECEIPT TEMPLATE TESTS PASSED!")
 	fmt.Println("=====================================")
 }
+
+// TestGetDefaultTemplate_BranchOverride verifies a branch's assigned template wins over the tenant default
+func TestGetDefaultTemplate_BranchOverride(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.ReceiptTemplate{}, &models.BranchReceiptTemplate{})
+
+	tenant := newTestTenant(t, db, "Multi Branch Exchange")
+	downtown := newTestBranch(t, db, tenant.ID, "Downtown", "DT")
+	airport := newTestBranch(t, db, tenant.ID, "Airport", "AP")
+
+	service := NewReceiptService(db)
+	tenantDefault, err := service.CreateTemplate(tenant.ID, 1, CreateTemplateRequest{
+		Name: "Tenant Receipt", TemplateType: "transaction", BodyHTML: "<p>tenant</p>", IsDefault: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create tenant default: %v", err)
+	}
+	airportTemplate, err := service.CreateTemplate(tenant.ID, 1, CreateTemplateRequest{
+		Name: "Airport Receipt", TemplateType: "transaction", BodyHTML: "<p>airport</p>",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create branch template: %v", err)
+	}
+
+	if _, err := service.SetBranchDefaultTemplate(tenant.ID, airport.ID, airportTemplate.ID); err != nil {
+		t.Fatalf("Failed to assign branch template: %v", err)
+	}
+
+	got, _ := service.GetDefaultTemplate(tenant.ID, &airport.ID, "transaction")
+	if got.ID != airportTemplate.ID {
+		t.Errorf("Expected airport branch to use template %d, got %d (%s)", airportTemplate.ID, got.ID, got.Name)
+	}
+
+	got, _ = service.GetDefaultTemplate(tenant.ID, &downtown.ID, "transaction")
+	if got.ID != tenantDefault.ID {
+		t.Errorf("Expected branch without override to use tenant default %d, got %d", tenantDefault.ID, got.ID)
+	}
+
+	got, _ = service.GetDefaultTemplate(tenant.ID, nil, "transaction")
+	if got.ID != tenantDefault.ID {
+		t.Errorf("Expected no branch to use tenant default %d, got %d", tenantDefault.ID, got.ID)
+	}
+
+	// Clearing the override falls back to the tenant default
+	if err := service.ClearBranchDefaultTemplate(tenant.ID, airport.ID, "transaction"); err != nil {
+		t.Fatalf("Failed to clear branch template: %v", err)
+	}
+	got, _ = service.GetDefaultTemplate(tenant.ID, &airport.ID, "transaction")
+	if got.ID != tenantDefault.ID {
+		t.Errorf("Expected cleared branch to use tenant default %d, got %d", tenantDefault.ID, got.ID)
+	}
+}
+
+// TestRenderReceiptPDF_PageSize verifies a rendered receipt becomes a PDF laid out on the template's page
+func TestRenderReceiptPDF_PageSize(t *testing.T) {
+	service := NewReceiptService(nil)
+	template := &models.ReceiptTemplate{
+		Name:         "PDF Receipt",
+		HeaderHTML:   "<h1>{{business.name}}</h1>",
+		BodyHTML:     "<table><tr><td><strong>Amount:</strong></td><td>{{send.amount}} {{send.currency}}</td></tr></table>",
+		FooterHTML:   "<p>Thank you &amp; goodbye</p>",
+		StyleCSS:     "h1 { color: blue; }",
+		MarginTop:    10,
+		MarginRight:  10,
+		MarginBottom: 10,
+		MarginLeft:   10,
+	}
+	html := service.RenderWithTemplate(template, map[string]interface{}{
+		"business.name": "Test Exchange Bureau",
+		"send.amount":   "1,000.00",
+		"send.currency": "CAD",
+	})
+
+	cases := []struct {
+		pageSize, orientation, mediaBox string
+	}{
+		{"A4", "portrait", "/MediaBox [0 0 595.28 841.89]"},
+		{"Letter", "portrait", "/MediaBox [0 0 612.00 792.00]"},
+		{"Letter", "landscape", "/MediaBox [0 0 792.00 612.00]"},
+	}
+	for _, tc := range cases {
+		template.PageSize, template.Orientation = tc.pageSize, tc.orientation
+		pdf, err := service.RenderReceiptPDF(template, html)
+		if err != nil {
+			t.Fatalf("Failed to render %s %s PDF: %v", tc.pageSize, tc.orientation, err)
+		}
+		if len(pdf) == 0 || !strings.HasPrefix(string(pdf), "%PDF-") {
+			t.Fatalf("Expected a PDF document for %s %s", tc.pageSize, tc.orientation)
+		}
+		if !strings.Contains(string(pdf), tc.mediaBox) {
+			t.Errorf("Expected %s %s to be laid out with %s", tc.pageSize, tc.orientation, tc.mediaBox)
+		}
+	}
+
+	basic := receiptBasicHTML(html)
+	if strings.Contains(basic, "color: blue") || strings.Contains(basic, "<table") {
+		t.Errorf("Expected styles and layout tags to be dropped, got %q", basic)
+	}
+	if !strings.Contains(basic, "<b>Test Exchange Bureau</b>") || !strings.Contains(basic, "Thank you & goodbye") {
+		t.Errorf("Expected headings in bold and entities decoded, got %q", basic)
+	}
+}
+
+// TestRenderWithTemplate_NestedBlocks verifies nested lookups, if blocks and each blocks in receipt templates
+func TestRenderWithTemplate_NestedBlocks(t *testing.T) {
+	service := NewReceiptService(nil)
+	data := map[string]interface{}{
+		"send.amount": "1,000.00",
+		"beneficiary": map[string]interface{}{"name": "Ali Mohammadi", "bank": map[string]interface{}{"name": "Bank Melli"}},
+		"payments": []map[string]interface{}{
+			{"amount": "25,000,000", "currency": "IRR"},
+			{"amount": "17,500,000", "currency": "IRR"},
+		},
+	}
+
+	t.Run("NestedAccess", func(t *testing.T) {
+		got := renderReceiptTemplate("{{send.amount}} to {{beneficiary.name}} at {{beneficiary.bank.name}}", data, models.ReceiptLocaleEnglish, false)
+		if got != "1,000.00 to Ali Mohammadi at Bank Melli" {
+			t.Errorf("Unexpected nested render: %q", got)
+		}
+	})
+
+	t.Run("IfHidesWhenEmpty", func(t *testing.T) {
+		content := "{{#if beneficiary.name}}To: {{beneficiary.name}}{{/if}}{{#if pickup.code}}Code: {{pickup.code}}{{else}}No code{{/if}}"
+		if got := renderReceiptTemplate(content, data, models.ReceiptLocaleEnglish, false); got != "To: Ali MohammadiNo code" {
+			t.Errorf("Unexpected if render: %q", got)
+		}
+		empty := map[string]interface{}{"beneficiary.name": ""}
+		if got := renderReceiptTemplate("{{#if beneficiary.name}}To: {{beneficiary.name}}{{/if}}", empty, models.ReceiptLocaleEnglish, false); got != "" {
+			t.Errorf("Expected an empty value to hide the block, got %q", got)
+		}
+	})
+
+	t.Run("EachIteratesPayments", func(t *testing.T) {
+		content := "<ul>{{#each payments}}<li>{{@index}}. {{amount}} {{currency}} for {{beneficiary.name}}</li>{{/each}}</ul>"
+		want := "<ul><li>1. 25,000,000 IRR for Ali Mohammadi</li><li>2. 17,500,000 IRR for Ali Mohammadi</li></ul>"
+		if got := renderReceiptTemplate(content, data, models.ReceiptLocaleEnglish, false); got != want {
+			t.Errorf("Unexpected each render:\n got %q\nwant %q", got, want)
+		}
+	})
+
+	t.Run("UnknownVariables", func(t *testing.T) {
+		if got := renderReceiptTemplate("Ref: {{reference.number}}", data, models.ReceiptLocaleEnglish, false); got != "Ref: " {
+			t.Errorf("Expected an unknown variable to render empty, got %q", got)
+		}
+		got := renderReceiptTemplate("Ref: {{reference.number}}", data, models.ReceiptLocaleEnglish, true)
+		if !strings.Contains(got, "receipt-missing-variable") || !strings.Contains(got, "{{reference.number}}") {
+			t.Errorf("Expected a visible placeholder in preview mode, got %q", got)
+		}
+	})
+
+	t.Run("ValidateBlocks", func(t *testing.T) {
+		errs := service.ValidateTemplate(&models.ReceiptTemplate{Name: "Broken", BodyHTML: "{{#each payments}}{{amount}}"})
+		if len(errs) != 1 || !strings.Contains(errs[0], "unclosed") {
+			t.Errorf("Expected an unclosed block error, got %v", errs)
+		}
+	})
+}
+
+// TestRenderWithTemplate_Locale verifies Persian receipts render right-to-left with translated labels and
+// Jalali dates while English ones stay left-to-right and Gregorian
+func TestRenderWithTemplate_Locale(t *testing.T) {
+	service := NewReceiptService(nil)
+	data := map[string]interface{}{
+		"transaction.id":   "TXN-1",
+		"transaction.date": time.Date(2023, 12, 20, 14, 30, 0, 0, time.UTC),
+	}
+
+	template := service.getBuiltInTemplate("transaction")
+	en := service.RenderWithTemplate(template, data)
+	if strings.Contains(en, `dir="rtl"`) || !strings.Contains(en, `dir="ltr"`) {
+		t.Error("Expected an English receipt to be left-to-right")
+	}
+	if !strings.Contains(en, "December 20, 2023") || !strings.Contains(en, "Transaction Receipt") {
+		t.Error("Expected a Gregorian date and English labels")
+	}
+
+	template.Locale = models.ReceiptLocalePersian
+	fa := service.RenderWithTemplate(template, data)
+	if !strings.Contains(fa, `dir="rtl"`) || !strings.Contains(fa, `lang="fa"`) {
+		t.Error("Expected a Persian receipt to be right-to-left")
+	}
+	if !strings.Contains(fa, "1402/09/29") || strings.Contains(fa, "December") {
+		t.Error("Expected the Jalali date 1402/09/29")
+	}
+	if !strings.Contains(fa, "رسید تراکنش") || strings.Contains(fa, "Transaction Receipt") {
+		t.Error("Expected Persian labels")
+	}
+	// Dates inside each blocks, and dates sent as JSON strings, are written in the locale's calendar too
+	var sent map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"payments": [{"date": "2023-12-20T14:30:00Z"}, {"date": "2024-03-20"}]}`), &sent))
+	listed := &models.ReceiptTemplate{Locale: models.ReceiptLocalePersian, BodyHTML: "{{#each payments}}[{{date}}]{{/each}}"}
+	if body := service.RenderWithTemplate(listed, ParseReceiptDates(sent)); !strings.Contains(body, "[1402/09/29][1403/01/01]") {
+		t.Errorf("Expected Jalali payment dates, got %s", body)
+	}
+
+	if _, err := service.RenderReceiptPDF(template, fa); err != ErrReceiptPDFLocaleUnsupported {
+		t.Errorf("Expected Persian PDFs to be refused, got %v", err)
+	}
+
+	cases := []struct {
+		gregorian  time.Time
+		jy, jm, jd int
+	}{
+		{time.Date(2024, 3, 19, 0, 0, 0, 0, time.UTC), 1402, 12, 29},
+		{time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), 1403, 1, 1},
+		{time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC), 1403, 12, 30},
+		{time.Date(2025, 3, 21, 0, 0, 0, 0, time.UTC), 1404, 1, 1},
+		{time.Date(2024, 9, 22, 0, 0, 0, 0, time.UTC), 1403, 7, 1},
+	}
+	for _, tc := range cases {
+		jy, jm, jd := gregorianToJalali(tc.gregorian.Year(), int(tc.gregorian.Month()), tc.gregorian.Day())
+		if jy != tc.jy || jm != tc.jm || jd != tc.jd {
+			t.Errorf("%s: expected %d/%d/%d, got %d/%d/%d", tc.gregorian.Format("2006-01-02"), tc.jy, tc.jm, tc.jd, jy, jm, jd)
+		}
+	}
+
+	if _, err := NormalizeReceiptLocale("de"); err == nil {
+		t.Error("Expected an unsupported locale to be rejected")
+	}
+}
+
+// TestNextReceiptNumber_ConcurrentGapFree verifies receipt numbers issued concurrently are unique and
+// contiguous, and that a record keeps the number it was first given
+func TestNextReceiptNumber_ConcurrentGapFree(t *testing.T) {
+	// A file database lets the goroutines race on connections of their own; writers wait on the lock
+	// instead of failing with SQLITE_BUSY
+	dsn := filepath.Join(t.TempDir(), "receipts.db") + "?_busy_timeout=10000&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(8)
+	require.NoError(t, db.AutoMigrate(&models.Tenant{}, &models.Branch{}, &models.Sequence{}, &models.IssuedReceipt{}))
+	require.NoError(t, migrations.AddIssuedReceiptNumberIndex(db))
+	tenant := newTestTenant(t, db, "Sequence Exchange")
+	service := NewReceiptService(db)
+
+	const workers = 40
+	numbers := make([]string, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			numbers[i], errs[i] = service.NextReceiptNumber(tenant.ID, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Worker %d failed: %v", i, err)
+		}
+	}
+	sort.Strings(numbers)
+	for i, number := range numbers {
+		if want := FormatReceiptNumber("", int64(i+1)); number != want {
+			t.Fatalf("Expected contiguous unique numbers, position %d is %s instead of %s", i, number, want)
+		}
+	}
+
+	// Concurrent reprints of one transaction's receipt share a single number
+	issued := make([]string, 5)
+	for i := range issued {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			issued[i], errs[i] = service.IssueReceiptNumber(tenant.ID, nil, models.ReceiptSourceTransaction, "txn-1")
+		}(i)
+	}
+	wg.Wait()
+	for i := range issued {
+		if errs[i] != nil || issued[i] != FormatReceiptNumber("", workers+1) {
+			t.Fatalf("Expected every reprint to get %s, got %s (%v)", FormatReceiptNumber("", workers+1), issued[i], errs[i])
+		}
+	}
+	if next, err := service.NextReceiptNumber(tenant.ID, nil); err != nil || next != FormatReceiptNumber("", workers+2) {
+		t.Errorf("Expected reprints not to use up numbers, next is %s (%v)", next, err)
+	}
+
+	// Branches count on their own and prefix their numbers
+	branch := &models.Branch{TenantID: tenant.ID, Name: "Downtown", BranchCode: "dt"}
+	db.Create(branch)
+	if number, err := service.NextReceiptNumber(tenant.ID, &branch.ID); err != nil || number != "RCT-DT-000001" {
+		t.Errorf("Expected the branch's first receipt RCT-DT-000001, got %s (%v)", number, err)
+	}
+
+	// A branch whose code formats the same keeps its own sequence without colliding with the other's numbers
+	twin := &models.Branch{TenantID: tenant.ID, Name: "Downtown East", BranchCode: "DT"}
+	db.Create(twin)
+	if number, err := service.IssueReceiptNumber(tenant.ID, &branch.ID, models.ReceiptSourceTransaction, "txn-dt-1"); err != nil || number != "RCT-DT-000002" {
+		t.Errorf("Expected RCT-DT-000002 for the first branch, got %s (%v)", number, err)
+	}
+	for i, want := range []string{"RCT-DT-000001", "RCT-DT-000002"} {
+		sourceID := fmt.Sprintf("txn-twin-%d", i)
+		if number, err := service.IssueReceiptNumber(tenant.ID, &twin.ID, models.ReceiptSourceTransaction, sourceID); err != nil || number != want {
+			t.Errorf("Expected %s for the twin branch, got %s (%v)", want, number, err)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// receiptTemplateTagRe matches {{path}}, {{#if path}}, {{#each path}}, {{else}}, {{/if}} and {{/each}}
+var receiptTemplateTagRe = regexp.MustCompile(`\{\{\s*(#if|#each|/if|/each|else)?\s*([a-zA-Z0-9_.@]*)\s*\}\}`)
+
+// receiptNode is one piece of a parsed receipt template
+type receiptNode struct {
+	kind     string // text, var, if or each
+	value    string // literal text, or the variable path
+	children []receiptNode
+	elseBody []receiptNode // rendered by an if whose value is empty
+}
+
+// parseReceiptTemplate parses template content into nodes. Unbalanced blocks are reported as an error,
+// but the nodes are still usable: unclosed blocks end with the content and stray closing tags stay as text.
+func parseReceiptTemplate(content string) ([]receiptNode, error) {
+	type frame struct {
+		node   *receiptNode
+		inElse bool
+	}
+	root := &receiptNode{kind: "root"}
+	stack := []*frame{{node: root}}
+	var parseErr error
+	fail := func(format string, args ...interface{}) {
+		if parseErr == nil {
+			parseErr = fmt.Errorf(format, args...)
+		}
+	}
+	appendNode := func(n receiptNode) {
+		top := stack[len(stack)-1]
+		if top.inElse {
+			top.node.elseBody = append(top.node.elseBody, n)
+		} else {
+			top.node.children = append(top.node.children, n)
+		}
+	}
+
+	last := 0
+	for _, m := range receiptTemplateTagRe.FindAllStringSubmatchIndex(content, -1) {
+		if m[0] > last {
+			appendNode(receiptNode{kind: "text", value: content[last:m[0]]})
+		}
+		last = m[1]
+		tag := content[m[0]:m[1]]
+		keyword, path := "", content[m[4]:m[5]]
+		if m[2] >= 0 {
+			keyword = content[m[2]:m[3]]
+		}
+
+		switch keyword {
+		case "":
+			if path == "" {
+				appendNode(receiptNode{kind: "text", value: tag})
+				continue
+			}
+			appendNode(receiptNode{kind: "var", value: path})
+		case "#if", "#each":
+			if path == "" {
+				fail("%s needs a variable", tag)
+				appendNode(receiptNode{kind: "text", value: tag})
+				continue
+			}
+			stack = append(stack, &frame{node: &receiptNode{kind: keyword[1:], value: path}})
+		case "else":
+			top := stack[len(stack)-1]
+			if top.node.kind != "if" || top.inElse {
+				fail("unexpected {{else}}")
+				appendNode(receiptNode{kind: "text", value: tag})
+				continue
+			}
+			top.inElse = true
+		case "/if", "/each":
+			top := stack[len(stack)-1]
+			if top.node.kind != keyword[1:] {
+				fail("unexpected %s", tag)
+				appendNode(receiptNode{kind: "text", value: tag})
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			appendNode(*top.node)
+		}
+	}
+	if last < len(content) {
+		appendNode(receiptNode{kind: "text", value: content[last:]})
+	}
+
+	for len(stack) > 1 {
+		top := stack[len(stack)-1]
+		fail("unclosed {{#%s %s}}", top.node.kind, top.node.value)
+		stack = stack[:len(stack)-1]
+		appendNode(*top.node)
+	}
+	return root.children, parseErr
+}
+
+// receiptScope is the data a template is rendered against; inside an each block the current item is
+// looked up first, then the enclosing scopes
+type receiptScope struct {
+	data   interface{}
+	index  int
+	parent *receiptScope
+}
+
+// renderReceiptTemplate fills template content from data. Paths are looked up as flat keys ("send.amount")
+// or by walking nested maps, slices and structs, and dates are formatted for the locale. Unknown variables
+// render empty, or as a visible placeholder when preview is set.
+func renderReceiptTemplate(content string, data map[string]interface{}, locale string, preview bool) string {
+	nodes, _ := parseReceiptTemplate(content)
+	var b strings.Builder
+	renderReceiptNodes(&b, nodes, &receiptScope{data: data}, locale, preview)
+	return b.String()
+}
+
+func renderReceiptNodes(b *strings.Builder, nodes []receiptNode, scope *receiptScope, locale string, preview bool) {
+	for _, n := range nodes {
+		switch n.kind {
+		case "text":
+			b.WriteString(n.value)
+		case "var":
+			value, ok := scope.lookup(n.value)
+			if !ok || value == nil {
+				if preview {
+					fmt.Fprintf(b, `<mark class="receipt-missing-variable">{{%s}}</mark>`, n.value)
+				}
+				continue
+			}
+			b.WriteString(formatReceiptValue(value, locale))
+		case "if":
+			value, _ := scope.lookup(n.value)
+			if receiptTruthy(value) {
+				renderReceiptNodes(b, n.children, scope, locale, preview)
+			} else {
+				renderReceiptNodes(b, n.elseBody, scope, locale, preview)
+			}
+		case "each":
+			value, _ := scope.lookup(n.value)
+			items := reflect.ValueOf(value)
+			if value == nil || (items.Kind() != reflect.Slice && items.Kind() != reflect.Array) {
+				continue
+			}
+			for i := 0; i < items.Len(); i++ {
+				renderReceiptNodes(b, n.children, &receiptScope{data: items.Index(i).Interface(), index: i, parent: scope}, locale, preview)
+			}
+		}
+	}
+}
+
+// lookup resolves a path in the scope, then in each enclosing scope. {{this}} is the current item and
+// {{@index}} its position counting from 1.
+func (s *receiptScope) lookup(path string) (interface{}, bool) {
+	switch path {
+	case "this":
+		return s.data, true
+	case "@index":
+		if s.parent == nil {
+			return nil, false
+		}
+		return s.index + 1, true
+	}
+	for scope := s; scope != nil; scope = scope.parent {
+		if value, ok := receiptLookup(scope.data, strings.Split(path, ".")); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// receiptLookup walks parts into data, preferring the longest flat key at each level so data keyed
+// "beneficiary.name" and data nested as beneficiary → name both resolve
+func receiptLookup(data interface{}, parts []string) (interface{}, bool) {
+	if len(parts) == 0 {
+		return data, true
+	}
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, false
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		for i := len(parts); i > 0; i-- {
+			entry := value.MapIndex(reflect.ValueOf(strings.Join(parts[:i], ".")).Convert(value.Type().Key()))
+			if !entry.IsValid() {
+				continue
+			}
+			if found, ok := receiptLookup(entry.Interface(), parts[i:]); ok {
+				return found, true
+			}
+		}
+	case reflect.Struct:
+		field := value.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, parts[0]) })
+		if field.IsValid() && field.CanInterface() {
+			return receiptLookup(field.Interface(), parts[1:])
+		}
+	}
+	return nil, false
+}
+
+// receiptTruthy reports whether an if block should render: missing values, false, zero, blank strings
+// and empty lists or maps are false
+func receiptTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	// Decimals and times know their own zero value
+	if zeroer, ok := value.(interface{ IsZero() bool }); ok {
+		return !zeroer.IsZero()
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) != ""
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > 0
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil() && receiptTruthy(v.Elem().Interface())
+	}
+	return !v.IsZero()
+}
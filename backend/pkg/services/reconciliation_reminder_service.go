@@ -0,0 +1,226 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reconciliationLookbackDays bounds how far back consecutive misses are counted
+const reconciliationLookbackDays = 30
+
+// ReconciliationReminderService escalates branches that repeatedly miss their daily reconciliation
+type ReconciliationReminderService struct {
+	db                    *gorm.DB
+	reconciliationService *ReconciliationService
+	settingsService       *TenantSettingsService
+}
+
+// NewReconciliationReminderService creates a new ReconciliationReminderService
+func NewReconciliationReminderService(db *gorm.DB) *ReconciliationReminderService {
+	return &ReconciliationReminderService{
+		db:                    db,
+		reconciliationService: NewReconciliationService(db),
+		settingsService:       NewTenantSettingsService(db),
+	}
+}
+
+// ReconciliationEscalationLevel returns the most severe escalation whose threshold has been reached.
+// A threshold of 0 disables that level.
+func ReconciliationEscalationLevel(missedDays int, settings *models.TenantSettings) string {
+	reached := func(threshold int) bool {
+		return threshold > 0 && missedDays >= threshold
+	}
+
+	switch {
+	case reached(settings.ReconciliationTicketAfterDays):
+		return models.ReconciliationEscalationTicket
+	case reached(settings.ReconciliationAlertAfterDays):
+		return models.ReconciliationEscalationAdminAlert
+	case reached(settings.ReconciliationWarnAfterDays):
+		return models.ReconciliationEscalationWarning
+	}
+	return models.ReconciliationEscalationNone
+}
+
+// CheckTenant escalates every branch of the tenant that did not reconcile on the given business day
+// and returns the reminders sent
+func (s *ReconciliationReminderService) CheckTenant(tenantID uint, businessDay time.Time) ([]models.ReconciliationReminder, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := s.reconciliationService.GetBranchesMissingReconciliation(tenantID, businessDay)
+	if err != nil {
+		return nil, err
+	}
+
+	day := startOfDay(businessDay)
+	var sent []models.ReconciliationReminder
+	for _, branch := range branches {
+		missed, err := s.reconciliationService.ConsecutiveMissedDays(branch, day, reconciliationLookbackDays)
+		if err != nil {
+			return sent, err
+		}
+
+		level := ReconciliationEscalationLevel(missed, settings)
+		if level == models.ReconciliationEscalationNone {
+			continue
+		}
+
+		var existing int64
+		s.db.Model(&models.ReconciliationReminder{}).
+			Where("branch_id = ? AND date = ? AND level = ?", branch.ID, day, level).
+			Count(&existing)
+		if existing > 0 {
+			continue
+		}
+
+		// One ticket per lapse: later runs leave it to the open ticket until it is resolved
+		if level == models.ReconciliationEscalationTicket {
+			open, err := s.hasOpenReminderTicket(branch.ID)
+			if err != nil {
+				return sent, err
+			}
+			if open {
+				continue
+			}
+		}
+
+		reminder := models.ReconciliationReminder{
+			TenantID:   tenantID,
+			BranchID:   branch.ID,
+			Date:       day,
+			Level:      level,
+			MissedDays: missed,
+		}
+		// The ticket and the reminder linking it are saved together, so a failed save cannot leave a
+		// ticket that the open-ticket check above would never see
+		var message *WSMessage
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if message, err = s.escalate(tx, branch, &reminder); err != nil {
+				return err
+			}
+			return tx.Create(&reminder).Error
+		})
+		if err != nil {
+			log.Printf("⚠️  Reconciliation escalation for branch %d failed: %v", branch.ID, err)
+			continue
+		}
+		if message != nil {
+			GetHub().Broadcast(*message)
+		}
+		sent = append(sent, reminder)
+	}
+
+	return sent, nil
+}
+
+// hasOpenReminderTicket reports whether an earlier reminder ticket for the branch is still unresolved
+func (s *ReconciliationReminderService) hasOpenReminderTicket(branchID uint) (bool, error) {
+	var count int64
+	err := s.db.Model(&models.Ticket{}).
+		Joins("JOIN reconciliation_reminders ON reconciliation_reminders.ticket_id = tickets.id").
+		Where("reconciliation_reminders.branch_id = ? AND tickets.status NOT IN ?", branchID,
+			[]models.TicketStatus{models.TicketStatusResolved, models.TicketStatusClosed}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// escalate prepares the reminder's level within tx, opening the ticket when the level calls for one, and
+// returns the notification to broadcast once the reminder is saved, or nil when nobody is to receive it
+func (s *ReconciliationReminderService) escalate(tx *gorm.DB, branch models.Branch, reminder *models.ReconciliationReminder) (*WSMessage, error) {
+	data := map[string]interface{}{
+		"branchId":   branch.ID,
+		"branchName": branch.Name,
+		"date":       reminder.Date.Format("2006-01-02"),
+		"missedDays": reminder.MissedDays,
+		"level":      reminder.Level,
+	}
+
+	message := &WSMessage{Type: "reconciliation", Data: data, TenantID: branch.TenantID}
+	switch reminder.Level {
+	case models.ReconciliationEscalationWarning:
+		message.Action = "missed"
+
+	case models.ReconciliationEscalationAdminAlert:
+		// Only owners' and admins' connections receive the alert
+		var adminIDs []uint
+		if err := tx.Model(&models.User{}).
+			Where("tenant_id = ? AND role IN ?", branch.TenantID, []string{models.RoleTenantOwner, models.RoleTenantAdmin}).
+			Pluck("id", &adminIDs).Error; err != nil {
+			return nil, err
+		}
+		if len(adminIDs) == 0 {
+			return nil, nil
+		}
+		data["recipientUserIds"] = adminIDs
+		message.Action = "escalated"
+		message.UserIDs = adminIDs
+
+	case models.ReconciliationEscalationTicket:
+		var tenant models.Tenant
+		if err := tx.First(&tenant, branch.TenantID).Error; err != nil {
+			return nil, fmt.Errorf("tenant not found: %w", err)
+		}
+
+		branchID := branch.ID
+		ticket, err := NewTicketService(tx).CreateTicket(tenant.ID, tenant.OwnerID, CreateTicketRequest{
+			Subject: fmt.Sprintf("Branch %s has missed reconciliation for %d days", branch.Name, reminder.MissedDays),
+			Description: fmt.Sprintf(
+				"Branch %q has not submitted a daily reconciliation for %d consecutive days (most recent: %s).",
+				branch.Name, reminder.MissedDays, reminder.Date.Format("2006-01-02"),
+			),
+			Priority:          models.TicketPriorityHigh,
+			Category:          models.TicketCategoryGeneral,
+			BranchID:          &branchID,
+			RelatedEntityType: "branch",
+			RelatedEntityID:   branch.ID,
+			Tags:              "reconciliation,automated",
+		})
+		if err != nil {
+			return nil, err
+		}
+		reminder.TicketID = &ticket.ID
+		message.Action = "ticket_created"
+	}
+
+	return message, nil
+}
+
+// CheckAll runs the escalation check for every active tenant
+func (s *ReconciliationReminderService) CheckAll(businessDay time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, businessDay); err != nil {
+			log.Printf("⚠️  Reconciliation reminder check failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start checks the previous business day on the given interval in the background
+func (s *ReconciliationReminderService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Reconciliation reminder scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now().AddDate(0, 0, -1)); err != nil {
+				log.Printf("⚠️  Reconciliation reminder check failed: %v", err)
+			}
+		}
+	}()
+}
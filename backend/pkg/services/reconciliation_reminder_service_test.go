@@ -0,0 +1,121 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// TestReconciliationEscalation_ByConsecutiveMisses verifies the level chosen for each run of missed days
+func TestReconciliationEscalation_ByConsecutiveMisses(t *testing.T) {
+	defaults := models.DefaultTenantSettings(1)
+
+	cases := []struct {
+		missed int
+		want   string
+	}{
+		{0, models.ReconciliationEscalationNone},
+		{1, models.ReconciliationEscalationWarning},
+		{2, models.ReconciliationEscalationAdminAlert},
+		{3, models.ReconciliationEscalationTicket},
+		{9, models.ReconciliationEscalationTicket},
+	}
+	for _, c := range cases {
+		if got := ReconciliationEscalationLevel(c.missed, &defaults); got != c.want {
+			t.Errorf("missed=%d: expected %q, got %q", c.missed, c.want, got)
+		}
+	}
+
+	t.Run("DisabledLevelsSkipped", func(t *testing.T) {
+		settings := models.DefaultTenantSettings(1)
+		settings.ReconciliationAlertAfterDays = 0
+		settings.ReconciliationTicketAfterDays = 0
+		if got := ReconciliationEscalationLevel(5, &settings); got != models.ReconciliationEscalationWarning {
+			t.Errorf("Expected only a warning when alert and ticket are disabled, got %q", got)
+		}
+	})
+
+	t.Run("ConsecutiveMissesFromHistory", func(t *testing.T) {
+		db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.DailyReconciliation{},
+			&models.ReconciliationReminder{}, &models.TenantSettings{})
+
+		tenant := newTestTenant(t, db, "Reminder Exchange")
+
+		today := startOfDay(time.Now())
+		reconciled := &models.Branch{TenantID: tenant.ID, Name: "Reconciled", BranchCode: "R1", Status: models.BranchStatusActive, CreatedAt: today.AddDate(0, 0, -10)}
+		lapsed := &models.Branch{TenantID: tenant.ID, Name: "Lapsed", BranchCode: "L1", Status: models.BranchStatusActive, CreatedAt: today.AddDate(0, 0, -10)}
+		db.Create(reconciled)
+		db.Create(lapsed)
+
+		db.Create(&models.DailyReconciliation{TenantID: tenant.ID, BranchID: reconciled.ID, Date: today.Add(2 * time.Hour), CreatedByUserID: 1})
+		db.Create(&models.DailyReconciliation{TenantID: tenant.ID, BranchID: lapsed.ID, Date: today.AddDate(0, 0, -3), CreatedByUserID: 1})
+
+		reconciliationService := NewReconciliationService(db)
+		missing, err := reconciliationService.GetBranchesMissingReconciliation(tenant.ID, today)
+		if err != nil {
+			t.Fatalf("Failed to list missing branches: %v", err)
+		}
+		if len(missing) != 1 || missing[0].ID != lapsed.ID {
+			t.Fatalf("Expected only the lapsed branch to be missing, got %d branches", len(missing))
+		}
+
+		missed, err := reconciliationService.ConsecutiveMissedDays(*lapsed, today, reconciliationLookbackDays)
+		if err != nil {
+			t.Fatalf("Failed to count missed days: %v", err)
+		}
+		if missed != 3 {
+			t.Errorf("Expected 3 consecutive missed days, got %d", missed)
+		}
+		if got := ReconciliationEscalationLevel(missed, &defaults); got != models.ReconciliationEscalationTicket {
+			t.Errorf("Expected ticket escalation after 3 missed days, got %q", got)
+		}
+	})
+}
+
+// TestCheckTenant_OneTicketPerLapse verifies daily runs do not open a new ticket while the branch's reminder
+// ticket is still open, and that a new one is opened once it has been resolved
+func TestCheckTenant_OneTicketPerLapse(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.DailyReconciliation{},
+		&models.ReconciliationReminder{}, &models.TenantSettings{}, &models.Ticket{}, &models.TicketActivity{})
+
+	owner := &models.User{Email: "owner@reminder.test", Role: models.RoleTenantOwner}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Lapse Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+
+	today := startOfDay(time.Now())
+	branch := &models.Branch{TenantID: tenant.ID, Name: "Lapsed", BranchCode: "L2", Status: models.BranchStatusActive, CreatedAt: today.AddDate(0, 0, -10)}
+	db.Create(branch)
+
+	service := NewReconciliationReminderService(db)
+	countTickets := func() int64 {
+		var count int64
+		db.Model(&models.Ticket{}).Where("tenant_id = ? AND branch_id = ?", tenant.ID, branch.ID).Count(&count)
+		return count
+	}
+
+	first, err := service.CheckTenant(tenant.ID, today.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if len(first) != 1 || first[0].TicketID == nil {
+		t.Fatalf("Expected the first run to open a ticket, got %+v", first)
+	}
+
+	second, err := service.CheckTenant(tenant.ID, today)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if len(second) != 0 || countTickets() != 1 {
+		t.Fatalf("Expected the next day's run to leave the open ticket alone, got %d reminders and %d tickets", len(second), countTickets())
+	}
+
+	db.Model(&models.Ticket{}).Where("id = ?", *first[0].TicketID).Update("status", models.TicketStatusResolved)
+	third, err := service.CheckTenant(tenant.ID, today)
+	if err != nil {
+		t.Fatalf("Third run failed: %v", err)
+	}
+	if len(third) != 1 || countTickets() != 2 {
+		t.Errorf("Expected a new ticket once the first was resolved, got %d reminders and %d tickets", len(third), countTickets())
+	}
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"api/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
@@ -80,8 +83,14 @@ func (s *ReconciliationService) GetSystemState(tenantID, branchID uint) ([]Expec
 	return results, nil
 }
 
-// CreateReconciliation creates a new daily reconciliation record
-func (s *ReconciliationService) CreateReconciliation(reconciliation *models.DailyReconciliation) error {
+// CreateReconciliation creates a new daily reconciliation record.
+// denominations is an optional per-currency breakdown of the physical count; each
+// currency's breakdown must add up to the amount stated in CurrencyBreakdown.
+func (s *ReconciliationService) CreateReconciliation(reconciliation *models.DailyReconciliation, denominations map[string][]DenominationCount) error {
+	if err := validateReconciliationDenominations(reconciliation, denominations); err != nil {
+		return err
+	}
+
 	// Calculate expected balance based on transactions for the day
 	expectedBalance, err := s.CalculateExpectedBalance(reconciliation.BranchID, reconciliation.Date)
 	if err != nil {
@@ -91,7 +100,236 @@ func (s *ReconciliationService) CreateReconciliation(reconciliation *models.Dail
 	reconciliation.ExpectedBalance = expectedBalance
 	reconciliation.Variance = reconciliation.ClosingBalance - expectedBalance
 
-	return s.DB.Create(reconciliation).Error
+	return s.saveReconciliation(reconciliation, denominations)
+}
+
+// validateReconciliationDenominations checks each currency's denomination count against the
+// amount stated for it in the reconciliation's CurrencyBreakdown
+func validateReconciliationDenominations(reconciliation *models.DailyReconciliation, denominations map[string][]DenominationCount) error {
+	if len(denominations) == 0 {
+		return nil
+	}
+
+	stated := make(map[string]float64)
+	if reconciliation.CurrencyBreakdown != "" {
+		if err := json.Unmarshal([]byte(reconciliation.CurrencyBreakdown), &stated); err != nil {
+			return fmt.Errorf("invalid currency breakdown: %w", err)
+		}
+	}
+
+	for currency, counts := range denominations {
+		// A currency without a stated total is treated as zero
+		if _, err := ValidateDenominations(currency, models.NewDecimal(stated[currency]), counts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveReconciliation stores the reconciliation together with its denomination rows
+func (s *ReconciliationService) saveReconciliation(reconciliation *models.DailyReconciliation, denominations map[string][]DenominationCount) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reconciliation).Error; err != nil {
+			return err
+		}
+
+		branchID := reconciliation.BranchID
+		for currency, counts := range denominations {
+			rows := buildDenominationRows(reconciliation.TenantID, &branchID, currency, &reconciliation.ID, counts, reconciliation.CreatedByUserID)
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReconciliationCurrencyMatch is the expected closing balance of one currency and, once counted, its variance
+type ReconciliationCurrencyMatch struct {
+	Currency  string          `json:"currency"`
+	Opening   models.Decimal  `json:"opening"` // Counted closing of the previous reconciliation
+	CashIn    models.Decimal  `json:"cashIn"`  // Cash taken in at the counter since then
+	CashOut   models.Decimal  `json:"cashOut"` // Cash paid out or taken from the till since then (negative)
+	Expected  models.Decimal  `json:"expected"`
+	Tolerance models.Decimal  `json:"tolerance"`
+	Counted   *models.Decimal `json:"counted,omitempty"`
+	Variance  *models.Decimal `json:"variance,omitempty"` // Counted - Expected
+	Flagged   bool            `json:"flagged"`            // Variance is beyond the currency's tolerance
+}
+
+// ReconciliationMatch is a branch's expected closing cash for a day, matched against the counted amounts if given
+type ReconciliationMatch struct {
+	BranchID          uint                          `json:"branchId"`
+	Date              time.Time                     `json:"date"`
+	PreviousDate      *time.Time                    `json:"previousDate,omitempty"` // Day of the reconciliation the opening figures come from
+	Tolerance         models.Decimal                `json:"tolerance"`              // Used for currencies without a tolerance of their own
+	Currencies        []ReconciliationCurrencyMatch `json:"currencies"`
+	ExpectedBalance   float64                       `json:"expectedBalance"`
+	CountedBalance    float64                       `json:"countedBalance"`
+	VarianceFlagged   bool                          `json:"varianceFlagged"`
+	FlaggedCurrencies []string                      `json:"flaggedCurrencies"`
+}
+
+// AutoMatch computes the branch's expected closing cash per currency at the end of date. Each currency
+// starts from the counted closing of the branch's last earlier reconciliation (zero if there is none) and
+// adds the applied cash adjustments made since that day. Every movement of cash through the till - a cash
+// payment, its edit, refund or reversal, or a manual adjustment - is recorded there once, so bank transfers,
+// card payments and book entries in the ledger never count as cash. counted, if non-nil, holds the amounts
+// staff counted; a currency that was not counted is taken as zero and any variance beyond the currency's
+// tolerance is flagged.
+func (s *ReconciliationService) AutoMatch(tenantID, branchID uint, date time.Time, counted map[string]float64) (*ReconciliationMatch, error) {
+	dayStart := startOfDay(date)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	settings, err := NewTenantSettingsService(s.DB).GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	match := &ReconciliationMatch{
+		BranchID:          branchID,
+		Date:              dayStart,
+		Tolerance:         settings.ReconciliationVarianceTolerance,
+		FlaggedCurrencies: make([]string, 0),
+	}
+	currencies := make(map[string]*ReconciliationCurrencyMatch)
+	entry := func(currency string) *ReconciliationCurrencyMatch {
+		if currencies[currency] == nil {
+			currencies[currency] = &ReconciliationCurrencyMatch{Currency: currency}
+		}
+		return currencies[currency]
+	}
+
+	var previous models.DailyReconciliation
+	err = s.DB.Where("tenant_id = ? AND branch_id = ? AND date < ?", tenantID, branchID, dayStart).
+		Order("date DESC, created_at DESC").
+		First(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	adjustments := s.DB.Model(&models.CashAdjustment{}).
+		Select("currency, amount").
+		Where("tenant_id = ? AND branch_id = ? AND status = ? AND created_at < ?",
+			tenantID, branchID, models.CashAdjustmentStatusApplied, dayEnd)
+
+	if err == nil {
+		opening := make(map[string]float64)
+		if previous.CurrencyBreakdown != "" {
+			if err := json.Unmarshal([]byte(previous.CurrencyBreakdown), &opening); err != nil {
+				return nil, fmt.Errorf("invalid currency breakdown on reconciliation %d: %w", previous.ID, err)
+			}
+		}
+		for currency, amount := range opening {
+			entry(currency).Opening = models.NewDecimal(amount)
+		}
+
+		previousDate := startOfDay(previous.Date)
+		match.PreviousDate = &previousDate
+		since := previousDate.AddDate(0, 0, 1)
+		adjustments = adjustments.Where("created_at >= ?", since)
+	}
+
+	// Amounts are summed as decimals rather than in SQL so no precision is lost on either driver
+	var movements []struct {
+		Currency string
+		Amount   models.Decimal
+	}
+	if err := adjustments.Scan(&movements).Error; err != nil {
+		return nil, err
+	}
+	for _, movement := range movements {
+		e := entry(movement.Currency)
+		if movement.Amount.IsNegative() {
+			e.CashOut = e.CashOut.Add(movement.Amount)
+		} else {
+			e.CashIn = e.CashIn.Add(movement.Amount)
+		}
+	}
+
+	for currency := range counted {
+		entry(currency)
+	}
+
+	match.Currencies = make([]ReconciliationCurrencyMatch, 0, len(currencies))
+	for _, e := range currencies {
+		e.Expected = e.Opening.Add(e.CashIn).Add(e.CashOut)
+		match.ExpectedBalance += e.Expected.Float64()
+		e.Tolerance = match.Tolerance
+		if tolerance, ok := settings.ReconciliationCurrencyTolerances[e.Currency]; ok {
+			e.Tolerance = models.NewDecimal(tolerance)
+		}
+
+		if counted != nil {
+			amount := models.NewDecimal(counted[e.Currency])
+			variance := amount.Sub(e.Expected)
+			e.Counted, e.Variance = &amount, &variance
+			e.Flagged = variance.Abs().GreaterThan(e.Tolerance)
+			match.CountedBalance += amount.Float64()
+		}
+		match.Currencies = append(match.Currencies, *e)
+	}
+
+	sort.Slice(match.Currencies, func(i, j int) bool {
+		return match.Currencies[i].Currency < match.Currencies[j].Currency
+	})
+	for _, e := range match.Currencies {
+		if e.Flagged {
+			match.FlaggedCurrencies = append(match.FlaggedCurrencies, e.Currency)
+		}
+	}
+	match.VarianceFlagged = len(match.FlaggedCurrencies) > 0
+
+	return match, nil
+}
+
+// CreateMatchedReconciliation saves a reconciliation whose expected figures come from AutoMatch, so staff
+// only supply the counted amount per currency. The reconciliation's CurrencyBreakdown, balances and
+// variance are filled in from the match, which is returned alongside.
+func (s *ReconciliationService) CreateMatchedReconciliation(reconciliation *models.DailyReconciliation, counted map[string]float64, denominations map[string][]DenominationCount) (*ReconciliationMatch, error) {
+	if counted == nil {
+		counted = make(map[string]float64)
+	}
+	countedJSON, err := json.Marshal(counted)
+	if err != nil {
+		return nil, err
+	}
+	reconciliation.CurrencyBreakdown = string(countedJSON)
+
+	if err := validateReconciliationDenominations(reconciliation, denominations); err != nil {
+		return nil, err
+	}
+
+	match, err := s.AutoMatch(reconciliation.TenantID, reconciliation.BranchID, reconciliation.Date, counted)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]float64, len(match.Currencies))
+	opening := 0.0
+	for _, e := range match.Currencies {
+		expected[e.Currency] = e.Expected.Float64()
+		opening += e.Opening.Float64()
+	}
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation.OpeningBalance = opening
+	reconciliation.ClosingBalance = match.CountedBalance
+	reconciliation.ExpectedBalance = match.ExpectedBalance
+	reconciliation.Variance = match.CountedBalance - match.ExpectedBalance
+	reconciliation.ExpectedBreakdown = string(expectedJSON)
+	reconciliation.VarianceFlagged = match.VarianceFlagged
+
+	if err := s.saveReconciliation(reconciliation, denominations); err != nil {
+		return nil, err
+	}
+	return match, nil
 }
 
 // CalculateExpectedBalance calculates the expected cash balance based on transactions
@@ -170,3 +408,57 @@ func (s *ReconciliationService) GetVarianceReport(tenantID uint) ([]models.Daily
 
 	return reconciliations, err
 }
+
+// startOfDay truncates t to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// GetBranchesMissingReconciliation returns the tenant's active branches with no reconciliation for the given day
+func (s *ReconciliationService) GetBranchesMissingReconciliation(tenantID uint, date time.Time) ([]models.Branch, error) {
+	dayStart := startOfDay(date)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	reconciled := s.DB.Model(&models.DailyReconciliation{}).
+		Select("branch_id").
+		Where("tenant_id = ? AND date >= ? AND date < ?", tenantID, dayStart, dayEnd)
+
+	var branches []models.Branch
+	err := s.DB.Where("tenant_id = ? AND status = ? AND created_at < ?", tenantID, models.BranchStatusActive, dayEnd).
+		Where("id NOT IN (?)", reconciled).
+		Order("id ASC").
+		Find(&branches).Error
+	return branches, err
+}
+
+// ConsecutiveMissedDays counts the days up to and including asOf on which the branch did not
+// reconcile, stopping at the last reconciliation, the branch's creation day, or maxDays
+func (s *ReconciliationService) ConsecutiveMissedDays(branch models.Branch, asOf time.Time, maxDays int) (int, error) {
+	day := startOfDay(asOf)
+	lookbackStart := day.AddDate(0, 0, -(maxDays - 1))
+
+	var last models.DailyReconciliation
+	err := s.DB.Where("tenant_id = ? AND branch_id = ? AND date < ?", branch.TenantID, branch.ID, day.AddDate(0, 0, 1)).
+		Order("date DESC").
+		First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	firstMissed := lookbackStart
+	if err == nil {
+		if next := startOfDay(last.Date).AddDate(0, 0, 1); next.After(firstMissed) {
+			firstMissed = next
+		}
+	}
+	if created := startOfDay(branch.CreatedAt.In(day.Location())); created.After(firstMissed) {
+		firstMissed = created
+	}
+
+	missed := 0
+	for d := firstMissed; !d.After(day); d = d.AddDate(0, 0, 1) {
+		missed++
+	}
+	return missed, nil
+}
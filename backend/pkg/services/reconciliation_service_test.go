@@ -0,0 +1,132 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReconciliationAutoMatch_ExpectedFromCashMovements verifies expected closing balances are built from the
+// previous count and the cash that moved through the till, counting each cash payment once and leaving bank
+// transfers and card payments out, and that variances beyond each currency's tolerance are flagged
+func TestReconciliationAutoMatch_ExpectedFromCashMovements(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.DailyReconciliation{},
+		&models.CashDenomination{}, &models.LedgerEntry{}, &models.CashAdjustment{}, &models.TenantSettings{},
+		&models.Client{}, &models.Transaction{}, &models.Payment{}, &models.PaymentPlan{}, &models.PaymentInstallment{},
+		&models.CashBalance{}, &models.DailyClose{})
+
+	tenant := newTestTenant(t, db, "Auto Match Exchange")
+	user := newTestUser(t, db, tenant.ID, "teller@automatch.test", models.RoleTenantOwner)
+	branch := newTestBranch(t, db, tenant.ID, "Main", "M1")
+	other := newTestBranch(t, db, tenant.ID, "Other", "O1")
+
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.ReconciliationVarianceTolerance = models.NewDecimal(1)
+	settings.ReconciliationCurrencyTolerances = map[string]float64{"USD": 0.1}
+	db.Create(&settings)
+
+	today := startOfDay(time.Now())
+	previousBreakdown, _ := json.Marshal(map[string]float64{"USD": 1000, "CAD": 500})
+	db.Create(&models.DailyReconciliation{TenantID: tenant.ID, BranchID: branch.ID, Date: today.AddDate(0, 0, -2),
+		CurrencyBreakdown: string(previousBreakdown), CreatedByUserID: 1})
+
+	db.Create(&models.Client{ID: "client-1", TenantID: tenant.ID, Name: "Match Client", PhoneNumber: "+14165550190"})
+	newTransaction := func(id, currency string, branchID uint) {
+		require.NoError(t, db.Create(&models.Transaction{ID: id, TenantID: tenant.ID, ClientID: "client-1", BranchID: &branchID,
+			PaymentMethod: models.TransactionMethodCash, SendCurrency: currency, SendAmount: models.NewDecimal(1000),
+			ReceiveCurrency: "IRR", ReceiveAmount: models.NewDecimal(85000000), ReceivedCurrency: currency,
+			TotalReceived: models.NewDecimal(1000), RemainingBalance: models.NewDecimal(1000), RateApplied: models.NewDecimal(85000),
+			AllowPartialPayment: true, PaymentStatus: models.PaymentStatusOpen, Status: models.StatusCompleted}).Error)
+	}
+	newTransaction("txn-usd", "USD", branch.ID)
+	newTransaction("txn-eur", "EUR", branch.ID)
+	newTransaction("txn-other", "USD", other.ID)
+
+	paymentService := NewPaymentService(db, NewLedgerService(db), NewCashBalanceService(db))
+	pay := func(transactionID, currency string, branchID uint, amount float64, method string, details map[string]interface{}) *models.Payment {
+		t.Helper()
+		payment := &models.Payment{TenantID: tenant.ID, TransactionID: transactionID, BranchID: &branchID, Amount: models.NewDecimal(amount),
+			Currency: currency, ExchangeRate: models.NewDecimal(1), PaymentMethod: method, Details: details}
+		require.NoError(t, paymentService.CreatePayment(payment, user.ID))
+		return payment
+	}
+	cash := pay("txn-usd", "USD", branch.ID, 300, models.PaymentMethodCash, nil)
+	pay("txn-usd", "USD", branch.ID, 200, models.PaymentMethodBankTransfer, map[string]interface{}{"referenceId": "WIRE-1"})
+	pay("txn-usd", "USD", branch.ID, 100, models.PaymentMethodCard, map[string]interface{}{"lastFourDigits": "4242"})
+	_, err := paymentService.RefundPayment(cash.ID, tenant.ID, models.NewDecimal(50), "Overcharge", user.ID)
+	require.NoError(t, err)
+	pay("txn-eur", "EUR", branch.ID, 200, models.PaymentMethodCash, nil)
+	pay("txn-other", "USD", other.ID, 700, models.PaymentMethodCash, nil)
+
+	branchID := branch.ID
+	adjust := func(currency string, amount float64, status string, at time.Time) {
+		db.Create(&models.CashAdjustment{TenantID: tenant.ID, BranchID: &branchID, Currency: currency, Amount: models.NewDecimal(amount),
+			Reason: "Manual", AdjustedBy: user.ID, Status: status, CreatedAt: at})
+	}
+	adjust("USD", 999, models.CashAdjustmentStatusApplied, today.AddDate(0, 0, -2).Add(10*time.Hour)) // Already in the previous count
+	adjust("CAD", -20, models.CashAdjustmentStatusApplied, today.Add(time.Hour))                      // Float sent to head office
+	adjust("CAD", -300, models.CashAdjustmentStatusPending, today.Add(time.Hour))                     // Not approved yet
+	adjust("USD", 40, models.CashAdjustmentStatusApplied, today.AddDate(0, 0, 1).Add(9*time.Hour))    // After the day
+
+	service := NewReconciliationService(db)
+
+	t.Run("ExpectedBalances", func(t *testing.T) {
+		match, err := service.AutoMatch(tenant.ID, branch.ID, today, nil)
+		if err != nil {
+			t.Fatalf("Failed to auto-match: %v", err)
+		}
+		want := map[string]float64{"CAD": 480, "EUR": 200, "USD": 1250}
+		if len(match.Currencies) != len(want) {
+			t.Fatalf("Expected %d currencies, got %+v", len(want), match.Currencies)
+		}
+		for _, c := range match.Currencies {
+			if c.Expected.Float64() != want[c.Currency] {
+				t.Errorf("%s: expected %v, got %v", c.Currency, want[c.Currency], c.Expected.Float64())
+			}
+			if c.Currency == "USD" && (c.CashIn.Float64() != 300 || c.CashOut.Float64() != -50) {
+				t.Errorf("USD: expected 300 in and 50 out, got %v in and %v out", c.CashIn.Float64(), c.CashOut.Float64())
+			}
+			if c.Counted != nil || c.Flagged {
+				t.Errorf("%s: expected an uncounted draft, got counted %v flagged %v", c.Currency, c.Counted, c.Flagged)
+			}
+		}
+		if match.PreviousDate == nil || !match.PreviousDate.Equal(today.AddDate(0, 0, -2)) {
+			t.Errorf("Expected opening figures from two days ago, got %v", match.PreviousDate)
+		}
+	})
+
+	t.Run("VariancesBeyondToleranceFlagged", func(t *testing.T) {
+		reconciliation := &models.DailyReconciliation{TenantID: tenant.ID, BranchID: branch.ID, Date: today, CreatedByUserID: 1}
+		match, err := service.CreateMatchedReconciliation(reconciliation, map[string]float64{"USD": 1249.5, "CAD": 479.5, "EUR": 199.5}, nil)
+		if err != nil {
+			t.Fatalf("Failed to create matched reconciliation: %v", err)
+		}
+
+		// The same half-unit shortfall is beyond USD's own tolerance but within the default elsewhere
+		if len(match.FlaggedCurrencies) != 1 || match.FlaggedCurrencies[0] != "USD" {
+			t.Errorf("Expected only USD to be flagged, got %v", match.FlaggedCurrencies)
+		}
+		for _, c := range match.Currencies {
+			if c.Variance.Float64() != -0.5 {
+				t.Errorf("%s: expected a -0.5 variance, got %v", c.Currency, c.Variance.Float64())
+			}
+			if want := map[string]float64{"USD": 0.1, "CAD": 1, "EUR": 1}[c.Currency]; c.Tolerance.Float64() != want {
+				t.Errorf("%s: expected tolerance %v, got %v", c.Currency, want, c.Tolerance.Float64())
+			}
+		}
+
+		var saved models.DailyReconciliation
+		db.First(&saved, reconciliation.ID)
+		if !saved.VarianceFlagged || saved.ExpectedBalance != 1930 || saved.ClosingBalance != 1928.5 || saved.OpeningBalance != 1500 {
+			t.Errorf("Expected a flagged reconciliation with the matched totals, got %+v", saved)
+		}
+		expected := map[string]float64{}
+		json.Unmarshal([]byte(saved.ExpectedBreakdown), &expected)
+		if expected["CAD"] != 480 || expected["USD"] != 1250 {
+			t.Errorf("Expected the expected breakdown to be stored, got %v", saved.ExpectedBreakdown)
+		}
+	})
+}
@@ -19,6 +19,7 @@ func TestRemittanceLossScenario(t *testing.T) {
 		&models.User{},
 		&models.Branch{},
 		&models.License{},
+		&models.TenantSettings{},
 		&models.OutgoingRemittance{},
 		&models.IncomingRemittance{},
 		&models.RemittanceSettlement{},
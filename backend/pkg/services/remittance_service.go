@@ -2,21 +2,28 @@ package services
 
 import (
 	"api/pkg/models"
+	"api/pkg/utils"
 	"errors"
 	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 // RemittanceService handles remittance business logic
 type RemittanceService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	notifier NotificationProvider
 }
 
 func NewRemittanceService(db *gorm.DB) *RemittanceService {
-	return &RemittanceService{db: db}
+	return &RemittanceService{db: db, notifier: NewNotificationProvider()}
 }
 
 // generateRemittanceCode generates a unique remittance code atomically using FOR UPDATE lock
@@ -77,11 +84,87 @@ func (s *RemittanceService) CreateOutgoingRemittance(req *models.OutgoingRemitta
 			return fmt.Errorf("failed to generate remittance code: %w", err)
 		}
 		req.RemittanceCode = code
+		if req.TrackingCode, err = generateTrackingCode(); err != nil {
+			return err
+		}
+
+		if req.TransactionID != nil {
+			var found int64
+			if err := tx.Model(&models.Transaction{}).
+				Where("id = ? AND tenant_id = ?", *req.TransactionID, req.TenantID).
+				Count(&found).Error; err != nil {
+				return fmt.Errorf("failed to check linked transaction: %w", err)
+			}
+			if found == 0 {
+				return ErrRemittanceTransactionNotFound
+			}
+		}
+
+		if err := s.linkSenderClient(tx, req); err != nil {
+			return err
+		}
+
+		reason, err := s.samePartyReason(tx, req.TenantID, req.SenderPhone, req.RecipientPhone,
+			"incoming_remittances", "recipient_phone", "sender_iban", req.RecipientIBAN)
+		if err != nil {
+			return err
+		}
+		if req.SamePartyFlag, err = s.applySamePartyPolicy(tx, req.TenantID, reason); err != nil {
+			return err
+		}
 
 		return tx.Create(req).Error
 	})
 }
 
+// linkSenderClient links an outgoing remittance to the CRM client with the sender's phone number, creating
+// the client when none matches. It only runs when the tenant has AutoCreateSenderClients on.
+func (s *RemittanceService) linkSenderClient(tx *gorm.DB, req *models.OutgoingRemittance) error {
+	phone := strings.TrimSpace(req.SenderPhone)
+	if req.ClientID != nil || phone == "" {
+		return nil
+	}
+
+	settings, err := NewTenantSettingsService(tx).GetSettings(req.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if !settings.AutoCreateSenderClients {
+		return nil
+	}
+
+	client, err := findOrCreateSenderClient(tx, req)
+	if err != nil {
+		return fmt.Errorf("failed to link sender client: %w", err)
+	}
+
+	req.ClientID = &client.ID
+	return nil
+}
+
+// findOrCreateSenderClient returns the tenant's oldest CRM client with the remittance sender's phone number,
+// creating one from the sender's details when none matches
+func findOrCreateSenderClient(tx *gorm.DB, outgoing *models.OutgoingRemittance) (*models.Client, error) {
+	phone := strings.TrimSpace(outgoing.SenderPhone)
+
+	var client models.Client
+	err := tx.Where("tenant_id = ? AND phone_number = ?", outgoing.TenantID, phone).Order("created_at ASC").First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		client = models.Client{
+			ID:          uuid.New().String(),
+			TenantID:    outgoing.TenantID,
+			Name:        strings.TrimSpace(outgoing.SenderName),
+			PhoneNumber: phone,
+			Email:       outgoing.SenderEmail,
+		}
+		err = tx.Create(&client).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
 // CreateIncomingRemittance creates a new incoming remittance (Iran to Canada)
 func (s *RemittanceService) CreateIncomingRemittance(req *models.IncomingRemittance) error {
 	// Calculate equivalent CAD
@@ -103,30 +186,522 @@ func (s *RemittanceService) CreateIncomingRemittance(req *models.IncomingRemitta
 			return fmt.Errorf("failed to generate remittance code: %w", err)
 		}
 		req.RemittanceCode = code
+		if req.TrackingCode, err = generateTrackingCode(); err != nil {
+			return err
+		}
+
+		var recipientPhone string
+		if req.RecipientPhone != nil {
+			recipientPhone = *req.RecipientPhone
+		}
+		reason, err := s.samePartyReason(tx, req.TenantID, recipientPhone, &req.SenderPhone,
+			"outgoing_remittances", "sender_phone", "recipient_iban", req.SenderIBAN)
+		if err != nil {
+			return err
+		}
+		if req.SamePartyFlag, err = s.applySamePartyPolicy(tx, req.TenantID, reason); err != nil {
+			return err
+		}
 
 		return tx.Create(req).Error
 	})
 }
 
+// ErrSamePartyRemittance is returned when the tenant blocks remittances whose sender and recipient are the same party
+var ErrSamePartyRemittance = errors.New("sender and recipient appear to be the same party")
+
+// ErrRemittanceTransactionNotFound is returned when an outgoing remittance names a transaction the tenant does not have
+var ErrRemittanceTransactionNotFound = errors.New("linked transaction not found")
+
+// samePartyReason explains why a remittance's two ends look like the same party, or returns "" if they don't.
+// The parties match when they share a phone number, or when the IBAN on this remittance was used on the
+// other end of an earlier remittance in the opposite direction by the party in Canada, identified by
+// canadaPhone. A remittance records only one party's IBAN, so the IBAN match relies on that history.
+func (s *RemittanceService) samePartyReason(tx *gorm.DB, tenantID uint, canadaPhone string, otherPhone *string,
+	oppositeTable, oppositePhoneColumn, oppositeIBANColumn string, iban *string) (string, error) {
+	if otherPhone != nil {
+		canadaDigits, otherDigits := phoneDigits(canadaPhone), phoneDigits(*otherPhone)
+		if canadaDigits != "" && canadaDigits == otherDigits {
+			return "sender and recipient share a phone number", nil
+		}
+	}
+
+	if iban == nil || strings.TrimSpace(canadaPhone) == "" {
+		return "", nil
+	}
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(*iban), " ", ""))
+	if normalized == "" {
+		return "", nil
+	}
+
+	var count int64
+	err := tx.Table(oppositeTable).
+		Where(fmt.Sprintf("tenant_id = ? AND %s = ? AND UPPER(REPLACE(%s, ' ', '')) = ? AND deleted_at IS NULL",
+			oppositePhoneColumn, oppositeIBANColumn), tenantID, strings.TrimSpace(canadaPhone), normalized).
+		Count(&count).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to check for same-party remittance: %w", err)
+	}
+	if count > 0 {
+		return fmt.Sprintf("IBAN %s was used by the same customer on an earlier remittance", normalized), nil
+	}
+	return "", nil
+}
+
+// applySamePartyPolicy returns the flag to store on a same-party remittance, or ErrSamePartyRemittance
+// when the tenant blocks them
+func (s *RemittanceService) applySamePartyPolicy(tx *gorm.DB, tenantID uint, reason string) (*string, error) {
+	if reason == "" {
+		return nil, nil
+	}
+
+	settings, err := NewTenantSettingsService(tx).GetSettings(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+
+	switch settings.SamePartyRemittances {
+	case models.SamePartyRemittancesBlock:
+		return nil, fmt.Errorf("%w: %s", ErrSamePartyRemittance, reason)
+	case models.SamePartyRemittancesFlag:
+		log.Printf("Same-party remittance flagged for tenant %d: %s", tenantID, reason)
+		return &reason, nil
+	}
+	return nil, nil
+}
+
+// phoneDigits strips a phone number down to its digits so formatting differences don't hide a match
+func phoneDigits(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+var (
+	// ErrSettlementNeedsApproval is returned when a settlement at or above the tenant's approval limits is
+	// attempted through a path that cannot hold it for approval
+	ErrSettlementNeedsApproval = errors.New("settlement is at or above the approval limit; settle it on its own so it can be approved")
+	// ErrSettlementSelfApproval is returned when the creator of a pending settlement tries to approve it
+	ErrSettlementSelfApproval = errors.New("a settlement must be approved by someone other than its creator")
+	// ErrSettlementApprovalForbidden is returned when someone other than an owner or admin decides a pending settlement
+	ErrSettlementApprovalForbidden = errors.New("only an owner or admin can approve or reject settlements")
+	// ErrSettlementExceedsDebt is returned when a settlement is larger than what is left of the outgoing remittance,
+	// e.g. because another settlement paid it down first
+	ErrSettlementExceedsDebt = errors.New("settlement amount exceeds remaining debt")
+)
+
+// SettlementPendingApprovalError is returned by SettleRemittance when the settlement was held for approval
+// instead of applied
+type SettlementPendingApprovalError struct {
+	Pending *models.PendingSettlement
+}
+
+func (e *SettlementPendingApprovalError) Error() string {
+	return fmt.Sprintf("settlement is at or above the approval limit and is pending approval (#%d)", e.Pending.ID)
+}
+
+// errSettlementHeld rolls back a settlement that has to wait for approval
+var errSettlementHeld = errors.New("settlement held for approval")
+
+// settlementNeedsApproval reports whether a settlement of amountIRR making profitCAD (or losing it) reaches
+// one of the tenant's approval limits
+func settlementNeedsApproval(settings *models.TenantSettings, amountIRR, profitCAD models.Decimal) bool {
+	if limit := settings.SettlementApprovalAmountIRR; limit.IsPositive() && amountIRR.GreaterThanOrEqual(limit) {
+		return true
+	}
+	if limit := settings.SettlementApprovalProfitCAD; limit.IsPositive() && profitCAD.Abs().GreaterThanOrEqual(limit) {
+		return true
+	}
+	return false
+}
+
 // SettleRemittance creates a settlement between incoming and outgoing remittances
-// This is the core function that handles multi-part settlements
+// This is the core function that handles multi-part settlements. A settlement at or above the tenant's
+// approval limits is not applied; it is held and a *SettlementPendingApprovalError is returned instead.
 func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID uint, amountIRR models.Decimal, userID uint) (*models.RemittanceSettlement, error) {
-	var outgoing models.OutgoingRemittance
-	var incoming models.IncomingRemittance
+	settings, err := NewTenantSettingsService(s.db).GetSettings(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+
+	var outcome *settlementOutcome
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		outcome, err = s.applySettlement(tx, tenantID, outgoingID, incomingID, amountIRR, userID)
+		if err != nil {
+			return err
+		}
+		// Applying it first validates the request and gives the profit the limits are checked against
+		if settlementNeedsApproval(settings, amountIRR, outcome.Settlement.ProfitCAD) {
+			return errSettlementHeld
+		}
+		return nil
+	})
+	if errors.Is(err, errSettlementHeld) {
+		pending, err := s.holdSettlement(tenantID, outgoingID, incomingID, amountIRR, outcome.Settlement.ProfitCAD, userID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &SettlementPendingApprovalError{Pending: pending}
+	}
+	if err != nil {
+		return nil, err
+	}
+	settlement := outcome.Settlement
+
+	GetEventBus().Publish(settlementEvent(settlement))
+	s.notifySettlementBranches(outcome)
+
+	// Load relations
+	s.db.Preload("OutgoingRemittance").Preload("IncomingRemittance").First(settlement, settlement.ID)
+
+	return settlement, nil
+}
+
+// SettlementAllocation is the share of an incoming remittance settled against one outgoing remittance
+type SettlementAllocation struct {
+	OutgoingID uint           `json:"outgoingRemittanceId"`
+	AmountIRR  models.Decimal `json:"amountIrr"`
+}
+
+// SettleMultiple settles one incoming remittance against several outgoing remittances in a single
+// transaction. The allocations together may not exceed the incoming remaining; if any allocation is
+// rejected none of them is applied. Non-nil notes are saved on every settlement.
+func (s *RemittanceService) SettleMultiple(tenantID, incomingID uint, allocations []SettlementAllocation, notes *string, userID uint) ([]models.RemittanceSettlement, error) {
+	if len(allocations) == 0 {
+		return nil, errors.New("at least one allocation is required")
+	}
+
+	total := models.Zero()
+	seen := make(map[uint]bool, len(allocations))
+	for _, allocation := range allocations {
+		if seen[allocation.OutgoingID] {
+			return nil, fmt.Errorf("outgoing remittance %d is allocated more than once", allocation.OutgoingID)
+		}
+		seen[allocation.OutgoingID] = true
+		if !allocation.AmountIRR.IsPositive() {
+			return nil, fmt.Errorf("allocation to outgoing remittance %d must be greater than 0", allocation.OutgoingID)
+		}
+		total = total.Add(allocation.AmountIRR)
+	}
+
+	settings, err := NewTenantSettingsService(s.db).GetSettings(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+
+	var outcomes []*settlementOutcome
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var incoming models.IncomingRemittance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ?", incomingID, tenantID).
+			First(&incoming).Error; err != nil {
+			return errors.New("incoming remittance not found")
+		}
+		if total.GreaterThan(incoming.RemainingIRR) {
+			return fmt.Errorf("allocations total (%s) exceeds incoming remaining (%s)", total.String(), incoming.RemainingIRR.String())
+		}
+
+		for _, allocation := range allocations {
+			outcome, err := s.applySettlement(tx, tenantID, allocation.OutgoingID, incomingID, allocation.AmountIRR, userID)
+			if err != nil {
+				return fmt.Errorf("outgoing remittance %d: %w", allocation.OutgoingID, err)
+			}
+			if settlementNeedsApproval(settings, allocation.AmountIRR, outcome.Settlement.ProfitCAD) {
+				return fmt.Errorf("outgoing remittance %d: %w", allocation.OutgoingID, ErrSettlementNeedsApproval)
+			}
+			if notes != nil {
+				if err := tx.Model(outcome.Settlement).Update("notes", *notes).Error; err != nil {
+					return fmt.Errorf("failed to save settlement notes: %w", err)
+				}
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settlements := make([]models.RemittanceSettlement, len(outcomes))
+	for i, outcome := range outcomes {
+		GetEventBus().Publish(settlementEvent(outcome.Settlement))
+		s.notifySettlementBranches(outcome)
+
+		s.db.Preload("OutgoingRemittance").Preload("IncomingRemittance").First(outcome.Settlement, outcome.Settlement.ID)
+		settlements[i] = *outcome.Settlement
+	}
+
+	return settlements, nil
+}
+
+// holdSettlement records a settlement that has to wait for approval. Asking again for the same settlement
+// returns the request already waiting rather than queueing a duplicate.
+func (s *RemittanceService) holdSettlement(tenantID, outgoingID, incomingID uint, amountIRR, profitCAD models.Decimal, userID uint) (*models.PendingSettlement, error) {
+	var pending models.PendingSettlement
+	err := s.db.Where("tenant_id = ? AND outgoing_remittance_id = ? AND incoming_remittance_id = ? AND amount_irr = ? AND status = ?",
+		tenantID, outgoingID, incomingID, amountIRR, models.PendingSettlementStatusPending).
+		First(&pending).Error
+	if err == nil {
+		return &pending, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	pending = models.PendingSettlement{
+		TenantID:             tenantID,
+		OutgoingRemittanceID: outgoingID,
+		IncomingRemittanceID: incomingID,
+		AmountIRR:            amountIRR,
+		ProjectedProfitCAD:   profitCAD,
+		Status:               models.PendingSettlementStatusPending,
+		CreatedBy:            userID,
+	}
+	if err := s.db.Create(&pending).Error; err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
+
+// GetPendingSettlements lists the tenant's settlements waiting for approval, oldest first
+func (s *RemittanceService) GetPendingSettlements(tenantID uint) ([]models.PendingSettlement, error) {
+	var pending []models.PendingSettlement
+	err := s.db.Where("tenant_id = ? AND status = ?", tenantID, models.PendingSettlementStatusPending).
+		Preload("OutgoingRemittance").Preload("IncomingRemittance").
+		Order("created_at ASC, id ASC").
+		Find(&pending).Error
+	return pending, err
+}
+
+// ApproveSettlement applies a pending settlement. The approver must be an owner or admin other than the
+// settlement's creator; the remittances are re-checked, so a settlement overtaken by others fails here.
+func (s *RemittanceService) ApproveSettlement(tenantID, pendingID uint, approver *models.User) (*models.RemittanceSettlement, error) {
+	var outcome *settlementOutcome
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		pending, err := s.lockPendingSettlement(tx, tenantID, pendingID, approver)
+		if err != nil {
+			return err
 		}
-	}()
 
+		outcome, err = s.applySettlement(tx, tenantID, pending.OutgoingRemittanceID, pending.IncomingRemittanceID, pending.AmountIRR, pending.CreatedBy)
+		if err != nil {
+			return err
+		}
+		if pending.Notes != nil {
+			if err := tx.Model(outcome.Settlement).Update("notes", *pending.Notes).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(pending).Updates(map[string]interface{}{
+			"status":        models.PendingSettlementStatusApproved,
+			"decided_by":    approver.ID,
+			"decided_at":    now,
+			"settlement_id": outcome.Settlement.ID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	settlement := outcome.Settlement
+
+	GetEventBus().Publish(settlementEvent(settlement))
+	s.notifySettlementBranches(outcome)
+
+	s.db.Preload("OutgoingRemittance").Preload("IncomingRemittance").First(settlement, settlement.ID)
+	return settlement, nil
+}
+
+// RejectSettlement declines a pending settlement, leaving both remittances untouched
+func (s *RemittanceService) RejectSettlement(tenantID, pendingID uint, approver *models.User, note string) (*models.PendingSettlement, error) {
+	var pending *models.PendingSettlement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pending, err = s.lockPendingSettlement(tx, tenantID, pendingID, approver)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"status":     models.PendingSettlementStatusRejected,
+			"decided_by": approver.ID,
+			"decided_at": time.Now(),
+		}
+		if note = strings.TrimSpace(note); note != "" {
+			updates["decision_note"] = note
+		}
+		return tx.Model(pending).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// lockPendingSettlement loads a pending settlement for a decision and checks the approver may make it
+func (s *RemittanceService) lockPendingSettlement(tx *gorm.DB, tenantID, pendingID uint, approver *models.User) (*models.PendingSettlement, error) {
+	switch approver.Role {
+	case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+	default:
+		return nil, ErrSettlementApprovalForbidden
+	}
+
+	var pending models.PendingSettlement
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", pendingID, tenantID).
+		First(&pending).Error; err != nil {
+		return nil, fmt.Errorf("pending settlement not found: %w", err)
+	}
+	if pending.Status != models.PendingSettlementStatusPending {
+		return nil, fmt.Errorf("settlement has already been %s", strings.ToLower(pending.Status))
+	}
+	if pending.CreatedBy == approver.ID {
+		return nil, ErrSettlementSelfApproval
+	}
+	return &pending, nil
+}
+
+// settlementEvent builds the domain event announcing a committed settlement
+func settlementEvent(settlement *models.RemittanceSettlement) DomainEvent {
+	return DomainEvent{
+		Name:     EventSettlementCreated,
+		TenantID: settlement.TenantID,
+		Data: map[string]interface{}{
+			"settlementId":         settlement.ID,
+			"tenantId":             settlement.TenantID,
+			"outgoingRemittanceId": settlement.OutgoingRemittanceID,
+			"incomingRemittanceId": settlement.IncomingRemittanceID,
+			"settledAmountIrr":     settlement.SettledAmountIRR.Float64(),
+		},
+		OccurredAt: time.Now(),
+	}
+}
+
+// notifySettlementBranches pushes a settlement event to the branches of both remittances and, if the
+// tenant has it enabled, emails their users. Delivery failures are logged and never undo the settlement.
+func (s *RemittanceService) notifySettlementBranches(outcome *settlementOutcome) {
+	branchIDs := make([]uint, 0, 2)
+	for _, branchID := range []*uint{outcome.Outgoing.BranchID, outcome.Incoming.BranchID} {
+		if branchID != nil && (len(branchIDs) == 0 || branchIDs[0] != *branchID) {
+			branchIDs = append(branchIDs, *branchID)
+		}
+	}
+	if len(branchIDs) == 0 {
+		return
+	}
+
+	settlement := outcome.Settlement
+	settings, err := NewTenantSettingsService(s.db).GetSettings(settlement.TenantID)
+	if err != nil || !settings.NotifyBranchesOnSettle {
+		return
+	}
+
+	GetHub().BroadcastToBranches(settlement.TenantID, branchIDs, "settlement", "completed", map[string]interface{}{
+		"settlementId":           settlement.ID,
+		"outgoingRemittanceId":   outcome.Outgoing.ID,
+		"outgoingRemittanceCode": outcome.Outgoing.RemittanceCode,
+		"outgoingBranchId":       outcome.Outgoing.BranchID,
+		"outgoingStatus":         outcome.Outgoing.Status,
+		"incomingRemittanceId":   outcome.Incoming.ID,
+		"incomingRemittanceCode": outcome.Incoming.RemittanceCode,
+		"incomingBranchId":       outcome.Incoming.BranchID,
+		"incomingStatus":         outcome.Incoming.Status,
+		"settledAmountIrr":       settlement.SettledAmountIRR.String(),
+	})
+
+	if !settings.EmailBranchesOnSettle || s.notifier == nil {
+		return
+	}
+
+	// Linked branch login users have placeholder addresses, so only real staff are emailed
+	var users []models.User
+	if err := s.db.Where("tenant_id = ? AND primary_branch_id IN ? AND status = ? AND email <> ''",
+		settlement.TenantID, branchIDs, models.StatusActive).
+		Where("id NOT IN (?)", s.db.Model(&models.Branch{}).Select("branch_user_id").Where("branch_user_id IS NOT NULL")).
+		Find(&users).Error; err != nil {
+		log.Printf("⚠️  Failed to load branch users for settlement %d: %v", settlement.ID, err)
+		return
+	}
+
+	subject := fmt.Sprintf("Settlement completed: %s ↔ %s", outcome.Outgoing.RemittanceCode, outcome.Incoming.RemittanceCode)
+	body := fmt.Sprintf("<p>%s IRR of outgoing remittance %s (%s) was settled against incoming remittance %s (%s).</p>",
+		settlement.SettledAmountIRR.StringFixed(0),
+		html.EscapeString(outcome.Outgoing.RemittanceCode), outcome.Outgoing.Status,
+		html.EscapeString(outcome.Incoming.RemittanceCode), outcome.Incoming.Status)
+	for _, user := range users {
+		if err := s.notifier.SendEmail(user.Email, subject, body); err != nil {
+			log.Printf("⚠️  Failed to email settlement %d to user %d: %v", settlement.ID, user.ID, err)
+		}
+	}
+}
+
+// SettlementPreview is the projected result of a settlement that has not been committed
+type SettlementPreview struct {
+	Settlement            models.RemittanceSettlement `json:"settlement"`
+	OutgoingStatus        string                      `json:"outgoingStatus"`
+	OutgoingRemainingIRR  models.Decimal              `json:"outgoingRemainingIrr"`
+	OutgoingWrittenOffIRR models.Decimal              `json:"outgoingWrittenOffIrr"`
+	IncomingStatus        string                      `json:"incomingStatus"`
+	IncomingRemainingIRR  models.Decimal              `json:"incomingRemainingIrr"`
+	IncomingWrittenOffIRR models.Decimal              `json:"incomingWrittenOffIrr"`
+}
+
+// errSettlementPreview rolls back the transaction a settlement preview runs in
+var errSettlementPreview = errors.New("settlement preview rolled back")
+
+// SettleRemittancePreview runs the exact settlement SettleRemittance would perform inside a
+// transaction that is always rolled back, and reports the projected settlement and balances
+func (s *RemittanceService) SettleRemittancePreview(tenantID, outgoingID, incomingID uint, amountIRR models.Decimal) (*SettlementPreview, error) {
+	var preview *SettlementPreview
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		outcome, err := s.applySettlement(tx, tenantID, outgoingID, incomingID, amountIRR, 0)
+		if err != nil {
+			return err
+		}
+
+		// Nothing is persisted, so drop the identifiers the rolled-back insert assigned
+		projected := *outcome.Settlement
+		projected.ID = 0
+		projected.CreatedAt = time.Time{}
+
+		preview = &SettlementPreview{
+			Settlement:            projected,
+			OutgoingStatus:        outcome.Outgoing.Status,
+			OutgoingRemainingIRR:  outcome.Outgoing.RemainingIRR,
+			OutgoingWrittenOffIRR: outcome.Outgoing.WrittenOffIRR,
+			IncomingStatus:        outcome.Incoming.Status,
+			IncomingRemainingIRR:  outcome.Incoming.RemainingIRR,
+			IncomingWrittenOffIRR: outcome.Incoming.WrittenOffIRR,
+		}
+		return errSettlementPreview
+	})
+	if err != nil && !errors.Is(err, errSettlementPreview) {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// settlementOutcome is what applySettlement wrote
+type settlementOutcome struct {
+	Settlement *models.RemittanceSettlement
+	Outgoing   models.OutgoingRemittance
+	Incoming   models.IncomingRemittance
+}
+
+// applySettlement settles amountIRR of the outgoing remittance against the incoming one within tx
+func (s *RemittanceService) applySettlement(tx *gorm.DB, tenantID, outgoingID, incomingID uint, amountIRR models.Decimal, userID uint) (*settlementOutcome, error) {
+	var outgoing models.OutgoingRemittance
+	var incoming models.IncomingRemittance
 	// Get outgoing remittance with lock (FOR UPDATE prevents race conditions)
 	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ? AND tenant_id = ?", outgoingID, tenantID).
 		First(&outgoing).Error; err != nil {
-		tx.Rollback()
 		return nil, errors.New("outgoing remittance not found")
 	}
 
@@ -134,23 +709,19 @@ func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID ui
 	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("id = ? AND tenant_id = ?", incomingID, tenantID).
 		First(&incoming).Error; err != nil {
-		tx.Rollback()
 		return nil, errors.New("incoming remittance not found")
 	}
 
 	// Validate settlement amount
 	if amountIRR.LessThanOrEqual(models.Zero()) {
-		tx.Rollback()
 		return nil, errors.New("settlement amount must be greater than 0")
 	}
 
 	if amountIRR.GreaterThan(outgoing.RemainingIRR) {
-		tx.Rollback()
-		return nil, fmt.Errorf("settlement amount (%s) exceeds remaining debt (%s)", amountIRR.String(), outgoing.RemainingIRR.String())
+		return nil, fmt.Errorf("%w: settlement amount (%s), remaining debt (%s)", ErrSettlementExceedsDebt, amountIRR.String(), outgoing.RemainingIRR.String())
 	}
 
 	if amountIRR.GreaterThan(incoming.RemainingIRR) {
-		tx.Rollback()
 		return nil, fmt.Errorf("settlement amount (%s) exceeds incoming remaining (%s)", amountIRR.String(), incoming.RemainingIRR.String())
 	}
 
@@ -170,11 +741,14 @@ func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID ui
 		OutgoingBuyRate:      outgoing.BuyRateCAD,
 		IncomingSellRate:     incoming.SellRateCAD,
 		ProfitCAD:            profit,
+		Currency:             outgoing.SettlementCurrency,
 		CreatedBy:            userID,
 	}
+	if settlement.Currency == "" {
+		settlement.Currency = "CAD"
+	}
 
 	if err := tx.Create(settlement).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
@@ -183,8 +757,22 @@ func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID ui
 	outgoing.RemainingIRR = outgoing.RemainingIRR.Sub(amountIRR)
 	outgoing.TotalProfitCAD = outgoing.TotalProfitCAD.Add(profit)
 
-	threshold := models.NewDecimal(0.01)
-	if outgoing.RemainingIRR.LessThanOrEqual(threshold) {
+	// Residuals within the IRR tolerance can never be settled, so round them off (if enabled) and complete
+	tolerance := models.NewDecimal(utils.GetPaymentTolerance("IRR"))
+	roundResiduals, err := s.roundsSettlementResiduals(tx, tenantID, outgoing.RemainingIRR, incoming.RemainingIRR.Sub(amountIRR), tolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	if roundResiduals && outgoing.RemainingIRR.IsPositive() && outgoing.RemainingIRR.LessThanOrEqual(tolerance) {
+		if err := s.writeOffSettlementResidual(tx, settlement, &outgoing.ID, nil, outgoing.RemainingIRR, userID); err != nil {
+			return nil, err
+		}
+		outgoing.WrittenOffIRR = outgoing.WrittenOffIRR.Add(outgoing.RemainingIRR)
+		outgoing.RemainingIRR = models.Zero()
+	}
+
+	if !outgoing.RemainingIRR.IsPositive() {
 		outgoing.Status = models.RemittanceStatusCompleted
 		now := time.Now()
 		outgoing.CompletedAt = &now
@@ -193,7 +781,6 @@ func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID ui
 	}
 
 	if err := tx.Save(&outgoing).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
@@ -201,26 +788,60 @@ func (s *RemittanceService) SettleRemittance(tenantID, outgoingID, incomingID ui
 	incoming.AllocatedIRR = incoming.AllocatedIRR.Add(amountIRR)
 	incoming.RemainingIRR = incoming.RemainingIRR.Sub(amountIRR)
 
-	if incoming.RemainingIRR.LessThanOrEqual(threshold) {
+	if roundResiduals && incoming.RemainingIRR.IsPositive() && incoming.RemainingIRR.LessThanOrEqual(tolerance) {
+		if err := s.writeOffSettlementResidual(tx, settlement, nil, &incoming.ID, incoming.RemainingIRR, userID); err != nil {
+			return nil, err
+		}
+		incoming.WrittenOffIRR = incoming.WrittenOffIRR.Add(incoming.RemainingIRR)
+		incoming.RemainingIRR = models.Zero()
+	}
+
+	if !incoming.RemainingIRR.IsPositive() {
 		incoming.Status = models.RemittanceStatusCompleted
 	} else if incoming.AllocatedIRR.GreaterThan(models.Zero()) {
 		incoming.Status = models.RemittanceStatusPartial
 	}
 
 	if err := tx.Save(&incoming).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+	return &settlementOutcome{Settlement: settlement, Outgoing: outgoing, Incoming: incoming}, nil
+}
+
+// roundsSettlementResiduals reports whether a residual left by a settlement should be written off.
+// Tenant settings are only consulted when one of the remainders actually falls within tolerance.
+func (s *RemittanceService) roundsSettlementResiduals(tx *gorm.DB, tenantID uint, outgoingRemaining, incomingRemaining, tolerance models.Decimal) (bool, error) {
+	withinTolerance := func(remaining models.Decimal) bool {
+		return remaining.IsPositive() && remaining.LessThanOrEqual(tolerance)
+	}
+	if !withinTolerance(outgoingRemaining) && !withinTolerance(incomingRemaining) {
+		return false, nil
 	}
 
-	// Load relations
-	s.db.Preload("OutgoingRemittance").Preload("IncomingRemittance").First(settlement, settlement.ID)
+	settings, err := NewTenantSettingsService(tx).GetSettings(tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	return settings.RoundSettlementResiduals, nil
+}
 
-	return settlement, nil
+// writeOffSettlementResidual posts a remittance's leftover IRR to the settlement rounding account
+func (s *RemittanceService) writeOffSettlementResidual(tx *gorm.DB, settlement *models.RemittanceSettlement, outgoingID, incomingID *uint, residual models.Decimal, userID uint) error {
+	writeOff := models.RemittanceWriteOff{
+		TenantID:             settlement.TenantID,
+		SettlementID:         settlement.ID,
+		OutgoingRemittanceID: outgoingID,
+		IncomingRemittanceID: incomingID,
+		Account:              models.RemittanceWriteOffAccount,
+		Currency:             "IRR",
+		Amount:               residual,
+		CreatedBy:            userID,
+	}
+	if err := tx.Create(&writeOff).Error; err != nil {
+		return fmt.Errorf("failed to write off settlement residual: %w", err)
+	}
+	return nil
 }
 
 // GetOutgoingRemittances retrieves outgoing remittances with filters
@@ -342,6 +963,53 @@ func (s *RemittanceService) CancelOutgoingRemittance(tenantID, id, userID uint,
 	return s.db.Save(&outgoing).Error
 }
 
+// PlaceComplianceHold holds an outgoing remittance so it is not settled until compliance clears it
+func (s *RemittanceService) PlaceComplianceHold(tenantID, id, userID uint, reason string) error {
+	var outgoing models.OutgoingRemittance
+
+	if err := s.db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&outgoing).Error; err != nil {
+		return err
+	}
+
+	if outgoing.Status == models.RemittanceStatusCompleted || outgoing.Status == models.RemittanceStatusCancelled {
+		return fmt.Errorf("cannot hold a %s remittance", strings.ToLower(outgoing.Status))
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required to place a compliance hold")
+	}
+
+	now := time.Now()
+	return s.db.Model(&outgoing).Updates(map[string]interface{}{
+		"compliance_hold":        true,
+		"compliance_hold_reason": reason,
+		"compliance_held_at":     now,
+		"compliance_held_by":     userID,
+		"compliance_cleared_at":  nil,
+		"compliance_cleared_by":  nil,
+		"compliance_notified_at": nil,
+	}).Error
+}
+
+// ReleaseComplianceHold clears a compliance hold, making the remittance eligible for settlement again
+func (s *RemittanceService) ReleaseComplianceHold(tenantID, id, userID uint) error {
+	var outgoing models.OutgoingRemittance
+
+	if err := s.db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&outgoing).Error; err != nil {
+		return err
+	}
+
+	if !outgoing.ComplianceHold {
+		return errors.New("remittance is not on compliance hold")
+	}
+
+	now := time.Now()
+	return s.db.Model(&outgoing).Updates(map[string]interface{}{
+		"compliance_hold":       false,
+		"compliance_cleared_at": now,
+		"compliance_cleared_by": userID,
+	}).Error
+}
+
 // CancelIncomingRemittance cancels an incoming remittance
 func (s *RemittanceService) CancelIncomingRemittance(tenantID, id, userID uint, reason string) error {
 	var incoming models.IncomingRemittance
@@ -363,7 +1031,18 @@ func (s *RemittanceService) CancelIncomingRemittance(tenantID, id, userID uint,
 	return s.db.Save(&incoming).Error
 }
 
-// GetRemittanceProfitSummary calculates profit summary for a tenant
+// CurrencyProfit is the settlement profit earned in one settlement currency
+type CurrencyProfit struct {
+	Currency         string  `json:"currency"`
+	Settlements      int     `json:"settlements"`
+	TotalProfit      float64 `json:"totalProfit"`      // In Currency
+	NormalizedProfit float64 `json:"normalizedProfit"` // In the tenant base currency, at each settlement's historical rate
+	Unconverted      int     `json:"unconverted"`      // Settlements left out of NormalizedProfit for lack of a rate
+}
+
+// GetRemittanceProfitSummary calculates profit summary for a tenant.
+// Profit is grouped by settlement currency and normalized to the tenant's base currency
+// using the rate in effect when each settlement was made.
 func (s *RemittanceService) GetRemittanceProfitSummary(tenantID uint, startDate, endDate *time.Time) (map[string]interface{}, error) {
 	var settlements []models.RemittanceSettlement
 
@@ -376,25 +1055,84 @@ func (s *RemittanceService) GetRemittanceProfitSummary(tenantID uint, startDate,
 		query = query.Where("created_at <= ?", endDate)
 	}
 
-	if err := query.Find(&settlements).Error; err != nil {
+	if err := query.Order("created_at ASC").Find(&settlements).Error; err != nil {
 		return nil, err
 	}
 
+	baseCurrency := NewTenantSettingsService(s.db).GetBaseCurrency(tenantID)
+
+	rateService := NewExchangeRateService(s.db)
+	byCurrency := make(map[string]*CurrencyProfit)
+	totals := make(map[string]models.Decimal)
+	normalized := make(map[string]models.Decimal)
 	totalProfit := models.Zero()
+	normalizedTotal := models.Zero()
+	unconverted := make([]string, 0)
 	totalSettlements := len(settlements)
+	convertedSettlements := 0
 
 	for _, settlement := range settlements {
+		currency := settlement.Currency
+		if currency == "" {
+			currency = "CAD"
+		}
+
+		entry := byCurrency[currency]
+		if entry == nil {
+			entry = &CurrencyProfit{Currency: currency}
+			byCurrency[currency] = entry
+			totals[currency] = models.Zero()
+			normalized[currency] = models.Zero()
+		}
+		entry.Settlements++
+		totals[currency] = totals[currency].Add(settlement.ProfitCAD)
 		totalProfit = totalProfit.Add(settlement.ProfitCAD)
+
+		rate, err := rateService.GetHistoricalRate(tenantID, currency, baseCurrency, settlement.CreatedAt)
+		if err != nil {
+			if entry.Unconverted == 0 {
+				unconverted = append(unconverted, currency)
+			}
+			entry.Unconverted++
+			continue
+		}
+		converted := settlement.ProfitCAD.Mul(rate)
+		normalized[currency] = normalized[currency].Add(converted)
+		normalizedTotal = normalizedTotal.Add(converted)
+		convertedSettlements++
+	}
+
+	currencies := make([]CurrencyProfit, 0, len(byCurrency))
+	for currency, entry := range byCurrency {
+		entry.TotalProfit = totals[currency].Round(2).Float64()
+		entry.NormalizedProfit = normalized[currency].Round(2).Float64()
+		currencies = append(currencies, *entry)
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Currency < currencies[j].Currency
+	})
+	sort.Strings(unconverted)
+
+	// Each average divides by the settlements its total actually covers
+	averageProfit := 0.0
+	if totalSettlements > 0 {
+		averageProfit = totalProfit.Float64() / float64(totalSettlements)
+	}
+	averageNormalizedProfit := 0.0
+	if convertedSettlements > 0 {
+		averageNormalizedProfit = normalizedTotal.Float64() / float64(convertedSettlements)
 	}
 
 	return map[string]interface{}{
+		"baseCurrency":            baseCurrency,
+		"byCurrency":              currencies,
+		"normalizedTotalProfit":   normalizedTotal.Round(2).Float64(),
+		"convertedSettlements":    convertedSettlements,
+		"averageNormalizedProfit": averageNormalizedProfit,
+		"unconvertedCurrencies":   unconverted,
+		// Unchanged for existing clients: raw settlement profit summed across currencies
 		"totalProfitCAD":   totalProfit.Float64(),
 		"totalSettlements": totalSettlements,
-		"averageProfitCAD": func() float64 {
-			if totalSettlements > 0 {
-				return totalProfit.Float64() / float64(totalSettlements)
-			}
-			return 0
-		}(),
+		"averageProfitCAD": averageProfit,
 	}, nil
 }
@@ -2,8 +2,11 @@ package services
 
 import (
 	"api/pkg/models"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,14 +25,14 @@ func TestRemittanceSystemFlow(t *testing.T) {
 		&models.Tenant{},
 		&models.User{},
 		&models.Branch{},
+		&models.TenantSettings{},
 		&models.OutgoingRemittance{},
 		&models.IncomingRemittance{},
 		&models.RemittanceSettlement{},
 	)
 
 	// Create test tenant and user
-	tenant := &models.Tenant{Name: "Test Exchange Bureau"}
-	db.Create(tenant)
+	tenant := newTestTenant(t, db, "Test Exchange Bureau")
 
 	user := &models.User{
 		Email:    "test@example.com",
@@ -260,4 +263,909 @@ func TestRemittanceSystemFlow(t *testing.T) {
 	fmt.Println("=====================================")
 }
 
+// TestRemittanceProfitSummary_MultiCurrency verifies profit is grouped by settlement currency and normalized at historical rates
+func TestRemittanceProfitSummary_MultiCurrency(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.ExchangeRate{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+	)
+
+	tenant := newTestTenant(t, db, "Multi Currency Exchange")
+
+	user := &models.User{
+		Email:    "multi@example.com",
+		TenantID: &tenant.ID,
+		Role:     "tenant_owner",
+	}
+	db.Create(user)
+
+	// USD->CAD was 1.35 when settling; a later rate must not be used
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "USD",
+		TargetCurrency: "CAD",
+		Rate:           models.NewDecimal(1.35),
+		Source:         models.RateSourceManual,
+		CreatedAt:      time.Now().Add(-time.Hour),
+	})
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "USD",
+		TargetCurrency: "CAD",
+		Rate:           models.NewDecimal(2.00),
+		Source:         models.RateSourceManual,
+		CreatedAt:      time.Now().Add(time.Hour),
+	})
+
+	service := NewRemittanceService(db)
+
+	newOutgoing := func(code, currency string, buyRate float64) *models.OutgoingRemittance {
+		outgoing := &models.OutgoingRemittance{
+			TenantID:           tenant.ID,
+			RemittanceCode:     code,
+			SenderName:         "Sender " + code,
+			SenderPhone:        "+1416555" + code[len(code)-4:],
+			RecipientName:      "Recipient " + code,
+			AmountIRR:          models.NewDecimal(81000000),
+			BuyRateCAD:         models.NewDecimal(buyRate),
+			ReceivedCAD:        models.NewDecimal(81000000 / buyRate),
+			RemainingIRR:       models.NewDecimal(81000000),
+			TotalCostCAD:       models.NewDecimal(81000000 / buyRate),
+			SettlementCurrency: currency,
+			Status:             models.RemittanceStatusPending,
+			CreatedBy:          user.ID,
+		}
+		if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+			t.Fatalf("Failed to create outgoing: %v", err)
+		}
+		return outgoing
+	}
+	newIncoming := func(code string, sellRate float64) *models.IncomingRemittance {
+		incoming := &models.IncomingRemittance{
+			TenantID:       tenant.ID,
+			RemittanceCode: code,
+			SenderName:     "Iran " + code,
+			SenderPhone:    "+98912" + code[len(code)-4:],
+			RecipientName:  "Canada " + code,
+			AmountIRR:      models.NewDecimal(81000000),
+			SellRateCAD:    models.NewDecimal(sellRate),
+			RemainingIRR:   models.NewDecimal(81000000),
+			Status:         models.RemittanceStatusPending,
+			CreatedBy:      user.ID,
+		}
+		if err := service.CreateIncomingRemittance(incoming); err != nil {
+			t.Fatalf("Failed to create incoming: %v", err)
+		}
+		return incoming
+	}
+
+	cadOut := newOutgoing("OUT-MC-0001", "CAD", 80000)
+	usdOut := newOutgoing("OUT-MC-0002", "USD", 60000)
+	cadIn := newIncoming("IN-MC-0001", 81000)
+	usdIn := newIncoming("IN-MC-0002", 61000)
+
+	cadSettlement, err := service.SettleRemittance(tenant.ID, cadOut.ID, cadIn.ID, models.NewDecimal(81000000), user.ID)
+	if err != nil {
+		t.Fatalf("CAD settlement failed: %v", err)
+	}
+	usdSettlement, err := service.SettleRemittance(tenant.ID, usdOut.ID, usdIn.ID, models.NewDecimal(81000000), user.ID)
+	if err != nil {
+		t.Fatalf("USD settlement failed: %v", err)
+	}
+	if cadSettlement.Currency != "CAD" || usdSettlement.Currency != "USD" {
+		t.Fatalf("Expected settlements in CAD and USD, got %s and %s", cadSettlement.Currency, usdSettlement.Currency)
+	}
+
+	summary, err := service.GetRemittanceProfitSummary(tenant.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get profit summary: %v", err)
+	}
+
+	if summary["baseCurrency"] != "CAD" {
+		t.Errorf("Expected base currency CAD, got %v", summary["baseCurrency"])
+	}
+
+	byCurrency := summary["byCurrency"].([]CurrencyProfit)
+	if len(byCurrency) != 2 {
+		t.Fatalf("Expected profit in 2 currencies, got %d", len(byCurrency))
+	}
+
+	cadProfit := cadSettlement.ProfitCAD.Float64()
+	usdProfit := usdSettlement.ProfitCAD.Float64()
+	if byCurrency[0].Currency != "CAD" || testAbs(byCurrency[0].TotalProfit-cadProfit) > 0.01 {
+		t.Errorf("Unexpected CAD group: %+v (expected profit %.2f)", byCurrency[0], cadProfit)
+	}
+	if byCurrency[1].Currency != "USD" || testAbs(byCurrency[1].TotalProfit-usdProfit) > 0.01 {
+		t.Errorf("Unexpected USD group: %+v (expected profit %.2f)", byCurrency[1], usdProfit)
+	}
+	if testAbs(byCurrency[1].NormalizedProfit-usdProfit*1.35) > 0.01 {
+		t.Errorf("Expected USD profit normalized at historical rate 1.35, got %.4f", byCurrency[1].NormalizedProfit)
+	}
+
+	expectedTotal := cadProfit + usdProfit*1.35
+	total := summary["normalizedTotalProfit"].(float64)
+	if testAbs(total-expectedTotal) > 0.01 {
+		t.Errorf("Expected normalized total %.2f, got %.2f", expectedTotal, total)
+	}
+	if testAbs(total-(byCurrency[0].NormalizedProfit+byCurrency[1].NormalizedProfit)) > 0.01 {
+		t.Errorf("Normalized total %.2f does not match the sum of currency groups", total)
+	}
+	if len(summary["unconvertedCurrencies"].([]string)) != 0 {
+		t.Errorf("Expected all currencies to convert, got %v", summary["unconvertedCurrencies"])
+	}
+}
+
+// TestRemittanceProfitSummary_AveragesMatchTheirTotals verifies the raw totals keep their meaning and that
+// the normalized average leaves out settlements that could not be converted
+func TestRemittanceProfitSummary_AveragesMatchTheirTotals(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.ExchangeRate{}, &models.RemittanceSettlement{})
+	tenant := newTestTenant(t, db, "Average Exchange")
+
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "USD",
+		TargetCurrency: "CAD",
+		Rate:           models.NewDecimal(1.5),
+		Source:         models.RateSourceManual,
+		CreatedAt:      time.Now().Add(-time.Hour),
+	})
+	// EUR has no rate, so its settlement cannot be normalized
+	for _, s := range []struct {
+		currency string
+		profit   float64
+	}{{"CAD", 100}, {"USD", 40}, {"EUR", 90}} {
+		if err := db.Create(&models.RemittanceSettlement{
+			TenantID:         tenant.ID,
+			SettledAmountIRR: models.NewDecimal(1000000),
+			OutgoingBuyRate:  models.NewDecimal(80000),
+			IncomingSellRate: models.NewDecimal(81000),
+			ProfitCAD:        models.NewDecimal(s.profit),
+			Currency:         s.currency,
+			CreatedBy:        1,
+		}).Error; err != nil {
+			t.Fatalf("Failed to create %s settlement: %v", s.currency, err)
+		}
+	}
+
+	summary, err := NewRemittanceService(db).GetRemittanceProfitSummary(tenant.ID, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to get profit summary: %v", err)
+	}
+
+	if total := summary["totalProfitCAD"].(float64); testAbs(total-230) > 0.01 {
+		t.Errorf("Expected totalProfitCAD to stay the raw sum 230, got %.2f", total)
+	}
+	if average := summary["averageProfitCAD"].(float64); testAbs(average-230.0/3) > 0.01 {
+		t.Errorf("Expected averageProfitCAD over all 3 settlements, got %.2f", average)
+	}
+	// Normalized: 100 + 40 * 1.5 = 160 over the 2 converted settlements
+	if total := summary["normalizedTotalProfit"].(float64); testAbs(total-160) > 0.01 {
+		t.Errorf("Expected normalized total 160, got %.2f", total)
+	}
+	if converted := summary["convertedSettlements"].(int); converted != 2 {
+		t.Errorf("Expected 2 converted settlements, got %d", converted)
+	}
+	if average := summary["averageNormalizedProfit"].(float64); testAbs(average-80) > 0.01 {
+		t.Errorf("Expected normalized average 80, got %.2f", average)
+	}
+}
+
+// TestSettleRemittance_WritesOffResidualWithinTolerance tests that a sub-toman residual is rounded off
+func TestSettleRemittance_WritesOffResidualWithinTolerance(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+	)
+
+	tenant := newTestTenant(t, db, "Rounding Exchange")
+
+	user := &models.User{
+		Email:    "rounding@example.com",
+		TenantID: &tenant.ID,
+		Role:     "tenant_owner",
+	}
+	db.Create(user)
+
+	service := NewRemittanceService(db)
+
+	outgoing := &models.OutgoingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Sara Karimi",
+		SenderPhone:   "+14165550101",
+		RecipientName: "Reza Karimi",
+		AmountIRR:     models.NewDecimal(50000000.5),
+		BuyRateCAD:    models.NewDecimal(80000),
+		ReceivedCAD:   models.NewDecimal(625),
+		CreatedBy:     user.ID,
+	}
+	if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+		t.Fatalf("Failed to create outgoing: %v", err)
+	}
+
+	incoming := &models.IncomingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Ali Moradi",
+		SenderPhone:   "+989125550101",
+		RecipientName: "Nima Moradi",
+		AmountIRR:     models.NewDecimal(50000000),
+		SellRateCAD:   models.NewDecimal(81000),
+		CreatedBy:     user.ID,
+	}
+	if err := service.CreateIncomingRemittance(incoming); err != nil {
+		t.Fatalf("Failed to create incoming: %v", err)
+	}
+
+	settlement, err := service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, models.NewDecimal(50000000), user.ID)
+	if err != nil {
+		t.Fatalf("Settlement failed: %v", err)
+	}
+
+	var settled models.OutgoingRemittance
+	db.First(&settled, outgoing.ID)
+	if settled.Status != models.RemittanceStatusCompleted {
+		t.Errorf("Expected outgoing to complete with a 0.5 IRR residual, got status %s", settled.Status)
+	}
+	if !settled.RemainingIRR.IsZero() {
+		t.Errorf("Expected remaining to be zero after write-off, got %s", settled.RemainingIRR.String())
+	}
+	if settled.WrittenOffIRR.Sub(models.NewDecimal(0.5)).Abs().GreaterThan(models.NewDecimal(0.001)) {
+		t.Errorf("Expected 0.5 IRR written off, got %s", settled.WrittenOffIRR.String())
+	}
+
+	var writeOffs []models.RemittanceWriteOff
+	db.Where("tenant_id = ?", tenant.ID).Find(&writeOffs)
+	if len(writeOffs) != 1 {
+		t.Fatalf("Expected 1 write-off entry, got %d", len(writeOffs))
+	}
+	writeOff := writeOffs[0]
+	if writeOff.SettlementID != settlement.ID || writeOff.OutgoingRemittanceID == nil || *writeOff.OutgoingRemittanceID != outgoing.ID {
+		t.Errorf("Write-off not linked to the settlement and outgoing remittance: %+v", writeOff)
+	}
+	if writeOff.Account != models.RemittanceWriteOffAccount || writeOff.Currency != "IRR" {
+		t.Errorf("Expected write-off posted to %s in IRR, got %s in %s", models.RemittanceWriteOffAccount, writeOff.Account, writeOff.Currency)
+	}
+	if writeOff.Amount.Sub(models.NewDecimal(0.5)).Abs().GreaterThan(models.NewDecimal(0.001)) {
+		t.Errorf("Expected write-off of 0.5 IRR, got %s", writeOff.Amount.String())
+	}
+}
+
+// TestSettleRemittancePreview_MatchesSettlement tests that a preview persists nothing and predicts the real settlement exactly
+func TestSettleRemittancePreview_MatchesSettlement(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+	)
+
+	tenant := newTestTenant(t, db, "Preview Exchange")
+
+	user := &models.User{
+		Email:    "preview@example.com",
+		TenantID: &tenant.ID,
+		Role:     "tenant_owner",
+	}
+	db.Create(user)
+
+	service := NewRemittanceService(db)
+
+	outgoing := &models.OutgoingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Sara Karimi",
+		SenderPhone:   "+14165550101",
+		RecipientName: "Reza Karimi",
+		AmountIRR:     models.NewDecimal(80000000),
+		BuyRateCAD:    models.NewDecimal(80000),
+		ReceivedCAD:   models.NewDecimal(1000),
+		CreatedBy:     user.ID,
+	}
+	if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+		t.Fatalf("Failed to create outgoing: %v", err)
+	}
+
+	incoming := &models.IncomingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Ali Moradi",
+		SenderPhone:   "+989125550101",
+		RecipientName: "Nima Moradi",
+		AmountIRR:     models.NewDecimal(30000000),
+		SellRateCAD:   models.NewDecimal(81000),
+		CreatedBy:     user.ID,
+	}
+	if err := service.CreateIncomingRemittance(incoming); err != nil {
+		t.Fatalf("Failed to create incoming: %v", err)
+	}
+
+	amount := models.NewDecimal(30000000)
+	preview, err := service.SettleRemittancePreview(tenant.ID, outgoing.ID, incoming.ID, amount)
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+
+	// The preview must leave no trace
+	var settlementCount int64
+	db.Model(&models.RemittanceSettlement{}).Count(&settlementCount)
+	if settlementCount != 0 {
+		t.Fatalf("Expected preview to persist no settlement, found %d", settlementCount)
+	}
+	var untouched models.OutgoingRemittance
+	db.First(&untouched, outgoing.ID)
+	if untouched.RemainingIRR.String() != outgoing.AmountIRR.String() || untouched.Status != models.RemittanceStatusPending {
+		t.Fatalf("Expected preview to leave outgoing untouched, got remaining %s status %s", untouched.RemainingIRR.String(), untouched.Status)
+	}
+
+	settlement, err := service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, amount, user.ID)
+	if err != nil {
+		t.Fatalf("Settlement failed: %v", err)
+	}
+
+	// The settled row is reloaded from its decimal(20,2) column, so compare at that scale
+	if preview.Settlement.ProfitCAD.StringFixed(2) != settlement.ProfitCAD.StringFixed(2) {
+		t.Errorf("Preview profit %s does not match settled profit %s", preview.Settlement.ProfitCAD.String(), settlement.ProfitCAD.String())
+	}
+	if preview.Settlement.SettledAmountIRR.String() != settlement.SettledAmountIRR.String() {
+		t.Errorf("Preview amount %s does not match settled amount %s", preview.Settlement.SettledAmountIRR.String(), settlement.SettledAmountIRR.String())
+	}
+	if preview.Settlement.Currency != settlement.Currency {
+		t.Errorf("Preview currency %s does not match settled currency %s", preview.Settlement.Currency, settlement.Currency)
+	}
+
+	var settledOutgoing models.OutgoingRemittance
+	db.First(&settledOutgoing, outgoing.ID)
+	if preview.OutgoingRemainingIRR.String() != settledOutgoing.RemainingIRR.String() || preview.OutgoingStatus != settledOutgoing.Status {
+		t.Errorf("Preview outgoing %s/%s does not match settled %s/%s",
+			preview.OutgoingRemainingIRR.String(), preview.OutgoingStatus, settledOutgoing.RemainingIRR.String(), settledOutgoing.Status)
+	}
+
+	var settledIncoming models.IncomingRemittance
+	db.First(&settledIncoming, incoming.ID)
+	if preview.IncomingRemainingIRR.String() != settledIncoming.RemainingIRR.String() || preview.IncomingStatus != settledIncoming.Status {
+		t.Errorf("Preview incoming %s/%s does not match settled %s/%s",
+			preview.IncomingRemainingIRR.String(), preview.IncomingStatus, settledIncoming.RemainingIRR.String(), settledIncoming.Status)
+	}
+}
+
+// TestSettleRemittance_NotifiesInvolvedBranches tests that only the settled remittances' branches receive the settlement event
+func TestSettleRemittance_NotifiesInvolvedBranches(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+	)
+
+	tenant := newTestTenant(t, db, "Notify Exchange")
+
+	newBranch := func(name, code string) *models.Branch {
+		branch := &models.Branch{TenantID: tenant.ID, Name: name, BranchCode: code, Status: models.BranchStatusActive}
+		if err := db.Create(branch).Error; err != nil {
+			t.Fatalf("Failed to create branch: %v", err)
+		}
+		return branch
+	}
+	toronto := newBranch("Toronto", "TOR-001")
+	tehran := newBranch("Tehran", "THR-001")
+	vancouver := newBranch("Vancouver", "VAN-001")
+
+	hub := GetHub()
+	newClient := func(branchID uint) *Client {
+		client := &Client{ID: fmt.Sprintf("branch-%d", branchID), TenantID: tenant.ID, BranchID: &branchID, Send: make(chan []byte, 8), Hub: hub}
+		client.Subscribe(ChannelSettlements)
+		hub.RegisterClient(client)
+		return client
+	}
+	torontoClient := newClient(toronto.ID)
+	tehranClient := newClient(tehran.ID)
+	vancouverClient := newClient(vancouver.ID)
+	defer func() {
+		for _, client := range []*Client{torontoClient, tehranClient, vancouverClient} {
+			hub.UnregisterClient(client)
+		}
+	}()
+
+	receive := func(client *Client) WSMessage {
+		select {
+		case raw := <-client.Send:
+			var message WSMessage
+			if err := json.Unmarshal(raw, &message); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			return message
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for a message on %s", client.ID)
+		}
+		return WSMessage{}
+	}
+
+	service := NewRemittanceService(db)
+
+	outgoing := &models.OutgoingRemittance{
+		TenantID:      tenant.ID,
+		BranchID:      &toronto.ID,
+		SenderName:    "Sara Karimi",
+		SenderPhone:   "+14165550101",
+		RecipientName: "Reza Karimi",
+		AmountIRR:     models.NewDecimal(40000000),
+		BuyRateCAD:    models.NewDecimal(80000),
+		ReceivedCAD:   models.NewDecimal(500),
+	}
+	if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+		t.Fatalf("Failed to create outgoing: %v", err)
+	}
+
+	incoming := &models.IncomingRemittance{
+		TenantID:      tenant.ID,
+		BranchID:      &tehran.ID,
+		SenderName:    "Ali Moradi",
+		SenderPhone:   "+989125550101",
+		RecipientName: "Nima Moradi",
+		AmountIRR:     models.NewDecimal(40000000),
+		SellRateCAD:   models.NewDecimal(81000),
+	}
+	if err := service.CreateIncomingRemittance(incoming); err != nil {
+		t.Fatalf("Failed to create incoming: %v", err)
+	}
+
+	settlement, err := service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, models.NewDecimal(40000000), 0)
+	if err != nil {
+		t.Fatalf("Settlement failed: %v", err)
+	}
+
+	for _, client := range []*Client{torontoClient, tehranClient} {
+		message := receive(client)
+		if message.Type != "settlement" || message.Action != "completed" {
+			t.Fatalf("Expected a settlement event on %s, got %s/%s", client.ID, message.Type, message.Action)
+		}
+		if id, _ := message.Data["settlementId"].(float64); uint(id) != settlement.ID {
+			t.Errorf("Expected settlement %d on %s, got %v", settlement.ID, client.ID, message.Data["settlementId"])
+		}
+	}
+
+	// The hub delivers in order, so the uninvolved branch's first message must be this tenant-wide marker
+	hub.Broadcast(WSMessage{Type: "settlement", Action: "marker", TenantID: tenant.ID})
+	if message := receive(vancouverClient); message.Action != "marker" {
+		t.Errorf("Expected the uninvolved branch to receive no settlement event, got %s/%s", message.Type, message.Action)
+	}
+}
+
+// TestCreateOutgoingRemittance_LinksSenderClient tests that a new sender becomes a client under the setting and a known one is reused
+func TestCreateOutgoingRemittance_LinksSenderClient(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.Client{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+	)
+
+	tenant := newTestTenant(t, db, "CRM Exchange")
+
+	service := NewRemittanceService(db)
+	newOutgoing := func(senderName, senderPhone string) *models.OutgoingRemittance {
+		outgoing := &models.OutgoingRemittance{
+			TenantID:      tenant.ID,
+			SenderName:    senderName,
+			SenderPhone:   senderPhone,
+			RecipientName: "Reza Karimi",
+			AmountIRR:     models.NewDecimal(10000000),
+			BuyRateCAD:    models.NewDecimal(80000),
+			ReceivedCAD:   models.NewDecimal(125),
+		}
+		if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+			t.Fatalf("Failed to create outgoing: %v", err)
+		}
+		return outgoing
+	}
+	countClients := func() int64 {
+		var count int64
+		db.Model(&models.Client{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+		return count
+	}
+
+	// Off by default: walk-in senders stay free text
+	if outgoing := newOutgoing("Sara Karimi", "+14165550171"); outgoing.ClientID != nil || countClients() != 0 {
+		t.Fatalf("Expected no client without the setting, got client %v and %d clients", outgoing.ClientID, countClients())
+	}
+
+	enabled := true
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{AutoCreateSenderClients: &enabled}); err != nil {
+		t.Fatalf("Failed to enable sender clients: %v", err)
+	}
+
+	first := newOutgoing("Sara Karimi", " +14165550171 ")
+	if first.ClientID == nil {
+		t.Fatal("Expected the new sender to be linked to a client")
+	}
+	var created models.Client
+	if err := db.First(&created, "id = ?", *first.ClientID).Error; err != nil {
+		t.Fatalf("Linked client was not created: %v", err)
+	}
+	if created.Name != "Sara Karimi" || created.PhoneNumber != "+14165550171" || created.TenantID != tenant.ID {
+		t.Errorf("Unexpected client created from sender: %+v", created)
+	}
+
+	second := newOutgoing("Sara K.", "+14165550171")
+	if second.ClientID == nil || *second.ClientID != created.ID {
+		t.Errorf("Expected the existing client %s to be reused, got %v", created.ID, second.ClientID)
+	}
+	if countClients() != 1 {
+		t.Errorf("Expected exactly 1 client, got %d", countClients())
+	}
+}
+
+// TestCreateRemittance_SamePartyPolicy verifies same-party remittances are allowed when the policy is off,
+// flagged under FLAG and refused under BLOCK
+func TestCreateRemittance_SamePartyPolicy(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.Client{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+	)
+
+	tenant := newTestTenant(t, db, "Same Party Exchange")
+
+	service := NewRemittanceService(db)
+	setPolicy := func(policy string) {
+		if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{SamePartyRemittances: &policy}); err != nil {
+			t.Fatalf("Failed to set policy %s: %v", policy, err)
+		}
+	}
+	outgoing := func(recipientPhone, recipientIBAN string) *models.OutgoingRemittance {
+		remittance := &models.OutgoingRemittance{
+			TenantID:      tenant.ID,
+			SenderName:    "Sara Karimi",
+			SenderPhone:   "+1 416 555 0181",
+			RecipientName: "Sara Karimi",
+			AmountIRR:     models.NewDecimal(10000000),
+			BuyRateCAD:    models.NewDecimal(80000),
+			ReceivedCAD:   models.NewDecimal(125),
+		}
+		if recipientPhone != "" {
+			remittance.RecipientPhone = &recipientPhone
+		}
+		if recipientIBAN != "" {
+			remittance.RecipientIBAN = &recipientIBAN
+		}
+		return remittance
+	}
+	samePhone := "14165550181"
+
+	t.Run("AllowedWhenOff", func(t *testing.T) {
+		remittance := outgoing(samePhone, "")
+		if err := service.CreateOutgoingRemittance(remittance); err != nil {
+			t.Fatalf("Expected same-party remittance to be allowed, got %v", err)
+		}
+		if remittance.SamePartyFlag != nil {
+			t.Errorf("Expected no flag with the policy off, got %q", *remittance.SamePartyFlag)
+		}
+	})
+
+	t.Run("FlaggedUnderFlag", func(t *testing.T) {
+		setPolicy("flag")
+		remittance := outgoing(samePhone, "")
+		if err := service.CreateOutgoingRemittance(remittance); err != nil {
+			t.Fatalf("Expected flagged remittance to be created, got %v", err)
+		}
+		var stored models.OutgoingRemittance
+		db.First(&stored, remittance.ID)
+		if stored.SamePartyFlag == nil {
+			t.Error("Expected the remittance to be flagged as same-party")
+		}
+
+		different := outgoing("+989121234567", "")
+		if err := service.CreateOutgoingRemittance(different); err != nil || different.SamePartyFlag != nil {
+			t.Errorf("Expected a different recipient not to be flagged, got %v / %v", err, different.SamePartyFlag)
+		}
+	})
+
+	t.Run("IBANUsedByTheSameCustomer", func(t *testing.T) {
+		senderIBAN := "IR120170000000123456789012"
+		recipientPhone := "+1 416 555 0181"
+		incoming := &models.IncomingRemittance{
+			TenantID:       tenant.ID,
+			SenderName:     "Ali Rezaei",
+			SenderPhone:    "+989121234567",
+			SenderIBAN:     &senderIBAN,
+			RecipientName:  "Sara Karimi",
+			RecipientPhone: &recipientPhone,
+			AmountIRR:      models.NewDecimal(8000000),
+			SellRateCAD:    models.NewDecimal(81000),
+		}
+		if err := service.CreateIncomingRemittance(incoming); err != nil {
+			t.Fatalf("Failed to create incoming: %v", err)
+		}
+
+		remittance := outgoing("+989129999999", "IR12 0170 0000 0012 3456 7890 12")
+		if err := service.CreateOutgoingRemittance(remittance); err != nil {
+			t.Fatalf("Expected flagged remittance to be created, got %v", err)
+		}
+		if remittance.SamePartyFlag == nil {
+			t.Error("Expected an outgoing remittance to an IBAN that paid the sender to be flagged")
+		}
+	})
+
+	t.Run("BlockedUnderBlock", func(t *testing.T) {
+		setPolicy(models.SamePartyRemittancesBlock)
+		var before int64
+		db.Model(&models.OutgoingRemittance{}).Count(&before)
+
+		err := service.CreateOutgoingRemittance(outgoing(samePhone, ""))
+		if !errors.Is(err, ErrSamePartyRemittance) {
+			t.Fatalf("Expected ErrSamePartyRemittance, got %v", err)
+		}
+		var after int64
+		db.Model(&models.OutgoingRemittance{}).Count(&after)
+		if after != before {
+			t.Errorf("Expected no remittance to be created, went from %d to %d", before, after)
+		}
+
+		recipientPhone := "(416) 555-0190"
+		incoming := &models.IncomingRemittance{
+			TenantID:       tenant.ID,
+			SenderName:     "Nima Ahmadi",
+			SenderPhone:    "416-555-0190",
+			RecipientName:  "Nima Ahmadi",
+			RecipientPhone: &recipientPhone,
+			AmountIRR:      models.NewDecimal(8000000),
+			SellRateCAD:    models.NewDecimal(81000),
+		}
+		if err := service.CreateIncomingRemittance(incoming); !errors.Is(err, ErrSamePartyRemittance) {
+			t.Errorf("Expected same-party incoming remittance to be blocked, got %v", err)
+		}
+	})
+}
+
 // Helper functions moved to test_helpers_test.go
+
+// TestSettleMultiple_AllocatesOneIncomingAcrossOutgoing verifies one incoming is split across two outgoing
+// remittances and that an over-allocation is rejected without applying anything
+func TestSettleMultiple_AllocatesOneIncomingAcrossOutgoing(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+	)
+
+	tenant := newTestTenant(t, db, "Treasury Exchange")
+	user := newTestUser(t, db, tenant.ID, "treasury@example.com", "tenant_owner")
+
+	service := NewRemittanceService(db)
+
+	newOutgoing := func(amount float64) *models.OutgoingRemittance {
+		outgoing := &models.OutgoingRemittance{
+			TenantID:      tenant.ID,
+			SenderName:    "Sara Karimi",
+			SenderPhone:   "+14165550101",
+			RecipientName: "Reza Karimi",
+			AmountIRR:     models.NewDecimal(amount),
+			BuyRateCAD:    models.NewDecimal(80000),
+			ReceivedCAD:   models.NewDecimal(amount / 80000),
+			CreatedBy:     user.ID,
+		}
+		if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+			t.Fatalf("Failed to create outgoing: %v", err)
+		}
+		return outgoing
+	}
+	first := newOutgoing(40000000)
+	second := newOutgoing(80000000)
+
+	incoming := &models.IncomingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Ali Moradi",
+		SenderPhone:   "+989125550101",
+		RecipientName: "Nima Moradi",
+		AmountIRR:     models.NewDecimal(100000000),
+		SellRateCAD:   models.NewDecimal(81000),
+		CreatedBy:     user.ID,
+	}
+	if err := service.CreateIncomingRemittance(incoming); err != nil {
+		t.Fatalf("Failed to create incoming: %v", err)
+	}
+
+	remaining := func() (float64, float64, float64) {
+		var out1, out2 models.OutgoingRemittance
+		var in models.IncomingRemittance
+		db.First(&out1, first.ID)
+		db.First(&out2, second.ID)
+		db.First(&in, incoming.ID)
+		return out1.RemainingIRR.Float64(), out2.RemainingIRR.Float64(), in.RemainingIRR.Float64()
+	}
+
+	t.Run("OverAllocationRejected", func(t *testing.T) {
+		_, err := service.SettleMultiple(tenant.ID, incoming.ID, []SettlementAllocation{
+			{OutgoingID: first.ID, AmountIRR: models.NewDecimal(40000000)},
+			{OutgoingID: second.ID, AmountIRR: models.NewDecimal(70000000)},
+		}, nil, user.ID)
+		if err == nil {
+			t.Fatal("Expected allocations above the incoming remaining to be rejected")
+		}
+		var count int64
+		db.Model(&models.RemittanceSettlement{}).Count(&count)
+		out1, out2, in := remaining()
+		if count != 0 || out1 != 40000000 || out2 != 80000000 || in != 100000000 {
+			t.Errorf("Expected nothing applied, got %d settlements and balances %v/%v/%v", count, out1, out2, in)
+		}
+	})
+
+	notes := "Split across two payouts"
+	settlements, err := service.SettleMultiple(tenant.ID, incoming.ID, []SettlementAllocation{
+		{OutgoingID: first.ID, AmountIRR: models.NewDecimal(40000000)},
+		{OutgoingID: second.ID, AmountIRR: models.NewDecimal(50000000)},
+	}, &notes, user.ID)
+	if err != nil {
+		t.Fatalf("SettleMultiple failed: %v", err)
+	}
+	if len(settlements) != 2 {
+		t.Fatalf("Expected 2 settlements, got %d", len(settlements))
+	}
+	for _, settlement := range settlements {
+		var saved models.RemittanceSettlement
+		db.First(&saved, settlement.ID)
+		if saved.Notes == nil || *saved.Notes != notes {
+			t.Errorf("Expected settlement %d to be saved with the notes, got %v", settlement.ID, saved.Notes)
+		}
+	}
+
+	out1, out2, in := remaining()
+	if out1 != 0 || out2 != 30000000 || in != 10000000 {
+		t.Errorf("Expected remaining 0 / 30000000 / 10000000, got %v / %v / %v", out1, out2, in)
+	}
+
+	var firstAfter, secondAfter models.OutgoingRemittance
+	db.First(&firstAfter, first.ID)
+	db.First(&secondAfter, second.ID)
+	if firstAfter.Status != models.RemittanceStatusCompleted || secondAfter.Status != models.RemittanceStatusPartial {
+		t.Errorf("Expected COMPLETED and PARTIAL, got %s and %s", firstAfter.Status, secondAfter.Status)
+	}
+}
+
+// TestSettleRemittance_LargeSettlementWaitsForApproval verifies a settlement at the approval limit stays pending
+// without touching either remittance, cannot be approved by its creator, and applies once another admin approves
+func TestSettleRemittance_LargeSettlementWaitsForApproval(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+		&models.PendingSettlement{},
+	)
+
+	tenant := newTestTenant(t, db, "Approval Exchange")
+	teller := newTestUser(t, db, tenant.ID, "teller@example.com", models.RoleTenantUser)
+	creator := newTestUser(t, db, tenant.ID, "creator@example.com", models.RoleTenantAdmin)
+	approver := newTestUser(t, db, tenant.ID, "approver@example.com", models.RoleTenantOwner)
+
+	limit := 100000000.0
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{SettlementApprovalAmountIRR: &limit}); err != nil {
+		t.Fatalf("Failed to set approval limit: %v", err)
+	}
+
+	service := NewRemittanceService(db)
+	outgoing := &models.OutgoingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Sara Karimi",
+		SenderPhone:   "+14165550111",
+		RecipientName: "Reza Karimi",
+		AmountIRR:     models.NewDecimal(200000000),
+		BuyRateCAD:    models.NewDecimal(80000),
+		ReceivedCAD:   models.NewDecimal(2500),
+		CreatedBy:     creator.ID,
+	}
+	if err := service.CreateOutgoingRemittance(outgoing); err != nil {
+		t.Fatalf("Failed to create outgoing: %v", err)
+	}
+	incoming := &models.IncomingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Ali Moradi",
+		SenderPhone:   "+989125550111",
+		RecipientName: "Nima Moradi",
+		AmountIRR:     models.NewDecimal(200000000),
+		SellRateCAD:   models.NewDecimal(81000),
+		CreatedBy:     creator.ID,
+	}
+	if err := service.CreateIncomingRemittance(incoming); err != nil {
+		t.Fatalf("Failed to create incoming: %v", err)
+	}
+
+	// Below the limit settles straight away
+	if _, err := service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, models.NewDecimal(50000000), creator.ID); err != nil {
+		t.Fatalf("Expected a small settlement to apply, got %v", err)
+	}
+
+	_, err := service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, models.NewDecimal(150000000), creator.ID)
+	var held *SettlementPendingApprovalError
+	if !errors.As(err, &held) {
+		t.Fatalf("Expected the large settlement to be held for approval, got %v", err)
+	}
+	if held.Pending.Status != models.PendingSettlementStatusPending || held.Pending.ProjectedProfitCAD.IsZero() {
+		t.Errorf("Expected a pending settlement with its projected profit, got %+v", held.Pending)
+	}
+
+	remaining := func() (float64, float64, int64) {
+		var out models.OutgoingRemittance
+		var in models.IncomingRemittance
+		var settlements int64
+		db.First(&out, outgoing.ID)
+		db.First(&in, incoming.ID)
+		db.Model(&models.RemittanceSettlement{}).Where("tenant_id = ?", tenant.ID).Count(&settlements)
+		return out.RemainingIRR.Float64(), in.RemainingIRR.Float64(), settlements
+	}
+	if out, in, count := remaining(); out != 150000000 || in != 150000000 || count != 1 {
+		t.Errorf("Expected balances to wait for approval, got outgoing %v incoming %v settlements %d", out, in, count)
+	}
+
+	// Asking again does not queue a second request
+	_, err = service.SettleRemittance(tenant.ID, outgoing.ID, incoming.ID, models.NewDecimal(150000000), creator.ID)
+	if !errors.As(err, &held) {
+		t.Fatalf("Expected the repeated request to be held, got %v", err)
+	}
+	if pending, _ := service.GetPendingSettlements(tenant.ID); len(pending) != 1 {
+		t.Errorf("Expected one pending settlement, got %d", len(pending))
+	}
+
+	if _, err := service.ApproveSettlement(tenant.ID, held.Pending.ID, creator); !errors.Is(err, ErrSettlementSelfApproval) {
+		t.Errorf("Expected the creator to be refused, got %v", err)
+	}
+	if _, err := service.ApproveSettlement(tenant.ID, held.Pending.ID, teller); !errors.Is(err, ErrSettlementApprovalForbidden) {
+		t.Errorf("Expected a teller to be refused, got %v", err)
+	}
+	if out, _, count := remaining(); out != 150000000 || count != 1 {
+		t.Errorf("Expected refused approvals to change nothing, got outgoing %v settlements %d", out, count)
+	}
+
+	settlement, err := service.ApproveSettlement(tenant.ID, held.Pending.ID, approver)
+	if err != nil {
+		t.Fatalf("Failed to approve settlement: %v", err)
+	}
+	if settlement.SettledAmountIRR.Float64() != 150000000 || settlement.CreatedBy != creator.ID {
+		t.Errorf("Expected the held settlement to apply as its creator's, got %v by %d", settlement.SettledAmountIRR, settlement.CreatedBy)
+	}
+	if out, in, count := remaining(); out != 0 || in != 0 || count != 2 {
+		t.Errorf("Expected the approval to settle both remittances, got outgoing %v incoming %v settlements %d", out, in, count)
+	}
+
+	var decided models.PendingSettlement
+	db.First(&decided, held.Pending.ID)
+	if decided.Status != models.PendingSettlementStatusApproved || decided.DecidedBy == nil || *decided.DecidedBy != approver.ID ||
+		decided.SettlementID == nil || *decided.SettlementID != settlement.ID {
+		t.Errorf("Expected the pending settlement to record its approval, got %+v", decided)
+	}
+	if _, err := service.ApproveSettlement(tenant.ID, held.Pending.ID, approver); err == nil {
+		t.Error("Expected a decided settlement not to be approved twice")
+	}
+}
@@ -26,6 +26,11 @@ func (s *RemittanceSettlementService) CreateSettlement(
 	settledBy uint,
 ) (*models.RemittanceSettlement, error) {
 
+	settings, err := NewTenantSettingsService(s.DB).GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start transaction
 	tx := s.DB.Begin()
 	defer func() {
@@ -80,6 +85,12 @@ func (s *RemittanceSettlementService) CreateSettlement(
 	revenue := settlementAmount.Div(incomingSellRate)
 	profitCAD := cost.Sub(revenue)
 
+	// Large settlements have to go through SettleRemittance, which holds them for approval
+	if settlementNeedsApproval(settings, settlementAmount, profitCAD) {
+		tx.Rollback()
+		return nil, ErrSettlementNeedsApproval
+	}
+
 	//Convert to pointer for notes
 	var notesPtr *string
 	if notes != "" {
@@ -95,9 +106,13 @@ func (s *RemittanceSettlementService) CreateSettlement(
 		OutgoingBuyRate:      outgoingBuyRate,  // Existing field name
 		IncomingSellRate:     incomingSellRate, // Existing field name
 		ProfitCAD:            profitCAD,        // Existing field name
+		Currency:             outgoing.SettlementCurrency,
 		Notes:                notesPtr,
 		CreatedBy:            settledBy,
 	}
+	if settlement.Currency == "" {
+		settlement.Currency = "CAD"
+	}
 
 	if err := tx.Create(&settlement).Error; err != nil {
 		tx.Rollback()
@@ -153,6 +168,7 @@ func (s *RemittanceSettlementService) CreateSettlement(
 		return nil, err
 	}
 
+	GetEventBus().Publish(settlementEvent(&settlement))
 	return &settlement, nil
 }
 
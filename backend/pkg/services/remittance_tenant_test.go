@@ -24,6 +24,7 @@ func TestRemittanceWithTenantUser(t *testing.T) {
 		&models.User{},
 		&models.Branch{},
 		&models.License{},
+		&models.TenantSettings{},
 		&models.OutgoingRemittance{},
 		&models.IncomingRemittance{},
 		&models.RemittanceSettlement{},
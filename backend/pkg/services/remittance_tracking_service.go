@@ -0,0 +1,186 @@
+package services
+
+import (
+	"api/pkg/models"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTrackingCodeNotFound is returned for unknown tracking codes and for remittances of tenants without public tracking
+var ErrTrackingCodeNotFound = errors.New("tracking code not found")
+
+// Public tracking statuses. They summarise the internal remittance statuses without revealing settlement details.
+const (
+	TrackingStatusCreated    = "CREATED"    // The exchange has recorded the remittance
+	TrackingStatusProcessing = "PROCESSING" // Funds are being matched and moved
+	TrackingStatusSettled    = "SETTLED"    // An outgoing remittance has been delivered in full
+	TrackingStatusPaid       = "PAID"       // An incoming remittance has been paid out to the recipient
+	TrackingStatusCancelled  = "CANCELLED"  // The remittance was cancelled
+)
+
+// trackingCodeAlphabet leaves out characters that are easily confused when read aloud or typed (0/O, 1/I)
+const trackingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// trackingCodeLength gives 60 random bits, so codes cannot practically be guessed
+const trackingCodeLength = 12
+
+// RemittanceTrackingEvent is one step of a remittance's public status timeline
+type RemittanceTrackingEvent struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// RemittanceTrackingStatus is what the public status page shows for a tracking code. It deliberately carries
+// no names, phone numbers, accounts, amounts or rates.
+type RemittanceTrackingStatus struct {
+	TrackingCode string                    `json:"trackingCode"`
+	Direction    string                    `json:"direction"` // OUTGOING or INCOMING
+	Status       string                    `json:"status"`
+	Timeline     []RemittanceTrackingEvent `json:"timeline"`
+}
+
+// RemittanceTrackingService answers public, unauthenticated remittance status lookups
+type RemittanceTrackingService struct {
+	db *gorm.DB
+}
+
+// NewRemittanceTrackingService creates a new RemittanceTrackingService
+func NewRemittanceTrackingService(db *gorm.DB) *RemittanceTrackingService {
+	return &RemittanceTrackingService{db: db}
+}
+
+// generateTrackingCode returns a new random tracking code for a remittance
+func generateTrackingCode() (*string, error) {
+	buf := make([]byte, trackingCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate tracking code: %w", err)
+	}
+	for i, b := range buf {
+		buf[i] = trackingCodeAlphabet[int(b)%len(trackingCodeAlphabet)]
+	}
+	code := string(buf)
+	return &code, nil
+}
+
+// GetStatus returns the public status timeline of the remittance with the given tracking code
+func (s *RemittanceTrackingService) GetStatus(code string) (*RemittanceTrackingStatus, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrTrackingCodeNotFound
+	}
+
+	var outgoing models.OutgoingRemittance
+	err := s.db.Where("tracking_code = ?", code).First(&outgoing).Error
+	if err == nil {
+		if err := s.ensureTrackingEnabled(outgoing.TenantID); err != nil {
+			return nil, err
+		}
+		return s.outgoingStatus(&outgoing, code)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var incoming models.IncomingRemittance
+	err = s.db.Where("tracking_code = ?", code).First(&incoming).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTrackingCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureTrackingEnabled(incoming.TenantID); err != nil {
+		return nil, err
+	}
+	return s.incomingStatus(&incoming, code)
+}
+
+// ensureTrackingEnabled hides the remittances of tenants that have not turned on public tracking
+func (s *RemittanceTrackingService) ensureTrackingEnabled(tenantID uint) error {
+	settings, err := NewTenantSettingsService(s.db).GetSettings(tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if !settings.PublicTrackingEnabled {
+		return ErrTrackingCodeNotFound
+	}
+	return nil
+}
+
+// firstSettlementAt returns when the remittance was first matched in a settlement, if it has been
+func (s *RemittanceTrackingService) firstSettlementAt(column string, remittanceID uint) (*time.Time, error) {
+	var settlement models.RemittanceSettlement
+	err := s.db.Where(column+" = ?", remittanceID).Order("created_at ASC").First(&settlement).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settlement.CreatedAt, nil
+}
+
+func (s *RemittanceTrackingService) outgoingStatus(remittance *models.OutgoingRemittance, code string) (*RemittanceTrackingStatus, error) {
+	timeline := []RemittanceTrackingEvent{{Status: TrackingStatusCreated, At: remittance.CreatedAt}}
+
+	processingAt, err := s.firstSettlementAt("outgoing_remittance_id", remittance.ID)
+	if err != nil {
+		return nil, err
+	}
+	if processingAt != nil {
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusProcessing, At: *processingAt})
+	}
+
+	switch remittance.Status {
+	case models.RemittanceStatusCompleted:
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusSettled, At: eventTime(remittance.CompletedAt, remittance.UpdatedAt)})
+	case models.RemittanceStatusCancelled:
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusCancelled, At: eventTime(remittance.CancelledAt, remittance.UpdatedAt)})
+	}
+
+	return newTrackingStatus(code, "OUTGOING", timeline), nil
+}
+
+func (s *RemittanceTrackingService) incomingStatus(remittance *models.IncomingRemittance, code string) (*RemittanceTrackingStatus, error) {
+	timeline := []RemittanceTrackingEvent{{Status: TrackingStatusCreated, At: remittance.CreatedAt}}
+
+	processingAt, err := s.firstSettlementAt("incoming_remittance_id", remittance.ID)
+	if err != nil {
+		return nil, err
+	}
+	if processingAt != nil {
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusProcessing, At: *processingAt})
+	}
+
+	switch {
+	case remittance.Status == models.RemittanceStatusCancelled:
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusCancelled, At: eventTime(remittance.CancelledAt, remittance.UpdatedAt)})
+	case remittance.Status == models.RemittanceStatusPaid || remittance.PaidAt != nil:
+		timeline = append(timeline, RemittanceTrackingEvent{Status: TrackingStatusPaid, At: eventTime(remittance.PaidAt, remittance.UpdatedAt)})
+	}
+
+	return newTrackingStatus(code, "INCOMING", timeline), nil
+}
+
+// newTrackingStatus builds the response, taking the current status from the latest timeline event
+func newTrackingStatus(code, direction string, timeline []RemittanceTrackingEvent) *RemittanceTrackingStatus {
+	return &RemittanceTrackingStatus{
+		TrackingCode: code,
+		Direction:    direction,
+		Status:       timeline[len(timeline)-1].Status,
+		Timeline:     timeline,
+	}
+}
+
+// eventTime returns the recorded time of an event, falling back to the remittance's last update
+func eventTime(at *time.Time, fallback time.Time) time.Time {
+	if at != nil {
+		return *at
+	}
+	return fallback
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestRemittanceTracking_TimelineFollowsStatus verifies the public timeline reflects each status transition and leaks no sensitive fields
+func TestRemittanceTracking_TimelineFollowsStatus(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.User{},
+		&models.Branch{},
+		&models.TenantSettings{},
+		&models.OutgoingRemittance{},
+		&models.IncomingRemittance{},
+		&models.RemittanceSettlement{},
+		&models.RemittanceWriteOff{},
+	)
+
+	tenant := newTestTenant(t, db, "Tracking Exchange")
+	user := newTestUser(t, db, tenant.ID, "tracking@example.com", "tenant_owner")
+	settings := models.DefaultTenantSettings(tenant.ID)
+	settings.PublicTrackingEnabled = true
+	db.Create(&settings)
+
+	remittances := NewRemittanceService(db)
+	tracking := NewRemittanceTrackingService(db)
+
+	outgoing := &models.OutgoingRemittance{
+		TenantID:      tenant.ID,
+		SenderName:    "Michael Chen",
+		SenderPhone:   "+16475551234",
+		RecipientName: "Fatemeh Ahmadi",
+		RecipientIBAN: testStringPtr("IR650170000000123456789012"),
+		AmountIRR:     models.NewDecimal(170000000),
+		BuyRateCAD:    models.NewDecimal(85000),
+		ReceivedCAD:   models.NewDecimal(2000),
+		CreatedBy:     user.ID,
+	}
+	if err := remittances.CreateOutgoingRemittance(outgoing); err != nil {
+		t.Fatalf("Failed to create outgoing remittance: %v", err)
+	}
+	if outgoing.TrackingCode == nil || len(*outgoing.TrackingCode) != trackingCodeLength {
+		t.Fatalf("Expected a %d character tracking code, got %v", trackingCodeLength, outgoing.TrackingCode)
+	}
+
+	newIncoming := func(amount float64) *models.IncomingRemittance {
+		incoming := &models.IncomingRemittance{
+			TenantID:      tenant.ID,
+			SenderName:    "Ali Rezaei",
+			SenderPhone:   "+989121234567",
+			RecipientName: "Sara Rezaei",
+			AmountIRR:     models.NewDecimal(amount),
+			SellRateCAD:   models.NewDecimal(86000),
+			CreatedBy:     user.ID,
+		}
+		if err := remittances.CreateIncomingRemittance(incoming); err != nil {
+			t.Fatalf("Failed to create incoming remittance: %v", err)
+		}
+		return incoming
+	}
+	first := newIncoming(100000000)
+	second := newIncoming(70000000)
+
+	statuses := func(code string) (*RemittanceTrackingStatus, []string) {
+		t.Helper()
+		status, err := tracking.GetStatus(code)
+		if err != nil {
+			t.Fatalf("Failed to track %s: %v", code, err)
+		}
+		steps := make([]string, len(status.Timeline))
+		for i, event := range status.Timeline {
+			steps[i] = event.Status
+		}
+		return status, steps
+	}
+	expectSteps := func(got []string, want ...string) {
+		t.Helper()
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected timeline %v, got %v", want, got)
+		}
+	}
+
+	code := *outgoing.TrackingCode
+	status, steps := statuses(strings.ToLower(code))
+	expectSteps(steps, TrackingStatusCreated)
+	if status.Status != TrackingStatusCreated || status.Direction != "OUTGOING" {
+		t.Errorf("Expected a CREATED outgoing remittance, got %s %s", status.Status, status.Direction)
+	}
+
+	if _, err := remittances.SettleRemittance(tenant.ID, outgoing.ID, first.ID, models.NewDecimal(100000000), user.ID); err != nil {
+		t.Fatalf("First settlement failed: %v", err)
+	}
+	status, steps = statuses(code)
+	expectSteps(steps, TrackingStatusCreated, TrackingStatusProcessing)
+	if status.Status != TrackingStatusProcessing {
+		t.Errorf("Expected PROCESSING after a partial settlement, got %s", status.Status)
+	}
+
+	if _, err := remittances.SettleRemittance(tenant.ID, outgoing.ID, second.ID, models.NewDecimal(70000000), user.ID); err != nil {
+		t.Fatalf("Second settlement failed: %v", err)
+	}
+	status, steps = statuses(code)
+	expectSteps(steps, TrackingStatusCreated, TrackingStatusProcessing, TrackingStatusSettled)
+	if status.Status != TrackingStatusSettled {
+		t.Errorf("Expected SETTLED once fully settled, got %s", status.Status)
+	}
+
+	t.Run("IncomingPaidOut", func(t *testing.T) {
+		_, steps := statuses(*first.TrackingCode)
+		expectSteps(steps, TrackingStatusCreated, TrackingStatusProcessing)
+
+		if err := remittances.MarkIncomingAsPaid(tenant.ID, first.ID, user.ID, "CASH", ""); err != nil {
+			t.Fatalf("Failed to mark incoming as paid: %v", err)
+		}
+		status, steps := statuses(*first.TrackingCode)
+		expectSteps(steps, TrackingStatusCreated, TrackingStatusProcessing, TrackingStatusPaid)
+		if status.Direction != "INCOMING" {
+			t.Errorf("Expected an INCOMING remittance, got %s", status.Direction)
+		}
+	})
+
+	t.Run("OmitsSensitiveFields", func(t *testing.T) {
+		body, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("Failed to encode status: %v", err)
+		}
+		for _, secret := range []string{"Michael", "6475551234", "Fatemeh", "IR6501", "170000000", "85000", outgoing.RemittanceCode, "amount", "rate", "tenant"} {
+			if strings.Contains(strings.ToLower(string(body)), strings.ToLower(secret)) {
+				t.Errorf("Expected the public status to omit %q, got %s", secret, body)
+			}
+		}
+	})
+
+	t.Run("UnknownCode", func(t *testing.T) {
+		if _, err := tracking.GetStatus("ABCDEFGHJKLM"); !errors.Is(err, ErrTrackingCodeNotFound) {
+			t.Errorf("Expected ErrTrackingCodeNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TrackingDisabled", func(t *testing.T) {
+		db.Model(&models.TenantSettings{}).Where("tenant_id = ?", tenant.ID).Update("public_tracking_enabled", false)
+		if _, err := tracking.GetStatus(code); !errors.Is(err, ErrTrackingCodeNotFound) {
+			t.Errorf("Expected ErrTrackingCodeNotFound while tracking is off, got %v", err)
+		}
+	})
+}
@@ -16,6 +16,7 @@ func TestRemittanceValidation(t *testing.T) {
 	db.AutoMigrate(
 		&models.Tenant{},
 		&models.User{},
+		&models.TenantSettings{},
 		&models.OutgoingRemittance{},
 		&models.IncomingRemittance{},
 		&models.RemittanceSettlement{},
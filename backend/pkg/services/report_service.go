@@ -164,3 +164,79 @@ func (s *ReportService) generateReport(tenantID uint, branchID *uint, startDate,
 
 	return report, nil
 }
+
+// cancelledTransactionStatuses are the statuses counted as voided/cancelled in quality reporting
+var cancelledTransactionStatuses = []string{models.StatusCancelled}
+
+// CancellationRateReport is the share of transactions that were voided/cancelled in a period
+type CancellationRateReport struct {
+	StartDate         time.Time                `json:"startDate"`
+	EndDate           time.Time                `json:"endDate"`
+	TotalTransactions int64                    `json:"totalTransactions"`
+	CancelledCount    int64                    `json:"cancelledCount"`
+	CancellationRate  float64                  `json:"cancellationRate"` // CancelledCount / TotalTransactions (0-1)
+	Branches          []BranchCancellationRate `json:"branches"`
+}
+
+// BranchCancellationRate is the cancellation rate of a single branch
+type BranchCancellationRate struct {
+	BranchID          uint    `json:"branchId"`
+	BranchName        string  `json:"branchName"`
+	TotalTransactions int64   `json:"totalTransactions"`
+	CancelledCount    int64   `json:"cancelledCount"`
+	CancellationRate  float64 `json:"cancellationRate"`
+}
+
+// cancellationRate returns cancelled/total, or 0 when there are no transactions
+func cancellationRate(cancelled, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(cancelled) / float64(total)
+}
+
+// GetCancellationRate computes the ratio of voided/cancelled to total transactions, overall and per branch
+func (s *ReportService) GetCancellationRate(tenantID uint, branchID *uint, startDate, endDate time.Time) (*CancellationRateReport, error) {
+	var rows []struct {
+		BranchID   *uint
+		BranchName string
+		Total      int64
+		Cancelled  int64
+	}
+
+	query := s.DB.Model(&models.Transaction{}).
+		Select("transactions.branch_id, branches.name as branch_name, COUNT(*) as total, "+
+			"SUM(CASE WHEN transactions.status IN ? THEN 1 ELSE 0 END) as cancelled", cancelledTransactionStatuses).
+		Joins("LEFT JOIN branches ON transactions.branch_id = branches.id").
+		Where("transactions.tenant_id = ? AND transactions.transaction_date >= ? AND transactions.transaction_date < ?",
+			tenantID, startDate, endDate)
+	if branchID != nil {
+		query = query.Where("transactions.branch_id = ?", *branchID)
+	}
+	if err := query.Group("transactions.branch_id, branches.name").Order("transactions.branch_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	report := &CancellationRateReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Branches:  []BranchCancellationRate{},
+	}
+	for _, row := range rows {
+		report.TotalTransactions += row.Total
+		report.CancelledCount += row.Cancelled
+		if row.BranchID == nil { // Transactions without a branch only count towards the overall rate
+			continue
+		}
+		report.Branches = append(report.Branches, BranchCancellationRate{
+			BranchID:          *row.BranchID,
+			BranchName:        row.BranchName,
+			TotalTransactions: row.Total,
+			CancelledCount:    row.Cancelled,
+			CancellationRate:  cancellationRate(row.Cancelled, row.Total),
+		})
+	}
+	report.CancellationRate = cancellationRate(report.CancelledCount, report.TotalTransactions)
+
+	return report, nil
+}
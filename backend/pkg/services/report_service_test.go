@@ -0,0 +1,83 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestGetCancellationRate verifies the cancelled share is computed overall and per branch
+func TestGetCancellationRate(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{})
+
+	tenant := newTestTenant(t, db, "Quality Exchange")
+	downtown := newTestBranch(t, db, tenant.ID, "Downtown", "DT")
+	airport := newTestBranch(t, db, tenant.ID, "Airport", "AP")
+	client := &models.Client{ID: "client-quality", TenantID: tenant.ID, Name: "Quality Client", PhoneNumber: "+14165550111"}
+	db.Create(client)
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	seq := 0
+	create := func(branchID uint, status string, date time.Time) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-quality-%d", seq),
+			TenantID:        tenant.ID,
+			BranchID:        &branchID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(100),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(73),
+			RateApplied:     models.NewDecimal(0.73),
+			Status:          status,
+			TransactionDate: date,
+		})
+	}
+
+	inRange := start.AddDate(0, 0, 10)
+	create(downtown.ID, models.StatusCompleted, inRange)
+	create(downtown.ID, models.StatusCompleted, inRange)
+	create(downtown.ID, models.StatusCompleted, inRange)
+	create(downtown.ID, models.StatusCancelled, inRange)
+	create(airport.ID, models.StatusCompleted, inRange)
+	create(airport.ID, models.StatusCancelled, inRange)
+	create(airport.ID, models.StatusCancelled, end.AddDate(0, 0, 1)) // outside the period
+
+	reportService := NewReportService(db)
+
+	report, err := reportService.GetCancellationRate(tenant.ID, nil, start, end)
+	if err != nil {
+		t.Fatalf("Failed to compute cancellation rate: %v", err)
+	}
+	if report.TotalTransactions != 6 || report.CancelledCount != 2 {
+		t.Fatalf("Expected 2 of 6 cancelled, got %d of %d", report.CancelledCount, report.TotalTransactions)
+	}
+	if math.Abs(report.CancellationRate-1.0/3.0) > 1e-9 {
+		t.Errorf("Expected overall rate 0.333, got %v", report.CancellationRate)
+	}
+
+	rates := make(map[uint]BranchCancellationRate)
+	for _, branch := range report.Branches {
+		rates[branch.BranchID] = branch
+	}
+	if got := rates[downtown.ID]; got.TotalTransactions != 4 || got.CancellationRate != 0.25 {
+		t.Errorf("Expected Downtown 1/4 cancelled, got %+v", got)
+	}
+	if got := rates[airport.ID]; got.TotalTransactions != 2 || got.CancellationRate != 0.5 {
+		t.Errorf("Expected Airport 1/2 cancelled, got %+v", got)
+	}
+
+	filtered, err := reportService.GetCancellationRate(tenant.ID, &airport.ID, start, end)
+	if err != nil {
+		t.Fatalf("Failed to compute branch cancellation rate: %v", err)
+	}
+	if len(filtered.Branches) != 1 || filtered.CancellationRate != 0.5 {
+		t.Errorf("Expected only Airport at 0.5, got %d branches at %v", len(filtered.Branches), filtered.CancellationRate)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"api/pkg/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestScheduledExportDue verifies each frequency becomes due one period after its last run
+func TestScheduledExportDue(t *testing.T) {
+	now := time.Date(2026, 5, 15, 9, 0, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time { return &t }
+
+	cases := []struct {
+		name      string
+		frequency string
+		active    bool
+		lastRun   *time.Time
+		want      bool
+	}{
+		{"NeverRun", models.ExportFrequencyDaily, true, nil, true},
+		{"Inactive", models.ExportFrequencyDaily, false, nil, false},
+		{"DailyNotYet", models.ExportFrequencyDaily, true, at(now.Add(-23 * time.Hour)), false},
+		{"DailyElapsed", models.ExportFrequencyDaily, true, at(now.Add(-24 * time.Hour)), true},
+		{"WeeklyNotYet", models.ExportFrequencyWeekly, true, at(now.AddDate(0, 0, -6)), false},
+		{"WeeklyElapsed", models.ExportFrequencyWeekly, true, at(now.AddDate(0, 0, -7)), true},
+		{"MonthlyNotYet", models.ExportFrequencyMonthly, true, at(now.AddDate(0, 0, -20)), false},
+		{"MonthlyElapsed", models.ExportFrequencyMonthly, true, at(now.AddDate(0, -1, 0)), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule := &models.ScheduledExport{Frequency: tc.frequency, IsActive: tc.active, LastRunAt: tc.lastRun}
+			if got := ScheduledExportDue(schedule, now); got != tc.want {
+				t.Errorf("Expected due=%v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestScheduledExportService_EmailsDueExportOnce verifies a due schedule runs its export profile and emails the
+// file once, recording the run
+func TestScheduledExportService_EmailsDueExportOnce(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.Client{}, &models.Transaction{}, &models.ExportProfile{}, &models.ScheduledExport{})
+
+	tenant := newTestTenant(t, db, "Scheduled Export Exchange")
+	client := &models.Client{ID: "client-scheduled", TenantID: tenant.ID, Name: "Scheduled Client", PhoneNumber: "+14165550123"}
+	db.Create(client)
+	db.Create(&models.Transaction{
+		ID:              "txn-scheduled",
+		TenantID:        tenant.ID,
+		ClientID:        client.ID,
+		PaymentMethod:   models.TransactionMethodCash,
+		SendCurrency:    "CAD",
+		SendAmount:      models.NewDecimal(100),
+		ReceiveCurrency: "USD",
+		ReceiveAmount:   models.NewDecimal(73),
+		RateApplied:     models.NewDecimal(0.73),
+	})
+
+	profile, err := NewExportProfileService(db).CreateProfile(tenant.ID, ExportProfileRequest{
+		Name:    "Daily CAD",
+		Columns: []string{"id", "sendAmount"},
+		Format:  "csv",
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create export profile: %v", err)
+	}
+
+	notifier := NewMockNotificationProvider()
+	service := NewScheduledExportService(db)
+	service.Notifier = notifier
+
+	if _, err := service.CreateSchedule(tenant.ID, ScheduledExportRequest{ExportProfileID: profile.ID, Frequency: "weekly", RecipientEmail: "not-an-email"}, 0); err == nil {
+		t.Error("Expected an invalid recipient to be rejected")
+	}
+	schedule, err := service.CreateSchedule(tenant.ID, ScheduledExportRequest{
+		ExportProfileID: profile.ID,
+		Frequency:       "daily",
+		RecipientEmail:  "accounting@example.com",
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+
+	now := time.Now()
+	ran, err := service.RunDue(now)
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(ran) != 1 || ran[0] != schedule.ID {
+		t.Fatalf("Expected schedule %d to run, got %v", schedule.ID, ran)
+	}
+
+	if len(notifier.Sent) != 1 {
+		t.Fatalf("Expected one email, got %d", len(notifier.Sent))
+	}
+	sent := notifier.Sent[0]
+	if sent.To != "accounting@example.com" || len(sent.Attachments) != 1 {
+		t.Fatalf("Expected the export emailed to accounting with one attachment, got %+v", sent)
+	}
+	attachment := sent.Attachments[0]
+	if !strings.HasSuffix(attachment.Filename, ".csv") || attachment.ContentType != "text/csv" ||
+		!strings.Contains(string(attachment.Content), "txn-scheduled") {
+		t.Errorf("Expected a CSV of the profile's rows, got %s (%s): %s", attachment.Filename, attachment.ContentType, attachment.Content)
+	}
+
+	var saved models.ScheduledExport
+	db.First(&saved, schedule.ID)
+	if saved.LastRunAt == nil || saved.LastRunStatus != models.ExportRunStatusSent || saved.LastRunRows != 1 {
+		t.Errorf("Expected the run to be recorded, got last run %v status %q rows %d", saved.LastRunAt, saved.LastRunStatus, saved.LastRunRows)
+	}
+
+	// Within the day nothing is due, so nothing is sent again
+	if ran, _ := service.RunDue(now.Add(time.Hour)); len(ran) != 0 {
+		t.Errorf("Expected no runs before the next day, got %v", ran)
+	}
+	if len(notifier.Sent) != 1 {
+		t.Errorf("Expected the export to be emailed once, got %d emails", len(notifier.Sent))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -188,6 +189,8 @@ type SearchFilter struct {
 	Status       []string               `json:"status"`
 	Currency     []string               `json:"currency"`
 	BranchID     *uint                  `json:"branchId"`
+	IBAN         string                 `json:"iban"`         // Beneficiary IBAN (remittances only)
+	IBANMatch    string                 `json:"ibanMatch"`    // "exact" (default) or "prefix"
 	CustomFields map[string]interface{} `json:"customFields"` // Additional entity-specific filters
 }
 
@@ -201,6 +204,10 @@ func (s *SearchService) AdvancedSearch(tenantID uint, filter SearchFilter, page,
 	}
 	offset := (page - 1) * limit
 
+	if filter.IBAN != "" && filter.Entity != "remittance" {
+		return nil, 0, errors.New("IBAN search is only supported for remittances")
+	}
+
 	switch filter.Entity {
 	case "transaction":
 		return s.searchTransactions(tenantID, filter, offset, limit)
@@ -305,6 +312,19 @@ func (s *SearchService) searchRemittances(tenantID uint, filter SearchFilter, of
 	if len(filter.Status) > 0 {
 		queryOut = queryOut.Where("status IN ?", filter.Status)
 	}
+	if filter.IBAN != "" {
+		iban, prefix, err := parseIBANFilter(filter.IBAN, filter.IBANMatch)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Stored IBANs may be grouped with spaces or lower-cased
+		column := "UPPER(REPLACE(recipient_iban, ' ', ''))"
+		if prefix {
+			queryOut = queryOut.Where(column+" LIKE ?", iban+"%")
+		} else {
+			queryOut = queryOut.Where(column+" = ?", iban)
+		}
+	}
 
 	var outCount int64
 	queryOut.Count(&outCount)
@@ -320,6 +340,33 @@ func (s *SearchService) searchRemittances(tenantID uint, filter SearchFilter, of
 	return results, total, nil
 }
 
+var (
+	// ibanPattern matches a complete IBAN: country code, check digits and 11-30 account characters
+	ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	// ibanPrefixPattern matches the start of an IBAN, at least the country code and check digits
+	ibanPrefixPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,30}$`)
+)
+
+// parseIBANFilter normalizes an IBAN search term and validates it for the requested match mode
+func parseIBANFilter(value, match string) (string, bool, error) {
+	iban := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+
+	switch strings.ToLower(match) {
+	case "", "exact":
+		if !ibanPattern.MatchString(iban) {
+			return "", false, fmt.Errorf("invalid IBAN: %s", value)
+		}
+		return iban, false, nil
+	case "prefix":
+		if !ibanPrefixPattern.MatchString(iban) {
+			return "", false, fmt.Errorf("invalid IBAN prefix: %s", value)
+		}
+		return iban, true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported IBAN match: %s", match)
+	}
+}
+
 func (s *SearchService) searchPickups(tenantID uint, filter SearchFilter, offset, limit int) ([]interface{}, int64, error) {
 	query := s.DB.Model(&models.PickupTransaction{}).Where("tenant_id = ?", tenantID)
 
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+)
+
+// TestAdvancedSearch_RemittancesByIBAN verifies beneficiary IBAN search by exact and prefix match
+func TestAdvancedSearch_RemittancesByIBAN(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Branch{}, &models.OutgoingRemittance{})
+
+	tenant := newTestTenant(t, db, "IBAN Exchange")
+	other := newTestTenant(t, db, "Other Exchange")
+
+	create := func(tenantID uint, code, iban string) {
+		db.Create(&models.OutgoingRemittance{
+			TenantID:       tenantID,
+			RemittanceCode: code,
+			SenderName:     "Sender",
+			SenderPhone:    "+14165550100",
+			RecipientName:  "Recipient",
+			RecipientIBAN:  testStringPtr(iban),
+			AmountIRR:      models.NewDecimal(100000000),
+			BuyRateCAD:     models.NewDecimal(80000),
+			EquivalentCAD:  models.NewDecimal(1250),
+			ReceivedCAD:    models.NewDecimal(1250),
+			Status:         models.RemittanceStatusPending,
+		})
+	}
+
+	create(tenant.ID, "OUT-IBAN-1", "IR650170000000123456789012")
+	create(tenant.ID, "OUT-IBAN-2", "ir65 0170 0000 0099 9999 9999 99") // stored grouped and lower-cased
+	create(tenant.ID, "OUT-IBAN-3", "IR820540102680020817909002")
+	create(other.ID, "OUT-IBAN-4", "IR650170000000123456789012") // other tenant
+
+	service := NewSearchService(db)
+	codes := func(results []interface{}) map[string]bool {
+		found := make(map[string]bool)
+		for _, result := range results {
+			found[result.(models.OutgoingRemittance).RemittanceCode] = true
+		}
+		return found
+	}
+
+	t.Run("ExactMatch", func(t *testing.T) {
+		results, total, err := service.AdvancedSearch(tenant.ID, SearchFilter{
+			Entity: "remittance",
+			IBAN:   "IR65 0170 0000 0012 3456 7890 12",
+		}, 1, 20)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if total != 1 || !codes(results)["OUT-IBAN-1"] {
+			t.Errorf("Expected only OUT-IBAN-1, got %d results %v", total, codes(results))
+		}
+	})
+
+	t.Run("PrefixMatch", func(t *testing.T) {
+		results, total, err := service.AdvancedSearch(tenant.ID, SearchFilter{
+			Entity:    "remittance",
+			IBAN:      "IR650170",
+			IBANMatch: "prefix",
+		}, 1, 20)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		found := codes(results)
+		if total != 2 || !found["OUT-IBAN-1"] || !found["OUT-IBAN-2"] {
+			t.Errorf("Expected OUT-IBAN-1 and OUT-IBAN-2, got %d results %v", total, found)
+		}
+	})
+
+	t.Run("MalformedIBAN", func(t *testing.T) {
+		for _, filter := range []SearchFilter{
+			{Entity: "remittance", IBAN: "IR65-0170"},
+			{Entity: "remittance", IBAN: "IR650170"}, // too short for an exact match
+			{Entity: "remittance", IBAN: "6501700000", IBANMatch: "prefix"},
+			{Entity: "transaction", IBAN: "IR650170000000123456789012"},
+		} {
+			if _, _, err := service.AdvancedSearch(tenant.ID, filter, 1, 20); err == nil {
+				t.Errorf("Expected validation error for %+v", filter)
+			}
+		}
+	})
+}
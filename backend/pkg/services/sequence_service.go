@@ -0,0 +1,97 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SequenceService issues per-tenant sequence numbers, restarting each key's count according to a reset policy
+type SequenceService struct {
+	db *gorm.DB
+}
+
+// NewSequenceService creates a new SequenceService
+func NewSequenceService(db *gorm.DB) *SequenceService {
+	return &SequenceService{db: db}
+}
+
+// Next issues the next number for the key and returns it with the period it belongs to. When the
+// period under the reset policy differs from the key's last one, numbering restarts at 1.
+func (s *SequenceService) Next(tenantID uint, key, policy string, now time.Time) (int64, string, error) {
+	period := models.SequencePeriod(policy, now)
+
+	var value int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Create a new key's row first so concurrent first uses all lock the same row instead of racing to insert it
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.Sequence{TenantID: tenantID, Key: key, Period: period}).Error; err != nil {
+			return err
+		}
+
+		var sequence models.Sequence
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND sequence_key = ?", tenantID, key).
+			First(&sequence).Error; err != nil {
+			return err
+		}
+
+		if sequence.Period != period {
+			sequence.Period = period
+			sequence.Value = 0
+		}
+		sequence.Value++
+		value = sequence.Value
+		return tx.Model(&sequence).Updates(map[string]interface{}{
+			"period":     sequence.Period,
+			"value":      sequence.Value,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to issue %s number: %w", key, err)
+	}
+	return value, period, nil
+}
+
+// transactionSequenceKey is the sequence key of a branch's transactions; transactions without a branch share one
+func transactionSequenceKey(branchID *uint) string {
+	if branchID == nil {
+		return "TXN:0"
+	}
+	return fmt.Sprintf("TXN:%d", *branchID)
+}
+
+// receiptSequenceKey is the sequence key of a branch's receipts; receipts without a branch share one
+func receiptSequenceKey(branchID *uint) string {
+	if branchID == nil {
+		return "RCT:0"
+	}
+	return fmt.Sprintf("RCT:%d", *branchID)
+}
+
+// FormatReceiptNumber builds a receipt number such as RCT-DT-000042; the branch code is left out when empty
+func FormatReceiptNumber(branchCode string, value int64) string {
+	if branchCode == "" {
+		return fmt.Sprintf("RCT-%06d", value)
+	}
+	return fmt.Sprintf("RCT-%s-%06d", strings.ToUpper(branchCode), value)
+}
+
+// FormatTransactionNumber builds a transaction number such as TXN-DT-20250610-0001; the branch code
+// and period parts are left out when empty
+func FormatTransactionNumber(branchCode, period string, value int64) string {
+	parts := []string{"TXN"}
+	if branchCode != "" {
+		parts = append(parts, strings.ToUpper(branchCode))
+	}
+	if period != "" {
+		parts = append(parts, period)
+	}
+	parts = append(parts, fmt.Sprintf("%04d", value))
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// TestSequenceService_ResetPolicies verifies daily numbering restarts on a new day while "never" keeps counting
+func TestSequenceService_ResetPolicies(t *testing.T) {
+	db := newTestDB(t, &models.Sequence{})
+
+	service := NewSequenceService(db)
+	day1 := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC)
+	nextMonth := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	next := func(key, policy string, now time.Time) (int64, string) {
+		value, period, err := service.Next(1, key, policy, now)
+		if err != nil {
+			t.Fatalf("Next(%s, %s) failed: %v", key, policy, err)
+		}
+		return value, period
+	}
+
+	cases := []struct {
+		name    string
+		key     string
+		policy  string
+		now     time.Time
+		want    int64
+		wantPer string
+	}{
+		{"DailyFirst", "TXN:1", models.SequenceResetDaily, day1, 1, "20250610"},
+		{"DailySameDay", "TXN:1", models.SequenceResetDaily, day1.Add(time.Hour), 2, "20250610"},
+		{"DailyNewDay", "TXN:1", models.SequenceResetDaily, day2, 1, "20250611"},
+		{"NeverFirst", "TXN:2", models.SequenceResetNever, day1, 1, ""},
+		{"NeverSameDay", "TXN:2", models.SequenceResetNever, day1.Add(time.Hour), 2, ""},
+		{"NeverNewDay", "TXN:2", models.SequenceResetNever, day2, 3, ""},
+		{"MonthlyFirst", "TXN:3", models.SequenceResetMonthly, day1, 1, "202506"},
+		{"MonthlyNewDay", "TXN:3", models.SequenceResetMonthly, day2, 2, "202506"},
+		{"MonthlyNewMonth", "TXN:3", models.SequenceResetMonthly, nextMonth, 1, "202507"},
+	}
+	for _, c := range cases {
+		if value, period := next(c.key, c.policy, c.now); value != c.want || period != c.wantPer {
+			t.Errorf("%s: expected %d in period %q, got %d in %q", c.name, c.want, c.wantPer, value, period)
+		}
+	}
+
+	t.Run("KeysAndTenantsCountIndependently", func(t *testing.T) {
+		if value, _, err := service.Next(2, "TXN:1", models.SequenceResetDaily, day2); err != nil || value != 1 {
+			t.Errorf("Expected another tenant's sequence to start at 1, got %d (%v)", value, err)
+		}
+	})
+
+	t.Run("FormatTransactionNumber", func(t *testing.T) {
+		if number := FormatTransactionNumber("dt", "20250610", 1); number != "TXN-DT-20250610-0001" {
+			t.Errorf("Expected TXN-DT-20250610-0001, got %s", number)
+		}
+		if number := FormatTransactionNumber("", "", 42); number != "TXN-0042" {
+			t.Errorf("Expected TXN-0042, got %s", number)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"api/pkg/models"
+	"api/pkg/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatementService builds client statements from the ledger and reconciles them against closed periods
+type StatementService struct {
+	db *gorm.DB
+}
+
+// NewStatementService creates a new StatementService
+func NewStatementService(db *gorm.DB) *StatementService {
+	return &StatementService{db: db}
+}
+
+// ClientStatement is a client's ledger activity in one currency over [PeriodStart, PeriodEnd)
+type ClientStatement struct {
+	ClientID       string               `json:"clientId"`
+	Currency       string               `json:"currency"`
+	PeriodStart    time.Time            `json:"periodStart"`
+	PeriodEnd      time.Time            `json:"periodEnd"`
+	OpeningBalance models.Decimal       `json:"openingBalance"`
+	ClosingBalance models.Decimal       `json:"closingBalance"`
+	Entries        []models.LedgerEntry `json:"entries"`
+
+	// Reconciliation against the most recent closed period ending on or before PeriodStart
+	PriorPeriodID      *uint           `json:"priorPeriodId,omitempty"`
+	ExpectedOpening    *models.Decimal `json:"expectedOpening,omitempty"` // Prior closing plus any activity between the periods
+	OpeningDiscrepancy bool            `json:"openingDiscrepancy"`
+	DiscrepancyAmount  models.Decimal  `json:"discrepancyAmount"` // OpeningBalance - ExpectedOpening
+	DiscrepancyNote    string          `json:"discrepancyNote,omitempty"`
+}
+
+// balanceBefore sums a client's ledger in one currency for entries created before the given time
+func (s *StatementService) balanceBefore(tenantID uint, clientID, currency string, before time.Time) (models.Decimal, error) {
+	var total models.Decimal
+	err := s.db.Model(&models.LedgerEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("tenant_id = ? AND client_id = ? AND currency = ? AND created_at < ?", tenantID, clientID, currency, before).
+		Scan(&total).Error
+	return total, err
+}
+
+// GenerateStatement builds the client's statement and flags an opening balance that does not follow
+// from the last closed period's closing balance
+func (s *StatementService) GenerateStatement(tenantID uint, clientID, currency string, from, to time.Time) (*ClientStatement, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return nil, errors.New("currency is required")
+	}
+	if !to.After(from) {
+		return nil, errors.New("statement end must be after its start")
+	}
+
+	opening, err := s.balanceBefore(tenantID, clientID, currency, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute opening balance: %w", err)
+	}
+
+	var entries []models.LedgerEntry
+	if err := s.db.Where("tenant_id = ? AND client_id = ? AND currency = ? AND created_at >= ? AND created_at < ?",
+		tenantID, clientID, currency, from, to).
+		Order("created_at ASC, id ASC").
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load statement entries: %w", err)
+	}
+
+	closing := opening
+	for _, entry := range entries {
+		closing = closing.Add(entry.Amount)
+	}
+
+	statement := &ClientStatement{
+		ClientID:          clientID,
+		Currency:          currency,
+		PeriodStart:       from,
+		PeriodEnd:         to,
+		OpeningBalance:    opening,
+		ClosingBalance:    closing,
+		Entries:           entries,
+		DiscrepancyAmount: models.Zero(),
+	}
+
+	if err := s.reconcileOpening(tenantID, statement); err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+// reconcileOpening compares the statement's opening balance with the last closed period before it
+func (s *StatementService) reconcileOpening(tenantID uint, statement *ClientStatement) error {
+	var prior models.StatementPeriod
+	err := s.db.Where("tenant_id = ? AND client_id = ? AND currency = ? AND period_end <= ?",
+		tenantID, statement.ClientID, statement.Currency, statement.PeriodStart).
+		Order("period_end DESC").
+		First(&prior).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load closed statement period: %w", err)
+	}
+
+	// Activity between the closed period and this statement carries the prior closing forward
+	var gap models.Decimal
+	if err := s.db.Model(&models.LedgerEntry{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("tenant_id = ? AND client_id = ? AND currency = ? AND created_at >= ? AND created_at < ?",
+			tenantID, statement.ClientID, statement.Currency, prior.PeriodEnd, statement.PeriodStart).
+		Scan(&gap).Error; err != nil {
+		return fmt.Errorf("failed to compute activity since closed period: %w", err)
+	}
+
+	expected := prior.ClosingBalance.Add(gap)
+	statement.PriorPeriodID = &prior.ID
+	statement.ExpectedOpening = &expected
+	statement.DiscrepancyAmount = statement.OpeningBalance.Sub(expected)
+
+	tolerance := models.NewDecimal(utils.GetPaymentTolerance(statement.Currency))
+	if statement.DiscrepancyAmount.Abs().GreaterThan(tolerance) {
+		statement.OpeningDiscrepancy = true
+		statement.DiscrepancyNote = fmt.Sprintf(
+			"opening balance %s %s does not match the closing balance %s %s of the period closed on %s",
+			statement.OpeningBalance.StringFixed(2), statement.Currency,
+			expected.StringFixed(2), statement.Currency,
+			prior.PeriodEnd.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// ClosePeriod generates the statement for the period and stores its closing balance for later reconciliation
+func (s *StatementService) ClosePeriod(tenantID uint, clientID, currency string, from, to time.Time, userID uint) (*models.StatementPeriod, error) {
+	statement, err := s.GenerateStatement(tenantID, clientID, currency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	s.db.Model(&models.StatementPeriod{}).
+		Where("tenant_id = ? AND client_id = ? AND currency = ? AND period_end > ?", tenantID, clientID, statement.Currency, from).
+		Count(&count)
+	if count > 0 {
+		return nil, errors.New("a statement period overlapping or after this range is already closed")
+	}
+
+	period := &models.StatementPeriod{
+		TenantID:       tenantID,
+		ClientID:       clientID,
+		Currency:       statement.Currency,
+		PeriodStart:    from,
+		PeriodEnd:      to,
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+		ClosedBy:       userID,
+	}
+	if err := s.db.Create(period).Error; err != nil {
+		return nil, fmt.Errorf("failed to close statement period: %w", err)
+	}
+	return period, nil
+}
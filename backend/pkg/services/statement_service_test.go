@@ -0,0 +1,77 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// TestGenerateStatement_FlagsOpeningMismatchWithClosedPeriod verifies a back-dated entry in a closed period is flagged on the next statement
+func TestGenerateStatement_FlagsOpeningMismatchWithClosedPeriod(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Client{}, &models.LedgerEntry{}, &models.StatementPeriod{})
+
+	tenant := newTestTenant(t, db, "Statement Exchange")
+	client := &models.Client{
+		ID:          "statement-client",
+		TenantID:    tenant.ID,
+		Name:        "Statement Client",
+		PhoneNumber: "+14165550199",
+	}
+	db.Create(client)
+
+	addEntry := func(amount float64, at time.Time) {
+		db.Create(&models.LedgerEntry{
+			TenantID:  tenant.ID,
+			ClientID:  client.ID,
+			Type:      models.LedgerTypeDeposit,
+			Currency:  "CAD",
+			Amount:    models.NewDecimal(amount),
+			CreatedAt: at,
+		})
+	}
+
+	january := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	addEntry(500, january.AddDate(0, 0, 10))
+	addEntry(250, february.AddDate(0, 0, 5))
+
+	service := NewStatementService(db)
+	period, err := service.ClosePeriod(tenant.ID, client.ID, "CAD", january, february, 1)
+	if err != nil {
+		t.Fatalf("Failed to close January: %v", err)
+	}
+	if period.ClosingBalance.Sub(models.NewDecimal(500)).Abs().GreaterThan(models.NewDecimal(0.001)) {
+		t.Fatalf("Expected January to close at 500, got %s", period.ClosingBalance.String())
+	}
+
+	// Before anything changes, February opens exactly where January closed
+	statement, err := service.GenerateStatement(tenant.ID, client.ID, "CAD", february, march)
+	if err != nil {
+		t.Fatalf("Failed to generate February statement: %v", err)
+	}
+	if statement.OpeningDiscrepancy {
+		t.Fatalf("Expected no discrepancy, got %s", statement.DiscrepancyNote)
+	}
+
+	// A back-dated entry inside the closed period changes February's computed opening
+	addEntry(75, january.AddDate(0, 0, 20))
+
+	statement, err = service.GenerateStatement(tenant.ID, client.ID, "CAD", february, march)
+	if err != nil {
+		t.Fatalf("Failed to regenerate February statement: %v", err)
+	}
+	if !statement.OpeningDiscrepancy {
+		t.Fatal("Expected the opening balance mismatch to be flagged")
+	}
+	if statement.PriorPeriodID == nil || *statement.PriorPeriodID != period.ID {
+		t.Errorf("Expected the discrepancy to reference the closed January period")
+	}
+	if statement.DiscrepancyAmount.Sub(models.NewDecimal(75)).Abs().GreaterThan(models.NewDecimal(0.001)) {
+		t.Errorf("Expected a discrepancy of 75, got %s", statement.DiscrepancyAmount.String())
+	}
+	if statement.ClosingBalance.Sub(models.NewDecimal(825)).Abs().GreaterThan(models.NewDecimal(0.001)) {
+		t.Errorf("Expected February to close at 825, got %s", statement.ClosingBalance.String())
+	}
+}
@@ -30,13 +30,25 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.User{},
 		&models.Branch{},
 		&models.Client{},
+		&models.Customer{},
+		&models.CustomerCompliance{},
+		&models.ComplianceAuditLog{},
 		&models.Transaction{},
 		&models.Payment{},
+		&models.PaymentPlan{},
+		&models.PaymentInstallment{},
 		&models.LedgerEntry{},
 		&models.CashBalance{},
 		&models.CashAdjustment{},
+		&models.DailyClose{},
 		&models.ExchangeRate{},
 		&models.IdempotencyRecord{},
+		&models.TenantSettings{},
+		&models.CurrencyProfitFloor{},
+		&models.TransactionRateAudit{},
+		&models.TransactionTemplate{},
+		&models.StructuringRule{},
+		&models.AuditLog{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TenantSettingsService handles per-tenant configuration
+type TenantSettingsService struct {
+	db *gorm.DB
+}
+
+// NewTenantSettingsService creates a new TenantSettingsService
+func NewTenantSettingsService(db *gorm.DB) *TenantSettingsService {
+	return &TenantSettingsService{db: db}
+}
+
+// UpdateTenantSettingsRequest contains the settings to change; nil fields are left untouched
+type UpdateTenantSettingsRequest struct {
+	PasswordHistoryDepth      *int               `json:"passwordHistoryDepth"`
+	RequireCustomerIDNumber   *bool              `json:"requireCustomerIdNumber"`
+	AutoFillTransactionRate   *bool              `json:"autoFillTransactionRate"`
+	FullKYCThreshold          *float64           `json:"fullKycThreshold"`
+	CompletedTransactionEdits *string            `json:"completedTransactionEdits"`
+	TransactionNumberReset    *string            `json:"transactionNumberReset"`
+	VolumeSpikeMultiple       *float64           `json:"volumeSpikeMultiple"`
+	VolumeBaselineWeeks       *int               `json:"volumeBaselineWeeks"`
+	BaseCurrency              *string            `json:"baseCurrency"`
+	PairMarginFloorPct        *float64           `json:"pairMarginFloorPct"`
+	PairMarginWindowDays      *int               `json:"pairMarginWindowDays"`
+	TicketOnWebhookFailure    *bool              `json:"ticketOnWebhookFailure"`
+	NotifyPickupReady         *bool              `json:"notifyPickupReady"`
+	WriteOffCap               *float64           `json:"writeOffCap"`
+	WriteOffCurrencyCaps      map[string]float64 `json:"writeOffCurrencyCaps"` // Replaces every per-currency cap; {} clears them
+	RoundSettlementResiduals  *bool              `json:"roundSettlementResiduals"`
+	NotifyBranchesOnSettle    *bool              `json:"notifyBranchesOnSettle"`
+	EmailBranchesOnSettle     *bool              `json:"emailBranchesOnSettle"`
+	AutoCreateSenderClients   *bool              `json:"autoCreateSenderClients"`
+	SamePartyRemittances      *string            `json:"samePartyRemittances"`
+	PublicTrackingEnabled     *bool              `json:"publicTrackingEnabled"`
+	HoldNotifyAfterHours      *int               `json:"holdNotifyAfterHours"`
+	HoldAutoReverseAfterHours *int               `json:"holdAutoReverseAfterHours"`
+	TicketAssignmentCap       *int               `json:"ticketAssignmentCap"`
+	TicketSLAEscalateToUserID *uint              `json:"ticketSlaEscalateToUserId"` // 0 clears it
+	ReadAuditEntities         *string            `json:"readAuditEntities"`
+
+	ReconciliationWarnAfterDays   *int `json:"reconciliationWarnAfterDays"`
+	ReconciliationAlertAfterDays  *int `json:"reconciliationAlertAfterDays"`
+	ReconciliationTicketAfterDays *int `json:"reconciliationTicketAfterDays"`
+
+	ReconciliationVarianceTolerance  *float64           `json:"reconciliationVarianceTolerance"`
+	ReconciliationCurrencyTolerances map[string]float64 `json:"reconciliationCurrencyTolerances"` // Replaces every per-currency tolerance; {} clears them
+
+	RateRefreshIntervalMinutes *int     `json:"rateRefreshIntervalMinutes"`
+	RateRefreshBaseCurrency    *string  `json:"rateRefreshBaseCurrency"`
+	RateSnapshotMinutes        *int     `json:"rateSnapshotMinutes"`
+	RateQuoteMarginPct         *float64 `json:"rateQuoteMarginPct"`
+
+	DocumentRequiredThreshold *float64 `json:"documentRequiredThreshold"`
+	DashboardCacheSeconds     *int     `json:"dashboardCacheSeconds"`
+
+	RateSpreadWarnPct  *float64 `json:"rateSpreadWarnPct"`
+	RateSpreadBlockPct *float64 `json:"rateSpreadBlockPct"`
+
+	PartialPaymentTicketAfterDays *int `json:"partialPaymentTicketAfterDays"`
+
+	SettlementApprovalAmountIRR *float64 `json:"settlementApprovalAmountIrr"`
+	SettlementApprovalProfitCAD *float64 `json:"settlementApprovalProfitCad"`
+
+	LargeTransactionWebhookAmount *float64 `json:"largeTransactionWebhookAmount"`
+
+	KYCRenewalReminderDays *int  `json:"kycRenewalReminderDays"`
+	KYCExpiryTickets       *bool `json:"kycExpiryTickets"`
+}
+
+// ErrInvalidTenantSetting is returned when an update gives a setting a value it cannot take
+var ErrInvalidTenantSetting = errors.New("invalid tenant setting")
+
+// TenantSettingError names the setting an update was rejected for
+type TenantSettingError struct {
+	Field  string
+	Reason string
+}
+
+func (e *TenantSettingError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+func (e *TenantSettingError) Unwrap() error {
+	return ErrInvalidTenantSetting
+}
+
+// settingsUpdates collects validated column updates, keeping the first rejected field
+type settingsUpdates struct {
+	updates map[string]interface{}
+	err     error
+}
+
+func (u *settingsUpdates) reject(field, reason string) {
+	if u.err == nil {
+		u.err = &TenantSettingError{Field: field, Reason: reason}
+	}
+}
+
+func (u *settingsUpdates) boolean(column string, value *bool) {
+	if value != nil {
+		u.updates[column] = *value
+	}
+}
+
+func (u *settingsUpdates) intRange(field, column string, value *int, min, max int) {
+	if value == nil {
+		return
+	}
+	if *value < min || *value > max {
+		u.reject(field, fmt.Sprintf("must be between %d and %d", min, max))
+		return
+	}
+	u.updates[column] = *value
+}
+
+func (u *settingsUpdates) nonNegativeInt(field, column string, value *int) {
+	u.intRange(field, column, value, 0, math.MaxInt32)
+}
+
+func (u *settingsUpdates) positiveInt(field, column string, value *int) {
+	u.intRange(field, column, value, 1, math.MaxInt32)
+}
+
+func (u *settingsUpdates) nonNegativeDecimal(field, column string, value *float64) {
+	if value == nil {
+		return
+	}
+	if *value < 0 || math.IsNaN(*value) || math.IsInf(*value, 0) {
+		u.reject(field, "must be zero or greater")
+		return
+	}
+	u.updates[column] = models.NewDecimal(*value)
+}
+
+// currencyAmounts sets a map of currency code to non-negative amount, stored as JSON
+func (u *settingsUpdates) currencyAmounts(field, column string, value map[string]float64) {
+	if value == nil {
+		return
+	}
+	amounts := make(map[string]float64, len(value))
+	for currency, amount := range value {
+		code := strings.ToUpper(strings.TrimSpace(currency))
+		if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			u.reject(field, fmt.Sprintf("%q is not a three-letter currency code", currency))
+			return
+		}
+		if amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			u.reject(field, fmt.Sprintf("%s must be zero or greater", code))
+			return
+		}
+		amounts[code] = amount
+	}
+	encoded, err := json.Marshal(amounts)
+	if err != nil {
+		u.reject(field, err.Error())
+		return
+	}
+	u.updates[column] = string(encoded)
+}
+
+func (u *settingsUpdates) enum(field, column string, value *string, allowed ...string) {
+	if value == nil {
+		return
+	}
+	policy := strings.ToUpper(strings.TrimSpace(*value))
+	for _, option := range allowed {
+		if policy == option {
+			u.updates[column] = policy
+			return
+		}
+	}
+	u.reject(field, "must be one of "+strings.Join(allowed, ", "))
+}
+
+func (u *settingsUpdates) currency(field, column string, value *string) {
+	if value == nil {
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(*value))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		u.reject(field, "must be a three-letter currency code")
+		return
+	}
+	u.updates[column] = code
+}
+
+// toUpdates validates the request and converts it into a column update map. The first invalid value
+// is returned as a *TenantSettingError and nothing is applied.
+func (req UpdateTenantSettingsRequest) toUpdates() (map[string]interface{}, error) {
+	u := &settingsUpdates{updates: make(map[string]interface{})}
+	u.intRange("passwordHistoryDepth", "password_history_depth", req.PasswordHistoryDepth, 0, maxPasswordHistoryDepth)
+	u.boolean("require_customer_id_number", req.RequireCustomerIDNumber)
+	u.boolean("auto_fill_transaction_rate", req.AutoFillTransactionRate)
+	u.nonNegativeDecimal("fullKycThreshold", "full_kyc_threshold", req.FullKYCThreshold)
+	u.nonNegativeDecimal("documentRequiredThreshold", "document_required_threshold", req.DocumentRequiredThreshold)
+	u.nonNegativeDecimal("rateSpreadWarnPct", "rate_spread_warn_pct", req.RateSpreadWarnPct)
+	u.nonNegativeDecimal("rateSpreadBlockPct", "rate_spread_block_pct", req.RateSpreadBlockPct)
+	u.enum("completedTransactionEdits", "completed_transaction_edits", req.CompletedTransactionEdits,
+		models.CompletedTransactionEditsOpen, models.CompletedTransactionEditsAdminOnly, models.CompletedTransactionEditsLocked)
+	u.enum("transactionNumberReset", "transaction_number_reset", req.TransactionNumberReset,
+		models.SequenceResetDaily, models.SequenceResetMonthly, models.SequenceResetNever)
+	u.nonNegativeDecimal("volumeSpikeMultiple", "volume_spike_multiple", req.VolumeSpikeMultiple)
+	u.positiveInt("volumeBaselineWeeks", "volume_baseline_weeks", req.VolumeBaselineWeeks)
+	u.nonNegativeInt("rateRefreshIntervalMinutes", "rate_refresh_interval_minutes", req.RateRefreshIntervalMinutes)
+	u.currency("rateRefreshBaseCurrency", "rate_refresh_base_currency", req.RateRefreshBaseCurrency)
+	u.nonNegativeInt("rateSnapshotMinutes", "rate_snapshot_minutes", req.RateSnapshotMinutes)
+	u.nonNegativeDecimal("rateQuoteMarginPct", "rate_quote_margin_pct", req.RateQuoteMarginPct)
+	u.currency("baseCurrency", "base_currency", req.BaseCurrency)
+	u.nonNegativeDecimal("pairMarginFloorPct", "pair_margin_floor_pct", req.PairMarginFloorPct)
+	u.positiveInt("pairMarginWindowDays", "pair_margin_window_days", req.PairMarginWindowDays)
+	u.boolean("ticket_on_webhook_failure", req.TicketOnWebhookFailure)
+	u.boolean("notify_pickup_ready", req.NotifyPickupReady)
+	u.nonNegativeDecimal("writeOffCap", "write_off_cap", req.WriteOffCap)
+	u.currencyAmounts("writeOffCurrencyCaps", "write_off_currency_caps", req.WriteOffCurrencyCaps)
+	u.nonNegativeInt("kycRenewalReminderDays", "kyc_renewal_reminder_days", req.KYCRenewalReminderDays)
+	u.boolean("kyc_expiry_tickets", req.KYCExpiryTickets)
+	u.nonNegativeDecimal("largeTransactionWebhookAmount", "large_transaction_webhook_amount", req.LargeTransactionWebhookAmount)
+	u.nonNegativeDecimal("settlementApprovalAmountIrr", "settlement_approval_amount_irr", req.SettlementApprovalAmountIRR)
+	u.nonNegativeDecimal("settlementApprovalProfitCad", "settlement_approval_profit_cad", req.SettlementApprovalProfitCAD)
+	u.boolean("round_settlement_residuals", req.RoundSettlementResiduals)
+	u.boolean("notify_branches_on_settle", req.NotifyBranchesOnSettle)
+	u.boolean("email_branches_on_settle", req.EmailBranchesOnSettle)
+	u.boolean("auto_create_sender_clients", req.AutoCreateSenderClients)
+	u.boolean("public_tracking_enabled", req.PublicTrackingEnabled)
+	u.enum("samePartyRemittances", "same_party_remittances", req.SamePartyRemittances,
+		models.SamePartyRemittancesOff, models.SamePartyRemittancesFlag, models.SamePartyRemittancesBlock)
+	u.nonNegativeInt("holdNotifyAfterHours", "hold_notify_after_hours", req.HoldNotifyAfterHours)
+	u.nonNegativeInt("holdAutoReverseAfterHours", "hold_auto_reverse_after_hours", req.HoldAutoReverseAfterHours)
+	u.nonNegativeInt("ticketAssignmentCap", "ticket_assignment_cap", req.TicketAssignmentCap)
+	if req.TicketSLAEscalateToUserID != nil {
+		if *req.TicketSLAEscalateToUserID == 0 {
+			u.updates["ticket_sla_escalate_to_user_id"] = nil
+		} else {
+			u.updates["ticket_sla_escalate_to_user_id"] = *req.TicketSLAEscalateToUserID
+		}
+	}
+	if req.ReadAuditEntities != nil {
+		if entities, ok := normalizeReadAuditEntities(*req.ReadAuditEntities); ok {
+			u.updates["read_audit_entities"] = entities
+		} else {
+			u.reject("readAuditEntities", "must only list "+strings.Join(readAuditableEntities, ", "))
+		}
+	}
+	u.nonNegativeInt("reconciliationWarnAfterDays", "reconciliation_warn_after_days", req.ReconciliationWarnAfterDays)
+	u.nonNegativeInt("reconciliationAlertAfterDays", "reconciliation_alert_after_days", req.ReconciliationAlertAfterDays)
+	u.nonNegativeInt("reconciliationTicketAfterDays", "reconciliation_ticket_after_days", req.ReconciliationTicketAfterDays)
+	u.nonNegativeDecimal("reconciliationVarianceTolerance", "reconciliation_variance_tolerance", req.ReconciliationVarianceTolerance)
+	u.currencyAmounts("reconciliationCurrencyTolerances", "reconciliation_currency_tolerances", req.ReconciliationCurrencyTolerances)
+	u.nonNegativeInt("dashboardCacheSeconds", "dashboard_cache_seconds", req.DashboardCacheSeconds)
+	u.nonNegativeInt("partialPaymentTicketAfterDays", "partial_payment_ticket_after_days", req.PartialPaymentTicketAfterDays)
+	if u.err != nil {
+		return nil, u.err
+	}
+	return u.updates, nil
+}
+
+// GetSettings returns the tenant's settings, falling back to defaults if none are stored
+func (s *TenantSettingsService) GetSettings(tenantID uint) (*models.TenantSettings, error) {
+	var settings models.TenantSettings
+	err := s.db.Where("tenant_id = ?", tenantID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			defaults := models.DefaultTenantSettings(tenantID)
+			return &defaults, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateSettings validates and applies the given changes, creating the settings row on first use
+func (s *TenantSettingsService) UpdateSettings(tenantID uint, req UpdateTenantSettingsRequest) (*models.TenantSettings, error) {
+	updates, err := req.toUpdates()
+	if err != nil {
+		return nil, err
+	}
+	if userID, ok := updates["ticket_sla_escalate_to_user_id"].(uint); ok {
+		var count int64
+		if err := s.db.Model(&models.User{}).Where("id = ? AND tenant_id = ?", userID, tenantID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, &TenantSettingError{Field: "ticketSlaEscalateToUserId", Reason: "must be a user of this tenant"}
+		}
+	}
+
+	var settings models.TenantSettings
+	err = s.db.Where("tenant_id = ?", tenantID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = models.DefaultTenantSettings(tenantID)
+		if err := s.db.Create(&settings).Error; err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&settings).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetSettings(tenantID)
+}
+
+// GetBaseCurrency returns the tenant's reporting currency, falling back to the default when unavailable
+func (s *TenantSettingsService) GetBaseCurrency(tenantID uint) string {
+	settings, err := s.GetSettings(tenantID)
+	if err != nil || settings.BaseCurrency == "" {
+		return models.DefaultTenantSettings(tenantID).BaseCurrency
+	}
+	return settings.BaseCurrency
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"testing"
+)
+
+// TestUpdateSettings_RejectsInvalidValues verifies an invalid value fails the whole update and names the field
+func TestUpdateSettings_RejectsInvalidValues(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{})
+	tenant := newTestTenant(t, db, "Settings Exchange")
+	service := NewTenantSettingsService(db)
+
+	negative := -5.0
+	zeroWeeks := 0
+	depth := maxPasswordHistoryDepth + 1
+	policy := "SOMETIMES"
+	currency := "DOLLARS"
+	entities := "customer,spaceship"
+	enabled := true
+
+	cases := []struct {
+		name  string
+		req   UpdateTenantSettingsRequest
+		field string
+	}{
+		{"NegativeThreshold", UpdateTenantSettingsRequest{RequireCustomerIDNumber: &enabled, FullKYCThreshold: &negative}, "fullKycThreshold"},
+		{"NonPositiveWindow", UpdateTenantSettingsRequest{VolumeBaselineWeeks: &zeroWeeks}, "volumeBaselineWeeks"},
+		{"DepthOutOfRange", UpdateTenantSettingsRequest{PasswordHistoryDepth: &depth}, "passwordHistoryDepth"},
+		{"UnknownEnum", UpdateTenantSettingsRequest{CompletedTransactionEdits: &policy}, "completedTransactionEdits"},
+		{"BadCurrency", UpdateTenantSettingsRequest{BaseCurrency: &currency}, "baseCurrency"},
+		{"UnknownAuditEntity", UpdateTenantSettingsRequest{ReadAuditEntities: &entities}, "readAuditEntities"},
+		{"BadToleranceCurrency", UpdateTenantSettingsRequest{ReconciliationCurrencyTolerances: map[string]float64{"DOLLARS": 1}}, "reconciliationCurrencyTolerances"},
+		{"NegativeTolerance", UpdateTenantSettingsRequest{ReconciliationCurrencyTolerances: map[string]float64{"IRR": -1}}, "reconciliationCurrencyTolerances"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.UpdateSettings(tenant.ID, tc.req)
+			if !errors.Is(err, ErrInvalidTenantSetting) {
+				t.Fatalf("Expected ErrInvalidTenantSetting, got: %v", err)
+			}
+			var settingErr *TenantSettingError
+			if !errors.As(err, &settingErr) || settingErr.Field != tc.field {
+				t.Errorf("Expected the error to name %s, got: %v", tc.field, err)
+			}
+		})
+	}
+
+	// Valid fields sent alongside a rejected one are not applied either
+	settings, err := service.GetSettings(tenant.ID)
+	if err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	defaults := models.DefaultTenantSettings(tenant.ID)
+	if settings.RequireCustomerIDNumber || settings.FullKYCThreshold.Float64() != defaults.FullKYCThreshold.Float64() {
+		t.Errorf("Expected a rejected update to leave settings unchanged, got %+v", settings)
+	}
+
+	lower := "irr"
+	updated, err := service.UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{BaseCurrency: &lower,
+		ReconciliationCurrencyTolerances: map[string]float64{"irr": 500000}})
+	if err != nil {
+		t.Fatalf("Expected a valid update to succeed, got: %v", err)
+	}
+	if updated.BaseCurrency != "IRR" {
+		t.Errorf("Expected base currency IRR, got %s", updated.BaseCurrency)
+	}
+	if updated.ReconciliationCurrencyTolerances["IRR"] != 500000 {
+		t.Errorf("Expected an IRR reconciliation tolerance of 500000, got %v", updated.ReconciliationCurrencyTolerances)
+	}
+}
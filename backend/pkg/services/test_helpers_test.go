@@ -1,11 +1,51 @@
 package services
 
 import (
+	"api/pkg/models"
 	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 // Shared test helper functions for services tests
 
+// newTestDB opens a fresh in-memory SQLite database and migrates the given models. It stops the test at once
+// if either step fails, so a missing table is not mistaken for a bug in the code under test.
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err, "failed to open test database")
+	require.NoError(t, db.AutoMigrate(tables...), "failed to migrate test database")
+	return db
+}
+
+// newTestTenant creates a tenant with the given name
+func newTestTenant(t *testing.T, db *gorm.DB, name string) *models.Tenant {
+	t.Helper()
+	tenant := &models.Tenant{Name: name}
+	require.NoError(t, db.Create(tenant).Error, "failed to create test tenant")
+	return tenant
+}
+
+// newTestUser creates a user of the tenant with the given email and role
+func newTestUser(t *testing.T, db *gorm.DB, tenantID uint, email, role string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, TenantID: &tenantID, Role: role}
+	require.NoError(t, db.Create(user).Error, "failed to create test user")
+	return user
+}
+
+// newTestBranch creates an active branch of the tenant
+func newTestBranch(t *testing.T, db *gorm.DB, tenantID uint, name, code string) *models.Branch {
+	t.Helper()
+	branch := &models.Branch{TenantID: tenantID, Name: name, BranchCode: code, Status: models.BranchStatusActive}
+	require.NoError(t, db.Create(branch).Error, "failed to create test branch")
+	return branch
+}
+
 // testStringPtr returns a pointer to a string (for optional fields)
 func testStringPtr(s string) *string {
 	return &s
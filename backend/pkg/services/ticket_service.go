@@ -4,11 +4,25 @@ import (
 	"api/pkg/models"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// Ticket merge errors
+var (
+	ErrTicketMergeSelf     = errors.New("a ticket cannot be merged into itself")
+	ErrTicketAlreadyMerged = errors.New("ticket has already been merged")
+	ErrTicketMerged        = errors.New("ticket was merged into another ticket and no longer accepts replies")
+)
+
+// Satisfaction rating errors
+var (
+	ErrInvalidSatisfactionScore = errors.New("satisfaction score must be between 1 and 5")
+	ErrTicketNotResolved        = errors.New("only resolved or closed tickets can be rated")
+)
+
 // TicketService handles ticket operations
 type TicketService struct {
 	DB *gorm.DB
@@ -30,6 +44,7 @@ type CreateTicketRequest struct {
 	BranchID          *uint                 `json:"branchId"`
 	RelatedEntityType string                `json:"relatedEntityType"`
 	RelatedEntityID   uint                  `json:"relatedEntityId"`
+	RelatedEntityRef  string                `json:"relatedEntityRef"`
 	Tags              string                `json:"tags"`
 }
 
@@ -63,11 +78,16 @@ func (s *TicketService) CreateTicket(tenantID, createdByUserID uint, req CreateT
 		BranchID:          req.BranchID,
 		RelatedEntityType: req.RelatedEntityType,
 		RelatedEntityID:   req.RelatedEntityID,
+		RelatedEntityRef:  req.RelatedEntityRef,
 		Tags:              req.Tags,
 	}
 
 	// Set SLA due date based on priority
 	ticket.DueAt = s.calculateDueDate(req.Priority)
+	if ticket.AssignedToUserID != nil {
+		now := time.Now()
+		ticket.AssignedAt = &now
+	}
 
 	if err := s.DB.Create(ticket).Error; err != nil {
 		return nil, fmt.Errorf("failed to create ticket: %w", err)
@@ -249,8 +269,10 @@ func (s *TicketService) AssignTicket(tenantID, ticketID, assignToUserID uint, as
 		oldAssignee = fmt.Sprintf("%d", *ticket.AssignedToUserID)
 	}
 
+	now := time.Now()
 	ticket.AssignedToUserID = &assignToUserID
-	ticket.UpdatedAt = time.Now()
+	ticket.AssignedAt = &now
+	ticket.UpdatedAt = now
 
 	// If first assignment, move to In Progress
 	if ticket.Status == models.TicketStatusOpen {
@@ -274,6 +296,9 @@ func (s *TicketService) AddMessage(tenantID, ticketID uint, authorUserID uint, c
 	if err := s.DB.Where("id = ? AND tenant_id = ?", ticketID, tenantID).First(&ticket).Error; err != nil {
 		return nil, err
 	}
+	if ticket.MergedIntoID != nil {
+		return nil, ErrTicketMerged
+	}
 
 	// Get author name
 	var user models.User
@@ -359,6 +384,95 @@ func (s *TicketService) ResolveTicket(tenantID, ticketID uint, resolution string
 	return nil
 }
 
+// MergeTickets folds a duplicate ticket into another: the source's messages, activity and attachments
+// move to the target, and the source is closed, linked to the target and locked against replies
+func (s *TicketService) MergeTickets(tenantID, sourceTicketID, targetTicketID, userID uint) error {
+	if sourceTicketID == targetTicketID {
+		return ErrTicketMergeSelf
+	}
+
+	var source, target models.Ticket
+	if err := s.DB.Where("id = ? AND tenant_id = ?", sourceTicketID, tenantID).First(&source).Error; err != nil {
+		return err
+	}
+	if err := s.DB.Where("id = ? AND tenant_id = ?", targetTicketID, tenantID).First(&target).Error; err != nil {
+		return err
+	}
+	if source.MergedIntoID != nil || target.MergedIntoID != nil {
+		return ErrTicketAlreadyMerged
+	}
+
+	now := time.Now()
+	resolution := "Merged into " + target.TicketCode
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.TicketMessage{}, &models.TicketActivity{}, &models.TicketAttachment{}} {
+			if err := tx.Model(model).Where("ticket_id = ? AND tenant_id = ?", source.ID, tenantID).
+				Update("ticket_id", target.ID).Error; err != nil {
+				return fmt.Errorf("failed to move ticket history: %w", err)
+			}
+		}
+
+		return tx.Model(&source).Updates(map[string]interface{}{
+			"status":              models.TicketStatusClosed,
+			"resolution":          resolution,
+			"resolved_at":         now,
+			"resolved_by_user_id": userID,
+			"closed_at":           now,
+			"merged_into_id":      target.ID,
+			"updated_at":          now,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.DB.Model(&target).Update("updated_at", now)
+
+	s.addSystemMessage(source.ID, tenantID, "merged", resolution)
+	s.logActivity(source.ID, tenantID, "merged", "merged_into_id", "", fmt.Sprintf("%d", target.ID), resolution, &userID, false)
+	s.addSystemMessage(target.ID, tenantID, "merged", "Merged "+source.TicketCode+" into this ticket")
+	s.logActivity(target.ID, tenantID, "merged", "", source.TicketCode, "", "Merged duplicate ticket "+source.TicketCode, &userID, false)
+
+	return nil
+}
+
+// RecordSatisfaction stores the customer's 1-5 satisfaction rating for a resolved or closed ticket.
+// Rating again replaces the earlier score.
+func (s *TicketService) RecordSatisfaction(tenantID, ticketID uint, score int, comment string) error {
+	if score < 1 || score > 5 {
+		return ErrInvalidSatisfactionScore
+	}
+
+	var ticket models.Ticket
+	if err := s.DB.Where("id = ? AND tenant_id = ?", ticketID, tenantID).First(&ticket).Error; err != nil {
+		return err
+	}
+	if ticket.MergedIntoID != nil {
+		return ErrTicketMerged
+	}
+	if ticket.Status != models.TicketStatusResolved && ticket.Status != models.TicketStatusClosed {
+		return ErrTicketNotResolved
+	}
+
+	var oldScore string
+	if ticket.SatisfactionScore != nil {
+		oldScore = fmt.Sprintf("%d", *ticket.SatisfactionScore)
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(&ticket).Updates(map[string]interface{}{
+		"satisfaction_score":    score,
+		"satisfaction_comment":  comment,
+		"satisfaction_rated_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	s.logActivity(ticketID, tenantID, "satisfaction_rated", "satisfaction_score", oldScore, fmt.Sprintf("%d", score),
+		fmt.Sprintf("Customer rated the support %d/5", score), nil, false)
+
+	return nil
+}
+
 // GetTicketStats returns ticket statistics for a tenant
 func (s *TicketService) GetTicketStats(tenantID uint) (*TicketStats, error) {
 	stats := &TicketStats{}
@@ -399,6 +513,18 @@ func (s *TicketService) GetTicketStats(tenantID uint) (*TicketStats, error) {
 		tenantID, []models.TicketStatus{models.TicketStatusClosed, models.TicketStatusResolved}).Count(&unassignedCount)
 	stats.UnassignedCount = int(unassignedCount)
 
+	// Customer satisfaction across rated tickets
+	var csat struct {
+		Average float64
+		Rated   int64
+	}
+	s.DB.Model(&models.Ticket{}).
+		Select("COALESCE(AVG(satisfaction_score), 0) as average, COUNT(satisfaction_score) as rated").
+		Where("tenant_id = ? AND satisfaction_score IS NOT NULL", tenantID).
+		Scan(&csat)
+	stats.AverageSatisfaction = csat.Average
+	stats.RatedCount = int(csat.Rated)
+
 	return stats, nil
 }
 
@@ -413,6 +539,9 @@ type TicketStats struct {
 	HighPriorityCount int `json:"highPriorityCount"`
 	SLABreachCount    int `json:"slaBreachCount"`
 	UnassignedCount   int `json:"unassignedCount"`
+
+	AverageSatisfaction float64 `json:"averageSatisfaction"` // Mean CSAT score (1-5) of rated tickets; 0 when none are rated
+	RatedCount          int     `json:"ratedCount"`
 }
 
 // GetMyTickets returns tickets assigned to a specific user
@@ -523,9 +652,138 @@ func (s *TicketService) calculateDueDate(priority models.TicketPriority) *time.T
 	return &due
 }
 
+// autoAssignTicket hands an unassigned ticket to the next agent in the round-robin and moves it to In Progress.
+// A ticket nobody can take is left open for manual assignment.
 func (s *TicketService) autoAssignTicket(ticket *models.Ticket) {
-	// Simple round-robin or category-based assignment could be implemented here
-	// For now, we leave it unassigned for manual assignment
+	agentID, err := s.nextAgent(ticket)
+	if err != nil {
+		log.Printf("⚠️  Auto-assignment failed for ticket %s: %v", ticket.TicketCode, err)
+		return
+	}
+	if agentID == nil {
+		return
+	}
+
+	now := time.Now()
+	if err := s.DB.Model(ticket).Updates(map[string]interface{}{
+		"assigned_to_user_id": *agentID,
+		"assigned_at":         now,
+		"status":              models.TicketStatusInProgress,
+		"updated_at":          now,
+	}).Error; err != nil {
+		log.Printf("⚠️  Auto-assignment failed for ticket %s: %v", ticket.TicketCode, err)
+		return
+	}
+	ticket.AssignedToUserID = agentID
+	ticket.AssignedAt = &now
+	ticket.Status = models.TicketStatusInProgress
+
+	s.logActivity(ticket.ID, ticket.TenantID, "assigned", "assigned_to_user_id", "", fmt.Sprintf("%d", *agentID),
+		"Ticket auto-assigned", nil, true)
+}
+
+// nextAgent picks who should get the ticket: among active users whose ticket categories include the
+// ticket's, preferring those who work at its branch, the one with the fewest open tickets wins, with
+// ties going to whoever was assigned a ticket longest ago. Agents at the tenant's cap are skipped.
+func (s *TicketService) nextAgent(ticket *models.Ticket) (*uint, error) {
+	var users []models.User
+	if err := s.DB.Where("tenant_id = ? AND status = ? AND ticket_categories <> ''", ticket.TenantID, models.StatusActive).
+		Order("id ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	var agents []models.User
+	for _, user := range users {
+		if user.HandlesTicketCategory(ticket.Category) {
+			agents = append(agents, user)
+		}
+	}
+	if len(agents) == 0 {
+		return nil, nil
+	}
+
+	if ticket.BranchID != nil {
+		var branchUserIDs []uint
+		if err := s.DB.Model(&models.UserBranch{}).Where("branch_id = ?", *ticket.BranchID).Pluck("user_id", &branchUserIDs).Error; err != nil {
+			return nil, err
+		}
+		inBranch := make(map[uint]bool, len(branchUserIDs))
+		for _, id := range branchUserIDs {
+			inBranch[id] = true
+		}
+		var branchAgents []models.User
+		for _, agent := range agents {
+			if inBranch[agent.ID] || (agent.PrimaryBranchID != nil && *agent.PrimaryBranchID == *ticket.BranchID) {
+				branchAgents = append(branchAgents, agent)
+			}
+		}
+		// Fall back to the whole tenant when nobody at the branch handles the category
+		if len(branchAgents) > 0 {
+			agents = branchAgents
+		}
+	}
+
+	settings, err := NewTenantSettingsService(s.DB).GetSettings(ticket.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var loads []struct {
+		AssignedToUserID uint
+		OpenCount        int
+	}
+	if err := s.DB.Model(&models.Ticket{}).
+		Select("assigned_to_user_id, COUNT(*) as open_count").
+		Where("tenant_id = ? AND assigned_to_user_id IS NOT NULL AND status IN ?", ticket.TenantID,
+			[]models.TicketStatus{models.TicketStatusOpen, models.TicketStatusInProgress, models.TicketStatusWaiting}).
+		Group("assigned_to_user_id").
+		Scan(&loads).Error; err != nil {
+		return nil, err
+	}
+	openCounts := make(map[uint]int, len(loads))
+	for _, load := range loads {
+		openCounts[load.AssignedToUserID] = load.OpenCount
+	}
+
+	var chosen *uint
+	var chosenOpen int
+	var chosenLast *time.Time
+	for i := range agents {
+		agent := &agents[i]
+		open := openCounts[agent.ID]
+		if settings.TicketAssignmentCap > 0 && open >= settings.TicketAssignmentCap {
+			continue
+		}
+		last, err := s.lastAssignedAt(agent.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		better := chosen == nil || open < chosenOpen
+		if !better && open == chosenOpen {
+			// Never assigned beats assigned; otherwise the longest wait wins
+			better = chosenLast != nil && (last == nil || last.Before(*chosenLast))
+		}
+		if better {
+			chosen, chosenOpen, chosenLast = &agent.ID, open, last
+		}
+	}
+	return chosen, nil
+}
+
+// lastAssignedAt returns when the user was last given a ticket, or nil if never
+func (s *TicketService) lastAssignedAt(userID uint) (*time.Time, error) {
+	var latest models.Ticket
+	err := s.DB.Select("assigned_at").
+		Where("assigned_to_user_id = ? AND assigned_at IS NOT NULL", userID).
+		Order("assigned_at DESC").
+		First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return latest.AssignedAt, nil
 }
 
 func (s *TicketService) logActivity(ticketID, tenantID uint, action, field, oldValue, newValue, description string, userID *uint, isSystem bool) {
@@ -569,6 +827,9 @@ func (s *TicketService) CreateQuickTicket(tenantID, userID uint, entityType stri
 	case "pickup":
 		subject = fmt.Sprintf("Issue with Pickup #%d", entityID)
 		category = models.TicketCategoryTransaction
+	case "compliance":
+		subject = fmt.Sprintf("Issue with Compliance Record #%d", entityID)
+		category = models.TicketCategoryCompliance
 	default:
 		return nil, errors.New("invalid entity type")
 	}
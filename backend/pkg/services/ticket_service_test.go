@@ -30,8 +30,7 @@ func TestTicketWorkflow(t *testing.T) {
 	)
 
 	// Create test data
-	tenant := &models.Tenant{Name: "Test Exchange Bureau"}
-	db.Create(tenant)
+	tenant := newTestTenant(t, db, "Test Exchange Bureau")
 
 	user := &models.User{
 		Email:    "agent@example.com",
@@ -338,3 +337,232 @@ func TestTicketWorkflow(t *testing.T) {
 	fmt.Println("\n✅ ALL TICKET TESTS PASSED!")
 	fmt.Println("=====================================")
 }
+
+// TestAutoAssignTicket verifies round-robin assignment spreads tickets across eligible agents
+// and skips agents at the tenant's open ticket cap
+func TestAutoAssignTicket(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.Branch{}, &models.UserBranch{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "Round Robin Exchange")
+
+	newUser := func(email, categories, status string) *models.User {
+		user := &models.User{Email: email, TenantID: &tenant.ID, Role: models.RoleTenantUser, Status: status, TicketCategories: categories}
+		db.Create(user)
+		return user
+	}
+	creator := newUser("creator@rr.test", "", models.StatusActive)
+	alice := newUser("alice@rr.test", "GENERAL,TRANSACTION", models.StatusActive)
+	bob := newUser("bob@rr.test", models.TicketCategoriesAll, models.StatusActive)
+	newUser("billing@rr.test", "BILLING", models.StatusActive)
+	newUser("suspended@rr.test", models.TicketCategoriesAll, models.StatusSuspended)
+
+	service := NewTicketService(db)
+	create := func(subject string) *models.Ticket {
+		ticket, err := service.CreateTicket(tenant.ID, creator.ID, CreateTicketRequest{
+			Subject:     subject,
+			Description: "Customer needs help",
+			Category:    models.TicketCategoryGeneral,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create ticket: %v", err)
+		}
+		return ticket
+	}
+
+	first := create("First")
+	second := create("Second")
+	if first.AssignedToUserID == nil || second.AssignedToUserID == nil {
+		t.Fatal("Expected both tickets to be auto-assigned")
+	}
+	if *first.AssignedToUserID == *second.AssignedToUserID {
+		t.Errorf("Expected sequential tickets to go to different agents, both went to %d", *first.AssignedToUserID)
+	}
+	for _, ticket := range []*models.Ticket{first, second} {
+		if id := *ticket.AssignedToUserID; id != alice.ID && id != bob.ID {
+			t.Errorf("Expected ticket to go to an eligible agent, got user %d", id)
+		}
+		var stored models.Ticket
+		db.First(&stored, ticket.ID)
+		if stored.Status != models.TicketStatusInProgress || stored.AssignedAt == nil {
+			t.Errorf("Expected auto-assigned ticket to be in progress with an assignment time, got %s", stored.Status)
+		}
+	}
+
+	var activities int64
+	db.Model(&models.TicketActivity{}).Where("ticket_id = ? AND action = ? AND is_system_action = ?", first.ID, "assigned", true).Count(&activities)
+	if activities != 1 {
+		t.Errorf("Expected one auto-assignment activity, got %d", activities)
+	}
+
+	t.Run("SkipsAgentsAtCap", func(t *testing.T) {
+		ticketCap := 1
+		if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{TicketAssignmentCap: &ticketCap}); err != nil {
+			t.Fatalf("Failed to set assignment cap: %v", err)
+		}
+
+		// Both agents hold one open ticket, so nobody can take another
+		if third := create("Third"); third.AssignedToUserID != nil || third.Status != models.TicketStatusOpen {
+			t.Errorf("Expected ticket to stay unassigned with every agent at the cap, got %v", third.AssignedToUserID)
+		}
+
+		// Once Bob's ticket is resolved he is under the cap again, while Alice is still skipped
+		bobsTicket := first
+		if *second.AssignedToUserID == bob.ID {
+			bobsTicket = second
+		}
+		if err := service.ResolveTicket(tenant.ID, bobsTicket.ID, "Done", bob.ID); err != nil {
+			t.Fatalf("Failed to resolve ticket: %v", err)
+		}
+		if fourth := create("Fourth"); fourth.AssignedToUserID == nil || *fourth.AssignedToUserID != bob.ID {
+			t.Errorf("Expected ticket to skip the capped agent and go to Bob, got %v", fourth.AssignedToUserID)
+		}
+	})
+}
+
+// TestMergeTickets verifies a duplicate's messages move to the target and the duplicate is closed and locked
+func TestMergeTickets(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.Branch{}, &models.UserBranch{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketAttachment{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "Merge Exchange")
+	agent := newTestUser(t, db, tenant.ID, "agent@merge.test", models.RoleTenantUser)
+
+	service := NewTicketService(db)
+	create := func(subject string) *models.Ticket {
+		ticket, err := service.CreateTicket(tenant.ID, agent.ID, CreateTicketRequest{Subject: subject, Description: "Transfer not received"})
+		if err != nil {
+			t.Fatalf("Failed to create ticket: %v", err)
+		}
+		return ticket
+	}
+	target := create("Transfer missing")
+	source := create("Transfer missing (again)")
+	third := create("Unrelated")
+
+	for _, content := range []string{"Customer called back", "Sent bank reference"} {
+		if _, err := service.AddMessage(tenant.ID, source.ID, agent.ID, content, false); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+
+	if err := service.MergeTickets(tenant.ID, source.ID, source.ID, agent.ID); err != ErrTicketMergeSelf {
+		t.Errorf("Expected ErrTicketMergeSelf, got %v", err)
+	}
+
+	if err := service.MergeTickets(tenant.ID, source.ID, target.ID, agent.ID); err != nil {
+		t.Fatalf("MergeTickets failed: %v", err)
+	}
+
+	var moved int64
+	db.Model(&models.TicketMessage{}).Where("ticket_id = ? AND is_system_message = ?", target.ID, false).Count(&moved)
+	if moved != 2 {
+		t.Errorf("Expected 2 messages on the target, got %d", moved)
+	}
+	var left int64
+	db.Model(&models.TicketMessage{}).Where("ticket_id = ? AND is_system_message = ?", source.ID, false).Count(&left)
+	if left != 0 {
+		t.Errorf("Expected no customer messages left on the source, got %d", left)
+	}
+	var createdActivity int64
+	db.Model(&models.TicketActivity{}).Where("ticket_id = ? AND action = ?", target.ID, "created").Count(&createdActivity)
+	if createdActivity != 2 {
+		t.Errorf("Expected the source's activity to move to the target, got %d created entries", createdActivity)
+	}
+
+	var merged models.Ticket
+	db.First(&merged, source.ID)
+	if merged.Status != models.TicketStatusClosed || merged.ClosedAt == nil {
+		t.Errorf("Expected merged ticket to be closed, got %s", merged.Status)
+	}
+	if merged.MergedIntoID == nil || *merged.MergedIntoID != target.ID {
+		t.Errorf("Expected merged ticket to link to %d, got %v", target.ID, merged.MergedIntoID)
+	}
+	if merged.Resolution != "Merged into "+target.TicketCode {
+		t.Errorf("Unexpected resolution %q", merged.Resolution)
+	}
+
+	if _, err := service.AddMessage(tenant.ID, source.ID, agent.ID, "Any update?", false); err != ErrTicketMerged {
+		t.Errorf("Expected replies on a merged ticket to fail with ErrTicketMerged, got %v", err)
+	}
+	if err := service.MergeTickets(tenant.ID, source.ID, third.ID, agent.ID); err != ErrTicketAlreadyMerged {
+		t.Errorf("Expected merging an already merged ticket to fail, got %v", err)
+	}
+	if err := service.MergeTickets(tenant.ID, third.ID, source.ID, agent.ID); err != ErrTicketAlreadyMerged {
+		t.Errorf("Expected merging into a merged ticket to fail, got %v", err)
+	}
+}
+
+// TestRecordSatisfaction verifies CSAT ratings are only taken on resolved tickets, within 1-5, and feed the stats
+func TestRecordSatisfaction(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.Branch{}, &models.UserBranch{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "CSAT Exchange")
+	agent := newTestUser(t, db, tenant.ID, "agent@csat.test", models.RoleTenantUser)
+
+	service := NewTicketService(db)
+	create := func(subject string) *models.Ticket {
+		ticket, err := service.CreateTicket(tenant.ID, agent.ID, CreateTicketRequest{Subject: subject, Description: "Rate change question"})
+		if err != nil {
+			t.Fatalf("Failed to create ticket: %v", err)
+		}
+		return ticket
+	}
+	resolved := create("Resolved")
+	closed := create("Closed")
+	open := create("Still open")
+	if err := service.ResolveTicket(tenant.ID, resolved.ID, "Explained the new rate", agent.ID); err != nil {
+		t.Fatalf("Failed to resolve ticket: %v", err)
+	}
+	if err := service.UpdateTicketStatus(tenant.ID, closed.ID, models.TicketStatusClosed, agent.ID); err != nil {
+		t.Fatalf("Failed to close ticket: %v", err)
+	}
+
+	t.Run("ValidRating", func(t *testing.T) {
+		if err := service.RecordSatisfaction(tenant.ID, resolved.ID, 5, "Quick and helpful"); err != nil {
+			t.Fatalf("RecordSatisfaction failed: %v", err)
+		}
+		if err := service.RecordSatisfaction(tenant.ID, closed.ID, 2, ""); err != nil {
+			t.Fatalf("RecordSatisfaction on a closed ticket failed: %v", err)
+		}
+
+		var stored models.Ticket
+		db.First(&stored, resolved.ID)
+		if stored.SatisfactionScore == nil || *stored.SatisfactionScore != 5 || stored.SatisfactionComment != "Quick and helpful" {
+			t.Errorf("Expected a stored 5/5 rating with comment, got %v %q", stored.SatisfactionScore, stored.SatisfactionComment)
+		}
+		if stored.SatisfactionRatedAt == nil {
+			t.Error("Expected the rating time to be recorded")
+		}
+	})
+
+	t.Run("OutOfRangeRejected", func(t *testing.T) {
+		for _, score := range []int{0, 6} {
+			if err := service.RecordSatisfaction(tenant.ID, resolved.ID, score, ""); err != ErrInvalidSatisfactionScore {
+				t.Errorf("Expected score %d to be rejected, got %v", score, err)
+			}
+		}
+	})
+
+	t.Run("OpenTicketRefused", func(t *testing.T) {
+		if err := service.RecordSatisfaction(tenant.ID, open.ID, 4, ""); err != ErrTicketNotResolved {
+			t.Errorf("Expected rating an open ticket to be refused, got %v", err)
+		}
+		var stored models.Ticket
+		db.First(&stored, open.ID)
+		if stored.SatisfactionScore != nil {
+			t.Errorf("Expected no rating on the open ticket, got %d", *stored.SatisfactionScore)
+		}
+	})
+
+	t.Run("StatsAverage", func(t *testing.T) {
+		stats, err := service.GetTicketStats(tenant.ID)
+		if err != nil {
+			t.Fatalf("GetTicketStats failed: %v", err)
+		}
+		if stats.RatedCount != 2 || stats.AverageSatisfaction != 3.5 {
+			t.Errorf("Expected an average of 3.5 over 2 ratings, got %v over %d", stats.AverageSatisfaction, stats.RatedCount)
+		}
+	})
+}
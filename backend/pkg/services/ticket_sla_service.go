@@ -0,0 +1,186 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultTicketSLAScanInterval is used when TICKET_SLA_SCAN_INTERVAL is unset or invalid
+const defaultTicketSLAScanInterval = 5 * time.Minute
+
+// TicketSLAService flags open tickets that passed their due time without a first response
+type TicketSLAService struct {
+	db       *gorm.DB
+	tickets  *TicketService
+	settings *TenantSettingsService
+
+	// BumpPriority raises a breached ticket's priority by one level
+	BumpPriority bool
+}
+
+// NewTicketSLAService creates a new TicketSLAService. Priority bumps are configured with
+// TICKET_SLA_BUMP_PRIORITY=true; each tenant names its own escalation user in its settings.
+func NewTicketSLAService(db *gorm.DB) *TicketSLAService {
+	return &TicketSLAService{
+		db:           db,
+		tickets:      NewTicketService(db),
+		settings:     NewTenantSettingsService(db),
+		BumpPriority: getEnv("TICKET_SLA_BUMP_PRIORITY", "false") == "true",
+	}
+}
+
+// TicketSLAScanInterval returns how often the SLA scan runs, from TICKET_SLA_SCAN_INTERVAL (e.g. "10m")
+func TicketSLAScanInterval() time.Duration {
+	interval, err := time.ParseDuration(getEnv("TICKET_SLA_SCAN_INTERVAL", defaultTicketSLAScanInterval.String()))
+	if err != nil || interval <= 0 {
+		return defaultTicketSLAScanInterval
+	}
+	return interval
+}
+
+// escalatedPriority returns the next priority level up; critical stays critical
+func escalatedPriority(priority models.TicketPriority) models.TicketPriority {
+	switch priority {
+	case models.TicketPriorityLow:
+		return models.TicketPriorityMedium
+	case models.TicketPriorityMedium:
+		return models.TicketPriorityHigh
+	default:
+		return models.TicketPriorityCritical
+	}
+}
+
+// CheckAll flags every open or in-progress ticket that is past due without a first response
+// and returns the IDs of the tickets it flagged
+func (s *TicketSLAService) CheckAll(now time.Time) ([]uint, error) {
+	var overdue []models.Ticket
+	if err := s.db.Where("status IN ? AND breached_sla = ? AND first_response_at IS NULL AND due_at < ?",
+		[]models.TicketStatus{models.TicketStatusOpen, models.TicketStatusInProgress}, false, now).
+		Find(&overdue).Error; err != nil {
+		return nil, err
+	}
+
+	var flagged []uint
+	for i := range overdue {
+		if err := s.escalate(&overdue[i], now); err != nil {
+			log.Printf("⚠️  SLA escalation failed for ticket %s: %v", overdue[i].TicketCode, err)
+			continue
+		}
+		flagged = append(flagged, overdue[i].ID)
+	}
+	return flagged, nil
+}
+
+// escalationUser returns the tenant's SLA escalation user, or nil when none is set or the user
+// no longer belongs to the tenant
+func (s *TicketSLAService) escalationUser(tenantID uint) (*uint, error) {
+	settings, err := s.settings.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	userID := settings.TicketSLAEscalateToUserID
+	if userID == nil {
+		return nil, nil
+	}
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ? AND tenant_id = ?", *userID, tenantID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		log.Printf("⚠️  Ignoring SLA escalation user %d: not a user of tenant %d", *userID, tenantID)
+		return nil, nil
+	}
+	return userID, nil
+}
+
+// escalate marks a ticket as breached and applies the tenant's escalation
+func (s *TicketSLAService) escalate(ticket *models.Ticket, now time.Time) error {
+	escalateTo, err := s.escalationUser(ticket.TenantID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"breached_sla": true,
+		"updated_at":   now,
+	}
+	oldPriority := ticket.Priority
+	newPriority := oldPriority
+	if s.BumpPriority {
+		newPriority = escalatedPriority(oldPriority)
+		updates["priority"] = newPriority
+	}
+	reassign := escalateTo != nil &&
+		(ticket.AssignedToUserID == nil || *ticket.AssignedToUserID != *escalateTo)
+	if reassign {
+		updates["assigned_to_user_id"] = *escalateTo
+		updates["assigned_at"] = now
+	}
+
+	// Guard on breached_sla so a concurrent scan cannot escalate the same ticket twice
+	result := s.db.Model(&models.Ticket{}).
+		Where("id = ? AND breached_sla = ?", ticket.ID, false).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("ticket already flagged")
+	}
+
+	s.tickets.logActivity(ticket.ID, ticket.TenantID, "sla_breached", "breached_sla", "false", "true",
+		fmt.Sprintf("SLA breached: no first response by %s", ticket.DueAt.Format(time.RFC3339)), nil, true)
+
+	if newPriority != oldPriority {
+		s.tickets.logActivity(ticket.ID, ticket.TenantID, "priority_changed", "priority", string(oldPriority), string(newPriority),
+			fmt.Sprintf("Priority escalated from %s to %s after SLA breach", oldPriority, newPriority), nil, true)
+	}
+	if reassign {
+		oldAssignee := ""
+		if ticket.AssignedToUserID != nil {
+			oldAssignee = fmt.Sprintf("%d", *ticket.AssignedToUserID)
+		}
+		s.tickets.logActivity(ticket.ID, ticket.TenantID, "assigned", "assigned_to_user_id", oldAssignee, fmt.Sprintf("%d", *escalateTo),
+			"Reassigned after SLA breach", nil, true)
+	}
+
+	GetEventBus().Publish(DomainEvent{
+		Name:     EventTicketSLABreached,
+		TenantID: ticket.TenantID,
+		Data: map[string]interface{}{
+			"ticketId":   ticket.ID,
+			"ticketCode": ticket.TicketCode,
+			"tenantId":   ticket.TenantID,
+			"subject":    ticket.Subject,
+			"priority":   newPriority,
+			"dueAt":      ticket.DueAt,
+		},
+		OccurredAt: now,
+	})
+	return nil
+}
+
+// Start runs the SLA scan on the given interval in the background
+func (s *TicketSLAService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Ticket SLA scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			flagged, err := s.CheckAll(time.Now())
+			if err != nil {
+				log.Printf("⚠️  Ticket SLA check failed: %v", err)
+				continue
+			}
+			if len(flagged) > 0 {
+				log.Printf("🚨 Flagged %d ticket(s) as SLA breached", len(flagged))
+			}
+		}
+	}()
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"api/pkg/models"
+	"testing"
+	"time"
+)
+
+// TestTicketSLAService_FlagsOverdueTickets verifies only unanswered, past-due open tickets are flagged
+func TestTicketSLAService_FlagsOverdueTickets(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.Branch{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "SLA Exchange")
+	lead := newTestUser(t, db, tenant.ID, "lead@sla.test", "tenant_admin")
+
+	now := time.Now()
+	create := func(code string, status models.TicketStatus, dueIn time.Duration, responded bool) *models.Ticket {
+		due := now.Add(dueIn)
+		ticket := &models.Ticket{
+			TenantID:    tenant.ID,
+			TicketCode:  code,
+			Subject:     "Missing transfer",
+			Description: "Customer is waiting on a transfer",
+			Status:      status,
+			Priority:    models.TicketPriorityMedium,
+			Category:    models.TicketCategoryGeneral,
+			DueAt:       &due,
+		}
+		if responded {
+			respondedAt := now.Add(-time.Hour)
+			ticket.FirstResponseAt = &respondedAt
+		}
+		db.Create(ticket)
+		return ticket
+	}
+
+	overdue := create("TKT-SLA-1", models.TicketStatusOpen, -2*time.Hour, false)
+	responded := create("TKT-SLA-2", models.TicketStatusInProgress, -2*time.Hour, true)
+	notDue := create("TKT-SLA-3", models.TicketStatusOpen, 2*time.Hour, false)
+	resolved := create("TKT-SLA-4", models.TicketStatusResolved, -2*time.Hour, false)
+
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{TicketSLAEscalateToUserID: &lead.ID}); err != nil {
+		t.Fatalf("Failed to set the escalation user: %v", err)
+	}
+	service := NewTicketSLAService(db)
+	service.BumpPriority = true
+
+	flagged, err := service.CheckAll(now)
+	if err != nil {
+		t.Fatalf("SLA check failed: %v", err)
+	}
+	if len(flagged) != 1 || flagged[0] != overdue.ID {
+		t.Fatalf("Expected only the overdue ticket to be flagged, got %v", flagged)
+	}
+
+	var reloaded models.Ticket
+	db.First(&reloaded, overdue.ID)
+	if !reloaded.BreachedSLA {
+		t.Error("Expected overdue ticket to be marked as breached")
+	}
+	if reloaded.Priority != models.TicketPriorityHigh {
+		t.Errorf("Expected priority to be bumped to HIGH, got %s", reloaded.Priority)
+	}
+	if reloaded.AssignedToUserID == nil || *reloaded.AssignedToUserID != lead.ID {
+		t.Errorf("Expected ticket to be reassigned to the support lead")
+	}
+
+	var activities int64
+	db.Model(&models.TicketActivity{}).Where("ticket_id = ? AND action = ? AND is_system_action = ?", overdue.ID, "sla_breached", true).Count(&activities)
+	if activities != 1 {
+		t.Errorf("Expected one sla_breached activity, got %d", activities)
+	}
+
+	for _, ticket := range []*models.Ticket{responded, notDue, resolved} {
+		reloaded = models.Ticket{}
+		db.First(&reloaded, ticket.ID)
+		if reloaded.BreachedSLA {
+			t.Errorf("Expected ticket %s not to be flagged", ticket.TicketCode)
+		}
+	}
+
+	t.Run("FlagsOnlyOnce", func(t *testing.T) {
+		again, err := service.CheckAll(now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("SLA check failed: %v", err)
+		}
+		if len(again) != 0 {
+			t.Errorf("Expected no tickets to be flagged again, got %v", again)
+		}
+	})
+}
+
+// TestTicketSLAService_EscalatesWithinTenant verifies each tenant's breached tickets go to its own escalation
+// user and never to a user of another tenant
+func TestTicketSLAService_EscalatesWithinTenant(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Customer{}, &models.Branch{},
+		&models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{}, &models.TenantSettings{})
+
+	tenant := newTestTenant(t, db, "SLA Exchange")
+	other := newTestTenant(t, db, "Other Exchange")
+	otherLead := newTestUser(t, db, other.ID, "lead@other.test", "tenant_admin")
+	settings := NewTenantSettingsService(db)
+
+	if _, err := settings.UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{TicketSLAEscalateToUserID: &otherLead.ID}); err == nil {
+		t.Fatal("Expected another tenant's user to be rejected as the escalation user")
+	}
+	if _, err := settings.UpdateSettings(other.ID, UpdateTenantSettingsRequest{TicketSLAEscalateToUserID: &otherLead.ID}); err != nil {
+		t.Fatalf("Failed to set the escalation user: %v", err)
+	}
+
+	due := time.Now().Add(-2 * time.Hour)
+	ticket := &models.Ticket{
+		TenantID:    tenant.ID,
+		TicketCode:  "TKT-SLA-X",
+		Subject:     "Missing transfer",
+		Description: "Customer is waiting on a transfer",
+		Status:      models.TicketStatusOpen,
+		Priority:    models.TicketPriorityMedium,
+		Category:    models.TicketCategoryGeneral,
+		DueAt:       &due,
+	}
+	db.Create(ticket)
+
+	if _, err := NewTicketSLAService(db).CheckAll(time.Now()); err != nil {
+		t.Fatalf("SLA check failed: %v", err)
+	}
+	var reloaded models.Ticket
+	db.First(&reloaded, ticket.ID)
+	if !reloaded.BreachedSLA {
+		t.Error("Expected the ticket to be marked as breached")
+	}
+	if reloaded.AssignedToUserID != nil {
+		t.Errorf("Expected the ticket to stay unassigned, got user %d", *reloaded.AssignedToUserID)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSupportingDocumentRequired is returned when a large transaction is completed without an attachment
+	ErrSupportingDocumentRequired = errors.New("a supporting document must be attached to complete this transaction")
+	// ErrRequiredDocumentProtected is returned when someone other than an owner or admin removes the only
+	// supporting document of a completed transaction that needed one
+	ErrRequiredDocumentProtected = errors.New("only an owner or admin can remove the only supporting document of a completed transaction")
+)
+
+// TransactionAttachmentService records the supporting documents filed against transactions
+type TransactionAttachmentService struct {
+	db *gorm.DB
+}
+
+// NewTransactionAttachmentService creates a new TransactionAttachmentService
+func NewTransactionAttachmentService(db *gorm.DB) *TransactionAttachmentService {
+	return &TransactionAttachmentService{db: db}
+}
+
+// AddAttachment records a stored file against one of the tenant's transactions
+func (s *TransactionAttachmentService) AddAttachment(tenantID uint, transactionID string, attachment *models.TransactionAttachment, userID uint) error {
+	var count int64
+	if err := s.db.Model(&models.Transaction{}).
+		Where("id = ? AND tenant_id = ?", transactionID, tenantID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to load transaction: %w", err)
+	}
+	if count == 0 {
+		return errors.New("transaction not found")
+	}
+
+	attachment.TenantID = tenantID
+	attachment.TransactionID = transactionID
+	attachment.UploadedBy = userID
+	attachment.Description = strings.TrimSpace(attachment.Description)
+	if err := s.db.Create(attachment).Error; err != nil {
+		return fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return nil
+}
+
+// GetAttachments lists a transaction's attachments, oldest first
+func (s *TransactionAttachmentService) GetAttachments(tenantID uint, transactionID string) ([]models.TransactionAttachment, error) {
+	var attachments []models.TransactionAttachment
+	if err := s.db.Where("tenant_id = ? AND transaction_id = ?", tenantID, transactionID).
+		Order("created_at ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetAttachment returns one of a transaction's attachments
+func (s *TransactionAttachmentService) GetAttachment(tenantID uint, transactionID string, attachmentID uint) (*models.TransactionAttachment, error) {
+	var attachment models.TransactionAttachment
+	if err := s.db.Where("id = ? AND tenant_id = ? AND transaction_id = ?", attachmentID, tenantID, transactionID).
+		First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment removes an attachment record and returns it so the caller can delete the stored file.
+// The only document of a completed transaction at or above the tenant's DocumentRequiredThreshold may be
+// removed by an owner or admin alone, and that removal is written to the audit log.
+func (s *TransactionAttachmentService) DeleteAttachment(tenantID uint, transactionID string, attachmentID uint, user *models.User) (*models.TransactionAttachment, error) {
+	var attachment models.TransactionAttachment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND tenant_id = ? AND transaction_id = ?", attachmentID, tenantID, transactionID).
+			First(&attachment).Error; err != nil {
+			return err
+		}
+
+		required, err := s.isRequiredDocument(tx, tenantID, transactionID)
+		if err != nil {
+			return err
+		}
+		if required {
+			switch user.Role {
+			case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+			default:
+				return ErrRequiredDocumentProtected
+			}
+		}
+
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return fmt.Errorf("failed to delete attachment: %w", err)
+		}
+		if !required {
+			return nil
+		}
+		return NewAuditService(tx).LogAction(user.ID, &tenantID, models.ActionDeleteRequiredDocument, "Transaction", transactionID,
+			fmt.Sprintf("Removed the only supporting document (%s) of a completed transaction", attachment.FileName),
+			attachment, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// isRequiredDocument reports whether the transaction is completed, at or above the tenant's document
+// threshold and down to its last attachment, so removing it would leave it without the document it needed
+func (s *TransactionAttachmentService) isRequiredDocument(tx *gorm.DB, tenantID uint, transactionID string) (bool, error) {
+	var count int64
+	if err := tx.Model(&models.TransactionAttachment{}).
+		Where("tenant_id = ? AND transaction_id = ?", tenantID, transactionID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 1 {
+		return false, nil
+	}
+
+	var transaction models.Transaction
+	if err := tx.Where("id = ? AND tenant_id = ?", transactionID, tenantID).First(&transaction).Error; err != nil {
+		return false, err
+	}
+	if !transaction.IsCompleted() {
+		return false, nil
+	}
+
+	settings, err := NewTenantSettingsService(tx).GetSettings(tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load tenant settings: %w", err)
+	}
+	if !settings.DocumentRequiredThreshold.IsPositive() {
+		return false, nil
+	}
+	// Completion doesn't ask for a document when the transaction can't be valued, so neither does this
+	value, err := completionValue(tx, &transaction, settings.BaseCurrency)
+	if err != nil {
+		return false, nil
+	}
+	return !value.LessThan(settings.DocumentRequiredThreshold), nil
+}
+
+// HasAttachment reports whether the transaction has at least one supporting document
+func (s *TransactionAttachmentService) HasAttachment(tenantID uint, transactionID string) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.TransactionAttachment{}).
+		Where("tenant_id = ? AND transaction_id = ?", tenantID, transactionID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
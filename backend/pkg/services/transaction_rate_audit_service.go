@@ -0,0 +1,75 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransactionRateAuditService records and serves the rate context each transaction was created under
+type TransactionRateAuditService struct {
+	db *gorm.DB
+}
+
+// NewTransactionRateAuditService creates a new TransactionRateAuditService
+func NewTransactionRateAuditService(db *gorm.DB) *TransactionRateAuditService {
+	return &TransactionRateAuditService{db: db}
+}
+
+// CaptureWithTx stores the snapshot for a transaction being created inside tx. standard is the effective
+// rate the transaction's profit was measured against, or nil if none was available.
+func (s *TransactionRateAuditService) CaptureWithTx(tx *gorm.DB, transaction *models.Transaction, standard *models.ExchangeRate, at time.Time) (*models.TransactionRateAudit, error) {
+	audit := models.TransactionRateAudit{
+		TenantID:       transaction.TenantID,
+		TransactionID:  transaction.ID,
+		BaseCurrency:   transaction.SendCurrency,
+		TargetCurrency: transaction.ReceiveCurrency,
+		RateApplied:    transaction.RateApplied,
+		RateSource:     transaction.RateSource,
+		CapturedAt:     at,
+	}
+
+	if standard != nil {
+		rate, recordedAt := standard.Rate, standard.CreatedAt
+		audit.StandardRate = &rate
+		audit.StandardRateID = &standard.ID
+		audit.StandardRateSource = standard.Source
+		audit.StandardRateRecordedAt = &recordedAt
+		if standard.Source == models.RateSourceManual {
+			audit.ManualOverrideRate = &rate
+			audit.ManualOverrideID = &standard.ID
+			audit.ManualOverrideExpiresAt = standard.ExpiresAt
+		}
+	}
+
+	var market models.ExchangeRate
+	err := tx.Where("tenant_id = ? AND base_currency = ? AND target_currency = ? AND source = ?",
+		transaction.TenantID, transaction.SendCurrency, transaction.ReceiveCurrency, models.RateSourceAPI).
+		Where("(expires_at IS NULL OR expires_at > ?)", at).
+		Order("created_at DESC, id DESC").
+		First(&market).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		audit.MarketRate = &market.Rate
+		audit.MarketRateID = &market.ID
+		audit.MarketRateRecordedAt = &market.CreatedAt
+	}
+
+	if err := tx.Create(&audit).Error; err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// Get returns the rate snapshot of one of the tenant's transactions
+func (s *TransactionRateAuditService) Get(tenantID uint, transactionID string) (*models.TransactionRateAudit, error) {
+	var audit models.TransactionRateAudit
+	if err := s.db.Where("tenant_id = ? AND transaction_id = ?", tenantID, transactionID).First(&audit).Error; err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestTransactionRateAudit_UnchangedByLaterRateEdits verifies the rate context captured at creation survives
+// later edits to the exchange rates it was taken from
+func TestTransactionRateAudit_UnchangedByLaterRateEdits(t *testing.T) {
+	db := setupTestDB(t)
+	defer services.ResetGlobalCacheService()
+
+	tenant := models.Tenant{Name: "Rate Audit Tenant"}
+	db.Create(&tenant)
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Rate Audit Client",
+		PhoneNumber: "555-0105",
+	}
+	db.Create(&client)
+
+	market := models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.74),
+		Source:         models.RateSourceAPI,
+		CreatedAt:      time.Now().Add(-2 * time.Hour),
+	}
+	db.Create(&market)
+	expiresAt := time.Now().Add(24 * time.Hour)
+	override := models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.75),
+		Source:         models.RateSourceManual,
+		ExpiresAt:      &expiresAt,
+		CreatedAt:      time.Now().Add(-time.Hour),
+	}
+	db.Create(&override)
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	transaction := &models.Transaction{
+		TenantID:        tenant.ID,
+		ClientID:        client.ID,
+		PaymentMethod:   models.TransactionMethodCash,
+		SendCurrency:    "CAD",
+		SendAmount:      models.NewDecimal(1000),
+		ReceiveCurrency: "USD",
+		ReceiveAmount:   models.NewDecimal(730),
+		RateApplied:     models.NewDecimal(0.73),
+		TransactionDate: time.Now(),
+	}
+	if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	captured, err := transactionService.GetRateAudit(context.Background(), transaction.ID, tenant.ID)
+	if err != nil {
+		t.Fatalf("Expected a rate audit snapshot: %v", err)
+	}
+	if captured.RateApplied.Float64() != 0.73 || captured.RateSource != models.TransactionRateEntered {
+		t.Errorf("Expected the entered rate 0.73, got %v (%s)", captured.RateApplied.Float64(), captured.RateSource)
+	}
+	if captured.StandardRate == nil || captured.StandardRate.Float64() != 0.75 || captured.StandardRateSource != models.RateSourceManual {
+		t.Errorf("Expected the manual override 0.75 as the standard rate, got %v (%s)", captured.StandardRate, captured.StandardRateSource)
+	}
+	if captured.ManualOverrideID == nil || *captured.ManualOverrideID != override.ID || captured.ManualOverrideExpiresAt == nil {
+		t.Errorf("Expected the manual override %d with its expiry to be captured, got %v", override.ID, captured.ManualOverrideID)
+	}
+	if captured.MarketRate == nil || captured.MarketRate.Float64() != 0.74 {
+		t.Errorf("Expected the market rate 0.74, got %v", captured.MarketRate)
+	}
+
+	// Edit both rates in place and publish a new one
+	db.Model(&models.ExchangeRate{}).Where("id = ?", override.ID).Updates(map[string]interface{}{"rate": models.NewDecimal(0.80), "expires_at": nil})
+	db.Model(&models.ExchangeRate{}).Where("id = ?", market.ID).Update("rate", models.NewDecimal(0.70))
+	if err := services.NewExchangeRateService(db).UpdateRate(tenant.ID, "CAD", "USD", 0.77, nil); err != nil {
+		t.Fatalf("Failed to update rate: %v", err)
+	}
+
+	after, err := transactionService.GetRateAudit(context.Background(), transaction.ID, tenant.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload rate audit: %v", err)
+	}
+	if after.StandardRate.Float64() != 0.75 || after.ManualOverrideRate.Float64() != 0.75 || after.MarketRate.Float64() != 0.74 ||
+		after.ManualOverrideExpiresAt == nil || !after.CapturedAt.Equal(captured.CapturedAt) {
+		t.Errorf("Expected the snapshot to be unchanged by later rate edits, got standard %v, override %v, market %v",
+			after.StandardRate.Float64(), after.ManualOverrideRate.Float64(), after.MarketRate.Float64())
+	}
+
+	if _, err := transactionService.GetRateAudit(context.Background(), transaction.ID, tenant.ID+1); err == nil {
+		t.Error("Expected another tenant not to see the snapshot")
+	}
+}
@@ -3,22 +3,83 @@ package services
 import (
 	"api/pkg/models"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrRateUnavailable is returned when no rate was supplied and none can be filled in
+	ErrRateUnavailable = errors.New("exchange rate is required: none supplied and no effective rate available for this currency pair")
+	// ErrInvalidRate is returned when a supplied rate is negative
+	ErrInvalidRate = errors.New("exchange rate cannot be negative")
+	// ErrCompletedTransactionLocked is returned when the tenant's edit policy forbids changing a completed transaction
+	ErrCompletedTransactionLocked = errors.New("completed transactions cannot be edited; reverse the transaction instead")
+	// ErrRateSpreadExceeded is returned when the applied rate is further from the standard rate than the tenant allows
+	ErrRateSpreadExceeded = errors.New("exchange rate is too far from the standard rate")
+	// ErrRateSpreadOverrideForbidden is returned when someone other than an admin tries to accept an off-market rate
+	ErrRateSpreadOverrideForbidden = errors.New("only an owner or admin can override the rate spread limit")
+	// ErrTransactionNotDeleted is returned when restoring a transaction that was never deleted
+	ErrTransactionNotDeleted = errors.New("transaction is not deleted")
+	// ErrInvalidCursor is returned when a transaction list cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	// ErrInvalidSearchField is returned when a transaction search is scoped to a field that cannot be searched
+	ErrInvalidSearchField = errors.New("invalid search field")
+)
+
+const (
+	// DefaultTransactionPageSize is the page size used when a transaction list does not ask for one
+	DefaultTransactionPageSize = 50
+	// MaxTransactionPageSize caps how many transactions a single page can return
+	MaxTransactionPageSize = 200
+
+	// Relevance each searched field adds to a transaction search result. The weights keep any exact match
+	// above any number of prefix matches, and any prefix match above any number of contains matches.
+	searchScoreExact    = 100
+	searchScorePrefix   = 10
+	searchScoreContains = 1
+)
+
 type TransactionService struct {
 	db                  *gorm.DB
 	exchangeRateService *ExchangeRateService
+
+	// Events receives a domain event after each committed transaction change; nil disables publishing
+	Events EventBus
 }
 
 func NewTransactionService(db *gorm.DB, exchangeRateService *ExchangeRateService) *TransactionService {
 	return &TransactionService{
 		db:                  db,
 		exchangeRateService: exchangeRateService,
+		Events:              GetEventBus(),
+	}
+}
+
+// PublishEvent announces a committed change to a transaction on the event bus
+func (s *TransactionService) PublishEvent(name string, transaction *models.Transaction) {
+	if s.Events == nil {
+		return
 	}
+	s.Events.Publish(DomainEvent{
+		Name:     name,
+		TenantID: transaction.TenantID,
+		Data: map[string]interface{}{
+			"transactionId": transaction.ID,
+			"tenantId":      transaction.TenantID,
+			"branchId":      transaction.BranchID,
+			"status":        transaction.Status,
+		},
+		OccurredAt: time.Now(),
+	})
 }
 
 // CreateTransaction creates a new transaction with profit calculation and multi-payment setup
@@ -36,6 +97,16 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, transaction
 		transaction.PaymentStatus = models.PaymentStatusOpen
 	}
 
+	// Block customers whose KYC has lapsed until it is renewed
+	if err := NewComplianceService(s.db).EnsureClientComplianceCurrent(transaction.TenantID, transaction.ClientID); err != nil {
+		return err
+	}
+
+	// Fill in the rate from the effective exchange rate if the teller omitted it
+	if err := s.resolveRate(transaction); err != nil {
+		return err
+	}
+
 	// Calculate Profit
 	// Try to get the standard market rate for this currency pair
 	standardRateObj, err := s.exchangeRateService.GetCurrentRate(transaction.TenantID, transaction.SendCurrency, transaction.ReceiveCurrency)
@@ -57,20 +128,372 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, transaction
 			log.Printf("Warning: Could not fetch standard rate for %s/%s (tenant %d): %v",
 				transaction.SendCurrency, transaction.ReceiveCurrency, transaction.TenantID, err)
 		}
+		standardRateObj = nil
 		transaction.StandardRate = models.Zero()
 		transaction.Profit = models.Zero()
 		transaction.ProfitCalculationStatus = models.ProfitStatusPending
 	}
 
-	// Save to database
-	if err := s.db.WithContext(ctx).Create(transaction).Error; err != nil {
+	transaction.NetProfit = computeNetProfit(transaction)
+
+	// Warn on or reject rates too far from the standard rate
+	if err := s.checkRateSpread(transaction); err != nil {
+		return err
+	}
+
+	// Reject margins below the pair's profit floor unless an admin justified the exception
+	overriddenFloor, err := NewProfitFloorService(s.db).Enforce(transaction)
+	if err != nil {
+		return err
+	}
+
+	// A numbering failure must not block the sale; the transaction is still identified by its ID
+	if err := s.assignTransactionNumber(transaction, time.Now()); err != nil {
+		log.Printf("Warning: Could not number transaction %s (tenant %d): %v", transaction.ID, transaction.TenantID, err)
+	}
+
+	// Save to database together with the snapshot of the rates it was priced against
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		_, err := NewTransactionRateAuditService(s.db).CaptureWithTx(tx, transaction, standardRateObj, time.Now())
+		return err
+	}); err != nil {
 		log.Printf("Error creating transaction: %v", err)
 		return err
 	}
 
+	if overriddenFloor != nil {
+		NewProfitFloorService(s.db).LogOverride(transaction, overriddenFloor)
+	}
+	s.logRateSpreadOverride(transaction)
+
+	s.PublishEvent(EventTransactionCreated, transaction)
+	s.publishIfLarge(transaction)
 	return nil
 }
 
+// publishIfLarge announces a new transaction valued at or above the tenant's large transaction amount
+func (s *TransactionService) publishIfLarge(transaction *models.Transaction) {
+	if s.Events == nil {
+		return
+	}
+	settings, err := NewTenantSettingsService(s.db).GetSettings(transaction.TenantID)
+	if err != nil || !settings.LargeTransactionWebhookAmount.IsPositive() {
+		return
+	}
+	rate, err := s.exchangeRateService.GetHistoricalRate(transaction.TenantID, transaction.SendCurrency, settings.BaseCurrency, time.Now())
+	if err != nil {
+		log.Printf("Warning: Could not value transaction %s in %s for the large transaction check: %v", transaction.ID, settings.BaseCurrency, err)
+		return
+	}
+	value := transaction.SendAmount.Mul(rate)
+	if value.LessThan(settings.LargeTransactionWebhookAmount) {
+		return
+	}
+
+	s.Events.Publish(DomainEvent{
+		Name:     EventTransactionLarge,
+		TenantID: transaction.TenantID,
+		Data: map[string]interface{}{
+			"transactionId":   transaction.ID,
+			"tenantId":        transaction.TenantID,
+			"branchId":        transaction.BranchID,
+			"clientId":        transaction.ClientID,
+			"sendAmount":      transaction.SendAmount.Float64(),
+			"sendCurrency":    transaction.SendCurrency,
+			"receiveAmount":   transaction.ReceiveAmount.Float64(),
+			"receiveCurrency": transaction.ReceiveCurrency,
+			"value":           value.Round(2).Float64(),
+			"baseCurrency":    settings.BaseCurrency,
+			"threshold":       settings.LargeTransactionWebhookAmount.Float64(),
+		},
+		OccurredAt: time.Now(),
+	})
+}
+
+// assignTransactionNumber gives the transaction the next number in its branch's sequence,
+// restarting daily, monthly or never according to the tenant's policy
+func (s *TransactionService) assignTransactionNumber(transaction *models.Transaction, now time.Time) error {
+	if transaction.TransactionNumber != nil {
+		return nil
+	}
+
+	settings, err := NewTenantSettingsService(s.db).GetSettings(transaction.TenantID)
+	if err != nil {
+		return err
+	}
+
+	var branchCode string
+	if transaction.BranchID != nil {
+		var branch models.Branch
+		if err := s.db.Select("branch_code").Where("id = ? AND tenant_id = ?", *transaction.BranchID, transaction.TenantID).
+			First(&branch).Error; err == nil {
+			branchCode = branch.BranchCode
+		}
+	}
+
+	value, period, err := NewSequenceService(s.db).Next(transaction.TenantID, transactionSequenceKey(transaction.BranchID),
+		settings.TransactionNumberReset, now)
+	if err != nil {
+		return err
+	}
+
+	number := FormatTransactionNumber(branchCode, period, value)
+	transaction.TransactionNumber = &number
+	return nil
+}
+
+// resolveRate fills RateApplied from the tenant's effective rate when it was not supplied
+// and records where the rate came from
+func (s *TransactionService) resolveRate(transaction *models.Transaction) error {
+	if transaction.RateApplied.IsNegative() {
+		return ErrInvalidRate
+	}
+	if transaction.RateApplied.IsPositive() {
+		transaction.RateSource = models.TransactionRateEntered
+		return nil
+	}
+
+	settings, err := NewTenantSettingsService(s.db).GetSettings(transaction.TenantID)
+	if err != nil {
+		return err
+	}
+	if !settings.AutoFillTransactionRate {
+		return ErrRateUnavailable
+	}
+
+	rate, err := s.exchangeRateService.GetCurrentRate(transaction.TenantID, transaction.SendCurrency, transaction.ReceiveCurrency)
+	if err != nil || rate == nil || !rate.Rate.IsPositive() {
+		return ErrRateUnavailable
+	}
+
+	transaction.RateApplied = rate.Rate
+	transaction.RateSource = models.TransactionRateEffective
+	transaction.ExchangeRateID = &rate.ID
+	if transaction.ReceiveAmount.IsZero() {
+		transaction.ReceiveAmount = transaction.SendAmount.Mul(rate.Rate).Round(4)
+	}
+	return nil
+}
+
+// RateDeviationPct returns how far the applied rate is from the standard rate, as a percentage of the standard rate
+func RateDeviationPct(applied, standard models.Decimal) models.Decimal {
+	if !standard.IsPositive() {
+		return models.Zero()
+	}
+	return applied.Sub(standard).Abs().Div(standard).Mul(models.NewDecimal(100))
+}
+
+// checkRateSpread compares the applied rate with the standard rate against the tenant's spread limits.
+// Beyond the warning spread the transaction carries a RateWarning; beyond the blocking spread it is rejected
+// unless RateOverride is set with a reason by an owner or admin. Transactions without a standard rate are not checked.
+func (s *TransactionService) checkRateSpread(transaction *models.Transaction) error {
+	reason := ""
+	if transaction.RateOverrideReason != nil {
+		reason = strings.TrimSpace(*transaction.RateOverrideReason)
+	}
+	override := transaction.RateOverride
+	// The warning and override are recomputed from the current rate; a rate back within limits carries neither
+	transaction.RateWarning = nil
+	transaction.RateOverride = false
+	transaction.RateOverrideReason = nil
+	transaction.RateOverrideBy = nil
+
+	if transaction.ProfitCalculationStatus != models.ProfitStatusCalculated {
+		return nil
+	}
+	settings, err := NewTenantSettingsService(s.db).GetSettings(transaction.TenantID)
+	if err != nil {
+		return err
+	}
+
+	deviation := RateDeviationPct(transaction.RateApplied, transaction.StandardRate)
+	blocked := settings.RateSpreadBlockPct.IsPositive() && deviation.GreaterThan(settings.RateSpreadBlockPct)
+	warned := settings.RateSpreadWarnPct.IsPositive() && deviation.GreaterThan(settings.RateSpreadWarnPct)
+	if !blocked && !warned {
+		return nil
+	}
+
+	if blocked {
+		if !override || reason == "" {
+			return fmt.Errorf("%w: rate %s deviates %s%% from the standard rate %s (limit %s%%); an owner or admin can override with a reason",
+				ErrRateSpreadExceeded, transaction.RateApplied.String(), deviation.Round(2).String(),
+				transaction.StandardRate.String(), settings.RateSpreadBlockPct.String())
+		}
+		user, err := overridingAdmin(s.db, transaction.CreatedBy, ErrRateSpreadOverrideForbidden)
+		if err != nil {
+			return err
+		}
+		transaction.RateOverride = true
+		transaction.RateOverrideReason = &reason
+		transaction.RateOverrideBy = &user.ID
+	}
+
+	warning := fmt.Sprintf("Rate %s deviates %s%% from the standard rate %s", transaction.RateApplied.String(),
+		deviation.Round(2).String(), transaction.StandardRate.String())
+	transaction.RateWarning = &warning
+	return nil
+}
+
+// logRateSpreadOverride records an accepted off-market rate in the audit log
+func (s *TransactionService) logRateSpreadOverride(transaction *models.Transaction) {
+	if !transaction.RateOverride || transaction.RateOverrideBy == nil || transaction.RateOverrideReason == nil {
+		return
+	}
+	deviation := RateDeviationPct(transaction.RateApplied, transaction.StandardRate)
+	tenantID := transaction.TenantID
+	NewAuditService(s.db).LogAction(*transaction.RateOverrideBy, &tenantID, models.ActionOverrideRateSpread, "Transaction", transaction.ID,
+		fmt.Sprintf("Accepted rate %s, %s%% from the standard rate %s: %s", transaction.RateApplied.String(),
+			deviation.Round(2).String(), transaction.StandardRate.String(), *transaction.RateOverrideReason),
+		nil, map[string]interface{}{
+			"rateApplied":  transaction.RateApplied,
+			"standardRate": transaction.StandardRate,
+			"deviationPct": deviation.Round(4),
+			"reason":       *transaction.RateOverrideReason,
+		}, nil)
+}
+
+// EnsureEditable checks the tenant's completed-transaction edit policy before user edits transaction.
+// Transactions that are still open or partially paid, or were cancelled, remain editable.
+func (s *TransactionService) EnsureEditable(transaction *models.Transaction, user *models.User) error {
+	if !transaction.IsCompleted() {
+		return nil
+	}
+
+	settings, err := NewTenantSettingsService(s.db).GetSettings(transaction.TenantID)
+	if err != nil {
+		return err
+	}
+
+	switch settings.CompletedTransactionEdits {
+	case models.CompletedTransactionEditsLocked:
+		return ErrCompletedTransactionLocked
+	case models.CompletedTransactionEditsAdminOnly:
+		switch user.Role {
+		case models.RoleSuperAdmin, models.RoleTenantOwner, models.RoleTenantAdmin:
+			return nil
+		}
+		return ErrCompletedTransactionLocked
+	}
+	return nil
+}
+
+// UpdateTransaction applies user's edit to an existing transaction after checking the tenant's edit policy,
+// so ErrCompletedTransactionLocked is returned for a completed transaction the user may not change. The
+// previous values are appended to the edit history and the stored profit is brought in line with the new
+// amounts, rate and fee. gorm.ErrRecordNotFound is returned if the transaction has gone.
+func (s *TransactionService) UpdateTransaction(ctx context.Context, existing *models.Transaction, updated *models.Transaction, user *models.User) (*models.Transaction, error) {
+	if err := s.EnsureEditable(existing, user); err != nil {
+		return nil, err
+	}
+
+	var branchName string
+	if user.PrimaryBranchID != nil {
+		var branch models.Branch
+		if err := s.db.WithContext(ctx).First(&branch, *user.PrimaryBranchID).Error; err == nil {
+			branchName = branch.Name
+		}
+	}
+
+	// Save current state to edit history before updating
+	editHistoryEntry := map[string]interface{}{
+		"editedAt":           existing.UpdatedAt,
+		"editedByBranchId":   user.PrimaryBranchID, // Track which branch made the edit
+		"editedByBranchName": branchName,           // Store name for easier display
+
+		"paymentMethod":      existing.PaymentMethod,
+		"sendCurrency":       existing.SendCurrency,
+		"sendAmount":         existing.SendAmount,
+		"receiveCurrency":    existing.ReceiveCurrency,
+		"receiveAmount":      existing.ReceiveAmount,
+		"rateApplied":        existing.RateApplied,
+		"feeCharged":         existing.FeeCharged,
+		"beneficiaryName":    existing.BeneficiaryName,
+		"beneficiaryDetails": existing.BeneficiaryDetails,
+		"userNotes":          existing.UserNotes,
+	}
+
+	// Parse existing edit history
+	var editHistory []map[string]interface{}
+	if existing.EditHistory != nil && *existing.EditHistory != "" {
+		if err := json.Unmarshal([]byte(*existing.EditHistory), &editHistory); err != nil {
+			// If parsing fails, start with empty history
+			editHistory = []map[string]interface{}{}
+		}
+	}
+
+	// Append new entry to history
+	editHistory = append(editHistory, editHistoryEntry)
+	historyJSON, _ := json.Marshal(editHistory)
+	historyStr := string(historyJSON)
+
+	// Mark as edited and update edit history
+	now := time.Now()
+
+	// Prepare update map to avoid tenant_id ambiguity with scoped queries
+	updates := map[string]interface{}{
+		"payment_method":        updated.PaymentMethod,
+		"send_currency":         updated.SendCurrency,
+		"send_amount":           updated.SendAmount,
+		"receive_currency":      updated.ReceiveCurrency,
+		"receive_amount":        updated.ReceiveAmount,
+		"rate_applied":          updated.RateApplied,
+		"fee_charged":           updated.FeeCharged,
+		"beneficiary_name":      updated.BeneficiaryName,
+		"beneficiary_details":   updated.BeneficiaryDetails,
+		"user_notes":            updated.UserNotes,
+		"allow_partial_payment": updated.AllowPartialPayment, // Allow updating this flag
+		"is_edited":             true,
+		"last_edited_at":        now,
+		"edited_by_branch_id":   user.PrimaryBranchID, // Set the current branch as editor
+		"edit_history":          historyStr,
+		"updated_at":            now,
+	}
+
+	// Amounts, rate or fee may have changed, so bring the stored profit in line with them
+	edited := *existing
+	edited.SendCurrency = updated.SendCurrency
+	edited.SendAmount = updated.SendAmount
+	edited.ReceiveCurrency = updated.ReceiveCurrency
+	edited.ReceiveAmount = updated.ReceiveAmount
+	edited.RateApplied = updated.RateApplied
+	edited.FeeCharged = updated.FeeCharged
+	pairChanged := edited.SendCurrency != existing.SendCurrency || edited.ReceiveCurrency != existing.ReceiveCurrency
+	s.RecalculateProfit(&edited, pairChanged)
+	updates["standard_rate"] = edited.StandardRate
+	updates["profit"] = edited.Profit
+	updates["profit_calc_status"] = edited.ProfitCalculationStatus
+	updates["net_profit"] = edited.NetProfit
+
+	// If enabling partial payments for the first time, initialize tracking fields
+	if updated.AllowPartialPayment && !existing.AllowPartialPayment {
+		updates["total_received"] = updated.SendAmount // Assuming we receive the SendAmount
+		updates["received_currency"] = updated.SendCurrency
+		updates["remaining_balance"] = updated.SendAmount
+		updates["payment_status"] = models.PaymentStatusOpen
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("id = ? AND tenant_id = ?", existing.ID, existing.TenantID).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var transaction models.Transaction
+	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", existing.ID, existing.TenantID).First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	s.PublishEvent(EventTransactionUpdated, &transaction)
+
+	return &transaction, nil
+}
+
 func (s *TransactionService) GetTransaction(ctx context.Context, id string, tenantID uint) (*models.Transaction, error) {
 	var transaction models.Transaction
 	if err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", id, tenantID).Preload("Client").First(&transaction).Error; err != nil {
@@ -78,3 +501,472 @@ func (s *TransactionService) GetTransaction(ctx context.Context, id string, tena
 	}
 	return &transaction, nil
 }
+
+// TransactionListFilter selects a page of transactions. Cancelled transactions are always excluded.
+type TransactionListFilter struct {
+	TenantID  *uint      // nil lists every tenant's transactions (SuperAdmin)
+	StartDate *time.Time // Inclusive
+	EndDate   *time.Time // Exclusive
+	BranchID  *uint
+	Limit     int    // Defaults to DefaultTransactionPageSize and is capped at MaxTransactionPageSize
+	Offset    int    // Ignored when Cursor is set
+	Cursor    string // NextCursor of the previous page
+}
+
+// TransactionPage is one page of a transaction list, newest first
+type TransactionPage struct {
+	Data       []models.Transaction `json:"data"`
+	Total      int64                `json:"total"` // Matching transactions across all pages
+	Limit      int                  `json:"limit"`
+	NextCursor string               `json:"nextCursor,omitempty"` // Empty on the last page
+}
+
+// transactionCursor is the keyset position after the last transaction of a page
+type transactionCursor struct {
+	TransactionDate time.Time `json:"d"`
+	ID              string    `json:"id"`
+}
+
+func encodeTransactionCursor(transaction *models.Transaction) string {
+	raw, _ := json.Marshal(transactionCursor{TransactionDate: transaction.TransactionDate, ID: transaction.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeTransactionCursor(cursor string) (*transactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var decoded transactionCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.ID == "" || decoded.TransactionDate.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &decoded, nil
+}
+
+// ListTransactions returns a page of transactions ordered by transaction date and ID, newest first.
+// Pages are continued with the returned NextCursor, which stays stable while new transactions are added.
+func (s *TransactionService) ListTransactions(ctx context.Context, filter TransactionListFilter) (*TransactionPage, error) {
+	limit := filter.Limit
+	if limit < 1 {
+		limit = DefaultTransactionPageSize
+	}
+	if limit > MaxTransactionPageSize {
+		limit = MaxTransactionPageSize
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Transaction{}).Where("status != ?", models.StatusCancelled)
+	if filter.TenantID != nil {
+		query = query.Where("tenant_id = ?", *filter.TenantID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("transaction_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("transaction_date < ?", *filter.EndDate)
+	}
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	pageQuery := query.Session(&gorm.Session{})
+	if filter.Cursor != "" {
+		cursor, err := decodeTransactionCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		pageQuery = pageQuery.Where("transaction_date < ? OR (transaction_date = ? AND id < ?)",
+			cursor.TransactionDate, cursor.TransactionDate, cursor.ID)
+	} else if filter.Offset > 0 {
+		pageQuery = pageQuery.Offset(filter.Offset)
+	}
+
+	// Fetch one extra row to tell whether another page follows
+	var transactions []models.Transaction
+	if err := pageQuery.Preload("Client").Preload("Branch").
+		Order("transaction_date DESC, id DESC").
+		Limit(limit + 1).
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	page := &TransactionPage{Total: total, Limit: limit}
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+		page.NextCursor = encodeTransactionCursor(&transactions[limit-1])
+	}
+	page.Data = transactions
+	return page, nil
+}
+
+// transactionSearchField is a column transaction search can be scoped to
+type transactionSearchField struct {
+	Name   string
+	Column string
+	Value  func(transaction *models.Transaction) string
+}
+
+// transactionSearchFields lists the searchable fields in the order they are searched by default
+var transactionSearchFields = []transactionSearchField{
+	{"beneficiary_name", "transactions.beneficiary_name", func(t *models.Transaction) string { return derefString(t.BeneficiaryName) }},
+	{"client_name", "clients.name", func(t *models.Transaction) string {
+		if t.Client == nil {
+			return ""
+		}
+		return t.Client.Name
+	}},
+	{"client_phone", "clients.phone_number", func(t *models.Transaction) string {
+		if t.Client == nil {
+			return ""
+		}
+		return t.Client.PhoneNumber
+	}},
+	{"user_notes", "transactions.user_notes", func(t *models.Transaction) string { return derefString(t.UserNotes) }},
+	{"send_currency", "transactions.send_currency", func(t *models.Transaction) string { return t.SendCurrency }},
+	{"receive_currency", "transactions.receive_currency", func(t *models.Transaction) string { return t.ReceiveCurrency }},
+	{"payment_method", "transactions.payment_method", func(t *models.Transaction) string { return t.PaymentMethod }},
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// SearchHighlight locates the first match of the query within a field, in characters
+type SearchHighlight struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"` // Exclusive
+}
+
+// TransactionSearchResult is a matching transaction with its relevance and where the query matched
+type TransactionSearchResult struct {
+	models.Transaction
+	Score      int               `json:"score"`
+	Highlights []SearchHighlight `json:"highlights"`
+}
+
+// SearchTransactions finds transactions whose fields contain the query, case-insensitively, ordered by
+// relevance: an exact field match ranks above a prefix match, which ranks above a match inside the field.
+// fields scopes the search to some of beneficiary_name, client_name, client_phone, user_notes, send_currency,
+// receive_currency and payment_method; empty searches them all. A nil tenantID searches every tenant.
+func (s *TransactionService) SearchTransactions(ctx context.Context, tenantID *uint, query string, fields []string, limit int) ([]TransactionSearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []TransactionSearchResult{}, nil
+	}
+	if limit < 1 {
+		limit = DefaultTransactionPageSize
+	}
+	if limit > MaxTransactionPageSize {
+		limit = MaxTransactionPageSize
+	}
+
+	searched := transactionSearchFields
+	if len(fields) > 0 {
+		searched = nil
+		for _, name := range fields {
+			name = strings.ToLower(strings.TrimSpace(name))
+			found := false
+			for _, field := range transactionSearchFields {
+				if field.Name == name {
+					searched = append(searched, field)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidSearchField, name)
+			}
+		}
+	}
+
+	pattern := escapeLikePattern(query)
+	var matches, scores []string
+	var matchArgs, scoreArgs []interface{}
+	for _, field := range searched {
+		column := fmt.Sprintf("LOWER(COALESCE(%s, ''))", field.Column)
+		matches = append(matches, column+" LIKE ? ESCAPE '\\'")
+		matchArgs = append(matchArgs, "%"+pattern+"%")
+		scores = append(scores, fmt.Sprintf("CASE WHEN %[1]s = ? THEN %[2]d WHEN %[1]s LIKE ? ESCAPE '\\' THEN %[3]d WHEN %[1]s LIKE ? ESCAPE '\\' THEN %[4]d ELSE 0 END",
+			column, searchScoreExact, searchScorePrefix, searchScoreContains))
+		scoreArgs = append(scoreArgs, query, pattern+"%", "%"+pattern+"%")
+	}
+
+	db := s.db.WithContext(ctx).Model(&models.Transaction{}).
+		Joins("LEFT JOIN clients ON clients.id = transactions.client_id")
+	if tenantID != nil {
+		db = db.Where("transactions.tenant_id = ?", *tenantID)
+	}
+
+	var transactions []models.Transaction
+	if err := db.Select("transactions.*, ("+strings.Join(scores, " + ")+") AS search_score", scoreArgs...).
+		Where("("+strings.Join(matches, " OR ")+")", matchArgs...).
+		Preload("Client").
+		Order("search_score DESC, transactions.transaction_date DESC").
+		Limit(limit).
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]TransactionSearchResult, 0, len(transactions))
+	for i := range transactions {
+		result := TransactionSearchResult{Transaction: transactions[i], Highlights: []SearchHighlight{}}
+		for _, field := range searched {
+			value := strings.ToLower(field.Value(&transactions[i]))
+			at := strings.Index(value, query)
+			if at < 0 {
+				continue
+			}
+			switch {
+			case value == query:
+				result.Score += searchScoreExact
+			case at == 0:
+				result.Score += searchScorePrefix
+			default:
+				result.Score += searchScoreContains
+			}
+			start := utf8.RuneCountInString(value[:at])
+			result.Highlights = append(result.Highlights, SearchHighlight{
+				Field: field.Name,
+				Start: start,
+				End:   start + utf8.RuneCountInString(query),
+			})
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// DeleteTransaction soft-deletes a transaction. In the same database transaction its ledger entries are
+// offset with reversals and the cash balances its cash payments fed are recalculated without them.
+func (s *TransactionService) DeleteTransaction(ctx context.Context, id string, tenantID, userID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	now := time.Now()
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND tenant_id = ?", id, tenantID).First(&transaction).Error; err != nil {
+			return err
+		}
+		if err := s.ensureDaysOpen(tx, &transaction, now); err != nil {
+			return err
+		}
+		if err := tx.Model(&transaction).Updates(map[string]interface{}{"deleted_at": now, "deleted_by": userID}).Error; err != nil {
+			return err
+		}
+
+		// Reversals are stamped with the deletion time so a restore can tell them from the original entries
+		if err := s.offsetLedger(tx, &transaction, now, now, userID, true); err != nil {
+			return err
+		}
+		return s.refreshCashBalances(tx, &transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	transaction.DeletedBy = &userID
+	return &transaction, nil
+}
+
+// RestoreTransaction un-deletes a soft-deleted transaction and re-applies the ledger entries and
+// cash balance effects its deletion reversed
+func (s *TransactionService) RestoreTransaction(ctx context.Context, id string, tenantID, userID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND tenant_id = ?", id, tenantID).First(&transaction).Error; err != nil {
+			return err
+		}
+		if !transaction.DeletedAt.Valid {
+			return ErrTransactionNotDeleted
+		}
+		deletedAt := transaction.DeletedAt.Time
+		now := time.Now()
+		if err := s.ensureDaysOpen(tx, &transaction, now); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&transaction).Updates(map[string]interface{}{"deleted_at": nil, "deleted_by": nil}).Error; err != nil {
+			return err
+		}
+		if err := s.offsetLedger(tx, &transaction, deletedAt, now, userID, false); err != nil {
+			return err
+		}
+		return s.refreshCashBalances(tx, &transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.DeletedAt = gorm.DeletedAt{}
+	transaction.DeletedBy = nil
+	return &transaction, nil
+}
+
+// ensureDaysOpen returns ErrDayClosed if deleting or restoring the transaction would change a closed day:
+// the day its completed payments were made, or the day at which the reversals are posted
+func (s *TransactionService) ensureDaysOpen(tx *gorm.DB, transaction *models.Transaction, at time.Time) error {
+	if err := ensureDayOpen(tx, transaction.TenantID, transaction.BranchID, at); err != nil {
+		return err
+	}
+
+	var payments []models.Payment
+	if err := tx.Select("branch_id", "paid_at").
+		Where("tenant_id = ? AND transaction_id = ? AND status = ?", transaction.TenantID, transaction.ID, models.PaymentStatusCompleted).
+		Find(&payments).Error; err != nil {
+		return err
+	}
+	for _, payment := range payments {
+		for _, day := range []time.Time{payment.PaidAt, at} {
+			if err := ensureDayOpen(tx, transaction.TenantID, payment.BranchID, day); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// offsetLedger sums the transaction's ledger entries recorded before the cut-off per client, branch and
+// currency and posts a reversal of each balance (when deleting) or re-applies it (when restoring)
+func (s *TransactionService) offsetLedger(tx *gorm.DB, transaction *models.Transaction, before, at time.Time, userID uint, deleting bool) error {
+	var nets []models.LedgerEntry
+	if err := tx.Model(&models.LedgerEntry{}).
+		Select("client_id, branch_id, currency, COALESCE(SUM(amount), 0) AS amount").
+		Where("tenant_id = ? AND transaction_id = ? AND created_at < ?", transaction.TenantID, transaction.ID, before).
+		Group("client_id, branch_id, currency").
+		Scan(&nets).Error; err != nil {
+		return err
+	}
+
+	ledger := NewLedgerService(tx)
+	for _, net := range nets {
+		if net.Amount.IsZero() {
+			continue
+		}
+		amount, description := net.Amount.Neg(), fmt.Sprintf("Reversed: transaction %s deleted", transaction.ID)
+		if !deleting {
+			amount, description = net.Amount, fmt.Sprintf("Re-applied: transaction %s restored", transaction.ID)
+		}
+		if _, err := ledger.AddEntryWithTx(tx, models.LedgerEntry{
+			TenantID:      transaction.TenantID,
+			ClientID:      net.ClientID,
+			BranchID:      net.BranchID,
+			TransactionID: &transaction.ID,
+			Type:          models.LedgerTypeReversal,
+			Currency:      net.Currency,
+			Amount:        amount,
+			Description:   description,
+			CreatedAt:     at,
+			CreatedBy:     userID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshCashBalances recalculates the cash balances fed by the transaction's completed cash payments
+func (s *TransactionService) refreshCashBalances(tx *gorm.DB, transaction *models.Transaction) error {
+	var payments []models.Payment
+	if err := tx.Model(&models.Payment{}).Distinct("branch_id", "currency").
+		Where("tenant_id = ? AND transaction_id = ? AND payment_method = ? AND status = ?",
+			transaction.TenantID, transaction.ID, models.PaymentMethodCash, models.PaymentStatusCompleted).
+		Find(&payments).Error; err != nil {
+		return err
+	}
+
+	cashBalances := NewCashBalanceService(tx)
+	for _, payment := range payments {
+		if err := cashBalances.RefreshBalanceFor(transaction.TenantID, payment.BranchID, payment.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransactionProfitBreakdown shows how a transaction's net profit was derived.
+// All amounts are in the transaction's send currency.
+type TransactionProfitBreakdown struct {
+	TransactionID string         `json:"transactionId"`
+	Currency      string         `json:"currency"`
+	StandardRate  models.Decimal `json:"standardRate"`
+	RateApplied   models.Decimal `json:"rateApplied"`
+	Spread        models.Decimal `json:"spread"`   // Profit from the rate difference
+	Fee           models.Decimal `json:"fee"`      // Fee charged to the customer
+	Cost          models.Decimal `json:"cost"`     // Direct costs incurred
+	Discount      models.Decimal `json:"discount"` // Discount given to the customer
+	Net           models.Decimal `json:"net"`      // Stored net profit: Spread + Fee - Cost - Discount
+	ProfitStatus  string         `json:"profitStatus"`
+}
+
+// spreadProfit computes the rate-spread profit in send currency from the stored rates
+func spreadProfit(transaction *models.Transaction) models.Decimal {
+	if !transaction.StandardRate.IsPositive() {
+		return models.Zero()
+	}
+	return transaction.SendAmount.Mul(transaction.StandardRate.Sub(transaction.RateApplied)).Div(transaction.StandardRate).Round(4)
+}
+
+// computeNetProfit returns spread + fee - cost - discount
+func computeNetProfit(transaction *models.Transaction) models.Decimal {
+	return spreadProfit(transaction).
+		Add(transaction.FeeCharged).
+		Sub(transaction.CostAmount).
+		Sub(transaction.DiscountAmount)
+}
+
+// RecalculateProfit refreshes the spread and net profit of an edited transaction. The standard rate captured
+// on creation is kept unless the currency pair changed or none was captured, in which case the current rate
+// is looked up again; without one the profit is left PENDING for the background job.
+func (s *TransactionService) RecalculateProfit(transaction *models.Transaction, pairChanged bool) {
+	if pairChanged || !transaction.StandardRate.IsPositive() {
+		transaction.StandardRate = models.Zero()
+		standardRateObj, err := s.exchangeRateService.GetCurrentRate(transaction.TenantID, transaction.SendCurrency, transaction.ReceiveCurrency)
+		if err == nil && standardRateObj != nil && standardRateObj.Rate.IsPositive() {
+			transaction.StandardRate = standardRateObj.Rate
+		}
+	}
+
+	if transaction.StandardRate.IsPositive() {
+		transaction.Profit = spreadProfit(transaction)
+		transaction.ProfitCalculationStatus = models.ProfitStatusCalculated
+	} else {
+		transaction.Profit = models.Zero()
+		transaction.ProfitCalculationStatus = models.ProfitStatusPending
+	}
+	transaction.NetProfit = computeNetProfit(transaction)
+}
+
+// GetProfitBreakdown returns the profit components for a single transaction
+func (s *TransactionService) GetProfitBreakdown(ctx context.Context, id string, tenantID uint) (*TransactionProfitBreakdown, error) {
+	transaction, err := s.GetTransaction(ctx, id, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	spread := spreadProfit(transaction)
+	return &TransactionProfitBreakdown{
+		TransactionID: transaction.ID,
+		Currency:      transaction.SendCurrency,
+		StandardRate:  transaction.StandardRate,
+		RateApplied:   transaction.RateApplied,
+		Spread:        spread,
+		Fee:           transaction.FeeCharged,
+		Cost:          transaction.CostAmount,
+		Discount:      transaction.DiscountAmount,
+		Net:           transaction.NetProfit,
+		ProfitStatus:  transaction.ProfitCalculationStatus,
+	}, nil
+}
+
+// GetRateAudit returns the rate snapshot captured when the transaction was created
+func (s *TransactionService) GetRateAudit(ctx context.Context, id string, tenantID uint) (*models.TransactionRateAudit, error) {
+	return NewTransactionRateAuditService(s.db.WithContext(ctx)).Get(tenantID, id)
+}
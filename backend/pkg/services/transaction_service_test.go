@@ -0,0 +1,825 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestTransactionProfitBreakdown verifies breakdown components sum to the stored net profit
+func TestTransactionProfitBreakdown(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Breakdown Tenant"}
+	db.Create(&tenant)
+
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Breakdown Client",
+		PhoneNumber: "555-0100",
+	}
+	db.Create(&client)
+
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.75),
+		Source:         models.RateSourceManual,
+	})
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+
+	transaction := &models.Transaction{
+		TenantID:        tenant.ID,
+		ClientID:        client.ID,
+		PaymentMethod:   models.TransactionMethodCash,
+		SendCurrency:    "CAD",
+		SendAmount:      models.NewDecimal(1000),
+		ReceiveCurrency: "USD",
+		ReceiveAmount:   models.NewDecimal(720),
+		RateApplied:     models.NewDecimal(0.72),
+		FeeCharged:      models.NewDecimal(15),
+		CostAmount:      models.NewDecimal(2.5),
+		DiscountAmount:  models.NewDecimal(5),
+		TransactionDate: time.Now(),
+	}
+	if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	breakdown, err := transactionService.GetProfitBreakdown(context.Background(), transaction.ID, tenant.ID)
+	if err != nil {
+		t.Fatalf("Failed to get profit breakdown: %v", err)
+	}
+
+	var stored models.Transaction
+	db.First(&stored, "id = ?", transaction.ID)
+
+	// Spread = 1000 * (0.75 - 0.72) / 0.75 = 40
+	if math.Abs(breakdown.Spread.Float64()-40) > 0.0001 {
+		t.Errorf("Expected spread 40, got %s", breakdown.Spread.String())
+	}
+
+	sum := breakdown.Spread.Add(breakdown.Fee).Sub(breakdown.Cost).Sub(breakdown.Discount)
+	if math.Abs(sum.Float64()-stored.NetProfit.Float64()) > 0.0001 {
+		t.Errorf("Components sum to %s, stored net profit is %s", sum.String(), stored.NetProfit.String())
+	}
+	if math.Abs(stored.NetProfit.Float64()-47.5) > 0.0001 {
+		t.Errorf("Expected net profit 47.5, got %s", stored.NetProfit.String())
+	}
+	if math.Abs(breakdown.Net.Float64()-stored.NetProfit.Float64()) > 0.0001 {
+		t.Errorf("Expected breakdown net %s to match stored %s", breakdown.Net.String(), stored.NetProfit.String())
+	}
+}
+
+// TestCreateTransaction_AutoFillsRate verifies an omitted rate is taken from the effective rate and its source recorded
+func TestCreateTransaction_AutoFillsRate(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TenantSettings{}))
+
+	tenant := models.Tenant{Name: "Auto Rate Tenant"}
+	db.Create(&tenant)
+
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Auto Rate Client",
+		PhoneNumber: "555-0101",
+	}
+	db.Create(&client)
+
+	rate := models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.74),
+		Source:         models.RateSourceManual,
+	}
+	db.Create(&rate)
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+
+	t.Run("OmittedRateFilled", func(t *testing.T) {
+		transaction := &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			TransactionDate: time.Now(),
+		}
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+
+		var saved models.Transaction
+		db.First(&saved, "id = ?", transaction.ID)
+		if saved.RateApplied.Float64() != 0.74 {
+			t.Errorf("Expected rate 0.74 to be filled, got %v", saved.RateApplied.Float64())
+		}
+		if saved.RateSource != models.TransactionRateEffective {
+			t.Errorf("Expected rate source %s, got %s", models.TransactionRateEffective, saved.RateSource)
+		}
+		if saved.ExchangeRateID == nil || *saved.ExchangeRateID != rate.ID {
+			t.Errorf("Expected exchange rate %d to be recorded, got %v", rate.ID, saved.ExchangeRateID)
+		}
+		if math.Abs(saved.ReceiveAmount.Float64()-740) > 0.0001 {
+			t.Errorf("Expected receive amount 740, got %v", saved.ReceiveAmount.Float64())
+		}
+	})
+
+	t.Run("SuppliedRateKept", func(t *testing.T) {
+		transaction := &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(100),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(72),
+			RateApplied:     models.NewDecimal(0.72),
+			TransactionDate: time.Now(),
+		}
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		if transaction.RateApplied.Float64() != 0.72 || transaction.RateSource != models.TransactionRateEntered {
+			t.Errorf("Expected entered rate 0.72 to be kept, got %v (%s)", transaction.RateApplied.Float64(), transaction.RateSource)
+		}
+	})
+
+	t.Run("RejectedWithoutEffectiveRate", func(t *testing.T) {
+		transaction := &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "EUR",
+			SendAmount:      models.NewDecimal(100),
+			ReceiveCurrency: "USD",
+			TransactionDate: time.Now(),
+		}
+		err := transactionService.CreateTransaction(context.Background(), transaction)
+		if !errors.Is(err, services.ErrRateUnavailable) {
+			t.Errorf("Expected ErrRateUnavailable, got %v", err)
+		}
+	})
+}
+
+// TestCreateTransaction_BlocksExpiredCompliance verifies customers with lapsed KYC cannot transact until renewed
+func TestCreateTransaction_BlocksExpiredCompliance(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "KYC Expiry Tenant"}
+	db.Create(&tenant)
+
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Expired Customer",
+		PhoneNumber: "+14165550199",
+	}
+	db.Create(&client)
+
+	customer := models.Customer{FullName: client.Name, Phone: client.PhoneNumber}
+	db.Create(&customer)
+
+	expired := time.Now().AddDate(0, 0, -1)
+	compliance := models.CustomerCompliance{
+		TenantID:   tenant.ID,
+		CustomerID: customer.ID,
+		Status:     models.ComplianceStatusApproved,
+		ExpiresAt:  &expired,
+	}
+	db.Create(&compliance)
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	newTransaction := func() *models.Transaction {
+		return &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(500),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(365),
+			RateApplied:     models.NewDecimal(0.73),
+			TransactionDate: time.Now(),
+		}
+	}
+
+	err := transactionService.CreateTransaction(context.Background(), newTransaction())
+	if !errors.Is(err, services.ErrComplianceExpired) {
+		t.Fatalf("Expected ErrComplianceExpired, got %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Transaction{}).Where("client_id = ?", client.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no transaction to be saved, got %d", count)
+	}
+
+	// Renewal: new expiry in the future and approved again
+	renewed := time.Now().AddDate(1, 0, 0)
+	db.Model(&compliance).Updates(map[string]interface{}{
+		"status":     models.ComplianceStatusApproved,
+		"expires_at": renewed,
+	})
+
+	if err := transactionService.CreateTransaction(context.Background(), newTransaction()); err != nil {
+		t.Fatalf("Expected renewed customer to transact, got %v", err)
+	}
+}
+
+// TestEnsureEditable_CompletedTransactionAdminOnly verifies only admins may edit completed transactions under ADMIN_ONLY,
+// and that UpdateTransaction enforces it
+func TestEnsureEditable_CompletedTransactionAdminOnly(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Edit Policy Tenant"}
+	db.Create(&tenant)
+
+	policy := models.CompletedTransactionEditsAdminOnly
+	settingsService := services.NewTenantSettingsService(db)
+	if _, err := settingsService.UpdateSettings(tenant.ID, services.UpdateTenantSettingsRequest{CompletedTransactionEdits: &policy}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	tenantUser := &models.User{TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	tenantAdmin := &models.User{TenantID: &tenant.ID, Role: models.RoleTenantAdmin}
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+
+	completed := &models.Transaction{
+		TenantID:      tenant.ID,
+		Status:        models.StatusCompleted,
+		PaymentStatus: models.PaymentStatusSingle,
+	}
+	if err := transactionService.EnsureEditable(completed, tenantUser); !errors.Is(err, services.ErrCompletedTransactionLocked) {
+		t.Errorf("Expected tenant user to be blocked, got %v", err)
+	}
+	if err := transactionService.EnsureEditable(completed, tenantAdmin); err != nil {
+		t.Errorf("Expected admin to be allowed, got %v", err)
+	}
+	if _, err := transactionService.UpdateTransaction(context.Background(), completed, &models.Transaction{SendAmount: models.NewDecimal(1)}, tenantUser); !errors.Is(err, services.ErrCompletedTransactionLocked) {
+		t.Errorf("Expected the update itself to refuse the tenant user, got %v", err)
+	}
+
+	partial := &models.Transaction{
+		TenantID:      tenant.ID,
+		Status:        models.StatusCompleted,
+		PaymentStatus: models.PaymentStatusPartial,
+	}
+	if err := transactionService.EnsureEditable(partial, tenantUser); err != nil {
+		t.Errorf("Expected partially paid transaction to stay editable, got %v", err)
+	}
+}
+
+// TestCreateTransaction_EnforcesProfitFloor verifies below-floor transactions are blocked unless an admin
+// overrides the floor with a justification, and that the override is audited
+func TestCreateTransaction_EnforcesProfitFloor(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Profit Floor Tenant"}
+	db.Create(&tenant)
+	teller := models.User{Email: "teller@example.com", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(&teller)
+	admin := models.User{Email: "admin@example.com", TenantID: &tenant.ID, Role: models.RoleTenantAdmin}
+	db.Create(&admin)
+
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Floor Client",
+		PhoneNumber: "555-0102",
+	}
+	db.Create(&client)
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.74),
+		Source:         models.RateSourceManual,
+	})
+
+	if _, err := services.NewProfitFloorService(db).SetFloor(tenant.ID, "cad", "usd", models.NewDecimal(1), admin.ID); err != nil {
+		t.Fatalf("Failed to set profit floor: %v", err)
+	}
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	newTransaction := func(rate float64, createdBy uint, reason string) *models.Transaction {
+		transaction := &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(1000 * rate),
+			RateApplied:     models.NewDecimal(rate),
+			CreatedBy:       &createdBy,
+			TransactionDate: time.Now(),
+		}
+		if reason != "" {
+			transaction.MarginOverrideReason = &reason
+		}
+		return transaction
+	}
+
+	// 0.73 against a 0.74 market rate is a 1.35% margin
+	if err := transactionService.CreateTransaction(context.Background(), newTransaction(0.73, teller.ID, "")); err != nil {
+		t.Fatalf("Expected a transaction above the floor to be created, got %v", err)
+	}
+
+	t.Run("BelowFloorBlocked", func(t *testing.T) {
+		err := transactionService.CreateTransaction(context.Background(), newTransaction(0.75, teller.ID, ""))
+		if !errors.Is(err, services.ErrBelowProfitFloor) {
+			t.Fatalf("Expected ErrBelowProfitFloor, got %v", err)
+		}
+	})
+
+	t.Run("NonAdminCannotOverride", func(t *testing.T) {
+		err := transactionService.CreateTransaction(context.Background(), newTransaction(0.75, teller.ID, "Loyal customer"))
+		if !errors.Is(err, services.ErrProfitFloorOverrideForbidden) {
+			t.Fatalf("Expected ErrProfitFloorOverrideForbidden, got %v", err)
+		}
+	})
+
+	t.Run("AdminOverrideAudited", func(t *testing.T) {
+		transaction := newTransaction(0.75, admin.ID, "Matching a competitor quote for a corporate client")
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Expected the admin override to be accepted, got %v", err)
+		}
+
+		var saved models.Transaction
+		db.First(&saved, "id = ?", transaction.ID)
+		if saved.MarginOverrideBy == nil || *saved.MarginOverrideBy != admin.ID || saved.MarginOverrideReason == nil {
+			t.Errorf("Expected the override to be recorded on the transaction, got by=%v reason=%v", saved.MarginOverrideBy, saved.MarginOverrideReason)
+		}
+
+		var audit models.AuditLog
+		if err := db.Where("action = ? AND entity_id = ?", models.ActionOverrideProfitFloor, transaction.ID).First(&audit).Error; err != nil {
+			t.Fatalf("Expected an override audit entry: %v", err)
+		}
+		if audit.UserID != admin.ID {
+			t.Errorf("Expected the override to be attributed to the admin, got user %d", audit.UserID)
+		}
+	})
+
+	var count int64
+	db.Model(&models.Transaction{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected only the above-floor and overridden transactions to be saved, got %d", count)
+	}
+}
+
+// TestCreateTransaction_RateSpreadGuardrails verifies rates within the tenant's spread pass silently, rates past
+// the warning spread carry a warning, and rates past the blocking spread need an audited admin override
+func TestCreateTransaction_RateSpreadGuardrails(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Rate Spread Tenant"}
+	db.Create(&tenant)
+	teller := models.User{Email: "spread-teller@example.com", TenantID: &tenant.ID, Role: models.RoleTenantUser}
+	db.Create(&teller)
+	owner := models.User{Email: "spread-owner@example.com", TenantID: &tenant.ID, Role: models.RoleTenantOwner}
+	db.Create(&owner)
+
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Spread Client",
+		PhoneNumber: "555-0103",
+	}
+	db.Create(&client)
+	db.Create(&models.ExchangeRate{
+		TenantID:       tenant.ID,
+		BaseCurrency:   "CAD",
+		TargetCurrency: "USD",
+		Rate:           models.NewDecimal(0.74),
+		Source:         models.RateSourceManual,
+	})
+
+	warnPct, blockPct := 1.0, 5.0
+	if _, err := services.NewTenantSettingsService(db).UpdateSettings(tenant.ID, services.UpdateTenantSettingsRequest{
+		RateSpreadWarnPct:  &warnPct,
+		RateSpreadBlockPct: &blockPct,
+	}); err != nil {
+		t.Fatalf("Failed to configure rate spread limits: %v", err)
+	}
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	newTransaction := func(rate float64, createdBy uint, override bool, reason string) *models.Transaction {
+		transaction := &models.Transaction{
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(1000 * rate),
+			RateApplied:     models.NewDecimal(rate),
+			CreatedBy:       &createdBy,
+			RateOverride:    override,
+			TransactionDate: time.Now(),
+		}
+		if reason != "" {
+			transaction.RateOverrideReason = &reason
+		}
+		return transaction
+	}
+
+	t.Run("WithinMargin", func(t *testing.T) {
+		// 0.735 is 0.68% from the 0.74 standard rate
+		transaction := newTransaction(0.735, teller.ID, false, "")
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Expected a rate within the spread to be accepted, got %v", err)
+		}
+		if transaction.RateWarning != nil {
+			t.Errorf("Expected no warning within the spread, got %q", *transaction.RateWarning)
+		}
+	})
+
+	t.Run("WarnLevel", func(t *testing.T) {
+		// 0.725 is 2.03% from the standard rate
+		transaction := newTransaction(0.725, teller.ID, false, "")
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Expected a warn-level rate to be accepted, got %v", err)
+		}
+		if transaction.RateWarning == nil {
+			t.Fatal("Expected a warn-level rate to carry a warning")
+		}
+		if transaction.RateOverride {
+			t.Error("Expected no override to be recorded for a warn-level rate")
+		}
+	})
+
+	t.Run("BlockLevel", func(t *testing.T) {
+		// 0.70 is 5.41% from the standard rate
+		err := transactionService.CreateTransaction(context.Background(), newTransaction(0.70, teller.ID, false, ""))
+		if !errors.Is(err, services.ErrRateSpreadExceeded) {
+			t.Fatalf("Expected ErrRateSpreadExceeded, got %v", err)
+		}
+
+		err = transactionService.CreateTransaction(context.Background(), newTransaction(0.70, owner.ID, true, ""))
+		if !errors.Is(err, services.ErrRateSpreadExceeded) {
+			t.Errorf("Expected an override without a reason to be rejected, got %v", err)
+		}
+
+		err = transactionService.CreateTransaction(context.Background(), newTransaction(0.70, teller.ID, true, "Customer insisted"))
+		if !errors.Is(err, services.ErrRateSpreadOverrideForbidden) {
+			t.Errorf("Expected ErrRateSpreadOverrideForbidden for a teller, got %v", err)
+		}
+
+		transaction := newTransaction(0.70, owner.ID, true, "Bulk order at a negotiated rate")
+		if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+			t.Fatalf("Expected the owner's override to be accepted, got %v", err)
+		}
+		if transaction.RateWarning == nil {
+			t.Error("Expected an overridden rate to still carry a warning")
+		}
+
+		var saved models.Transaction
+		db.First(&saved, "id = ?", transaction.ID)
+		if !saved.RateOverride || saved.RateOverrideBy == nil || *saved.RateOverrideBy != owner.ID || saved.RateOverrideReason == nil {
+			t.Errorf("Expected the override to be recorded on the transaction, got override=%v by=%v", saved.RateOverride, saved.RateOverrideBy)
+		}
+
+		var audit models.AuditLog
+		if err := db.Where("action = ? AND entity_id = ?", models.ActionOverrideRateSpread, transaction.ID).First(&audit).Error; err != nil {
+			t.Fatalf("Expected an override audit entry: %v", err)
+		}
+		if audit.UserID != owner.ID {
+			t.Errorf("Expected the override to be attributed to the owner, got user %d", audit.UserID)
+		}
+	})
+
+	var count int64
+	db.Model(&models.Transaction{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+	if count != 3 {
+		t.Errorf("Expected the within-margin, warn-level and overridden transactions to be saved, got %d", count)
+	}
+}
+
+// TestDeleteRestoreTransaction_RoundTripsTotals verifies a soft delete reverses the transaction's ledger and
+// cash balance effects and hides it from queries, and a restore puts every total back
+func TestDeleteRestoreTransaction_RoundTripsTotals(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Soft Delete Tenant"}
+	db.Create(&tenant)
+	owner := models.User{Email: "restore-owner@example.com", TenantID: &tenant.ID, Role: models.RoleTenantOwner}
+	db.Create(&owner)
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Soft Delete Client",
+		PhoneNumber: "555-0104",
+	}
+	db.Create(&client)
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	transaction := &models.Transaction{
+		TenantID:        tenant.ID,
+		ClientID:        client.ID,
+		PaymentMethod:   models.TransactionMethodCash,
+		SendCurrency:    "CAD",
+		SendAmount:      models.NewDecimal(1000),
+		ReceiveCurrency: "USD",
+		ReceiveAmount:   models.NewDecimal(740),
+		RateApplied:     models.NewDecimal(0.74),
+		CreatedBy:       &owner.ID,
+		TransactionDate: time.Now(),
+	}
+	if err := transactionService.CreateTransaction(context.Background(), transaction); err != nil {
+		t.Fatalf("Failed to create transaction: %v", err)
+	}
+
+	db.Create(&models.Payment{
+		TenantID:      tenant.ID,
+		TransactionID: transaction.ID,
+		Amount:        models.NewDecimal(1000),
+		Currency:      "CAD",
+		AmountInBase:  models.NewDecimal(1000),
+		PaymentMethod: models.PaymentMethodCash,
+		Status:        models.PaymentStatusCompleted,
+		PaidBy:        owner.ID,
+		PaidAt:        time.Now(),
+	})
+	ledgerService := services.NewLedgerService(db)
+	for _, entry := range []models.LedgerEntry{
+		{Type: models.LedgerTypeExchangeOut, Currency: "CAD", Amount: models.NewDecimal(-1000)},
+		{Type: models.LedgerTypeExchangeIn, Currency: "USD", Amount: models.NewDecimal(740)},
+	} {
+		entry.TenantID, entry.ClientID, entry.TransactionID, entry.CreatedBy = tenant.ID, client.ID, &transaction.ID, owner.ID
+		entry.CreatedAt = time.Now().Add(-time.Minute)
+		if _, err := ledgerService.AddEntry(entry); err != nil {
+			t.Fatalf("Failed to add ledger entry: %v", err)
+		}
+	}
+
+	cashBalances := services.NewCashBalanceService(db)
+	if _, err := cashBalances.GetOrCreateCashBalance(tenant.ID, nil, "CAD"); err != nil {
+		t.Fatalf("Failed to create cash balance: %v", err)
+	}
+
+	type totals struct {
+		cash         float64
+		ledgerCAD    float64
+		ledgerUSD    float64
+		transactions int64
+	}
+	current := func() totals {
+		t.Helper()
+		balance, err := cashBalances.GetBalanceByCurrency(tenant.ID, nil, "CAD")
+		if err != nil {
+			t.Fatalf("Failed to get cash balance: %v", err)
+		}
+		ledger, err := ledgerService.GetClientBalances(client.ID, tenant.ID)
+		if err != nil {
+			t.Fatalf("Failed to get ledger balances: %v", err)
+		}
+		var count int64
+		db.Model(&models.Transaction{}).Where("tenant_id = ?", tenant.ID).Count(&count)
+		return totals{balance.FinalBalance.Float64(), ledger["CAD"].Float64(), ledger["USD"].Float64(), count}
+	}
+
+	before := current()
+	if before != (totals{cash: 1000, ledgerCAD: -1000, ledgerUSD: 740, transactions: 1}) {
+		t.Fatalf("Unexpected totals before delete: %+v", before)
+	}
+
+	if _, err := transactionService.DeleteTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID); err != nil {
+		t.Fatalf("Failed to delete transaction: %v", err)
+	}
+	if deleted := current(); deleted != (totals{}) {
+		t.Errorf("Expected the delete to reverse every total, got %+v", deleted)
+	}
+	var stored models.Transaction
+	if err := db.Unscoped().First(&stored, "id = ?", transaction.ID).Error; err != nil || !stored.DeletedAt.Valid {
+		t.Errorf("Expected the transaction row to be kept with deleted_at set, got err=%v", err)
+	}
+	if _, err := transactionService.DeleteTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected deleting an already deleted transaction to find nothing, got %v", err)
+	}
+
+	restored, err := transactionService.RestoreTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID)
+	if err != nil {
+		t.Fatalf("Failed to restore transaction: %v", err)
+	}
+	if restored.DeletedAt.Valid {
+		t.Error("Expected the restored transaction to no longer be deleted")
+	}
+	if after := current(); after != before {
+		t.Errorf("Expected the restore to round-trip the totals to %+v, got %+v", before, after)
+	}
+	if _, err := transactionService.RestoreTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID); !errors.Is(err, services.ErrTransactionNotDeleted) {
+		t.Errorf("Expected ErrTransactionNotDeleted, got %v", err)
+	}
+
+	// A second cycle must reverse and re-apply only the original effects
+	if _, err := transactionService.DeleteTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID); err != nil {
+		t.Fatalf("Failed to delete transaction again: %v", err)
+	}
+	if deleted := current(); deleted != (totals{}) {
+		t.Errorf("Expected the second delete to reverse every total, got %+v", deleted)
+	}
+	if _, err := transactionService.RestoreTransaction(context.Background(), transaction.ID, tenant.ID, owner.ID); err != nil {
+		t.Fatalf("Failed to restore transaction again: %v", err)
+	}
+	if after := current(); after != before {
+		t.Errorf("Expected the second restore to round-trip the totals to %+v, got %+v", before, after)
+	}
+}
+
+// TestListTransactions_CursorPagination verifies pages continue from the cursor without overlap or gaps,
+// including transactions that share a date, and that cancelled transactions stay excluded
+func TestListTransactions_CursorPagination(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Paging Tenant"}
+	db.Create(&tenant)
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Paging Client",
+		PhoneNumber: "555-0110",
+	}
+	db.Create(&client)
+
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	create := func(date time.Time, status string) string {
+		transaction := models.Transaction{
+			ID:              uuid.New().String(),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(100),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(73),
+			RateApplied:     models.NewDecimal(0.73),
+			TransactionDate: date,
+			Status:          status,
+		}
+		if err := db.Create(&transaction).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		return transaction.ID
+	}
+
+	expected := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		expected[create(base.Add(time.Duration(i)*time.Hour), models.StatusCompleted)] = true
+	}
+	// Two transactions on the same instant must still page apart by ID
+	expected[create(base.Add(2*time.Hour), models.StatusCompleted)] = true
+	cancelled := create(base.Add(3*time.Hour), models.StatusCancelled)
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+	filter := services.TransactionListFilter{TenantID: &tenant.ID, Limit: 4}
+
+	first, err := transactionService.ListTransactions(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("Failed to list first page: %v", err)
+	}
+	if first.Total != 6 || len(first.Data) != 4 || first.NextCursor == "" {
+		t.Fatalf("Expected 4 of 6 transactions and a cursor, got %d of %d (cursor %q)", len(first.Data), first.Total, first.NextCursor)
+	}
+	if !first.Data[0].TransactionDate.Equal(base.Add(4 * time.Hour)) {
+		t.Errorf("Expected the newest transaction first, got %v", first.Data[0].TransactionDate)
+	}
+
+	filter.Cursor = first.NextCursor
+	second, err := transactionService.ListTransactions(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("Failed to list second page: %v", err)
+	}
+	if len(second.Data) != 2 || second.NextCursor != "" {
+		t.Fatalf("Expected the last 2 transactions and no cursor, got %d (cursor %q)", len(second.Data), second.NextCursor)
+	}
+
+	seen := map[string]bool{}
+	for _, page := range [][]models.Transaction{first.Data, second.Data} {
+		for _, transaction := range page {
+			if transaction.ID == cancelled {
+				t.Error("Expected the cancelled transaction to be excluded")
+			}
+			if seen[transaction.ID] {
+				t.Errorf("Transaction %s appeared on two pages", transaction.ID)
+			}
+			seen[transaction.ID] = true
+		}
+	}
+	for id := range expected {
+		if !seen[id] {
+			t.Errorf("Transaction %s was skipped between pages", id)
+		}
+	}
+
+	if _, err := transactionService.ListTransactions(context.Background(), services.TransactionListFilter{
+		TenantID: &tenant.ID,
+		Cursor:   "not-a-cursor",
+	}); !errors.Is(err, services.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+
+	capped, err := transactionService.ListTransactions(context.Background(), services.TransactionListFilter{TenantID: &tenant.ID, Limit: 10000})
+	if err != nil || capped.Limit != services.MaxTransactionPageSize {
+		t.Errorf("Expected the page size to be capped at %d, got %v (%v)", services.MaxTransactionPageSize, capped, err)
+	}
+}
+
+// TestSearchTransactions_RanksAndScopesFields verifies an exact beneficiary match ranks above a partial
+// currency match, that client names are searched with highlights, and that field scoping restricts the columns searched
+func TestSearchTransactions_RanksAndScopesFields(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Search Tenant"}
+	db.Create(&tenant)
+	other := models.Tenant{Name: "Other Search Tenant"}
+	db.Create(&other)
+
+	create := func(tenantID uint, clientName, beneficiary, receiveCurrency string) string {
+		client := models.Client{
+			ID:          uuid.New().String(),
+			TenantID:    tenantID,
+			Name:        clientName,
+			PhoneNumber: "555-0120",
+		}
+		db.Create(&client)
+		transaction := models.Transaction{
+			ID:              uuid.New().String(),
+			TenantID:        tenantID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(100),
+			ReceiveCurrency: receiveCurrency,
+			ReceiveAmount:   models.NewDecimal(73),
+			RateApplied:     models.NewDecimal(0.73),
+			BeneficiaryName: &beneficiary,
+			TransactionDate: time.Now(),
+		}
+		if err := db.Create(&transaction).Error; err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+		return transaction.ID
+	}
+
+	// Created first so it would lead if results were ordered by date alone
+	partial := create(tenant.ID, "Dana Client", "Someone Else", "USD")
+	byClient := create(tenant.ID, "Gus Traders", "Nobody", "EUR")
+	exact := create(tenant.ID, "Sam Client", "us", "EUR")
+	create(other.ID, "Other Client", "US", "USD")
+
+	transactionService := services.NewTransactionService(db, services.NewExchangeRateService(db))
+
+	results, err := transactionService.SearchTransactions(context.Background(), &tenant.ID, "US", nil, 0)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results from the tenant, got %d", len(results))
+	}
+	if results[0].ID != exact || results[0].Score <= results[1].Score {
+		t.Errorf("Expected the exact beneficiary match to rank first, got %s (score %d)", results[0].ID, results[0].Score)
+	}
+	if results[1].ID != partial || results[2].ID != byClient {
+		t.Errorf("Expected the currency prefix match before the client name match, got %s then %s", results[1].ID, results[2].ID)
+	}
+	if len(results[0].Highlights) != 1 || results[0].Highlights[0] != (services.SearchHighlight{Field: "beneficiary_name", Start: 0, End: 2}) {
+		t.Errorf("Expected a beneficiary highlight at 0-2, got %+v", results[0].Highlights)
+	}
+	if len(results[2].Highlights) != 1 || results[2].Highlights[0] != (services.SearchHighlight{Field: "client_name", Start: 1, End: 3}) {
+		t.Errorf("Expected a client name highlight at 1-3, got %+v", results[2].Highlights)
+	}
+
+	scoped, err := transactionService.SearchTransactions(context.Background(), &tenant.ID, "US", []string{"beneficiary_name"}, 0)
+	if err != nil {
+		t.Fatalf("Failed to search scoped: %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].ID != exact {
+		t.Errorf("Expected only the beneficiary match when scoped, got %d results", len(scoped))
+	}
+
+	// LIKE wildcards in the query match literally
+	for _, query := range []string{"%", "_s"} {
+		if wildcard, err := transactionService.SearchTransactions(context.Background(), &tenant.ID, query, nil, 0); err != nil || len(wildcard) != 0 {
+			t.Errorf("Expected no results for %q, got %d (%v)", query, len(wildcard), err)
+		}
+	}
+
+	if _, err := transactionService.SearchTransactions(context.Background(), &tenant.ID, "US", []string{"password"}, 0); !errors.Is(err, services.ErrInvalidSearchField) {
+		t.Errorf("Expected ErrInvalidSearchField, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TransactionTemplateService manages saved templates for repeat exchanges
+type TransactionTemplateService struct {
+	db *gorm.DB
+}
+
+// NewTransactionTemplateService creates a new TransactionTemplateService
+func NewTransactionTemplateService(db *gorm.DB) *TransactionTemplateService {
+	return &TransactionTemplateService{db: db}
+}
+
+// TransactionTemplateRequest is the body for creating or updating a transaction template
+type TransactionTemplateRequest struct {
+	Name               string         `json:"name"`
+	ClientID           string         `json:"clientId"`
+	BranchID           *uint          `json:"branchId"` // Omit to share the template across branches
+	PaymentMethod      string         `json:"paymentMethod"`
+	SendCurrency       string         `json:"sendCurrency"`
+	ReceiveCurrency    string         `json:"receiveCurrency"`
+	FeeCharged         models.Decimal `json:"feeCharged"`
+	BeneficiaryName    *string        `json:"beneficiaryName"`
+	BeneficiaryDetails *string        `json:"beneficiaryDetails"`
+	UserNotes          *string        `json:"userNotes"`
+}
+
+// apply validates the request against the tenant and copies it onto the template
+func (s *TransactionTemplateService) apply(tenantID uint, req TransactionTemplateRequest, template *models.TransactionTemplate) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	sendCurrency := strings.ToUpper(strings.TrimSpace(req.SendCurrency))
+	receiveCurrency := strings.ToUpper(strings.TrimSpace(req.ReceiveCurrency))
+	if len(sendCurrency) != 3 || len(receiveCurrency) != 3 || sendCurrency == receiveCurrency {
+		return errors.New("a template needs two different 3-letter currencies")
+	}
+
+	paymentMethod := strings.ToUpper(strings.TrimSpace(req.PaymentMethod))
+	switch paymentMethod {
+	case "":
+		paymentMethod = models.TransactionMethodCash
+	case models.TransactionMethodCash, models.TransactionMethodBank:
+	default:
+		return errors.New("payment method must be CASH_EXCHANGE or BANK_TRANSFER")
+	}
+
+	if req.FeeCharged.IsNegative() {
+		return errors.New("fee cannot be negative")
+	}
+
+	var clients int64
+	if err := s.db.Model(&models.Client{}).Where("id = ? AND tenant_id = ?", req.ClientID, tenantID).Count(&clients).Error; err != nil {
+		return err
+	}
+	if clients == 0 {
+		return errors.New("client not found")
+	}
+	if req.BranchID != nil {
+		var branches int64
+		if err := s.db.Model(&models.Branch{}).Where("id = ? AND tenant_id = ?", *req.BranchID, tenantID).Count(&branches).Error; err != nil {
+			return err
+		}
+		if branches == 0 {
+			return errors.New("branch not found")
+		}
+	}
+
+	template.Name = name
+	template.ClientID = req.ClientID
+	template.BranchID = req.BranchID
+	template.PaymentMethod = paymentMethod
+	template.SendCurrency = sendCurrency
+	template.ReceiveCurrency = receiveCurrency
+	template.FeeCharged = req.FeeCharged
+	template.BeneficiaryName = req.BeneficiaryName
+	template.BeneficiaryDetails = req.BeneficiaryDetails
+	template.UserNotes = req.UserNotes
+	return nil
+}
+
+// GetTemplates lists the tenant's templates by name. With a branch, only that branch's templates and
+// those shared across branches are returned.
+func (s *TransactionTemplateService) GetTemplates(tenantID uint, branchID *uint) ([]models.TransactionTemplate, error) {
+	query := s.db.Where("tenant_id = ?", tenantID)
+	if branchID != nil {
+		query = query.Where("branch_id IS NULL OR branch_id = ?", *branchID)
+	}
+	var templates []models.TransactionTemplate
+	err := query.Preload("Client").Order("name ASC").Find(&templates).Error
+	return templates, err
+}
+
+// GetTemplate returns one of the tenant's templates
+func (s *TransactionTemplateService) GetTemplate(tenantID, templateID uint) (*models.TransactionTemplate, error) {
+	var template models.TransactionTemplate
+	if err := s.db.Where("id = ? AND tenant_id = ?", templateID, tenantID).Preload("Client").First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateTemplate saves a new template for one of the tenant's clients
+func (s *TransactionTemplateService) CreateTemplate(tenantID uint, req TransactionTemplateRequest, userID uint) (*models.TransactionTemplate, error) {
+	template := &models.TransactionTemplate{TenantID: tenantID, CreatedBy: userID}
+	if err := s.apply(tenantID, req, template); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(template).Error; err != nil {
+		return nil, err
+	}
+	return s.GetTemplate(tenantID, template.ID)
+}
+
+// UpdateTemplate replaces a template's details
+func (s *TransactionTemplateService) UpdateTemplate(tenantID, templateID uint, req TransactionTemplateRequest) (*models.TransactionTemplate, error) {
+	template, err := s.GetTemplate(tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(tenantID, req, template); err != nil {
+		return nil, err
+	}
+	template.Client = nil
+	if err := s.db.Save(template).Error; err != nil {
+		return nil, err
+	}
+	return s.GetTemplate(tenantID, templateID)
+}
+
+// DeleteTemplate removes a template; transactions created from it are unaffected
+func (s *TransactionTemplateService) DeleteTemplate(tenantID, templateID uint) error {
+	result := s.db.Where("id = ? AND tenant_id = ?", templateID, tenantID).Delete(&models.TransactionTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// NewTransaction prefills an unsaved transaction from a template. The amounts and rate are left for the
+// teller to enter before the transaction is created as usual.
+func (s *TransactionTemplateService) NewTransaction(tenantID, templateID uint) (*models.Transaction, error) {
+	template, err := s.GetTemplate(tenantID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Transaction{
+		TenantID:           template.TenantID,
+		BranchID:           template.BranchID,
+		ClientID:           template.ClientID,
+		PaymentMethod:      template.PaymentMethod,
+		SendCurrency:       template.SendCurrency,
+		ReceiveCurrency:    template.ReceiveCurrency,
+		FeeCharged:         template.FeeCharged,
+		BeneficiaryName:    template.BeneficiaryName,
+		BeneficiaryDetails: template.BeneficiaryDetails,
+		UserNotes:          template.UserNotes,
+		Client:             template.Client,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"api/pkg/models"
+	"api/pkg/services"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TestTransactionTemplate_PrefillsTransaction verifies a new transaction is prefilled from a template with the
+// amounts and rate left blank, and that another tenant cannot see or use the template or the client
+func TestTransactionTemplate_PrefillsTransaction(t *testing.T) {
+	db := setupTestDB(t)
+
+	tenant := models.Tenant{Name: "Template Tenant"}
+	db.Create(&tenant)
+	other := models.Tenant{Name: "Other Template Tenant"}
+	db.Create(&other)
+	branch := models.Branch{TenantID: tenant.ID, Name: "Downtown", BranchCode: "DT"}
+	db.Create(&branch)
+	client := models.Client{
+		ID:          uuid.New().String(),
+		TenantID:    tenant.ID,
+		Name:        "Regular Customer",
+		PhoneNumber: "555-0130",
+	}
+	db.Create(&client)
+
+	service := services.NewTransactionTemplateService(db)
+	beneficiary := "Reza Ahmadi"
+	notes := "Monthly family support"
+	template, err := service.CreateTemplate(tenant.ID, services.TransactionTemplateRequest{
+		Name:            "Monthly to Tehran",
+		ClientID:        client.ID,
+		BranchID:        &branch.ID,
+		SendCurrency:    "cad",
+		ReceiveCurrency: "irr",
+		FeeCharged:      models.NewDecimal(10),
+		BeneficiaryName: &beneficiary,
+		UserNotes:       &notes,
+	}, 1)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	if template.SendCurrency != "CAD" || template.ReceiveCurrency != "IRR" || template.PaymentMethod != models.TransactionMethodCash {
+		t.Errorf("Expected normalized currencies and the cash default, got %s/%s %s", template.SendCurrency, template.ReceiveCurrency, template.PaymentMethod)
+	}
+
+	draft, err := service.NewTransaction(tenant.ID, template.ID)
+	if err != nil {
+		t.Fatalf("Failed to prefill transaction: %v", err)
+	}
+	if draft.ClientID != client.ID || draft.TenantID != tenant.ID || draft.BranchID == nil || *draft.BranchID != branch.ID {
+		t.Errorf("Expected the template's client and branch, got client %s branch %v", draft.ClientID, draft.BranchID)
+	}
+	if draft.SendCurrency != "CAD" || draft.ReceiveCurrency != "IRR" || draft.FeeCharged.Float64() != 10 {
+		t.Errorf("Expected CAD/IRR with a 10 fee, got %s/%s %v", draft.SendCurrency, draft.ReceiveCurrency, draft.FeeCharged.Float64())
+	}
+	if draft.BeneficiaryName == nil || *draft.BeneficiaryName != beneficiary || draft.UserNotes == nil || *draft.UserNotes != notes {
+		t.Errorf("Expected the beneficiary and notes to be prefilled, got %v / %v", draft.BeneficiaryName, draft.UserNotes)
+	}
+	if draft.ID != "" || !draft.SendAmount.IsZero() || !draft.ReceiveAmount.IsZero() || !draft.RateApplied.IsZero() {
+		t.Errorf("Expected an unsaved draft with the amounts and rate left blank, got %+v", draft)
+	}
+
+	otherBranch := branch.ID + 1
+	if templates, err := service.GetTemplates(tenant.ID, &otherBranch); err != nil || len(templates) != 0 {
+		t.Errorf("Expected a branch template to be hidden from other branches, got %d (%v)", len(templates), err)
+	}
+
+	// Tenant isolation
+	if _, err := service.NewTransaction(other.ID, template.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected another tenant not to find the template, got %v", err)
+	}
+	if templates, err := service.GetTemplates(other.ID, nil); err != nil || len(templates) != 0 {
+		t.Errorf("Expected another tenant to list no templates, got %d (%v)", len(templates), err)
+	}
+	if err := service.DeleteTemplate(other.ID, template.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected another tenant not to delete the template, got %v", err)
+	}
+	if _, err := service.CreateTemplate(other.ID, services.TransactionTemplateRequest{
+		Name:            "Borrowed client",
+		ClientID:        client.ID,
+		SendCurrency:    "CAD",
+		ReceiveCurrency: "IRR",
+	}, 1); err == nil {
+		t.Error("Expected another tenant not to template this tenant's client")
+	}
+}
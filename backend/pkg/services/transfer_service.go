@@ -83,7 +83,7 @@ func (s *TransferService) CreateTransfer(tenantID, sourceBranchID, destBranchID
 
 	txCashService := NewCashBalanceService(tx)
 
-	_, err = txCashService.CreateManualAdjustment(
+	_, err = txCashService.CreateAppliedAdjustment(
 		tenantID,
 		&sourceBranchID,
 		currency,
@@ -144,7 +144,7 @@ func (s *TransferService) AcceptTransfer(transferID, tenantID, acceptedBy uint)
 
 	// Add to Destination Branch
 	txCashService := NewCashBalanceService(tx)
-	_, err := txCashService.CreateManualAdjustment(
+	_, err := txCashService.CreateAppliedAdjustment(
 		tenantID,
 		&transfer.DestinationBranchID,
 		transfer.Currency,
@@ -202,7 +202,7 @@ func (s *TransferService) CancelTransfer(transferID, tenantID, cancelledBy uint)
 
 	// Refund Source Branch
 	txCashService := NewCashBalanceService(tx)
-	_, err := txCashService.CreateManualAdjustment(
+	_, err := txCashService.CreateAppliedAdjustment(
 		tenantID,
 		&transfer.SourceBranchID,
 		transfer.Currency,
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"api/pkg/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// twoFactorIssuer names the account in authenticator apps and marks 2FA challenge tokens
+	twoFactorIssuer = "digital-transaction-ledger-2fa"
+	// twoFactorChallengeTTL is how long the user has to enter a code after the password step
+	twoFactorChallengeTTL = 5 * time.Minute
+	// recoveryCodeCount is how many single-use recovery codes are issued on activation
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling a user who already has 2FA active
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnrolled is returned when activating before EnableTwoFactor was called
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication has not been set up")
+	// ErrInvalidTwoFactorCode is returned when a TOTP or recovery code does not match
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrInvalidChallenge is returned when a 2FA challenge token is malformed or expired
+	ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+)
+
+// TwoFactorSetup is returned on enrollment for the user to add to their authenticator app
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+// EnableTwoFactor generates a new TOTP secret for the user. 2FA is not enforced until the first
+// code is confirmed with VerifyAndActivateTwoFactor.
+func (as *AuthService) EnableTwoFactor(userID uint) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := as.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := as.DB.Model(&user).Updates(map[string]interface{}{
+		"two_factor_secret":    secret,
+		"two_factor_last_step": 0,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to store two-factor secret: %w", err)
+	}
+
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI("Digital Ledger", user.Email, secret),
+	}, nil
+}
+
+// VerifyAndActivateTwoFactor confirms the first code from the authenticator app, turns 2FA on and
+// returns the recovery codes. The codes are only ever shown here.
+func (as *AuthService) VerifyAndActivateTwoFactor(userID uint, code string) ([]string, error) {
+	var user models.User
+	if err := as.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil || *user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := validateTOTP(*user.TwoFactorSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	var codes []string
+	err := as.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":   true,
+			"two_factor_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to activate two-factor authentication: %w", err)
+	}
+
+	log.Printf("✅ Two-factor authentication enabled for user: %s", user.Email)
+	return codes, nil
+}
+
+// DisableTwoFactor turns 2FA off after checking a current TOTP or recovery code
+func (as *AuthService) DisableTwoFactor(userID uint, code string) error {
+	var user models.User
+	if err := as.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnrolled
+	}
+	if err := as.checkSecondFactor(&user, code); err != nil {
+		return err
+	}
+
+	return as.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":   false,
+			"two_factor_secret":    nil,
+			"two_factor_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.TwoFactorRecoveryCode{}).Error
+	})
+}
+
+// generateTwoFactorChallenge issues the short-lived token that stands in for the password step
+func (as *AuthService) generateTwoFactorChallenge(user *models.User) (string, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		ExpiresAt: jwt.NewNumericDate(now.Add(twoFactorChallengeTTL)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Issuer:    twoFactorIssuer,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(as.JWTSecret))
+}
+
+// Verify2FA completes a login started with a password by checking a TOTP or recovery code
+// against the challenge token, returning the access/refresh pair on success
+func (as *AuthService) Verify2FA(challengeToken, code string) (*LoginResponse, error) {
+	token, err := jwt.ParseWithClaims(challengeToken, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(as.JWTSecret), nil
+	})
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || !token.Valid || claims.Issuer != twoFactorIssuer {
+		return nil, ErrInvalidChallenge
+	}
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	var user models.User
+	if err := as.DB.Preload("Tenant").Preload("PrimaryBranch").First(&user, uint(userID)).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.Status == models.StatusSuspended {
+		return nil, errors.New("account is suspended. Please contact support")
+	}
+	if !user.TwoFactorEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	// Wrong codes count toward the same lockout as wrong passwords
+	now := time.Now()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return nil, &AccountLockedError{Until: *user.LockedUntil}
+	}
+	if err := as.checkSecondFactor(&user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if lockErr := as.recordFailedLogin(&user, now); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+	if err := as.clearFailedLogins(&user); err != nil {
+		return nil, err
+	}
+
+	return as.generateLoginResponse(&user)
+}
+
+// checkSecondFactor accepts a TOTP code not used before, or consumes an unused recovery code
+func (as *AuthService) checkSecondFactor(user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if user.TwoFactorSecret != nil {
+		if step, ok := validateTOTP(*user.TwoFactorSecret, code, time.Now()); ok {
+			// Only advance if this step is newer than the last one used, so a code works once
+			result := as.DB.Model(&models.User{}).
+				Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+				Update("two_factor_last_step", step)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrInvalidTwoFactorCode
+			}
+			user.TwoFactorLastStep = step
+			return nil
+		}
+	}
+
+	result := as.DB.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	log.Printf("⚠️ Recovery code used for user: %s", user.Email)
+	return nil
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores a fresh set, returning them in plain text
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := generateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(raw[:5] + "-" + raw[5:10])
+		if err := tx.Create(&models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code for storage and lookup
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"api/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserPreferencesService manages each user's personal dashboard and report defaults
+type UserPreferencesService struct {
+	db *gorm.DB
+}
+
+// NewUserPreferencesService creates a new UserPreferencesService
+func NewUserPreferencesService(db *gorm.DB) *UserPreferencesService {
+	return &UserPreferencesService{db: db}
+}
+
+// UpdateUserPreferencesRequest contains the preferences to change; nil fields are left untouched.
+// Custom dates are YYYY-MM-DD and required when the default range is custom.
+type UpdateUserPreferencesRequest struct {
+	DefaultDateRange *string `json:"defaultDateRange"`
+	CustomStartDate  *string `json:"customStartDate"`
+	CustomEndDate    *string `json:"customEndDate"`
+}
+
+// GetPreferences returns the user's preferences, or empty preferences if none are stored
+func (s *UserPreferencesService) GetPreferences(userID uint) (*models.UserPreferences, error) {
+	var preferences models.UserPreferences
+	err := s.db.Where("user_id = ?", userID).First(&preferences).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.UserPreferences{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// UpdatePreferences validates and applies the given changes, creating the row on first use
+func (s *UserPreferencesService) UpdatePreferences(userID uint, req UpdateUserPreferencesRequest) (*models.UserPreferences, error) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DefaultDateRange != nil {
+		switch dateRange := strings.ToLower(strings.TrimSpace(*req.DefaultDateRange)); dateRange {
+		case models.DateRangeNone, models.DateRangeToday, models.DateRange7Days, models.DateRange30Days, models.DateRangeCustom:
+			preferences.DefaultDateRange = dateRange
+		default:
+			return nil, fmt.Errorf("unsupported default date range: %s", *req.DefaultDateRange)
+		}
+	}
+	if req.CustomStartDate != nil {
+		if preferences.CustomStartDate, err = parsePreferenceDate(*req.CustomStartDate); err != nil {
+			return nil, fmt.Errorf("invalid custom start date: %w", err)
+		}
+	}
+	if req.CustomEndDate != nil {
+		if preferences.CustomEndDate, err = parsePreferenceDate(*req.CustomEndDate); err != nil {
+			return nil, fmt.Errorf("invalid custom end date: %w", err)
+		}
+	}
+
+	if preferences.DefaultDateRange == models.DateRangeCustom {
+		if preferences.CustomStartDate == nil || preferences.CustomEndDate == nil {
+			return nil, errors.New("a custom date range needs both a start and an end date")
+		}
+		if preferences.CustomEndDate.Before(*preferences.CustomStartDate) {
+			return nil, errors.New("custom start date must not be after the end date")
+		}
+	}
+
+	if err := s.db.Save(preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// DefaultDateRange returns the user's preferred date range as of now; ok is false if they have none
+func (s *UserPreferencesService) DefaultDateRange(userID uint, now time.Time) (start, end time.Time, ok bool) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return preferences.DateRange(now)
+}
+
+// parsePreferenceDate parses a YYYY-MM-DD date; an empty string clears it
+func parsePreferenceDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestUserPreferences_DefaultDateRangeScopesResults verifies a 7-day default limits an unranged
+// request to the last seven days
+func TestUserPreferences_DefaultDateRangeScopesResults(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.User{}, &models.Branch{}, &models.Client{}, &models.Transaction{},
+		&models.UserPreferences{})
+
+	tenant := newTestTenant(t, db, "Preference Exchange")
+	user := newTestUser(t, db, tenant.ID, "prefs@test.com", models.RoleTenantUser)
+	client := &models.Client{ID: "client-prefs", TenantID: tenant.ID, Name: "Prefs Client", PhoneNumber: "+14165550126"}
+	db.Create(client)
+
+	now := time.Date(2025, 10, 20, 15, 0, 0, 0, time.UTC)
+	for i, daysAgo := range []int{0, 6, 7, 20} {
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-prefs-%d", i),
+			TenantID:        tenant.ID,
+			ClientID:        client.ID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(1000),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(730),
+			RateApplied:     models.NewDecimal(0.73),
+			Profit:          models.NewDecimal(10),
+			Status:          models.StatusCompleted,
+			TransactionDate: now.AddDate(0, 0, -daysAgo).Add(-time.Hour),
+		})
+	}
+
+	service := NewUserPreferencesService(db)
+	if _, _, ok := service.DefaultDateRange(user.ID, now); ok {
+		t.Fatal("Expected no default range before the user sets one")
+	}
+
+	sevenDays := models.DateRange7Days
+	if _, err := service.UpdatePreferences(user.ID, UpdateUserPreferencesRequest{DefaultDateRange: &sevenDays}); err != nil {
+		t.Fatalf("Failed to save preferences: %v", err)
+	}
+
+	start, end, ok := service.DefaultDateRange(user.ID, now)
+	if !ok {
+		t.Fatal("Expected the saved 7-day default to apply")
+	}
+	if want := time.Date(2025, 10, 14, 0, 0, 0, 0, time.UTC); !start.Equal(want) || !end.Equal(now) {
+		t.Errorf("Expected range %v - %v, got %v - %v", want, now, start, end)
+	}
+
+	methods := NewProfitAnalysisService(db).GetProfitByPaymentMethod(tenant.ID, nil, start, end)
+	if len(methods) != 1 || methods[0].TransactionCount != 2 {
+		t.Errorf("Expected only the 2 transactions from the last seven days, got %+v", methods)
+	}
+
+	t.Run("CustomRangeNeedsDates", func(t *testing.T) {
+		custom := models.DateRangeCustom
+		if _, err := service.UpdatePreferences(user.ID, UpdateUserPreferencesRequest{DefaultDateRange: &custom}); err == nil {
+			t.Error("Expected an error for a custom range without dates")
+		}
+
+		startDate, endDate := "2025-09-01", "2025-09-30"
+		preferences, err := service.UpdatePreferences(user.ID, UpdateUserPreferencesRequest{
+			DefaultDateRange: &custom,
+			CustomStartDate:  &startDate,
+			CustomEndDate:    &endDate,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save custom range: %v", err)
+		}
+		_, end, _ := preferences.DateRange(now)
+		if want := time.Date(2025, 9, 30, 23, 59, 59, 0, time.UTC); !end.Equal(want) {
+			t.Errorf("Expected custom range to end %v, got %v", want, end)
+		}
+	})
+
+	t.Run("UnsupportedRange", func(t *testing.T) {
+		weekly := "weekly"
+		if _, err := service.UpdatePreferences(user.ID, UpdateUserPreferencesRequest{DefaultDateRange: &weekly}); err == nil {
+			t.Error("Expected an error for an unsupported range")
+		}
+	})
+}
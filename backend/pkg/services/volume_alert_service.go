@@ -0,0 +1,207 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// volumeWindow is the length of the recent window compared against the customer's baseline
+const volumeWindow = 7 * 24 * time.Hour
+
+// VolumeAlertService raises compliance alerts when a customer's recent volume spikes above their baseline
+type VolumeAlertService struct {
+	db              *gorm.DB
+	settingsService *TenantSettingsService
+	rateService     *ExchangeRateService
+}
+
+// NewVolumeAlertService creates a new VolumeAlertService
+func NewVolumeAlertService(db *gorm.DB) *VolumeAlertService {
+	return &VolumeAlertService{
+		db:              db,
+		settingsService: NewTenantSettingsService(db),
+		rateService:     NewExchangeRateService(db),
+	}
+}
+
+// clientVolume is a customer's volume in the recent window and the baseline period before it
+type clientVolume struct {
+	recent   models.Decimal
+	baseline models.Decimal
+}
+
+// CheckTenant compares each customer's volume over the 7 days before now with their weekly average
+// over the baseline period and raises an alert for those above the tenant's multiple.
+// Customers with no baseline activity, or an alert already open for the same window, are skipped.
+func (s *VolumeAlertService) CheckTenant(tenantID uint, now time.Time) ([]models.ComplianceAlert, error) {
+	settings, err := s.settingsService.GetSettings(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.VolumeSpikeMultiple.IsPositive() || settings.VolumeBaselineWeeks <= 0 {
+		return nil, nil
+	}
+
+	windowStart := now.Add(-volumeWindow)
+	baselineStart := windowStart.Add(-time.Duration(settings.VolumeBaselineWeeks) * volumeWindow)
+
+	volumes, err := s.clientVolumes(tenantID, settings.BaseCurrency, baselineStart, windowStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	weeks := models.NewDecimal(float64(settings.VolumeBaselineWeeks))
+	alerts := make([]models.ComplianceAlert, 0)
+	for clientID, volume := range volumes {
+		if !volume.baseline.IsPositive() {
+			continue
+		}
+		weeklyAverage := volume.baseline.Div(weeks)
+		if !volume.recent.GreaterThan(weeklyAverage.Mul(settings.VolumeSpikeMultiple)) {
+			continue
+		}
+
+		var existing int64
+		s.db.Model(&models.ComplianceAlert{}).
+			Where("tenant_id = ? AND client_id = ? AND alert_type = ? AND status = ? AND window_end > ?",
+				tenantID, clientID, models.ComplianceAlertVolumeSpike, models.ComplianceAlertStatusOpen, windowStart).
+			Count(&existing)
+		if existing > 0 {
+			continue
+		}
+
+		multiple := volume.recent.Div(weeklyAverage).Round(2)
+		alert := models.ComplianceAlert{
+			TenantID:       tenantID,
+			ClientID:       clientID,
+			AlertType:      models.ComplianceAlertVolumeSpike,
+			Status:         models.ComplianceAlertStatusOpen,
+			Currency:       settings.BaseCurrency,
+			RecentVolume:   volume.recent.Round(2),
+			BaselineVolume: weeklyAverage.Round(2),
+			Multiple:       multiple,
+			WindowStart:    windowStart,
+			WindowEnd:      now,
+			Details: fmt.Sprintf("7-day volume of %s %s is %sx the %d-week weekly average of %s %s",
+				volume.recent.StringFixed(2), settings.BaseCurrency, multiple.String(),
+				settings.VolumeBaselineWeeks, weeklyAverage.StringFixed(2), settings.BaseCurrency),
+		}
+		if err := s.db.Create(&alert).Error; err != nil {
+			return alerts, fmt.Errorf("failed to record volume alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+// clientVolumes sums each customer's completed transactions in base currency, split into the
+// baseline period [baselineStart, windowStart) and the recent window [windowStart, now)
+func (s *VolumeAlertService) clientVolumes(tenantID uint, baseCurrency string, baselineStart, windowStart, now time.Time) (map[string]*clientVolume, error) {
+	var transactions []models.Transaction
+	if err := s.db.Select("client_id", "send_currency", "send_amount", "transaction_date").
+		Where("tenant_id = ? AND status = ? AND transaction_date >= ? AND transaction_date < ?",
+			tenantID, models.StatusCompleted, baselineStart, now).
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]models.Decimal)
+	volumes := make(map[string]*clientVolume)
+	for _, transaction := range transactions {
+		rate, ok := rates[transaction.SendCurrency]
+		if !ok {
+			var err error
+			rate, err = s.rateService.GetHistoricalRate(tenantID, transaction.SendCurrency, baseCurrency, now)
+			if err != nil {
+				log.Printf("⚠️  Volume alert: skipping %s transactions for tenant %d: %v", transaction.SendCurrency, tenantID, err)
+			}
+			rates[transaction.SendCurrency] = rate
+		}
+		if !rate.IsPositive() {
+			continue
+		}
+
+		volume, ok := volumes[transaction.ClientID]
+		if !ok {
+			volume = &clientVolume{}
+			volumes[transaction.ClientID] = volume
+		}
+		amount := transaction.SendAmount.Mul(rate)
+		if transaction.TransactionDate.Before(windowStart) {
+			volume.baseline = volume.baseline.Add(amount)
+		} else {
+			volume.recent = volume.recent.Add(amount)
+		}
+	}
+	return volumes, nil
+}
+
+// GetAlerts returns the tenant's compliance alerts, newest first, optionally filtered by status
+func (s *VolumeAlertService) GetAlerts(tenantID uint, status string, limit int) ([]models.ComplianceAlert, error) {
+	var alerts []models.ComplianceAlert
+	query := s.db.Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Preload("Client").Order("created_at DESC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// ResolveAlert closes an open alert after review
+func (s *VolumeAlertService) ResolveAlert(tenantID, alertID, userID uint) error {
+	now := time.Now()
+	result := s.db.Model(&models.ComplianceAlert{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", alertID, tenantID, models.ComplianceAlertStatusOpen).
+		Updates(map[string]interface{}{
+			"status":              models.ComplianceAlertStatusResolved,
+			"resolved_at":         now,
+			"resolved_by_user_id": userID,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CheckAll runs the volume check for every active tenant
+func (s *VolumeAlertService) CheckAll(now time.Time) error {
+	var tenantIDs []uint
+	if err := s.db.Model(&models.Tenant{}).
+		Where("status IN ?", []string{models.TenantStatusActive, models.TenantStatusTrial}).
+		Pluck("id", &tenantIDs).Error; err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenantIDs {
+		if _, err := s.CheckTenant(tenantID, now); err != nil {
+			log.Printf("⚠️  Volume alert check failed for tenant %d: %v", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// Start runs the volume check on the given interval in the background
+func (s *VolumeAlertService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Volume alert scheduler started (every %v)", interval)
+
+		for range ticker.C {
+			if err := s.CheckAll(time.Now()); err != nil {
+				log.Printf("⚠️  Volume alert check failed: %v", err)
+			}
+		}
+	}()
+}
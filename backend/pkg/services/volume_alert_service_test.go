@@ -0,0 +1,80 @@
+package services
+
+import (
+	"api/pkg/models"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestVolumeAlert_SpikeTriggersAlert verifies a sudden 5x jump over the weekly average raises one alert
+func TestVolumeAlert_SpikeTriggersAlert(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.Client{}, &models.Transaction{}, &models.ExchangeRate{},
+		&models.TenantSettings{}, &models.ComplianceAlert{})
+
+	tenant := newTestTenant(t, db, "Volume Exchange")
+	spiking := &models.Client{ID: "client-spike", TenantID: tenant.ID, Name: "Spiking Client", PhoneNumber: "+14165550201"}
+	steady := &models.Client{ID: "client-steady", TenantID: tenant.ID, Name: "Steady Client", PhoneNumber: "+14165550202"}
+	db.Create(spiking)
+	db.Create(steady)
+
+	now := time.Now()
+	seq := 0
+	record := func(clientID string, amount float64, at time.Time) {
+		seq++
+		db.Create(&models.Transaction{
+			ID:              fmt.Sprintf("txn-volume-%d", seq),
+			TenantID:        tenant.ID,
+			ClientID:        clientID,
+			PaymentMethod:   models.TransactionMethodCash,
+			SendCurrency:    "CAD",
+			SendAmount:      models.NewDecimal(amount),
+			ReceiveCurrency: "USD",
+			ReceiveAmount:   models.NewDecimal(amount * 0.7),
+			RateApplied:     models.NewDecimal(0.7),
+			TransactionDate: at,
+			Status:          models.StatusCompleted,
+		})
+	}
+
+	// Eight baseline weeks of 1,000 CAD each for both clients
+	for week := 1; week <= 8; week++ {
+		at := now.AddDate(0, 0, -7*week-3)
+		record(spiking.ID, 1000, at)
+		record(steady.ID, 1000, at)
+	}
+	// This week: the spiking client does 5,000 CAD, the steady one keeps to 1,200 CAD
+	record(spiking.ID, 3000, now.AddDate(0, 0, -2))
+	record(spiking.ID, 2000, now.AddDate(0, 0, -1))
+	record(steady.ID, 1200, now.AddDate(0, 0, -1))
+
+	service := NewVolumeAlertService(db)
+	alerts, err := service.CheckTenant(tenant.ID, now)
+	if err != nil {
+		t.Fatalf("CheckTenant failed: %v", err)
+	}
+
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.ClientID != spiking.ID {
+		t.Errorf("Expected alert for %s, got %s", spiking.ID, alert.ClientID)
+	}
+	if alert.AlertType != models.ComplianceAlertVolumeSpike || alert.Status != models.ComplianceAlertStatusOpen {
+		t.Errorf("Unexpected alert type/status %s/%s", alert.AlertType, alert.Status)
+	}
+	if alert.RecentVolume.Float64() != 5000 || alert.BaselineVolume.Float64() != 1000 || alert.Multiple.Float64() != 5 {
+		t.Errorf("Expected 5000 vs 1000 (5x), got %s vs %s (%sx)",
+			alert.RecentVolume.String(), alert.BaselineVolume.String(), alert.Multiple.String())
+	}
+
+	// Running again in the same window does not duplicate the open alert
+	again, err := service.CheckTenant(tenant.ID, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Second CheckTenant failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected no duplicate alert, got %d", len(again))
+	}
+}
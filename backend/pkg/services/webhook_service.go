@@ -0,0 +1,508 @@
+package services
+
+import (
+	"api/pkg/models"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the webhook body
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// maxWebhookBackoff caps the wait between retries however many attempts have failed
+const maxWebhookBackoff = 6 * time.Hour
+
+// WebhookEventTypes are the domain events forwarded to webhook endpoints
+var WebhookEventTypes = []string{
+	EventSettlementCreated,
+	EventTransactionLarge,
+	EventTicketSLABreached,
+}
+
+// ErrWebhookTargetNotAllowed is returned for webhook URLs that point at loopback, private or link-local addresses
+var ErrWebhookTargetNotAllowed = errors.New("webhook URL must not point at a loopback, private or link-local address")
+
+// WebhookService queues and delivers event notifications to tenant webhook endpoints
+type WebhookService struct {
+	db          *gorm.DB
+	MaxAttempts int
+	// RetryBackoff is the wait after the first failed attempt; it doubles after each further failure
+	RetryBackoff time.Duration
+	HTTPClient   *http.Client
+	// AllowPrivateTargets lets endpoints point at internal addresses, for local development only
+	AllowPrivateTargets bool
+}
+
+// NewWebhookService creates a new WebhookService. Endpoints on internal addresses are refused unless
+// WEBHOOK_ALLOW_PRIVATE_TARGETS=true.
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	s := &WebhookService{
+		db:                  db,
+		MaxAttempts:         5,
+		RetryBackoff:        time.Minute,
+		AllowPrivateTargets: getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false") == "true",
+	}
+	// Check the address actually dialed as well, so a hostname that resolves somewhere internal after
+	// the endpoint was saved, or a redirect to one, is still refused
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !s.AllowPrivateTargets && blockedWebhookIP(net.ParseIP(host)) {
+				return ErrWebhookTargetNotAllowed
+			}
+			return nil
+		},
+	}
+	s.HTTPClient = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+	return s
+}
+
+// blockedWebhookIP reports whether an address is internal: loopback, private, link-local (including the
+// 169.254.169.254 metadata service), multicast or unspecified
+func blockedWebhookIP(ip net.IP) bool {
+	return ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// checkWebhookHost refuses a host that is, or resolves to, an internal address
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedWebhookIP(ip) {
+			return ErrWebhookTargetNotAllowed
+		}
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("webhook host %q could not be resolved", host)
+	}
+	for _, ip := range ips {
+		if blockedWebhookIP(ip) {
+			return ErrWebhookTargetNotAllowed
+		}
+	}
+	return nil
+}
+
+// WebhookEndpointRequest is the body for creating or updating a webhook endpoint
+type WebhookEndpointRequest struct {
+	URL      string   `json:"url"`
+	Events   []string `json:"events"` // Empty or "*" subscribes to every webhook event
+	IsActive *bool    `json:"isActive"`
+}
+
+// CreatedWebhookEndpoint is a new endpoint together with its signing secret, which is not retrievable later
+type CreatedWebhookEndpoint struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// normalizeWebhookEndpoint validates the URL and subscribed events of a request. Unless allowPrivate is
+// set, a URL whose host is or resolves to an internal address is refused.
+func normalizeWebhookEndpoint(req WebhookEndpointRequest, allowPrivate bool) (string, string, error) {
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return "", "", errors.New("a valid http or https URL is required")
+	}
+	if !allowPrivate {
+		if err := checkWebhookHost(target.Hostname()); err != nil {
+			return "", "", err
+		}
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if event == "" {
+			continue
+		}
+		known := event == "*"
+		for _, eventType := range WebhookEventTypes {
+			known = known || event == eventType
+		}
+		if !known {
+			return "", "", fmt.Errorf("unknown webhook event %q", event)
+		}
+		events = append(events, event)
+	}
+	return target.String(), strings.Join(events, ","), nil
+}
+
+// GetEndpoints lists the tenant's webhook endpoints
+func (s *WebhookService) GetEndpoints(tenantID uint) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := s.db.Where("tenant_id = ?", tenantID).Order("created_at DESC, id DESC").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// GetEndpoint returns one of the tenant's webhook endpoints
+func (s *WebhookService) GetEndpoint(tenantID, endpointID uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := s.db.Where("id = ? AND tenant_id = ?", endpointID, tenantID).First(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// CreateEndpoint registers a webhook endpoint with a freshly generated signing secret
+func (s *WebhookService) CreateEndpoint(tenantID uint, req WebhookEndpointRequest) (*CreatedWebhookEndpoint, error) {
+	target, events, err := normalizeWebhookEndpoint(req, s.AllowPrivateTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	secret := "whsec_" + hex.EncodeToString(secretBytes)
+
+	endpoint := models.WebhookEndpoint{
+		TenantID: tenantID,
+		URL:      target,
+		Secret:   secret,
+		Events:   events,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	}
+	if err := s.db.Create(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	// The column default would turn an explicit false into true on insert
+	if !endpoint.IsActive {
+		s.db.Model(&endpoint).Update("is_active", false)
+	}
+	return &CreatedWebhookEndpoint{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// UpdateEndpoint changes an endpoint's URL, subscribed events and active flag; the secret is kept
+func (s *WebhookService) UpdateEndpoint(tenantID, endpointID uint, req WebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.GetEndpoint(tenantID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	target, events, err := normalizeWebhookEndpoint(req, s.AllowPrivateTargets)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"url":        target,
+		"events":     events,
+		"updated_at": time.Now(),
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if err := s.db.Model(endpoint).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetEndpoint(tenantID, endpointID)
+}
+
+// DeleteEndpoint removes an endpoint together with its deliveries
+func (s *WebhookService) DeleteEndpoint(tenantID, endpointID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND tenant_id = ?", endpointID, tenantID).Delete(&models.WebhookEndpoint{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		deliveries := tx.Model(&models.WebhookDelivery{}).Select("id").Where("endpoint_id = ?", endpointID)
+		if err := tx.Where("delivery_id IN (?)", deliveries).Delete(&models.WebhookDeliveryAttempt{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("endpoint_id = ?", endpointID).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+// GetDeliveries returns the tenant's most recent deliveries with their attempts, newest first.
+// endpointID and status narrow the log when given.
+func (s *WebhookService) GetDeliveries(tenantID uint, endpointID *uint, status string, limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	query := s.db.Where("tenant_id = ?", tenantID)
+	if endpointID != nil {
+		query = query.Where("endpoint_id = ?", *endpointID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("attempt ASC")
+	}).Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// HandleEvent queues a webhook delivery for domain events tenants can subscribe to
+func (s *WebhookService) HandleEvent(event DomainEvent) {
+	for _, eventType := range WebhookEventTypes {
+		if event.Name != eventType {
+			continue
+		}
+		if err := s.Dispatch(event.TenantID, event.Name, event.Data); err != nil {
+			log.Printf("⚠️  Failed to queue %s webhooks for tenant %d: %v", event.Name, event.TenantID, err)
+		}
+		return
+	}
+}
+
+// webhookBackoff returns the wait before the next try after the given number of failed attempts
+func webhookBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < maxWebhookBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxWebhookBackoff {
+		return maxWebhookBackoff
+	}
+	return backoff
+}
+
+// WebhookEvent is the JSON envelope POSTed to webhook endpoints
+type WebhookEvent struct {
+	ID        uint        `json:"id"`
+	Type      string      `json:"type"`
+	TenantID  uint        `json:"tenantId"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// subscribes reports whether the endpoint wants events of the given type
+func subscribes(endpoint models.WebhookEndpoint, eventType string) bool {
+	if strings.TrimSpace(endpoint.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(endpoint.Events, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatch queues an event for every active endpoint of the tenant subscribed to it
+func (s *WebhookService) Dispatch(tenantID uint, eventType string, data interface{}) error {
+	var endpoints []models.WebhookEndpoint
+	if err := s.db.Where("tenant_id = ? AND is_active = ?", tenantID, true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, endpoint := range endpoints {
+		if !subscribes(endpoint, eventType) {
+			continue
+		}
+
+		delivery := &models.WebhookDelivery{
+			TenantID:      tenantID,
+			EndpointID:    endpoint.ID,
+			EventType:     eventType,
+			Payload:       "{}",
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}
+		if err := s.db.Create(delivery).Error; err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(WebhookEvent{
+			ID:        delivery.ID,
+			Type:      eventType,
+			TenantID:  tenantID,
+			CreatedAt: now,
+			Data:      data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+		if err := s.db.Model(delivery).Update("payload", string(payload)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProcessPending attempts every due delivery once and returns how many succeeded
+func (s *WebhookService) ProcessPending() (int, error) {
+	var deliveries []models.WebhookDelivery
+	err := s.db.Preload("Endpoint").
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, time.Now()).
+		Order("id ASC").
+		Find(&deliveries).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		if s.attempt(&deliveries[i]) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends a single delivery and records the outcome
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery) bool {
+	now := time.Now()
+	delivery.Attempts++
+
+	statusCode, sendErr := s.send(delivery)
+	record := models.WebhookDeliveryAttempt{
+		TenantID:     delivery.TenantID,
+		DeliveryID:   delivery.ID,
+		Attempt:      delivery.Attempts,
+		ResponseCode: statusCode,
+		DurationMs:   time.Since(now).Milliseconds(),
+		AttemptedAt:  now,
+	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		log.Printf("⚠️  Failed to record attempt %d of webhook delivery %d: %v", delivery.Attempts, delivery.ID, err)
+	}
+
+	updates := map[string]interface{}{
+		"attempts":      delivery.Attempts,
+		"response_code": statusCode,
+		"updated_at":    now,
+	}
+
+	if sendErr == nil {
+		updates["status"] = models.WebhookDeliveryDelivered
+		updates["last_error"] = ""
+		updates["delivered_at"] = now
+		s.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates)
+		return true
+	}
+
+	updates["last_error"] = sendErr.Error()
+	updates["next_attempt_at"] = now.Add(webhookBackoff(s.RetryBackoff, delivery.Attempts))
+	exhausted := delivery.Attempts >= s.MaxAttempts
+	if exhausted {
+		updates["status"] = models.WebhookDeliveryFailed
+	}
+	s.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates)
+
+	if exhausted {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.LastError = sendErr.Error()
+		log.Printf("❌ Webhook delivery %d to endpoint %d failed permanently: %v", delivery.ID, delivery.EndpointID, sendErr)
+		s.handlePermanentFailure(delivery)
+	}
+	return false
+}
+
+// send POSTs the delivery payload to its endpoint
+func (s *WebhookService) send(delivery *models.WebhookDelivery) (int, error) {
+	if delivery.Endpoint == nil {
+		return 0, fmt.Errorf("webhook endpoint %d not found", delivery.EndpointID)
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	if delivery.Endpoint.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignPayload(delivery.Endpoint.Secret, body))
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach webhook endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// handlePermanentFailure opens a support ticket when the tenant has opted in
+func (s *WebhookService) handlePermanentFailure(delivery *models.WebhookDelivery) {
+	settings, err := NewTenantSettingsService(s.db).GetSettings(delivery.TenantID)
+	if err != nil || !settings.TicketOnWebhookFailure {
+		return
+	}
+
+	var tenant models.Tenant
+	if err := s.db.First(&tenant, delivery.TenantID).Error; err != nil {
+		log.Printf("⚠️  Could not load tenant %d for webhook failure ticket: %v", delivery.TenantID, err)
+		return
+	}
+
+	// The endpoint may have been deleted since the delivery was queued
+	endpoint := fmt.Sprintf("#%d (deleted)", delivery.EndpointID)
+	if delivery.Endpoint != nil {
+		endpoint = delivery.Endpoint.URL
+	}
+	description := fmt.Sprintf(
+		"Webhook delivery #%d for event %q could not be delivered after %d attempts.\n\nEndpoint: %s\nLast error: %s",
+		delivery.ID, delivery.EventType, delivery.Attempts, endpoint, delivery.LastError,
+	)
+
+	_, err = NewTicketService(s.db).CreateTicket(tenant.ID, tenant.OwnerID, CreateTicketRequest{
+		Subject:           fmt.Sprintf("Webhook delivery failed: %s", delivery.EventType),
+		Description:       description,
+		Priority:          models.TicketPriorityHigh,
+		Category:          models.TicketCategoryTechnical,
+		RelatedEntityType: "webhook_delivery",
+		RelatedEntityID:   delivery.ID,
+		Tags:              "webhook,automated",
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to create ticket for webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// Start subscribes to the event bus and processes pending deliveries on the given interval in the background
+func (s *WebhookService) Start(interval time.Duration) {
+	GetEventBus().Subscribe(s.HandleEvent)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Printf("⏰ Webhook dispatcher started (every %v)", interval)
+
+		for range ticker.C {
+			if _, err := s.ProcessPending(); err != nil {
+				log.Printf("⚠️  Webhook processing failed: %v", err)
+			}
+		}
+	}()
+}
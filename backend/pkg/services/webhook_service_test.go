@@ -0,0 +1,295 @@
+package services
+
+import (
+	"api/pkg/models"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestWebhookPermanentFailureCreatesTicket verifies a webhook failing all attempts opens exactly one ticket
+func TestWebhookPermanentFailureCreatesTicket(t *testing.T) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.TenantSettings{},
+		&models.User{},
+		&models.Customer{},
+		&models.Branch{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.Ticket{},
+		&models.TicketMessage{},
+		&models.TicketActivity{},
+	)
+
+	owner := &models.User{Email: "owner@example.com", Role: "tenant_owner"}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Webhook Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+
+	enabled := true
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{TicketOnWebhookFailure: &enabled}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	// Sink that always fails
+	calls := 0
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer sink.Close()
+
+	db.Create(&models.WebhookEndpoint{TenantID: tenant.ID, URL: sink.URL, Secret: "s3cret", IsActive: true})
+
+	service := NewWebhookService(db)
+	service.AllowPrivateTargets = true // The test sink listens on loopback
+	service.MaxAttempts = 3
+	service.RetryBackoff = 0
+
+	if err := service.Dispatch(tenant.ID, "settlement.created", map[string]interface{}{"id": 42}); err != nil {
+		t.Fatalf("Failed to dispatch webhook: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := service.ProcessPending(); err != nil {
+			t.Fatalf("Failed to process deliveries: %v", err)
+		}
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 delivery attempts, got %d", calls)
+	}
+
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	if delivery.Status != models.WebhookDeliveryFailed {
+		t.Errorf("Expected delivery to be FAILED, got %s", delivery.Status)
+	}
+
+	var tickets []models.Ticket
+	db.Where("tenant_id = ?", tenant.ID).Find(&tickets)
+	if len(tickets) != 1 {
+		t.Fatalf("Expected exactly 1 ticket, got %d", len(tickets))
+	}
+	if tickets[0].Category != models.TicketCategoryTechnical || tickets[0].RelatedEntityID != delivery.ID {
+		t.Errorf("Unexpected ticket: category=%s relatedEntityId=%d", tickets[0].Category, tickets[0].RelatedEntityID)
+	}
+}
+
+// TestWebhookPermanentFailureWithoutEndpoint verifies a failed delivery whose endpoint is gone still opens a
+// ticket naming the endpoint ID
+func TestWebhookPermanentFailureWithoutEndpoint(t *testing.T) {
+	db := newTestDB(t, &models.Tenant{}, &models.TenantSettings{}, &models.User{}, &models.Customer{}, &models.Branch{},
+		&models.WebhookEndpoint{}, &models.WebhookDelivery{}, &models.Ticket{}, &models.TicketMessage{}, &models.TicketActivity{})
+
+	owner := &models.User{Email: "owner@example.com", Role: "tenant_owner"}
+	db.Create(owner)
+	tenant := &models.Tenant{Name: "Webhook Exchange", OwnerID: owner.ID}
+	db.Create(tenant)
+	enabled := true
+	if _, err := NewTenantSettingsService(db).UpdateSettings(tenant.ID, UpdateTenantSettingsRequest{TicketOnWebhookFailure: &enabled}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	delivery := &models.WebhookDelivery{TenantID: tenant.ID, EndpointID: 99, EventType: EventTransactionLarge, Attempts: 5}
+	NewWebhookService(db).handlePermanentFailure(delivery)
+
+	var ticket models.Ticket
+	if err := db.Where("tenant_id = ?", tenant.ID).First(&ticket).Error; err != nil {
+		t.Fatalf("Expected a ticket for the failed delivery: %v", err)
+	}
+	if !strings.Contains(ticket.Description, "#99 (deleted)") {
+		t.Errorf("Expected the ticket to name the deleted endpoint, got %q", ticket.Description)
+	}
+}
+
+// setupWebhookTestDB creates an in-memory database with a tenant for webhook tests
+func setupWebhookTestDB(t *testing.T) (*gorm.DB, *models.Tenant) {
+	db := newTestDB(t,
+		&models.Tenant{},
+		&models.TenantSettings{},
+		&models.User{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
+	)
+
+	tenant := newTestTenant(t, db, "Webhook Exchange")
+	return db, tenant
+}
+
+// TestSignPayload verifies the signature is the hex HMAC-SHA256 of the body
+func TestSignPayload(t *testing.T) {
+	got := SignPayload("key", []byte("The quick brown fox jumps over the lazy dog"))
+	want := "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got != want {
+		t.Errorf("Expected signature %s, got %s", want, got)
+	}
+	if SignPayload("other", []byte("The quick brown fox jumps over the lazy dog")) == want {
+		t.Error("Expected a different secret to give a different signature")
+	}
+}
+
+// TestWebhookDeliverySucceedsWithSignature verifies a subscribed event is POSTed once with a valid signature
+func TestWebhookDeliverySucceedsWithSignature(t *testing.T) {
+	db, tenant := setupWebhookTestDB(t)
+
+	var secret string
+	calls := 0
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(WebhookSignatureHeader); got != SignPayload(secret, body) {
+			t.Errorf("Signature %q does not match the body", got)
+		}
+		if r.Header.Get("X-Webhook-Event") != EventSettlementCreated {
+			t.Errorf("Unexpected event header %q", r.Header.Get("X-Webhook-Event"))
+		}
+		var event WebhookEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.Type != EventSettlementCreated || event.TenantID != tenant.ID {
+			t.Errorf("Unexpected payload %s (%v)", body, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer sink.Close()
+
+	service := NewWebhookService(db)
+	service.AllowPrivateTargets = true // The test sink listens on loopback
+	created, err := service.CreateEndpoint(tenant.ID, WebhookEndpointRequest{URL: sink.URL, Events: []string{EventSettlementCreated}})
+	if err != nil {
+		t.Fatalf("Failed to create endpoint: %v", err)
+	}
+	secret = created.Secret
+	if _, err := service.CreateEndpoint(tenant.ID, WebhookEndpointRequest{URL: sink.URL + "/large", Events: []string{EventTransactionLarge}}); err != nil {
+		t.Fatalf("Failed to create endpoint: %v", err)
+	}
+
+	service.HandleEvent(DomainEvent{Name: EventSettlementCreated, TenantID: tenant.ID, Data: map[string]interface{}{"settlementId": 7}})
+	service.HandleEvent(DomainEvent{Name: EventPaymentCreated, TenantID: tenant.ID})
+
+	delivered, err := service.ProcessPending()
+	if err != nil {
+		t.Fatalf("Failed to process deliveries: %v", err)
+	}
+	if delivered != 1 || calls != 1 {
+		t.Fatalf("Expected exactly one delivery to the settlement endpoint, got %d delivered and %d calls", delivered, calls)
+	}
+
+	deliveries, err := service.GetDeliveries(tenant.ID, nil, "", 0)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("Expected one delivery in the log, got %d (%v)", len(deliveries), err)
+	}
+	delivery := deliveries[0]
+	if delivery.Status != models.WebhookDeliveryDelivered || len(delivery.History) != 1 || delivery.History[0].ResponseCode != http.StatusNoContent {
+		t.Errorf("Expected a delivered delivery with one 204 attempt, got %s with %d attempts", delivery.Status, len(delivery.History))
+	}
+}
+
+// TestWebhookRetriesAfterServerError verifies a 500 is retried with exponential backoff until it succeeds
+func TestWebhookRetriesAfterServerError(t *testing.T) {
+	db, tenant := setupWebhookTestDB(t)
+
+	calls := 0
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer sink.Close()
+
+	service := NewWebhookService(db)
+	service.AllowPrivateTargets = true // The test sink listens on loopback
+	service.RetryBackoff = time.Minute
+	if _, err := service.CreateEndpoint(tenant.ID, WebhookEndpointRequest{URL: sink.URL}); err != nil {
+		t.Fatalf("Failed to create endpoint: %v", err)
+	}
+	if err := service.Dispatch(tenant.ID, EventTransactionLarge, map[string]interface{}{"transactionId": "tx-1"}); err != nil {
+		t.Fatalf("Failed to dispatch webhook: %v", err)
+	}
+
+	// makeDue moves the delivery's next attempt into the past and returns the wait that had been scheduled
+	makeDue := func() time.Duration {
+		var delivery models.WebhookDelivery
+		db.First(&delivery)
+		wait := time.Until(delivery.NextAttemptAt)
+		db.Model(&delivery).Update("next_attempt_at", time.Now().Add(-time.Second))
+		return wait
+	}
+
+	service.ProcessPending()
+	if calls != 1 {
+		t.Fatalf("Expected one attempt, got %d", calls)
+	}
+	service.ProcessPending()
+	if calls != 1 {
+		t.Fatalf("Expected the retry to wait for its backoff, got %d attempts", calls)
+	}
+	if wait := makeDue(); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("Expected a one minute backoff after the first failure, got %v", wait)
+	}
+
+	service.ProcessPending()
+	if wait := makeDue(); wait < 110*time.Second || wait > 2*time.Minute {
+		t.Errorf("Expected a two minute backoff after the second failure, got %v", wait)
+	}
+
+	delivered, _ := service.ProcessPending()
+	if delivered != 1 || calls != 3 {
+		t.Fatalf("Expected the third attempt to succeed, got %d delivered after %d attempts", delivered, calls)
+	}
+
+	deliveries, _ := service.GetDeliveries(tenant.ID, nil, models.WebhookDeliveryDelivered, 0)
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected one delivered delivery, got %d", len(deliveries))
+	}
+	history := deliveries[0].History
+	if len(history) != 3 || history[0].ResponseCode != 500 || history[1].ResponseCode != 500 || history[2].ResponseCode != 200 ||
+		history[0].Error == "" || history[2].Error != "" {
+		t.Errorf("Expected attempts 500, 500, 200 to be recorded, got %+v", history)
+	}
+	if deliveries[0].Attempts != 3 {
+		t.Errorf("Expected 3 attempts on the delivery, got %d", deliveries[0].Attempts)
+	}
+}
+
+// TestWebhookRefusesInternalTargets verifies endpoints cannot point at loopback, private or link-local
+// addresses, and that a delivery to one is refused when dialing
+func TestWebhookRefusesInternalTargets(t *testing.T) {
+	db, tenant := setupWebhookTestDB(t)
+	service := NewWebhookService(db)
+
+	for _, target := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.5/hook",
+		"https://192.168.1.20/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+	} {
+		if _, err := service.CreateEndpoint(tenant.ID, WebhookEndpointRequest{URL: target}); err == nil {
+			t.Errorf("Expected %s to be refused", target)
+		}
+	}
+
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the internal sink never to be called")
+	}))
+	defer sink.Close()
+	db.Create(&models.WebhookEndpoint{TenantID: tenant.ID, URL: sink.URL, Secret: "s3cret", IsActive: true})
+	if err := service.Dispatch(tenant.ID, EventTransactionLarge, map[string]interface{}{"transactionId": "tx-1"}); err != nil {
+		t.Fatalf("Failed to dispatch webhook: %v", err)
+	}
+	if delivered, _ := service.ProcessPending(); delivered != 0 {
+		t.Errorf("Expected no delivery to an internal address, got %d", delivered)
+	}
+}
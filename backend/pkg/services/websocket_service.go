@@ -1,9 +1,12 @@
 package services
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -16,15 +19,194 @@ type WSMessage struct {
 	Data      map[string]interface{} `json:"data"`
 	TenantID  uint                   `json:"tenantId"`
 	Timestamp time.Time              `json:"timestamp"`
+
+	// BranchIDs limits delivery to clients of these branches (plus tenant-wide clients); empty means the whole tenant
+	BranchIDs []uint `json:"-"`
+	// UserIDs limits delivery to these users' connections; empty means every user
+	UserIDs []uint `json:"-"`
+}
+
+// Channels a client can subscribe to. A client receives nothing until it subscribes.
+const (
+	ChannelDashboard      = "dashboard" // Everything that moves dashboard figures
+	ChannelTransactions   = "transactions"
+	ChannelPayments       = "payments"
+	ChannelSettlements    = "settlements"
+	ChannelRemittances    = "remittances"
+	ChannelPickups        = "pickups"
+	ChannelCashBalances   = "cash_balances"
+	ChannelRates          = "rates"
+	ChannelTickets        = "tickets"
+	ChannelReconciliation = "reconciliation" // Missed and escalated cash reconciliations
+	ChannelProfit         = "profit"         // Margin and profit alerts
+)
+
+// messageChannels maps each message type to the channels that carry it
+var messageChannels = map[string][]string{
+	"transaction":       {ChannelTransactions, ChannelDashboard},
+	"payment":           {ChannelPayments, ChannelDashboard},
+	"settlement":        {ChannelSettlements, ChannelDashboard},
+	"remittance":        {ChannelRemittances, ChannelDashboard},
+	"cash_balance":      {ChannelCashBalances, ChannelDashboard},
+	"pickup":            {ChannelPickups},
+	"fx_rate":           {ChannelRates},
+	"ticket":            {ChannelTickets},
+	"ticket_message":    {ChannelTickets},
+	"ticket_assignment": {ChannelTickets},
+	"reconciliation":    {ChannelReconciliation, ChannelDashboard},
+	"profit":            {ChannelProfit, ChannelDashboard},
+}
+
+// knownChannels are the channels clients may subscribe to
+var knownChannels = map[string]bool{
+	ChannelDashboard:      true,
+	ChannelTransactions:   true,
+	ChannelPayments:       true,
+	ChannelSettlements:    true,
+	ChannelRemittances:    true,
+	ChannelPickups:        true,
+	ChannelCashBalances:   true,
+	ChannelRates:          true,
+	ChannelTickets:        true,
+	ChannelReconciliation: true,
+	ChannelProfit:         true,
+}
+
+// IsKnownChannel reports whether clients may subscribe to the channel
+func IsKnownChannel(channel string) bool {
+	return knownChannels[channel]
 }
 
 // Client represents a WebSocket client
 type Client struct {
 	ID       string
 	TenantID uint
+	UserID   uint
+	BranchID *uint // Branch-bound clients only receive branch-scoped messages for their own branch
 	Conn     *websocket.Conn
 	Send     chan []byte
 	Hub      *Hub
+
+	PingInterval   time.Duration // How often the server pings the connection; WebSocketPingInterval() when zero
+	MaxMissedPongs int           // Consecutive unanswered pings before the connection is dropped; WebSocketMaxMissedPongs() when zero
+
+	channelsMu  sync.RWMutex
+	channels    map[string]bool // Channels the client opted into
+	closed      bool            // Send has been closed by the hub; guarded by Hub.mu
+	writeMu     sync.Mutex      // gorilla/websocket allows only one concurrent writer per connection
+	missedPongs int32           // Pings sent since the last pong; accessed atomically
+}
+
+const (
+	defaultWSPingInterval   = 54 * time.Second
+	defaultWSMaxMissedPongs = 2
+	wsWriteWait             = 10 * time.Second
+)
+
+// WebSocketPingInterval returns how often connections are pinged, from WS_PING_INTERVAL (e.g. "30s")
+func WebSocketPingInterval() time.Duration {
+	interval, err := time.ParseDuration(getEnv("WS_PING_INTERVAL", defaultWSPingInterval.String()))
+	if err != nil || interval <= 0 {
+		return defaultWSPingInterval
+	}
+	return interval
+}
+
+// WebSocketMaxMissedPongs returns how many consecutive pings may go unanswered before a connection is
+// dropped, from WS_MAX_MISSED_PONGS
+func WebSocketMaxMissedPongs() int {
+	missed, err := strconv.Atoi(getEnv("WS_MAX_MISSED_PONGS", strconv.Itoa(defaultWSMaxMissedPongs)))
+	if err != nil || missed <= 0 {
+		return defaultWSMaxMissedPongs
+	}
+	return missed
+}
+
+func (c *Client) pingInterval() time.Duration {
+	if c.PingInterval > 0 {
+		return c.PingInterval
+	}
+	return WebSocketPingInterval()
+}
+
+func (c *Client) maxMissedPongs() int {
+	if c.MaxMissedPongs > 0 {
+		return c.MaxMissedPongs
+	}
+	return WebSocketMaxMissedPongs()
+}
+
+// pongWait is how long a read may wait for any frame: every allowed missed ping plus one more interval
+func (c *Client) pongWait() time.Duration {
+	return c.pingInterval() * time.Duration(c.maxMissedPongs()+1)
+}
+
+// write sends one frame, serialized with every other write to the connection
+func (c *Client) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.Conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return c.Conn.WriteMessage(messageType, data)
+}
+
+// Subscribe opts the client into a channel's messages
+func (c *Client) Subscribe(channel string) {
+	c.channelsMu.Lock()
+	defer c.channelsMu.Unlock()
+	if c.channels == nil {
+		c.channels = make(map[string]bool)
+	}
+	c.channels[channel] = true
+}
+
+// Unsubscribe stops the client receiving a channel's messages
+func (c *Client) Unsubscribe(channel string) {
+	c.channelsMu.Lock()
+	defer c.channelsMu.Unlock()
+	delete(c.channels, channel)
+}
+
+// subscribedTo reports whether the client has opted into a channel that carries the message type
+func (c *Client) subscribedTo(messageType string) bool {
+	channels, ok := messageChannels[messageType]
+	if !ok {
+		channels = []string{messageType}
+	}
+
+	c.channelsMu.RLock()
+	defer c.channelsMu.RUnlock()
+	for _, channel := range channels {
+		if c.channels[channel] {
+			return true
+		}
+	}
+	return false
+}
+
+// receives reports whether the client should get the message
+func (c *Client) receives(message WSMessage) bool {
+	if !c.subscribedTo(message.Type) {
+		return false
+	}
+	if len(message.UserIDs) > 0 {
+		addressed := false
+		for _, userID := range message.UserIDs {
+			addressed = addressed || userID == c.UserID
+		}
+		if !addressed {
+			return false
+		}
+	}
+	if len(message.BranchIDs) == 0 || c.BranchID == nil {
+		return true
+	}
+	for _, branchID := range message.BranchIDs {
+		if branchID == *c.BranchID {
+			return true
+		}
+	}
+	return false
 }
 
 // Hub maintains the set of active clients and broadcasts messages to them
@@ -81,6 +263,7 @@ func (h *Hub) run() {
 			if _, ok := h.clients[client.TenantID][client]; ok {
 				delete(h.clients[client.TenantID], client)
 				close(client.Send)
+				client.closed = true
 				if len(h.clients[client.TenantID]) == 0 {
 					delete(h.clients, client.TenantID)
 				}
@@ -101,6 +284,9 @@ func (h *Hub) run() {
 				}
 
 				for client := range tenantClients {
+					if !client.receives(message) {
+						continue
+					}
 					select {
 					case client.Send <- messageJSON:
 					default:
@@ -108,6 +294,7 @@ func (h *Hub) run() {
 						h.mu.Lock()
 						delete(h.clients[message.TenantID], client)
 						close(client.Send)
+						client.closed = true
 						h.mu.Unlock()
 					}
 				}
@@ -126,6 +313,34 @@ func (h *Hub) UnregisterClient(client *Client) {
 	h.unregister <- client
 }
 
+// ClientCount returns the number of connected clients of a tenant
+func (h *Hub) ClientCount(tenantID uint) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[tenantID])
+}
+
+// reply sends a message to a single client unless the hub has already closed its connection
+func (h *Hub) reply(client *Client, message WSMessage) {
+	message.TenantID = client.TenantID
+	message.Timestamp = time.Now()
+	messageJSON, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if client.closed {
+		return
+	}
+	select {
+	case client.Send <- messageJSON:
+	default:
+	}
+}
+
 // Broadcast sends a message to all clients in a tenant
 func (h *Hub) Broadcast(message WSMessage) {
 	message.Timestamp = time.Now()
@@ -188,6 +403,17 @@ func (h *Hub) BroadcastRemittanceUpdate(tenantID uint, action string, data map[s
 	})
 }
 
+// BroadcastToBranches sends a message to the clients of the given branches in a tenant
+func (h *Hub) BroadcastToBranches(tenantID uint, branchIDs []uint, messageType, action string, data map[string]interface{}) {
+	h.Broadcast(WSMessage{
+		Type:      messageType,
+		Action:    action,
+		Data:      data,
+		TenantID:  tenantID,
+		BranchIDs: branchIDs,
+	})
+}
+
 // BroadcastRateUpdateAll broadcasts FX rate updates to all tenants
 func (h *Hub) BroadcastRateUpdateAll(data map[string]interface{}) {
 	h.BroadcastToAll(WSMessage{
@@ -228,6 +454,37 @@ func (h *Hub) BroadcastTicketAssignment(tenantID uint, assignedToUserID uint, da
 	})
 }
 
+// subscriptionRequest is a frame a client sends to change its channels,
+// e.g. {"action":"subscribe","channel":"dashboard"}
+type subscriptionRequest struct {
+	Action  string `json:"action"` // "subscribe" or "unsubscribe"
+	Channel string `json:"channel"`
+}
+
+// handleFrame applies a subscription request from the client and acknowledges it
+func (c *Client) handleFrame(frame []byte) {
+	var request subscriptionRequest
+	if err := json.Unmarshal(frame, &request); err != nil {
+		c.Hub.reply(c, WSMessage{Type: "subscription", Action: "error", Data: map[string]interface{}{"error": "invalid frame"}})
+		return
+	}
+	if !IsKnownChannel(request.Channel) {
+		c.Hub.reply(c, WSMessage{Type: "subscription", Action: "error", Data: map[string]interface{}{"error": "unknown channel", "channel": request.Channel}})
+		return
+	}
+
+	switch request.Action {
+	case "subscribe":
+		c.Subscribe(request.Channel)
+		c.Hub.reply(c, WSMessage{Type: "subscription", Action: "subscribed", Data: map[string]interface{}{"channel": request.Channel}})
+	case "unsubscribe":
+		c.Unsubscribe(request.Channel)
+		c.Hub.reply(c, WSMessage{Type: "subscription", Action: "unsubscribed", Data: map[string]interface{}{"channel": request.Channel}})
+	default:
+		c.Hub.reply(c, WSMessage{Type: "subscription", Action: "error", Data: map[string]interface{}{"error": "unknown action", "action": request.Action}})
+	}
+}
+
 // ReadPump pumps messages from the websocket connection to the hub
 func (c *Client) ReadPump() {
 	defer func() {
@@ -235,26 +492,29 @@ func (c *Client) ReadPump() {
 		c.Conn.Close()
 	}()
 
-	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.Conn.SetReadDeadline(time.Now().Add(c.pongWait()))
 	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		atomic.StoreInt32(&c.missedPongs, 0)
+		c.Conn.SetReadDeadline(time.Now().Add(c.pongWait()))
 		return nil
 	})
 
 	for {
-		_, _, err := c.Conn.ReadMessage()
+		_, frame, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		c.handleFrame(frame)
 	}
 }
 
-// WritePump pumps messages from the hub to the websocket connection
+// WritePump pumps messages from the hub to the websocket connection and pings it on the client's
+// interval. A connection that leaves MaxMissedPongs pings in a row unanswered is closed and deregistered.
 func (c *Client) WritePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	ticker := time.NewTicker(c.pingInterval())
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
@@ -263,32 +523,32 @@ func (c *Client) WritePump() {
 	for {
 		select {
 		case message, ok := <-c.Send:
-			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.write(websocket.CloseMessage, []byte{})
 				return
 			}
 
-			w, err := c.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
 			// Add queued messages to the current websocket message
+			var frame bytes.Buffer
+			frame.Write(message)
 			n := len(c.Send)
 			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.Send)
+				frame.WriteByte('\n')
+				frame.Write(<-c.Send)
 			}
 
-			if err := w.Close(); err != nil {
+			if err := c.write(websocket.TextMessage, frame.Bytes()); err != nil {
 				return
 			}
 
 		case <-ticker.C:
-			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if missed := int(atomic.LoadInt32(&c.missedPongs)); missed >= c.maxMissedPongs() {
+				log.Printf("🔌 WebSocket client %s missed %d pongs, closing connection", c.ID, missed)
+				c.Hub.UnregisterClient(c)
+				return
+			}
+			atomic.AddInt32(&c.missedPongs, 1)
+			if err := c.write(websocket.PingMessage, nil); err != nil {
 				return
 			}
 		}
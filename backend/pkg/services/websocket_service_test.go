@@ -0,0 +1,25 @@
+package services
+
+import "testing"
+
+// TestClientReceives_AddressedToUsers verifies a message addressed to users only reaches their connections
+func TestClientReceives_AddressedToUsers(t *testing.T) {
+	admin := &Client{TenantID: 1, UserID: 7}
+	teller := &Client{TenantID: 1, UserID: 8}
+	for _, client := range []*Client{admin, teller} {
+		client.Subscribe(ChannelReconciliation)
+	}
+
+	alert := WSMessage{Type: "reconciliation", Action: "escalated", TenantID: 1, UserIDs: []uint{7}}
+	if !admin.receives(alert) {
+		t.Error("Expected the addressed admin to receive the alert")
+	}
+	if teller.receives(alert) {
+		t.Error("Expected the teller not to receive an alert addressed to admins")
+	}
+
+	warning := WSMessage{Type: "reconciliation", Action: "missed", TenantID: 1}
+	if !teller.receives(warning) {
+		t.Error("Expected an unaddressed message to reach every subscriber")
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxSheet is one worksheet of a generated workbook
+type xlsxSheet struct {
+	Name string
+	Rows [][]interface{}
+}
+
+// xlsxPart is one file inside the workbook archive
+type xlsxPart struct {
+	name string
+	body []byte
+}
+
+const (
+	xlsxMainNS      = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelNS       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxPackageNS   = "http://schemas.openxmlformats.org/package/2006/relationships"
+	xlsxContentNS   = "http://schemas.openxmlformats.org/package/2006/content-types"
+	xlsxWorksheetCT = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+)
+
+// writeXLSX writes a minimal Office Open XML workbook with one worksheet per sheet.
+// Numeric values become number cells; everything else is written as an inline string.
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	archive := zip.NewWriter(w)
+
+	var contentTypes, workbook, workbookRels bytes.Buffer
+	contentTypes.WriteString(xml.Header + `<Types xmlns="` + xlsxContentNS + `">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelNS + `"><sheets>`)
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="` + xlsxPackageNS + `">`)
+
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="%s"/>`, n, xlsxWorksheetCT)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.Name), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, n, xlsxRelNS, n)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []xlsxPart{
+		{"[Content_Types].xml", contentTypes.Bytes()},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="` + xlsxPackageNS + `">` +
+			`<Relationship Id="rId1" Type="` + xlsxRelNS + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`)},
+		{"xl/workbook.xml", workbook.Bytes()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.Bytes()},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, xlsxPart{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxWorksheet(sheet.Rows)})
+	}
+
+	for _, part := range parts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.body); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// xlsxWorksheet renders the rows of a worksheet
+func xlsxWorksheet(rows [][]interface{}) []byte {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header + `<worksheet xmlns="` + xlsxMainNS + `"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			if number, ok := xlsxNumber(value); ok {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, number)
+			} else if value != nil {
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xlsxEscape(fmt.Sprint(value)))
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.Bytes()
+}
+
+// xlsxNumber formats numeric cell values
+func xlsxNumber(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	}
+	return "", false
+}
+
+// xlsxColumn converts a zero-based column index to its letter reference (0 → A, 26 → AA)
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func xlsxEscape(value string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
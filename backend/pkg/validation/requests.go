@@ -67,8 +67,9 @@ type CreateSettlementRequest struct {
 
 // AutoSettleRequest represents a request for auto-settlement
 type AutoSettleRequest struct {
-	IncomingRemittanceID uint   `json:"incomingRemittanceId" validate:"required,gt=0"`
-	Strategy             string `json:"strategy" validate:"omitempty,oneof=FIFO LIFO BEST_RATE MANUAL"`
+	IncomingRemittanceID uint     `json:"incomingRemittanceId" validate:"required,gt=0"`
+	Strategy             string   `json:"strategy" validate:"omitempty,oneof=FIFO LIFO BEST_RATE MANUAL"`
+	MaxAmountIRR         *float64 `json:"maxAmountIrr" validate:"omitempty,gt=0"` // Settle at most this much of the incoming remittance
 }
 
 // CreateTransactionRequest represents the request to create a transaction
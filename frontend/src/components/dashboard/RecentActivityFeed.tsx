@@ -30,10 +30,9 @@ export function RecentActivityFeed({ transactions: providedTransactions, isLoadi
     const { data: fetchedTransactions, isLoading } = useQuery<RecentTransaction[]>({
         queryKey: ['recent-transactions'],
         queryFn: async () => {
-            // Fetch transactions with a limit, assuming API supports pagination or we slice client-side
-            const response = await apiClient.get('/transactions');
-            // Sort by date desc and take top 5
-            return response.data;
+            // The list is paginated newest first, so the first page holds the latest transactions
+            const response = await apiClient.get('/transactions', { params: { limit: 5 } });
+            return response.data.data;
         },
         enabled: shouldFetch,
     });
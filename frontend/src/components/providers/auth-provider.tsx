@@ -1,8 +1,8 @@
 'use client';
 
 import React, { createContext, useContext, useEffect, useState } from 'react';
-import type { AuthContextType, User, Tenant } from '@/src/models/auth.model';
-import { useLogin, useLogout, useGetMe } from '@/src/queries/auth.query';
+import type { AuthContextType, LoginResponse, User, Tenant } from '@/src/models/auth.model';
+import { useLogin, useLogout, useGetMe, useVerify2FA } from '@/src/queries/auth.query';
 import { tokenStorage } from '@/src/lib/api-client';
 
 const AuthContext = createContext<AuthContextType | undefined>(undefined);
@@ -14,6 +14,7 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
   const [isLoading, setIsLoading] = useState(true);
 
   const loginMutation = useLogin();
+  const verify2FAMutation = useVerify2FA();
   const logoutMutation = useLogout();
   const { data: meData, isLoading: isMeLoading, refetch } = useGetMe(!!token);
 
@@ -61,6 +62,20 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
   const login = async (email: string, password: string) => {
     const response = await loginMutation.mutateAsync({ email, password });
 
+    // Two-factor users get a challenge to complete with verifyTwoFactor
+    if (response.twoFactorRequired && response.challengeToken) {
+      return { challengeToken: response.challengeToken };
+    }
+
+    completeLogin(response);
+  };
+
+  const verifyTwoFactor = async (challengeToken: string, code: string) => {
+    const response = await verify2FAMutation.mutateAsync({ challengeToken, code });
+    completeLogin(response);
+  };
+
+  const completeLogin = (response: LoginResponse) => {
     // Ensure storage is updated before state change to prevent race condition
     tokenStorage.setAccessToken(response.token);
     // Store refresh token for token refresh functionality
@@ -91,8 +106,9 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
     user,
     tenant,
     isAuthenticated: !!token && !!user,
-    isLoading: isLoading || loginMutation.isPending || (!!token && isMeLoading),
+    isLoading: isLoading || loginMutation.isPending || verify2FAMutation.isPending || (!!token && isMeLoading),
     login,
+    verifyTwoFactor,
     logout,
     refreshUser,
   };
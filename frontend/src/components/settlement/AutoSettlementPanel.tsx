@@ -68,7 +68,7 @@ export function AutoSettlementPanel({
 
     const handleAutoSettle = async () => {
         try {
-            const result = await autoSettleMutation.mutateAsync(incomingId);
+            const result = await autoSettleMutation.mutateAsync({ incomingId });
             toast.success(
                 `Auto-settled ${result.settledCount} remittances. Total profit: ${formatCurrency(result.totalProfitCAD, 'CAD')}`
             );
@@ -36,8 +36,8 @@ export interface Client {
 class ApiClient {
   // Transaction endpoints
   async getTransactions(): Promise<Transaction[]> {
-    const response = await apiClient.get<Transaction[]>('/transactions');
-    return response.data;
+    const response = await apiClient.get<{ data: Transaction[] }>('/transactions');
+    return response.data.data;
   }
 
   async getTransaction(id: string): Promise<Transaction> {
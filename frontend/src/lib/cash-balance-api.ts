@@ -2,8 +2,10 @@ import axiosInstance from './axios-config';
 import {
     CashBalance,
     CashAdjustment,
+    CashAdjustmentApprovalLimit,
     CreateAdjustmentRequest,
     AdjustmentHistoryResponse,
+    DailyClose,
 } from './models/cash-balance.model';
 
 // Get all balances for tenant
@@ -58,6 +60,72 @@ export const getAdjustmentHistory = async (
     return response.data;
 };
 
+// Get adjustments waiting for a second approver
+export const getPendingAdjustments = async (branchId?: number): Promise<CashAdjustment[]> => {
+    const params = branchId ? { branch_id: branchId } : {};
+    const response = await axiosInstance.get('/cash-balances/adjustments/pending', { params });
+    return response.data;
+};
+
+// Approve a pending adjustment, applying it to the balance
+export const approveAdjustment = async (id: number): Promise<CashAdjustment> => {
+    const response = await axiosInstance.post(`/cash-balances/adjustments/${id}/approve`);
+    return response.data;
+};
+
+// Reject a pending adjustment; the balance is left unchanged
+export const rejectAdjustment = async (id: number, note?: string): Promise<CashAdjustment> => {
+    const response = await axiosInstance.post(`/cash-balances/adjustments/${id}/reject`, { note });
+    return response.data;
+};
+
+// Get the per-currency amounts above which adjustments need approval
+export const getApprovalLimits = async (): Promise<CashAdjustmentApprovalLimit[]> => {
+    const response = await axiosInstance.get('/cash-balances/approval-limits');
+    return response.data;
+};
+
+// Set the approval limit for a currency
+export const setApprovalLimit = async (
+    currency: string,
+    amount: number
+): Promise<CashAdjustmentApprovalLimit> => {
+    const response = await axiosInstance.put(`/cash-balances/approval-limits/${currency}`, { amount });
+    return response.data;
+};
+
+// Remove a currency's approval limit
+export const deleteApprovalLimit = async (currency: string): Promise<void> => {
+    await axiosInstance.delete(`/cash-balances/approval-limits/${currency}`);
+};
+
+// Close the till for a day: snapshot every currency's balance and block postings into the day
+export const closeDay = async (date: string, branchId?: number): Promise<DailyClose> => {
+    const response = await axiosInstance.post('/cash-balances/close', { date, branchId });
+    return response.data;
+};
+
+// Reopen a closed day (owners and admins only)
+export const reopenDay = async (date: string, reason: string, branchId?: number): Promise<DailyClose> => {
+    const response = await axiosInstance.post('/cash-balances/reopen', { date, reason, branchId });
+    return response.data;
+};
+
+// Get day closes with their balance snapshots
+export const getDailyCloses = async (
+    branchId?: number,
+    startDate?: string,
+    endDate?: string
+): Promise<DailyClose[]> => {
+    const params: Record<string, string | number> = {};
+    if (branchId) params.branch_id = branchId;
+    if (startDate) params.startDate = startDate;
+    if (endDate) params.endDate = endDate;
+
+    const response = await axiosInstance.get('/cash-balances/closes', { params });
+    return response.data;
+};
+
 // Get active currencies
 export const getActiveCurrencies = async (branchId?: number): Promise<string[]> => {
     const params = branchId ? { branch_id: branchId } : {};
@@ -5,6 +5,7 @@ export interface CustomerCompliance {
     id: number;
     tenantId: number;
     customerId: number;
+    branchId?: number | null;
     status: ComplianceStatus;
     kycLevel: string;
     riskLevel: RiskLevel;
@@ -96,6 +97,42 @@ export interface VerificationStatus {
     riskScore?: number;
 }
 
+export interface ScreeningMatch {
+    matchType: 'PEP' | 'SANCTIONS' | 'ADVERSE_MEDIA' | string;
+    matchScore: number; // 0-1
+    matchName: string;
+    listName: string;
+    description: string;
+}
+
+export interface ScreeningResult {
+    screeningId: string;
+    status: 'CLEAR' | 'POTENTIAL_MATCH';
+    matches: ScreeningMatch[];
+    screenedAt: string;
+}
+
+export interface ComplianceAlert {
+    id: number;
+    tenantId: number;
+    clientId: string;
+    alertType: 'VOLUME_SPIKE';
+    status: ComplianceAlertStatus;
+    currency: string;
+    recentVolume: number;
+    baselineVolume: number;
+    multiple: number;
+    windowStart: string;
+    windowEnd: string;
+    details: string;
+    resolvedAt?: string;
+    resolvedByUserId?: number;
+    createdAt: string;
+    client?: { id: string; name: string; phoneNumber: string };
+}
+
+export type ComplianceAlertStatus = 'OPEN' | 'RESOLVED';
+
 export type ComplianceStatus =
     | 'PENDING'
     | 'UNDER_REVIEW'
@@ -184,6 +221,13 @@ export async function uploadComplianceDocument(
     return response.data;
 }
 
+export async function downloadComplianceDocument(docId: number): Promise<Blob> {
+    const response = await apiClient.get(`/compliance/documents/${docId}/download`, {
+        responseType: 'blob',
+    });
+    return response.data;
+}
+
 export async function reviewDocument(
     docId: number,
     approved: boolean,
@@ -192,13 +236,21 @@ export async function reviewDocument(
     await apiClient.put(`/compliance/documents/${docId}/review`, { approved, notes });
 }
 
-export async function getPendingReviews(limit?: number): Promise<CustomerCompliance[]> {
+export async function getPendingReviews(limit?: number, branchId?: number): Promise<CustomerCompliance[]> {
     const response = await apiClient.get<CustomerCompliance[]>('/compliance/pending', {
-        params: { limit },
+        params: { limit, branchId },
     });
     return response.data;
 }
 
+export async function routeComplianceToBranch(complianceId: number, branchId: number): Promise<void> {
+    await apiClient.put(`/compliance/${complianceId}/branch`, { branchId });
+}
+
+export async function assignComplianceOfficer(branchId: number, officerId: number | null): Promise<void> {
+    await apiClient.put(`/compliance/branches/${branchId}/officer`, { officerId });
+}
+
 export async function getExpiringCompliance(days?: number): Promise<CustomerCompliance[]> {
     const response = await apiClient.get<CustomerCompliance[]>('/compliance/expiring', {
         params: { days },
@@ -206,6 +258,20 @@ export async function getExpiringCompliance(days?: number): Promise<CustomerComp
     return response.data;
 }
 
+export async function getComplianceAlerts(
+    status?: ComplianceAlertStatus,
+    limit?: number
+): Promise<ComplianceAlert[]> {
+    const response = await apiClient.get<ComplianceAlert[]>('/compliance/alerts', {
+        params: { status, limit },
+    });
+    return response.data;
+}
+
+export async function resolveComplianceAlert(alertId: number): Promise<void> {
+    await apiClient.put(`/compliance/alerts/${alertId}/resolve`);
+}
+
 export async function getComplianceAuditLog(
     complianceId: number,
     limit?: number
@@ -225,3 +291,9 @@ export async function getVerificationStatus(complianceId: number): Promise<Verif
     const response = await apiClient.get<VerificationStatus>(`/compliance/${complianceId}/verify/status`);
     return response.data;
 }
+
+// Screens the customer against sanctions/PEP lists; a potential match moves the record to IN_REVIEW
+export async function screenCustomer(complianceId: number, dateOfBirth?: string): Promise<ScreeningResult> {
+    const response = await apiClient.post<ScreeningResult>(`/compliance/${complianceId}/screen`, { dateOfBirth });
+    return response.data;
+}
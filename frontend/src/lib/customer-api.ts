@@ -1,8 +1,12 @@
 import axiosInstance from './axios-config';
 import {
     Customer,
+    CustomerCampaign,
+    CustomerMessageRequest,
     CustomerSearchResult,
+    CustomerTenantLink,
     FindOrCreateCustomerRequest,
+    UpdateCustomerConsentRequest,
     UpdateCustomerRequest,
 } from './models/customer.model';
 
@@ -28,9 +32,11 @@ export const findOrCreateCustomer = async (
     return response.data;
 };
 
-// Get all customers for tenant
-export const getCustomersForTenant = async (): Promise<Customer[]> => {
-    const response = await axiosInstance.get('/customers');
+// Get all customers for tenant, optionally only those carrying a tag
+export const getCustomersForTenant = async (tag?: string): Promise<Customer[]> => {
+    const response = await axiosInstance.get('/customers', {
+        params: tag ? { tag } : undefined,
+    });
     return response.data;
 };
 
@@ -43,6 +49,33 @@ export const updateCustomer = async (
     return response.data;
 };
 
+// Replace the tenant's tags on a customer
+export const setCustomerTags = async (id: number, tags: string[]): Promise<CustomerTenantLink> => {
+    const response = await axiosInstance.put(`/customers/${id}/tags`, { tags });
+    return response.data;
+};
+
+// Record a customer's messaging consent and opt-outs
+export const updateCustomerConsent = async (
+    id: number,
+    data: UpdateCustomerConsentRequest
+): Promise<CustomerTenantLink> => {
+    const response = await axiosInstance.put(`/customers/${id}/consent`, data);
+    return response.data;
+};
+
+// Email or SMS every consenting customer carrying a tag
+export const sendCustomerMessage = async (data: CustomerMessageRequest): Promise<CustomerCampaign> => {
+    const response = await axiosInstance.post('/customers/message', data);
+    return response.data;
+};
+
+// List the tenant's customer campaigns
+export const getCustomerCampaigns = async (): Promise<CustomerCampaign[]> => {
+    const response = await axiosInstance.get('/customers/campaigns');
+    return response.data;
+};
+
 // ============ SUPER ADMIN ENDPOINTS ============
 
 // Search customers globally (SuperAdmin only)
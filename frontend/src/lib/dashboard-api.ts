@@ -9,20 +9,28 @@ import {
     UnsettledSummary,
     ProfitAnalysis,
     BranchProfit,
+    PaymentMethodProfit,
+    AgentProfit,
     ProfitPeriod,
+    ProfitMovingAverage,
     CustomerProfit,
+    MarginAlert,
     ProfitFilters,
     DashboardSummary,
+    CashFlowForecast,
 } from './models/dashboard.model';
 
 // ============ Dashboard API ============
 
 /**
- * Get comprehensive dashboard data
+ * Get comprehensive dashboard data. The server caches it briefly; forceRefresh bypasses the cache.
  */
-export const getDashboardData = async (branchId?: number): Promise<DashboardData> => {
-    const params = branchId ? `?branchId=${branchId}` : '';
-    const response = await apiClient.get<DashboardData>(`/dashboard${params}`);
+export const getDashboardData = async (branchId?: number, forceRefresh = false): Promise<DashboardData> => {
+    const params = new URLSearchParams();
+    if (branchId) params.append('branchId', String(branchId));
+    if (forceRefresh) params.append('forceRefresh', 'true');
+    const query = params.toString();
+    const response = await apiClient.get<DashboardData>(`/dashboard${query ? `?${query}` : ''}`);
     return response.data;
 };
 
@@ -102,6 +110,16 @@ export const getDebtAging = async (branchId?: number): Promise<DebtAging[]> => {
     return response.data;
 };
 
+/**
+ * Get the projected cash in and out for each of the coming days
+ */
+export const getCashFlowForecast = async (days: number = 30, branchId?: number): Promise<CashFlowForecast> => {
+    const params = new URLSearchParams({ days: String(days) });
+    if (branchId) params.append('branchId', String(branchId));
+    const response = await apiClient.get<CashFlowForecast>(`/dashboard/cash-flow-forecast?${params.toString()}`);
+    return response.data;
+};
+
 /**
  * Get exchange rate trends
  */
@@ -121,12 +139,14 @@ export const getCashOnHand = async (branchId?: number): Promise<CashBalanceSumma
 
 // ============ Auto-Settlement API ============
 
+export type SettlementStrategy = 'fifo' | 'lifo' | 'best_rate';
+
 /**
  * Get settlement suggestions for an incoming remittance
  */
 export const getSettlementSuggestions = async (
     incomingId: number,
-    strategy: 'fifo' | 'best_rate' = 'fifo'
+    strategy: SettlementStrategy = 'fifo'
 ): Promise<SettlementSuggestion[]> => {
     const response = await apiClient.get<SettlementSuggestion[]>(
         `/auto-settlement/suggestions?incomingId=${incomingId}&strategy=${strategy}`
@@ -135,11 +155,18 @@ export const getSettlementSuggestions = async (
 };
 
 /**
- * Auto-settle an incoming remittance using FIFO
+ * Auto-settle an incoming remittance using the given strategy (FIFO by default),
+ * allocating at most maxAmountIrr of it when given
  */
-export const autoSettle = async (incomingId: number): Promise<AutoSettlementResult> => {
+export const autoSettle = async (
+    incomingId: number,
+    strategy: SettlementStrategy = 'fifo',
+    maxAmountIrr?: number
+): Promise<AutoSettlementResult> => {
     const response = await apiClient.post<AutoSettlementResult>('/auto-settlement/auto-settle', {
         incomingId,
+        strategy,
+        maxAmountIrr,
     });
     return response.data;
 };
@@ -178,6 +205,11 @@ export const getProfitAnalysis = async (filters?: ProfitFilters): Promise<Profit
     if (filters?.startDate) params.append('startDate', filters.startDate);
     if (filters?.endDate) params.append('endDate', filters.endDate);
     if (filters?.branchId) params.append('branchId', filters.branchId.toString());
+    if (filters?.baseCurrency) params.append('baseCurrency', filters.baseCurrency);
+    if (filters?.baselineStart && filters?.baselineEnd) {
+        params.append('baselineStart', filters.baselineStart);
+        params.append('baselineEnd', filters.baselineEnd);
+    }
 
     const response = await apiClient.get<ProfitAnalysis>(`/profit-analysis?${params.toString()}`);
     return response.data;
@@ -190,11 +222,38 @@ export const getProfitByBranch = async (filters?: ProfitFilters): Promise<Branch
     const params = new URLSearchParams();
     if (filters?.startDate) params.append('startDate', filters.startDate);
     if (filters?.endDate) params.append('endDate', filters.endDate);
+    if (filters?.baseCurrency) params.append('baseCurrency', filters.baseCurrency);
 
     const response = await apiClient.get<BranchProfit[]>(`/profit-analysis/by-branch?${params.toString()}`);
     return response.data;
 };
 
+/**
+ * Get profit by payment method
+ */
+export const getProfitByPaymentMethod = async (filters?: ProfitFilters): Promise<PaymentMethodProfit[]> => {
+    const params = new URLSearchParams();
+    if (filters?.startDate) params.append('startDate', filters.startDate);
+    if (filters?.endDate) params.append('endDate', filters.endDate);
+    if (filters?.branchId) params.append('branchId', filters.branchId.toString());
+
+    const response = await apiClient.get<PaymentMethodProfit[]>(`/profit-analysis/by-method?${params.toString()}`);
+    return response.data;
+};
+
+/**
+ * Get profit by the staff member who created each transaction
+ */
+export const getProfitByAgent = async (filters?: ProfitFilters): Promise<AgentProfit[]> => {
+    const params = new URLSearchParams();
+    if (filters?.startDate) params.append('startDate', filters.startDate);
+    if (filters?.endDate) params.append('endDate', filters.endDate);
+    if (filters?.branchId) params.append('branchId', filters.branchId.toString());
+
+    const response = await apiClient.get<AgentProfit[]>(`/profit-analysis/by-agent?${params.toString()}`);
+    return response.data;
+};
+
 /**
  * Get profit trend over time
  */
@@ -212,6 +271,23 @@ export const getProfitTrend = async (
     return response.data;
 };
 
+/**
+ * Get daily profit with a trailing moving average
+ */
+export const getProfitMovingAverage = async (
+    window: number = 7,
+    days: number = 30,
+    branchId?: number
+): Promise<ProfitMovingAverage[]> => {
+    const params = new URLSearchParams();
+    params.append('window', window.toString());
+    params.append('days', days.toString());
+    if (branchId) params.append('branchId', branchId.toString());
+
+    const response = await apiClient.get<ProfitMovingAverage[]>(`/profit-analysis/moving-average?${params.toString()}`);
+    return response.data;
+};
+
 /**
  * Get top profitable customers
  */
@@ -229,6 +305,38 @@ export const getTopCustomers = async (
     return response.data;
 };
 
+/**
+ * Download the full profit analysis as an Excel workbook or CSV
+ */
+export const exportProfitAnalysis = async (format: 'xlsx' | 'csv', filters?: ProfitFilters): Promise<Blob> => {
+    const params = new URLSearchParams({ format });
+    if (filters?.startDate) params.append('startDate', filters.startDate);
+    if (filters?.endDate) params.append('endDate', filters.endDate);
+    if (filters?.branchId) params.append('branchId', filters.branchId.toString());
+    if (filters?.baseCurrency) params.append('baseCurrency', filters.baseCurrency);
+
+    const response = await apiClient.get(`/profit-analysis/export?${params.toString()}`, {
+        responseType: 'blob',
+    });
+    return response.data;
+};
+
+/**
+ * Get alerts for currency pairs whose margin fell below the tenant's floor
+ */
+export const getMarginAlerts = async (status?: 'OPEN' | 'RESOLVED'): Promise<MarginAlert[]> => {
+    const params = status ? `?status=${status}` : '';
+    const response = await apiClient.get<MarginAlert[]>(`/profit-analysis/margin-alerts${params}`);
+    return response.data;
+};
+
+/**
+ * Resolve a currency-pair margin alert
+ */
+export const resolveMarginAlert = async (alertId: number): Promise<void> => {
+    await apiClient.put(`/profit-analysis/margin-alerts/${alertId}/resolve`);
+};
+
 // ============ Receipt API ============
 
 /**
@@ -11,6 +11,8 @@ export interface Tenant {
   currentLicenseId?: number;
   userLimit: number;
   status: 'trial' | 'active' | 'suspended' | 'expired';
+  accessTokenTtl: number; // Minutes; 0 uses the default
+  refreshTokenTtl: number; // Minutes; 0 uses the default
   createdAt: string;
   updatedAt: string;
   owner?: User;
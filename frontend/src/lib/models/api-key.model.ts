@@ -0,0 +1,31 @@
+// ==================== Request Types ====================
+
+export type ApiKeyScope = 'reports:read' | 'transactions:read';
+
+export interface CreateApiKeyRequest {
+  name: string;
+  scopes: ApiKeyScope[];
+  expiresAt?: string; // Omit for a key that never expires
+}
+
+// ==================== Response Types ====================
+
+export interface ApiKey {
+  id: number;
+  tenantId: number;
+  name: string;
+  prefix: string; // Public start of the secret, to tell keys apart
+  scopes: string; // Comma-separated
+  expiresAt?: string;
+  lastUsedAt?: string;
+  revokedAt?: string;
+  revokedBy?: number;
+  createdBy: number;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// Returned only when the key is created; send the secret as the X-API-Key header
+export interface CreatedApiKey extends ApiKey {
+  secret: string;
+}
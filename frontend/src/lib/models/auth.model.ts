@@ -40,6 +40,9 @@ export interface User {
   trialEndsAt: string | null;
   licenseActivatedAt?: string | null;
   emailVerified: boolean;
+  twoFactorEnabled?: boolean;
+  failedLoginCount?: number;
+  lockedUntil?: string | null;
   createdAt: string;
 }
 
@@ -66,6 +69,28 @@ export interface LoginResponse {
   refreshToken?: string;
   user: User;
   tenant?: Tenant;
+  // Set instead of the tokens when the user has two-factor authentication enabled
+  twoFactorRequired?: boolean;
+  challengeToken?: string;
+}
+
+export interface TwoFactorChallenge {
+  challengeToken: string;
+}
+
+export interface Verify2FARequest {
+  challengeToken: string;
+  code: string; // Authenticator code or a recovery code
+}
+
+export interface TwoFactorSetup {
+  secret: string;
+  provisioningUri: string;
+}
+
+export interface ActivateTwoFactorResponse {
+  message: string;
+  recoveryCodes: string[];
 }
 
 export type GetMeResponse = User;
@@ -77,7 +102,8 @@ export interface AuthContextType {
   tenant: Tenant | null;
   isAuthenticated: boolean;
   isLoading: boolean;
-  login: (email: string, password: string) => Promise<void>;
+  login: (email: string, password: string) => Promise<TwoFactorChallenge | void>;
+  verifyTwoFactor: (challengeToken: string, code: string) => Promise<void>;
   logout: () => void;
   refreshUser: () => void;
 }
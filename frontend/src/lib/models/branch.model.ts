@@ -9,6 +9,8 @@ export interface Branch {
     isPrimary: boolean;
     status: 'active' | 'inactive';
     username?: string | null;
+    branchUserId?: number | null; // User that branch logins act as
+    complianceOfficerId?: number | null; // User who reviews KYC routed to this branch
     todayVolume?: number;
     currentCash?: number;
     createdAt: string;
@@ -38,3 +40,20 @@ export interface AssignUserRequest {
     userId: number;
     accessLevel: 'manager' | 'staff';
 }
+
+export interface BranchFloatTarget {
+    id: number;
+    tenantId: number;
+    branchId: number;
+    currency: string;
+    targetAmount: number;
+    bandPercent: number; // Allowed deviation either side of the target, in percent
+    createdAt: string;
+    updatedAt: string;
+}
+
+export interface FloatTargetRequest {
+    currency: string;
+    targetAmount: number;
+    bandPercent?: number;
+}
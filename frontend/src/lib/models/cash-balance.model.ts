@@ -32,6 +32,12 @@ export interface CashAdjustment {
     balanceAfter: number;
     createdAt: string;
 
+    // Approval: adjustments above the currency's approval limit wait as PENDING
+    status: CashAdjustmentStatus;
+    decidedBy?: number;
+    decidedAt?: string;
+    decisionNote?: string;
+
     // Relations
     branch?: {
         id: number;
@@ -41,6 +47,22 @@ export interface CashAdjustment {
         id: number;
         fullName: string;
     };
+    decidedByUser?: {
+        id: number;
+        fullName: string;
+    };
+}
+
+export type CashAdjustmentStatus = 'APPLIED' | 'PENDING' | 'REJECTED';
+
+export interface CashAdjustmentApprovalLimit {
+    id: number;
+    tenantId: number;
+    currency: string;
+    amount: number;
+    createdBy: number;
+    createdAt: string;
+    updatedAt: string;
 }
 
 export interface CreateAdjustmentRequest {
@@ -57,3 +79,32 @@ export interface AdjustmentHistoryResponse {
     limit: number;
     totalPages: number;
 }
+
+export type DailyCloseStatus = 'CLOSED' | 'REOPENED';
+
+export interface DailyCloseBalance {
+    id: number;
+    dailyCloseId: number;
+    currency: string;
+    autoCalculatedBalance: number;
+    manualAdjustment: number;
+    finalBalance: number;
+}
+
+export interface DailyClose {
+    id: number;
+    tenantId: number;
+    branchId?: number;
+    date: string;
+    status: DailyCloseStatus;
+    closedBy: number;
+    closedAt: string;
+    reopenedBy?: number;
+    reopenedAt?: string;
+    reopenReason?: string;
+    balances?: DailyCloseBalance[];
+    branch?: {
+        id: number;
+        name: string;
+    };
+}
@@ -23,10 +23,34 @@ export interface UpdateClientRequest {
   email?: string;
 }
 
+export type ClientImportStatus = 'CREATED' | 'WOULD_CREATE' | 'INVALID' | 'DUPLICATE';
+
+export interface ClientImportRow {
+  row: number; // Line number in the file, counting the header
+  name: string;
+  phoneNumber: string;
+  email?: string;
+  joinDate?: string;
+  status: ClientImportStatus;
+  clientId?: string;
+  errors?: Record<string, string>;
+}
+
+export interface ClientImportResult {
+  dryRun: boolean;
+  totalRows: number;
+  valid: number;
+  invalid: number;
+  duplicates: number;
+  created: number;
+  rows: ClientImportRow[];
+}
+
 // ==================== Transaction Types ====================
 
 export interface Transaction {
   id: string;
+  transactionNumber?: string; // e.g. TXN-DT-20250610-0001, restarting per the tenant's numbering policy
   clientId: string;
   tenantId: number;
   type: 'CASH_EXCHANGE' | 'BANK_TRANSFER' | 'MONEY_PICKUP' | 'WALK_IN_CUSTOMER';
@@ -57,6 +81,19 @@ export interface Transaction {
   remainingBalance?: number;
   paymentStatus?: 'SINGLE' | 'OPEN' | 'PARTIAL' | 'FULLY_PAID';
   allowPartialPayment?: boolean;
+  writtenOffAmount?: number;
+  writeOffReason?: string;
+  writtenOffAt?: string;
+  writtenOffBy?: number;
+  collectionTicketId?: number;
+  marginOverrideReason?: string; // Justification for a margin below the pair's profit floor (admins only)
+  marginOverrideBy?: number;
+  rateWarning?: string; // Set when the rate is further from the standard rate than the tenant's warning spread
+  rateOverride?: boolean;
+  rateOverrideReason?: string;
+  rateOverrideBy?: number;
+  deletedAt?: string;
+  deletedBy?: number;
 
   // Profit & Loss fields
   standardRate?: number;
@@ -66,9 +103,53 @@ export interface Transaction {
   payments?: Payment[];
 }
 
+// One page of GET /transactions, newest first
+export interface TransactionPage {
+  data: Transaction[];
+  total: number; // Matching transactions across all pages
+  limit: number;
+  nextCursor?: string; // Pass as cursor to fetch the next page; absent on the last page
+}
+
+export type TransactionSearchField =
+  | 'beneficiary_name'
+  | 'client_name'
+  | 'client_phone'
+  | 'user_notes'
+  | 'send_currency'
+  | 'receive_currency'
+  | 'payment_method';
+
+// A transaction matching a search, with its relevance and where the query matched (character offsets)
+export interface TransactionSearchResult extends Transaction {
+  score: number;
+  highlights: { field: TransactionSearchField; start: number; end: number }[];
+}
+
 // Import Payment type
 import { Payment } from './payment.model';
 
+// Immutable snapshot of the rates in force when a transaction was created
+export interface TransactionRateAudit {
+  id: number;
+  transactionId: string;
+  baseCurrency: string;
+  targetCurrency: string;
+  rateApplied: number;
+  rateSource: 'ENTERED' | 'EFFECTIVE_RATE';
+  standardRate?: number;
+  standardRateId?: number;
+  standardRateSource?: 'API' | 'MANUAL';
+  standardRateRecordedAt?: string;
+  marketRate?: number;
+  marketRateId?: number;
+  marketRateRecordedAt?: string;
+  manualOverrideRate?: number;
+  manualOverrideId?: number;
+  manualOverrideExpiresAt?: string;
+  capturedAt: string;
+}
+
 export interface CreateTransactionRequest {
   clientId: string;
   type: 'CASH_EXCHANGE' | 'BANK_TRANSFER' | 'MONEY_PICKUP' | 'WALK_IN_CUSTOMER';
@@ -83,6 +164,8 @@ export interface CreateTransactionRequest {
   userNotes?: string;
   transactionDate?: string;
   allowPartialPayment?: boolean;
+  rateOverride?: boolean; // Accept a rate beyond the tenant's blocking spread (owners and admins only)
+  rateOverrideReason?: string;
 }
 
 export interface UpdateTransactionRequest {
@@ -100,6 +183,39 @@ export interface UpdateTransactionRequest {
   allowPartialPayment?: boolean;
 }
 
+// Saved details of a repeat exchange; only the amounts and rate are entered each time
+export interface TransactionTemplate {
+  id: number;
+  tenantId: number;
+  branchId?: number; // Absent when shared by every branch
+  name: string;
+  clientId: string;
+  paymentMethod: 'CASH_EXCHANGE' | 'BANK_TRANSFER';
+  sendCurrency: string;
+  receiveCurrency: string;
+  feeCharged: number;
+  beneficiaryName?: string;
+  beneficiaryDetails?: string;
+  userNotes?: string;
+  createdBy: number;
+  createdAt: string;
+  updatedAt: string;
+  client?: Client;
+}
+
+export interface TransactionTemplateRequest {
+  name: string;
+  clientId: string;
+  branchId?: number;
+  paymentMethod?: 'CASH_EXCHANGE' | 'BANK_TRANSFER';
+  sendCurrency: string;
+  receiveCurrency: string;
+  feeCharged?: number;
+  beneficiaryName?: string;
+  beneficiaryDetails?: string;
+  userNotes?: string;
+}
+
 export interface ClientWithTransactions extends Client {
   transactions: Transaction[];
 }
@@ -21,6 +21,12 @@ export interface CustomerTenantLink {
     createdAt: string;
     updatedAt: string;
 
+    // CRM segmentation and messaging consent (per tenant)
+    tags: string; // Comma-separated, lower-case
+    marketingConsent: boolean;
+    emailOptOut: boolean;
+    smsOptOut: boolean;
+
     // Relations
     customer?: Customer;
     tenant?: {
@@ -66,3 +72,33 @@ export interface UpdateCustomerRequest {
     fullName: string;
     email?: string;
 }
+
+export interface UpdateCustomerConsentRequest {
+    marketingConsent?: boolean;
+    emailOptOut?: boolean;
+    smsOptOut?: boolean;
+}
+
+export type CampaignChannel = 'EMAIL' | 'SMS';
+
+export interface CustomerMessageRequest {
+    tag: string;
+    channel: CampaignChannel;
+    subject?: string; // Required for email
+    message: string;
+}
+
+export interface CustomerCampaign {
+    id: number;
+    tenantId: number;
+    tag: string;
+    channel: CampaignChannel;
+    subject: string;
+    message: string;
+    matchedCount: number;
+    sentCount: number;
+    skippedCount: number; // No consent, opted out of the channel, or no address
+    failedCount: number;
+    createdBy: number;
+    createdAt: string;
+}
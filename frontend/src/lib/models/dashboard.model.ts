@@ -28,8 +28,31 @@ export interface DebtAging {
     isCritical: boolean;
 }
 
+export interface InstallmentAging {
+    bucket: string;
+    count: number;
+    outstanding: Record<string, number>;
+    isWarning: boolean;
+    isCritical: boolean;
+}
+
+export interface CashFlowForecastPoint {
+    date: string;
+    expectedIn: number;
+    expectedOut: number;
+    netPosition: number;
+}
+
+export interface CashFlowForecast {
+    baseCurrency: string;
+    days: number;
+    series: CashFlowForecastPoint[];
+    unconvertedCurrencies: string[];
+}
+
 export interface RateTrend {
     date: string;
+    baseCurrency: string;
     currency: string;
     buyRate: number;
     sellRate: number;
@@ -63,6 +86,7 @@ export interface DashboardData {
     weekMetrics: DailyMetrics;
     monthMetrics: DailyMetrics;
     debtAging: DebtAging[];
+    installmentAging: InstallmentAging[];
     rateTrends: RateTrend[];
     dailyProfit: DailyProfit[];
     dailyVolumes: DailyVolume[];
@@ -94,6 +118,15 @@ export interface AutoSettlementResult {
     totalAmountIRR: number;
     totalProfitCAD: number;
     settlements: SettlementInfo[];
+    pendingApproval?: HeldSettlementInfo[]; // Held for a second admin's approval
+    maxAmountIrr?: number; // Cap the allocation was limited to, if any
+}
+
+export interface HeldSettlementInfo {
+    id: number;
+    outgoingRemittanceId: number;
+    amountIrr: number;
+    projectedProfitCad: number;
 }
 
 export interface SettlementInfo {
@@ -114,6 +147,7 @@ export interface UnsettledSummary {
 // Profit Analysis Models
 
 export interface ProfitAnalysis {
+    baseCurrency: string;
     totalProfitCAD: number;
     totalSettlements: number;
     averageProfitPerSettlement: number;
@@ -122,6 +156,17 @@ export interface ProfitAnalysis {
         incoming: number;
     };
     topProfitableCurrencies: CurrencyProfit[];
+    unconvertedTransactions: number;
+    unconvertedCurrencies: string[];
+    vsBaseline?: ProfitTrendComparison; // Present when baselineStart/baselineEnd were requested
+}
+
+export interface ProfitTrendComparison {
+    currentPeriodProfit: number;
+    previousPeriodProfit: number;
+    changeAmount: number;
+    changePercentage: number;
+    isPositive: boolean;
 }
 
 export interface CurrencyProfit {
@@ -138,6 +183,23 @@ export interface BranchProfit {
     averageProfit: number;
 }
 
+export interface PaymentMethodProfit {
+    paymentMethod: string;
+    totalProfitCad: number;
+    transactionCount: number;
+    volume: number;
+    percentage: number;
+}
+
+export interface AgentProfit {
+    userId: number;
+    agentName: string;
+    totalProfitCad: number;
+    transactionCount: number;
+    volume: number;
+    percentage: number;
+}
+
 export interface ProfitPeriod {
     period: string;
     totalProfitCAD: number;
@@ -145,6 +207,13 @@ export interface ProfitPeriod {
     averageProfit: number;
 }
 
+export interface ProfitMovingAverage {
+    date: string;
+    profit: number;
+    movingAverage: number;
+    windowDays: number;
+}
+
 export interface CustomerProfit {
     customerId: number;
     customerName: string;
@@ -153,10 +222,32 @@ export interface CustomerProfit {
     averageProfit: number;
 }
 
+export interface MarginAlert {
+    id: number;
+    tenantId: number;
+    baseCurrency: string;
+    targetCurrency: string;
+    status: 'OPEN' | 'RESOLVED';
+    marginPct: number;
+    floorPct: number;
+    profit: number;
+    volume: number;
+    transactionCount: number;
+    windowStart: string;
+    windowEnd: string;
+    details: string;
+    resolvedAt?: string | null;
+    resolvedByUserId?: number | null;
+    createdAt: string;
+}
+
 export interface ProfitFilters {
     startDate?: string;
     endDate?: string;
     branchId?: number;
+    baseCurrency?: string;
+    baselineStart?: string;
+    baselineEnd?: string;
     groupBy?: 'day' | 'week' | 'month';
 }
 
@@ -38,3 +38,37 @@ export interface ExchangeRequest {
 export interface LedgerBalance {
     [currency: string]: number;
 }
+
+export interface ClientStatement {
+    clientId: string;
+    currency: string;
+    periodStart: string;
+    periodEnd: string; // Exclusive
+    openingBalance: number;
+    closingBalance: number;
+    entries: LedgerEntry[];
+    priorPeriodId?: number;
+    expectedOpening?: number; // Prior closed period's closing plus activity since
+    openingDiscrepancy: boolean;
+    discrepancyAmount: number;
+    discrepancyNote?: string;
+}
+
+export interface StatementPeriod {
+    id: number;
+    tenantId: number;
+    clientId: string;
+    currency: string;
+    periodStart: string;
+    periodEnd: string;
+    openingBalance: number;
+    closingBalance: number;
+    closedAt: string;
+    closedBy: number;
+}
+
+export interface CloseStatementPeriodRequest {
+    currency: string;
+    from: string; // YYYY-MM-DD
+    to: string; // YYYY-MM-DD, inclusive
+}
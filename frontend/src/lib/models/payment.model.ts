@@ -1,3 +1,5 @@
+export type PaymentRateSource = 'manual' | 'navasan' | 'stored';
+
 export interface Payment {
     id: number;
     tenantId: number;
@@ -6,6 +8,7 @@ export interface Payment {
     amount: number;
     currency: string;
     exchangeRate: number;
+    rateSource: PaymentRateSource;
     amountInBase: number;
     paymentMethod: string;
     paidBy: number;
@@ -22,6 +25,7 @@ export interface Payment {
     cancelledAt?: string;
     cancelledBy?: number;
     cancelReason?: string;
+    refundedAmount: number;
 
     // Relations
     branch?: {
@@ -38,7 +42,8 @@ export interface Payment {
 export interface CreatePaymentRequest {
     amount: number;
     currency: string;
-    exchangeRate: number;
+    exchangeRate?: number;
+    rateSource?: PaymentRateSource;
     paymentMethod: string;
     notes?: string;
     receiptNumber?: string;
@@ -59,6 +64,51 @@ export interface CancelPaymentRequest {
     reason: string;
 }
 
+export interface RefundPaymentRequest {
+    amount: number;
+    reason: string;
+}
+
+export type InstallmentStatus = 'PENDING' | 'PARTIAL' | 'PAID';
+
+export interface PaymentInstallment {
+    id: number;
+    planId: number;
+    transactionId: string;
+    sequence: number;
+    dueDate: string;
+    expectedAmount: number;
+    currency: string;
+    paidAmount: number;
+    status: InstallmentStatus;
+    paidAt?: string;
+}
+
+export interface PaymentPlan {
+    id: number;
+    transactionId: string;
+    currency: string;
+    paidBeforePlan: number;
+    notes?: string;
+    createdBy: number;
+    createdAt: string;
+    installments: PaymentInstallment[];
+}
+
+export interface CreatePaymentPlanRequest {
+    installments: {
+        dueDate: string; // YYYY-MM-DD
+        expectedAmount: number;
+        currency?: string;
+    }[];
+    notes?: string;
+}
+
+export interface CompleteTransactionRequest {
+    writeOff?: boolean;
+    reason?: string;
+}
+
 export const PAYMENT_METHODS = [
     { value: 'CASH', label: 'Cash' },
     { value: 'BANK_TRANSFER', label: 'Bank Transfer' },
@@ -26,7 +26,7 @@ export type DisbursementType =
 export type TransactionType = DisbursementType;
 
 // Disbursement Status
-export type DisbursementStatus = 'PENDING' | 'DISBURSED' | 'CANCELLED' | 'PICKED_UP';
+export type DisbursementStatus = 'PENDING' | 'READY' | 'DISBURSED' | 'CANCELLED' | 'PICKED_UP';
 export type PickupStatus = DisbursementStatus;
 
 /**
@@ -51,6 +51,7 @@ export interface Disbursement {
     // Recipient/Beneficiary Details
     recipientName: string;
     recipientPhone?: string;
+    recipientEmail?: string;
     recipientIban?: string;
 
     /** Disbursement method */
@@ -66,6 +67,10 @@ export interface Disbursement {
 
     status: DisbursementStatus;
 
+    // Ready for pickup
+    readyAt?: string;
+    readyNotifiedAt?: string;
+
     // Disbursement completion
     pickedUpAt?: string;
     pickedUpByUserId?: number;
@@ -135,6 +140,7 @@ export interface CreateDisbursementRequest {
     senderPhone: string;
     recipientName: string;
     recipientPhone?: string;
+    recipientEmail?: string;
     recipientIban?: string;
     transactionType: DisbursementType;
     amount: number;
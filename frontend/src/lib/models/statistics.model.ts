@@ -31,3 +31,58 @@ export interface StatisticsFilters {
     startDate?: string;
     endDate?: string;
 }
+
+export type ExportFormat = 'CSV' | 'JSON' | 'PDF';
+
+export interface ExportProfileFilters {
+    branchId?: number;
+    startDate?: string;
+    endDate?: string;
+    currency?: string;
+}
+
+export interface ExportProfile {
+    id: number;
+    tenantId: number;
+    name: string;
+    entity: 'transactions';
+    columns: string; // JSON array of TransactionExportRow keys
+    filters: string; // JSON ExportProfileFilters
+    format: ExportFormat;
+    createdBy: number;
+    createdAt: string;
+    updatedAt: string;
+}
+
+export interface ExportProfileRequest {
+    name: string;
+    entity?: 'transactions';
+    columns: (keyof TransactionExportRow)[];
+    filters: ExportProfileFilters;
+    format: ExportFormat;
+}
+
+export type ExportFrequency = 'DAILY' | 'WEEKLY' | 'MONTHLY';
+
+export interface ScheduledExport {
+    id: number;
+    tenantId: number;
+    exportProfileId: number;
+    frequency: ExportFrequency;
+    recipientEmail: string;
+    isActive: boolean;
+    lastRunAt?: string;
+    lastRunStatus?: 'SENT' | 'FAILED';
+    lastRunRows: number;
+    lastError?: string;
+    createdBy: number;
+    createdAt: string;
+    updatedAt: string;
+    exportProfile?: ExportProfile;
+}
+
+export interface ScheduledExportRequest {
+    exportProfileId: number;
+    frequency: ExportFrequency;
+    recipientEmail: string;
+}
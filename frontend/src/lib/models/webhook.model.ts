@@ -0,0 +1,61 @@
+// ==================== Request Types ====================
+
+export type WebhookEventType = 'settlement.created' | 'transaction.large' | 'ticket.sla_breached';
+
+export interface WebhookEndpointRequest {
+  url: string;
+  events?: (WebhookEventType | '*')[]; // Empty or '*' subscribes to every event
+  isActive?: boolean;
+}
+
+export interface WebhookDeliveryFilters {
+  endpointId?: number;
+  status?: WebhookDeliveryStatus;
+  limit?: number;
+}
+
+// ==================== Response Types ====================
+
+export interface WebhookEndpoint {
+  id: number;
+  tenantId: number;
+  url: string;
+  events: string; // Comma-separated, empty = all events
+  isActive: boolean;
+  createdAt: string;
+  updatedAt: string;
+}
+
+// Returned only when the endpoint is created; verify the X-Webhook-Signature header with this secret
+export interface CreatedWebhookEndpoint extends WebhookEndpoint {
+  secret: string;
+}
+
+export type WebhookDeliveryStatus = 'PENDING' | 'DELIVERED' | 'FAILED';
+
+export interface WebhookDeliveryAttempt {
+  id: number;
+  deliveryId: number;
+  attempt: number;
+  responseCode: number; // 0 when the endpoint could not be reached
+  error?: string;
+  durationMs: number;
+  attemptedAt: string;
+}
+
+export interface WebhookDelivery {
+  id: number;
+  tenantId: number;
+  endpointId: number;
+  eventType: string;
+  payload: string;
+  status: WebhookDeliveryStatus;
+  attempts: number;
+  responseCode: number;
+  lastError: string;
+  nextAttemptAt: string;
+  deliveredAt?: string;
+  createdAt: string;
+  updatedAt: string;
+  history?: WebhookDeliveryAttempt[];
+}
@@ -4,6 +4,10 @@ import {
     CreatePaymentRequest,
     UpdatePaymentRequest,
     CancelPaymentRequest,
+    RefundPaymentRequest,
+    CompleteTransactionRequest,
+    PaymentPlan,
+    CreatePaymentPlanRequest,
 } from './models/payment.model';
 import { Transaction } from './models/client.model';
 
@@ -66,13 +70,44 @@ export const cancelPayment = async (
     return response.data;
 };
 
+/**
+ * Issue a partial refund against a payment
+ */
+export const refundPayment = async (
+    paymentId: number,
+    data: RefundPaymentRequest
+): Promise<{ message: string; payment: Payment }> => {
+    const response = await axiosInstance.post(`/payments/${paymentId}/refund`, data);
+    return response.data;
+};
+
 /**
  * Complete a transaction (mark as fully paid)
  */
 export const completeTransaction = async (
-    transactionId: string
+    transactionId: string,
+    data?: CompleteTransactionRequest
 ): Promise<{ message: string; transaction: Transaction }> => {
-    const response = await axiosInstance.post(`/transactions/${transactionId}/complete`);
+    const response = await axiosInstance.post(`/transactions/${transactionId}/complete`, data);
+    return response.data;
+};
+
+/**
+ * Attach an installment plan to a partial-payment transaction
+ */
+export const createPaymentPlan = async (
+    transactionId: string,
+    data: CreatePaymentPlanRequest
+): Promise<{ message: string; plan: PaymentPlan }> => {
+    const response = await axiosInstance.post(`/transactions/${transactionId}/payment-plan`, data);
+    return response.data;
+};
+
+/**
+ * Get a transaction's installment plan
+ */
+export const getPaymentPlan = async (transactionId: string): Promise<PaymentPlan> => {
+    const response = await axiosInstance.get(`/transactions/${transactionId}/payment-plan`);
     return response.data;
 };
 
@@ -41,6 +41,14 @@ export const searchPickupByCode = async (code: string): Promise<PickupTransactio
     return response.data;
 };
 
+// Mark pickup as ready for collection (notifies the recipient)
+export const markAsReady = async (
+    id: number
+): Promise<{ message: string; recipientNotified: boolean; pickup: PickupTransaction }> => {
+    const response = await axiosInstance.post(`/pickups/${id}/ready`);
+    return response.data;
+};
+
 // Mark pickup as picked up
 export const markAsPickedUp = async (id: number): Promise<{ message: string }> => {
     const response = await axiosInstance.post(`/pickups/${id}/pickup`);
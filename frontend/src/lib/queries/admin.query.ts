@@ -96,6 +96,26 @@ export const useActivateTenant = () => {
   });
 };
 
+export const useUpdateTenantTokenLifetimes = () => {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: async ({
+      id,
+      accessTokenTtl,
+      refreshTokenTtl,
+    }: {
+      id: string;
+      accessTokenTtl?: number;
+      refreshTokenTtl?: number;
+    }) => {
+      await api.put(`/admin/tenants/${id}/token-lifetimes`, { accessTokenTtl, refreshTokenTtl });
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['admin', 'tenants'] });
+    },
+  });
+};
+
 export const useDeleteTenant = () => {
   const queryClient = useQueryClient();
   return useMutation({
@@ -119,3 +139,15 @@ export const useGetAllUsers = () => {
     },
   });
 };
+
+export const useUnlockUser = () => {
+  const queryClient = useQueryClient();
+  return useMutation({
+    mutationFn: async (id: number) => {
+      await api.post(`/admin/users/${id}/unlock`);
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['admin', 'users'] });
+    },
+  });
+};
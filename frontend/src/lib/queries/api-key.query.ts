@@ -0,0 +1,54 @@
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
+import apiClient from '../axios-config';
+import { ApiKey, CreateApiKeyRequest, CreatedApiKey } from '../models/api-key.model';
+
+// ==================== API Functions ====================
+
+const apiKeyApi = {
+  getApiKeys: async (): Promise<ApiKey[]> => {
+    const response = await apiClient.get('/api-keys');
+    return response.data;
+  },
+
+  createApiKey: async (data: CreateApiKeyRequest): Promise<CreatedApiKey> => {
+    const response = await apiClient.post('/api-keys', data);
+    return response.data;
+  },
+
+  revokeApiKey: async (keyId: number): Promise<ApiKey> => {
+    const response = await apiClient.post(`/api-keys/${keyId}/revoke`);
+    return response.data;
+  },
+};
+
+// ==================== React Query Hooks ====================
+
+export const useGetApiKeys = (enabled = true) => {
+  return useQuery({
+    queryKey: ['api-keys'],
+    queryFn: apiKeyApi.getApiKeys,
+    enabled,
+  });
+};
+
+export const useCreateApiKey = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: apiKeyApi.createApiKey,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['api-keys'] });
+    },
+  });
+};
+
+export const useRevokeApiKey = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: apiKeyApi.revokeApiKey,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['api-keys'] });
+    },
+  });
+};
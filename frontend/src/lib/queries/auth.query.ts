@@ -9,6 +9,9 @@ import {
   LoginResponse,
   ResendCodeRequest,
   GetMeResponse,
+  Verify2FARequest,
+  TwoFactorSetup,
+  ActivateTwoFactorResponse,
 } from '../models/auth.model';
 
 // ==================== API Functions ====================
@@ -29,6 +32,26 @@ const authApi = {
     return response.data;
   },
 
+  verify2FA: async (data: Verify2FARequest): Promise<LoginResponse> => {
+    const response = await apiClient.post('/auth/2fa/verify', data);
+    return response.data;
+  },
+
+  enableTwoFactor: async (): Promise<TwoFactorSetup> => {
+    const response = await apiClient.post('/auth/2fa/enable');
+    return response.data;
+  },
+
+  activateTwoFactor: async (code: string): Promise<ActivateTwoFactorResponse> => {
+    const response = await apiClient.post('/auth/2fa/activate', { code });
+    return response.data;
+  },
+
+  disableTwoFactor: async (code: string): Promise<{ message: string }> => {
+    const response = await apiClient.post('/auth/2fa/disable', { code });
+    return response.data;
+  },
+
   resendCode: async (data: ResendCodeRequest): Promise<{ message: string }> => {
     const response = await apiClient.post('/auth/resend-code', data);
     return response.data;
@@ -73,6 +96,9 @@ export const useLogin = () => {
   return useMutation({
     mutationFn: authApi.login,
     onSuccess: (data) => {
+      // Nothing to store until the second factor is verified
+      if (data.twoFactorRequired) return;
+
       // Store token and user in localStorage (SSR-safe)
       tokenStorage.setAccessToken(data.token);
       tokenStorage.setUser(data.user);
@@ -83,6 +109,47 @@ export const useLogin = () => {
   });
 };
 
+export const useVerify2FA = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: authApi.verify2FA,
+    onSuccess: (data) => {
+      tokenStorage.setAccessToken(data.token);
+      tokenStorage.setUser(data.user);
+      queryClient.setQueryData(['user', 'me'], data.user);
+    },
+  });
+};
+
+export const useEnableTwoFactor = () => {
+  return useMutation({
+    mutationFn: authApi.enableTwoFactor,
+  });
+};
+
+export const useActivateTwoFactor = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: authApi.activateTwoFactor,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['user', 'me'] });
+    },
+  });
+};
+
+export const useDisableTwoFactor = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: authApi.disableTwoFactor,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['user', 'me'] });
+    },
+  });
+};
+
 export const useResendCode = () => {
   return useMutation({
     mutationFn: authApi.resendCode,
@@ -5,6 +5,8 @@ import type {
     CreateBranchRequest,
     UpdateBranchRequest,
     AssignUserRequest,
+    BranchFloatTarget,
+    FloatTargetRequest,
 } from '../models/branch.model';
 
 // ==================== Branch Queries ====================
@@ -99,3 +101,61 @@ export function useAssignUserToBranch(branchId: number) {
         },
     });
 }
+
+// ==================== Float Targets ====================
+
+export function useGetFloatTargets(branchId: number) {
+    return useQuery<BranchFloatTarget[]>({
+        queryKey: ['branch', branchId, 'floatTargets'],
+        queryFn: async () => {
+            const response = await axiosInstance.get(`/branches/${branchId}/float-targets`);
+            return response.data;
+        },
+        enabled: !!branchId,
+    });
+}
+
+export function useCreateFloatTarget(branchId: number) {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: async (data: FloatTargetRequest) => {
+            const response = await axiosInstance.post(`/branches/${branchId}/float-targets`, data);
+            return response.data as BranchFloatTarget;
+        },
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['branch', branchId, 'floatTargets'] });
+            queryClient.invalidateQueries({ queryKey: ['dashboard'] });
+        },
+    });
+}
+
+export function useUpdateFloatTarget(branchId: number) {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: async ({ targetId, data }: { targetId: number; data: FloatTargetRequest }) => {
+            const response = await axiosInstance.put(`/branches/${branchId}/float-targets/${targetId}`, data);
+            return response.data as BranchFloatTarget;
+        },
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['branch', branchId, 'floatTargets'] });
+            queryClient.invalidateQueries({ queryKey: ['dashboard'] });
+        },
+    });
+}
+
+export function useDeleteFloatTarget(branchId: number) {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: async (targetId: number) => {
+            const response = await axiosInstance.delete(`/branches/${branchId}/float-targets/${targetId}`);
+            return response.data;
+        },
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['branch', branchId, 'floatTargets'] });
+            queryClient.invalidateQueries({ queryKey: ['dashboard'] });
+        },
+    });
+}
@@ -4,8 +4,15 @@ import type {
   Client,
   CreateClientRequest,
   UpdateClientRequest,
+  ClientImportResult,
   ClientWithTransactions,
   Transaction,
+  TransactionPage,
+  TransactionSearchField,
+  TransactionSearchResult,
+  TransactionTemplate,
+  TransactionTemplateRequest,
+  TransactionRateAudit,
   CreateTransactionRequest,
   UpdateTransactionRequest,
 } from '../models/client.model';
@@ -76,6 +83,29 @@ export function useDeleteClient() {
   });
 }
 
+// Validates a CSV of clients; with dryRun nothing is saved and only the per-row outcome is returned
+export function useImportClients() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async ({ file, dryRun }: { file: File; dryRun: boolean }) => {
+      const formData = new FormData();
+      formData.append('file', file);
+      const response = await axiosInstance.post<ClientImportResult>(
+        `/clients/import?dryRun=${dryRun}`,
+        formData,
+        { headers: { 'Content-Type': 'multipart/form-data' } }
+      );
+      return response.data;
+    },
+    onSuccess: (result) => {
+      if (!result.dryRun) {
+        queryClient.invalidateQueries({ queryKey: ['clients'] });
+      }
+    },
+  });
+}
+
 // ==================== Transaction Queries ====================
 
 export function useGetTransactions(
@@ -105,12 +135,72 @@ export function useGetTransactions(
       const fullUrl = queryString ? `${url}?${queryString}` : url;
 
       const response = await axiosInstance.get(fullUrl);
-      return response.data;
+      // The tenant-wide list is paginated; this hook only shows its first page
+      return clientId ? response.data : (response.data as TransactionPage).data;
     },
     enabled: clientId ? !!clientId : true,
   });
 }
 
+export function useGetTransactionsPage(
+  filters?: { startDate?: string; endDate?: string },
+  branchId?: string,
+  cursor?: string,
+  limit?: number
+) {
+  return useQuery<TransactionPage>({
+    queryKey: ['transactions', 'page', filters, branchId, cursor, limit],
+    queryFn: async () => {
+      const params = new URLSearchParams();
+
+      if (filters?.startDate) {
+        params.append('startDate', filters.startDate);
+      }
+      if (filters?.endDate) {
+        params.append('endDate', filters.endDate);
+      }
+      if (branchId && branchId !== 'all') {
+        params.append('branchId', branchId);
+      }
+      if (cursor) {
+        params.append('cursor', cursor);
+      }
+      if (limit) {
+        params.append('limit', String(limit));
+      }
+
+      const response = await axiosInstance.get(`/transactions?${params.toString()}`);
+      return response.data;
+    },
+  });
+}
+
+export function useSearchTransactions(query: string, fields?: TransactionSearchField[]) {
+  return useQuery<TransactionSearchResult[]>({
+    queryKey: ['transactions', 'search', query, fields],
+    queryFn: async () => {
+      const params = new URLSearchParams({ q: query });
+      if (fields && fields.length > 0) {
+        params.append('fields', fields.join(','));
+      }
+      const response = await axiosInstance.get(`/transactions/search?${params.toString()}`);
+      return response.data;
+    },
+    enabled: query.trim().length > 0,
+  });
+}
+
+export function useGetTransactionRateAudit(transactionId: string) {
+  return useQuery<TransactionRateAudit>({
+    queryKey: ['transaction-rate-audit', transactionId],
+    queryFn: async () => {
+      const response = await axiosInstance.get(`/transactions/${transactionId}/rate-audit`);
+      return response.data;
+    },
+    enabled: !!transactionId,
+  });
+}
+
 export function useCreateTransaction() {
   const queryClient = useQueryClient();
 
@@ -161,6 +251,26 @@ export function useCancelTransaction() {
   });
 }
 
+export function useVoidTransaction() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async ({ transactionId, reason }: { transactionId: string; reason: string }) => {
+      const response = await axiosInstance.post<{ message: string; transaction: Transaction }>(
+        `/transactions/${transactionId}/void`,
+        { reason }
+      );
+      return response.data.transaction;
+    },
+    onSuccess: (_, { transactionId }) => {
+      queryClient.invalidateQueries({ queryKey: ['transactions'] });
+      queryClient.invalidateQueries({ queryKey: ['recent-transactions'] });
+      queryClient.invalidateQueries({ queryKey: ['payments', transactionId] });
+    },
+
+  });
+}
+
 export function useDeleteTransaction() {
   const queryClient = useQueryClient();
 
@@ -176,6 +286,22 @@ export function useDeleteTransaction() {
   });
 }
 
+export function useRestoreTransaction() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async (transactionId: string) => {
+      const response = await axiosInstance.post<Transaction>(`/transactions/${transactionId}/restore`);
+      return response.data;
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['transactions'] });
+      queryClient.invalidateQueries({ queryKey: ['recent-transactions'] });
+    },
+
+  });
+}
+
 // ==================== Payment Queries ====================
 
 export function useCreatePayment(transactionId: string) {
@@ -209,3 +335,67 @@ export function useGetPayments(transactionId: string) {
     enabled: !!transactionId,
   });
 }
+
+// ==================== Transaction Template Queries ====================
+
+export function useGetTransactionTemplates(branchId?: string) {
+  return useQuery<TransactionTemplate[]>({
+    queryKey: ['transaction-templates', branchId],
+    queryFn: async () => {
+      const params = branchId && branchId !== 'all' ? `?branchId=${branchId}` : '';
+      const response = await axiosInstance.get(`/transaction-templates${params}`);
+      return response.data;
+    },
+  });
+}
+
+export function useCreateTransactionTemplate() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async (data: TransactionTemplateRequest) => {
+      const response = await axiosInstance.post('/transaction-templates', data);
+      return response.data as TransactionTemplate;
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['transaction-templates'] });
+    },
+  });
+}
+
+export function useUpdateTransactionTemplate(templateId: number) {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async (data: TransactionTemplateRequest) => {
+      const response = await axiosInstance.put(`/transaction-templates/${templateId}`, data);
+      return response.data as TransactionTemplate;
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['transaction-templates'] });
+    },
+  });
+}
+
+export function useDeleteTransactionTemplate() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: async (templateId: number) => {
+      await axiosInstance.delete(`/transaction-templates/${templateId}`);
+    },
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['transaction-templates'] });
+    },
+  });
+}
+
+// Prefills an unsaved transaction from a template; enter the amounts and rate, then use useCreateTransaction
+export function usePrefillTransactionFromTemplate() {
+  return useMutation({
+    mutationFn: async (templateId: number) => {
+      const response = await axiosInstance.post(`/transaction-templates/${templateId}/transaction`);
+      return response.data as Transaction;
+    },
+  });
+}
@@ -1,6 +1,7 @@
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
 import {
     checkTransactionCompliance,
+    getComplianceAlerts,
     getComplianceAuditLog,
     getComplianceDocuments,
     getCustomerCompliance,
@@ -8,10 +9,12 @@ import {
     getPendingReviews,
     getVerificationStatus,
     initiateVerification,
+    resolveComplianceAlert,
     reviewDocument,
     setTransactionLimits,
     updateComplianceStatus,
     uploadComplianceDocument,
+    type ComplianceAlertStatus,
     type ComplianceStatus,
     type DocumentType,
 } from '../compliance-api';
@@ -24,6 +27,7 @@ export const complianceKeys = {
     auditLog: (complianceId: number) => [...complianceKeys.all, 'audit', complianceId] as const,
     pending: () => [...complianceKeys.all, 'pending'] as const,
     expiring: (days?: number) => [...complianceKeys.all, 'expiring', days] as const,
+    alerts: (status?: ComplianceAlertStatus) => [...complianceKeys.all, 'alerts', status] as const,
     verification: (complianceId: number) => [...complianceKeys.all, 'verification', complianceId] as const,
 };
 
@@ -66,6 +70,13 @@ export function useExpiringCompliance(days?: number) {
     });
 }
 
+export function useComplianceAlerts(status?: ComplianceAlertStatus, limit?: number) {
+    return useQuery({
+        queryKey: complianceKeys.alerts(status),
+        queryFn: () => getComplianceAlerts(status, limit),
+    });
+}
+
 export function useVerificationStatus(complianceId: number, enabled: boolean = true) {
     return useQuery({
         queryKey: complianceKeys.verification(complianceId),
@@ -109,6 +120,17 @@ export function useUpdateComplianceStatus() {
     });
 }
 
+export function useResolveComplianceAlert() {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: (alertId: number) => resolveComplianceAlert(alertId),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: [...complianceKeys.all, 'alerts'] });
+        },
+    });
+}
+
 export function useSetTransactionLimits() {
     const queryClient = useQueryClient();
 
@@ -7,8 +7,17 @@ import {
     updateCustomer,
     searchCustomersGlobal,
     getCustomerWithTenants,
+    setCustomerTags,
+    updateCustomerConsent,
+    sendCustomerMessage,
+    getCustomerCampaigns,
 } from '../customer-api';
-import { FindOrCreateCustomerRequest, UpdateCustomerRequest } from '../models/customer.model';
+import {
+    CustomerMessageRequest,
+    FindOrCreateCustomerRequest,
+    UpdateCustomerConsentRequest,
+    UpdateCustomerRequest,
+} from '../models/customer.model';
 
 // Search customers
 export const useSearchCustomers = (query: string) => {
@@ -28,11 +37,11 @@ export const useGetCustomerByPhone = (phone: string) => {
     });
 };
 
-// Get all customers for tenant
-export const useGetCustomersForTenant = () => {
+// Get all customers for tenant, optionally only those carrying a tag
+export const useGetCustomersForTenant = (tag?: string) => {
     return useQuery({
-        queryKey: ['customers', 'tenant'],
-        queryFn: getCustomersForTenant,
+        queryKey: ['customers', 'tenant', tag ?? ''],
+        queryFn: () => getCustomersForTenant(tag),
     });
 };
 
@@ -61,6 +70,50 @@ export const useUpdateCustomer = (id: number) => {
     });
 };
 
+// Replace a customer's tags
+export const useSetCustomerTags = (id: number) => {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: (tags: string[]) => setCustomerTags(id, tags),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['customers'] });
+        },
+    });
+};
+
+// Update a customer's messaging consent
+export const useUpdateCustomerConsent = (id: number) => {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: (data: UpdateCustomerConsentRequest) => updateCustomerConsent(id, data),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['customers'] });
+        },
+    });
+};
+
+// Message a tagged customer segment
+export const useSendCustomerMessage = () => {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: (data: CustomerMessageRequest) => sendCustomerMessage(data),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['customer-campaigns'] });
+        },
+    });
+};
+
+// Get the tenant's customer campaigns
+export const useGetCustomerCampaigns = () => {
+    return useQuery({
+        queryKey: ['customer-campaigns'],
+        queryFn: getCustomerCampaigns,
+    });
+};
+
 // ============ SUPER ADMIN HOOKS ============
 
 // Search customers globally (SuperAdmin only)
@@ -10,11 +10,14 @@ import {
     getUnsettledSummary,
     getProfitAnalysis,
     getProfitByBranch,
+    getProfitByAgent,
     getProfitTrend,
+    getProfitMovingAverage,
     getTopCustomers,
     downloadOutgoingReceipt,
     downloadIncomingReceipt,
     downloadBlobAsFile,
+    SettlementStrategy,
 } from '../dashboard-api';
 import { ProfitFilters } from '../models/dashboard.model';
 
@@ -69,7 +72,7 @@ export const useGetCashOnHand = (branchId?: number) => {
  */
 export const useGetSettlementSuggestions = (
     incomingId: number,
-    strategy: 'fifo' | 'best_rate' = 'fifo',
+    strategy: SettlementStrategy = 'fifo',
     enabled: boolean = true
 ) => {
     return useQuery({
@@ -86,7 +89,15 @@ export const useAutoSettle = () => {
     const queryClient = useQueryClient();
 
     return useMutation({
-        mutationFn: (incomingId: number) => autoSettle(incomingId),
+        mutationFn: ({
+            incomingId,
+            strategy,
+            maxAmountIrr,
+        }: {
+            incomingId: number;
+            strategy?: SettlementStrategy;
+            maxAmountIrr?: number;
+        }) => autoSettle(incomingId, strategy, maxAmountIrr),
         onSuccess: () => {
             // Invalidate related queries
             queryClient.invalidateQueries({ queryKey: ['dashboard'] });
@@ -166,6 +177,26 @@ export const useGetProfitTrend = (
     });
 };
 
+/**
+ * Hook to get daily profit with a trailing moving average
+ */
+export const useGetProfitMovingAverage = (window: number = 7, days: number = 30, branchId?: number) => {
+    return useQuery({
+        queryKey: ['profit-analysis', 'moving-average', window, days, branchId],
+        queryFn: () => getProfitMovingAverage(window, days, branchId),
+    });
+};
+
+/**
+ * Hook to get profit by agent
+ */
+export const useGetProfitByAgent = (filters?: ProfitFilters) => {
+    return useQuery({
+        queryKey: ['profit-analysis', 'by-agent', filters],
+        queryFn: () => getProfitByAgent(filters),
+    });
+};
+
 /**
  * Hook to get top customers by profit
  */
@@ -7,9 +7,10 @@ export interface ExchangeRate {
     baseCurrency: string;
     targetCurrency: string;
     rate: number;
-    source: string;
+    source: string; // MANUAL for overrides, API for the external rate
     createdAt: string;
     updatedAt: string;
+    expiresAt?: string; // Temporary manual overrides revert to the external rate after this time
 }
 
 export interface ExternalRatesResponse {
@@ -141,7 +142,7 @@ export const useGetScrapedRates = () => {
                 success: true,
                 source: 'Navasan API',
                 rates: rates,
-                refreshed: true
+                refreshed: !responseData.stale
             };
         },
         refetchInterval: 5 * 60 * 1000, // Refetch every 5 minutes
@@ -217,7 +218,7 @@ export const useSetCustomRate = () => {
     const queryClient = useQueryClient();
 
     return useMutation({
-        mutationFn: async (data: { baseCurrency: string; targetCurrency: string; rate: number }) => {
+        mutationFn: async (data: { baseCurrency: string; targetCurrency: string; rate: number; expiresAt?: string }) => {
             const response = await apiClient.post('/rates/manual', data);
             return response.data;
         },
@@ -272,6 +273,9 @@ export interface NavasanResponse {
     items?: NavasanRawItem[];
     source: string;
     message?: string;
+    fetched_at?: string;
+    stale?: boolean; // true when Navasan was unreachable and the last fetched rates were returned
+    age_seconds?: number;
 }
 
 /**
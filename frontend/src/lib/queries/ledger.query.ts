@@ -5,6 +5,9 @@ import type {
     CreateLedgerEntryRequest,
     ExchangeRequest,
     LedgerBalance,
+    ClientStatement,
+    StatementPeriod,
+    CloseStatementPeriodRequest,
 } from '../models/ledger.model';
 
 // ==================== Ledger Queries ====================
@@ -64,3 +67,32 @@ export function useExchangeCurrency(clientId: string) {
         },
     });
 }
+
+// ==================== Statements ====================
+
+export function useGetClientStatement(clientId: string, currency: string, from: string, to: string) {
+    return useQuery<ClientStatement>({
+        queryKey: ['ledger-statement', clientId, currency, from, to],
+        queryFn: async () => {
+            const response = await axiosInstance.get(`/clients/${clientId}/ledger/statement`, {
+                params: { currency, from, to },
+            });
+            return response.data;
+        },
+        enabled: !!clientId && !!currency && !!from && !!to,
+    });
+}
+
+export function useCloseStatementPeriod(clientId: string) {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: async (data: CloseStatementPeriodRequest) => {
+            const response = await axiosInstance.post(`/clients/${clientId}/ledger/statement/close`, data);
+            return response.data as StatementPeriod;
+        },
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['ledger-statement', clientId] });
+        },
+    });
+}
@@ -11,6 +11,8 @@ export interface DailyReconciliation {
     expectedBalance: number;
     variance: number;
     currencyBreakdown?: string;
+    expectedBreakdown?: string;
+    varianceFlagged: boolean;
     notes?: string;
     createdByUserId: number;
     createdAt: string;
@@ -32,6 +34,30 @@ export interface ExpectedBalanceBreakdown {
     total: number;
 }
 
+export interface ReconciliationCurrencyMatch {
+    currency: string;
+    opening: number;
+    cashIn: number;
+    cashOut: number; // Negative
+    expected: number;
+    tolerance: number;
+    counted?: number;
+    variance?: number;
+    flagged: boolean;
+}
+
+export interface ReconciliationMatch {
+    branchId: number;
+    date: string;
+    previousDate?: string;
+    tolerance: number; // Used for currencies without a tolerance of their own
+    currencies: ReconciliationCurrencyMatch[];
+    expectedBalance: number;
+    countedBalance: number;
+    varianceFlagged: boolean;
+    flaggedCurrencies: string[];
+}
+
 /**
  * Hook to create a new reconciliation record
  */
@@ -104,3 +130,49 @@ export const useGetSystemState = (branchId?: number) => {
         enabled: !!branchId,
     });
 };
+
+/**
+ * Hook to get the expected closing balances per currency that pre-fill a branch's reconciliation
+ */
+export const useGetReconciliationAutoMatch = (branchId?: number, date?: string) => {
+    return useQuery({
+        queryKey: ['reconciliationAutoMatch', branchId, date],
+        queryFn: async () => {
+            const params = new URLSearchParams();
+            if (branchId) params.append('branchId', branchId.toString());
+            if (date) params.append('date', date);
+            const response = await apiClient.get<ReconciliationMatch>(
+                `/reconciliation/auto-match?${params.toString()}`
+            );
+            return response.data;
+        },
+        enabled: !!branchId,
+    });
+};
+
+/**
+ * Hook to create a reconciliation from counted amounts, matched against the expected balances
+ */
+export const useCreateMatchedReconciliation = () => {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: async (data: {
+            branchId: number;
+            date: string;
+            counted: Record<string, number>;
+            notes?: string;
+        }) => {
+            const response = await apiClient.post<{
+                reconciliation: DailyReconciliation;
+                match: ReconciliationMatch;
+            }>('/reconciliation/auto-match', data);
+            return response.data;
+        },
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['reconciliations'] });
+            queryClient.invalidateQueries({ queryKey: ['reconciliationVariance'] });
+            queryClient.invalidateQueries({ queryKey: ['reconciliationAutoMatch'] });
+        },
+    });
+};
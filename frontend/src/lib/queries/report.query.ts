@@ -26,6 +26,23 @@ export interface BranchSummary {
     revenue: number;
 }
 
+export interface CancellationRateReport {
+    startDate: string;
+    endDate: string;
+    totalTransactions: number;
+    cancelledCount: number;
+    cancellationRate: number;
+    branches: BranchCancellationRate[];
+}
+
+export interface BranchCancellationRate {
+    branchId: number;
+    branchName: string;
+    totalTransactions: number;
+    cancelledCount: number;
+    cancellationRate: number;
+}
+
 /**
  * Hook to get daily report
  */
@@ -79,3 +96,21 @@ export const useGetCustomReport = (startDate?: string, endDate?: string, branchI
         enabled: !!startDate && !!endDate,
     });
 };
+
+/**
+ * Hook to get the voided/cancelled transaction rate
+ */
+export const useGetCancellationRate = (start?: string, end?: string, branchId?: number) => {
+    return useQuery({
+        queryKey: ['cancellationRate', start, end, branchId],
+        queryFn: async () => {
+            const params = new URLSearchParams();
+            if (start) params.append('start', start);
+            if (end) params.append('end', end);
+            if (branchId) params.append('branchId', branchId.toString());
+
+            const response = await apiClient.get<CancellationRateReport>(`/reports/cancellation-rate?${params.toString()}`);
+            return response.data;
+        },
+    });
+};
@@ -1,6 +1,17 @@
-import { useQuery, useMutation } from '@tanstack/react-query';
-import { getStatistics, exportToCSV, exportToJSON, exportToPDF, downloadBlob } from '../statistics-api';
-import { StatisticsFilters } from '../models/statistics.model';
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
+import {
+    getStatistics,
+    exportToCSV,
+    exportToJSON,
+    exportToPDF,
+    downloadBlob,
+    getExportProfiles,
+    createExportProfile,
+    updateExportProfile,
+    deleteExportProfile,
+    runExportProfile,
+} from '../statistics-api';
+import { StatisticsFilters, ExportProfile, ExportProfileRequest } from '../models/statistics.model';
 
 /**
  * Hook to get transaction statistics
@@ -50,3 +61,65 @@ export const useExportToPDF = () => {
         },
     });
 };
+
+/**
+ * Hook to get the tenant's export profiles
+ */
+export const useGetExportProfiles = () => {
+    return useQuery({
+        queryKey: ['export-profiles'],
+        queryFn: getExportProfiles,
+    });
+};
+
+/**
+ * Hook to create an export profile
+ */
+export const useCreateExportProfile = () => {
+    const queryClient = useQueryClient();
+    return useMutation({
+        mutationFn: (data: ExportProfileRequest) => createExportProfile(data),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['export-profiles'] });
+        },
+    });
+};
+
+/**
+ * Hook to update an export profile
+ */
+export const useUpdateExportProfile = () => {
+    const queryClient = useQueryClient();
+    return useMutation({
+        mutationFn: ({ id, data }: { id: number; data: ExportProfileRequest }) => updateExportProfile(id, data),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['export-profiles'] });
+        },
+    });
+};
+
+/**
+ * Hook to delete an export profile
+ */
+export const useDeleteExportProfile = () => {
+    const queryClient = useQueryClient();
+    return useMutation({
+        mutationFn: (id: number) => deleteExportProfile(id),
+        onSuccess: () => {
+            queryClient.invalidateQueries({ queryKey: ['export-profiles'] });
+        },
+    });
+};
+
+/**
+ * Hook to run a saved export profile and download the result
+ */
+export const useRunExportProfile = () => {
+    return useMutation({
+        mutationFn: (profile: ExportProfile) => runExportProfile(profile.id),
+        onSuccess: (blob, profile) => {
+            const date = new Date().toISOString().split('T')[0];
+            downloadBlob(blob, `${profile.entity}_${profile.name}_${date}.${profile.format.toLowerCase()}`);
+        },
+    });
+};
@@ -5,8 +5,11 @@ import {
     updateUser,
     deleteUser,
     checkUsernameAvailability,
+    getMyPreferences,
+    updateMyPreferences,
     CreateBranchUserRequest,
     UpdateUserRequest,
+    UpdateUserPreferencesRequest,
 } from '../user-api';
 
 // Query keys
@@ -14,6 +17,7 @@ export const userKeys = {
     all: ['users'] as const,
     lists: () => [...userKeys.all, 'list'] as const,
     detail: (id: number) => [...userKeys.all, 'detail', id] as const,
+    preferences: () => [...userKeys.all, 'me', 'preferences'] as const,
 };
 
 // Get all users
@@ -69,3 +73,24 @@ export const useDeleteUser = () => {
         },
     });
 };
+
+// Get the current user's preferences
+export const useGetMyPreferences = () => {
+    return useQuery({
+        queryKey: userKeys.preferences(),
+        queryFn: getMyPreferences,
+    });
+};
+
+// Update the current user's preferences; report and dashboard data depend on the default range
+export const useUpdateMyPreferences = () => {
+    const queryClient = useQueryClient();
+
+    return useMutation({
+        mutationFn: (data: UpdateUserPreferencesRequest) => updateMyPreferences(data),
+        onSuccess: (preferences) => {
+            queryClient.setQueryData(userKeys.preferences(), preferences);
+            queryClient.invalidateQueries({ queryKey: ['profit-analysis'] });
+        },
+    });
+};
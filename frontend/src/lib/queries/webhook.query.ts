@@ -0,0 +1,88 @@
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
+import apiClient from '../axios-config';
+import {
+  CreatedWebhookEndpoint,
+  WebhookDelivery,
+  WebhookDeliveryFilters,
+  WebhookEndpoint,
+  WebhookEndpointRequest,
+} from '../models/webhook.model';
+
+// ==================== API Functions ====================
+
+const webhookApi = {
+  getWebhookEndpoints: async (): Promise<WebhookEndpoint[]> => {
+    const response = await apiClient.get('/webhooks');
+    return response.data;
+  },
+
+  createWebhookEndpoint: async (data: WebhookEndpointRequest): Promise<CreatedWebhookEndpoint> => {
+    const response = await apiClient.post('/webhooks', data);
+    return response.data;
+  },
+
+  updateWebhookEndpoint: async ({ id, data }: { id: number; data: WebhookEndpointRequest }): Promise<WebhookEndpoint> => {
+    const response = await apiClient.put(`/webhooks/${id}`, data);
+    return response.data;
+  },
+
+  deleteWebhookEndpoint: async (id: number): Promise<void> => {
+    await apiClient.delete(`/webhooks/${id}`);
+  },
+
+  getWebhookDeliveries: async (filters?: WebhookDeliveryFilters): Promise<WebhookDelivery[]> => {
+    const response = await apiClient.get('/webhooks/deliveries', { params: filters });
+    return response.data;
+  },
+};
+
+// ==================== React Query Hooks ====================
+
+export const useGetWebhookEndpoints = (enabled = true) => {
+  return useQuery({
+    queryKey: ['webhooks'],
+    queryFn: webhookApi.getWebhookEndpoints,
+    enabled,
+  });
+};
+
+export const useGetWebhookDeliveries = (filters?: WebhookDeliveryFilters, enabled = true) => {
+  return useQuery({
+    queryKey: ['webhooks', 'deliveries', filters],
+    queryFn: () => webhookApi.getWebhookDeliveries(filters),
+    enabled,
+  });
+};
+
+export const useCreateWebhookEndpoint = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: webhookApi.createWebhookEndpoint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['webhooks'] });
+    },
+  });
+};
+
+export const useUpdateWebhookEndpoint = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: webhookApi.updateWebhookEndpoint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['webhooks'] });
+    },
+  });
+};
+
+export const useDeleteWebhookEndpoint = () => {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: webhookApi.deleteWebhookEndpoint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['webhooks'] });
+    },
+  });
+};
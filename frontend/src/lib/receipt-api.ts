@@ -1,6 +1,8 @@
 import { apiClient } from './api-client';
 
 // Types
+export type ReceiptLocale = 'en' | 'fa';
+
 export interface ReceiptTemplate {
     id: number;
     tenantId: number;
@@ -19,6 +21,7 @@ export interface ReceiptTemplate {
     marginLeft: number;
     logoPath?: string;
     logoPosition: 'left' | 'center' | 'right';
+    locale: ReceiptLocale;
     isDefault: boolean;
     isActive: boolean;
     version: number;
@@ -26,6 +29,16 @@ export interface ReceiptTemplate {
     updatedAt: string;
 }
 
+export interface BranchReceiptTemplate {
+    id: number;
+    tenantId: number;
+    branchId: number;
+    templateType: ReceiptTemplate['templateType'];
+    templateId: number;
+    createdAt: string;
+    updatedAt: string;
+}
+
 export interface ReceiptVariable {
     name: string;
     description: string;
@@ -49,6 +62,7 @@ export interface CreateTemplateRequest {
     marginLeft?: number;
     logoPath?: string;
     logoPosition?: string;
+    locale?: ReceiptLocale;
     isDefault?: boolean;
 }
 
@@ -82,6 +96,15 @@ export async function setDefaultTemplate(id: number): Promise<void> {
     await apiClient.put(`/receipts/templates/${id}/default`);
 }
 
+export async function setBranchDefaultTemplate(branchId: number, templateId: number): Promise<BranchReceiptTemplate> {
+    const response = await apiClient.put<BranchReceiptTemplate>(`/branches/${branchId}/default-template`, { templateId });
+    return response.data;
+}
+
+export async function clearBranchDefaultTemplate(branchId: number, templateType: string): Promise<void> {
+    await apiClient.put(`/branches/${branchId}/default-template`, { templateId: null, templateType });
+}
+
 export async function duplicateTemplate(id: number, name?: string): Promise<ReceiptTemplate> {
     const response = await apiClient.post<ReceiptTemplate>(`/receipts/templates/${id}/duplicate`, { name });
     return response.data;
@@ -100,15 +123,26 @@ export async function getAvailableVariables(templateType = 'transaction'): Promi
     return response.data;
 }
 
-export async function renderReceipt(templateId: number | null, templateType: string, data: Record<string, unknown>): Promise<string> {
+export async function renderReceipt(templateId: number | null, templateType: string, data: Record<string, unknown>, locale?: ReceiptLocale): Promise<string> {
     const response = await apiClient.post<string>('/receipts/render', {
         templateId,
         templateType,
         data,
+        locale,
     }, { responseType: 'text' });
     return response.data;
 }
 
+export async function renderReceiptPDF(templateId: number | null, templateType: string, data: Record<string, unknown>, locale?: ReceiptLocale): Promise<Blob> {
+    const response = await apiClient.post('/receipts/render', {
+        templateId,
+        templateType,
+        data,
+        locale,
+    }, { params: { format: 'pdf' }, responseType: 'blob' });
+    return response.data;
+}
+
 export async function createDefaultTemplates(): Promise<void> {
     await apiClient.post('/receipts/templates/defaults');
 }
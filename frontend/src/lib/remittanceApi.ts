@@ -2,9 +2,12 @@ import {
     OutgoingRemittance,
     IncomingRemittance,
     RemittanceSettlement,
+    PendingSettlement,
+    SettlementPreview,
     CreateOutgoingRemittanceRequest,
     CreateIncomingRemittanceRequest,
     CreateSettlementRequest,
+    SettleMultipleRequest,
     MarkAsPaidRequest,
     CancelRemittanceRequest,
     RemittanceFilters,
@@ -146,10 +149,57 @@ class RemittanceApiClient {
     // ==================== Settlements ====================
 
     /**
-     * Create a settlement between outgoing and incoming remittances
+     * Create a settlement between outgoing and incoming remittances. A settlement at or above the
+     * tenant's approval limits comes back as a pending settlement instead of being applied.
      */
-    async createSettlement(data: CreateSettlementRequest): Promise<RemittanceSettlement> {
-        return this.request<RemittanceSettlement>('/api/remittances/settle', {
+    async createSettlement(data: CreateSettlementRequest): Promise<RemittanceSettlement | PendingSettlement> {
+        return this.request<RemittanceSettlement | PendingSettlement>('/api/remittances/settle', {
+            method: 'POST',
+            body: JSON.stringify(data),
+        });
+    }
+
+    /**
+     * List settlements waiting for approval
+     */
+    async getPendingSettlements(): Promise<PendingSettlement[]> {
+        return this.request<PendingSettlement[]>('/api/remittances/settlements/pending');
+    }
+
+    /**
+     * Approve a pending settlement; the approver must differ from its creator
+     */
+    async approveSettlement(id: number): Promise<RemittanceSettlement> {
+        return this.request<RemittanceSettlement>(`/api/remittances/settlements/${id}/approve`, {
+            method: 'POST',
+        });
+    }
+
+    /**
+     * Reject a pending settlement, leaving both remittances unchanged
+     */
+    async rejectSettlement(id: number, note?: string): Promise<PendingSettlement> {
+        return this.request<PendingSettlement>(`/api/remittances/settlements/${id}/reject`, {
+            method: 'POST',
+            body: JSON.stringify({ note }),
+        });
+    }
+
+    /**
+     * Settle one incoming remittance against several outgoing remittances; all allocations are saved or none
+     */
+    async settleMultiple(data: SettleMultipleRequest): Promise<RemittanceSettlement[]> {
+        return this.request<RemittanceSettlement[]>('/api/remittances/settle-multiple', {
+            method: 'POST',
+            body: JSON.stringify(data),
+        });
+    }
+
+    /**
+     * Preview a settlement without saving it
+     */
+    async previewSettlement(data: CreateSettlementRequest): Promise<SettlementPreview> {
+        return this.request<SettlementPreview>('/api/remittances/settle/preview', {
             method: 'POST',
             body: JSON.stringify(data),
         });
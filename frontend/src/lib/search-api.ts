@@ -21,6 +21,8 @@ export interface SearchFilter {
     status?: string[];
     currency?: string[];
     branchId?: number;
+    iban?: string;
+    ibanMatch?: 'exact' | 'prefix';
     customFields?: Record<string, unknown>;
 }
 
@@ -1,5 +1,12 @@
 import { apiClient } from './axios-config';
-import { TransactionStatistics, StatisticsFilters } from './models/statistics.model';
+import {
+    TransactionStatistics,
+    StatisticsFilters,
+    ExportProfile,
+    ExportProfileRequest,
+    ScheduledExport,
+    ScheduledExportRequest,
+} from './models/statistics.model';
 
 /**
  * Get transaction statistics
@@ -92,6 +99,68 @@ export const exportToPDF = async (filters?: StatisticsFilters): Promise<Blob> =>
     return response.data;
 };
 
+/**
+ * Get the tenant's saved export profiles
+ */
+export const getExportProfiles = async (): Promise<ExportProfile[]> => {
+    const response = await apiClient.get<ExportProfile[]>('/exports/profiles');
+    return response.data;
+};
+
+/**
+ * Save a new export profile
+ */
+export const createExportProfile = async (data: ExportProfileRequest): Promise<ExportProfile> => {
+    const response = await apiClient.post<ExportProfile>('/exports/profiles', data);
+    return response.data;
+};
+
+/**
+ * Replace an export profile's configuration
+ */
+export const updateExportProfile = async (id: number, data: ExportProfileRequest): Promise<ExportProfile> => {
+    const response = await apiClient.put<ExportProfile>(`/exports/profiles/${id}`, data);
+    return response.data;
+};
+
+/**
+ * Delete an export profile
+ */
+export const deleteExportProfile = async (id: number): Promise<void> => {
+    await apiClient.delete(`/exports/profiles/${id}`);
+};
+
+/**
+ * Run a saved export profile
+ */
+export const runExportProfile = async (id: number): Promise<Blob> => {
+    const response = await apiClient.post(`/exports/${id}/run`, null, { responseType: 'blob' });
+    return response.data;
+};
+
+/**
+ * Get the tenant's scheduled exports
+ */
+export const getScheduledExports = async (): Promise<ScheduledExport[]> => {
+    const response = await apiClient.get<ScheduledExport[]>('/exports/schedules');
+    return response.data;
+};
+
+/**
+ * Email an export profile on a recurring schedule
+ */
+export const createScheduledExport = async (data: ScheduledExportRequest): Promise<ScheduledExport> => {
+    const response = await apiClient.post<ScheduledExport>('/exports/schedules', data);
+    return response.data;
+};
+
+/**
+ * Stop a scheduled export
+ */
+export const deleteScheduledExport = async (id: number): Promise<void> => {
+    await apiClient.delete(`/exports/schedules/${id}`);
+};
+
 /**
  * Helper function to download a blob as a file
  */
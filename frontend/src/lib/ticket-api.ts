@@ -23,6 +23,10 @@ export interface Ticket {
     tags?: string;
     resolution?: string;
     resolvedAt?: string;
+    mergedIntoId?: number;
+    satisfactionScore?: number;
+    satisfactionComment?: string;
+    satisfactionRatedAt?: string;
     dueAt?: string;
     breachedSla?: boolean;
     createdAt: string;
@@ -70,6 +74,8 @@ export interface TicketStats {
     highPriorityCount: number;
     slaBreachCount: number;
     unassignedCount: number;
+    averageSatisfaction: number;
+    ratedCount: number;
 }
 
 export interface TicketListResponse {
@@ -148,6 +154,14 @@ export async function resolveTicket(id: number, resolution: string): Promise<voi
     await apiClient.post(`/tickets/${id}/resolve`, { resolution });
 }
 
+export async function mergeTicket(id: number, targetTicketId: number): Promise<void> {
+    await apiClient.post(`/tickets/${id}/merge`, { targetTicketId });
+}
+
+export async function recordTicketSatisfaction(id: number, score: number, comment = ''): Promise<void> {
+    await apiClient.post(`/tickets/${id}/satisfaction`, { score, comment });
+}
+
 export async function getTicketMessages(id: number, includeInternal = false): Promise<TicketMessage[]> {
     const url = `/tickets/${id}/messages${includeInternal ? '?includeInternal=true' : ''}`;
     const response = await apiClient.get<TicketMessage[]>(url);
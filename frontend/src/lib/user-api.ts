@@ -13,6 +13,7 @@ export interface User {
     };
     status: string;
     emailVerified: boolean;
+    ticketCategories: string; // Comma-separated ticket categories auto-assigned to the user, or ALL
     createdAt: string;
 }
 
@@ -31,6 +32,7 @@ export interface UpdateUserRequest {
     primaryBranchId?: number | null;
     role?: string;
     status?: string;
+    ticketCategories?: string;
 }
 
 export interface CheckUsernameResponse {
@@ -39,6 +41,22 @@ export interface CheckUsernameResponse {
     suggestions?: string[];
 }
 
+export type DefaultDateRange = '' | 'today' | '7d' | '30d' | 'custom';
+
+export interface UserPreferences {
+    id?: number;
+    userId: number;
+    defaultDateRange: DefaultDateRange;
+    customStartDate?: string;
+    customEndDate?: string;
+}
+
+export interface UpdateUserPreferencesRequest {
+    defaultDateRange?: DefaultDateRange;
+    customStartDate?: string; // YYYY-MM-DD
+    customEndDate?: string;
+}
+
 // Check username availability
 export const checkUsernameAvailability = async (username: string): Promise<CheckUsernameResponse> => {
     const response = await axiosInstance.get(`/users/check-username?username=${username}`);
@@ -68,3 +86,15 @@ export const deleteUser = async (id: number): Promise<{ message: string }> => {
     const response = await axiosInstance.delete(`/users/${id}`);
     return response.data;
 };
+
+// Get the current user's preferences
+export const getMyPreferences = async (): Promise<UserPreferences> => {
+    const response = await axiosInstance.get('/me/preferences');
+    return response.data;
+};
+
+// Update the current user's preferences
+export const updateMyPreferences = async (data: UpdateUserPreferencesRequest): Promise<UserPreferences> => {
+    const response = await axiosInstance.put('/me/preferences', data);
+    return response.data;
+};
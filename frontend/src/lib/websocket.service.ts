@@ -1,8 +1,8 @@
 import { tokenStorage } from './api-client';
 
 export interface WSMessage {
-    type: 'transaction' | 'pickup' | 'cash_balance' | 'remittance' | 'ticket' | 'ticket_message' | 'ticket_assignment';
-    action: 'created' | 'updated' | 'deleted' | 'status_changed' | 'assigned' | 'resolved' | 'message';
+    type: 'transaction' | 'pickup' | 'cash_balance' | 'remittance' | 'payment' | 'settlement' | 'ticket' | 'ticket_message' | 'ticket_assignment' | 'subscription';
+    action: 'created' | 'updated' | 'deleted' | 'status_changed' | 'assigned' | 'resolved' | 'message' | 'subscribed' | 'unsubscribed' | 'error';
     data: Record<string, unknown>;
     tenantId: number;
     timestamp: string;
@@ -10,6 +10,22 @@ export interface WSMessage {
 
 type MessageHandler = (message: WSMessage) => void;
 
+// The server only sends a connection the channels it subscribed to; each message type travels on one channel
+const TYPE_CHANNELS: Record<string, string> = {
+    transaction: 'transactions',
+    payment: 'payments',
+    settlement: 'settlements',
+    remittance: 'remittances',
+    pickup: 'pickups',
+    cash_balance: 'cash_balances',
+    ticket: 'tickets',
+    ticket_message: 'tickets',
+    ticket_assignment: 'tickets',
+};
+
+const channelsFor = (type: string): string[] =>
+    type === '*' ? Array.from(new Set(Object.values(TYPE_CHANNELS))) : TYPE_CHANNELS[type] ? [TYPE_CHANNELS[type]] : [];
+
 class WebSocketService {
     private ws: WebSocket | null = null;
     private reconnectTimeout: NodeJS.Timeout | null = null;
@@ -18,6 +34,7 @@ class WebSocketService {
     private reconnectDelay = 5000; // Increased delay
     private messageQueue: WSMessage[] = [];
     private handlers: Map<string, Set<MessageHandler>> = new Map();
+    private channelRefs: Map<string, number> = new Map();
     private isConnecting = false;
 
     connect(): void {
@@ -43,6 +60,7 @@ class WebSocketService {
                 console.log('✅ WebSocket connected');
                 this.isConnecting = false;
                 this.reconnectAttempts = 0;
+                this.channelRefs.forEach((_, channel) => this.sendSubscription('subscribe', channel));
                 this.flushMessageQueue();
             };
 
@@ -144,18 +162,47 @@ class WebSocketService {
         }
     }
 
+    private sendSubscription(action: 'subscribe' | 'unsubscribe', channel: string): void {
+        if (this.ws?.readyState === WebSocket.OPEN) {
+            this.ws.send(JSON.stringify({ action, channel }));
+        }
+    }
+
+    private retainChannels(type: string): void {
+        channelsFor(type).forEach((channel) => {
+            const refs = this.channelRefs.get(channel) ?? 0;
+            this.channelRefs.set(channel, refs + 1);
+            if (refs === 0) {
+                this.sendSubscription('subscribe', channel);
+            }
+        });
+    }
+
+    private releaseChannels(type: string): void {
+        channelsFor(type).forEach((channel) => {
+            const refs = (this.channelRefs.get(channel) ?? 0) - 1;
+            if (refs > 0) {
+                this.channelRefs.set(channel, refs);
+                return;
+            }
+            this.channelRefs.delete(channel);
+            this.sendSubscription('unsubscribe', channel);
+        });
+    }
+
     subscribe(type: string, handler: MessageHandler): () => void {
         if (!this.handlers.has(type)) {
             this.handlers.set(type, new Set());
         }
 
         this.handlers.get(type)!.add(handler);
+        this.retainChannels(type);
 
         // Return unsubscribe function
         return () => {
             const handlers = this.handlers.get(type);
-            if (handlers) {
-                handlers.delete(handler);
+            if (handlers?.delete(handler)) {
+                this.releaseChannels(type);
                 if (handlers.size === 0) {
                     this.handlers.delete(type);
                 }
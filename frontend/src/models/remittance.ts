@@ -10,6 +10,8 @@ export interface OutgoingRemittance {
   senderName: string;
   senderPhone: string;
   senderEmail?: string;
+  clientId?: string; // CRM client linked by sender phone
+  transactionId?: string; // Transaction the customer paid through
   
   // Recipient (in Iran)
   recipientName: string;
@@ -28,12 +30,17 @@ export interface OutgoingRemittance {
   // Settlement tracking
   settledAmountIrr: number;
   remainingIrr: number;
+  writtenOffIrr?: number; // Residual rounded off on completion
   status: 'PENDING' | 'PARTIAL' | 'COMPLETED' | 'CANCELLED';
   
   // Profit tracking
   totalProfitCad: number;
   totalCostCad: number;
   
+  // Compliance
+  samePartyFlag?: string; // Why sender and recipient look like the same party
+  trackingCode?: string; // Code the customer uses on the public status page
+  
   // Notes
   notes?: string;
   internalNotes?: string;
@@ -82,6 +89,7 @@ export interface IncomingRemittance {
   // Settlement tracking
   allocatedIrr: number;
   remainingIrr: number;
+  writtenOffIrr?: number; // Residual rounded off on completion
   status: 'PENDING' | 'PARTIAL' | 'COMPLETED' | 'PAID' | 'CANCELLED';
   
   // Payment tracking
@@ -90,6 +98,10 @@ export interface IncomingRemittance {
   paidAt?: string;
   paidBy?: number;
   
+  // Compliance
+  samePartyFlag?: string; // Why sender and recipient look like the same party
+  trackingCode?: string; // Code the customer uses on the public status page
+  
   // Notes
   notes?: string;
   internalNotes?: string;
@@ -131,6 +143,34 @@ export interface RemittanceSettlement {
   creator?: User;
 }
 
+// A settlement at or above the tenant's approval limits, applied only once another owner or admin approves it
+export type PendingSettlementStatus = 'PENDING' | 'APPROVED' | 'REJECTED';
+
+export interface PendingSettlement {
+  id: number;
+  tenantId: number;
+  outgoingRemittanceId: number;
+  incomingRemittanceId: number;
+
+  amountIrr: number;
+  projectedProfitCad: number;
+  notes?: string;
+
+  status: PendingSettlementStatus;
+  decidedBy?: number;
+  decidedAt?: string;
+  decisionNote?: string;
+  settlementId?: number; // Set once approved
+
+  createdBy: number;
+  createdAt: string;
+  updatedAt: string;
+
+  // Relations
+  outgoingRemittance?: OutgoingRemittance;
+  incomingRemittance?: IncomingRemittance;
+}
+
 // Request/Form types
 export interface CreateOutgoingRemittanceRequest {
   senderName: string;
@@ -145,6 +185,7 @@ export interface CreateOutgoingRemittanceRequest {
   buyRateCad: number;
   receivedCad: number;
   feeCAD?: number;
+  transactionId?: string; // Transaction the customer paid through
   notes?: string;
   internalNotes?: string;
 }
@@ -172,6 +213,22 @@ export interface CreateSettlementRequest {
   notes?: string;
 }
 
+export interface SettleMultipleRequest {
+  incomingRemittanceId: number;
+  allocations: { outgoingRemittanceId: number; amountIrr: number }[];
+  notes?: string;
+}
+
+export interface SettlementPreview {
+  settlement: RemittanceSettlement;
+  outgoingStatus: OutgoingRemittance['status'];
+  outgoingRemainingIrr: number;
+  outgoingWrittenOffIrr: number;
+  incomingStatus: IncomingRemittance['status'];
+  incomingRemainingIrr: number;
+  incomingWrittenOffIrr: number;
+}
+
 export interface MarkAsPaidRequest {
   paymentMethod: 'CASH' | 'E_TRANSFER' | 'BANK_TRANSFER' | 'CHEQUE' | 'OTHER';
   paymentReference?: string;
@@ -194,6 +251,11 @@ export interface ProfitSummary {
   totalProfitCAD: number;
   totalSettlements: number;
   averageProfitCAD: number;
+  baseCurrency?: string;
+  normalizedTotalProfit?: number;
+  convertedSettlements?: number;
+  averageNormalizedProfit?: number; // normalizedTotalProfit / convertedSettlements
+  unconvertedCurrencies?: string[];
 }
 
 // Supporting types